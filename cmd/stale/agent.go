@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jiin/stale/internal/config"
+	"github.com/jiin/stale/internal/service/agent"
+	"github.com/jiin/stale/internal/service/scanner"
+)
+
+// runAgent scans the sources of a configuration document locally and reports their dependencies
+// to a central server. It needs no database and runs until interrupted, or once with --interval 0.
+func runAgent(cfg *config.Config, args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("agent", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	server := fs.String("server", os.Getenv("STALE_AGENT_SERVER"), "base URL of the central stale server (STALE_AGENT_SERVER)")
+	apiKey := fs.String("api-key", os.Getenv("STALE_AGENT_API_KEY"), "API key of the central server (STALE_AGENT_API_KEY)")
+	sourcesPath := fs.String("config", cfg.AgentConfig, "configuration document listing the sources to scan, with tokens (STALE_AGENT_CONFIG)")
+	name := fs.String("name", cfg.AgentName, "external source the repositories are reported under (STALE_AGENT_NAME)")
+	interval := fs.Duration("interval", cfg.AgentInterval, "time between scans, 0 to scan once and exit (STALE_AGENT_INTERVAL)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if *server == "" {
+		fmt.Fprintln(stderr, "--server is required")
+		return 2
+	}
+	sources, err := agent.LoadSources(*sourcesPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to load sources: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Registries are looked up by the agent; without a database no recommended versions apply
+	collector := scanner.New(nil, nil, nil, nil, nil, nil, nil, cfg.OwnershipFile, cfg.SPIToken)
+	a := agent.New(agent.Config{
+		ServerURL: *server,
+		APIKey:    *apiKey,
+		Name:      *name,
+		Interval:  *interval,
+		Sources:   sources,
+	}, collector)
+	if err := a.Run(ctx); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jiin/stale/internal/config"
+	"github.com/jiin/stale/internal/database"
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/render"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+const usage = `Usage: stale [command] [flags]
+
+Without a command, stale starts the web server.
+
+Commands:
+  dependencies   List tracked dependencies
+  scans          List scan results
+  migrate        Show, apply or roll back database migrations
+  encrypt-tokens Encrypt source tokens stored in plaintext by older versions
+  agent          Scan sources in a private network and report them to a central server
+
+Run 'stale <command> -h' for the flags of a command.
+`
+
+// runCommand runs a CLI subcommand against the local database and returns the exit code
+func runCommand(cfg *config.Config, name string, args []string, stdout, stderr io.Writer) int {
+	var run func(ctx context.Context, db *sqlx.DB, args []string, stdout io.Writer) error
+	migrate := true
+	switch name {
+	case "dependencies", "deps":
+		run = runDependencies
+	case "scans":
+		run = runScans
+	case "migrate":
+		run, migrate = runMigrate, false
+	case "encrypt-tokens":
+		run = runEncryptTokens
+	case "agent":
+		// Agents report to a central server and do not use a local database
+		return runAgent(cfg, args, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", name, usage)
+		return 2
+	}
+
+	db, err := database.New(cfg.DatabasePath)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to open database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	if migrate {
+		if err := database.Migrate(db); err != nil {
+			fmt.Fprintf(stderr, "failed to run migrations: %v\n", err)
+			return 1
+		}
+	}
+
+	if err := run(context.Background(), db, args, stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// outputFlag registers the --output flag shared by all listing commands
+func outputFlag(fs *flag.FlagSet) *string {
+	return fs.String("output", "table", "output format: json, table or csv")
+}
+
+func runDependencies(ctx context.Context, db *sqlx.DB, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("dependencies", flag.ContinueOnError)
+	output := outputFlag(fs)
+	outdated := fs.Bool("outdated", false, "only list outdated dependencies")
+	repo := fs.String("repo", "", "only list dependencies of this repository (owner/name)")
+	ecosystem := fs.String("ecosystem", "", "only list dependencies of this ecosystem (npm, maven, gradle, go)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := render.ParseFormat(*output)
+	if err != nil {
+		return err
+	}
+
+	filter := ""
+	if *outdated {
+		filter = "upgradable"
+	}
+	deps, err := repository.NewDependencyRepository(db).GetFilteredWithAll(ctx, filter, *repo, "", *ecosystem, "")
+	if err != nil {
+		return err
+	}
+	if deps == nil {
+		deps = []domain.DependencyWithRepo{}
+	}
+
+	return render.Write(stdout, format, dependencyList(deps))
+}
+
+func runScans(ctx context.Context, db *sqlx.DB, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("scans", flag.ContinueOnError)
+	output := outputFlag(fs)
+	limit := fs.Int("limit", 20, "maximum number of scans to list (0 for all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := render.ParseFormat(*output)
+	if err != nil {
+		return err
+	}
+
+	scans, err := repository.NewScanRepository(db).GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	if *limit > 0 && len(scans) > *limit {
+		scans = scans[:*limit]
+	}
+	if scans == nil {
+		scans = []domain.ScanJob{}
+	}
+
+	return render.Write(stdout, format, scanList(scans))
+}
+
+// runMigrate shows the migrations with whether they were applied (status, the default), applies
+// the pending ones (up) or rolls back the latest ones (down --steps N)
+// runEncryptTokens encrypts plaintext source tokens once, with the key in STALE_ENCRYPTION_KEY
+func runEncryptTokens(ctx context.Context, db *sqlx.DB, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("encrypt-tokens", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	n, err := repository.NewSourceRepository(db).EncryptPlaintextTokens(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "encrypted %d plaintext source tokens\n", n)
+	return nil
+}
+
+func runMigrate(ctx context.Context, db *sqlx.DB, args []string, stdout io.Writer) error {
+	action := "status"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	output := outputFlag(fs)
+	steps := fs.Int("steps", 1, "number of migrations rolled back by down")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch action {
+	case "status":
+		format, err := render.ParseFormat(*output)
+		if err != nil {
+			return err
+		}
+		statuses, err := database.Status(db)
+		if err != nil {
+			return err
+		}
+		return render.Write(stdout, format, migrationList(statuses))
+
+	case "up":
+		pending, err := database.Pending(db)
+		if err != nil {
+			return err
+		}
+		if err := database.Migrate(db); err != nil {
+			return err
+		}
+		for _, m := range pending {
+			fmt.Fprintf(stdout, "applied %s\n", m.Name)
+		}
+
+	case "down":
+		if *steps < 1 {
+			return errors.New("--steps must be at least 1")
+		}
+		reverted, err := database.Rollback(db, *steps)
+		for _, m := range reverted {
+			fmt.Fprintf(stdout, "rolled back %s\n", m.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unknown migrate action %q: use status, up or down", action)
+	}
+
+	version, err := database.Version(db)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "schema version %d\n", version)
+	return nil
+}
+
+// dependencyList renders dependencies as rows
+type dependencyList []domain.DependencyWithRepo
+
+func (l dependencyList) Headers() []string {
+	return []string{"REPOSITORY", "MANIFEST", "DEPENDENCY", "ECOSYSTEM", "TYPE", "CURRENT", "LATEST", "OUTDATED"}
+}
+
+func (l dependencyList) Rows() [][]string {
+	rows := make([][]string, len(l))
+	for i, dep := range l {
+		rows[i] = []string{
+			dep.RepoFullName,
+			dep.ManifestPath,
+			dep.Name,
+			dep.Ecosystem,
+			dep.Type,
+			dep.CurrentVersion,
+			dep.LatestVersion,
+			strconv.FormatBool(dep.IsOutdated),
+		}
+	}
+	return rows
+}
+
+// scanList renders scan jobs as rows
+type scanList []domain.ScanJob
+
+func (l scanList) Headers() []string {
+	return []string{"ID", "STATUS", "STARTED", "DURATION", "REPOS", "DEPENDENCIES", "NEW REPOS", "REMOVED REPOS", "API CALLS", "BYTES FETCHED", "ERROR"}
+}
+
+func (l scanList) Rows() [][]string {
+	rows := make([][]string, len(l))
+	for i, scan := range l {
+		started, duration, scanErr := "", "", ""
+		if scan.StartedAt != nil {
+			started = scan.StartedAt.Format(time.RFC3339)
+			if scan.FinishedAt != nil {
+				duration = scan.FinishedAt.Sub(*scan.StartedAt).Round(time.Second).String()
+			}
+		}
+		if scan.Error != nil {
+			scanErr = *scan.Error
+		}
+		rows[i] = []string{
+			strconv.FormatInt(scan.ID, 10),
+			string(scan.Status),
+			started,
+			duration,
+			strconv.Itoa(scan.ReposFound),
+			strconv.Itoa(scan.DepsFound),
+			strconv.Itoa(scan.ReposNew),
+			strconv.Itoa(scan.ReposRemoved),
+			strconv.FormatInt(scan.APICalls, 10),
+			strconv.FormatInt(scan.BytesFetched, 10),
+			scanErr,
+		}
+	}
+	return rows
+}
+
+// migrationList renders migrations with whether they were applied as rows
+type migrationList []database.MigrationStatus
+
+func (l migrationList) Headers() []string {
+	return []string{"VERSION", "MIGRATION", "APPLIED", "REVERSIBLE"}
+}
+
+func (l migrationList) Rows() [][]string {
+	rows := make([][]string, len(l))
+	for i, m := range l {
+		applied := "pending"
+		if m.AppliedAt != nil {
+			applied = m.AppliedAt.Format(time.RFC3339)
+		}
+		rows[i] = []string{
+			strconv.Itoa(m.Version),
+			strings.TrimPrefix(m.Name, "migrations/"),
+			applied,
+			strconv.FormatBool(m.Reversible()),
+		}
+	}
+	return rows
+}
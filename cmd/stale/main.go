@@ -9,18 +9,23 @@ import (
 	"time"
 
 	"github.com/jiin/stale/internal/api"
+	"github.com/jiin/stale/internal/buildinfo"
 	"github.com/jiin/stale/internal/config"
 	"github.com/jiin/stale/internal/database"
 	"github.com/jiin/stale/internal/repository"
 	"github.com/jiin/stale/internal/service/email"
+	"github.com/jiin/stale/internal/service/feature"
+	"github.com/jiin/stale/internal/service/httputil"
+	"github.com/jiin/stale/internal/service/observability"
+	"github.com/jiin/stale/internal/service/outbox"
 	"github.com/jiin/stale/internal/service/scanner"
 	"github.com/jiin/stale/internal/service/scheduler"
+	"github.com/jiin/stale/internal/service/tracing"
+	"github.com/jiin/stale/internal/service/update"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
-var Version = "0.2.0"
-
 func main() {
 	// Load configuration
 	cfg := config.Load()
@@ -28,8 +33,23 @@ func main() {
 	// Setup logging
 	setupLogging(cfg.LogLevel)
 
+	// Identify outbound requests to registries and providers
+	httputil.SetUserAgent(buildinfo.Version, cfg.InstanceName, cfg.ContactURL)
+	httputil.SetTransportConfig(httputil.TransportConfig{
+		MaxConnsPerHost:         cfg.HTTPMaxConnsPerHost,
+		ProviderMaxConnsPerHost: cfg.HTTPProviderMaxConnsPerHost,
+		HTTP2:                   cfg.HTTP2,
+	})
+
+	// Subcommands (e.g. `stale dependencies --output json`) run against the database and exit
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(cfg, os.Args[1], os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	log.Info().
-		Str("version", Version).
+		Str("version", buildinfo.Version).
+		Str("commit", buildinfo.Get().Commit).
+		Str("user_agent", httputil.UserAgent()).
 		Str("port", cfg.Port).
 		Str("db_path", cfg.DatabasePath).
 		Msg("starting stale")
@@ -41,12 +61,37 @@ func main() {
 	}
 	defer db.Close()
 
-	// Run migrations
-	if err := database.Migrate(db); err != nil {
-		log.Fatal().Err(err).Msg("failed to run migrations")
+	// Run migrations, or refuse to start on a schema that is behind when they are applied separately.
+	// Read-only replicas leave the schema to the instance writing the database.
+	if cfg.AutoMigrate && !cfg.ReadOnly {
+		if err := database.Migrate(db); err != nil {
+			log.Fatal().Err(err).Msg("failed to run migrations")
+		}
+	} else {
+		pending, err := database.Pending(db)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to check migrations")
+		}
+		if len(pending) > 0 {
+			log.Fatal().Int("pending", len(pending)).Str("next", pending[0].Name).
+				Msg("database has pending migrations - run `stale migrate up` or set STALE_AUTO_MIGRATE=true")
+		}
 	}
 
-	log.Info().Msg("database initialized")
+	version, err := database.Version(db)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to read schema version")
+	}
+	log.Info().Int("schema_version", version).Msg("database initialized")
+
+	// Tracing is exported over OTLP when OTEL_EXPORTER_OTLP_ENDPOINT is set
+	shutdownTracing, err := tracing.Setup(context.Background(), buildinfo.Version)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to set up tracing")
+	}
+	if tracing.Enabled() {
+		log.Info().Msg("OpenTelemetry tracing enabled")
+	}
 
 	// Initialize repositories
 	sourceRepo := repository.NewSourceRepository(db)
@@ -54,17 +99,49 @@ func main() {
 	depRepo := repository.NewDependencyRepository(db)
 	scanRepo := repository.NewScanRepository(db)
 	settingsRepo := repository.NewSettingsRepository(db)
+	ignoredRepo := repository.NewIgnoredRepository(db)
+	alertRepo := repository.NewAlertRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	viewRepo := repository.NewViewRepository(db)
+	snapshotRepo := repository.NewSnapshotRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	featureFlags := feature.New(repository.NewFeatureFlagRepository(db))
+
+	if cfg.ReadOnly {
+		log.Info().Msg("read-only mode - scheduler disabled and mutating endpoints rejected")
+	}
 
 	// Initialize services
 	emailService := email.New()
-	scannerService := scanner.New(sourceRepo, repoRepo, depRepo, scanRepo)
-	schedulerService := scheduler.New(scannerService, scanRepo, depRepo, settingsRepo, emailService)
+	scannerService := scanner.New(sourceRepo, repoRepo, depRepo, scanRepo, alertRepo, ignoredRepo, featureFlags, cfg.OwnershipFile, cfg.SPIToken)
+	scannerService.SetDeterministic(cfg.DeterministicScans)
+	scannerService.SetManifestSnapshots(cfg.ManifestSnapshots, cfg.CompressManifests)
+	scannerService.SetOutbox(outboxRepo)
+	schedulerService := scheduler.New(scannerService, scanRepo, sourceRepo, depRepo, ignoredRepo, settingsRepo, notificationRepo, viewRepo, snapshotRepo, emailService, observability.New())
+	schedulerService.SetPrefetch(cfg.PrefetchPackages)
+	schedulerService.SetOutbox(outboxRepo)
 
 	// Start background scheduler
-	go schedulerService.Start()
+	if !cfg.ReadOnly {
+		go schedulerService.Start()
+	}
+
+	// Check the GitHub releases for a newer version of stale itself
+	updateChecker := update.New(buildinfo.Version, time.Duration(max(cfg.UpdateCheckIntervalHours, 1))*time.Hour)
+	if cfg.UpdateCheck {
+		go updateChecker.Start()
+	}
+
+	// Relay integration events to NATS; replicas leave it to the instance writing the database
+	var outboxRelay *outbox.Relay
+	if cfg.OutboxNATSURL != "" && !cfg.ReadOnly {
+		outboxRelay = outbox.NewRelay(outboxRepo, cfg.OutboxNATSURL, cfg.OutboxSubjectPrefix)
+		go outboxRelay.Start()
+		log.Info().Str("subject_prefix", cfg.OutboxSubjectPrefix).Msg("relaying outbox events to NATS")
+	}
 
 	// Initialize router
-	app := api.NewRouter(db, schedulerService, emailService)
+	app := api.NewRouter(cfg, db, schedulerService, scannerService, emailService, featureFlags, updateChecker)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -92,6 +169,10 @@ func main() {
 
 	// Stop scheduler
 	schedulerService.Stop()
+	updateChecker.Stop()
+	if outboxRelay != nil {
+		outboxRelay.Stop()
+	}
 
 	// Stop rate limiter cleanup goroutine
 	app.Stop()
@@ -104,6 +185,11 @@ func main() {
 		log.Error().Err(err).Msg("server shutdown error")
 	}
 
+	// Flush pending spans
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("tracing shutdown error")
+	}
+
 	log.Info().Msg("server stopped")
 }
 
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/jiin/stale/internal/service/scanner"
+	"github.com/jiin/stale/internal/service/scheduler"
+)
+
+// AdhocScanHandler scans a single repository on demand without requiring a configured source
+type AdhocScanHandler struct {
+	sourceRepo *repository.SourceRepository
+	scanner    *scanner.Scanner
+	scheduler  *scheduler.Scheduler
+	onPersist  func() // called after results were persisted (e.g. to clear caches)
+}
+
+func NewAdhocScanHandler(sourceRepo *repository.SourceRepository, scanner *scanner.Scanner, scheduler *scheduler.Scheduler, onPersist func()) *AdhocScanHandler {
+	return &AdhocScanHandler{sourceRepo: sourceRepo, scanner: scanner, scheduler: scheduler, onPersist: onPersist}
+}
+
+// Scan scans the repository at the given URL immediately and returns its dependencies inline.
+// With persist set and a successful scan, a source limited to that repository is created (or
+// reused) and the results are stored. The repository is always read with the token of the request.
+func (h *AdhocScanHandler) Scan(w http.ResponseWriter, r *http.Request) {
+	LimitBody(r)
+	var input domain.AdhocScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	if input.URL == "" || input.Token == "" {
+		RespondBadRequest(w, "url and token are required")
+		return
+	}
+
+	sourceType, baseURL, fullName, err := parseRepositoryURL(input.URL, strings.ToLower(input.Type))
+	if err != nil {
+		RespondBadRequest(w, err.Error())
+		return
+	}
+
+	branch := strings.TrimSpace(input.Branch)
+	if !isValidBranchName(branch) {
+		RespondBadRequest(w, "invalid branch name")
+		return
+	}
+
+	ctx := r.Context()
+	if input.Persist && !checkWritable(w, ctx, h.scheduler) {
+		return
+	}
+	source := domain.Source{
+		Name:               fullName,
+		Type:               sourceType,
+		Token:              input.Token,
+		URL:                baseURL,
+		Repositories:       fullName,
+		InsecureSkipVerify: input.InsecureSkipVerify,
+	}
+
+	if msg, err := checkSourceAccess(ctx, domain.SourceInput{Type: source.Type, Token: source.Token, URL: source.URL, InsecureSkipVerify: source.InsecureSkipVerify}); err != nil {
+		RespondError(w, http.StatusBadRequest, msg, err)
+		return
+	}
+
+	result, err := h.scanner.ScanRepository(ctx, source, fullName, branch)
+	if err != nil {
+		RespondError(w, http.StatusBadGateway, "failed to scan repository", err)
+		return
+	}
+
+	if input.Persist {
+		persisted, err := h.findOrCreateSource(ctx, source)
+		if err != nil {
+			RespondInternalError(w, err)
+			return
+		}
+		if err := h.scanner.PersistRepositoryScan(ctx, persisted.ID, result); err != nil {
+			RespondInternalError(w, err)
+			return
+		}
+		if h.onPersist != nil {
+			h.onPersist()
+		}
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// checkWritable responds 503 and returns false while maintenance mode rejects writing scan results
+func checkWritable(w http.ResponseWriter, ctx context.Context, s *scheduler.Scheduler) bool {
+	if err := s.CheckWritable(ctx); err != nil {
+		if errors.Is(err, scheduler.ErrMaintenanceMode) {
+			RespondError(w, http.StatusServiceUnavailable, "scan results cannot be recorded during maintenance", nil)
+			return false
+		}
+		RespondInternalError(w, err)
+		return false
+	}
+	return true
+}
+
+// findOrCreateSource returns the existing source limited to the same repository, or creates one
+// with the token of the request. The token of an existing source is kept.
+func (h *AdhocScanHandler) findOrCreateSource(ctx context.Context, source domain.Source) (*domain.Source, error) {
+	sources, err := h.sourceRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sources {
+		s := sources[i]
+		if s.Type == source.Type && s.URL == source.URL && s.Repositories == source.Repositories {
+			return &s, nil
+		}
+	}
+
+	return h.sourceRepo.Create(ctx, domain.SourceInput{
+		Name:               source.Name,
+		Type:               source.Type,
+		Token:              source.Token,
+		URL:                source.URL,
+		Repositories:       source.Repositories,
+		InsecureSkipVerify: source.InsecureSkipVerify,
+	})
+}
+
+// parseRepositoryURL extracts the source type, API base URL (GitLab only) and full repository name
+// from a repository web URL. When sourceType is empty, github.com URLs are treated as GitHub and
+// all other hosts as (self-hosted) GitLab.
+func parseRepositoryURL(rawURL, sourceType string) (string, string, string, error) {
+	parsedURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return "", "", "", errors.New("invalid repository URL")
+	}
+
+	host := strings.ToLower(parsedURL.Host)
+	isGitHub := host == "github.com" || host == "www.github.com"
+
+	if sourceType == "" {
+		sourceType = "gitlab"
+		if isGitHub {
+			sourceType = "github"
+		}
+	}
+
+	repoPath := strings.Trim(parsedURL.Path, "/")
+	// GitLab web URLs separate the project path from pages with "/-/" (e.g. /group/project/-/tree/main)
+	if idx := strings.Index(repoPath, "/-/"); idx != -1 {
+		repoPath = repoPath[:idx]
+	}
+	repoPath = strings.TrimSuffix(repoPath, ".git")
+
+	segments := strings.Split(repoPath, "/")
+	if len(segments) < 2 || segments[0] == "" || segments[1] == "" {
+		return "", "", "", errors.New("repository URL must include owner and repository name")
+	}
+
+	switch sourceType {
+	case "github":
+		if !isGitHub {
+			return "", "", "", errors.New("only github.com repositories are supported for type 'github'")
+		}
+		// Ignore trailing pages such as /tree/main
+		return "github", "", segments[0] + "/" + segments[1], nil
+	case "gitlab":
+		baseURL := parsedURL.Scheme + "://" + parsedURL.Host
+		if baseURL == "https://gitlab.com" {
+			baseURL = ""
+		}
+		return "gitlab", baseURL, repoPath, nil
+	default:
+		return "", "", "", errors.New("type must be 'github' or 'gitlab'")
+	}
+}
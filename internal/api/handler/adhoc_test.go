@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseRepositoryURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		sourceType string
+		wantType   string
+		wantBase   string
+		wantName   string
+		wantErr    bool
+	}{
+		{"github", "https://github.com/owner/repo", "", "github", "", "owner/repo", false},
+		{"github with tree page", "https://github.com/owner/repo/tree/main", "", "github", "", "owner/repo", false},
+		{"github .git suffix", "https://github.com/owner/repo.git", "", "github", "", "owner/repo", false},
+		{"gitlab.com subgroup", "https://gitlab.com/group/sub/project", "", "gitlab", "", "group/sub/project", false},
+		{"self-hosted gitlab page", "https://git.example.com/group/project/-/tree/main", "", "gitlab", "https://git.example.com", "group/project", false},
+		{"github type on other host", "https://git.example.com/owner/repo", "github", "", "", "", true},
+		{"missing repo name", "https://github.com/owner", "", "", "", "", true},
+		{"not http", "ssh://github.com/owner/repo", "", "", "", "", true},
+		{"unknown type", "https://github.com/owner/repo", "bitbucket", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sourceType, baseURL, fullName, err := parseRepositoryURL(tt.url, tt.sourceType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRepositoryURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if sourceType != tt.wantType || baseURL != tt.wantBase || fullName != tt.wantName {
+				t.Errorf("parseRepositoryURL(%q) = (%q, %q, %q), want (%q, %q, %q)",
+					tt.url, sourceType, baseURL, fullName, tt.wantType, tt.wantBase, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestAdhocScanHandler_Scan_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", "not json"},
+		{"missing token", `{"url": "https://github.com/owner/repo"}`},
+		{"missing url", `{"token": "ghp_test"}`},
+		{"invalid url", `{"url": "github.com", "token": "ghp_test"}`},
+		{"invalid branch", `{"url": "https://github.com/owner/repo", "token": "ghp_test", "branch": "bad..ref"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &AdhocScanHandler{} // nil scanner - testing validation only
+
+			req := httptest.NewRequest("POST", "/adhoc-scan", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			h.Scan(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
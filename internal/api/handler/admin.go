@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jiin/stale/internal/api/middleware"
+	"github.com/jiin/stale/internal/repository"
+)
+
+// Maintenance tasks, run in this order
+const (
+	MaintenanceOrphans  = "orphans"  // remove rows of deleted sources and repositories
+	MaintenanceOutdated = "outdated" // recompute is_outdated from the stored versions
+	MaintenanceOptimize = "optimize" // analyze and vacuum the database
+)
+
+var maintenanceTasks = []string{MaintenanceOrphans, MaintenanceOutdated, MaintenanceOptimize}
+
+type AdminHandler struct {
+	repo      *repository.MaintenanceRepository
+	recompute func(context.Context) (int64, error) // recomputes is_outdated, see Scheduler.RecomputeOutdated
+	onChanged func()                               // called after data was changed (e.g. to clear caches)
+}
+
+func NewAdminHandler(repo *repository.MaintenanceRepository, recompute func(context.Context) (int64, error), onChanged func()) *AdminHandler {
+	return &AdminHandler{repo: repo, recompute: recompute, onChanged: onChanged}
+}
+
+// MaintenanceRequest selects the tasks to run; all tasks run when none are given
+type MaintenanceRequest struct {
+	Tasks []string `json:"tasks"`
+}
+
+// MaintenanceReport describes what the maintenance tasks fixed
+type MaintenanceReport struct {
+	Tasks           []string         `json:"tasks"`
+	OrphansRemoved  map[string]int64 `json:"orphans_removed,omitempty"`
+	OutdatedUpdated int64            `json:"outdated_updated"`
+	Optimized       bool             `json:"optimized"`
+	DurationMs      int64            `json:"duration_ms"`
+}
+
+// Maintenance runs data integrity tasks and reports what was fixed. It requires the admin API key.
+func (h *AdminHandler) Maintenance(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		RespondForbidden(w, "maintenance requires the admin API key (STALE_ADMIN_API_KEY)")
+		return
+	}
+
+	LimitBody(r)
+	var req MaintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	tasks, err := selectMaintenanceTasks(req.Tasks)
+	if err != nil {
+		RespondBadRequest(w, err.Error())
+		return
+	}
+
+	start := time.Now()
+	report := MaintenanceReport{Tasks: tasks}
+	for _, task := range tasks {
+		switch task {
+		case MaintenanceOrphans:
+			report.OrphansRemoved, err = h.repo.DeleteOrphans(r.Context())
+		case MaintenanceOutdated:
+			report.OutdatedUpdated, err = h.recompute(r.Context())
+		case MaintenanceOptimize:
+			err = h.repo.Optimize(r.Context())
+			report.Optimized = err == nil
+		}
+		if err != nil {
+			RespondInternalError(w, fmt.Errorf("maintenance task %s: %w", task, err))
+			return
+		}
+	}
+	report.DurationMs = time.Since(start).Milliseconds()
+
+	if (len(report.OrphansRemoved) > 0 || report.OutdatedUpdated > 0) && h.onChanged != nil {
+		h.onChanged()
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
+
+// selectMaintenanceTasks validates the requested tasks and returns them in run order
+func selectMaintenanceTasks(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return maintenanceTasks, nil
+	}
+
+	want := make(map[string]bool, len(requested))
+	for _, task := range requested {
+		want[task] = true
+	}
+
+	var tasks []string
+	for _, task := range maintenanceTasks {
+		if want[task] {
+			tasks = append(tasks, task)
+			delete(want, task)
+		}
+	}
+	for task := range want {
+		return nil, fmt.Errorf("unknown maintenance task %q", task)
+	}
+	return tasks, nil
+}
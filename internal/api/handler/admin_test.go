@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/jiin/stale/internal/api/middleware"
+)
+
+func TestAdminHandler_Maintenance_RequiresAdmin(t *testing.T) {
+	h := NewAdminHandler(nil, nil, nil) // nil repo - the request is rejected first
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/maintenance", nil)
+	w := httptest.NewRecorder()
+
+	h.Maintenance(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestAdminHandler_Maintenance_Validation(t *testing.T) {
+	h := NewAdminHandler(nil, nil, nil) // nil repo - testing validation only
+	auth := middleware.Auth(middleware.AuthConfig{AdminAPIKey: "admin-key", Enabled: true})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", `{`},
+		{"unknown task", `{"tasks": ["orphans", "reindex"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/maintenance", bytes.NewBufferString(tt.body))
+			req.Header.Set("X-API-Key", "admin-key")
+			w := httptest.NewRecorder()
+
+			auth(http.HandlerFunc(h.Maintenance)).ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestSelectMaintenanceTasks(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested []string
+		want      []string
+	}{
+		{"all by default", nil, []string{MaintenanceOrphans, MaintenanceOutdated, MaintenanceOptimize}},
+		{"run order", []string{MaintenanceOptimize, MaintenanceOrphans}, []string{MaintenanceOrphans, MaintenanceOptimize}},
+		{"duplicates", []string{MaintenanceOutdated, MaintenanceOutdated}, []string{MaintenanceOutdated}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectMaintenanceTasks(tt.requested)
+			if err != nil {
+				t.Fatalf("selectMaintenanceTasks() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectMaintenanceTasks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
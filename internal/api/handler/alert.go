@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+)
+
+var validSeverities = map[string]bool{
+	"low":      true,
+	"medium":   true,
+	"high":     true,
+	"critical": true,
+}
+
+// AlertHandler serves vulnerability alerts imported from GitHub Dependabot
+type AlertHandler struct {
+	repo *repository.AlertRepository
+}
+
+func NewAlertHandler(repo *repository.AlertRepository) *AlertHandler {
+	return &AlertHandler{repo: repo}
+}
+
+// List returns the alerts of all repositories, optionally filtered by severity
+func (h *AlertHandler) List(w http.ResponseWriter, r *http.Request) {
+	severity := strings.ToLower(r.URL.Query().Get("severity"))
+	if severity != "" && !validSeverities[severity] {
+		RespondBadRequest(w, "severity must be one of low, medium, high, critical")
+		return
+	}
+
+	alerts, err := h.repo.GetAll(r.Context(), severity)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if alerts == nil {
+		alerts = []domain.VulnerabilityAlertWithDependency{}
+	}
+	json.NewEncoder(w).Encode(alerts)
+}
+
+// ListByRepository returns the alerts of a repository
+func (h *AlertHandler) ListByRepository(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	alerts, err := h.repo.GetByRepoID(r.Context(), id)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if alerts == nil {
+		alerts = []domain.VulnerabilityAlertWithDependency{}
+	}
+	json.NewEncoder(w).Encode(alerts)
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestAlertHandler_List_InvalidSeverity(t *testing.T) {
+	h := &AlertHandler{} // nil repo - testing validation only
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/alerts?severity=urgent", nil)
+	w := httptest.NewRecorder()
+	h.List(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestAlertHandler_ListByRepository_InvalidID(t *testing.T) {
+	h := &AlertHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/repositories/abc/alerts", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "abc")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+	h.ListByRepository(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-chi/chi/v5"
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/jiin/stale/internal/service/scanner"
+)
+
+// CampaignHandler manages upgrade campaigns. Campaign progress is computed from the
+// dependencies found by the latest scans, so it updates automatically.
+type CampaignHandler struct {
+	repo    *repository.CampaignRepository
+	depRepo *repository.DependencyRepository
+}
+
+func NewCampaignHandler(repo *repository.CampaignRepository, depRepo *repository.DependencyRepository) *CampaignHandler {
+	return &CampaignHandler{repo: repo, depRepo: depRepo}
+}
+
+// List returns all campaigns with their progress (without per-repository details)
+func (h *CampaignHandler) List(w http.ResponseWriter, r *http.Request) {
+	campaigns, err := h.repo.GetAll(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	result := make([]domain.CampaignProgress, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		progress, err := h.progress(r, campaign)
+		if err != nil {
+			RespondInternalError(w, err)
+			return
+		}
+		progress.Repositories = nil
+		result = append(result, *progress)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// Get returns a campaign with the status of every repository using the package
+func (h *CampaignHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	campaign, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		RespondNotFound(w, "campaign not found")
+		return
+	}
+
+	progress, err := h.progress(r, *campaign)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(progress)
+}
+
+func (h *CampaignHandler) Create(w http.ResponseWriter, r *http.Request) {
+	LimitBody(r)
+	var input domain.CampaignInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	if msg := validateCampaignInput(&input); msg != "" {
+		RespondBadRequest(w, msg)
+		return
+	}
+
+	campaign, err := h.repo.Create(r.Context(), input)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(campaign)
+}
+
+func (h *CampaignHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	LimitBody(r)
+	var input domain.CampaignInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	if msg := validateCampaignInput(&input); msg != "" {
+		RespondBadRequest(w, msg)
+		return
+	}
+
+	campaign, err := h.repo.Update(r.Context(), id, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			RespondNotFound(w, "campaign not found")
+			return
+		}
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(campaign)
+}
+
+func (h *CampaignHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CampaignHandler) progress(r *http.Request, campaign domain.Campaign) (*domain.CampaignProgress, error) {
+	deps, err := h.depRepo.GetFilteredWithAll(r.Context(), "", "", campaign.PackageName, campaign.Ecosystem, "")
+	if err != nil {
+		return nil, err
+	}
+	return scanner.CampaignProgress(campaign, deps), nil
+}
+
+// validateCampaignInput trims the input and returns an error message if it is invalid
+func validateCampaignInput(input *domain.CampaignInput) string {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Ecosystem = strings.TrimSpace(input.Ecosystem)
+	input.PackageName = strings.TrimSpace(input.PackageName)
+	input.TargetVersion = strings.TrimSpace(input.TargetVersion)
+
+	if input.Name == "" || input.Ecosystem == "" || input.PackageName == "" || input.TargetVersion == "" {
+		return "name, ecosystem, package_name and target_version are required"
+	}
+	if _, err := semver.NewVersion(input.TargetVersion); err != nil {
+		return "target_version must be a valid version"
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestValidateCampaignInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input domain.CampaignInput
+		valid bool
+	}{
+		{"valid", domain.CampaignInput{Name: "React 18", Ecosystem: "npm", PackageName: "react", TargetVersion: "18.2.0"}, true},
+		{"trimmed", domain.CampaignInput{Name: " React 18 ", Ecosystem: "npm", PackageName: " react ", TargetVersion: " 18 "}, true},
+		{"missing name", domain.CampaignInput{Ecosystem: "npm", PackageName: "react", TargetVersion: "18.2.0"}, false},
+		{"missing package", domain.CampaignInput{Name: "React 18", Ecosystem: "npm", TargetVersion: "18.2.0"}, false},
+		{"invalid target", domain.CampaignInput{Name: "React 18", Ecosystem: "npm", PackageName: "react", TargetVersion: "next"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			msg := validateCampaignInput(&input)
+			if (msg == "") != tt.valid {
+				t.Errorf("validateCampaignInput() = %q, want valid=%v", msg, tt.valid)
+			}
+		})
+	}
+}
+
+func TestCampaignHandler_Create_Validation(t *testing.T) {
+	h := &CampaignHandler{} // nil repos - testing validation only
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", "{"},
+		{"missing fields", `{"name":"React 18"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/campaigns", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			h.Create(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jiin/stale/internal/api/middleware"
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/service/feature"
+	"github.com/jiin/stale/internal/service/scheduler"
+)
+
+// Roles of callers: anonymous when authentication is disabled, user with the API key and admin
+// with the admin API key
+const (
+	RoleAnonymous = "anonymous"
+	RoleUser      = "user"
+	RoleAdmin     = "admin"
+)
+
+// CapabilitiesHandler tells callers what they may do, so clients can adapt their UI instead of
+// finding out through 403s
+type CapabilitiesHandler struct {
+	features  Features
+	flags     *feature.Flags
+	scheduler *scheduler.Scheduler
+}
+
+func NewCapabilitiesHandler(features Features, flags *feature.Flags, scheduler *scheduler.Scheduler) *CapabilitiesHandler {
+	if features.Ecosystems == nil {
+		features.Ecosystems = []string{}
+	}
+	return &CapabilitiesHandler{features: features, flags: flags, scheduler: scheduler}
+}
+
+// Capabilities are the actions the caller may take and the features enabled on the instance
+type Capabilities struct {
+	Role                string          `json:"role"`
+	TriggerScans        bool            `json:"trigger_scans"`        // start scans and rescans
+	EditSettings        bool            `json:"edit_settings"`        // change settings, ignore rules, views and policies
+	ManageSources       bool            `json:"manage_sources"`       // create, change and delete sources
+	RevealTokens        bool            `json:"reveal_tokens"`        // read the tokens of sources
+	Maintenance         bool            `json:"maintenance"`          // run maintenance tasks
+	RecommendedVersions bool            `json:"recommended_versions"` // set recommended versions of packages
+	MaintenanceMode     bool            `json:"maintenance_mode"`     // scans are rejected until maintenance ends
+	Features            Features        `json:"features"`
+	FeatureFlags        map[string]bool `json:"feature_flags"`
+}
+
+// Get returns the capabilities of the caller
+func (h *CapabilitiesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	state, err := h.scheduler.State(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(capabilitiesFor(h.features, middleware.IsAdmin(r.Context()), state.InMaintenance(time.Now()), h.flags.List(r.Context())))
+}
+
+// capabilitiesFor returns the capabilities of a caller, admin when it authenticated with the
+// admin API key
+func capabilitiesFor(features Features, admin, maintenance bool, flags []domain.FeatureFlag) Capabilities {
+	role := RoleUser
+	switch {
+	case admin:
+		role = RoleAdmin
+	case !features.Auth:
+		role = RoleAnonymous
+	}
+
+	writable := !features.ReadOnly
+	c := Capabilities{
+		Role:                role,
+		TriggerScans:        writable && !maintenance,
+		EditSettings:        writable,
+		ManageSources:       writable,
+		RevealTokens:        admin,
+		Maintenance:         writable && admin,
+		RecommendedVersions: writable && admin,
+		MaintenanceMode:     maintenance,
+		Features:            features,
+		FeatureFlags:        make(map[string]bool, len(flags)),
+	}
+	for _, flag := range flags {
+		c.FeatureFlags[flag.Name] = flag.Enabled
+	}
+	return c
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestCapabilitiesFor(t *testing.T) {
+	flags := []domain.FeatureFlag{{Name: "vulnerability_alerts", Enabled: true}, {Name: "supply_chain_risks"}}
+
+	tests := []struct {
+		name        string
+		features    Features
+		admin       bool
+		maintenance bool
+		want        Capabilities
+	}{
+		{
+			name: "authentication disabled",
+			want: Capabilities{Role: RoleAnonymous, TriggerScans: true, EditSettings: true, ManageSources: true},
+		},
+		{
+			name:     "API key",
+			features: Features{Auth: true},
+			want:     Capabilities{Role: RoleUser, TriggerScans: true, EditSettings: true, ManageSources: true},
+		},
+		{
+			name:     "admin API key",
+			features: Features{Auth: true},
+			admin:    true,
+			want: Capabilities{Role: RoleAdmin, TriggerScans: true, EditSettings: true, ManageSources: true,
+				RevealTokens: true, Maintenance: true, RecommendedVersions: true},
+		},
+		{
+			name:        "maintenance mode",
+			features:    Features{Auth: true},
+			maintenance: true,
+			want:        Capabilities{Role: RoleUser, EditSettings: true, ManageSources: true, MaintenanceMode: true},
+		},
+		{
+			name:     "read-only admin",
+			features: Features{Auth: true, ReadOnly: true},
+			admin:    true,
+			want:     Capabilities{Role: RoleAdmin, RevealTokens: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := capabilitiesFor(tt.features, tt.admin, tt.maintenance, flags)
+			if !got.FeatureFlags["vulnerability_alerts"] || got.FeatureFlags["supply_chain_risks"] {
+				t.Errorf("feature flags = %v", got.FeatureFlags)
+			}
+			got.Features, got.FeatureFlags = Features{}, nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("capabilitiesFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,495 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/jiin/stale/internal/service/scheduler"
+	"github.com/robfig/cron/v3"
+)
+
+// ConfigHandler exports and applies the declarative instance configuration
+// (sources, scan schedule, ignore rules and failure notifications) so the instance can be managed as code
+type ConfigHandler struct {
+	sourceRepo   *repository.SourceRepository
+	configRepo   *repository.ConfigRepository
+	ignoredRepo  *repository.IgnoredRepository
+	settingsRepo *repository.SettingsRepository
+	events       *repository.EventRepository
+	scheduler    *scheduler.Scheduler
+}
+
+func NewConfigHandler(
+	sourceRepo *repository.SourceRepository,
+	configRepo *repository.ConfigRepository,
+	ignoredRepo *repository.IgnoredRepository,
+	settingsRepo *repository.SettingsRepository,
+	events *repository.EventRepository,
+	scheduler *scheduler.Scheduler,
+) *ConfigHandler {
+	return &ConfigHandler{
+		sourceRepo:   sourceRepo,
+		configRepo:   configRepo,
+		ignoredRepo:  ignoredRepo,
+		settingsRepo: settingsRepo,
+		events:       events,
+		scheduler:    scheduler,
+	}
+}
+
+// sourceUpdate is an existing source whose configuration differs from the document
+type sourceUpdate struct {
+	id    int64
+	input domain.SourceInput
+}
+
+// sourcePlan is the set of changes needed to reconcile sources with a document
+type sourcePlan struct {
+	create    []domain.SourceInput
+	update    []sourceUpdate
+	delete    []domain.Source
+	unchanged int
+}
+
+// ignoreUpdate is an existing ignore rule whose reason or expiry differs from the document
+type ignoreUpdate struct {
+	id    int64
+	input domain.IgnoredDependencyInput
+}
+
+// ignorePlan is the set of changes needed to reconcile ignore rules with a document
+type ignorePlan struct {
+	create    []domain.IgnoredDependencyInput
+	update    []ignoreUpdate
+	delete    []domain.IgnoredDependency
+	unchanged int
+}
+
+// Get exports the current configuration. Tokens and external sources, which are created by
+// ingesting dependencies, are not included.
+func (h *ConfigHandler) Get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sources, err := h.sourceRepo.GetAll(ctx)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	ignored, err := h.ignoredRepo.GetAll(ctx)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	settings, err := h.settingsRepo.Get(ctx)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	doc := domain.ConfigDocument{
+		Sources:  make([]domain.SourceInput, 0, len(sources)),
+		Schedule: &domain.ScheduleConfig{Enabled: settings.ScheduleEnabled, Cron: settings.ScheduleCron, Timezone: settings.ScheduleTimezone},
+		Ignores:  make([]domain.IgnoredDependencyInput, len(ignored)),
+		Notifications: &domain.NotificationsConfig{
+			FailureNotifyEmail: settings.FailureNotifyEmail,
+			FailureNotifyHours: settings.FailureNotifyHours,
+		},
+	}
+	if settings.FailureWebhookURL != "" {
+		doc.Notifications.FailureWebhookURL = maskedSecret
+	}
+	for _, s := range sources {
+		if s.External() {
+			continue
+		}
+		input := sourceToInput(s)
+		input.Token = ""
+		doc.Sources = append(doc.Sources, input)
+	}
+	for i, ig := range ignored {
+		doc.Ignores[i] = domain.IgnoredDependencyInput{Name: ig.Name, Ecosystem: ig.Ecosystem, Repository: ig.Repository, Reason: ig.Reason, ExpiresAt: ig.ExpiresAt}
+	}
+
+	json.NewEncoder(w).Encode(doc)
+}
+
+// Apply reconciles the instance with the posted document. Applying the same document twice is a no-op.
+// Sources and ignore rules missing from the document are only deleted with prune=true, and
+// dry_run=true reports the planned changes without applying them. The whole document is validated
+// (including source credentials of new and changed sources) before anything is changed, and the
+// changes are applied in a single transaction.
+func (h *ConfigHandler) Apply(w http.ResponseWriter, r *http.Request) {
+	LimitBody(r)
+	var doc domain.ConfigDocument
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields() // catch typos in hand-written documents
+	if err := decoder.Decode(&doc); err != nil {
+		RespondBadRequest(w, "invalid config document: "+err.Error())
+		return
+	}
+
+	prune := r.URL.Query().Get("prune") == "true"
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	// Exported documents have no tokens, so existing sources keep their stored token
+	ctx := r.Context()
+	var sources []domain.Source
+	sourcesLoaded := false
+	if hasMissingToken(doc.Sources) {
+		var err error
+		if sources, err = h.sourceRepo.GetAll(ctx); err != nil {
+			RespondInternalError(w, err)
+			return
+		}
+		sourcesLoaded = true
+		fillSourceTokens(doc.Sources, sources)
+	}
+
+	if msg := validateConfigDocument(&doc); msg != "" {
+		RespondBadRequest(w, msg)
+		return
+	}
+
+	if !sourcesLoaded {
+		var err error
+		if sources, err = h.sourceRepo.GetAll(ctx); err != nil {
+			RespondInternalError(w, err)
+			return
+		}
+	}
+	ignored, err := h.ignoredRepo.GetAll(ctx)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	settings, err := h.settingsRepo.Get(ctx)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	sp := planSources(sources, doc.Sources, prune)
+	ip := planIgnores(ignored, doc.Ignores, prune)
+	scheduleChanged := doc.Schedule != nil &&
+		(doc.Schedule.Enabled != settings.ScheduleEnabled || doc.Schedule.Cron != settings.ScheduleCron ||
+			doc.Schedule.Timezone != settings.ScheduleTimezone)
+	notifications := doc.Notifications
+	if notifications != nil && notifications.FailureWebhookURL == maskedSecret {
+		notifications.FailureWebhookURL = settings.FailureWebhookURL
+	}
+	notificationsChanged := notifications != nil &&
+		(notifications.FailureNotifyEmail != settings.FailureNotifyEmail || notifications.FailureWebhookURL != settings.FailureWebhookURL ||
+			notifications.FailureNotifyHours != settings.FailureNotifyHours)
+
+	// Check credentials of new and changed sources before changing anything
+	for _, input := range sp.create {
+		if msg, err := checkSourceAccess(ctx, input); err != nil {
+			RespondError(w, http.StatusBadRequest, fmt.Sprintf("source %q: %s", input.Name, msg), err)
+			return
+		}
+	}
+	for _, u := range sp.update {
+		if msg, err := checkSourceAccess(ctx, u.input); err != nil {
+			RespondError(w, http.StatusBadRequest, fmt.Sprintf("source %q: %s", u.input.Name, msg), err)
+			return
+		}
+	}
+
+	result := domain.ConfigApplyResult{
+		DryRun:               dryRun,
+		Sources:              domain.ConfigChanges{Created: []string{}, Updated: []string{}, Deleted: []string{}, Unchanged: sp.unchanged},
+		Ignores:              domain.ConfigChanges{Created: []string{}, Updated: []string{}, Deleted: []string{}, Unchanged: ip.unchanged},
+		ScheduleChanged:      scheduleChanged,
+		NotificationsChanged: notificationsChanged,
+	}
+	for _, input := range sp.create {
+		result.Sources.Created = append(result.Sources.Created, input.Name)
+	}
+	for _, u := range sp.update {
+		result.Sources.Updated = append(result.Sources.Updated, u.input.Name)
+	}
+	for _, s := range sp.delete {
+		result.Sources.Deleted = append(result.Sources.Deleted, s.Name)
+	}
+	for _, input := range ip.create {
+		result.Ignores.Created = append(result.Ignores.Created, ignoreLabel(input.Name, input.Ecosystem, input.Repository))
+	}
+	for _, u := range ip.update {
+		result.Ignores.Updated = append(result.Ignores.Updated, ignoreLabel(u.input.Name, u.input.Ecosystem, u.input.Repository))
+	}
+	for _, ig := range ip.delete {
+		result.Ignores.Deleted = append(result.Ignores.Deleted, ignoreLabel(ig.Name, ig.Ecosystem, ig.Repository))
+	}
+
+	if dryRun {
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	changes := repository.ConfigChangeSet{CreateSources: sp.create, CreateIgnores: ip.create}
+	for _, u := range sp.update {
+		changes.UpdateSources = append(changes.UpdateSources, repository.SourceUpdate{ID: u.id, Input: u.input})
+	}
+	for _, s := range sp.delete {
+		changes.DeleteSources = append(changes.DeleteSources, s.ID)
+	}
+	for _, u := range ip.update {
+		changes.UpdateIgnores = append(changes.UpdateIgnores, repository.IgnoreUpdate{ID: u.id, Input: u.input})
+	}
+	for _, ig := range ip.delete {
+		changes.DeleteIgnores = append(changes.DeleteIgnores, ig.ID)
+	}
+	if scheduleChanged || notificationsChanged {
+		changes.Settings = &domain.SettingsInput{}
+	}
+	if scheduleChanged {
+		changes.Settings.ScheduleEnabled = &doc.Schedule.Enabled
+		changes.Settings.ScheduleCron = &doc.Schedule.Cron
+		changes.Settings.ScheduleTimezone = &doc.Schedule.Timezone
+	}
+	if notificationsChanged {
+		changes.Settings.FailureNotifyEmail = &notifications.FailureNotifyEmail
+		changes.Settings.FailureWebhookURL = &notifications.FailureWebhookURL
+		changes.Settings.FailureNotifyHours = &notifications.FailureNotifyHours
+	}
+
+	createdSources, createdIgnores, err := h.configRepo.Apply(ctx, changes)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	for _, source := range createdSources {
+		recordEvent(ctx, h.events, domain.EventSourceCreated, source.ID, source.Name, nil)
+	}
+	for _, s := range sp.delete {
+		recordEvent(ctx, h.events, domain.EventSourceDeleted, s.ID, s.Name, sourceToInput(s))
+	}
+	for _, ig := range createdIgnores {
+		recordEvent(ctx, h.events, domain.EventIgnoreCreated, ig.ID, ignoreLabel(ig.Name, ig.Ecosystem, ig.Repository), nil)
+	}
+	for _, ig := range ip.delete {
+		recordEvent(ctx, h.events, domain.EventIgnoreDeleted, ig.ID, ignoreLabel(ig.Name, ig.Ecosystem, ig.Repository), ignoreToInput(ig))
+	}
+
+	if len(sp.create)+len(sp.update)+len(sp.delete) > 0 {
+		h.scheduler.ReloadSourceSchedules()
+	}
+	if scheduleChanged {
+		h.scheduler.ReloadSchedule()
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// validateConfigDocument normalizes the document and returns a message describing
+// the first problem, or an empty string when the document is valid
+func validateConfigDocument(doc *domain.ConfigDocument) string {
+	names := make(map[string]bool)
+	for i := range doc.Sources {
+		input := &doc.Sources[i]
+		input.Name = strings.TrimSpace(input.Name)
+		if msg := validateSourceInput(input); msg != "" {
+			return fmt.Sprintf("sources[%d]: %s", i, msg)
+		}
+		if names[input.Name] {
+			return fmt.Sprintf("sources[%d]: duplicate source name %q", i, input.Name)
+		}
+		names[input.Name] = true
+	}
+
+	ignores := make(map[string]bool)
+	for i := range doc.Ignores {
+		ig := &doc.Ignores[i]
+		ig.Name = strings.TrimSpace(ig.Name)
+		ig.Ecosystem = strings.TrimSpace(ig.Ecosystem)
+		if ig.Name == "" {
+			return fmt.Sprintf("ignores[%d]: name is required", i)
+		}
+		key := ignoreLabel(ig.Name, ig.Ecosystem, ig.Repository)
+		if ignores[key] {
+			return fmt.Sprintf("ignores[%d]: duplicate ignore rule %q", i, key)
+		}
+		ignores[key] = true
+	}
+
+	if doc.Schedule != nil {
+		parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+		if _, err := parser.Parse(doc.Schedule.Cron); err != nil {
+			return "schedule: invalid cron expression"
+		}
+		doc.Schedule.Timezone = strings.TrimSpace(doc.Schedule.Timezone)
+		if _, err := scheduler.LoadTimezone(doc.Schedule.Timezone); err != nil {
+			return "schedule: " + err.Error()
+		}
+	}
+
+	if n := doc.Notifications; n != nil {
+		n.FailureWebhookURL = strings.TrimSpace(n.FailureWebhookURL)
+		input := domain.SettingsInput{FailureWebhookURL: &n.FailureWebhookURL, FailureNotifyHours: &n.FailureNotifyHours}
+		if diagnostics := validateSettings(&input); len(diagnostics) > 0 {
+			return "notifications: " + diagnostics[0].Message
+		}
+	}
+
+	return ""
+}
+
+func hasMissingToken(inputs []domain.SourceInput) bool {
+	for _, input := range inputs {
+		if input.Token == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// fillSourceTokens copies the stored token of existing sources (matched by name) into inputs without
+// a token. Inputs pointing at another server or other repositories get no token, so a document
+// cannot send a stored token elsewhere.
+func fillSourceTokens(inputs []domain.SourceInput, existing []domain.Source) {
+	byName := make(map[string]domain.Source, len(existing))
+	for _, s := range existing {
+		byName[s.Name] = s
+	}
+	for i := range inputs {
+		if inputs[i].Token != "" {
+			continue
+		}
+		if s, ok := byName[strings.TrimSpace(inputs[i].Name)]; ok && sameTokenTarget(s, inputs[i]) {
+			inputs[i].Token = s.Token
+		}
+	}
+}
+
+// planSources matches existing sources to the desired ones by name
+func planSources(existing []domain.Source, desired []domain.SourceInput, prune bool) sourcePlan {
+	var plan sourcePlan
+
+	// External sources are created by ingesting dependencies and are not part of the configuration
+	configured := make([]domain.Source, 0, len(existing))
+	byName := make(map[string]domain.Source, len(existing))
+	for _, s := range existing {
+		if s.External() {
+			continue
+		}
+		configured = append(configured, s)
+		byName[s.Name] = s
+	}
+
+	wanted := make(map[string]bool, len(desired))
+	for _, input := range desired {
+		wanted[input.Name] = true
+		current, ok := byName[input.Name]
+		switch {
+		case !ok:
+			plan.create = append(plan.create, input)
+		case sourceToInput(current) != input:
+			plan.update = append(plan.update, sourceUpdate{id: current.ID, input: input})
+		default:
+			plan.unchanged++
+		}
+	}
+
+	if prune {
+		for _, s := range configured {
+			if !wanted[s.Name] {
+				plan.delete = append(plan.delete, s)
+			}
+		}
+	}
+
+	return plan
+}
+
+// planIgnores matches existing ignore rules to the desired ones by name, ecosystem and repository
+func planIgnores(existing []domain.IgnoredDependency, desired []domain.IgnoredDependencyInput, prune bool) ignorePlan {
+	var plan ignorePlan
+
+	current := make(map[string]domain.IgnoredDependency, len(existing))
+	for _, ig := range existing {
+		current[ignoreLabel(ig.Name, ig.Ecosystem, ig.Repository)] = ig
+	}
+
+	wanted := make(map[string]bool, len(desired))
+	for _, input := range desired {
+		key := ignoreLabel(input.Name, input.Ecosystem, input.Repository)
+		wanted[key] = true
+		ig, ok := current[key]
+		switch {
+		case !ok:
+			plan.create = append(plan.create, input)
+		case ig.Reason != input.Reason || !sameExpiry(ig.ExpiresAt, input.ExpiresAt):
+			plan.update = append(plan.update, ignoreUpdate{id: ig.ID, input: input})
+		default:
+			plan.unchanged++
+		}
+	}
+
+	if prune {
+		for _, ig := range existing {
+			if !wanted[ignoreLabel(ig.Name, ig.Ecosystem, ig.Repository)] {
+				plan.delete = append(plan.delete, ig)
+			}
+		}
+	}
+
+	return plan
+}
+
+// sameExpiry reports whether two optional expiry times are the same instant
+func sameExpiry(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func sourceToInput(s domain.Source) domain.SourceInput {
+	return domain.SourceInput{
+		Name:                   s.Name,
+		Type:                   s.Type,
+		Token:                  s.Token,
+		Organization:           s.Organization,
+		URL:                    s.URL,
+		Repositories:           s.Repositories,
+		ScanBranch:             s.ScanBranch,
+		AdditionalBranches:     s.AdditionalBranches,
+		FetchMode:              s.FetchMode,
+		InsecureSkipVerify:     s.InsecureSkipVerify,
+		MembershipOnly:         s.MembershipOnly,
+		OwnerOnly:              s.OwnerOnly,
+		ImportDependabotAlerts: s.ImportDependabotAlerts,
+		ScheduleCron:           s.ScheduleCron,
+		ScanWindow:             s.ScanWindow,
+		ScheduleTimezone:       s.ScheduleTimezone,
+		Priority:               s.Priority,
+	}
+}
+
+func ignoreToInput(ig domain.IgnoredDependency) domain.IgnoredDependencyInput {
+	return domain.IgnoredDependencyInput{
+		Name:       ig.Name,
+		Ecosystem:  ig.Ecosystem,
+		Reason:     ig.Reason,
+		Repository: ig.Repository,
+		ExpiresAt:  ig.ExpiresAt,
+	}
+}
+
+// ignoreLabel identifies an ignore rule, e.g. "lodash", "lodash (npm)" or "lodash (npm) in acme/web"
+func ignoreLabel(name, ecosystem, repository string) string {
+	label := name
+	if ecosystem != "" {
+		label += " (" + ecosystem + ")"
+	}
+	if repository != "" {
+		label += " in " + repository
+	}
+	return label
+}
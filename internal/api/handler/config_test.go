@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestValidateConfigDocument(t *testing.T) {
+	valid := func() domain.ConfigDocument {
+		return domain.ConfigDocument{
+			Sources: []domain.SourceInput{
+				{Name: "github-org", Type: "GitHub", Token: "ghp_x", Organization: "org"},
+				{Name: "gitlab", Type: "gitlab", Token: "glpat", URL: "https://gitlab.example.com"},
+			},
+			Schedule: &domain.ScheduleConfig{Enabled: true, Cron: "0 9 * * 1"},
+			Ignores:  []domain.IgnoredDependencyInput{{Name: "lodash"}, {Name: "lodash", Ecosystem: "npm"}},
+		}
+	}
+
+	doc := valid()
+	if msg := validateConfigDocument(&doc); msg != "" {
+		t.Fatalf("validateConfigDocument() = %q, want valid", msg)
+	}
+	if doc.Sources[0].Type != "github" {
+		t.Errorf("expected source type to be normalized, got %q", doc.Sources[0].Type)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*domain.ConfigDocument)
+		want   string
+	}{
+		{"invalid source", func(d *domain.ConfigDocument) { d.Sources[1].Token = "" }, "sources[1]"},
+		{"duplicate source", func(d *domain.ConfigDocument) { d.Sources[1].Name = "github-org" }, "duplicate source name"},
+		{"ignore without name", func(d *domain.ConfigDocument) { d.Ignores[0].Name = " " }, "ignores[0]"},
+		{"duplicate ignore", func(d *domain.ConfigDocument) { d.Ignores[1].Ecosystem = "" }, "duplicate ignore rule"},
+		{"invalid cron", func(d *domain.ConfigDocument) { d.Schedule.Cron = "daily" }, "invalid cron"},
+		{"invalid webhook", func(d *domain.ConfigDocument) {
+			d.Notifications = &domain.NotificationsConfig{FailureWebhookURL: "ftp://hooks.example.com"}
+		}, "notifications: failure_webhook_url"},
+		{"invalid notify hours", func(d *domain.ConfigDocument) {
+			d.Notifications = &domain.NotificationsConfig{FailureNotifyHours: 200}
+		}, "notifications: failure_notify_hours"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := valid()
+			tt.mutate(&doc)
+			if msg := validateConfigDocument(&doc); !strings.Contains(msg, tt.want) {
+				t.Errorf("validateConfigDocument() = %q, want message containing %q", msg, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanSources(t *testing.T) {
+	existing := []domain.Source{
+		{ID: 1, Name: "same", Type: "github", Token: "t1", Organization: "a"},
+		{ID: 2, Name: "changed", Type: "github", Token: "t2", Organization: "b"},
+		{ID: 3, Name: "extra", Type: "gitlab", Token: "t3"},
+	}
+	desired := []domain.SourceInput{
+		{Name: "same", Type: "github", Token: "t1", Organization: "a"},
+		{Name: "changed", Type: "github", Token: "t2", Organization: "b", ScanBranch: "develop"},
+		{Name: "new", Type: "github", Token: "t4"},
+	}
+
+	plan := planSources(existing, desired, false)
+	if len(plan.create) != 1 || plan.create[0].Name != "new" {
+		t.Errorf("create = %+v", plan.create)
+	}
+	if len(plan.update) != 1 || plan.update[0].id != 2 {
+		t.Errorf("update = %+v", plan.update)
+	}
+	if plan.unchanged != 1 || len(plan.delete) != 0 {
+		t.Errorf("unchanged = %d, delete = %+v", plan.unchanged, plan.delete)
+	}
+
+	pruned := planSources(existing, desired, true)
+	if len(pruned.delete) != 1 || pruned.delete[0].ID != 3 {
+		t.Errorf("delete with prune = %+v", pruned.delete)
+	}
+}
+
+func TestPlanIgnores(t *testing.T) {
+	expires := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	existing := []domain.IgnoredDependency{
+		{ID: 1, Name: "lodash", Ecosystem: "npm"},
+		{ID: 2, Name: "junit"},
+		{ID: 3, Name: "vue", Reason: "legacy", ExpiresAt: &expires},
+	}
+	later := expires.AddDate(0, 1, 0)
+	desired := []domain.IgnoredDependencyInput{
+		{Name: "lodash", Ecosystem: "npm", Reason: "pinned"},
+		{Name: "react"},
+		{Name: "vue", Reason: "legacy", ExpiresAt: &later},
+	}
+
+	plan := planIgnores(existing, desired, true)
+	if len(plan.create) != 1 || plan.create[0].Name != "react" {
+		t.Errorf("create = %+v", plan.create)
+	}
+	if len(plan.delete) != 1 || plan.delete[0].ID != 2 {
+		t.Errorf("delete = %+v", plan.delete)
+	}
+	if len(plan.update) != 2 || plan.update[0].id != 1 || plan.update[1].id != 3 {
+		t.Errorf("update = %+v, want the changed reason and expiry", plan.update)
+	}
+	if plan.unchanged != 0 {
+		t.Errorf("unchanged = %d, want 0", plan.unchanged)
+	}
+
+	desired[0].Reason, desired[2].ExpiresAt = "", &expires
+	if plan := planIgnores(existing, desired, false); len(plan.update) != 0 || plan.unchanged != 2 {
+		t.Errorf("planIgnores() = %+v, want the unchanged rules left alone", plan)
+	}
+}
+
+func TestConfigHandler_Apply_Validation(t *testing.T) {
+	h := &ConfigHandler{} // nil repos - testing validation only
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", "{"},
+		{"unknown field", `{"webhooks":[]}`},
+		{"invalid source", `{"sources":[{"name":"x","type":"svn","token":"t"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/config", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			h.Apply(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestFillSourceTokens(t *testing.T) {
+	existing := []domain.Source{
+		{Name: "github", Type: "github", Token: "stored"},
+		{Name: "gitlab", Type: "gitlab", Token: "glpat", URL: "https://gitlab.example.com"},
+	}
+	inputs := []domain.SourceInput{
+		{Name: " github "},
+		{Name: "other"},
+		{Name: "github", Token: "new"},
+		{Name: "gitlab", Type: "gitlab", URL: "https://attacker.example.com"},
+	}
+
+	fillSourceTokens(inputs, existing)
+
+	if inputs[0].Token != "stored" {
+		t.Errorf("inputs[0].Token = %q, want stored", inputs[0].Token)
+	}
+	if inputs[1].Token != "" {
+		t.Errorf("inputs[1].Token = %q, want empty", inputs[1].Token)
+	}
+	if inputs[2].Token != "new" {
+		t.Errorf("inputs[2].Token = %q, want new", inputs[2].Token)
+	}
+	if inputs[3].Token != "" {
+		t.Errorf("inputs[3].Token = %q, want empty for a source moved to another server", inputs[3].Token)
+	}
+}
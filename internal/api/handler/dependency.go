@@ -1,33 +1,60 @@
 package handler
 
 import (
+	"cmp"
+	"database/sql"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-chi/chi/v5"
 	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/i18n"
 	"github.com/jiin/stale/internal/repository"
 	"github.com/jiin/stale/internal/service/cache"
+	"github.com/jiin/stale/internal/service/fuzzy"
+	"github.com/jiin/stale/internal/service/graph"
+	"github.com/jiin/stale/internal/service/scanner"
 )
 
 type DependencyHandler struct {
-	repo       *repository.DependencyRepository
-	statsCache *cache.Cache[*domain.DependencyStats]
-	reposCache *cache.Cache[[]string]
+	repo         *repository.DependencyRepository
+	settingsRepo *repository.SettingsRepository
+	ignoredRepo  *repository.IgnoredRepository
+	viewRepo     *repository.ViewRepository
+	alertRepo    *repository.AlertRepository
+	policyRepo   *repository.PolicyRepository
+	statsCache   *cache.Cache[*domain.DependencyStats]
+	reposCache   *cache.Cache[[]string]
 }
 
-func NewDependencyHandler(repo *repository.DependencyRepository) *DependencyHandler {
+func NewDependencyHandler(repo *repository.DependencyRepository, settingsRepo *repository.SettingsRepository, ignoredRepo *repository.IgnoredRepository, viewRepo *repository.ViewRepository, alertRepo *repository.AlertRepository, policyRepo *repository.PolicyRepository) *DependencyHandler {
 	return &DependencyHandler{
-		repo:       repo,
-		statsCache: cache.New[*domain.DependencyStats](2 * time.Minute),
-		reposCache: cache.New[[]string](5 * time.Minute),
+		repo:         repo,
+		settingsRepo: settingsRepo,
+		ignoredRepo:  ignoredRepo,
+		viewRepo:     viewRepo,
+		alertRepo:    alertRepo,
+		policyRepo:   policyRepo,
+		statsCache:   cache.New[*domain.DependencyStats](2 * time.Minute),
+		reposCache:   cache.New[[]string](5 * time.Minute),
 	}
 }
 
+// ApplyCacheSettings changes the lifetimes of the stats and repository name caches to the ones
+// configured in the settings
+func (h *DependencyHandler) ApplyCacheSettings(settings *domain.Settings) {
+	h.statsCache.SetTTL(time.Duration(settings.StatsCacheSeconds) * time.Second)
+	h.reposCache.SetTTL(time.Duration(settings.ReposCacheSeconds) * time.Second)
+}
+
 // ClearCache clears all cached data (call after scan completes)
 func (h *DependencyHandler) ClearCache() {
 	h.statsCache.Clear()
@@ -40,7 +67,7 @@ func (h *DependencyHandler) List(w http.ResponseWriter, r *http.Request) {
 	if outdated == "true" {
 		deps, err := h.repo.GetUpgradable(r.Context())
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			RespondInternalError(w, err)
 			return
 		}
 		if deps == nil {
@@ -52,7 +79,7 @@ func (h *DependencyHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	deps, err := h.repo.GetAll(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		RespondInternalError(w, err)
 		return
 	}
 	if deps == nil {
@@ -61,13 +88,21 @@ func (h *DependencyHandler) List(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(deps)
 }
 
+// ListPaginated lists a page of the dependencies. The filters of a saved view given by the view
+// query parameter apply to the filters that are not set.
 func (h *DependencyHandler) ListPaginated(w http.ResponseWriter, r *http.Request) {
+	view, ok := h.savedView(w, r)
+	if !ok {
+		return
+	}
+
 	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	statusFilter := r.URL.Query().Get("status") // all, upgradable, uptodate, prod, dev
-	repoFilter := r.URL.Query().Get("repo")
-	ecosystemFilter := r.URL.Query().Get("ecosystem")
-	search := r.URL.Query().Get("search")
+	statusFilter := cmp.Or(r.URL.Query().Get("status"), view.Status) // all, upgradable, uptodate, prod, dev
+	repoFilter := cmp.Or(r.URL.Query().Get("repo"), view.Repo)
+	ecosystemFilter := cmp.Or(r.URL.Query().Get("ecosystem"), view.Ecosystem)
+	search := cmp.Or(r.URL.Query().Get("search"), view.Search)
+	sort := cmp.Or(r.URL.Query().Get("sort"), view.Sort)
 
 	// Support legacy upgradable parameter
 	if r.URL.Query().Get("upgradable") == "true" && statusFilter == "" {
@@ -81,9 +116,9 @@ func (h *DependencyHandler) ListPaginated(w http.ResponseWriter, r *http.Request
 		limit = 50
 	}
 
-	result, err := h.repo.GetPaginated(r.Context(), page, limit, statusFilter, repoFilter, ecosystemFilter, search)
+	result, err := h.repo.GetPaginated(r.Context(), page, limit, statusFilter, repoFilter, ecosystemFilter, search, sort)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		RespondInternalError(w, err)
 		return
 	}
 	if result.Data == nil {
@@ -92,10 +127,58 @@ func (h *DependencyHandler) ListPaginated(w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(result)
 }
 
+// Grouped aggregates the dependencies by package, repository or ecosystem, with the filters of
+// ListPaginated. The grouping defaults to package.
+func (h *DependencyHandler) Grouped(w http.ResponseWriter, r *http.Request) {
+	by := r.URL.Query().Get("by")
+	switch by {
+	case "":
+		by = domain.GroupByPackage
+	case domain.GroupByPackage, domain.GroupByRepo, domain.GroupByEcosystem:
+	default:
+		RespondBadRequest(w, "by must be package, repo or ecosystem")
+		return
+	}
+
+	groups, err := h.repo.GetGrouped(r.Context(), by,
+		r.URL.Query().Get("status"), r.URL.Query().Get("repo"),
+		r.URL.Query().Get("ecosystem"), r.URL.Query().Get("search"))
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(groups)
+}
+
+// savedView returns the saved view given by the view query parameter, or an empty view without
+// one. An invalid or unknown view is reported and ok is false.
+func (h *DependencyHandler) savedView(w http.ResponseWriter, r *http.Request) (view *domain.SavedView, ok bool) {
+	param := r.URL.Query().Get("view")
+	if param == "" {
+		return &domain.SavedView{}, true
+	}
+
+	id, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid view")
+		return nil, false
+	}
+	view, err = h.viewRepo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			RespondNotFound(w, "view not found")
+			return nil, false
+		}
+		RespondInternalError(w, err)
+		return nil, false
+	}
+	return view, true
+}
+
 func (h *DependencyHandler) GetUpgradable(w http.ResponseWriter, r *http.Request) {
 	deps, err := h.repo.GetUpgradable(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		RespondInternalError(w, err)
 		return
 	}
 	if deps == nil {
@@ -105,20 +188,37 @@ func (h *DependencyHandler) GetUpgradable(w http.ResponseWriter, r *http.Request
 }
 
 func (h *DependencyHandler) GetStats(w http.ResponseWriter, r *http.Request) {
-	// Check cache first
-	if stats, found := h.statsCache.Get("stats"); found {
+	settings, err := h.settingsRepo.Get(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	// Check cache first; the key changes with the exclusions so a settings change takes effect
+	cacheKey := "stats:" + settings.StatsExcludedTypes + ":" + settings.StatsExcludedScopes
+	if stats, found := h.statsCache.Get(cacheKey); found {
 		json.NewEncoder(w).Encode(stats)
 		return
 	}
 
-	stats, err := h.repo.GetStats(r.Context())
+	stats, err := h.repo.GetStats(r.Context(), settings.StatsExclusions())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		RespondInternalError(w, err)
+		return
+	}
+	outdated, err := h.repo.GetUpgradable(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	stats.SLABreaches = scanner.SLABreaches(outdated, settings, time.Now())
+	if stats.PolicyViolations, err = h.policyRepo.CountViolations(r.Context()); err != nil {
+		RespondInternalError(w, err)
 		return
 	}
 
 	// Cache the result
-	h.statsCache.Set("stats", stats)
+	h.statsCache.Set(cacheKey, stats)
 	json.NewEncoder(w).Encode(stats)
 }
 
@@ -131,7 +231,7 @@ func (h *DependencyHandler) GetRepositoryNames(w http.ResponseWriter, r *http.Re
 
 	names, err := h.repo.GetRepositoryNames(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		RespondInternalError(w, err)
 		return
 	}
 	if names == nil {
@@ -143,18 +243,50 @@ func (h *DependencyHandler) GetRepositoryNames(w http.ResponseWriter, r *http.Re
 	json.NewEncoder(w).Encode(names)
 }
 
+// maxPackageLookup is the number of package names returned by a lookup
+const maxPackageLookup = 20
+
+// GetPackageNames returns the package names in use. With q, it looks up the names matching q
+// despite typos or partial words instead, closest matches first.
 func (h *DependencyHandler) GetPackageNames(w http.ResponseWriter, r *http.Request) {
 	names, err := h.repo.GetPackageNames(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		RespondInternalError(w, err)
 		return
 	}
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		names = fuzzy.Rank(q, names, maxPackageLookup)
+	}
 	if names == nil {
 		names = []string{}
 	}
 	json.NewEncoder(w).Encode(names)
 }
 
+// Maintainers lists the packages in use with at most max_maintainers (default 1) maintainers
+// known from their registries, e.g. to review the packages maintained by a single person
+func (h *DependencyHandler) Maintainers(w http.ResponseWriter, r *http.Request) {
+	maxMaintainers := 1
+	if v := r.URL.Query().Get("max_maintainers"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			RespondBadRequest(w, "max_maintainers must be a positive integer")
+			return
+		}
+		maxMaintainers = n
+	}
+
+	packages, err := h.repo.GetMaintainers(r.Context(), maxMaintainers)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if packages == nil {
+		packages = []domain.PackageMaintainers{}
+	}
+	json.NewEncoder(w).Encode(packages)
+}
+
 func (h *DependencyHandler) GetFilterOptions(w http.ResponseWriter, r *http.Request) {
 	repoFilter := r.URL.Query().Get("repo")
 	ecosystemFilter := r.URL.Query().Get("ecosystem")
@@ -163,7 +295,7 @@ func (h *DependencyHandler) GetFilterOptions(w http.ResponseWriter, r *http.Requ
 
 	options, err := h.repo.GetFilterOptions(r.Context(), repoFilter, ecosystemFilter, statusFilter, packageFilter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		RespondInternalError(w, err)
 		return
 	}
 	if options.Repos == nil {
@@ -179,11 +311,23 @@ func (h *DependencyHandler) GetFilterOptions(w http.ResponseWriter, r *http.Requ
 }
 
 func (h *DependencyHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
-	filter := r.URL.Query().Get("filter")
-	repoFilter := r.URL.Query().Get("repo")
+	view, ok := h.savedView(w, r)
+	if !ok {
+		return
+	}
+
+	filter := cmp.Or(r.URL.Query().Get("filter"), view.Status)
+	repoFilter := cmp.Or(r.URL.Query().Get("repo"), view.Repo)
 	packageFilter := r.URL.Query().Get("package")
-	ecosystemFilter := r.URL.Query().Get("ecosystem")
-	searchFilter := r.URL.Query().Get("search")
+	ecosystemFilter := cmp.Or(r.URL.Query().Get("ecosystem"), view.Ecosystem)
+	searchFilter := cmp.Or(r.URL.Query().Get("search"), view.Search)
+	sort := cmp.Or(r.URL.Query().Get("sort"), view.Sort)
+
+	columns, err := parseExportColumns(cmp.Or(r.URL.Query().Get("columns"), view.Columns))
+	if err != nil {
+		RespondBadRequest(w, err.Error())
+		return
+	}
 
 	// Support legacy outdated parameter
 	if r.URL.Query().Get("outdated") == "true" && filter == "" {
@@ -191,7 +335,7 @@ func (h *DependencyHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get filtered dependencies directly from database for better performance
-	deps, err := h.repo.GetFilteredWithAll(r.Context(), filter, repoFilter, packageFilter, ecosystemFilter, searchFilter)
+	deps, err := h.repo.GetFilteredSorted(r.Context(), filter, repoFilter, packageFilter, ecosystemFilter, searchFilter, sort)
 	if err != nil {
 		RespondInternalError(w, err)
 		return
@@ -201,6 +345,42 @@ func (h *DependencyHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 		deps = []domain.DependencyWithRepo{}
 	}
 
+	settings, err := h.settingsRepo.Get(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	// Column headers follow the configured locale unless the locale query parameter asks for another,
+	// never the browser's Accept-Language, so scripts reading exports get the same headers
+	locale := i18n.Resolve(r.URL.Query().Get("locale"), settings.Locale)
+
+	// The appendix records what was deliberately left out of the numbers. Its sections have other
+	// columns than the table, so it is only written when asked for with appendix=true.
+	// Reproducible exports (reproducible=true) leave out the generation time, so exports of unchanged
+	// data are identical and diffs between exports only show changes.
+	var appendix *exportAppendix
+	if r.URL.Query().Get("appendix") == "true" {
+		ignored, err := h.ignoredRepo.GetAll(r.Context())
+		if err != nil {
+			RespondInternalError(w, err)
+			return
+		}
+		appendix = &exportAppendix{
+			locale:   locale,
+			ignored:  ignored,
+			settings: settings,
+			filters: [][2]string{
+				{"filter.view", view.Name},
+				{"filter.status", filter},
+				{"filter.repository", repoFilter},
+				{"filter.package", packageFilter},
+				{"filter.ecosystem", ecosystemFilter},
+				{"filter.search", searchFilter},
+			},
+			reproducible: r.URL.Query().Get("reproducible") == "true",
+		}
+	}
+
 	// Build filename
 	var filenameParts []string
 
@@ -224,6 +404,8 @@ func (h *DependencyHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 		filenameParts = append(filenameParts, "production")
 	case "dev":
 		filenameParts = append(filenameParts, "development")
+	case "peer", "optional", "bundled", "provided", "single_maintainer", "risky":
+		filenameParts = append(filenameParts, filter)
 	}
 
 	filenameParts = append(filenameParts, "dependencies")
@@ -238,27 +420,251 @@ func (h *DependencyHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 	defer writer.Flush()
 
 	// Write header row
-	header := []string{"No.", "Repository", "Source", "Dependency", "Ecosystem", "Type", "Current Version", "Latest Version", "Upgradable"}
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = i18n.T(locale, column.label)
+	}
 	writer.Write(header)
 
 	// Write data rows
 	for i, dep := range deps {
-		upgradable := "No"
-		if dep.IsOutdated {
-			upgradable = "Yes"
+		row := make([]string, len(columns))
+		for j, column := range columns {
+			row[j] = column.value(locale, i+1, dep)
 		}
+		writer.Write(row)
+	}
 
-		row := []string{
-			strconv.Itoa(i + 1),
-			dep.RepoFullName,
-			dep.SourceName,
-			dep.Name,
-			dep.Ecosystem,
-			dep.Type,
-			dep.CurrentVersion,
-			dep.LatestVersion,
-			upgradable,
+	if appendix != nil {
+		appendix.write(writer, time.Now())
+	}
+}
+
+// exportAppendix holds the context appended to a CSV export: the ignore rules in effect,
+// the filters applied and the upgrade SLA settings
+type exportAppendix struct {
+	locale   string
+	ignored  []domain.IgnoredDependency
+	settings *domain.Settings
+	filters  [][2]string // label message key, value
+
+	reproducible bool // leave out the generation time
+}
+
+// write appends the appendix sections, each preceded by a blank row and a title row.
+// Expired ignore rules are left out since they no longer exclude anything.
+func (a *exportAppendix) write(writer *csv.Writer, now time.Time) {
+	t := func(key string, args ...interface{}) string { return i18n.T(a.locale, key, args...) }
+
+	writer.Write([]string{})
+	writer.Write([]string{t("export.ignored_title")})
+	writer.Write([]string{t("column.dependency"), t("column.ecosystem"), t("column.reason"), t("column.ignored_since"), t("column.expires")})
+	for _, ig := range a.ignored {
+		if ig.ExpiresAt != nil && !ig.ExpiresAt.After(now) {
+			continue
 		}
-		writer.Write(row)
+		ecosystem := ig.Ecosystem
+		if ecosystem == "" {
+			ecosystem = t("value.all")
+		}
+		expires := t("value.never")
+		if ig.ExpiresAt != nil {
+			expires = i18n.Date(a.locale, *ig.ExpiresAt)
+		}
+		writer.Write([]string{ig.Name, ecosystem, ig.Reason, i18n.Date(a.locale, ig.CreatedAt), expires})
+	}
+
+	writer.Write([]string{})
+	writer.Write([]string{t("export.settings_title")})
+	writer.Write([]string{t("column.setting"), t("column.value")})
+	if !a.reproducible {
+		writer.Write([]string{t("export.generated_at"), now.Format(time.RFC3339)})
 	}
+	writer.Write([]string{t("export.locale"), i18n.Resolve(a.locale)})
+	for _, f := range a.filters {
+		if f[1] != "" {
+			writer.Write([]string{t("export.filter", t(f[0])), f[1]})
+		}
+	}
+	if a.settings != nil {
+		writer.Write([]string{t("export.sla_major"), i18n.Number(a.locale, a.settings.SLAMajorDays)})
+		writer.Write([]string{t("export.sla_minor"), i18n.Number(a.locale, a.settings.SLAMinorDays)})
+		writer.Write([]string{t("export.sla_patch"), i18n.Number(a.locale, a.settings.SLAPatchDays)})
+	}
+}
+
+// Get returns a dependency with the versions it was found at and the ignore rules and
+// vulnerability alerts of its package in the repository
+func (h *DependencyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	ctx := r.Context()
+	dep, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			RespondNotFound(w, "dependency not found")
+			return
+		}
+		RespondInternalError(w, err)
+		return
+	}
+
+	detail := domain.DependencyDetail{DependencyWithRepo: *dep}
+	if detail.History, err = h.repo.GetHistory(ctx, dep.Dependency); err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if detail.Ignores, err = h.ignoredRepo.GetForDependency(ctx, dep.Name, dep.Ecosystem, dep.RepoFullName); err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if detail.Alerts, err = h.alertRepo.GetByPackage(ctx, dep.RepositoryID, dep.Ecosystem, dep.Name); err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	if detail.History == nil {
+		detail.History = []domain.DependencyVersion{}
+	}
+	if detail.Ignores == nil {
+		detail.Ignores = []domain.IgnoredDependency{}
+	}
+	if detail.Alerts == nil {
+		detail.Alerts = []domain.VulnerabilityAlertWithDependency{}
+	}
+	json.NewEncoder(w).Encode(detail)
+}
+
+// Graph returns a graph of repositories and the packages they share, as JSON (default) or
+// Graphviz DOT (format=dot). Packages used by fewer than min_repos repositories (default 2) are left out.
+func (h *DependencyHandler) Graph(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "dot" {
+		RespondBadRequest(w, "format must be 'json' or 'dot'")
+		return
+	}
+
+	minRepos := 2
+	if v := r.URL.Query().Get("min_repos"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			RespondBadRequest(w, "min_repos must be a positive integer")
+			return
+		}
+		minRepos = n
+	}
+
+	deps, err := h.repo.GetFilteredWithAll(r.Context(), "", "", "", r.URL.Query().Get("ecosystem"), "")
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	g := graph.Build(deps, minRepos)
+
+	if format == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		w.Header().Set("Content-Disposition", "attachment; filename=dependencies.dot")
+		graph.WriteDOT(w, g)
+		return
+	}
+	json.NewEncoder(w).Encode(g)
+}
+
+// Convergence reports, for each package used by at least min_repos repositories (default 2), the
+// spread of its versions and how far its usages agree on one version, least converged first.
+// An ecosystem query parameter limits the report to one ecosystem.
+func (h *DependencyHandler) Convergence(w http.ResponseWriter, r *http.Request) {
+	minRepos := 2
+	if v := r.URL.Query().Get("min_repos"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			RespondBadRequest(w, "min_repos must be a positive integer")
+			return
+		}
+		minRepos = n
+	}
+
+	deps, err := h.repo.GetFilteredWithAll(r.Context(), "", "", "", r.URL.Query().Get("ecosystem"), "")
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(scanner.Convergence(deps, minRepos))
+}
+
+// Forecast estimates the next release window of each package in use from its release cadence and
+// lists the dependencies projected to fall at least majors (default 2) major versions behind within
+// months (default 6) months. An ecosystem query parameter limits the forecast to one ecosystem.
+func (h *DependencyHandler) Forecast(w http.ResponseWriter, r *http.Request) {
+	months, majors := 6, 2
+	if v := r.URL.Query().Get("months"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 60 {
+			RespondBadRequest(w, "months must be between 1 and 60")
+			return
+		}
+		months = n
+	}
+	if v := r.URL.Query().Get("majors"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			RespondBadRequest(w, "majors must be a positive integer")
+			return
+		}
+		majors = n
+	}
+
+	ecosystem := r.URL.Query().Get("ecosystem")
+	deps, err := h.repo.GetFilteredWithAll(r.Context(), "", "", "", ecosystem, "")
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	releases, err := h.repo.GetPackageReleases(r.Context(), ecosystem)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(scanner.Forecast(deps, releases, time.Now(), months, majors))
+}
+
+// Impact lists every repository that would need a change to upgrade a package to the target
+// version, grouped by whether the jump is major, minor or patch. Package names containing
+// slashes (e.g. npm scopes) must be URL-encoded.
+func (h *DependencyHandler) Impact(w http.ResponseWriter, r *http.Request) {
+	ecosystem := chi.URLParam(r, "ecosystem")
+	name, err := url.PathUnescape(chi.URLParam(r, "name"))
+	if err != nil || ecosystem == "" || name == "" {
+		RespondBadRequest(w, "invalid package")
+		return
+	}
+
+	target := strings.TrimSpace(r.URL.Query().Get("target"))
+	if target == "" {
+		RespondBadRequest(w, "target is required")
+		return
+	}
+	if _, err := semver.NewVersion(target); err != nil {
+		RespondBadRequest(w, "target must be a valid version")
+		return
+	}
+
+	deps, err := h.repo.GetFilteredWithAll(r.Context(), "", "", name, ecosystem, "")
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if len(deps) == 0 {
+		RespondNotFound(w, "package not found")
+		return
+	}
+
+	json.NewEncoder(w).Encode(scanner.PackageImpact(ecosystem, name, target, deps))
 }
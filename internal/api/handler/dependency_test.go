@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestDependencyHandler_Graph_Validation(t *testing.T) {
+	h := &DependencyHandler{} // nil repo - testing validation only
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"invalid format", "?format=svg"},
+		{"non-numeric min_repos", "?min_repos=abc"},
+		{"zero min_repos", "?min_repos=0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/graph"+tt.query, nil)
+			w := httptest.NewRecorder()
+			h.Graph(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestDependencyHandler_Convergence_Validation(t *testing.T) {
+	h := &DependencyHandler{}
+
+	for _, query := range []string{"?min_repos=abc", "?min_repos=0"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/packages/convergence"+query, nil)
+		w := httptest.NewRecorder()
+		h.Convergence(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestDependencyHandler_Grouped_Validation(t *testing.T) {
+	h := &DependencyHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/dependencies/grouped?by=owner", nil)
+	w := httptest.NewRecorder()
+	h.Grouped(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestDependencyHandler_Maintainers_Validation(t *testing.T) {
+	h := &DependencyHandler{}
+
+	for _, query := range []string{"?max_maintainers=abc", "?max_maintainers=0"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/dependencies/maintainers"+query, nil)
+		w := httptest.NewRecorder()
+		h.Maintainers(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestDependencyHandler_Impact_Validation(t *testing.T) {
+	h := &DependencyHandler{}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"missing target", ""},
+		{"invalid target", "?target=next"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/packages/npm/react/impact"+tt.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("ecosystem", "npm")
+			rctx.URLParams.Add("name", "react")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+			h.Impact(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestDependencyHandler_Get_InvalidID(t *testing.T) {
+	h := &DependencyHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/dependencies/abc", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "abc")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+	h.Get(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestExportAppendix_Write(t *testing.T) {
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(30 * 24 * time.Hour)
+	a := &exportAppendix{
+		ignored: []domain.IgnoredDependency{
+			{Name: "lodash", Ecosystem: "npm", Reason: "pinned by legacy build", ExpiresAt: &future, CreatedAt: now},
+			{Name: "guava", Reason: "expired rule", ExpiresAt: &past, CreatedAt: now},
+			{Name: "log4j"},
+		},
+		settings: &domain.Settings{SLAMajorDays: 90},
+		filters:  [][2]string{{"filter.status", "upgradable"}, {"filter.repository", ""}},
+	}
+
+	rows := func(locale string) map[string][]string {
+		a.locale = locale
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		a.write(writer, now)
+		writer.Flush()
+
+		reader := csv.NewReader(&buf)
+		reader.FieldsPerRecord = -1
+		records, err := reader.ReadAll()
+		if err != nil {
+			t.Fatalf("invalid CSV: %v", err)
+		}
+		got := make(map[string][]string)
+		for _, row := range records {
+			got[row[0]] = row
+		}
+		return got
+	}
+
+	got := rows("en")
+	if row := got["lodash"]; len(row) != 5 || row[2] != "pinned by legacy build" || row[4] != "2025-03-31" {
+		t.Errorf("lodash row = %v", row)
+	}
+	if row := got["log4j"]; len(row) != 5 || row[1] != "all" || row[4] != "Never" {
+		t.Errorf("log4j row = %v", row)
+	}
+	if _, ok := got["guava"]; ok {
+		t.Error("expired ignore rule should be left out")
+	}
+	if row := got["Status Filter"]; len(row) != 2 || row[1] != "upgradable" {
+		t.Errorf("filter row = %v", row)
+	}
+	if _, ok := got["Repository Filter"]; ok {
+		t.Error("empty filters should be left out")
+	}
+	if row := got["Major Upgrade SLA (days)"]; len(row) != 2 || row[1] != "90" {
+		t.Errorf("SLA row = %v", row)
+	}
+
+	got = rows("ko")
+	if row := got["lodash"]; len(row) != 5 || row[4] != "2025년 3월 31일" {
+		t.Errorf("ko lodash row = %v", row)
+	}
+	if row := got["상태 필터"]; len(row) != 2 || row[1] != "upgradable" {
+		t.Errorf("ko filter row = %v", row)
+	}
+	if row := got["언어"]; len(row) != 2 || row[1] != "ko" {
+		t.Errorf("ko locale row = %v", row)
+	}
+}
+
+func TestExportAppendix_Reproducible(t *testing.T) {
+	write := func(reproducible bool, now time.Time) string {
+		a := &exportAppendix{locale: "en", settings: &domain.Settings{SLAMajorDays: 90}, reproducible: reproducible}
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		a.write(writer, now)
+		writer.Flush()
+		return buf.String()
+	}
+
+	first := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+	if !strings.Contains(write(false, first), "Generated At") {
+		t.Error("appendix should record when it was generated")
+	}
+	if a, b := write(true, first), write(true, second); a != b || strings.Contains(a, "Generated At") {
+		t.Errorf("reproducible appendices differ or record the generation time:\n%s\n%s", a, b)
+	}
+}
+
+func TestDependencyHandler_Forecast_Validation(t *testing.T) {
+	h := &DependencyHandler{}
+
+	for _, query := range []string{"?months=abc", "?months=0", "?months=61", "?majors=0"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/packages/forecast"+query, nil)
+		w := httptest.NewRecorder()
+		h.Forecast(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
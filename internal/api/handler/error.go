@@ -1,9 +1,9 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 
+	"github.com/jiin/stale/internal/api/problem"
 	"github.com/rs/zerolog/log"
 )
 
@@ -15,30 +15,22 @@ func LimitBody(r *http.Request) {
 	r.Body = http.MaxBytesReader(nil, r.Body, MaxBodySize)
 }
 
-// ErrorResponse represents a structured API error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
-	Code    int    `json:"code"`
-}
+// ErrorResponse represents a structured API error response, see problem.Details
+type ErrorResponse = problem.Details
 
-// RespondError sends a JSON error response and logs the error
+// RespondError sends a problem details response with the error code of the status and logs the error
 func RespondError(w http.ResponseWriter, code int, userMessage string, err error) {
+	RespondProblem(w, code, problem.CodeFor(code), userMessage, err)
+}
+
+// RespondProblem sends a problem details response with a specific error code and logs the error
+func RespondProblem(w http.ResponseWriter, status int, errorCode, userMessage string, err error) {
 	// Log the actual error for debugging
 	if err != nil {
-		log.Error().Err(err).Int("status", code).Str("message", userMessage).Msg("API error")
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-
-	response := ErrorResponse{
-		Error:   http.StatusText(code),
-		Message: userMessage,
-		Code:    code,
+		log.Error().Err(err).Int("status", status).Str("error_code", errorCode).Str("message", userMessage).Msg("API error")
 	}
 
-	json.NewEncoder(w).Encode(response)
+	problem.Write(w, status, errorCode, userMessage)
 }
 
 // RespondBadRequest sends a 400 Bad Request response
@@ -60,8 +60,8 @@ func TestRespondError(t *testing.T) {
 				t.Errorf("RespondError() status = %d, want %d", w.Code, tt.code)
 			}
 
-			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
-				t.Errorf("RespondError() Content-Type = %q, want %q", ct, "application/json")
+			if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("RespondError() Content-Type = %q, want %q", ct, "application/problem+json")
 			}
 
 			var response ErrorResponse
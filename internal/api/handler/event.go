@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/jiin/stale/internal/service/scheduler"
+	"github.com/rs/zerolog/log"
+)
+
+// EventHandler lists the changes made to ignore rules, sources and repositories and undoes recent
+// deletions
+type EventHandler struct {
+	repo        *repository.EventRepository
+	ignoredRepo *repository.IgnoredRepository
+	sourceRepo  *repository.SourceRepository
+	repoRepo    *repository.RepoRepository
+	depRepo     *repository.DependencyRepository
+	scheduler   *scheduler.Scheduler
+}
+
+func NewEventHandler(
+	repo *repository.EventRepository,
+	ignoredRepo *repository.IgnoredRepository,
+	sourceRepo *repository.SourceRepository,
+	repoRepo *repository.RepoRepository,
+	depRepo *repository.DependencyRepository,
+	scheduler *scheduler.Scheduler,
+) *EventHandler {
+	return &EventHandler{
+		repo:        repo,
+		ignoredRepo: ignoredRepo,
+		sourceRepo:  sourceRepo,
+		repoRepo:    repoRepo,
+		depRepo:     depRepo,
+		scheduler:   scheduler,
+	}
+}
+
+const (
+	defaultEventLimit = 100
+	maxEventLimit     = 500
+)
+
+// List returns the latest events, newest first (?limit, default 100)
+func (h *EventHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit := defaultEventLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxEventLimit {
+			RespondBadRequest(w, "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	events, err := h.repo.GetRecent(r.Context(), limit)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if events == nil {
+		events = []domain.Event{}
+	}
+	now := time.Now()
+	for i := range events {
+		events[i].Undoable = events[i].CanUndo(now)
+	}
+	json.NewEncoder(w).Encode(events)
+}
+
+// UndoResult is the event whose change was undone and the restored entity. Restored sources and
+// repositories get a new id.
+type UndoResult struct {
+	Event    *domain.Event `json:"event"`
+	Restored any           `json:"restored"`
+}
+
+// undoConflict is an undo refused because of the current state, e.g. an equal ignore rule was
+// created since
+type undoConflict string
+
+func (e undoConflict) Error() string { return string(e) }
+
+// Undo restores the entity deleted by a recent event
+func (h *EventHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	ctx := r.Context()
+	event, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			RespondNotFound(w, "event not found")
+			return
+		}
+		RespondInternalError(w, err)
+		return
+	}
+	if event.UndoneAt != nil {
+		RespondError(w, http.StatusConflict, "event was already undone", nil)
+		return
+	}
+	if !event.CanUndo(time.Now()) {
+		RespondError(w, http.StatusConflict, "event cannot be undone: only deletions of the last hour can be", nil)
+		return
+	}
+
+	// Claim the event first so concurrent undos restore the entity once
+	claimed, err := h.repo.MarkUndone(ctx, id)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if !claimed {
+		RespondError(w, http.StatusConflict, "event was already undone", nil)
+		return
+	}
+
+	restored, err := h.restore(ctx, event)
+	if err != nil {
+		if unmarkErr := h.repo.UnmarkUndone(ctx, id); unmarkErr != nil {
+			log.Warn().Err(unmarkErr).Int64("event_id", id).Msg("failed to reset event after a failed undo")
+		}
+		var conflict undoConflict
+		if errors.As(err, &conflict) {
+			RespondError(w, http.StatusConflict, conflict.Error(), nil)
+			return
+		}
+		RespondInternalError(w, err)
+		return
+	}
+
+	event, err = h.repo.GetByID(ctx, id)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	event.Payload = ""
+	json.NewEncoder(w).Encode(UndoResult{Event: event, Restored: restored})
+}
+
+// restore recreates the entity deleted by an event from its snapshot
+func (h *EventHandler) restore(ctx context.Context, event *domain.Event) (any, error) {
+	switch event.Type {
+	case domain.EventIgnoreDeleted:
+		var input domain.IgnoredDependencyInput
+		if err := json.Unmarshal([]byte(event.Payload), &input); err != nil {
+			return nil, err
+		}
+		ignored, err := h.ignoredRepo.Create(ctx, &input)
+		if err != nil && strings.Contains(err.Error(), "UNIQUE") {
+			return nil, undoConflict("an equal ignore rule exists")
+		}
+		return ignored, err
+
+	case domain.EventSourceDeleted:
+		var input domain.SourceInput
+		if err := json.Unmarshal([]byte(event.Payload), &input); err != nil {
+			return nil, err
+		}
+		source, err := h.sourceRepo.Create(ctx, input)
+		if err != nil {
+			if strings.Contains(err.Error(), "UNIQUE") {
+				return nil, undoConflict("a source with the same name exists")
+			}
+			return nil, err
+		}
+		// Repositories of the source are found again by its next scan
+		h.scheduler.ReloadSourceSchedules()
+		return source, nil
+
+	case domain.EventRepositoryDeleted:
+		var deleted domain.DeletedRepository
+		if err := json.Unmarshal([]byte(event.Payload), &deleted); err != nil {
+			return nil, err
+		}
+		if _, err := h.sourceRepo.GetByID(ctx, deleted.Repository.SourceID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, undoConflict("the source of the repository was deleted")
+			}
+			return nil, err
+		}
+		repoID, err := h.repoRepo.Upsert(ctx, deleted.Repository)
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range deleted.Dependencies {
+			dep.RepositoryID = repoID
+			if err := h.depRepo.Upsert(ctx, dep); err != nil {
+				return nil, err
+			}
+		}
+		return h.repoRepo.GetByID(ctx, repoID)
+	}
+	return nil, undoConflict("event cannot be undone")
+}
+
+// recordEvent logs a change to ignore rules, sources or repositories with an optional snapshot of
+// the deleted entity. Failures are logged and do not fail the change.
+func recordEvent(ctx context.Context, events *repository.EventRepository, eventType string, entityID int64, summary string, snapshot any) {
+	if events == nil {
+		return
+	}
+
+	var payload []byte
+	if snapshot != nil {
+		var err error
+		if payload, err = json.Marshal(snapshot); err != nil {
+			log.Warn().Err(err).Str("type", eventType).Int64("entity_id", entityID).Msg("failed to encode event snapshot")
+			return
+		}
+	}
+
+	_, err := events.Create(ctx, domain.Event{Type: eventType, EntityID: entityID, Summary: summary, Payload: string(payload)})
+	if err != nil {
+		log.Warn().Err(err).Str("type", eventType).Int64("entity_id", entityID).Msg("failed to record event")
+	}
+}
+
+// snapshotRepository returns a repository with its dependencies for the event of its deletion
+func snapshotRepository(ctx context.Context, repoRepo *repository.RepoRepository, depRepo *repository.DependencyRepository, id int64) (*domain.DeletedRepository, error) {
+	repo, err := repoRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	deps, err := depRepo.GetByRepoID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	repo.ID = 0
+	for i := range deps {
+		deps[i].ID, deps[i].RepositoryID = 0, 0
+	}
+	return &domain.DeletedRepository{Repository: *repo, Dependencies: deps}, nil
+}
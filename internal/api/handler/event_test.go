@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestEventHandler_List_InvalidLimit(t *testing.T) {
+	h := &EventHandler{} // nil repos - testing validation only
+
+	for _, limit := range []string{"abc", "0", "501"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events?limit="+limit, nil)
+		w := httptest.NewRecorder()
+		h.List(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("limit %s: status = %d, want %d", limit, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestEventHandler_Undo_InvalidID(t *testing.T) {
+	h := &EventHandler{}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events/abc/undo", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "abc")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+	h.Undo(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestEventCanUndo(t *testing.T) {
+	now := time.Now()
+	undone := now.Add(-time.Minute)
+
+	tests := []struct {
+		name  string
+		event domain.Event
+		want  bool
+	}{
+		{"recent deletion", domain.Event{Type: domain.EventSourceDeleted, CreatedAt: now.Add(-time.Minute)}, true},
+		{"creation", domain.Event{Type: domain.EventIgnoreCreated, CreatedAt: now.Add(-time.Minute)}, false},
+		{"already undone", domain.Event{Type: domain.EventIgnoreDeleted, CreatedAt: now.Add(-2 * time.Minute), UndoneAt: &undone}, false},
+		{"outside the undo window", domain.Event{Type: domain.EventRepositoryDeleted, CreatedAt: now.Add(-domain.EventUndoWindow)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.event.CanUndo(now); got != tt.want {
+				t.Errorf("CanUndo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
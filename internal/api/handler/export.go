@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/i18n"
+)
+
+// exportColumn is a column the dependency CSV export can include
+type exportColumn struct {
+	name  string
+	label string // message key of the header
+	value func(locale string, row int, dep domain.DependencyWithRepo) string
+}
+
+// exportColumns are the columns of the dependency CSV export, selected by name with the columns
+// query parameter
+var exportColumns = []exportColumn{
+	{"no", "column.no", func(_ string, row int, _ domain.DependencyWithRepo) string { return strconv.Itoa(row) }},
+	{"repository", "column.repository", func(_ string, _ int, dep domain.DependencyWithRepo) string { return dep.RepoFullName }},
+	{"owners", "column.owners", func(_ string, _ int, dep domain.DependencyWithRepo) string { return dep.RepoOwners }},
+	{"source", "column.source", func(_ string, _ int, dep domain.DependencyWithRepo) string { return dep.SourceName }},
+	{"dependency", "column.dependency", func(_ string, _ int, dep domain.DependencyWithRepo) string { return dep.Name }},
+	{"ecosystem", "column.ecosystem", func(_ string, _ int, dep domain.DependencyWithRepo) string { return dep.Ecosystem }},
+	{"type", "column.type", func(_ string, _ int, dep domain.DependencyWithRepo) string { return dep.Type }},
+	{"scope", "column.scope", func(_ string, _ int, dep domain.DependencyWithRepo) string { return dep.Scope }},
+	{"current_version", "column.current_version", func(_ string, _ int, dep domain.DependencyWithRepo) string { return dep.CurrentVersion }},
+	{"latest_version", "column.latest_version", func(_ string, _ int, dep domain.DependencyWithRepo) string { return dep.LatestVersion }},
+	{"latest_in_major", "column.latest_in_major", func(_ string, _ int, dep domain.DependencyWithRepo) string { return dep.LatestInMajor }},
+	{"upgradable", "column.upgradable", func(locale string, _ int, dep domain.DependencyWithRepo) string {
+		if dep.IsOutdated {
+			return i18n.T(locale, "value.yes")
+		}
+		return i18n.T(locale, "value.no")
+	}},
+	{"outdated_since", "column.outdated_since", func(locale string, _ int, dep domain.DependencyWithRepo) string {
+		if dep.FirstOutdatedAt == nil {
+			return ""
+		}
+		return i18n.Date(locale, *dep.FirstOutdatedAt)
+	}},
+	{"manifest", "column.manifest", func(_ string, _ int, dep domain.DependencyWithRepo) string { return dep.ManifestPath }},
+	{"risks", "column.risks", func(_ string, _ int, dep domain.DependencyWithRepo) string { return dep.Risks }},
+}
+
+// defaultExportColumns are exported when no columns are requested
+var defaultExportColumns = []string{"no", "repository", "owners", "source", "dependency", "ecosystem", "type", "current_version", "latest_version", "upgradable"}
+
+// parseExportColumns returns the export columns of a comma-separated list of column names in
+// their order, or the default columns for an empty list
+func parseExportColumns(list string) ([]exportColumn, error) {
+	names := defaultExportColumns
+	if strings.TrimSpace(list) != "" {
+		names = strings.Split(list, ",")
+	}
+
+	columns := make([]exportColumn, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		column, ok := findExportColumn(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q, columns are %s", name, exportColumnNames())
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("column %q is listed twice", name)
+		}
+		seen[name] = true
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+func findExportColumn(name string) (exportColumn, bool) {
+	for _, column := range exportColumns {
+		if column.name == name {
+			return column, true
+		}
+	}
+	return exportColumn{}, false
+}
+
+func exportColumnNames() string {
+	names := make([]string, len(exportColumns))
+	for i, column := range exportColumns {
+		names[i] = column.name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestParseExportColumns(t *testing.T) {
+	columns, err := parseExportColumns("")
+	if err != nil || len(columns) != len(defaultExportColumns) {
+		t.Fatalf("parseExportColumns(\"\") = %d columns, %v; want the default columns", len(columns), err)
+	}
+
+	columns, err = parseExportColumns("dependency, current_version,latest_in_major")
+	if err != nil {
+		t.Fatalf("parseExportColumns() error = %v", err)
+	}
+	var names []string
+	for _, c := range columns {
+		names = append(names, c.name)
+	}
+	if want := []string{"dependency", "current_version", "latest_in_major"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("parseExportColumns() = %v, want %v", names, want)
+	}
+
+	for _, list := range []string{"dependency,size", "dependency,dependency", "dependency,"} {
+		if _, err := parseExportColumns(list); err == nil {
+			t.Errorf("parseExportColumns(%q) expected error", list)
+		}
+	}
+}
+
+func TestExportColumnValues(t *testing.T) {
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	dep := domain.DependencyWithRepo{
+		Dependency: domain.Dependency{Name: "react", CurrentVersion: "17.0.2", IsOutdated: true, FirstOutdatedAt: &since},
+	}
+
+	columns, err := parseExportColumns("no,dependency,upgradable,outdated_since")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var row []string
+	for _, c := range columns {
+		row = append(row, c.value("en", 3, dep))
+	}
+	if want := []string{"3", "react", "Yes", "2024-03-01"}; !reflect.DeepEqual(row, want) {
+		t.Errorf("row = %v, want %v", row, want)
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jiin/stale/internal/service/feature"
+)
+
+type FeatureHandler struct {
+	flags *feature.Flags
+}
+
+func NewFeatureHandler(flags *feature.Flags) *FeatureHandler {
+	return &FeatureHandler{flags: flags}
+}
+
+// FeatureFlagRequest enables or disables a feature flag
+type FeatureFlagRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// List returns every feature flag with its state and where the state comes from
+func (h *FeatureHandler) List(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(h.flags.List(r.Context()))
+}
+
+// Update enables or disables a feature flag on this instance
+func (h *FeatureHandler) Update(w http.ResponseWriter, r *http.Request) {
+	LimitBody(r)
+	var req FeatureFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+	if req.Enabled == nil {
+		RespondBadRequest(w, "enabled is required")
+		return
+	}
+
+	flag, err := h.flags.Set(r.Context(), chi.URLParam(r, "name"), *req.Enabled)
+	if errors.Is(err, feature.ErrUnknownFlag) {
+		RespondNotFound(w, "feature flag not found")
+		return
+	}
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(flag)
+}
+
+// Reset removes the state set through the API so the flag's default applies again
+func (h *FeatureHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	flag, err := h.flags.Reset(r.Context(), chi.URLParam(r, "name"))
+	if errors.Is(err, feature.ErrUnknownFlag) {
+		RespondNotFound(w, "feature flag not found")
+		return
+	}
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(flag)
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFeatureHandler_Update_Validation(t *testing.T) {
+	h := NewFeatureHandler(nil) // nil flags - testing validation only
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", `{`},
+		{"missing enabled", `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/features/ignore_suggestions", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			h.Update(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
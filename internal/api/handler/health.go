@@ -6,21 +6,25 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/jiin/stale/internal/buildinfo"
+	"github.com/jiin/stale/internal/service/update"
 	"github.com/jmoiron/sqlx"
 )
 
 type HealthHandler struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	updates *update.Checker
 }
 
-func NewHealthHandler(db *sqlx.DB) *HealthHandler {
-	return &HealthHandler{db: db}
+func NewHealthHandler(db *sqlx.DB, updates *update.Checker) *HealthHandler {
+	return &HealthHandler{db: db, updates: updates}
 }
 
 type HealthResponse struct {
 	Status   string            `json:"status"`
 	Checks   map[string]string `json:"checks,omitempty"`
 	Version  string            `json:"version,omitempty"`
+	Update   *update.Status    `json:"update,omitempty"` // set once the releases have been checked
 }
 
 func (h *HealthHandler) Check(w http.ResponseWriter, r *http.Request) {
@@ -45,7 +49,12 @@ func (h *HealthHandler) Check(w http.ResponseWriter, r *http.Request) {
 	response := HealthResponse{
 		Status:  status,
 		Checks:  checks,
-		Version: "1.0.0",
+		Version: buildinfo.Version,
+	}
+	if h.updates != nil {
+		if st := h.updates.Status(); st.CheckedAt != nil {
+			response.Update = &st
+		}
 	}
 
 	if status != "ok" {
@@ -5,10 +5,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/buildinfo"
+	"github.com/jiin/stale/internal/service/update"
 )
 
 func TestHealthHandler_Check_NoDB(t *testing.T) {
-	handler := NewHealthHandler(nil)
+	handler := NewHealthHandler(nil, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
 	w := httptest.NewRecorder()
@@ -38,7 +42,7 @@ func TestHealthHandler_Check_NoDB(t *testing.T) {
 }
 
 func TestHealthHandler_Check_ResponseStructure(t *testing.T) {
-	handler := NewHealthHandler(nil)
+	handler := NewHealthHandler(nil, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
 	w := httptest.NewRecorder()
@@ -59,3 +63,26 @@ func TestHealthHandler_Check_ResponseStructure(t *testing.T) {
 		t.Error("Response should have 'version' field")
 	}
 }
+
+func TestHealthHandler_Check_Version(t *testing.T) {
+	handler := NewHealthHandler(nil, update.New("0.2.0", time.Hour))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+	w := httptest.NewRecorder()
+
+	handler.Check(w, req)
+
+	var response HealthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Version != buildinfo.Version {
+		t.Errorf("Response.Version = %q, want %q", response.Version, buildinfo.Version)
+	}
+
+	// The update status is left out until the releases have been checked
+	if response.Update != nil {
+		t.Errorf("Response.Update = %+v, want nil before the first check", response.Update)
+	}
+}
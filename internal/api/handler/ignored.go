@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jiin/stale/internal/domain"
@@ -11,17 +15,18 @@ import (
 )
 
 type IgnoredHandler struct {
-	repo *repository.IgnoredRepository
+	repo   *repository.IgnoredRepository
+	events *repository.EventRepository
 }
 
-func NewIgnoredHandler(repo *repository.IgnoredRepository) *IgnoredHandler {
-	return &IgnoredHandler{repo: repo}
+func NewIgnoredHandler(repo *repository.IgnoredRepository, events *repository.EventRepository) *IgnoredHandler {
+	return &IgnoredHandler{repo: repo, events: events}
 }
 
 func (h *IgnoredHandler) List(w http.ResponseWriter, r *http.Request) {
 	ignored, err := h.repo.GetAll(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		RespondInternalError(w, err)
 		return
 	}
 	if ignored == nil {
@@ -34,20 +39,25 @@ func (h *IgnoredHandler) Create(w http.ResponseWriter, r *http.Request) {
 	LimitBody(r)
 	var input domain.IgnoredDependencyInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		RespondBadRequest(w, "invalid request body")
 		return
 	}
 
 	if input.Name == "" {
-		http.Error(w, "name is required", http.StatusBadRequest)
+		RespondBadRequest(w, "name is required")
+		return
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		RespondBadRequest(w, "expires_at must be in the future")
 		return
 	}
 
 	ignored, err := h.repo.Create(r.Context(), &input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		RespondInternalError(w, err)
 		return
 	}
+	h.recordCreated(r.Context(), ignored)
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(ignored)
@@ -56,18 +66,40 @@ func (h *IgnoredHandler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *IgnoredHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		RespondBadRequest(w, "invalid id")
 		return
 	}
 
-	if err := h.repo.Delete(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.delete(r.Context(), id); err != nil {
+		RespondInternalError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// delete removes an ignore rule, recording its deletion so it can be undone
+func (h *IgnoredHandler) delete(ctx context.Context, id int64) error {
+	ignored, err := h.repo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := h.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordEvent(ctx, h.events, domain.EventIgnoreDeleted, id, ignoreLabel(ignored.Name, ignored.Ecosystem, ignored.Repository), ignoreToInput(*ignored))
+	return nil
+}
+
+// recordCreated records the creation of an ignore rule
+func (h *IgnoredHandler) recordCreated(ctx context.Context, ignored *domain.IgnoredDependency) {
+	recordEvent(ctx, h.events, domain.EventIgnoreCreated, ignored.ID, ignoreLabel(ignored.Name, ignored.Ecosystem, ignored.Repository), nil)
+}
+
 // BulkCreate adds multiple dependencies to the ignore list
 func (h *IgnoredHandler) BulkCreate(w http.ResponseWriter, r *http.Request) {
 	LimitBody(r)
@@ -75,12 +107,12 @@ func (h *IgnoredHandler) BulkCreate(w http.ResponseWriter, r *http.Request) {
 		Items []domain.IgnoredDependencyInput `json:"items"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		RespondBadRequest(w, "invalid request body")
 		return
 	}
 
 	if len(input.Items) == 0 {
-		http.Error(w, "items array is required", http.StatusBadRequest)
+		RespondBadRequest(w, "items array is required")
 		return
 	}
 
@@ -90,7 +122,7 @@ func (h *IgnoredHandler) BulkCreate(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 	for _, item := range input.Items {
-		if item.Name == "" {
+		if item.Name == "" || (item.ExpiresAt != nil && !item.ExpiresAt.After(time.Now())) {
 			skipped++
 			continue
 		}
@@ -100,6 +132,7 @@ func (h *IgnoredHandler) BulkCreate(w http.ResponseWriter, r *http.Request) {
 			duplicates++
 			continue
 		}
+		h.recordCreated(ctx, ignored)
 		created = append(created, *ignored)
 	}
 
@@ -118,12 +151,12 @@ func (h *IgnoredHandler) BulkDelete(w http.ResponseWriter, r *http.Request) {
 		IDs []int64 `json:"ids"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		RespondBadRequest(w, "invalid request body")
 		return
 	}
 
 	if len(input.IDs) == 0 {
-		http.Error(w, "ids array is required", http.StatusBadRequest)
+		RespondBadRequest(w, "ids array is required")
 		return
 	}
 
@@ -131,7 +164,7 @@ func (h *IgnoredHandler) BulkDelete(w http.ResponseWriter, r *http.Request) {
 	var failed int
 	ctx := r.Context()
 	for _, id := range input.IDs {
-		if err := h.repo.Delete(ctx, id); err == nil {
+		if err := h.delete(ctx, id); err == nil {
 			deleted++
 		} else {
 			failed++
@@ -143,3 +176,102 @@ func (h *IgnoredHandler) BulkDelete(w http.ResponseWriter, r *http.Request) {
 		"failed":  failed,
 	})
 }
+
+// ListSuggestions returns the dependencies excluded by the Renovate or Dependabot configs of scanned
+// repositories that are not ignored yet. Dismissed suggestions are included with dismissed=true.
+func (h *IgnoredHandler) ListSuggestions(w http.ResponseWriter, r *http.Request) {
+	suggestions, err := h.repo.GetSuggestions(r.Context(), r.URL.Query().Get("dismissed") == "true")
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if suggestions == nil {
+		suggestions = []domain.IgnoreSuggestion{}
+	}
+	json.NewEncoder(w).Encode(suggestions)
+}
+
+// ImportSuggestions creates ignore rules scoped to their repository from the reviewed suggestions
+func (h *IgnoredHandler) ImportSuggestions(w http.ResponseWriter, r *http.Request) {
+	ids, ok := decodeSuggestionIDs(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	suggestions, err := h.repo.GetSuggestionsByIDs(ctx, ids)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	created := []domain.IgnoredDependency{}
+	var duplicates int
+	for _, sg := range suggestions {
+		ignored, err := h.repo.Create(ctx, &domain.IgnoredDependencyInput{
+			Name:       sg.Name,
+			Ecosystem:  sg.Ecosystem,
+			Repository: sg.RepoFullName,
+			Reason:     suggestionReason(sg),
+		})
+		if err != nil {
+			// Count duplicates separately (UNIQUE constraint violation)
+			duplicates++
+			continue
+		}
+		h.recordCreated(ctx, ignored)
+		created = append(created, *ignored)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"created":    len(created),
+		"not_found":  len(ids) - len(suggestions),
+		"duplicates": duplicates,
+		"items":      created,
+	})
+}
+
+// DismissSuggestions hides suggestions that should not be imported; they are not offered again
+func (h *IgnoredHandler) DismissSuggestions(w http.ResponseWriter, r *http.Request) {
+	ids, ok := decodeSuggestionIDs(w, r)
+	if !ok {
+		return
+	}
+
+	dismissed, err := h.repo.SetSuggestionsDismissed(r.Context(), ids, true)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dismissed": dismissed,
+	})
+}
+
+// decodeSuggestionIDs reads the suggestion IDs of a review request, responding with an error when
+// the body is invalid
+func decodeSuggestionIDs(w http.ResponseWriter, r *http.Request) ([]int64, bool) {
+	LimitBody(r)
+	var input struct {
+		IDs []int64 `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return nil, false
+	}
+	if len(input.IDs) == 0 {
+		RespondBadRequest(w, "ids array is required")
+		return nil, false
+	}
+	return input.IDs, true
+}
+
+// suggestionReason describes where an imported ignore rule came from, e.g. "Imported from renovate.json (ignoreDeps)"
+func suggestionReason(sg domain.IgnoreSuggestion) string {
+	reason := "Imported from " + sg.ConfigPath
+	if sg.Detail != "" {
+		reason += " (" + sg.Detail + ")"
+	}
+	return reason
+}
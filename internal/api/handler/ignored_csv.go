@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+// ignoreCSVColumns are the columns of an ignore list export, in order. Imports accept them in
+// any order; only name is required.
+var ignoreCSVColumns = []string{"name", "ecosystem", "repository", "reason", "expires_at"}
+
+// ExportCSV downloads the ignore list as CSV, one rule per row with its expiry as an RFC 3339
+// timestamp, for review in spreadsheets or import into another instance
+func (h *IgnoredHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
+	ignored, err := h.repo.GetAll(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=ignored_dependencies_%s.csv", time.Now().Format("2006-01-02")))
+
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	writer.Write(ignoreCSVColumns)
+	for _, ig := range ignored {
+		var expires string
+		if ig.ExpiresAt != nil {
+			expires = ig.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+		writer.Write([]string{ig.Name, ig.Ecosystem, ig.Repository, ig.Reason, expires})
+	}
+}
+
+// ImportCSV creates ignore rules from a CSV file in the format of ExportCSV, uploaded either as
+// the request body (Content-Type text/csv) or as the "file" field of a multipart form. Every row
+// gets its own result; rules already in the ignore list are skipped. With dry_run=true nothing
+// is created.
+func (h *IgnoredHandler) ImportCSV(w http.ResponseWriter, r *http.Request) {
+	LimitBody(r)
+	data, format, err := readImportFile(r)
+	if err != nil {
+		RespondBadRequest(w, err.Error())
+		return
+	}
+	if format != "csv" {
+		RespondBadRequest(w, "ignore list imports must be CSV files")
+		return
+	}
+
+	inputs, err := parseIgnoreCSV(data)
+	if err != nil {
+		RespondBadRequest(w, err.Error())
+		return
+	}
+	if len(inputs) == 0 {
+		RespondBadRequest(w, "import file contains no ignore rules")
+		return
+	}
+
+	ctx := r.Context()
+	existing, err := h.repo.GetAll(ctx)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	rules := make(map[string]bool, len(existing))
+	for _, ig := range existing {
+		rules[ignoreLabel(ig.Name, ig.Ecosystem, ig.Repository)] = true
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	result := domain.IgnoreImportResult{DryRun: dryRun, Rows: make([]domain.IgnoreImportRow, len(inputs))}
+	now := time.Now()
+	for i := range inputs {
+		input := &inputs[i]
+		res := &result.Rows[i]
+		res.Row = i + 1
+		res.Name = input.Name
+
+		label := ignoreLabel(input.Name, input.Ecosystem, input.Repository)
+		switch {
+		case input.Name == "":
+			res.Status, res.Error = "invalid", "name is required"
+		case input.ExpiresAt != nil && !input.ExpiresAt.After(now):
+			res.Status, res.Error = "invalid", "expires_at must be in the future"
+		case rules[label]:
+			res.Status, res.Error = "skipped", "already ignored"
+		case dryRun:
+			res.Status = "valid"
+			rules[label] = true // duplicates within the file
+		default:
+			ignored, err := h.repo.Create(ctx, input)
+			if err != nil {
+				res.Status, res.Error = "failed", "failed to save ignore rule"
+				break
+			}
+			h.recordCreated(ctx, ignored)
+			res.Status, res.IgnoredID = "created", ignored.ID
+			rules[label] = true
+		}
+	}
+
+	for _, res := range result.Rows {
+		switch res.Status {
+		case "created", "valid":
+			result.Created++
+		case "skipped":
+			result.Skipped++
+		default:
+			result.Failed++
+		}
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// parseIgnoreCSV parses ignore rules from a CSV file with a header row naming the columns
+func parseIgnoreCSV(data []byte) ([]domain.IgnoredDependencyInput, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+
+	inputs := make([]domain.IgnoredDependencyInput, 0, len(records)-1)
+	for n, record := range records[1:] {
+		var input domain.IgnoredDependencyInput
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			switch header[i] {
+			case "name":
+				input.Name = value
+			case "ecosystem":
+				input.Ecosystem = value
+			case "repository":
+				input.Repository = value
+			case "reason":
+				input.Reason = value
+			case "expires_at":
+				if value == "" {
+					continue
+				}
+				expires, err := parseExpiry(value)
+				if err != nil {
+					return nil, fmt.Errorf("row %d: column expires_at: %w", n+1, err)
+				}
+				input.ExpiresAt = &expires
+			default:
+				return nil, fmt.Errorf("row %d: unknown column %q", n+1, header[i])
+			}
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+// parseExpiry parses an RFC 3339 timestamp or a date, which expires at its end in UTC
+func parseExpiry(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if d, err := time.Parse(time.DateOnly, value); err == nil {
+		return d.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, errors.New("must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+}
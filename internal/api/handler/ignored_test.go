@@ -31,6 +31,11 @@ func TestIgnoredHandler_Create_Validation(t *testing.T) {
 			body:           `{"reason": "test"}`,
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "expiry in the past",
+			body:           `{"name": "lodash", "expires_at": "2020-01-01T00:00:00Z"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -193,3 +198,85 @@ func TestIgnoredDependencyInput_JSON(t *testing.T) {
 		t.Errorf("Reason = %q, want %q", decoded.Reason, input.Reason)
 	}
 }
+
+func TestIgnoredHandler_Suggestions_Validation(t *testing.T) {
+	h := &IgnoredHandler{} // nil repo - testing validation only
+
+	for _, body := range []string{"not json", `{}`, `{"ids": []}`} {
+		for name, handle := range map[string]http.HandlerFunc{"import": h.ImportSuggestions, "dismiss": h.DismissSuggestions} {
+			req := httptest.NewRequest("POST", "/ignored/suggestions/"+name, bytes.NewBufferString(body))
+			w := httptest.NewRecorder()
+			handle(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s %s: status = %d, want %d", name, body, w.Code, http.StatusBadRequest)
+			}
+		}
+	}
+}
+
+func TestSuggestionReason(t *testing.T) {
+	sg := domain.IgnoreSuggestion{ConfigPath: "renovate.json", Detail: "ignoreDeps"}
+	if got := suggestionReason(sg); got != "Imported from renovate.json (ignoreDeps)" {
+		t.Errorf("suggestionReason() = %q", got)
+	}
+}
+
+func TestParseIgnoreCSV(t *testing.T) {
+	data := "Reason,name,ecosystem,repository,expires_at\n" +
+		"\"Pinned, see #12\",lodash,npm,,2030-01-31T00:00:00Z\n" +
+		"CVE triaged,log4j-core,maven,org/api,2030-06-30\n"
+
+	inputs, err := parseIgnoreCSV([]byte(data))
+	if err != nil {
+		t.Fatalf("parseIgnoreCSV() error = %v", err)
+	}
+	if len(inputs) != 2 {
+		t.Fatalf("len(inputs) = %d, want 2", len(inputs))
+	}
+	if inputs[0].Name != "lodash" || inputs[0].Reason != "Pinned, see #12" || inputs[0].Repository != "" {
+		t.Errorf("inputs[0] = %+v", inputs[0])
+	}
+	if inputs[1].Repository != "org/api" || inputs[1].ExpiresAt == nil || inputs[1].ExpiresAt.Format("2006-01-02") != "2030-06-30" {
+		t.Errorf("inputs[1] = %+v, want a rule for org/api expiring 2030-06-30", inputs[1])
+	}
+
+	for _, data := range []string{
+		"name,scope\nlodash,npm\n",
+		"name,expires_at\nlodash,next year\n",
+		"name\n\"lodash\n",
+	} {
+		if _, err := parseIgnoreCSV([]byte(data)); err == nil {
+			t.Errorf("parseIgnoreCSV(%q) error = nil", data)
+		}
+	}
+}
+
+func TestIgnoredHandler_ImportCSV_Validation(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"json body", "application/json", `{"name": "lodash"}`},
+		{"yaml body", "application/yaml", "- name: lodash\n"},
+		{"header only", "text/csv", "name,ecosystem\n"},
+		{"unknown column", "text/csv", "name,version\nlodash,4.17.21\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &IgnoredHandler{} // nil dependencies - testing validation only
+
+			req := httptest.NewRequest("POST", "/ignored/import", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+
+			h.ImportCSV(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/jiin/stale/internal/service/scanner"
+	"github.com/jiin/stale/internal/service/scheduler"
+)
+
+// maxIngestDependencies bounds the dependencies of a single ingested repository
+const maxIngestDependencies = 5000
+
+// defaultIngestSource is the name of the external source of ingested repositories without one
+const defaultIngestSource = "external"
+
+// maxSBOMSize is the maximum size of an uploaded SBOM, which lists every package of an image (16MB)
+const maxSBOMSize = 16 << 20
+
+// maxIngestBatchSize is the maximum size of an ingested batch of repositories (16MB)
+const maxIngestBatchSize = 16 << 20
+
+// maxIngestBatchRepositories bounds the repositories of an ingested batch
+const maxIngestBatchRepositories = 100
+
+// IngestHandler records dependency lists pushed by external scanners, e.g. CI jobs running other
+// tools in networks stale cannot reach
+type IngestHandler struct {
+	sourceRepo *repository.SourceRepository
+	scanner    *scanner.Scanner
+	scheduler  *scheduler.Scheduler
+	onPersist  func() // called after dependencies were recorded (e.g. to clear caches)
+}
+
+func NewIngestHandler(sourceRepo *repository.SourceRepository, scanner *scanner.Scanner, scheduler *scheduler.Scheduler, onPersist func()) *IngestHandler {
+	return &IngestHandler{sourceRepo: sourceRepo, scanner: scanner, scheduler: scheduler, onPersist: onPersist}
+}
+
+// Ingest replaces the dependencies of a repository with the pushed list. The repository is
+// attributed to an external source, which is created on first use.
+func (h *IngestHandler) Ingest(w http.ResponseWriter, r *http.Request) {
+	LimitBody(r)
+	var input domain.IngestRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	h.ingest(w, r, input, 0)
+}
+
+// IngestBatch replaces the dependencies of several repositories at once, e.g. every repository an
+// agent found in a source. Repositories whose list is invalid are reported in the result and
+// skipped; the others are recorded.
+func (h *IngestHandler) IngestBatch(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxIngestBatchSize)
+	var input domain.IngestBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+	if len(input.Repositories) == 0 {
+		RespondBadRequest(w, "repositories is required")
+		return
+	}
+	if len(input.Repositories) > maxIngestBatchRepositories {
+		RespondBadRequest(w, fmt.Sprintf("at most %d repositories can be ingested at once", maxIngestBatchRepositories))
+		return
+	}
+
+	ctx := r.Context()
+	if !checkWritable(w, ctx, h.scheduler) {
+		return
+	}
+
+	ecosystems := h.scanner.Ecosystems()
+	result := domain.IngestBatchResult{Repositories: make([]domain.IngestBatchItem, len(input.Repositories))}
+	recorded := false
+	for i := range input.Repositories {
+		item := &result.Repositories[i]
+		msg := validateIngestRequest(&input.Repositories[i], ecosystems)
+		item.Repository = input.Repositories[i].Repository
+		if msg != "" {
+			item.Error = msg
+			continue
+		}
+
+		res, conflict, err := h.record(ctx, input.Repositories[i])
+		if err != nil {
+			RespondInternalError(w, err)
+			return
+		}
+		if conflict != "" {
+			item.Error = conflict
+			continue
+		}
+		item.Result = res
+		recorded = true
+	}
+
+	if recorded && h.onPersist != nil {
+		h.onPersist()
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// IngestSBOM replaces the dependencies of a repository with the components of a CycloneDX or
+// SPDX JSON SBOM. The repository is given by ?repository, with optional ?source, ?branch,
+// ?html_url and ?manifest_path (default "sbom").
+func (h *IngestHandler) IngestSBOM(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxSBOMSize)
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	deps, skipped, err := scanner.ParseSBOM(content)
+	if err != nil {
+		RespondBadRequest(w, err.Error())
+		return
+	}
+	if len(deps) == 0 {
+		RespondBadRequest(w, "SBOM has no components of a supported ecosystem")
+		return
+	}
+
+	query := r.URL.Query()
+	manifestPath := cmp.Or(strings.TrimSpace(query.Get("manifest_path")), "sbom")
+	for i := range deps {
+		deps[i].ManifestPath = manifestPath
+	}
+	h.ingest(w, r, domain.IngestRequest{
+		Source:       query.Get("source"),
+		Repository:   query.Get("repository"),
+		HTMLURL:      query.Get("html_url"),
+		Branch:       query.Get("branch"),
+		Dependencies: deps,
+	}, skipped)
+}
+
+// ingest validates and records an ingested dependency list. skipped is the number of entries
+// dropped before, e.g. SBOM components of unsupported ecosystems.
+func (h *IngestHandler) ingest(w http.ResponseWriter, r *http.Request, input domain.IngestRequest, skipped int) {
+	if msg := validateIngestRequest(&input, h.scanner.Ecosystems()); msg != "" {
+		RespondBadRequest(w, msg)
+		return
+	}
+
+	ctx := r.Context()
+	if !checkWritable(w, ctx, h.scheduler) {
+		return
+	}
+
+	result, conflict, err := h.record(ctx, input)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if conflict != "" {
+		RespondError(w, http.StatusConflict, conflict, nil)
+		return
+	}
+
+	result.Skipped = skipped
+
+	if h.onPersist != nil {
+		h.onPersist()
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// record stores a validated dependency list. A list that cannot be attributed to an external
+// source is not stored; the reason is returned as conflict.
+func (h *IngestHandler) record(ctx context.Context, input domain.IngestRequest) (result *domain.IngestResult, conflict string, err error) {
+	source, err := findOrCreateExternalSource(ctx, h.sourceRepo, input.Source)
+	if err != nil {
+		return nil, "", err
+	}
+	if !source.External() {
+		return nil, fmt.Sprintf("source %q is not an external source", source.Name), nil
+	}
+
+	repo := domain.Repository{
+		Name:          input.Repository[strings.LastIndex(input.Repository, "/")+1:],
+		FullName:      input.Repository,
+		DefaultBranch: input.Branch,
+		HTMLURL:       input.HTMLURL,
+	}
+	deps := make([]domain.Dependency, len(input.Dependencies))
+	for i, d := range input.Dependencies {
+		deps[i] = domain.Dependency{
+			Name:           d.Name,
+			CurrentVersion: d.Version,
+			LatestVersion:  d.LatestVersion,
+			Ecosystem:      d.Ecosystem,
+			Type:           d.Type,
+			Scope:          d.Scope,
+			ManifestPath:   d.ManifestPath,
+			ManifestType:   d.ManifestType,
+		}
+	}
+
+	result, err = h.scanner.Ingest(ctx, *source, repo, deps)
+	if errors.Is(err, scanner.ErrNotExternal) {
+		return nil, err.Error(), nil
+	}
+	return result, "", err
+}
+
+// findOrCreateExternalSource returns the source with the given name, or creates an external one
+func findOrCreateExternalSource(ctx context.Context, sourceRepo *repository.SourceRepository, name string) (*domain.Source, error) {
+	sources, err := sourceRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sources {
+		if sources[i].Name == name {
+			return &sources[i], nil
+		}
+	}
+
+	return sourceRepo.Create(ctx, domain.SourceInput{Name: name, Type: domain.SourceTypeExternal})
+}
+
+// validateIngestRequest normalizes the ingested list and returns a message describing
+// the first invalid field, or an empty string when the list is valid
+func validateIngestRequest(input *domain.IngestRequest, ecosystems []string) string {
+	input.Source = strings.TrimSpace(input.Source)
+	if input.Source == "" {
+		input.Source = defaultIngestSource
+	}
+	if len(input.Source) > 100 {
+		return "source name too long"
+	}
+
+	input.Repository = strings.Trim(strings.TrimSpace(input.Repository), "/")
+	owner, name, ok := strings.Cut(input.Repository, "/")
+	if !ok || owner == "" || name == "" {
+		return "repository must be a full name such as owner/repo"
+	}
+	if len(input.Repository) > 255 || strings.ContainsAny(input.Repository, " \t\n") || strings.Contains(input.Repository, "//") {
+		return "invalid repository name"
+	}
+
+	input.HTMLURL = strings.TrimSpace(input.HTMLURL)
+	if input.HTMLURL != "" {
+		u, err := url.Parse(input.HTMLURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "html_url must be an http or https URL"
+		}
+	}
+
+	input.Branch = strings.TrimSpace(input.Branch)
+	if !isValidBranchName(input.Branch) {
+		return "invalid branch name"
+	}
+
+	if len(input.Dependencies) == 0 {
+		return "dependencies are required"
+	}
+	if len(input.Dependencies) > maxIngestDependencies {
+		return fmt.Sprintf("at most %d dependencies can be ingested at once", maxIngestDependencies)
+	}
+	for i := range input.Dependencies {
+		if msg := validateIngestDependency(&input.Dependencies[i], ecosystems); msg != "" {
+			return fmt.Sprintf("dependencies[%d]: %s", i, msg)
+		}
+	}
+
+	return ""
+}
+
+// validateIngestDependency normalizes a dependency of an ingested list and returns a message
+// describing the first invalid field
+func validateIngestDependency(dep *domain.IngestDependency, ecosystems []string) string {
+	dep.Name = strings.TrimSpace(dep.Name)
+	dep.Version = strings.TrimSpace(dep.Version)
+	dep.LatestVersion = strings.TrimSpace(dep.LatestVersion)
+	dep.Ecosystem = strings.ToLower(strings.TrimSpace(dep.Ecosystem))
+	dep.ManifestPath = strings.Trim(strings.TrimSpace(dep.ManifestPath), "/")
+	dep.ManifestType = strings.TrimSpace(dep.ManifestType)
+
+	if dep.Name == "" || dep.Version == "" {
+		return "name and version are required"
+	}
+	if len(dep.Name) > 255 || len(dep.Version) > 100 || len(dep.LatestVersion) > 100 {
+		return "name or version too long"
+	}
+	if !slices.Contains(ecosystems, dep.Ecosystem) {
+		return "ecosystem must be one of " + strings.Join(ecosystems, ", ")
+	}
+	if dep.Type == "" {
+		dep.Type = domain.DependencyTypeProd
+	}
+	if !slices.Contains(domain.DependencyTypes, dep.Type) {
+		return "type must be one of " + strings.Join(domain.DependencyTypes, ", ")
+	}
+	if dep.Scope != "" && !slices.Contains(domain.DependencyScopes, dep.Scope) {
+		return "scope must be one of " + strings.Join(domain.DependencyScopes, ", ")
+	}
+	if dep.ManifestPath == "" {
+		dep.ManifestPath = cmp.Or(dep.ManifestType, defaultIngestSource)
+	}
+	if len(dep.ManifestPath) > 500 || strings.Contains(dep.ManifestPath, "..") {
+		return "invalid manifest_path"
+	}
+	return ""
+}
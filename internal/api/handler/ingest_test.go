@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestIngestHandler_Ingest_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", "not json"},
+		{"missing repository", `{"dependencies": [{"name": "react", "version": "18.2.0", "ecosystem": "npm"}]}`},
+		{"repository without owner", `{"repository": "repo", "dependencies": [{"name": "react", "version": "18.2.0", "ecosystem": "npm"}]}`},
+		{"invalid html url", `{"repository": "org/repo", "html_url": "ftp://example.com", "dependencies": [{"name": "react", "version": "18.2.0", "ecosystem": "npm"}]}`},
+		{"invalid branch", `{"repository": "org/repo", "branch": "bad..ref", "dependencies": [{"name": "react", "version": "18.2.0", "ecosystem": "npm"}]}`},
+		{"no dependencies", `{"repository": "org/repo", "dependencies": []}`},
+		{"missing version", `{"repository": "org/repo", "dependencies": [{"name": "react", "ecosystem": "npm"}]}`},
+		{"unknown ecosystem", `{"repository": "org/repo", "dependencies": [{"name": "react", "version": "18.2.0", "ecosystem": "pypi"}]}`},
+		{"unknown type", `{"repository": "org/repo", "dependencies": [{"name": "react", "version": "18.2.0", "ecosystem": "npm", "type": "runtime"}]}`},
+		{"unknown scope", `{"repository": "org/repo", "dependencies": [{"name": "junit", "version": "4.13", "ecosystem": "maven", "scope": "dev"}]}`},
+		{"manifest path traversal", `{"repository": "org/repo", "dependencies": [{"name": "react", "version": "18.2.0", "ecosystem": "npm", "manifest_path": "../package.json"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &IngestHandler{} // nil dependencies - testing validation only
+
+			req := httptest.NewRequest("POST", "/ingest", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			h.Ingest(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestValidateIngestRequest_Defaults(t *testing.T) {
+	input := domain.IngestRequest{
+		Repository: " /org/repo/ ",
+		Dependencies: []domain.IngestDependency{
+			{Name: "react", Version: "18.2.0", Ecosystem: "NPM", ManifestType: "package.json"},
+			{Name: "lodash", Version: "4.17.21", Ecosystem: "npm"},
+		},
+	}
+
+	if msg := validateIngestRequest(&input, []string{"maven", "npm"}); msg != "" {
+		t.Fatalf("validateIngestRequest() = %q, want valid", msg)
+	}
+	if input.Source != "external" || input.Repository != "org/repo" {
+		t.Errorf("source, repository = %q, %q, want external, org/repo", input.Source, input.Repository)
+	}
+	first, second := input.Dependencies[0], input.Dependencies[1]
+	if first.Ecosystem != "npm" || first.Type != domain.DependencyTypeProd || first.ManifestPath != "package.json" {
+		t.Errorf("first dependency = %+v, want npm dependency declared in package.json", first)
+	}
+	if second.ManifestPath != "external" {
+		t.Errorf("manifest path = %q, want external", second.ManifestPath)
+	}
+}
+
+func TestIngestHandler_IngestSBOM_Validation(t *testing.T) {
+	const sbom = `{"bomFormat": "CycloneDX", "components": [{"name": "lodash", "purl": "pkg:npm/lodash@4.17.21"}]}`
+	tests := []struct {
+		name  string
+		query string
+		body  string
+	}{
+		{"not an sbom", "?repository=org/repo", `{"name": "app"}`},
+		{"no supported components", "?repository=org/repo", `{"bomFormat": "CycloneDX", "components": [{"name": "requests", "purl": "pkg:pypi/requests@2.31.0"}]}`},
+		{"missing repository", "", sbom},
+		{"invalid branch", "?repository=org/repo&branch=bad..ref", sbom},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &IngestHandler{} // nil dependencies - testing validation only
+
+			req := httptest.NewRequest("POST", "/ingest/sbom"+tt.query, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			h.IngestSBOM(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestIngestHandler_IngestBatch_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", "not json"},
+		{"no repositories", `{"repositories": []}`},
+		{"too many repositories", `{"repositories": [` + strings.Repeat(`{},`, maxIngestBatchRepositories) + `{}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &IngestHandler{} // nil dependencies - testing validation only
+
+			req := httptest.NewRequest("POST", "/ingest/batch", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			h.IngestBatch(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
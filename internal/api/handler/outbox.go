@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+)
+
+// OutboxHandler serves the integration events of the outbox to polling consumers
+type OutboxHandler struct {
+	repo *repository.OutboxRepository
+}
+
+func NewOutboxHandler(repo *repository.OutboxRepository) *OutboxHandler {
+	return &OutboxHandler{repo: repo}
+}
+
+const (
+	defaultOutboxLimit = 100
+	maxOutboxLimit     = 1000
+)
+
+// Stream returns the integration events after a cursor, oldest first (?since, default 0, and
+// ?limit, default 100). Consumers pass the returned next cursor as since to poll for more.
+func (h *OutboxHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	var since int64
+	if s := r.URL.Query().Get("since"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 0 {
+			RespondBadRequest(w, "since must be a non-negative event ID")
+			return
+		}
+		since = n
+	}
+	limit := defaultOutboxLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxOutboxLimit {
+			RespondBadRequest(w, "limit must be between 1 and 1000")
+			return
+		}
+		limit = n
+	}
+
+	events, err := h.repo.GetSince(r.Context(), since, limit)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	page := domain.OutboxPage{Events: events, Next: since}
+	if page.Events == nil {
+		page.Events = []domain.OutboxEvent{}
+	}
+	if len(events) > 0 {
+		page.Next = events[len(events)-1].ID
+	}
+	json.NewEncoder(w).Encode(page)
+}
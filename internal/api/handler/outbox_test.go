@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOutboxHandler_Stream_InvalidQuery(t *testing.T) {
+	h := &OutboxHandler{} // nil repo - testing validation only
+
+	for _, query := range []string{"since=abc", "since=-1", "limit=0", "limit=1001"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events/stream?"+query, nil)
+		w := httptest.NewRecorder()
+		h.Stream(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
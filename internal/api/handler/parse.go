@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/service/scanner"
+)
+
+// ParseHandler parses uploaded manifests, to debug what scans read from a manifest without
+// running one
+type ParseHandler struct{}
+
+func NewParseHandler() *ParseHandler {
+	return &ParseHandler{}
+}
+
+// ParseResponse is what a scan would read from a manifest, before latest versions are looked up
+type ParseResponse struct {
+	ManifestPath string                     `json:"manifest_path"`
+	ManifestType string                     `json:"manifest_type"`
+	Dependencies []domain.Dependency        `json:"dependencies"`
+	Skipped      []domain.SkippedDependency `json:"skipped"` // entries left out, with the reason
+}
+
+// Parse parses the manifest in the request body. ?path gives its path or file name, e.g.
+// app/build.gradle, which decides how it is parsed.
+func (h *ParseHandler) Parse(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimSpace(r.URL.Query().Get("path")), "/")
+	if path == "" {
+		RespondBadRequest(w, "path is required, e.g. build.gradle")
+		return
+	}
+	if !scanner.IsManifest(path) {
+		RespondBadRequest(w, "unsupported manifest type, see the manifests scans read")
+		return
+	}
+
+	LimitBody(r)
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	deps, skipped, err := scanner.ParseManifest(path, content)
+	if err != nil {
+		RespondBadRequest(w, "failed to parse manifest: "+err.Error())
+		return
+	}
+	if deps == nil {
+		deps = []domain.Dependency{}
+	}
+	if skipped == nil {
+		skipped = []domain.SkippedDependency{}
+	}
+
+	json.NewEncoder(w).Encode(ParseResponse{
+		ManifestPath: path,
+		ManifestType: path[strings.LastIndex(path, "/")+1:],
+		Dependencies: deps,
+		Skipped:      skipped,
+	})
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestParseHandler_Parse(t *testing.T) {
+	h := NewParseHandler()
+	gradle := `dependencies {
+    implementation 'com.google.guava:guava:33.0.0-jre'
+    implementation "org.slf4j:slf4j-api:$slf4jVersion"
+}`
+
+	req := httptest.NewRequest("POST", "/parse?path=app/build.gradle", strings.NewReader(gradle))
+	w := httptest.NewRecorder()
+	h.Parse(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp ParseResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.ManifestType != "build.gradle" || len(resp.Dependencies) != 1 || resp.Dependencies[0].Name != "com.google.guava:guava" {
+		t.Errorf("response = %+v, want guava from build.gradle", resp)
+	}
+	if len(resp.Skipped) != 1 || resp.Skipped[0].Reason != domain.SkipReasonUnresolvedProperty || resp.Skipped[0].ManifestPath != "app/build.gradle" {
+		t.Errorf("skipped = %+v, want slf4j-api with an unresolved property", resp.Skipped)
+	}
+}
+
+func TestParseHandler_Parse_Validation(t *testing.T) {
+	h := NewParseHandler()
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"missing path", "", "{}"},
+		{"unsupported manifest", "requirements.txt", "django==5.0"},
+		{"invalid manifest", "package.json", "not json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/parse?path="+tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.Parse(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
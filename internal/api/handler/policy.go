@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+)
+
+// PolicyHandler manages the package allowlist and denylist and lists the dependencies violating
+// them. Violations are reported apart from staleness.
+type PolicyHandler struct {
+	repo      *repository.PolicyRepository
+	onChanged func() // called after the policies changed (e.g. to clear cached stats)
+}
+
+func NewPolicyHandler(repo *repository.PolicyRepository, onChanged func()) *PolicyHandler {
+	return &PolicyHandler{repo: repo, onChanged: onChanged}
+}
+
+// List returns the package policies
+func (h *PolicyHandler) List(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.repo.GetAll(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if policies == nil {
+		policies = []domain.PackagePolicy{}
+	}
+	json.NewEncoder(w).Encode(policies)
+}
+
+func (h *PolicyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	LimitBody(r)
+	var input domain.PackagePolicyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+	if msg := validatePolicyInput(&input); msg != "" {
+		RespondBadRequest(w, msg)
+		return
+	}
+
+	policy, err := h.repo.Create(r.Context(), input)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			RespondError(w, http.StatusConflict, "the package is already on this list", nil)
+			return
+		}
+		RespondInternalError(w, err)
+		return
+	}
+	h.changed()
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(policy)
+}
+
+func (h *PolicyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	deleted, err := h.repo.Delete(r.Context(), id)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if !deleted {
+		RespondNotFound(w, "policy not found")
+		return
+	}
+	h.changed()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Violations lists the dependencies on denied packages or missing from the allowlist of their
+// ecosystem, optionally filtered by violation (denied or not_allowed) and ecosystem
+func (h *PolicyHandler) Violations(w http.ResponseWriter, r *http.Request) {
+	violation := r.URL.Query().Get("violation")
+	if violation != "" && violation != domain.ViolationDenied && violation != domain.ViolationNotAllowed {
+		RespondBadRequest(w, "violation must be 'denied' or 'not_allowed'")
+		return
+	}
+
+	violations, err := h.repo.GetViolations(r.Context(), violation, r.URL.Query().Get("ecosystem"))
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if violations == nil {
+		violations = []domain.PolicyViolation{}
+	}
+	json.NewEncoder(w).Encode(violations)
+}
+
+func (h *PolicyHandler) changed() {
+	if h.onChanged != nil {
+		h.onChanged()
+	}
+}
+
+// validatePolicyInput trims the input and returns an error message if it is invalid
+func validatePolicyInput(input *domain.PackagePolicyInput) string {
+	input.List = strings.TrimSpace(input.List)
+	input.Ecosystem = strings.TrimSpace(input.Ecosystem)
+	input.Name = strings.TrimSpace(input.Name)
+	input.Reason = strings.TrimSpace(input.Reason)
+
+	if input.List != domain.PolicyAllow && input.List != domain.PolicyDeny {
+		return "list must be 'allow' or 'deny'"
+	}
+	if input.Name == "" {
+		return "name is required"
+	}
+	// GLOB and path.Match share their wildcard syntax
+	if _, err := path.Match(input.Name, ""); err != nil {
+		return "name must be a valid pattern"
+	}
+	if len(input.Reason) > 500 {
+		return "reason must be at most 500 characters"
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestValidatePolicyInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input domain.PackagePolicyInput
+		valid bool
+	}{
+		{"deny", domain.PackagePolicyInput{List: "deny", Name: "left-pad"}, true},
+		{"allow pattern", domain.PackagePolicyInput{List: "allow", Ecosystem: "npm", Name: " @acme/* "}, true},
+		{"unknown list", domain.PackagePolicyInput{List: "block", Name: "left-pad"}, false},
+		{"missing name", domain.PackagePolicyInput{List: "deny", Name: " "}, false},
+		{"invalid pattern", domain.PackagePolicyInput{List: "deny", Name: "[abc"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			msg := validatePolicyInput(&input)
+			if (msg == "") != tt.valid {
+				t.Errorf("validatePolicyInput() = %q, want valid=%v", msg, tt.valid)
+			}
+		})
+	}
+}
+
+func TestPolicyHandler_Validation(t *testing.T) {
+	h := &PolicyHandler{} // nil repo - testing validation only
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/policies", bytes.NewBufferString("{"))
+	w := httptest.NewRecorder()
+	h.Create(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Create: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/policies/violations?violation=stale", nil)
+	w = httptest.NewRecorder()
+	h.Violations(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Violations: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-chi/chi/v5"
+	"github.com/jiin/stale/internal/api/middleware"
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// RecommendedVersionHandler manages the versions pinned per package, which dependencies are
+// compared against instead of the registry's latest version
+type RecommendedVersionHandler struct {
+	repo      *repository.DependencyRepository
+	recompute func(context.Context) (int64, error) // recomputes is_outdated, see Scheduler.RecomputeOutdated
+}
+
+func NewRecommendedVersionHandler(repo *repository.DependencyRepository, recompute func(context.Context) (int64, error)) *RecommendedVersionHandler {
+	return &RecommendedVersionHandler{repo: repo, recompute: recompute}
+}
+
+// List returns the recommended versions
+func (h *RecommendedVersionHandler) List(w http.ResponseWriter, r *http.Request) {
+	recommended, err := h.repo.GetRecommendedVersions(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if recommended == nil {
+		recommended = []domain.RecommendedVersion{}
+	}
+	json.NewEncoder(w).Encode(recommended)
+}
+
+// Set pins the recommended version of a package and re-evaluates which dependencies are outdated.
+// It requires the admin API key. Package names containing slashes must be URL-encoded.
+func (h *RecommendedVersionHandler) Set(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		RespondForbidden(w, "recommended versions require the admin API key (STALE_ADMIN_API_KEY)")
+		return
+	}
+	ecosystem, name, ok := recommendedPackage(w, r)
+	if !ok {
+		return
+	}
+
+	LimitBody(r)
+	var input domain.RecommendedVersionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+	input.Version = strings.TrimSpace(input.Version)
+	if _, err := semver.NewVersion(input.Version); err != nil {
+		RespondBadRequest(w, "version must be a valid version")
+		return
+	}
+	if len(input.Reason) > 500 {
+		RespondBadRequest(w, "reason must be at most 500 characters")
+		return
+	}
+
+	recommended, err := h.repo.SetRecommendedVersion(r.Context(), ecosystem, name, input)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	h.recomputeOutdated(r.Context())
+	json.NewEncoder(w).Encode(recommended)
+}
+
+// Delete unpins the recommended version of a package, so its dependencies are compared with the
+// latest version again. It requires the admin API key.
+func (h *RecommendedVersionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		RespondForbidden(w, "recommended versions require the admin API key (STALE_ADMIN_API_KEY)")
+		return
+	}
+	ecosystem, name, ok := recommendedPackage(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.repo.DeleteRecommendedVersion(r.Context(), ecosystem, name)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if !deleted {
+		RespondNotFound(w, "recommended version not found")
+		return
+	}
+	h.recomputeOutdated(r.Context())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// recomputeOutdated applies a changed recommended version to the stored dependencies. Failures are
+// logged; the next scan applies it as well.
+func (h *RecommendedVersionHandler) recomputeOutdated(ctx context.Context) {
+	if _, err := h.recompute(ctx); err != nil {
+		log.Error().Err(err).Msg("failed to recompute outdated dependencies after a recommended version change")
+	}
+}
+
+// recommendedPackage reads the ecosystem and name of the package from the URL, responding with an
+// error when they are missing
+func recommendedPackage(w http.ResponseWriter, r *http.Request) (ecosystem, name string, ok bool) {
+	ecosystem = chi.URLParam(r, "ecosystem")
+	name, err := url.PathUnescape(chi.URLParam(r, "name"))
+	if err != nil || ecosystem == "" || name == "" {
+		RespondBadRequest(w, "invalid package")
+		return "", "", false
+	}
+	return ecosystem, name, true
+}
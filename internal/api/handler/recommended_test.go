@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jiin/stale/internal/api/middleware"
+)
+
+func TestRecommendedVersionHandler_RequiresAdmin(t *testing.T) {
+	h := NewRecommendedVersionHandler(nil, nil) // nil repo - the request is rejected first
+
+	for _, handle := range []http.HandlerFunc{h.Set, h.Delete} {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/recommended-versions/npm/react", bytes.NewBufferString(`{"version":"18.2.0"}`))
+		w := httptest.NewRecorder()
+		handle(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+		}
+	}
+}
+
+func TestRecommendedVersionHandler_Set_Validation(t *testing.T) {
+	h := NewRecommendedVersionHandler(nil, nil) // nil repo - testing validation only
+	auth := middleware.Auth(middleware.AuthConfig{AdminAPIKey: "admin-key", Enabled: true})
+
+	tests := []struct {
+		name      string
+		ecosystem string
+		body      string
+	}{
+		{"missing ecosystem", "", `{"version":"18.2.0"}`},
+		{"invalid json", "npm", `{`},
+		{"missing version", "npm", `{}`},
+		{"invalid version", "npm", `{"version":"stable"}`},
+		{"long reason", "npm", `{"version":"18.2.0","reason":"` + string(bytes.Repeat([]byte("x"), 501)) + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/recommended-versions/npm/react", bytes.NewBufferString(tt.body))
+			req.Header.Set("X-API-Key", "admin-key")
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("ecosystem", tt.ecosystem)
+			rctx.URLParams.Add("name", "react")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			auth(http.HandlerFunc(h.Set)).ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
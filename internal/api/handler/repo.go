@@ -1,22 +1,41 @@
 package handler
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jiin/stale/internal/domain"
 	"github.com/jiin/stale/internal/repository"
+	"github.com/jiin/stale/internal/service/scanner"
+	"github.com/jiin/stale/internal/service/scheduler"
 )
 
 type RepoHandler struct {
-	repo    *repository.RepoRepository
-	depRepo *repository.DependencyRepository
+	repo       *repository.RepoRepository
+	depRepo    *repository.DependencyRepository
+	sourceRepo *repository.SourceRepository
+	events     *repository.EventRepository
+	scanner    *scanner.Scanner
+	scheduler  *scheduler.Scheduler
 }
 
-func NewRepoHandler(repo *repository.RepoRepository, depRepo *repository.DependencyRepository) *RepoHandler {
-	return &RepoHandler{repo: repo, depRepo: depRepo}
+func NewRepoHandler(
+	repo *repository.RepoRepository,
+	depRepo *repository.DependencyRepository,
+	sourceRepo *repository.SourceRepository,
+	events *repository.EventRepository,
+	scanner *scanner.Scanner,
+	scheduler *scheduler.Scheduler,
+) *RepoHandler {
+	return &RepoHandler{repo: repo, depRepo: depRepo, sourceRepo: sourceRepo, events: events, scanner: scanner, scheduler: scheduler}
 }
 
 func (h *RepoHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -66,6 +85,56 @@ func (h *RepoHandler) Get(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(repo)
 }
 
+// Update applies user-editable settings (currently the branch override) to a repository
+func (h *RepoHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	LimitBody(r)
+	var input domain.RepositoryPatch
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	if input.BranchOverride != nil {
+		branch := strings.TrimSpace(*input.BranchOverride)
+		if !isValidBranchName(branch) {
+			RespondBadRequest(w, "invalid branch name")
+			return
+		}
+		if err := h.repo.UpdateBranchOverride(r.Context(), id, branch); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				RespondNotFound(w, "repository not found")
+				return
+			}
+			RespondInternalError(w, err)
+			return
+		}
+	}
+
+	repo, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		RespondNotFound(w, "repository not found")
+		return
+	}
+	json.NewEncoder(w).Encode(repo)
+}
+
+// isValidBranchName performs basic sanity checks on a git ref name (empty is allowed to clear)
+func isValidBranchName(branch string) bool {
+	if len(branch) > 255 {
+		return false
+	}
+	if strings.Contains(branch, "..") || strings.HasPrefix(branch, "-") {
+		return false
+	}
+	return !strings.ContainsAny(branch, " \t\n~^:?*[\\")
+}
+
 func (h *RepoHandler) GetDependencies(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
@@ -73,7 +142,13 @@ func (h *RepoHandler) GetDependencies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	deps, err := h.depRepo.GetByRepoID(r.Context(), id)
+	// Dependencies of an additional branch are requested with ?branch=
+	var deps []domain.Dependency
+	if branch := r.URL.Query().Get("branch"); branch != "" {
+		deps, err = h.depRepo.GetByRepoBranch(r.Context(), id, branch)
+	} else {
+		deps, err = h.depRepo.GetByRepoID(r.Context(), id)
+	}
 	if err != nil {
 		RespondInternalError(w, err)
 		return
@@ -84,6 +159,145 @@ func (h *RepoHandler) GetDependencies(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(deps)
 }
 
+// GetManifests lists the manifest files of a repository with dependency and outdated counts
+func (h *RepoHandler) GetManifests(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	manifests, err := h.depRepo.GetManifestsByRepoID(r.Context(), id)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if manifests == nil {
+		manifests = []domain.ManifestSummary{}
+	}
+	json.NewEncoder(w).Encode(manifests)
+}
+
+// GetManifestContent returns the content of a manifest as processed by the last scan that parsed
+// it, e.g. GET /repositories/1/manifests/packages/web/package.json/content
+func (h *RepoHandler) GetManifestContent(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	path, ok := strings.CutSuffix(chi.URLParam(r, "*"), "/content")
+	if !ok || path == "" {
+		RespondNotFound(w, "not found")
+		return
+	}
+	if path, err = url.PathUnescape(path); err != nil {
+		RespondBadRequest(w, "invalid manifest path")
+		return
+	}
+
+	mc, err := h.depRepo.GetManifestContent(r.Context(), id, path)
+	if errors.Is(err, sql.ErrNoRows) {
+		RespondNotFound(w, "no content stored for this manifest")
+		return
+	}
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Last-Modified", mc.StoredAt.UTC().Format(http.TimeFormat))
+	w.Write(mc.Content)
+}
+
+// GetSkipped lists the dependencies of a repository that were skipped or could not be checked
+// by the last scan, with the reason
+func (h *RepoHandler) GetSkipped(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	skipped, err := h.depRepo.GetSkippedByRepoID(r.Context(), id)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if skipped == nil {
+		skipped = []domain.SkippedDependency{}
+	}
+	json.NewEncoder(w).Encode(skipped)
+}
+
+// GetBranches lists the additional branches scanned for a repository with dependency counts
+func (h *RepoHandler) GetBranches(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	branches, err := h.depRepo.GetBranchesByRepoID(r.Context(), id)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if branches == nil {
+		branches = []domain.BranchSummary{}
+	}
+	json.NewEncoder(w).Encode(branches)
+}
+
+// Compare fetches the manifests of two refs of a repository on demand and returns the
+// dependency diff between them. Nothing is persisted.
+func (h *RepoHandler) Compare(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	base := strings.TrimSpace(r.URL.Query().Get("base"))
+	head := strings.TrimSpace(r.URL.Query().Get("head"))
+	if base == "" || head == "" {
+		RespondBadRequest(w, "base and head are required")
+		return
+	}
+	if !isValidBranchName(base) || !isValidBranchName(head) {
+		RespondBadRequest(w, "invalid branch name")
+		return
+	}
+
+	repo, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		RespondNotFound(w, "repository not found")
+		return
+	}
+
+	source, err := h.sourceRepo.GetByID(r.Context(), repo.SourceID)
+	if err != nil {
+		RespondNotFound(w, "source not found")
+		return
+	}
+
+	baseDeps, err := h.scanner.DependenciesAtRef(r.Context(), *source, repo.FullName, base)
+	if err != nil {
+		RespondError(w, http.StatusBadGateway, "failed to fetch manifests for "+base, err)
+		return
+	}
+
+	headDeps, err := h.scanner.DependenciesAtRef(r.Context(), *source, repo.FullName, head)
+	if err != nil {
+		RespondError(w, http.StatusBadGateway, "failed to fetch manifests for "+head, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(scanner.CompareDependencies(base, head, baseDeps, headDeps))
+}
+
 func (h *RepoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
@@ -91,21 +305,36 @@ func (h *RepoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ctx := r.Context()
+	snapshot, err := snapshotRepository(ctx, h.repo, h.depRepo, id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		RespondInternalError(w, err)
+		return
+	}
+
 	// Delete dependencies first
-	if err := h.depRepo.DeleteByRepoID(r.Context(), id); err != nil {
+	if err := h.depRepo.DeleteByRepoID(ctx, id); err != nil {
 		RespondInternalError(w, err)
 		return
 	}
 
 	// Delete repository
-	if err := h.repo.Delete(r.Context(), id); err != nil {
+	if err := h.repo.Delete(ctx, id); err != nil {
 		RespondInternalError(w, err)
 		return
 	}
+	h.recordDeleted(ctx, id, snapshot)
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// recordDeleted records the deletion of a repository so it can be undone
+func (h *RepoHandler) recordDeleted(ctx context.Context, id int64, snapshot *domain.DeletedRepository) {
+	if snapshot != nil {
+		recordEvent(ctx, h.events, domain.EventRepositoryDeleted, id, snapshot.Repository.FullName, snapshot)
+	}
+}
+
 type BulkDeleteRequest struct {
 	IDs []int64 `json:"ids"`
 }
@@ -140,6 +369,16 @@ func (h *RepoHandler) BulkDelete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	for _, id := range req.IDs {
+		snapshot, err := snapshotRepository(ctx, h.repo, h.depRepo, id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			response.Failed++
+			response.Errors = append(response.Errors, BulkDeleteError{
+				ID:    id,
+				Error: "failed to load repository: " + err.Error(),
+			})
+			continue
+		}
+
 		// Delete dependencies first
 		if err := h.depRepo.DeleteByRepoID(ctx, id); err != nil {
 			response.Failed++
@@ -159,9 +398,132 @@ func (h *RepoHandler) BulkDelete(w http.ResponseWriter, r *http.Request) {
 			})
 			continue
 		}
+		h.recordDeleted(ctx, id, snapshot)
 		response.Deleted++
 	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+type BulkRescanRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+// Bulk rescan result statuses
+const (
+	RescanAccepted = "accepted"
+	RescanDeferred = "deferred" // rescanned too recently, coalesced into a pending rescan
+	RescanFailed   = "failed"
+)
+
+type BulkRescanResult struct {
+	ID        int64      `json:"id"`
+	Status    string     `json:"status"`
+	ScanAfter *time.Time `json:"scan_after,omitempty"` // when a deferred rescan runs
+	Error     string     `json:"error,omitempty"`
+}
+
+type BulkRescanResponse struct {
+	ScanID   *int64             `json:"scan_id,omitempty"` // unset when queued or nothing was accepted
+	Queued   bool               `json:"queued"`            // the rescan runs once the running scan completes
+	Accepted int                `json:"accepted"`
+	Deferred int                `json:"deferred"`
+	Failed   int                `json:"failed"`
+	Results  []BulkRescanResult `json:"results"`
+}
+
+// BulkRescan queues a scan of the given repositories only. Repositories that are unknown, no
+// longer found in their source or belong to a disabled source are reported as failed, and those
+// rescanned within the rescan interval setting as deferred.
+func (h *RepoHandler) BulkRescan(w http.ResponseWriter, r *http.Request) {
+	LimitBody(r)
+	var req BulkRescanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	if len(req.IDs) == 0 {
+		RespondBadRequest(w, "no ids provided")
+		return
+	}
+
+	ctx := r.Context()
+	response := BulkRescanResponse{
+		Results: make([]BulkRescanResult, 0, len(req.IDs)),
+	}
+
+	var accepted []int64
+	seen := make(map[int64]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if reason := h.rescanRejection(ctx, id); reason != "" {
+			response.Failed++
+			response.Results = append(response.Results, BulkRescanResult{ID: id, Status: RescanFailed, Error: reason})
+			continue
+		}
+		accepted = append(accepted, id)
+		response.Results = append(response.Results, BulkRescanResult{ID: id, Status: RescanAccepted})
+	}
+
+	if len(accepted) > 0 {
+		status, err := h.scheduler.RescanRepositories(ctx, accepted)
+		if err != nil {
+			if errors.Is(err, scheduler.ErrMaintenanceMode) {
+				RespondError(w, http.StatusServiceUnavailable, "scans are disabled during maintenance", nil)
+				return
+			}
+			RespondInternalError(w, err)
+			return
+		}
+		response.Queued = status.Queued
+		if status.Scan != nil {
+			response.ScanID = &status.Scan.ID
+		}
+		for i, result := range response.Results {
+			if scanAfter, ok := status.Deferred[result.ID]; ok {
+				response.Results[i].Status = RescanDeferred
+				response.Results[i].ScanAfter = &scanAfter
+			}
+		}
+	}
+	for _, result := range response.Results {
+		switch result.Status {
+		case RescanAccepted:
+			response.Accepted++
+		case RescanDeferred:
+			response.Deferred++
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+// rescanRejection returns why a repository cannot be rescanned, or an empty string
+func (h *RepoHandler) rescanRejection(ctx context.Context, id int64) string {
+	repo, err := h.repo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "repository not found"
+	}
+	if err != nil {
+		return "failed to load repository: " + err.Error()
+	}
+	if repo.RemovedAt != nil {
+		return "repository is no longer found in its source"
+	}
+
+	source, err := h.sourceRepo.GetByID(ctx, repo.SourceID)
+	if err != nil {
+		return "failed to load source: " + err.Error()
+	}
+	if source.Disabled() {
+		return "source is disabled after repeated scan failures"
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestIsValidBranchName(t *testing.T) {
+	tests := []struct {
+		branch string
+		valid  bool
+	}{
+		{"", true},
+		{"main", true},
+		{"develop", true},
+		{"release/1.x", true},
+		{"feature/foo-bar_baz", true},
+		{"has space", false},
+		{"bad..ref", false},
+		{"-leading-dash", false},
+		{"weird~ref", false},
+		{"glob*", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			if got := isValidBranchName(tt.branch); got != tt.valid {
+				t.Errorf("isValidBranchName(%q) = %v, want %v", tt.branch, got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestRepoHandler_Update_Validation(t *testing.T) {
+	h := &RepoHandler{} // nil repo - testing validation only
+
+	req := httptest.NewRequest("PATCH", "/repositories/abc", bytes.NewBufferString(`{"branch_override": "develop"}`))
+	w := httptest.NewRecorder()
+
+	// chi.URLParam returns "" without router context, which fails id parsing
+	h.Update(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestRepoHandler_Compare_Validation(t *testing.T) {
+	h := &RepoHandler{} // nil repos - testing validation only
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"missing base", "?head=release/1.x"},
+		{"missing head", "?base=main"},
+		{"invalid ref", "?base=main&head=bad..ref"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/repositories/1/compare"+tt.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "1")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			h.Compare(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestRepoHandler_GetManifestContent_Validation(t *testing.T) {
+	h := &RepoHandler{} // nil repos - testing validation only
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"missing content suffix", "package.json", http.StatusNotFound},
+		{"missing manifest path", "content", http.StatusNotFound},
+		{"invalid escape", "bad%zz/content", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/repositories/1/manifests/", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "1")
+			rctx.URLParams.Add("*", tt.path)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			h.GetManifestContent(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepoHandler_BulkRescan_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", "not json"},
+		{"empty ids array", `{"ids": []}`},
+		{"missing ids", `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &RepoHandler{}
+
+			req := httptest.NewRequest("POST", "/repositories/bulk-rescan", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			h.BulkRescan(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
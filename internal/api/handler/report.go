@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/service/scheduler"
+)
+
+// maxComparisonDays is the longest period a comparison report looks back over; older state is
+// not archived
+const maxComparisonDays = 90
+
+// ReportHandler serves the reports also delivered as notifications
+type ReportHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+func NewReportHandler(scheduler *scheduler.Scheduler) *ReportHandler {
+	return &ReportHandler{scheduler: scheduler}
+}
+
+// Comparison compares the outdated dependencies now with the state archived 30 and 90 days ago,
+// as in the monthly report email. A days query parameter of comma-separated numbers of days
+// (e.g. 7,30) chooses other periods.
+func (h *ReportHandler) Comparison(w http.ResponseWriter, r *http.Request) {
+	days := domain.ComparisonReportDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		days = nil
+		for _, s := range strings.Split(v, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || n < 1 || n > maxComparisonDays {
+				RespondBadRequest(w, "days must be comma-separated numbers between 1 and 90")
+				return
+			}
+			days = append(days, n)
+		}
+		if len(days) > 5 {
+			RespondBadRequest(w, "at most 5 periods can be compared")
+			return
+		}
+	}
+
+	report, err := h.scheduler.ComparisonReport(r.Context(), days)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(report)
+}
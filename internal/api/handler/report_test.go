@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReportHandler_Comparison_InvalidDays(t *testing.T) {
+	h := &ReportHandler{} // nil scheduler - testing validation only
+
+	for _, days := range []string{"abc", "0", "91", "30,", "1,2,3,4,5,6"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/reports/comparison?days="+days, nil)
+		w := httptest.NewRecorder()
+		h.Comparison(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("days %s: status = %d, want %d", days, w.Code, http.StatusBadRequest)
+		}
+	}
+}
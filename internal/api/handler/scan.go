@@ -1,15 +1,19 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jiin/stale/internal/api/problem"
 	"github.com/jiin/stale/internal/domain"
 	"github.com/jiin/stale/internal/repository"
 	"github.com/jiin/stale/internal/service/scheduler"
+	"github.com/rs/zerolog/log"
 )
 
 type ScanHandler struct {
@@ -38,7 +42,15 @@ func (h *ScanHandler) TriggerScan(w http.ResponseWriter, r *http.Request) {
 	scan, err := h.scheduler.TriggerScan(r.Context(), req.SourceID)
 	if err != nil {
 		if errors.Is(err, scheduler.ErrScanAlreadyRunning) {
-			RespondError(w, http.StatusConflict, "a scan is already running", nil)
+			RespondProblem(w, http.StatusConflict, problem.CodeScanRunning, "a scan is already running", nil)
+			return
+		}
+		if errors.Is(err, scheduler.ErrMaintenanceMode) {
+			RespondError(w, http.StatusServiceUnavailable, "scans are disabled during maintenance", nil)
+			return
+		}
+		if errors.Is(err, scheduler.ErrSourceDisabled) {
+			RespondError(w, http.StatusConflict, "source is disabled after repeated scan failures; re-enable it first", nil)
 			return
 		}
 		RespondInternalError(w, err)
@@ -46,7 +58,20 @@ func (h *ScanHandler) TriggerScan(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(scan)
+	json.NewEncoder(w).Encode(h.withProgress(r.Context(), scan))
+}
+
+// progressHistory is the number of previous scans the progress of a scan is estimated from
+const progressHistory = 5
+
+// withProgress returns a scan with an estimate of its progress. Without history the estimate
+// only covers the repositories listed so far.
+func (h *ScanHandler) withProgress(ctx context.Context, scan *domain.ScanJob) domain.RunningScan {
+	history, err := h.repo.GetRecentCompleted(ctx, scan.SourceID, progressHistory)
+	if err != nil {
+		log.Warn().Err(err).Int64("scan_id", scan.ID).Msg("failed to load previous scans")
+	}
+	return domain.RunningScan{ScanJob: *scan, Progress: scheduler.EstimateProgress(*scan, history, time.Now())}
 }
 
 func (h *ScanHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -76,6 +101,31 @@ func (h *ScanHandler) Get(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(scan)
 }
 
+// Usage returns the provider API usage of a scan per source
+func (h *ScanHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	ctx := r.Context()
+	if _, err := h.repo.GetByID(ctx, id); err != nil {
+		RespondNotFound(w, "scan not found")
+		return
+	}
+
+	usage, err := h.repo.GetSourceUsage(ctx, id)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if usage == nil {
+		usage = []domain.ScanSourceUsage{}
+	}
+	json.NewEncoder(w).Encode(usage)
+}
+
 func (h *ScanHandler) GetRunning(w http.ResponseWriter, r *http.Request) {
 	// First, cleanup any stale scans that have been running too long
 	_, _ = h.repo.CleanupStaleScans(r.Context())
@@ -86,7 +136,7 @@ func (h *ScanHandler) GetRunning(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("null"))
 		return
 	}
-	json.NewEncoder(w).Encode(scan)
+	json.NewEncoder(w).Encode(h.withProgress(r.Context(), scan))
 }
 
 func (h *ScanHandler) Cancel(w http.ResponseWriter, r *http.Request) {
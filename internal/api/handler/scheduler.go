@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jiin/stale/internal/service/scheduler"
+)
+
+type SchedulerHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+func NewSchedulerHandler(scheduler *scheduler.Scheduler) *SchedulerHandler {
+	return &SchedulerHandler{scheduler: scheduler}
+}
+
+// PauseRequest pauses scheduled scans, optionally until a given time
+type PauseRequest struct {
+	Reason          string     `json:"reason,omitempty"`
+	Until           *time.Time `json:"until,omitempty"`
+	MaintenanceMode bool       `json:"maintenance_mode,omitempty"` // also reject manual scans
+}
+
+// Status returns whether scheduled scans are paused
+func (h *SchedulerHandler) Status(w http.ResponseWriter, r *http.Request) {
+	state, err := h.scheduler.State(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(state)
+}
+
+// Pause stops scheduled scans without changing their schedule
+func (h *SchedulerHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	LimitBody(r)
+	var req PauseRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			RespondBadRequest(w, "invalid request body")
+			return
+		}
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if len(req.Reason) > 500 {
+		RespondBadRequest(w, "reason must be at most 500 characters")
+		return
+	}
+	if req.Until != nil && !req.Until.After(time.Now()) {
+		RespondBadRequest(w, "until must be in the future")
+		return
+	}
+
+	state, err := h.scheduler.Pause(r.Context(), req.Reason, req.Until, req.MaintenanceMode)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(state)
+}
+
+// Resume resumes scheduled scans and leaves maintenance mode
+func (h *SchedulerHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	state, err := h.scheduler.Resume(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(state)
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchedulerHandler_Pause_Validation(t *testing.T) {
+	h := NewSchedulerHandler(nil)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid body", `{"reason":`},
+		{"until in the past", `{"until": "` + time.Now().Add(-time.Hour).Format(time.RFC3339) + `"}`},
+		{"reason too long", `{"reason": "` + strings.Repeat("x", 501) + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/scheduler/pause", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.Pause(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -1,32 +1,52 @@
 package handler
 
 import (
+	"cmp"
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/i18n"
 	"github.com/jiin/stale/internal/repository"
 	"github.com/jiin/stale/internal/service/email"
+	"github.com/jiin/stale/internal/service/observability"
+	"github.com/jiin/stale/internal/service/scanner"
 	"github.com/jiin/stale/internal/service/scheduler"
 	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
 )
 
 type SettingsHandler struct {
 	repo         *repository.SettingsRepository
+	sourceRepo   *repository.SourceRepository
 	scheduler    *scheduler.Scheduler
 	emailService *email.Service
+	webhooks     *observability.Service
 }
 
 func NewSettingsHandler(
 	repo *repository.SettingsRepository,
+	sourceRepo *repository.SourceRepository,
 	scheduler *scheduler.Scheduler,
 	emailService *email.Service,
+	webhooks *observability.Service,
 ) *SettingsHandler {
 	return &SettingsHandler{
 		repo:         repo,
+		sourceRepo:   sourceRepo,
 		scheduler:    scheduler,
 		emailService: emailService,
+		webhooks:     webhooks,
 	}
 }
 
@@ -37,9 +57,15 @@ func (h *SettingsHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Mask SMTP password in response
+	// Mask SMTP password and API key in response
 	if settings.EmailSMTPPass != "" {
-		settings.EmailSMTPPass = "********"
+		settings.EmailSMTPPass = maskedSecret
+	}
+	if settings.ObservabilityAPIKey != "" {
+		settings.ObservabilityAPIKey = maskedSecret
+	}
+	if settings.FailureWebhookURL != "" {
+		settings.FailureWebhookURL = maskedSecret
 	}
 
 	json.NewEncoder(w).Encode(settings)
@@ -49,22 +75,20 @@ func (h *SettingsHandler) Update(w http.ResponseWriter, r *http.Request) {
 	LimitBody(r)
 	var input domain.SettingsInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		RespondBadRequest(w, "invalid request body")
 		return
 	}
 
-	// Validate cron expression if provided
-	if input.ScheduleCron != nil {
-		parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-		if _, err := parser.Parse(*input.ScheduleCron); err != nil {
-			RespondBadRequest(w, "invalid cron expression")
-			return
-		}
+	if diagnostics := validateSettings(&input); len(diagnostics) > 0 {
+		RespondBadRequest(w, diagnostics[0].Message)
+		return
 	}
+	dropMaskedSecrets(&input)
 
-	// Don't update password if it's the masked value
-	if input.EmailSMTPPass != nil && *input.EmailSMTPPass == "********" {
-		input.EmailSMTPPass = nil
+	previous, err := h.repo.Get(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
 	}
 
 	if err := h.repo.Update(r.Context(), &input); err != nil {
@@ -73,10 +97,26 @@ func (h *SettingsHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Reload scheduler if schedule settings changed
-	if input.ScheduleEnabled != nil || input.ScheduleCron != nil {
+	if input.ScheduleEnabled != nil || input.ScheduleCron != nil || input.ScheduleTimezone != nil {
 		h.scheduler.ReloadSchedule()
 	}
 
+	if input.RegistryFallback != nil {
+		h.scheduler.ApplyRegistryFallback(r.Context())
+	}
+	if input.RegistryCacheSeconds != nil || input.RegistryCacheOverrides != nil || input.StatsCacheSeconds != nil || input.ReposCacheSeconds != nil || input.ManifestRefreshHours != nil {
+		h.scheduler.ApplyCacheSettings(r.Context())
+	}
+
+	// Re-evaluate stored dependencies in the background if version comparison changed
+	if input.IgnorePrereleases != nil && *input.IgnorePrereleases != previous.IgnorePrereleases {
+		go func() {
+			if _, err := h.scheduler.RecomputeOutdated(context.Background()); err != nil {
+				log.Error().Err(err).Msg("failed to recompute outdated dependencies")
+			}
+		}()
+	}
+
 	// Return updated settings
 	settings, err := h.repo.Get(r.Context())
 	if err != nil {
@@ -84,14 +124,163 @@ func (h *SettingsHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Mask SMTP password in response
+	// Mask SMTP password and API key in response
 	if settings.EmailSMTPPass != "" {
-		settings.EmailSMTPPass = "********"
+		settings.EmailSMTPPass = maskedSecret
+	}
+	if settings.ObservabilityAPIKey != "" {
+		settings.ObservabilityAPIKey = maskedSecret
+	}
+	if settings.FailureWebhookURL != "" {
+		settings.FailureWebhookURL = maskedSecret
 	}
 
 	json.NewEncoder(w).Encode(settings)
 }
 
+// PreviewNotification renders the notification of the latest completed scan with the
+// current settings without sending it. The locale query parameter overrides the configured locale.
+func (h *SettingsHandler) PreviewNotification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	settings, err := h.repo.Get(ctx)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	report, err := h.scheduler.LatestNewOutdatedReport(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		RespondNotFound(w, "no completed scan to preview")
+		return
+	}
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	preview, err := h.emailService.PreviewNewOutdatedReport(settings, report, r.URL.Query().Get("locale"))
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(preview)
+}
+
+// settingsCheckTimeout bounds each connectivity check of Validate
+const settingsCheckTimeout = 10 * time.Second
+
+// Validate checks proposed settings without saving them: the checks of Update, then whether the
+// SMTP server accepts proposed email settings and a proposed failure webhook can be reached.
+// Settings the proposal leaves out or masks are checked with their current value.
+func (h *SettingsHandler) Validate(w http.ResponseWriter, r *http.Request) {
+	LimitBody(r)
+	var input domain.SettingsInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	ctx := r.Context()
+	current, err := h.repo.Get(ctx)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	diagnostics := validateSettings(&input)
+	invalid := make(map[string]bool, len(diagnostics))
+	for _, d := range diagnostics {
+		invalid[d.Field] = true
+	}
+	dropMaskedSecrets(&input)
+
+	if input.ScheduleCron != nil && !invalid["schedule_cron"] && !invalid["schedule_timezone"] {
+		timezone := cmp.Or(input.ScheduleTimezone, &current.ScheduleTimezone)
+		if next, _ := nextScan(*input.ScheduleCron, *timezone, time.Now()); next != nil {
+			diagnostics = append(diagnostics, SettingDiagnostic{Field: "schedule_cron", Level: DiagnosticOK, Message: "next run at " + *next})
+		}
+	}
+
+	if proposesSMTP(&input) {
+		diagnostics = append(diagnostics, h.checkSMTP(proposedEmailSettings(*current, &input)))
+	}
+
+	if input.FailureWebhookURL != nil && *input.FailureWebhookURL != "" && !invalid["failure_webhook_url"] {
+		d := SettingDiagnostic{Field: "failure_webhook_url", Level: DiagnosticOK, Message: "webhook is reachable"}
+		checkCtx, cancel := context.WithTimeout(ctx, settingsCheckTimeout)
+		if err := h.webhooks.CheckWebhook(checkCtx, *input.FailureWebhookURL); err != nil {
+			d.Level, d.Message = DiagnosticError, "webhook is not reachable: "+err.Error()
+		}
+		cancel()
+		diagnostics = append(diagnostics, d)
+	}
+
+	validation := SettingsValidation{Valid: true, Diagnostics: diagnostics}
+	for _, d := range diagnostics {
+		if d.Level == DiagnosticError {
+			validation.Valid = false
+		}
+	}
+	if validation.Diagnostics == nil {
+		validation.Diagnostics = []SettingDiagnostic{}
+	}
+	json.NewEncoder(w).Encode(validation)
+}
+
+// proposesSMTP reports whether proposed settings change how the SMTP server is reached
+func proposesSMTP(input *domain.SettingsInput) bool {
+	return input.EmailSMTPHost != nil || input.EmailSMTPPort != nil || input.EmailSMTPUser != nil ||
+		input.EmailSMTPPass != nil || (input.EmailEnabled != nil && *input.EmailEnabled)
+}
+
+// proposedEmailSettings returns the current settings with the proposed SMTP settings applied
+func proposedEmailSettings(current domain.Settings, input *domain.SettingsInput) *domain.Settings {
+	if input.EmailSMTPHost != nil {
+		current.EmailSMTPHost = *input.EmailSMTPHost
+	}
+	if input.EmailSMTPPort != nil {
+		current.EmailSMTPPort = *input.EmailSMTPPort
+	}
+	if input.EmailSMTPUser != nil {
+		current.EmailSMTPUser = *input.EmailSMTPUser
+	}
+	if input.EmailSMTPPass != nil {
+		current.EmailSMTPPass = *input.EmailSMTPPass
+	}
+	return &current
+}
+
+// checkSMTP connects and authenticates to the SMTP server of settings
+func (h *SettingsHandler) checkSMTP(settings *domain.Settings) SettingDiagnostic {
+	d := SettingDiagnostic{Field: "email_smtp_host", Level: DiagnosticOK}
+	if settings.EmailSMTPHost == "" {
+		d.Level, d.Message = DiagnosticWarning, "SMTP host not configured"
+		return d
+	}
+	if err := h.emailService.CheckConnection(settings, settingsCheckTimeout); err != nil {
+		d.Level, d.Message = DiagnosticError, "SMTP check failed: "+err.Error()
+		return d
+	}
+	d.Message = "connected to " + net.JoinHostPort(settings.EmailSMTPHost, strconv.Itoa(settings.EmailSMTPPort))
+	return d
+}
+
+// RecomputeOutdatedResponse reports how many stored dependencies changed outdated status
+type RecomputeOutdatedResponse struct {
+	Changed int64 `json:"changed"`
+}
+
+// RecomputeOutdated re-evaluates whether each stored dependency is outdated with the current
+// version comparison settings, without rescanning providers
+func (h *SettingsHandler) RecomputeOutdated(w http.ResponseWriter, r *http.Request) {
+	changed, err := h.scheduler.RecomputeOutdated(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(RecomputeOutdatedResponse{Changed: changed})
+}
+
 func (h *SettingsHandler) TestEmail(w http.ResponseWriter, r *http.Request) {
 	settings, err := h.repo.Get(r.Context())
 	if err != nil {
@@ -113,32 +302,280 @@ func (h *SettingsHandler) TestEmail(w http.ResponseWriter, r *http.Request) {
 }
 
 type NextScanResponse struct {
-	Enabled  bool    `json:"enabled"`
-	NextRun  *string `json:"next_run,omitempty"`
-	CronExpr string  `json:"cron_expr"`
+	Enabled         bool             `json:"enabled"`
+	NextRun         *string          `json:"next_run,omitempty"`    // in the schedule timezone
+	NextRunAt       *time.Time       `json:"next_run_at,omitempty"` // RFC 3339 with the offset of the schedule timezone
+	CronExpr        string           `json:"cron_expr"`
+	Timezone        string           `json:"timezone"` // schedule timezone, or Local for the server timezone
+	Paused          bool             `json:"paused"`   // scheduled scans are paused; next runs are the ones after resuming
+	PausedUntil     *time.Time       `json:"paused_until,omitempty"`
+	MaintenanceMode bool             `json:"maintenance_mode"`
+	Sources         []SourceNextScan `json:"sources,omitempty"` // sources with their own schedule
 }
 
+// SourceNextScan is the next scheduled scan of a source with its own schedule
+type SourceNextScan struct {
+	SourceID   int64      `json:"source_id"`
+	SourceName string     `json:"source_name"`
+	NextRun    *string    `json:"next_run,omitempty"`
+	NextRunAt  *time.Time `json:"next_run_at,omitempty"`
+	CronExpr   string     `json:"cron_expr"`
+	Timezone   string     `json:"timezone"`
+}
+
+// GetNextScan returns the next run of the global schedule and of each source schedule, shown
+// in the timezone of the schedule. While the scheduler is paused, next runs are the first ones
+// after it resumes by itself, and are left out when it has to be resumed manually.
 func (h *SettingsHandler) GetNextScan(w http.ResponseWriter, r *http.Request) {
 	settings, err := h.repo.Get(r.Context())
 	if err != nil {
 		RespondInternalError(w, err)
 		return
 	}
+	state, err := h.scheduler.State(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
 
+	now := time.Now()
 	response := NextScanResponse{
-		Enabled:  settings.ScheduleEnabled,
-		CronExpr: settings.ScheduleCron,
+		Enabled:         settings.ScheduleEnabled,
+		CronExpr:        settings.ScheduleCron,
+		Timezone:        timezoneName(settings.ScheduleTimezone),
+		Paused:          state.ScansPaused(now),
+		MaintenanceMode: state.InMaintenance(now),
+	}
+	from := &now
+	if response.Paused {
+		response.PausedUntil = state.PausedUntil
+		from = state.PausedUntil
 	}
 
-	if settings.ScheduleEnabled {
-		parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-		schedule, err := parser.Parse(settings.ScheduleCron)
-		if err == nil {
-			nextTime := schedule.Next(time.Now())
-			nextStr := nextTime.Format("2006-01-02 15:04:05")
-			response.NextRun = &nextStr
+	if settings.ScheduleEnabled && from != nil {
+		response.NextRun, response.NextRunAt = nextScan(settings.ScheduleCron, settings.ScheduleTimezone, *from)
+	}
+
+	sources, err := h.sourceRepo.GetAll(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	for _, source := range sources {
+		if source.ScheduleCron == "" {
+			continue
 		}
+		next := SourceNextScan{
+			SourceID:   source.ID,
+			SourceName: source.Name,
+			CronExpr:   source.ScheduleCron,
+			Timezone:   timezoneName(source.ScheduleTimezone),
+		}
+		if from != nil {
+			next.NextRun, next.NextRunAt = nextScan(source.ScheduleCron, source.ScheduleTimezone, *from)
+		}
+		response.Sources = append(response.Sources, next)
 	}
 
 	json.NewEncoder(w).Encode(response)
 }
+
+// nextScan returns the next run of a schedule formatted in its timezone, or nils when the
+// schedule is invalid
+func nextScan(expr, timezone string, now time.Time) (*string, *time.Time) {
+	next, err := scheduler.NextRun(expr, timezone, now)
+	if err != nil {
+		return nil, nil
+	}
+	formatted := next.Format("2006-01-02 15:04:05")
+	return &formatted, &next
+}
+
+// timezoneName names a schedule timezone, Local standing for the server timezone
+func timezoneName(timezone string) string {
+	if timezone == "" {
+		return time.Local.String()
+	}
+	return timezone
+}
+
+// maskedSecret is returned in place of stored secrets; submitting it keeps the stored value
+const maskedSecret = "********"
+
+// Levels of setting diagnostics
+const (
+	DiagnosticError   = "error"
+	DiagnosticWarning = "warning"
+	DiagnosticOK      = "ok"
+)
+
+// SettingDiagnostic is the result of a check of a proposed setting
+type SettingDiagnostic struct {
+	Field   string `json:"field"`
+	Level   string `json:"level"` // error, warning or ok
+	Message string `json:"message"`
+}
+
+// SettingsValidation lists the diagnostics of proposed settings. They are valid unless a
+// diagnostic is an error.
+type SettingsValidation struct {
+	Valid       bool                `json:"valid"`
+	Diagnostics []SettingDiagnostic `json:"diagnostics"`
+}
+
+// validateSettings checks the values of proposed settings and normalizes them, returning an error
+// diagnostic for each invalid one
+func validateSettings(input *domain.SettingsInput) []SettingDiagnostic {
+	var errs []SettingDiagnostic
+	fail := func(field, message string) {
+		errs = append(errs, SettingDiagnostic{Field: field, Level: DiagnosticError, Message: message})
+	}
+
+	if input.ScheduleCron != nil {
+		parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+		if _, err := parser.Parse(*input.ScheduleCron); err != nil {
+			fail("schedule_cron", "invalid cron expression")
+		}
+	}
+	if input.ScheduleTimezone != nil {
+		tz := strings.TrimSpace(*input.ScheduleTimezone)
+		if _, err := scheduler.LoadTimezone(tz); err != nil {
+			fail("schedule_timezone", "invalid schedule_timezone: "+err.Error())
+		} else {
+			input.ScheduleTimezone = &tz
+		}
+	}
+
+	for _, sla := range []struct {
+		field string
+		days  *int
+	}{{"sla_major_days", input.SLAMajorDays}, {"sla_minor_days", input.SLAMinorDays}, {"sla_patch_days", input.SLAPatchDays}} {
+		if sla.days != nil && (*sla.days < 0 || *sla.days > 3650) {
+			fail(sla.field, "SLA days must be between 0 and 3650")
+		}
+	}
+
+	if input.EmailRemindDays != nil && (*input.EmailRemindDays < 0 || *input.EmailRemindDays > 365) {
+		fail("email_remind_days", "email_remind_days must be between 0 and 365")
+	}
+	if input.RescanIntervalMinutes != nil && (*input.RescanIntervalMinutes < 0 || *input.RescanIntervalMinutes > 1440) {
+		fail("rescan_interval_minutes", "rescan_interval_minutes must be between 0 and 1440")
+	}
+	if input.EmailNotifyViewID != nil && *input.EmailNotifyViewID < 0 {
+		fail("email_notify_view_id", "email_notify_view_id must not be negative")
+	}
+	if input.FailureNotifyHours != nil && (*input.FailureNotifyHours < 0 || *input.FailureNotifyHours > 168) {
+		fail("failure_notify_hours", "failure_notify_hours must be between 0 and 168")
+	}
+	if input.SourceDisableAfter != nil && (*input.SourceDisableAfter < 0 || *input.SourceDisableAfter > 100) {
+		fail("source_disable_after", "source_disable_after must be between 0 and 100")
+	}
+	if input.FailureWebhookURL != nil && *input.FailureWebhookURL != "" && *input.FailureWebhookURL != maskedSecret {
+		if u, err := url.Parse(*input.FailureWebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("failure_webhook_url", "failure_webhook_url must be an http or https URL")
+		}
+	}
+
+	if input.Locale != nil {
+		if locale := i18n.Normalize(*input.Locale); locale == "" {
+			fail("locale", "locale must be one of: "+strings.Join(i18n.Supported(), ", "))
+		} else {
+			input.Locale = &locale
+		}
+	}
+	if input.EmailRecipientLocales != nil {
+		if _, err := email.ParseRecipientLocales(*input.EmailRecipientLocales); err != nil {
+			fail("email_recipient_locales", "invalid email_recipient_locales: "+err.Error())
+		}
+	}
+
+	if !normalizeList(input.StatsExcludedTypes, domain.DependencyTypes) {
+		fail("stats_excluded_types", "stats_excluded_types must only contain: "+strings.Join(domain.DependencyTypes, ", "))
+	}
+	if !normalizeList(input.StatsExcludedScopes, domain.DependencyScopes) {
+		fail("stats_excluded_scopes", "stats_excluded_scopes must only contain: "+strings.Join(domain.DependencyScopes, ", "))
+	}
+
+	if input.ObservabilityProvider != nil {
+		switch *input.ObservabilityProvider {
+		case "", "datadog", "newrelic":
+		default:
+			fail("observability_provider", "observability_provider must be 'datadog', 'newrelic' or empty")
+		}
+	}
+	if input.ObservabilitySite != nil {
+		site := strings.TrimSpace(*input.ObservabilitySite)
+		input.ObservabilitySite = &site
+		provider := ""
+		if input.ObservabilityProvider != nil {
+			provider = *input.ObservabilityProvider
+		}
+		if !observability.ValidSite(provider, site) {
+			fail("observability_site", fmt.Sprintf("observability_site must be a DataDog site (%s) or a New Relic region (%s)",
+				strings.Join(observability.DatadogSites, ", "), strings.Join(observability.NewRelicRegions, ", ")))
+		}
+	}
+	if input.ObservabilityAccountID != nil && *input.ObservabilityAccountID != "" {
+		if _, err := strconv.ParseInt(*input.ObservabilityAccountID, 10, 64); err != nil {
+			fail("observability_account_id", "observability_account_id must be numeric")
+		}
+	}
+
+	for _, c := range []struct {
+		field   string
+		seconds *int
+	}{{"registry_cache_seconds", input.RegistryCacheSeconds}, {"stats_cache_seconds", input.StatsCacheSeconds}, {"repos_cache_seconds", input.ReposCacheSeconds}} {
+		if c.seconds != nil && (*c.seconds < 0 || *c.seconds > maxCacheSeconds) {
+			fail(c.field, fmt.Sprintf("%s must be between 0 and %d", c.field, maxCacheSeconds))
+		}
+	}
+	if input.ManifestRefreshHours != nil && (*input.ManifestRefreshHours < 0 || *input.ManifestRefreshHours > maxManifestRefreshHours) {
+		fail("manifest_refresh_hours", fmt.Sprintf("manifest_refresh_hours must be between 0 and %d", maxManifestRefreshHours))
+	}
+	if input.RegistryCacheOverrides != nil {
+		if _, err := scanner.ParseCacheTTLOverrides(*input.RegistryCacheOverrides); err != nil {
+			fail("registry_cache_overrides", "invalid registry_cache_overrides: "+err.Error())
+		}
+	}
+
+	return errs
+}
+
+// maxCacheSeconds bounds cache lifetimes to a day
+const maxCacheSeconds = 86400
+
+// maxManifestRefreshHours bounds how long parsed dependencies of unchanged manifests are reused to 30 days
+const maxManifestRefreshHours = 720
+
+// dropMaskedSecrets leaves out the secrets submitted masked, so their stored values are kept
+func dropMaskedSecrets(input *domain.SettingsInput) {
+	if input.EmailSMTPPass != nil && *input.EmailSMTPPass == maskedSecret {
+		input.EmailSMTPPass = nil
+	}
+	if input.ObservabilityAPIKey != nil && *input.ObservabilityAPIKey == maskedSecret {
+		input.ObservabilityAPIKey = nil
+	}
+	if input.FailureWebhookURL != nil && *input.FailureWebhookURL == maskedSecret {
+		input.FailureWebhookURL = nil
+	}
+}
+
+// normalizeList trims the entries of a comma-separated setting and drops empty ones. It reports
+// false when an entry is not allowed; a nil value is left as is.
+func normalizeList(value *string, allowed []string) bool {
+	if value == nil {
+		return true
+	}
+	var items []string
+	for _, item := range strings.Split(*value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		if !slices.Contains(allowed, item) {
+			return false
+		}
+		items = append(items, item)
+	}
+	*value = strings.Join(items, ",")
+	return true
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 
+	"github.com/jiin/stale/internal/domain"
 	"github.com/robfig/cron/v3"
 )
 
@@ -59,6 +61,111 @@ func TestSettingsUpdateValidation(t *testing.T) {
 			body:           "{}",
 			expectedStatus: http.StatusInternalServerError, // Will fail at repo layer (nil repo)
 		},
+		{
+			name:           "negative SLA days",
+			body:           `{"sla_major_days": -1}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "SLA days out of range",
+			body:           `{"sla_patch_days": 5000}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "reminder window out of range",
+			body:           `{"email_remind_days": 400}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "negative rescan interval",
+			body:           `{"rescan_interval_minutes": -1}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "rescan interval over a day",
+			body:           `{"rescan_interval_minutes": 1441}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "negative notification view",
+			body:           `{"email_notify_view_id": -1}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "failure notification interval out of range",
+			body:           `{"failure_notify_hours": 200}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "negative source disable threshold",
+			body:           `{"source_disable_after": -1}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "failure webhook is not an http URL",
+			body:           `{"failure_webhook_url": "ftp://hooks.example.com/stale"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown schedule timezone",
+			body:           `{"schedule_timezone": "Mars/Olympus"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unsupported locale",
+			body:           `{"locale": "xx"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid recipient locales",
+			body:           `{"email_recipient_locales": "kim@example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown excluded stats type",
+			body:           `{"stats_excluded_types": "peerDependency,runtime"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown excluded stats scope",
+			body:           `{"stats_excluded_scopes": "provided,shaded"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "negative registry cache lifetime",
+			body:           `{"registry_cache_seconds": -1}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "stats cache lifetime over a day",
+			body:           `{"stats_cache_seconds": 86401}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "observability site that is not a DataDog site",
+			body:           `{"observability_provider": "datadog", "observability_site": "attacker.example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "manifest refresh over 30 days",
+			body:           `{"manifest_refresh_hours": 721}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "registry cache override of an unknown ecosystem",
+			body:           `{"registry_cache_overrides": "pypi=60"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown observability provider",
+			body:           `{"observability_provider": "splunk"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "non-numeric New Relic account ID",
+			body:           `{"observability_provider": "newrelic", "observability_account_id": "abc"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -208,3 +315,38 @@ func TestNextScanResponseOmitEmpty(t *testing.T) {
 		t.Error("next_run should be omitted when nil")
 	}
 }
+
+func TestValidateSettings_CollectsDiagnostics(t *testing.T) {
+	var input domain.SettingsInput
+	body := `{"schedule_cron": "bad", "sla_minor_days": -1, "locale": "KO", "failure_webhook_url": "ftp://hooks.example.com"}`
+	if err := json.Unmarshal([]byte(body), &input); err != nil {
+		t.Fatal(err)
+	}
+
+	diagnostics := validateSettings(&input)
+	var fields []string
+	for _, d := range diagnostics {
+		if d.Level != DiagnosticError {
+			t.Errorf("diagnostic %+v is not an error", d)
+		}
+		fields = append(fields, d.Field)
+	}
+	if want := []string{"schedule_cron", "sla_minor_days", "failure_webhook_url"}; !slices.Equal(fields, want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+	if *input.Locale != "ko" {
+		t.Errorf("locale = %q, want it normalized to ko", *input.Locale)
+	}
+}
+
+func TestSettingsValidateInvalidJSON(t *testing.T) {
+	h := NewSettingsHandler(nil, nil, nil, nil, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/settings/validate", bytes.NewBufferString("not json"))
+	rec := httptest.NewRecorder()
+
+	h.Validate(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+)
+
+// slackMaxSkew is the maximum age of a Slack request timestamp (protects against replays)
+const slackMaxSkew = 5 * time.Minute
+
+// slackListLimit caps the number of lines listed in a summary
+const slackListLimit = 10
+
+const slackUsage = "Usage:\n" +
+	"• `/stale repo owner/name` – dependency summary of a repository\n" +
+	"• `/stale package name` – versions of a package across repositories"
+
+// SlackHandler answers Slack slash commands (e.g. `/stale repo owner/name`).
+// Requests are authenticated with the Slack signing secret instead of the API key.
+type SlackHandler struct {
+	signingSecret string
+	repoRepo      *repository.RepoRepository
+	depRepo       *repository.DependencyRepository
+}
+
+func NewSlackHandler(signingSecret string, repoRepo *repository.RepoRepository, depRepo *repository.DependencyRepository) *SlackHandler {
+	return &SlackHandler{signingSecret: signingSecret, repoRepo: repoRepo, depRepo: depRepo}
+}
+
+// slackResponse is a slash command response message
+type slackResponse struct {
+	ResponseType string `json:"response_type"` // ephemeral (only visible to the caller) or in_channel
+	Text         string `json:"text"`
+}
+
+// Command handles a slash command request
+func (h *SlackHandler) Command(w http.ResponseWriter, r *http.Request) {
+	if h.signingSecret == "" {
+		RespondNotFound(w, "Slack integration is not configured")
+		return
+	}
+
+	LimitBody(r)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	if !verifySlackSignature(h.signingSecret, r.Header.Get("X-Slack-Request-Timestamp"), r.Header.Get("X-Slack-Signature"), body, time.Now()) {
+		RespondUnauthorized(w, "invalid Slack signature")
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	text, err := h.execute(r, form.Get("text"))
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(slackResponse{ResponseType: "ephemeral", Text: text})
+}
+
+// execute runs a command and returns the message text
+func (h *SlackHandler) execute(r *http.Request, text string) (string, error) {
+	fields := strings.Fields(text)
+	if len(fields) != 2 {
+		return slackUsage, nil
+	}
+
+	ctx := r.Context()
+	switch strings.ToLower(fields[0]) {
+	case "repo":
+		repo, err := h.repoRepo.GetByFullName(ctx, fields[1])
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Sprintf("Repository `%s` is not tracked by stale.", fields[1]), nil
+			}
+			return "", err
+		}
+		deps, err := h.depRepo.GetByRepoID(ctx, repo.ID)
+		if err != nil {
+			return "", err
+		}
+		return formatSlackRepoSummary(repo, deps), nil
+	case "package", "pkg":
+		deps, err := h.depRepo.GetFilteredWithAll(ctx, "", "", fields[1], "", "")
+		if err != nil {
+			return "", err
+		}
+		return formatSlackPackageSummary(fields[1], deps), nil
+	default:
+		return slackUsage, nil
+	}
+}
+
+// verifySlackSignature checks the v0 request signature Slack computes with the signing secret
+func verifySlackSignature(secret, timestamp, signature string, body []byte, now time.Time) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > slackMaxSkew || skew < -slackMaxSkew {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func formatSlackRepoSummary(repo *domain.Repository, deps []domain.Dependency) string {
+	var outdated []domain.Dependency
+	for _, dep := range deps {
+		if dep.IsOutdated {
+			outdated = append(outdated, dep)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s* (`%s`)\n", repo.FullName, repo.DefaultBranch)
+	fmt.Fprintf(&b, "%d dependencies, %d outdated", len(deps), len(outdated))
+	if repo.LastScanAt != nil {
+		fmt.Fprintf(&b, " · last scanned %s", repo.LastScanAt.Format("2006-01-02 15:04"))
+	}
+	if repo.RemovedAt != nil {
+		b.WriteString(" · no longer found in its source")
+	}
+
+	for i, dep := range outdated {
+		if i == slackListLimit {
+			fmt.Fprintf(&b, "\n…and %d more", len(outdated)-slackListLimit)
+			break
+		}
+		fmt.Fprintf(&b, "\n• `%s` %s → %s", dep.Name, dep.CurrentVersion, dep.LatestVersion)
+	}
+	return b.String()
+}
+
+func formatSlackPackageSummary(name string, deps []domain.DependencyWithRepo) string {
+	if len(deps) == 0 {
+		return fmt.Sprintf("Package `%s` is not used by any tracked repository.", name)
+	}
+
+	repos := make(map[string]bool)
+	outdatedRepos := make(map[string]bool)
+	latest := ""
+	for _, dep := range deps {
+		repos[dep.RepoFullName] = true
+		if dep.IsOutdated {
+			outdatedRepos[dep.RepoFullName] = true
+		}
+		if dep.LatestVersion != "" {
+			latest = dep.LatestVersion
+		}
+	}
+
+	// Outdated usages first, then by repository
+	sorted := make([]domain.DependencyWithRepo, len(deps))
+	copy(sorted, deps)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].IsOutdated != sorted[j].IsOutdated {
+			return sorted[i].IsOutdated
+		}
+		return sorted[i].RepoFullName < sorted[j].RepoFullName
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s*", name)
+	if latest != "" {
+		fmt.Fprintf(&b, " (latest %s)", latest)
+	}
+	fmt.Fprintf(&b, "\nUsed by %d repositories, outdated in %d", len(repos), len(outdatedRepos))
+
+	for i, dep := range sorted {
+		if i == slackListLimit {
+			fmt.Fprintf(&b, "\n…and %d more", len(sorted)-slackListLimit)
+			break
+		}
+		marker := ""
+		if dep.IsOutdated {
+			marker = " ⚠️"
+		}
+		fmt.Fprintf(&b, "\n• %s: %s%s", dep.RepoFullName, dep.CurrentVersion, marker)
+	}
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func signSlack(secret, timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySlackSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte("command=%2Fstale&text=repo+org%2Fweb")
+	ts := strconv.FormatInt(now.Unix(), 10)
+	valid := signSlack("secret", ts, string(body))
+
+	if !verifySlackSignature("secret", ts, valid, body, now) {
+		t.Error("expected valid signature")
+	}
+	if verifySlackSignature("other", ts, valid, body, now) {
+		t.Error("expected signature with wrong secret to fail")
+	}
+	if verifySlackSignature("secret", ts, valid, []byte("tampered"), now) {
+		t.Error("expected signature of tampered body to fail")
+	}
+	if verifySlackSignature("secret", ts, valid, body, now.Add(10*time.Minute)) {
+		t.Error("expected stale timestamp to fail")
+	}
+	if verifySlackSignature("secret", "not-a-number", valid, body, now) {
+		t.Error("expected invalid timestamp to fail")
+	}
+}
+
+func TestSlackHandler_Command(t *testing.T) {
+	t.Run("not configured", func(t *testing.T) {
+		h := NewSlackHandler("", nil, nil)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/slack/command", strings.NewReader("text=help"))
+		w := httptest.NewRecorder()
+		h.Command(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		h := NewSlackHandler("secret", nil, nil)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/slack/command", strings.NewReader("text=help"))
+		req.Header.Set("X-Slack-Request-Timestamp", strconv.FormatInt(time.Now().Unix(), 10))
+		req.Header.Set("X-Slack-Signature", "v0=deadbeef")
+		w := httptest.NewRecorder()
+		h.Command(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		h := NewSlackHandler("secret", nil, nil) // nil repos - help does not query
+		body := "command=%2Fstale&text=help"
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/slack/command", strings.NewReader(body))
+		req.Header.Set("X-Slack-Request-Timestamp", ts)
+		req.Header.Set("X-Slack-Signature", signSlack("secret", ts, body))
+		w := httptest.NewRecorder()
+		h.Command(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+		if !strings.Contains(w.Body.String(), "Usage") {
+			t.Errorf("expected usage text, got %s", w.Body.String())
+		}
+	})
+}
+
+func TestFormatSlackRepoSummary(t *testing.T) {
+	repo := &domain.Repository{FullName: "org/web", DefaultBranch: "main"}
+	deps := []domain.Dependency{
+		{Name: "react", CurrentVersion: "^17.0.0", LatestVersion: "18.2.0", IsOutdated: true},
+		{Name: "lodash", CurrentVersion: "4.17.21", LatestVersion: "4.17.21"},
+	}
+
+	got := formatSlackRepoSummary(repo, deps)
+	for _, want := range []string{"*org/web* (`main`)", "2 dependencies, 1 outdated", "• `react` ^17.0.0 → 18.2.0"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "lodash") {
+		t.Errorf("summary should only list outdated dependencies:\n%s", got)
+	}
+}
+
+func TestFormatSlackPackageSummary(t *testing.T) {
+	if got := formatSlackPackageSummary("left-pad", nil); !strings.Contains(got, "not used") {
+		t.Errorf("unexpected summary for unused package: %s", got)
+	}
+
+	deps := []domain.DependencyWithRepo{
+		{Dependency: domain.Dependency{Name: "react", CurrentVersion: "18.2.0", LatestVersion: "18.2.0"}, RepoFullName: "org/a"},
+		{Dependency: domain.Dependency{Name: "react", CurrentVersion: "^17.0.0", LatestVersion: "18.2.0", IsOutdated: true}, RepoFullName: "org/b"},
+	}
+	got := formatSlackPackageSummary("react", deps)
+	if !strings.Contains(got, "*react* (latest 18.2.0)") || !strings.Contains(got, "Used by 2 repositories, outdated in 1") {
+		t.Errorf("unexpected summary:\n%s", got)
+	}
+	if strings.Index(got, "org/b") > strings.Index(got, "org/a") {
+		t.Errorf("outdated usages should be listed first:\n%s", got)
+	}
+}
@@ -1,27 +1,40 @@
 package handler
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jiin/stale/internal/api/middleware"
 	"github.com/jiin/stale/internal/domain"
 	"github.com/jiin/stale/internal/repository"
 	"github.com/jiin/stale/internal/service/github"
 	"github.com/jiin/stale/internal/service/gitlab"
+	"github.com/jiin/stale/internal/service/gitrepo"
+	"github.com/jiin/stale/internal/service/scanner"
+	"github.com/jiin/stale/internal/service/scheduler"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
 )
 
 type SourceHandler struct {
-	repo    *repository.SourceRepository
-	repoRep *repository.RepoRepository
-	depRepo *repository.DependencyRepository
+	repo      *repository.SourceRepository
+	repoRep   *repository.RepoRepository
+	depRepo   *repository.DependencyRepository
+	events    *repository.EventRepository
+	scanner   *scanner.Scanner
+	scheduler *scheduler.Scheduler
 }
 
-func NewSourceHandler(repo *repository.SourceRepository, repoRep *repository.RepoRepository, depRepo *repository.DependencyRepository) *SourceHandler {
-	return &SourceHandler{repo: repo, repoRep: repoRep, depRepo: depRepo}
+func NewSourceHandler(repo *repository.SourceRepository, repoRep *repository.RepoRepository, depRepo *repository.DependencyRepository, events *repository.EventRepository, scanner *scanner.Scanner, scheduler *scheduler.Scheduler) *SourceHandler {
+	return &SourceHandler{repo: repo, repoRep: repoRep, depRepo: depRepo, events: events, scanner: scanner, scheduler: scheduler}
 }
 
 func (h *SourceHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -51,6 +64,27 @@ func (h *SourceHandler) Get(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(source)
 }
 
+// RevealToken returns the decrypted token of a source. It requires the admin API key.
+func (h *SourceHandler) RevealToken(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		RespondForbidden(w, "revealing tokens requires the admin API key (STALE_ADMIN_API_KEY)")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	source, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		RespondNotFound(w, "source not found")
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"token": source.Token})
+}
+
 func (h *SourceHandler) Create(w http.ResponseWriter, r *http.Request) {
 	LimitBody(r)
 	var input domain.SourceInput
@@ -59,60 +93,77 @@ func (h *SourceHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if input.Name == "" || input.Token == "" {
-		RespondBadRequest(w, "name and token are required")
+	if msg := validateSourceInput(&input); msg != "" {
+		RespondBadRequest(w, msg)
 		return
 	}
 
-	// Validate and normalize type
-	if input.Type == "" {
-		input.Type = "github"
+	// Validate token based on source type (use request context for proper timeout)
+	ctx := r.Context()
+	if msg, err := checkSourceAccess(ctx, input); err != nil {
+		RespondError(w, http.StatusBadRequest, msg, err)
+		return
 	}
-	input.Type = strings.ToLower(input.Type)
-	if input.Type != "github" && input.Type != "gitlab" {
-		RespondBadRequest(w, "type must be 'github' or 'gitlab'")
+
+	source, err := h.repo.Create(ctx, input)
+	if err != nil {
+		RespondInternalError(w, err)
 		return
 	}
+	recordEvent(ctx, h.events, domain.EventSourceCreated, source.ID, source.Name, nil)
+	source = h.refreshTokenStatus(ctx, source)
+	h.scheduler.ReloadSourceSchedules()
 
-	// Validate organization name (prevent injection)
-	if input.Organization != "" && len(input.Organization) > 100 {
-		RespondBadRequest(w, "organization name too long")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(source)
+}
+
+// Onboarding previews a repository of the source before its first scan: detected manifests,
+// estimated dependency count and the scan settings that would apply (?repo=owner/name)
+func (h *SourceHandler) Onboarding(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
 		return
 	}
 
-	// Validate GitLab URL if provided
-	if input.Type == "gitlab" && input.URL != "" {
-		parsedURL, err := url.Parse(input.URL)
-		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-			RespondBadRequest(w, "invalid GitLab URL")
-			return
-		}
+	repoName := strings.TrimSpace(r.URL.Query().Get("repo"))
+	if repoName == "" {
+		RespondBadRequest(w, "repo is required")
+		return
+	}
+	if len(repoName) > 255 {
+		RespondBadRequest(w, "repo name too long")
+		return
 	}
 
-	// Validate token based on source type (use request context for proper timeout)
 	ctx := r.Context()
-	if input.Type == "gitlab" {
-		glClient := gitlab.New(input.Token, input.URL, input.Organization, input.InsecureSkipVerify, input.MembershipOnly)
-		if err := glClient.ValidateToken(ctx); err != nil {
-			RespondError(w, http.StatusBadRequest, "invalid token: unable to authenticate", err)
-			return
-		}
-	} else {
-		ghClient := github.New(input.Token, input.Organization, input.OwnerOnly)
-		if err := ghClient.ValidateToken(ctx); err != nil {
-			RespondError(w, http.StatusBadRequest, "invalid token: unable to authenticate", err)
-			return
-		}
+	source, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		RespondNotFound(w, "source not found")
+		return
 	}
 
-	source, err := h.repo.Create(ctx, input)
+	preview, err := h.scanner.PreviewRepository(ctx, *source, repoName)
+	if err != nil {
+		RespondError(w, http.StatusBadGateway, "failed to inspect repository", err)
+		return
+	}
+
+	tracked, err := h.repoRep.GetBySourceID(ctx, id)
 	if err != nil {
 		RespondInternalError(w, err)
 		return
 	}
+	for _, repo := range tracked {
+		if strings.EqualFold(repo.FullName, preview.Repository.FullName) {
+			preview.Tracked = true
+			preview.Repository.ID = repo.ID
+			break
+		}
+	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(source)
+	json.NewEncoder(w).Encode(preview)
 }
 
 func (h *SourceHandler) Delete(w http.ResponseWriter, r *http.Request) {
@@ -123,6 +174,11 @@ func (h *SourceHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
+	source, err := h.repo.GetByID(ctx, id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		RespondInternalError(w, err)
+		return
+	}
 
 	// Cascade delete: dependencies -> repositories -> source
 	if err := h.depRepo.DeleteBySourceID(ctx, id); err != nil {
@@ -139,6 +195,10 @@ func (h *SourceHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		RespondInternalError(w, err)
 		return
 	}
+	if source != nil {
+		recordEvent(ctx, h.events, domain.EventSourceDeleted, id, source.Name, sourceToInput(*source))
+	}
+	h.scheduler.ReloadSourceSchedules()
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -157,57 +217,241 @@ func (h *SourceHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if input.Name == "" || input.Token == "" {
-		RespondBadRequest(w, "name and token are required")
+	ctx := r.Context()
+
+	// Tokens are never returned to clients, so keep the stored token when none is provided. It is
+	// only sent to another server with the admin key, since checking access would hand it over.
+	if input.Token == "" {
+		existing, err := h.repo.GetByID(ctx, id)
+		if err != nil {
+			RespondNotFound(w, "source not found")
+			return
+		}
+		if existing.Token != "" && !sameTokenTarget(*existing, input) && !middleware.IsAdmin(ctx) {
+			RespondBadRequest(w, "token is required when changing the type, URL or repositories of a source")
+			return
+		}
+		input.Token = existing.Token
+	}
+
+	if msg := validateSourceInput(&input); msg != "" {
+		RespondBadRequest(w, msg)
+		return
+	}
+
+	// Validate token based on source type (use request context for proper timeout)
+	if msg, err := checkSourceAccess(ctx, input); err != nil {
+		RespondError(w, http.StatusBadRequest, msg, err)
 		return
 	}
 
+	source, err := h.repo.Update(ctx, id, input)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	source = h.refreshTokenStatus(ctx, source)
+	h.scheduler.ReloadSourceSchedules()
+
+	json.NewEncoder(w).Encode(source)
+}
+
+// Enable makes a source disabled after repeated scan failures active again, so scans include it
+func (h *SourceHandler) Enable(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	ctx := r.Context()
+	if _, err := h.repo.GetByID(ctx, id); err != nil {
+		RespondNotFound(w, "source not found")
+		return
+	}
+	if err := h.repo.Enable(ctx, id); err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	source, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(source)
+}
+
+// TokenWarnings lists sources whose token was found invalid or expires soon
+func (h *SourceHandler) TokenWarnings(w http.ResponseWriter, r *http.Request) {
+	sources, err := h.repo.GetAll(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	warnings := []domain.SourceTokenWarning{}
+	now := time.Now()
+	for _, source := range sources {
+		if warning := scanner.TokenWarning(source, now); warning != nil {
+			warnings = append(warnings, *warning)
+		}
+	}
+	json.NewEncoder(w).Encode(warnings)
+}
+
+// CheckToken validates the token of a source now and returns the source with its token status
+func (h *SourceHandler) CheckToken(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	ctx := r.Context()
+	source, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		RespondNotFound(w, "source not found")
+		return
+	}
+
+	checked, err := h.scanner.CheckSourceToken(ctx, *source)
+	if errors.Is(err, scanner.ErrTokenCheckFailed) {
+		RespondError(w, http.StatusBadGateway, err.Error(), err)
+		return
+	}
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(checked)
+}
+
+// refreshTokenStatus records the token status and expiration of a created or updated source
+func (h *SourceHandler) refreshTokenStatus(ctx context.Context, source *domain.Source) *domain.Source {
+	checked, err := h.scanner.CheckSourceToken(ctx, *source)
+	if err != nil {
+		log.Warn().Err(err).Int64("source_id", source.ID).Msg("failed to check source token")
+		return source
+	}
+	return checked
+}
+
+// validateSourceInput normalizes the source input and returns a message describing
+// the first invalid field, or an empty string when the input is valid
+func validateSourceInput(input *domain.SourceInput) string {
 	// Validate and normalize type
 	if input.Type == "" {
 		input.Type = "github"
 	}
 	input.Type = strings.ToLower(input.Type)
-	if input.Type != "github" && input.Type != "gitlab" {
-		RespondBadRequest(w, "type must be 'github' or 'gitlab'")
-		return
+	if input.Type != "github" && input.Type != "gitlab" && input.Type != "git" {
+		return "type must be 'github', 'gitlab' or 'git'"
+	}
+
+	// Plain git repositories may be cloned anonymously
+	if input.Name == "" || (input.Token == "" && input.Type != "git") {
+		return "name and token are required"
 	}
 
 	// Validate organization name (prevent injection)
 	if input.Organization != "" && len(input.Organization) > 100 {
-		RespondBadRequest(w, "organization name too long")
-		return
+		return "organization name too long"
+	}
+
+	// Validate additional branch list length
+	if len(input.AdditionalBranches) > 500 {
+		return "additional branches list too long"
+	}
+
+	// Archives are downloaded from the GitHub and GitLab APIs; git sources always clone
+	input.FetchMode = strings.ToLower(strings.TrimSpace(input.FetchMode))
+	switch input.FetchMode {
+	case "", domain.FetchModeAPI:
+	case domain.FetchModeArchive:
+		if input.Type == "git" {
+			return "archive fetch mode is only supported for GitHub and GitLab sources"
+		}
+	default:
+		return "fetch_mode must be 'api' or 'archive'"
 	}
 
 	// Validate GitLab URL if provided
 	if input.Type == "gitlab" && input.URL != "" {
 		parsedURL, err := url.Parse(input.URL)
 		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-			RespondBadRequest(w, "invalid GitLab URL")
-			return
+			return "invalid GitLab URL"
 		}
 	}
 
-	// Validate token based on source type (use request context for proper timeout)
-	ctx := r.Context()
-	if input.Type == "gitlab" {
+	// Plain git servers cannot list repositories, so they must be configured explicitly
+	if input.Type == "git" && strings.TrimSpace(input.Repositories) == "" {
+		return "repositories are required for git sources"
+	}
+
+	// Git sources only clone from remote servers, never paths on this server
+	if input.Type == "git" {
+		if err := gitrepo.ValidateRepositories(input.URL, input.Repositories); err != nil {
+			return "invalid git repository: " + err.Error()
+		}
+	}
+
+	if input.Priority < -100 || input.Priority > 100 {
+		return "priority must be between -100 and 100"
+	}
+
+	// Validate own scan schedule and scan window
+	input.ScheduleCron = strings.TrimSpace(input.ScheduleCron)
+	if input.ScheduleCron != "" {
+		parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+		if _, err := parser.Parse(input.ScheduleCron); err != nil {
+			return "invalid schedule cron expression"
+		}
+	}
+	input.ScheduleTimezone = strings.TrimSpace(input.ScheduleTimezone)
+	if _, err := scheduler.LoadTimezone(input.ScheduleTimezone); err != nil {
+		return err.Error()
+	}
+	input.ScanWindow = strings.TrimSpace(input.ScanWindow)
+	if input.ScanWindow != "" {
+		if _, _, err := scheduler.ParseScanWindow(input.ScanWindow); err != nil {
+			return err.Error()
+		}
+	}
+
+	return ""
+}
+
+// checkSourceAccess verifies that the source credentials are accepted by its provider.
+// On failure it returns a message for the client along with the underlying error.
+// sameTokenTarget reports whether a source input sends the token to the same server and
+// repositories as the stored source, so the stored token may be kept
+func sameTokenTarget(existing domain.Source, input domain.SourceInput) bool {
+	sourceType := strings.ToLower(input.Type)
+	if sourceType == "" {
+		sourceType = "github"
+	}
+	return sourceType == existing.Type && input.URL == existing.URL &&
+		input.Repositories == existing.Repositories && input.InsecureSkipVerify == existing.InsecureSkipVerify
+}
+
+func checkSourceAccess(ctx context.Context, input domain.SourceInput) (string, error) {
+	switch input.Type {
+	case "gitlab":
 		glClient := gitlab.New(input.Token, input.URL, input.Organization, input.InsecureSkipVerify, input.MembershipOnly)
 		if err := glClient.ValidateToken(ctx); err != nil {
-			RespondError(w, http.StatusBadRequest, "invalid token: unable to authenticate", err)
-			return
+			return "invalid token: unable to authenticate", err
+		}
+	case "git":
+		gitClient := gitrepo.New(input.Token, input.URL, input.Repositories, input.InsecureSkipVerify)
+		if err := gitClient.ValidateToken(ctx); err != nil {
+			return "unable to access repository", err
 		}
-	} else {
+	default:
 		ghClient := github.New(input.Token, input.Organization, input.OwnerOnly)
 		if err := ghClient.ValidateToken(ctx); err != nil {
-			RespondError(w, http.StatusBadRequest, "invalid token: unable to authenticate", err)
-			return
+			return "invalid token: unable to authenticate", err
 		}
 	}
-
-	source, err := h.repo.Update(ctx, id, input)
-	if err != nil {
-		RespondInternalError(w, err)
-		return
-	}
-
-	json.NewEncoder(w).Encode(source)
+	return "", nil
 }
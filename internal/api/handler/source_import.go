@@ -0,0 +1,328 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jiin/stale/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// importConcurrency limits the number of tokens validated in parallel
+const importConcurrency = 5
+
+// sourceImportRow is a source definition in an import file. Field names match the source API.
+type sourceImportRow struct {
+	Name                   string `yaml:"name"`
+	Type                   string `yaml:"type"`
+	Token                  string `yaml:"token"`
+	Organization           string `yaml:"organization"`
+	URL                    string `yaml:"url"`
+	Repositories           string `yaml:"repositories"`
+	ScanBranch             string `yaml:"scan_branch"`
+	AdditionalBranches     string `yaml:"additional_branches"`
+	FetchMode              string `yaml:"fetch_mode"`
+	InsecureSkipVerify     bool   `yaml:"insecure_skip_verify"`
+	MembershipOnly         bool   `yaml:"membership_only"`
+	OwnerOnly              bool   `yaml:"owner_only"`
+	ImportDependabotAlerts bool   `yaml:"import_dependabot_alerts"`
+	ScheduleCron           string `yaml:"schedule_cron"`
+	ScanWindow             string `yaml:"scan_window"`
+	ScheduleTimezone       string `yaml:"schedule_timezone"`
+	Priority               int    `yaml:"priority"`
+}
+
+func (r sourceImportRow) input() domain.SourceInput {
+	return domain.SourceInput{
+		Name:                   strings.TrimSpace(r.Name),
+		Type:                   strings.TrimSpace(r.Type),
+		Token:                  strings.TrimSpace(r.Token),
+		Organization:           strings.TrimSpace(r.Organization),
+		URL:                    strings.TrimSpace(r.URL),
+		Repositories:           strings.TrimSpace(r.Repositories),
+		ScanBranch:             strings.TrimSpace(r.ScanBranch),
+		AdditionalBranches:     strings.TrimSpace(r.AdditionalBranches),
+		FetchMode:              strings.TrimSpace(r.FetchMode),
+		InsecureSkipVerify:     r.InsecureSkipVerify,
+		MembershipOnly:         r.MembershipOnly,
+		OwnerOnly:              r.OwnerOnly,
+		ImportDependabotAlerts: r.ImportDependabotAlerts,
+		ScheduleCron:           strings.TrimSpace(r.ScheduleCron),
+		ScanWindow:             strings.TrimSpace(r.ScanWindow),
+		ScheduleTimezone:       strings.TrimSpace(r.ScheduleTimezone),
+		Priority:               r.Priority,
+	}
+}
+
+// Import creates many sources at once from a CSV or YAML file, uploaded either as the request
+// body (Content-Type text/csv or application/yaml) or as the "file" field of a multipart form.
+// Tokens are validated in parallel and every row gets its own result; rows whose name is already
+// used by a source are skipped. With dry_run=true nothing is created.
+func (h *SourceHandler) Import(w http.ResponseWriter, r *http.Request) {
+	LimitBody(r)
+	data, format, err := readImportFile(r)
+	if err != nil {
+		RespondBadRequest(w, err.Error())
+		return
+	}
+
+	rows, err := parseSourceImport(data, format)
+	if err != nil {
+		RespondBadRequest(w, err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		RespondBadRequest(w, "import file contains no sources")
+		return
+	}
+
+	ctx := r.Context()
+	existing, err := h.repo.GetAll(ctx)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	names := make(map[string]bool, len(existing))
+	for _, s := range existing {
+		names[s.Name] = true
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	result := domain.SourceImportResult{DryRun: dryRun, Rows: make([]domain.SourceImportRow, len(rows))}
+
+	// Validate rows up front, then check tokens of the valid ones in parallel
+	inputs := make([]domain.SourceInput, len(rows))
+	var pending []int
+	for i, row := range rows {
+		inputs[i] = row.input()
+		res := &result.Rows[i]
+		res.Row = i + 1
+		res.Name = inputs[i].Name
+
+		if msg := validateSourceInput(&inputs[i]); msg != "" {
+			res.Status, res.Error = "invalid", msg
+			continue
+		}
+		if names[inputs[i].Name] {
+			res.Status, res.Error = "skipped", "a source with this name already exists"
+			continue
+		}
+		names[inputs[i].Name] = true // duplicates within the file
+		pending = append(pending, i)
+	}
+
+	checkSourcesAccess(ctx, inputs, pending, result.Rows)
+
+	created := 0
+	for _, i := range pending {
+		res := &result.Rows[i]
+		if res.Status != "" {
+			continue
+		}
+		if dryRun {
+			res.Status = "valid"
+			continue
+		}
+		source, err := h.repo.Create(ctx, inputs[i])
+		if err != nil {
+			res.Status, res.Error = "failed", "failed to save source"
+			continue
+		}
+		recordEvent(ctx, h.events, domain.EventSourceCreated, source.ID, source.Name, nil)
+		res.Status = "created"
+		res.SourceID = source.ID
+		created++
+	}
+	if created > 0 {
+		h.scheduler.ReloadSourceSchedules()
+	}
+
+	for _, res := range result.Rows {
+		switch res.Status {
+		case "created", "valid":
+			result.Created++
+		case "skipped":
+			result.Skipped++
+		default:
+			result.Failed++
+		}
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// checkSourcesAccess validates the credentials of the given rows in parallel,
+// marking rows whose provider rejects them as failed
+func checkSourcesAccess(ctx context.Context, inputs []domain.SourceInput, rows []int, results []domain.SourceImportRow) {
+	sem := make(chan struct{}, importConcurrency)
+	var wg sync.WaitGroup
+	for _, i := range rows {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if msg, err := checkSourceAccess(ctx, inputs[i]); err != nil {
+				results[i].Status, results[i].Error = "failed", msg
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// readImportFile returns the uploaded file and its format ("csv" or "yaml")
+func readImportFile(r *http.Request) ([]byte, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if mediaType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, "", errors.New("file is required")
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, "", errors.New("failed to read file")
+		}
+		switch strings.ToLower(path.Ext(header.Filename)) {
+		case ".csv":
+			return data, "csv", nil
+		case ".yaml", ".yml":
+			return data, "yaml", nil
+		default:
+			return nil, "", errors.New("file must be a .csv, .yaml or .yml file")
+		}
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, "", errors.New("failed to read request body")
+	}
+	switch mediaType {
+	case "text/csv":
+		return data, "csv", nil
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return data, "yaml", nil
+	default:
+		return nil, "", errors.New("content type must be text/csv, application/yaml or multipart/form-data")
+	}
+}
+
+// parseSourceImport parses source definitions from a CSV file with a header row naming
+// the columns, or from a YAML list of sources (optionally under a "sources" key)
+func parseSourceImport(data []byte, format string) ([]sourceImportRow, error) {
+	if format == "yaml" {
+		var rows []sourceImportRow
+		if err := yaml.Unmarshal(data, &rows); err == nil {
+			return rows, nil
+		}
+		var doc struct {
+			Sources []sourceImportRow `yaml:"sources"`
+		}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		return doc.Sources, nil
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+
+	rows := make([]sourceImportRow, 0, len(records)-1)
+	for n, record := range records[1:] {
+		var row sourceImportRow
+		for i, value := range record {
+			if err := setImportField(&row, header[i], strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("row %d: %w", n+1, err)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func setImportField(row *sourceImportRow, column, value string) error {
+	parseBool := func(dst *bool) error {
+		if value == "" {
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("column %s: invalid boolean %q", column, value)
+		}
+		*dst = b
+		return nil
+	}
+
+	switch column {
+	case "name":
+		row.Name = value
+	case "type":
+		row.Type = value
+	case "token":
+		row.Token = value
+	case "organization":
+		row.Organization = value
+	case "url":
+		row.URL = value
+	case "repositories":
+		row.Repositories = value
+	case "scan_branch":
+		row.ScanBranch = value
+	case "additional_branches":
+		row.AdditionalBranches = value
+	case "fetch_mode":
+		row.FetchMode = value
+	case "insecure_skip_verify":
+		return parseBool(&row.InsecureSkipVerify)
+	case "membership_only":
+		return parseBool(&row.MembershipOnly)
+	case "owner_only":
+		return parseBool(&row.OwnerOnly)
+	case "import_dependabot_alerts":
+		return parseBool(&row.ImportDependabotAlerts)
+	case "schedule_cron":
+		row.ScheduleCron = value
+	case "scan_window":
+		row.ScanWindow = value
+	case "schedule_timezone":
+		row.ScheduleTimezone = value
+	case "priority":
+		if value == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("column %s: invalid number %q", column, value)
+		}
+		row.Priority = n
+	default:
+		return fmt.Errorf("unknown column %q", column)
+	}
+	return nil
+}
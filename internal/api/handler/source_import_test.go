@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseSourceImport_CSV(t *testing.T) {
+	data := "name,type,token,organization,owner_only\n" +
+		"acme,github,ghp_1,acme,true\n" +
+		"platform, gitlab ,glpat,platform,\n"
+
+	rows, err := parseSourceImport([]byte(data), "csv")
+	if err != nil {
+		t.Fatalf("parseSourceImport() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Name != "acme" || rows[0].Organization != "acme" || !rows[0].OwnerOnly {
+		t.Errorf("row 1 = %+v", rows[0])
+	}
+	if rows[1].Type != "gitlab" || rows[1].OwnerOnly {
+		t.Errorf("row 2 = %+v", rows[1])
+	}
+
+	if _, err := parseSourceImport([]byte("name,color\nacme,red\n"), "csv"); err == nil {
+		t.Error("expected error for unknown column")
+	}
+	if _, err := parseSourceImport([]byte("name,owner_only\nacme,maybe\n"), "csv"); err == nil {
+		t.Error("expected error for invalid boolean")
+	}
+}
+
+func TestParseSourceImport_YAML(t *testing.T) {
+	list := `
+- name: acme
+  type: github
+  token: ghp_1
+  organization: acme
+- name: internal
+  type: git
+  url: https://git.example.com
+  repositories: platform/api
+`
+	rows, err := parseSourceImport([]byte(list), "yaml")
+	if err != nil {
+		t.Fatalf("parseSourceImport() error = %v", err)
+	}
+	if len(rows) != 2 || rows[1].Repositories != "platform/api" {
+		t.Errorf("rows = %+v", rows)
+	}
+
+	doc := "sources:\n  - name: acme\n    token: ghp_1\n    import_dependabot_alerts: true\n"
+	rows, err = parseSourceImport([]byte(doc), "yaml")
+	if err != nil {
+		t.Fatalf("parseSourceImport() error = %v", err)
+	}
+	if len(rows) != 1 || !rows[0].ImportDependabotAlerts {
+		t.Errorf("rows = %+v", rows)
+	}
+
+	if _, err := parseSourceImport([]byte("sources: [unclosed"), "yaml"); err == nil {
+		t.Error("expected error for invalid YAML")
+	}
+}
+
+func TestReadImportFile(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("file", "orgs.yml")
+	part.Write([]byte("- name: acme\n"))
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sources/import", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	data, format, err := readImportFile(req)
+	if err != nil || format != "yaml" || !strings.Contains(string(data), "acme") {
+		t.Errorf("readImportFile(multipart) = %q, %q, %v", data, format, err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/sources/import", strings.NewReader("name\nacme\n"))
+	req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	if _, format, err := readImportFile(req); err != nil || format != "csv" {
+		t.Errorf("readImportFile(csv) = %q, %v", format, err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/sources/import", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	if _, _, err := readImportFile(req); err == nil {
+		t.Error("expected error for unsupported content type")
+	}
+}
+
+func TestSourceHandler_Import_Validation(t *testing.T) {
+	h := &SourceHandler{} // nil repos - testing validation only
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"unsupported content type", "application/json", "[]"},
+		{"empty file", "text/csv", "name,token\n"},
+		{"invalid csv", "text/csv", "name,token\n\"unterminated\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/sources/import", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			h.Import(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/testutil"
+)
+
+func TestSourceHandler_Onboarding_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		id    string
+		query string
+	}{
+		{"invalid id", "abc", "?repo=owner/repo"},
+		{"missing repo", "1", ""},
+		{"blank repo", "1", "?repo=%20"},
+		{"repo too long", "1", "?repo=" + strings.Repeat("a", 256)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &SourceHandler{} // nil repos - testing validation only
+
+			req := httptest.NewRequest("GET", "/sources/"+tt.id+"/onboarding"+tt.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+
+			h.Onboarding(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestSourceHandler_RevealToken_RequiresAdmin(t *testing.T) {
+	h := &SourceHandler{} // nil repos - testing authorization only
+
+	req := httptest.NewRequest("GET", "/sources/1/token", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	h.RevealToken(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestSourceHandler_Update_RequiresTokenForNewServer(t *testing.T) {
+	harness := testutil.NewHarness(t)
+	source := harness.AddSource(domain.SourceInput{Name: "gitlab", Type: "gitlab", Token: "glpat-secret", URL: "https://gitlab.example.com"})
+	h := &SourceHandler{repo: harness.Sources}
+
+	body := `{"name": "gitlab", "type": "gitlab", "url": "https://attacker.example.com"}`
+	req := httptest.NewRequest("PUT", "/sources/1", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", strconv.FormatInt(source.ID, 10))
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	h.Update(w, req)
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "token is required") {
+		t.Errorf("status = %d, body = %s, want 400 asking for a token", w.Code, w.Body.String())
+	}
+}
+
+func TestSameTokenTarget(t *testing.T) {
+	existing := domain.Source{Type: "git", URL: "https://git.example.com", Repositories: "app"}
+
+	tests := []struct {
+		name  string
+		input domain.SourceInput
+		want  bool
+	}{
+		{"unchanged", domain.SourceInput{Type: "Git", URL: "https://git.example.com", Repositories: "app", ScanBranch: "main"}, true},
+		{"other type", domain.SourceInput{Type: "gitlab", URL: "https://git.example.com", Repositories: "app"}, false},
+		{"other URL", domain.SourceInput{Type: "git", URL: "https://evil.example.com", Repositories: "app"}, false},
+		{"other repositories", domain.SourceInput{Type: "git", URL: "https://git.example.com", Repositories: "https://evil.example.com/app"}, false},
+		{"TLS verification skipped", domain.SourceInput{Type: "git", URL: "https://git.example.com", Repositories: "app", InsecureSkipVerify: true}, false},
+	}
+	for _, tt := range tests {
+		if got := sameTokenTarget(existing, tt.input); got != tt.want {
+			t.Errorf("%s: sameTokenTarget() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSourceHandler_Enable_InvalidID(t *testing.T) {
+	h := &SourceHandler{} // nil repos - testing validation only
+
+	req := httptest.NewRequest("POST", "/sources/abc/enable", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "abc")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+
+	h.Enable(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestValidateSourceInput_Schedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   domain.SourceInput
+		wantErr bool
+	}{
+		{"no schedule", domain.SourceInput{Name: "s", Token: "t"}, false},
+		{"valid schedule and window", domain.SourceInput{Name: "s", Token: "t", ScheduleCron: "0 2 * * *", ScanWindow: "22:00-06:00"}, false},
+		{"invalid cron", domain.SourceInput{Name: "s", Token: "t", ScheduleCron: "every night"}, true},
+		{"invalid window", domain.SourceInput{Name: "s", Token: "t", ScanWindow: "night"}, true},
+		{"valid schedule timezone", domain.SourceInput{Name: "s", Token: "t", ScheduleCron: "0 2 * * *", ScheduleTimezone: "Asia/Seoul"}, false},
+		{"unknown schedule timezone", domain.SourceInput{Name: "s", Token: "t", ScheduleTimezone: "Mars/Olympus"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := validateSourceInput(&tt.input)
+			if (msg != "") != tt.wantErr {
+				t.Errorf("validateSourceInput() = %q, wantErr %v", msg, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSourceInput_FetchMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   domain.SourceInput
+		wantErr bool
+	}{
+		{"default", domain.SourceInput{Name: "s", Token: "t"}, false},
+		{"archive", domain.SourceInput{Name: "s", Token: "t", FetchMode: "Archive"}, false},
+		{"unknown mode", domain.SourceInput{Name: "s", Token: "t", FetchMode: "clone"}, true},
+		{"archive for git source", domain.SourceInput{Name: "s", Type: "git", URL: "https://git.example.com", Repositories: "app", FetchMode: "archive"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := validateSourceInput(&tt.input)
+			if (msg != "") != tt.wantErr {
+				t.Errorf("validateSourceInput() = %q, wantErr %v", msg, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSourceInput_GitRemotes(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   domain.SourceInput
+		wantErr bool
+	}{
+		{"https base", domain.SourceInput{Name: "s", Type: "git", URL: "https://gerrit.example.com", Repositories: "platform/api"}, false},
+		{"scp-like base", domain.SourceInput{Name: "s", Type: "git", URL: "git@git.example.com:", Repositories: "team/repo.git"}, false},
+		{"full URLs", domain.SourceInput{Name: "s", Type: "git", Repositories: "ssh://git@git.example.com/a.git, git@git.example.com:b.git"}, false},
+		{"local path", domain.SourceInput{Name: "s", Type: "git", Repositories: "/etc"}, true},
+		{"relative path without base", domain.SourceInput{Name: "s", Type: "git", Repositories: "app"}, true},
+		{"file URL", domain.SourceInput{Name: "s", Type: "git", Repositories: "file:///var/lib/stale"}, true},
+		{"file base", domain.SourceInput{Name: "s", Type: "git", URL: "file:///srv/git", Repositories: "app"}, true},
+		{"plain http base", domain.SourceInput{Name: "s", Type: "git", URL: "http://git.example.com", Repositories: "app"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := validateSourceInput(&tt.input)
+			if (msg != "") != tt.wantErr {
+				t.Errorf("validateSourceInput() = %q, wantErr %v", msg, tt.wantErr)
+			}
+		})
+	}
+}
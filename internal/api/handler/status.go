@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jiin/stale/internal/service/httputil"
+)
+
+// StatusHandler reports the availability of the registries and providers the instance talks to,
+// to tell registry outages apart from problems of the instance
+type StatusHandler struct{}
+
+func NewStatusHandler() *StatusHandler {
+	return &StatusHandler{}
+}
+
+// IntegrationsResponse is the availability of each host requested within the window
+type IntegrationsResponse struct {
+	WindowSeconds int                          `json:"window_seconds"`
+	Integrations  []httputil.IntegrationStatus `json:"integrations"`
+}
+
+// Integrations returns the error rate and latency of the requests made to each registry and
+// provider host within the rolling availability window
+func (h *StatusHandler) Integrations(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(IntegrationsResponse{
+		WindowSeconds: int(httputil.AvailabilityWindow.Seconds()),
+		Integrations:  httputil.IntegrationStatuses(time.Now()),
+	})
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusHandler_Integrations(t *testing.T) {
+	h := NewStatusHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/status/integrations", nil)
+	w := httptest.NewRecorder()
+	h.Integrations(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp IntegrationsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.WindowSeconds != 900 {
+		t.Errorf("window_seconds = %d, want 900", resp.WindowSeconds)
+	}
+	if resp.Integrations == nil {
+		t.Error("integrations should be an empty list, not null")
+	}
+}
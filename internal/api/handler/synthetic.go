@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/jiin/stale/internal/service/scanner"
+	"github.com/jiin/stale/internal/service/synthetic"
+	"github.com/rs/zerolog/log"
+)
+
+// Bounds of generated data: 5000 repositories of up to maxIngestDependencies dependencies, but
+// at most a million dependencies in total
+const (
+	maxSyntheticRepositories = 5000
+	maxSyntheticDependencies = 1_000_000
+)
+
+// defaultSyntheticSource is the external source generated repositories are attributed to
+const defaultSyntheticSource = "synthetic"
+
+// SyntheticHandler generates fake data to load test the UI, the database and exports before
+// real organizations are scanned. It is only routed in dev mode (STALE_DEV_MODE).
+type SyntheticHandler struct {
+	sourceRepo *repository.SourceRepository
+	scanRepo   *repository.ScanRepository
+	scanner    *scanner.Scanner
+	onPersist  func() // called after data was generated (e.g. to clear caches)
+}
+
+func NewSyntheticHandler(sourceRepo *repository.SourceRepository, scanRepo *repository.ScanRepository, scanner *scanner.Scanner, onPersist func()) *SyntheticHandler {
+	return &SyntheticHandler{sourceRepo: sourceRepo, scanRepo: scanRepo, scanner: scanner, onPersist: onPersist}
+}
+
+// SyntheticRequest sizes the generated data and names the source it is attributed to
+type SyntheticRequest struct {
+	synthetic.Spec
+	Source string `json:"source"`
+}
+
+// Generate starts a scan recording synthetic repositories and dependencies under an external
+// source and returns the scan, which completes in the background. Deleting the source removes
+// the data again.
+func (h *SyntheticHandler) Generate(w http.ResponseWriter, r *http.Request) {
+	LimitBody(r)
+	req := SyntheticRequest{Spec: synthetic.Spec{Repositories: 100, DependenciesPerRepo: 50, OutdatedPercent: 30, Seed: 1}}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+	if msg := validateSyntheticRequest(&req); msg != "" {
+		RespondBadRequest(w, msg)
+		return
+	}
+
+	ctx := r.Context()
+	source, err := findOrCreateExternalSource(ctx, h.sourceRepo, req.Source)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if !source.External() {
+		RespondError(w, http.StatusConflict, fmt.Sprintf("source %q is not an external source", source.Name), nil)
+		return
+	}
+
+	scan, err := h.scanRepo.Create(ctx, &source.ID)
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+
+	go h.run(context.Background(), *source, scan.ID, req.Spec)
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(scan)
+}
+
+// run records the generated data as the given scan
+func (h *SyntheticHandler) run(ctx context.Context, source domain.Source, scanID int64, spec synthetic.Spec) {
+	_ = h.scanRepo.UpdateStatus(ctx, scanID, domain.ScanStatusRunning, nil)
+	_ = h.scanRepo.AddReposTotal(ctx, scanID, spec.Repositories)
+
+	var repos, deps int
+	var err error
+	for _, repo := range synthetic.Generate(spec) {
+		if _, err = h.scanner.Ingest(ctx, source, repo.Repository, repo.Dependencies); err != nil {
+			break
+		}
+		repos++
+		deps += len(repo.Dependencies)
+		_ = h.scanRepo.UpdateStats(ctx, scanID, repos, deps)
+	}
+
+	status := domain.ScanStatusCompleted
+	if err != nil {
+		status = domain.ScanStatusFailed
+		log.Error().Err(err).Int64("scan_id", scanID).Msg("synthetic scan failed")
+	} else {
+		log.Info().Int64("scan_id", scanID).Int("repos", repos).Int("dependencies", deps).Msg("synthetic scan completed")
+	}
+	_ = h.scanRepo.UpdateStatus(ctx, scanID, status, err)
+
+	if h.onPersist != nil {
+		h.onPersist()
+	}
+}
+
+// validateSyntheticRequest normalizes the request and returns a message describing the first
+// invalid field, or an empty string when the request is valid
+func validateSyntheticRequest(req *SyntheticRequest) string {
+	req.Source = strings.TrimSpace(req.Source)
+	if req.Source == "" {
+		req.Source = defaultSyntheticSource
+	}
+	if len(req.Source) > 100 {
+		return "source name too long"
+	}
+	if req.Repositories < 1 || req.Repositories > maxSyntheticRepositories {
+		return fmt.Sprintf("repositories must be between 1 and %d", maxSyntheticRepositories)
+	}
+	if req.DependenciesPerRepo < 1 || req.DependenciesPerRepo > maxIngestDependencies {
+		return fmt.Sprintf("dependencies_per_repo must be between 1 and %d", maxIngestDependencies)
+	}
+	if req.Repositories*req.DependenciesPerRepo > maxSyntheticDependencies {
+		return fmt.Sprintf("at most %d dependencies can be generated at once", maxSyntheticDependencies)
+	}
+	if req.OutdatedPercent < 0 || req.OutdatedPercent > 100 {
+		return "outdated_percent must be between 0 and 100"
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSyntheticHandler_Generate_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", "not json"},
+		{"no repositories", `{"repositories": 0}`},
+		{"too many repositories", `{"repositories": 5001}`},
+		{"no dependencies", `{"dependencies_per_repo": 0}`},
+		{"too many dependencies in total", `{"repositories": 5000, "dependencies_per_repo": 500}`},
+		{"outdated share out of range", `{"outdated_percent": 101}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &SyntheticHandler{} // nil dependencies - testing validation only
+
+			req := httptest.NewRequest("POST", "/dev/synthetic", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			h.Generate(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestValidateSyntheticRequest_Defaults(t *testing.T) {
+	req := SyntheticRequest{Source: "  "}
+	req.Repositories, req.DependenciesPerRepo = 500, 200
+
+	if msg := validateSyntheticRequest(&req); msg != "" {
+		t.Fatalf("validateSyntheticRequest() = %q, want 500 x 200 to be valid", msg)
+	}
+	if req.Source != defaultSyntheticSource {
+		t.Errorf("Source = %q, want %q", req.Source, defaultSyntheticSource)
+	}
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jiin/stale/internal/buildinfo"
+)
+
+// VersionHandler reports the build of the running instance, for support and debugging
+type VersionHandler struct {
+	features Features
+}
+
+// Features are the optional capabilities enabled on the running instance
+type Features struct {
+	Auth       bool     `json:"auth"`
+	ReadOnly   bool     `json:"read_only"` // mutating endpoints are rejected, see STALE_READ_ONLY
+	Ecosystems []string `json:"ecosystems"`
+}
+
+func NewVersionHandler(features Features) *VersionHandler {
+	if features.Ecosystems == nil {
+		features.Ecosystems = []string{}
+	}
+	return &VersionHandler{features: features}
+}
+
+// VersionResponse is the build information with the enabled features
+type VersionResponse struct {
+	buildinfo.Info
+	Features Features `json:"features"`
+}
+
+// Get returns the version, commit, build date and Go version of the build
+func (h *VersionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(VersionResponse{
+		Info:     buildinfo.Get(),
+		Features: h.features,
+	})
+}
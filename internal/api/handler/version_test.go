@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jiin/stale/internal/buildinfo"
+)
+
+func TestVersionHandler_Get(t *testing.T) {
+	h := NewVersionHandler(Features{Auth: true, Ecosystems: []string{"go", "npm"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/version", nil)
+	w := httptest.NewRecorder()
+
+	h.Get(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response["version"] != buildinfo.Version {
+		t.Errorf("version = %v, want %q", response["version"], buildinfo.Version)
+	}
+	if response["go_version"] == "" {
+		t.Error("go_version should not be empty")
+	}
+	features, ok := response["features"].(map[string]interface{})
+	if !ok || features["auth"] != true || len(features["ecosystems"].([]interface{})) != 2 {
+		t.Errorf("features = %v, want auth enabled with 2 ecosystems", response["features"])
+	}
+}
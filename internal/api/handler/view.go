@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+)
+
+// ViewHandler manages saved views of the dependency list. The list, its exports and the new
+// outdated notification refer to a view by id.
+type ViewHandler struct {
+	repo         *repository.ViewRepository
+	settingsRepo *repository.SettingsRepository
+}
+
+func NewViewHandler(repo *repository.ViewRepository, settingsRepo *repository.SettingsRepository) *ViewHandler {
+	return &ViewHandler{repo: repo, settingsRepo: settingsRepo}
+}
+
+func (h *ViewHandler) List(w http.ResponseWriter, r *http.Request) {
+	views, err := h.repo.GetAll(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if views == nil {
+		views = []domain.SavedView{}
+	}
+	json.NewEncoder(w).Encode(views)
+}
+
+func (h *ViewHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	view, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		RespondNotFound(w, "view not found")
+		return
+	}
+	json.NewEncoder(w).Encode(view)
+}
+
+func (h *ViewHandler) Create(w http.ResponseWriter, r *http.Request) {
+	LimitBody(r)
+	var input domain.SavedViewInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	if msg := validateViewInput(&input); msg != "" {
+		RespondBadRequest(w, msg)
+		return
+	}
+
+	view, err := h.repo.Create(r.Context(), input)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			RespondError(w, http.StatusConflict, "a view with this name already exists", nil)
+			return
+		}
+		RespondInternalError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(view)
+}
+
+func (h *ViewHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	LimitBody(r)
+	var input domain.SavedViewInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	if msg := validateViewInput(&input); msg != "" {
+		RespondBadRequest(w, msg)
+		return
+	}
+
+	view, err := h.repo.Update(r.Context(), id, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			RespondNotFound(w, "view not found")
+		case strings.Contains(err.Error(), "UNIQUE"):
+			RespondError(w, http.StatusConflict, "a view with this name already exists", nil)
+		default:
+			RespondInternalError(w, err)
+		}
+		return
+	}
+	json.NewEncoder(w).Encode(view)
+}
+
+// Delete removes a view unless the new outdated notification is limited to it
+func (h *ViewHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondBadRequest(w, "invalid id")
+		return
+	}
+
+	settings, err := h.settingsRepo.Get(r.Context())
+	if err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	if settings.EmailNotifyViewID == id {
+		RespondError(w, http.StatusConflict, "the view is used by the new outdated notification", nil)
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		RespondInternalError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateViewInput trims the input and returns an error message if it is invalid
+func validateViewInput(input *domain.SavedViewInput) string {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Status = strings.TrimSpace(input.Status)
+	input.Repo = strings.TrimSpace(input.Repo)
+	input.Ecosystem = strings.TrimSpace(input.Ecosystem)
+	input.Search = strings.TrimSpace(input.Search)
+	input.Sort = strings.TrimSpace(input.Sort)
+	input.Columns = strings.TrimSpace(input.Columns)
+
+	if input.Name == "" {
+		return "name is required"
+	}
+	if !repository.IsStatusFilter(input.Status) {
+		return "unknown status " + input.Status
+	}
+	if input.Sort != "" && !slices.Contains(domain.DependencySorts, input.Sort) {
+		return "sort must be one of " + strings.Join(domain.DependencySorts, ", ")
+	}
+	if _, err := parseExportColumns(input.Columns); err != nil {
+		return err.Error()
+	}
+	return ""
+}
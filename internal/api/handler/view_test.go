@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestValidateViewInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input domain.SavedViewInput
+		valid bool
+	}{
+		{"valid", domain.SavedViewInput{Name: "Outdated npm", Status: "upgradable", Ecosystem: "npm", Sort: "outdated_since"}, true},
+		{"name only", domain.SavedViewInput{Name: " Everything "}, true},
+		{"all statuses", domain.SavedViewInput{Name: "All", Status: "all"}, true},
+		{"missing name", domain.SavedViewInput{Status: "upgradable"}, false},
+		{"unknown status", domain.SavedViewInput{Name: "Stale", Status: "stale"}, false},
+		{"unknown sort", domain.SavedViewInput{Name: "By size", Sort: "size"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			msg := validateViewInput(&input)
+			if (msg == "") != tt.valid {
+				t.Errorf("validateViewInput() = %q, want valid=%v", msg, tt.valid)
+			}
+		})
+	}
+}
+
+func TestViewHandler_Create_Validation(t *testing.T) {
+	h := &ViewHandler{} // nil repos - testing validation only
+
+	for _, body := range []string{"{", `{"status":"upgradable"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/views", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		h.Create(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestDependencyHandler_InvalidView(t *testing.T) {
+	h := &DependencyHandler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/dependencies/export?view=abc", nil)
+	w := httptest.NewRecorder()
+	h.ExportCSV(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
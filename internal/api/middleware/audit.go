@@ -70,11 +70,71 @@ func shouldAudit(r *http.Request) bool {
 		return true
 	}
 
+	// Audit token reveals
+	if strings.HasPrefix(path, "/api/v1/sources/") && strings.HasSuffix(path, "/token") {
+		return true
+	}
+
+	// Audit repository setting changes
+	if strings.HasPrefix(path, "/api/v1/repositories") && r.Method == http.MethodPatch {
+		return true
+	}
+
 	// Audit scan triggers
 	if strings.HasPrefix(path, "/api/v1/scans") && r.Method == http.MethodPost {
 		return true
 	}
 
+	// Audit scheduler pauses and maintenance mode
+	if strings.HasPrefix(path, "/api/v1/scheduler") && r.Method == http.MethodPost {
+		return true
+	}
+
+	// Audit admin maintenance
+	if strings.HasPrefix(path, "/api/v1/admin") {
+		return true
+	}
+
+	// Audit feature flag changes
+	if strings.HasPrefix(path, "/api/v1/features") && r.Method != http.MethodGet {
+		return true
+	}
+
+	// Audit ad-hoc scans (tokens involved)
+	if path == "/api/v1/adhoc-scan" {
+		return true
+	}
+
+	// Audit dependency lists and SBOMs pushed by external scanners
+	if strings.HasPrefix(path, "/api/v1/ingest") {
+		return true
+	}
+
+	// Audit declarative configuration changes (tokens involved)
+	if path == "/api/v1/config" && r.Method == http.MethodPut {
+		return true
+	}
+
+	// Audit campaign changes
+	if strings.HasPrefix(path, "/api/v1/campaigns") && r.Method != http.MethodGet {
+		return true
+	}
+
+	// Audit package policy changes
+	if strings.HasPrefix(path, "/api/v1/policies") && r.Method != http.MethodGet {
+		return true
+	}
+
+	// Audit recommended version changes
+	if strings.HasPrefix(path, "/api/v1/recommended-versions") && r.Method != http.MethodGet {
+		return true
+	}
+
+	// Audit undos of deletions
+	if strings.HasPrefix(path, "/api/v1/events") && r.Method == http.MethodPost {
+		return true
+	}
+
 	// Audit delete operations
 	if r.Method == http.MethodDelete {
 		return true
@@ -101,19 +161,34 @@ func getAuditAction(r *http.Request) string {
 		if strings.HasSuffix(path, "/test-email") {
 			return "test_email_sent"
 		}
+		if strings.HasSuffix(path, "/preview-notification") {
+			return "notification_previewed"
+		}
+		if strings.HasSuffix(path, "/recompute-outdated") {
+			return "outdated_recomputed"
+		}
 	case strings.HasPrefix(path, "/api/v1/sources"):
-		switch method {
-		case http.MethodPost:
+		switch {
+		case strings.HasSuffix(path, "/import"):
+			return "sources_imported"
+		case strings.HasSuffix(path, "/token"):
+			return "source_token_revealed"
+		case strings.HasSuffix(path, "/check-token"):
+			return "source_token_checked"
+		case method == http.MethodPost:
 			return "source_created"
-		case http.MethodPut:
+		case method == http.MethodPut:
 			return "source_updated"
-		case http.MethodDelete:
+		case method == http.MethodDelete:
 			return "source_deleted"
 		}
 	case strings.HasPrefix(path, "/api/v1/repositories"):
 		if method == http.MethodDelete {
 			return "repository_deleted"
 		}
+		if method == http.MethodPatch {
+			return "repository_updated"
+		}
 		if strings.Contains(path, "bulk-delete") {
 			return "repositories_bulk_deleted"
 		}
@@ -124,6 +199,58 @@ func getAuditAction(r *http.Request) string {
 			}
 			return "scan_triggered"
 		}
+	case strings.HasPrefix(path, "/api/v1/scheduler"):
+		switch {
+		case strings.HasSuffix(path, "/pause"):
+			return "scheduler_paused"
+		case strings.HasSuffix(path, "/resume"):
+			return "scheduler_resumed"
+		}
+	case path == "/api/v1/admin/maintenance":
+		return "maintenance_run"
+	case strings.HasPrefix(path, "/api/v1/features"):
+		switch method {
+		case http.MethodPut:
+			return "feature_flag_updated"
+		case http.MethodDelete:
+			return "feature_flag_reset"
+		}
+	case path == "/api/v1/adhoc-scan":
+		return "adhoc_scan"
+	case strings.HasPrefix(path, "/api/v1/ingest"):
+		if strings.HasSuffix(path, "/sbom") {
+			return "sbom_ingested"
+		}
+		return "dependencies_ingested"
+	case path == "/api/v1/config":
+		return "config_applied"
+	case strings.HasPrefix(path, "/api/v1/campaigns"):
+		switch method {
+		case http.MethodPost:
+			return "campaign_created"
+		case http.MethodPut:
+			return "campaign_updated"
+		case http.MethodDelete:
+			return "campaign_deleted"
+		}
+	case strings.HasPrefix(path, "/api/v1/policies"):
+		switch method {
+		case http.MethodPost:
+			return "policy_created"
+		case http.MethodDelete:
+			return "policy_deleted"
+		}
+	case strings.HasPrefix(path, "/api/v1/recommended-versions"):
+		switch method {
+		case http.MethodPut:
+			return "recommended_version_set"
+		case http.MethodDelete:
+			return "recommended_version_removed"
+		}
+	case strings.HasPrefix(path, "/api/v1/events"):
+		if strings.HasSuffix(path, "/undo") {
+			return "event_undone"
+		}
 	case strings.HasPrefix(path, "/api/v1/ignored"):
 		switch {
 		case strings.Contains(path, "bulk-delete"):
@@ -150,3 +277,8 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap returns the wrapped writer, so http.ResponseController reaches the connection
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
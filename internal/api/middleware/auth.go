@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/hex"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/jiin/stale/internal/api/problem"
 	"github.com/rs/zerolog/log"
 )
 
@@ -15,29 +17,48 @@ import (
 type AuthConfig struct {
 	APIKey     string // Plain API key (for backward compatibility)
 	APIKeyHash string // SHA-256 hash of API key (recommended for production)
-	Enabled    bool
+	// Admin key grants access to sensitive endpoints (e.g. revealing source tokens) in addition to the regular API
+	AdminAPIKey     string
+	AdminAPIKeyHash string
+	Enabled         bool
+}
+
+type adminContextKey struct{}
+
+// IsAdmin reports whether the request was authenticated with the admin API key
+func IsAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminContextKey{}).(bool)
+	return admin
 }
 
 // DefaultAuthConfig returns authentication configuration from environment
 // Supports both plain API key (STALE_API_KEY) and hashed API key (STALE_API_KEY_HASH)
 // If STALE_API_KEY_HASH is set, it takes precedence over STALE_API_KEY
+// The admin key is configured the same way with STALE_ADMIN_API_KEY and STALE_ADMIN_API_KEY_HASH
 func DefaultAuthConfig() AuthConfig {
 	apiKey := os.Getenv("STALE_API_KEY")
 	apiKeyHash := os.Getenv("STALE_API_KEY_HASH")
-	enabled := apiKey != "" || apiKeyHash != ""
+	adminAPIKey := os.Getenv("STALE_ADMIN_API_KEY")
+	adminAPIKeyHash := os.Getenv("STALE_ADMIN_API_KEY_HASH")
+	enabled := apiKey != "" || apiKeyHash != "" || adminAPIKey != "" || adminAPIKeyHash != ""
 
 	if apiKeyHash != "" {
 		log.Info().Msg("API authentication enabled (using hashed key)")
 	} else if apiKey != "" {
 		log.Info().Msg("API authentication enabled (using plain key - consider using STALE_API_KEY_HASH for better security)")
-	} else {
+	} else if !enabled {
 		log.Warn().Msg("API authentication disabled - set STALE_API_KEY or STALE_API_KEY_HASH to enable")
 	}
+	if adminAPIKey != "" || adminAPIKeyHash != "" {
+		log.Info().Msg("Admin API key configured")
+	}
 
 	return AuthConfig{
-		APIKey:     apiKey,
-		APIKeyHash: apiKeyHash,
-		Enabled:    enabled,
+		APIKey:          apiKey,
+		APIKeyHash:      apiKeyHash,
+		AdminAPIKey:     adminAPIKey,
+		AdminAPIKeyHash: adminAPIKeyHash,
+		Enabled:         enabled,
 	}
 }
 
@@ -64,6 +85,12 @@ func Auth(config AuthConfig) func(http.Handler) http.Handler {
 				return
 			}
 
+			// Slack slash commands are authenticated with the Slack signing secret
+			if r.URL.Path == "/api/v1/slack/command" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Skip auth for frontend routes (non-API)
 			if !strings.HasPrefix(r.URL.Path, "/api/") {
 				next.ServeHTTP(w, r)
@@ -85,10 +112,15 @@ func Auth(config AuthConfig) func(http.Handler) http.Handler {
 				providedKey = authHeader
 			}
 
+			// The admin key also grants regular access
+			if matchAPIKey(providedKey, config.AdminAPIKey, config.AdminAPIKeyHash) {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminContextKey{}, true)))
+				return
+			}
+
 			// Validate API key
 			if !validateAPIKey(providedKey, config) {
-				w.Header().Set("Content-Type", "application/json")
-				http.Error(w, `{"error": "Unauthorized", "message": "Invalid or missing API key", "code": 401}`, http.StatusUnauthorized)
+				problem.Write(w, http.StatusUnauthorized, problem.CodeInvalidAPIKey, "Invalid or missing API key")
 				return
 			}
 
@@ -100,19 +132,24 @@ func Auth(config AuthConfig) func(http.Handler) http.Handler {
 // validateAPIKey checks if the provided API key is valid
 // Uses constant-time comparison to prevent timing attacks
 func validateAPIKey(providedKey string, config AuthConfig) bool {
+	return matchAPIKey(providedKey, config.APIKey, config.APIKeyHash)
+}
+
+// matchAPIKey compares the provided key against a configured hash, or the plain key if no hash is set
+func matchAPIKey(providedKey, apiKey, apiKeyHash string) bool {
 	if providedKey == "" {
 		return false
 	}
 
 	// If hash is configured, compare using hash
-	if config.APIKeyHash != "" {
+	if apiKeyHash != "" {
 		providedHash := HashAPIKey(providedKey)
-		return subtle.ConstantTimeCompare([]byte(providedHash), []byte(config.APIKeyHash)) == 1
+		return subtle.ConstantTimeCompare([]byte(providedHash), []byte(apiKeyHash)) == 1
 	}
 
 	// Fall back to direct comparison (constant-time)
-	if config.APIKey != "" {
-		return subtle.ConstantTimeCompare([]byte(providedKey), []byte(config.APIKey)) == 1
+	if apiKey != "" {
+		return subtle.ConstantTimeCompare([]byte(providedKey), []byte(apiKey)) == 1
 	}
 
 	return false
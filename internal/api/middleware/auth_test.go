@@ -280,3 +280,68 @@ func TestValidateAPIKey(t *testing.T) {
 		})
 	}
 }
+
+func TestAuth_SlackCommandSkipped(t *testing.T) {
+	config := AuthConfig{
+		APIKey:  "secret",
+		Enabled: true,
+	}
+
+	called := false
+	handler := Auth(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Slack commands carry a Slack signature instead of the API key
+	req := httptest.NewRequest("POST", "/api/v1/slack/command", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if !called {
+		t.Error("slack command endpoint should skip API key authentication")
+	}
+}
+
+func TestAuth_AdminAPIKey(t *testing.T) {
+	config := AuthConfig{
+		APIKey:          "my-secret-key",
+		AdminAPIKeyHash: HashAPIKey("admin-key"),
+		Enabled:         true,
+	}
+
+	var admin bool
+	handler := Auth(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admin = IsAdmin(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		key       string
+		wantCode  int
+		wantAdmin bool
+	}{
+		{"admin-key", http.StatusOK, true},
+		{"my-secret-key", http.StatusOK, false},
+		{"wrong-key", http.StatusUnauthorized, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			admin = false
+			req := httptest.NewRequest("GET", "/api/v1/sources/1/token", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.key)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if admin != tt.wantAdmin {
+				t.Errorf("IsAdmin() = %v, want %v", admin, tt.wantAdmin)
+			}
+		})
+	}
+}
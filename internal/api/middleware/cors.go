@@ -36,7 +36,7 @@ func DefaultCORSConfig() CORSConfig {
 
 	return CORSConfig{
 		AllowedOrigins: allowedOrigins,
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-Requested-With", "X-API-Key"},
 		MaxAge:         86400, // 24 hours
 	}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// deadlineMargin is the time left to write a response once the work of a request is canceled
+const deadlineMargin = 10 * time.Second
+
+// Deadline gives requests of slow routes, such as scans run while the client waits, the given time
+// instead of the server's read and write timeouts. Their context is canceled once it has passed.
+func Deadline(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rc := http.NewResponseController(w)
+			deadline := time.Now().Add(timeout)
+			if err := rc.SetReadDeadline(deadline); err != nil {
+				log.Warn().Err(err).Str("path", r.URL.Path).Msg("failed to extend the read deadline")
+			}
+			if err := rc.SetWriteDeadline(deadline.Add(deadlineMargin)); err != nil {
+				log.Warn().Err(err).Str("path", r.URL.Path).Msg("failed to extend the write deadline")
+			}
+
+			ctx, cancel := context.WithDeadline(r.Context(), deadline)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/jiin/stale/internal/api/problem"
+	"github.com/jiin/stale/internal/service/feature"
+)
+
+// RequireFeature responds 404 Not Found to requests for routes of a disabled feature flag
+func RequireFeature(flags *feature.Flags, name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !flags.Enabled(r.Context(), name) {
+				problem.Write(w, http.StatusNotFound, problem.CodeFeatureDisabled, "feature "+name+" is disabled")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
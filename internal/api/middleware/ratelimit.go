@@ -5,6 +5,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/jiin/stale/internal/api/problem"
 )
 
 // RateLimiter implements a simple token bucket rate limiter
@@ -105,7 +107,7 @@ func (rl *RateLimiter) Handler(next http.Handler) http.Handler {
 
 		if !rl.Allow(clientIP) {
 			w.Header().Set("Retry-After", "1")
-			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			problem.Write(w, http.StatusTooManyRequests, problem.CodeRateLimited, "Rate limit exceeded")
 			return
 		}
 
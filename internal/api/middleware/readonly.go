@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/jiin/stale/internal/api/problem"
+)
+
+// readOnlySafePaths are POST endpoints that change nothing and stay available in read-only mode
+var readOnlySafePaths = []string{
+	"/api/v1/parse",
+	"/api/v1/settings/validate",
+	"/api/v1/slack/command",
+}
+
+// ReadOnly responds 403 Forbidden to requests that would change data when enabled, so replicas
+// can serve dashboards from a database another instance writes to. Reads, and POST endpoints
+// that change nothing, pass through.
+func ReadOnly(enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				if r.Method != http.MethodPost || !slices.Contains(readOnlySafePaths, r.URL.Path) {
+					problem.Write(w, http.StatusForbidden, problem.CodeReadOnly, "this instance is read-only (STALE_READ_ONLY)")
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
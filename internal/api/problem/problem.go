@@ -0,0 +1,122 @@
+// Package problem writes the error responses of the API as RFC 7807 problem details
+// (application/problem+json) with a machine-readable error code. Titles are translated to the
+// language of the response, see Language.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jiin/stale/internal/i18n"
+)
+
+// ContentType is the media type of problem details
+const ContentType = "application/problem+json"
+
+// typePrefix prefixes the error code in the type URI of a problem, e.g. urn:stale:error:not_found
+const typePrefix = "urn:stale:error:"
+
+// Error codes. Clients should branch on these rather than on titles or details, which are meant
+// for people.
+const (
+	CodeBadRequest      = "bad_request"
+	CodeUnauthorized    = "unauthorized"
+	CodeForbidden       = "forbidden"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodeTooLarge        = "request_too_large"
+	CodeRateLimited     = "rate_limited"
+	CodeInternal        = "internal_error"
+	CodeUpstream        = "upstream_error" // a provider or registry failed
+	CodeUnavailable     = "unavailable"
+	CodeInvalidAPIKey   = "invalid_api_key"
+	CodeFeatureDisabled = "feature_disabled"
+	CodeScanRunning     = "scan_running"
+	CodeReadOnly        = "read_only" // the instance is a read-only replica
+)
+
+// Codes are every error code, in declaration order
+var Codes = []string{
+	CodeBadRequest, CodeUnauthorized, CodeForbidden, CodeNotFound, CodeConflict, CodeTooLarge,
+	CodeRateLimited, CodeInternal, CodeUpstream, CodeUnavailable, CodeInvalidAPIKey,
+	CodeFeatureDisabled, CodeScanRunning, CodeReadOnly,
+}
+
+// Details is a problem details response. Error, Message and Code repeat the status text, detail
+// and status for clients of the earlier error format.
+type Details struct {
+	Type      string `json:"type"`  // urn:stale:error: followed by the error code
+	Title     string `json:"title"` // short summary of the error code, in the language of the response
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	ErrorCode string `json:"error_code"`
+
+	Error   string `json:"error"`
+	Message string `json:"message,omitempty"`
+	Code    int    `json:"code"`
+}
+
+// CodeFor returns the error code of an HTTP status
+func CodeFor(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodeTooLarge
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return CodeUpstream
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// New returns the problem details of an error code, titled in locale
+func New(locale string, status int, code, detail string) Details {
+	return Details{
+		Type:      typePrefix + code,
+		Title:     i18n.T(locale, "error."+code),
+		Status:    status,
+		Detail:    detail,
+		ErrorCode: code,
+		Error:     http.StatusText(status),
+		Message:   detail,
+		Code:      status,
+	}
+}
+
+// Write writes the problem details of an error code. The title is in the language set by
+// Language, English otherwise.
+func Write(w http.ResponseWriter, status int, code, detail string) {
+	locale := i18n.Resolve(w.Header().Get("Content-Language"))
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(New(locale, status, code, detail))
+}
+
+// Language is a middleware answering in the supported language preferred by the Accept-Language
+// header of a request. It sets the Content-Language of the response, which titles problems.
+func Language() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if locale := i18n.FromAcceptLanguage(r.Header.Get("Accept-Language")); locale != "" {
+				w.Header().Set("Content-Language", locale)
+				w.Header().Add("Vary", "Accept-Language")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
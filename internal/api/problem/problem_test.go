@@ -0,0 +1,90 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jiin/stale/internal/i18n"
+)
+
+func TestWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+	Write(w, http.StatusConflict, CodeScanRunning, "a scan is already running")
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, ContentType)
+	}
+	var got Details
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	want := Details{
+		Type:      "urn:stale:error:scan_running",
+		Title:     "A scan is already running",
+		Status:    409,
+		Detail:    "a scan is already running",
+		ErrorCode: CodeScanRunning,
+		Error:     "Conflict",
+		Message:   "a scan is already running",
+		Code:      409,
+	}
+	if got != want {
+		t.Errorf("problem = %+v, want %+v", got, want)
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	h := Language()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, http.StatusNotFound, CodeNotFound, "source not found")
+	}))
+
+	tests := map[string]string{
+		"ko-KR,ko;q=0.9,en;q=0.8": i18n.T("ko", "error.not_found"),
+		"fr":                      "Not found",
+		"":                        "Not found",
+	}
+	for header, want := range tests {
+		req := httptest.NewRequest("GET", "/api/v1/sources/1", nil)
+		req.Header.Set("Accept-Language", header)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		var got Details
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatalf("failed to decode problem: %v", err)
+		}
+		if got.Title != want || got.Detail != "source not found" {
+			t.Errorf("Accept-Language %q: title %q, detail %q, want %q", header, got.Title, got.Detail, want)
+		}
+	}
+}
+
+func TestCodeFor(t *testing.T) {
+	tests := map[int]string{
+		http.StatusBadRequest:          CodeBadRequest,
+		http.StatusNotFound:            CodeNotFound,
+		http.StatusTooManyRequests:     CodeRateLimited,
+		http.StatusBadGateway:          CodeUpstream,
+		http.StatusServiceUnavailable:  CodeUnavailable,
+		http.StatusInternalServerError: CodeInternal,
+		http.StatusMethodNotAllowed:    CodeBadRequest,
+	}
+	for status, want := range tests {
+		if got := CodeFor(status); got != want {
+			t.Errorf("CodeFor(%d) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestCodesTitled(t *testing.T) {
+	for _, code := range Codes {
+		if title := i18n.T(i18n.Default, "error."+code); title == "error."+code {
+			t.Errorf("error code %s has no title", code)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"io/fs"
 	"net/http"
 	"path/filepath"
@@ -11,17 +12,25 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jiin/stale/internal/api/handler"
 	apimiddleware "github.com/jiin/stale/internal/api/middleware"
+	"github.com/jiin/stale/internal/api/problem"
+	"github.com/jiin/stale/internal/config"
 	"github.com/jiin/stale/internal/repository"
 	"github.com/jiin/stale/internal/service/email"
+	"github.com/jiin/stale/internal/service/feature"
+	"github.com/jiin/stale/internal/service/observability"
+	"github.com/jiin/stale/internal/service/scanner"
 	"github.com/jiin/stale/internal/service/scheduler"
+	"github.com/jiin/stale/internal/service/update"
 	"github.com/jiin/stale/ui"
 	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
 )
 
 // App holds the router and its dependencies for lifecycle management
 type App struct {
-	Router      *chi.Mux
-	rateLimiter *apimiddleware.RateLimiter
+	Router        *chi.Mux
+	rateLimiter   *apimiddleware.RateLimiter
+	ingestLimiter *apimiddleware.RateLimiter
 }
 
 // Stop cleans up resources (call during shutdown)
@@ -29,20 +38,29 @@ func (a *App) Stop() {
 	if a.rateLimiter != nil {
 		a.rateLimiter.Stop()
 	}
+	if a.ingestLimiter != nil {
+		a.ingestLimiter.Stop()
+	}
 }
 
 func NewRouter(
+	cfg *config.Config,
 	db *sqlx.DB,
 	scheduler *scheduler.Scheduler,
+	scanner *scanner.Scanner,
 	emailService *email.Service,
+	flags *feature.Flags,
+	updates *update.Checker,
 ) *App {
 	r := chi.NewRouter()
 
 	// Middleware
+	r.Use(apimiddleware.Tracing())
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Compress(5))
 	r.Use(apimiddleware.SecurityHeaders())
+	r.Use(problem.Language())
 	r.Use(apimiddleware.AuditLog())
 
 	// CORS configuration
@@ -57,6 +75,16 @@ func NewRouter(
 	rateLimiter := apimiddleware.NewRateLimiter(100, time.Second)
 	r.Use(rateLimiter.Handler)
 
+	// Read-only replicas reject requests that would change data
+	r.Use(apimiddleware.ReadOnly(cfg.ReadOnly))
+
+	// Ingestion by external scanners writes whole dependency lists: 10 requests per minute per client
+	ingestLimiter := apimiddleware.NewRateLimiter(10, time.Minute)
+
+	// Ad-hoc scans and ingestion look up the latest version of every dependency while the client
+	// waits, which takes longer than the server's timeouts
+	slowRequests := apimiddleware.Deadline(5 * time.Minute)
+
 	// Repositories
 	sourceRepo := repository.NewSourceRepository(db)
 	repoRepo := repository.NewRepoRepository(db)
@@ -64,72 +92,209 @@ func NewRouter(
 	scanRepo := repository.NewScanRepository(db)
 	settingsRepo := repository.NewSettingsRepository(db)
 	ignoredRepo := repository.NewIgnoredRepository(db)
+	alertRepo := repository.NewAlertRepository(db)
+	campaignRepo := repository.NewCampaignRepository(db)
+	viewRepo := repository.NewViewRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	policyRepo := repository.NewPolicyRepository(db)
 
 	// Handlers
-	healthHandler := handler.NewHealthHandler(db)
-	sourceHandler := handler.NewSourceHandler(sourceRepo, repoRepo, depRepo)
-	repoHandler := handler.NewRepoHandler(repoRepo, depRepo)
-	depHandler := handler.NewDependencyHandler(depRepo)
+	healthHandler := handler.NewHealthHandler(db, updates)
+	features := handler.Features{
+		Auth:       authConfig.Enabled,
+		ReadOnly:   cfg.ReadOnly,
+		Ecosystems: scanner.Ecosystems(),
+	}
+	versionHandler := handler.NewVersionHandler(features)
+	capabilitiesHandler := handler.NewCapabilitiesHandler(features, flags, scheduler)
+	sourceHandler := handler.NewSourceHandler(sourceRepo, repoRepo, depRepo, eventRepo, scanner, scheduler)
+	repoHandler := handler.NewRepoHandler(repoRepo, depRepo, sourceRepo, eventRepo, scanner, scheduler)
+	depHandler := handler.NewDependencyHandler(depRepo, settingsRepo, ignoredRepo, viewRepo, alertRepo, policyRepo)
 	scanHandler := handler.NewScanHandler(scanRepo, scheduler)
-	settingsHandler := handler.NewSettingsHandler(settingsRepo, scheduler, emailService)
-	ignoredHandler := handler.NewIgnoredHandler(ignoredRepo)
+	schedulerHandler := handler.NewSchedulerHandler(scheduler)
+	settingsHandler := handler.NewSettingsHandler(settingsRepo, sourceRepo, scheduler, emailService, observability.New())
+	ignoredHandler := handler.NewIgnoredHandler(ignoredRepo, eventRepo)
+	alertHandler := handler.NewAlertHandler(alertRepo)
+	featureHandler := handler.NewFeatureHandler(flags)
+	adminHandler := handler.NewAdminHandler(repository.NewMaintenanceRepository(db), scheduler.RecomputeOutdated, depHandler.ClearCache)
+	campaignHandler := handler.NewCampaignHandler(campaignRepo, depRepo)
+	viewHandler := handler.NewViewHandler(viewRepo, settingsRepo)
+	policyHandler := handler.NewPolicyHandler(policyRepo, depHandler.ClearCache)
+	recommendedHandler := handler.NewRecommendedVersionHandler(depRepo, scheduler.RecomputeOutdated)
+	eventHandler := handler.NewEventHandler(eventRepo, ignoredRepo, sourceRepo, repoRepo, depRepo, scheduler)
+	outboxHandler := handler.NewOutboxHandler(repository.NewOutboxRepository(db))
+	reportHandler := handler.NewReportHandler(scheduler)
+	configHandler := handler.NewConfigHandler(sourceRepo, repository.NewConfigRepository(db), ignoredRepo, settingsRepo, eventRepo, scheduler)
+	slackHandler := handler.NewSlackHandler(cfg.SlackSigningSecret, repoRepo, depRepo)
+	adhocHandler := handler.NewAdhocScanHandler(sourceRepo, scanner, scheduler, depHandler.ClearCache)
+	ingestHandler := handler.NewIngestHandler(sourceRepo, scanner, scheduler, depHandler.ClearCache)
+	statusHandler := handler.NewStatusHandler()
+	parseHandler := handler.NewParseHandler()
+	syntheticHandler := handler.NewSyntheticHandler(sourceRepo, scanRepo, scanner, depHandler.ClearCache)
 
 	// Register cache invalidation callback for scan completion
 	scheduler.OnScanComplete(depHandler.ClearCache)
+	scheduler.OnCacheSettings(depHandler.ApplyCacheSettings)
+	scheduler.ApplyCacheSettings(context.Background())
 
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Use(jsonContentType)
 
 		r.Get("/health", healthHandler.Check)
+		r.Get("/version", versionHandler.Get)
+		r.Get("/capabilities", capabilitiesHandler.Get)
+		r.Get("/status/integrations", statusHandler.Integrations)
 
 		r.Route("/sources", func(r chi.Router) {
 			r.Get("/", sourceHandler.List)
 			r.Post("/", sourceHandler.Create)
+			r.Post("/import", sourceHandler.Import)
+			r.Get("/token-warnings", sourceHandler.TokenWarnings)
 			r.Get("/{id}", sourceHandler.Get)
+			r.Get("/{id}/onboarding", sourceHandler.Onboarding)
+			r.Get("/{id}/token", sourceHandler.RevealToken)
+			r.Post("/{id}/check-token", sourceHandler.CheckToken)
+			r.Post("/{id}/enable", sourceHandler.Enable)
 			r.Put("/{id}", sourceHandler.Update)
 			r.Delete("/{id}", sourceHandler.Delete)
 		})
 
+		r.Route("/events", func(r chi.Router) {
+			r.Get("/", eventHandler.List)
+			r.Get("/stream", outboxHandler.Stream)
+			r.Post("/{id}/undo", eventHandler.Undo)
+		})
+
 		r.Route("/repositories", func(r chi.Router) {
 			r.Get("/", repoHandler.List)
 			r.Post("/bulk-delete", repoHandler.BulkDelete)
+			r.Post("/bulk-rescan", repoHandler.BulkRescan)
 			r.Get("/{id}", repoHandler.Get)
+			r.Patch("/{id}", repoHandler.Update)
 			r.Get("/{id}/dependencies", repoHandler.GetDependencies)
+			r.Get("/{id}/manifests", repoHandler.GetManifests)
+			r.Get("/{id}/manifests/*", repoHandler.GetManifestContent)
+			r.Get("/{id}/skipped", repoHandler.GetSkipped)
+			r.Get("/{id}/branches", repoHandler.GetBranches)
+			r.Get("/{id}/compare", repoHandler.Compare)
+			r.With(apimiddleware.RequireFeature(flags, feature.VulnerabilityAlerts)).Get("/{id}/alerts", alertHandler.ListByRepository)
 			r.Delete("/{id}", repoHandler.Delete)
 		})
 
 		r.Route("/dependencies", func(r chi.Router) {
 			r.Get("/", depHandler.List)
 			r.Get("/paginated", depHandler.ListPaginated)
+			r.Get("/grouped", depHandler.Grouped)
 			r.Get("/upgradable", depHandler.GetUpgradable)
 			r.Get("/stats", depHandler.GetStats)
 			r.Get("/repos", depHandler.GetRepositoryNames)
 			r.Get("/packages", depHandler.GetPackageNames)
 			r.Get("/filter-options", depHandler.GetFilterOptions)
+			r.Get("/maintainers", depHandler.Maintainers)
 			r.Get("/export", depHandler.ExportCSV)
+			r.Get("/{id}", depHandler.Get)
 		})
 
+		r.With(apimiddleware.RequireFeature(flags, feature.VulnerabilityAlerts)).Get("/alerts", alertHandler.List)
+
+		r.Post("/admin/maintenance", adminHandler.Maintenance)
+
+		// Load testing helpers, never routed in production
+		if cfg.DevMode {
+			log.Warn().Msg("Dev mode enabled - synthetic data can be generated at /api/v1/dev/synthetic")
+			r.Post("/dev/synthetic", syntheticHandler.Generate)
+		}
+
+		r.Route("/features", func(r chi.Router) {
+			r.Get("/", featureHandler.List)
+			r.Put("/{name}", featureHandler.Update)
+			r.Delete("/{name}", featureHandler.Reset)
+		})
+		r.Get("/graph", depHandler.Graph)
+		r.Get("/packages/convergence", depHandler.Convergence)
+		r.Get("/packages/forecast", depHandler.Forecast)
+		r.Get("/reports/comparison", reportHandler.Comparison)
+
+		r.Route("/policies", func(r chi.Router) {
+			r.Get("/", policyHandler.List)
+			r.Post("/", policyHandler.Create)
+			r.Get("/violations", policyHandler.Violations)
+			r.Delete("/{id}", policyHandler.Delete)
+		})
+
+		r.Route("/recommended-versions", func(r chi.Router) {
+			r.Get("/", recommendedHandler.List)
+			r.Put("/{ecosystem}/{name}", recommendedHandler.Set)
+			r.Delete("/{ecosystem}/{name}", recommendedHandler.Delete)
+		})
+		r.Get("/packages/{ecosystem}/{name}/impact", depHandler.Impact)
+
+		r.With(slowRequests).Post("/adhoc-scan", adhocHandler.Scan)
+		r.Post("/parse", parseHandler.Parse)
+		r.Route("/ingest", func(r chi.Router) {
+			r.Use(ingestLimiter.Handler, slowRequests)
+			r.Post("/", ingestHandler.Ingest)
+			r.Post("/batch", ingestHandler.IngestBatch)
+			r.Post("/sbom", ingestHandler.IngestSBOM)
+		})
+
+		r.Post("/slack/command", slackHandler.Command)
+
 		r.Route("/scans", func(r chi.Router) {
 			r.Post("/", scanHandler.TriggerScan)
 			r.Get("/", scanHandler.List)
 			r.Get("/running", scanHandler.GetRunning)
 			r.Get("/{id}", scanHandler.Get)
+			r.Get("/{id}/usage", scanHandler.Usage)
 			r.Post("/{id}/cancel", scanHandler.Cancel)
 		})
 
+		r.Route("/scheduler", func(r chi.Router) {
+			r.Get("/", schedulerHandler.Status)
+			r.Post("/pause", schedulerHandler.Pause)
+			r.Post("/resume", schedulerHandler.Resume)
+		})
+
 		r.Route("/settings", func(r chi.Router) {
 			r.Get("/", settingsHandler.Get)
 			r.Put("/", settingsHandler.Update)
 			r.Post("/test-email", settingsHandler.TestEmail)
+			r.Post("/validate", settingsHandler.Validate)
+			r.Post("/preview-notification", settingsHandler.PreviewNotification)
+			r.Post("/recompute-outdated", settingsHandler.RecomputeOutdated)
 			r.Get("/next-scan", settingsHandler.GetNextScan)
 		})
 
+		r.Route("/views", func(r chi.Router) {
+			r.Get("/", viewHandler.List)
+			r.Post("/", viewHandler.Create)
+			r.Get("/{id}", viewHandler.Get)
+			r.Put("/{id}", viewHandler.Update)
+			r.Delete("/{id}", viewHandler.Delete)
+		})
+
+		r.Route("/campaigns", func(r chi.Router) {
+			r.Get("/", campaignHandler.List)
+			r.Post("/", campaignHandler.Create)
+			r.Get("/{id}", campaignHandler.Get)
+			r.Put("/{id}", campaignHandler.Update)
+			r.Delete("/{id}", campaignHandler.Delete)
+		})
+
+		r.Get("/config", configHandler.Get)
+		r.Put("/config", configHandler.Apply)
+
 		r.Route("/ignored", func(r chi.Router) {
 			r.Get("/", ignoredHandler.List)
 			r.Post("/", ignoredHandler.Create)
 			r.Post("/bulk", ignoredHandler.BulkCreate)
 			r.Post("/bulk-delete", ignoredHandler.BulkDelete)
+			r.Get("/export", ignoredHandler.ExportCSV)
+			r.Post("/import", ignoredHandler.ImportCSV)
+			r.Get("/suggestions", ignoredHandler.ListSuggestions)
+			r.Post("/suggestions/import", ignoredHandler.ImportSuggestions)
+			r.Post("/suggestions/dismiss", ignoredHandler.DismissSuggestions)
 			r.Delete("/{id}", ignoredHandler.Delete)
 		})
 	})
@@ -138,8 +303,9 @@ func NewRouter(
 	r.Get("/*", spaHandler())
 
 	return &App{
-		Router:      r,
-		rateLimiter: rateLimiter,
+		Router:        r,
+		rateLimiter:   rateLimiter,
+		ingestLimiter: ingestLimiter,
 	}
 }
 
@@ -0,0 +1,50 @@
+// Package buildinfo describes the running build of stale. Version, Commit and Date are injected
+// at build time, e.g.
+//
+//	go build -ldflags "-X github.com/jiin/stale/internal/buildinfo.Version=0.3.0 -X github.com/jiin/stale/internal/buildinfo.Commit=$(git rev-parse HEAD)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	// Version is the release version of the build
+	Version = "0.2.0"
+	// Commit is the git commit the build was made from
+	Commit = ""
+	// Date is when the build was made, in RFC 3339 format
+	Date = ""
+)
+
+// Info is the build information reported by the version endpoint
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information. The commit and date fall back to the VCS information Go
+// embeds in binaries built from a git checkout.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: Date,
+		GoVersion: runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = s.Value
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,21 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	oldVersion, oldCommit, oldDate := Version, Commit, Date
+	defer func() { Version, Commit, Date = oldVersion, oldCommit, oldDate }()
+
+	Version, Commit, Date = "1.2.3", "abc123", "2024-05-01T10:00:00Z"
+	info := Get()
+
+	if info.Version != "1.2.3" || info.Commit != "abc123" || info.BuildDate != "2024-05-01T10:00:00Z" {
+		t.Errorf("Get() = %+v, want the injected version, commit and date", info)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("Get().GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+}
@@ -3,21 +3,93 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 type Config struct {
 	Port              string
 	DatabasePath      string
+	AutoMigrate       bool // apply pending migrations on start; when off, the server refuses to start with pending migrations
 	ScanIntervalHours int
 	LogLevel          string
+	OwnershipFile     string // repository metadata file to read owners from, besides CODEOWNERS
+	SPIToken          string // Swift Package Index API token, needed to look up Swift packages
+	InstanceName      string // identifies this instance in the User-Agent of outbound requests
+	ContactURL        string // where registry and provider admins can reach the operator, sent in the User-Agent
+
+	// Scan sources one at a time and everything in name order, see scanner.SetDeterministic
+	DeterministicScans bool
+	// Most used packages whose latest versions are prefetched on startup and before scheduled scans
+	PrefetchPackages int
+	// Store the content of scanned manifests for inspection, gzipped with CompressManifests
+	ManifestSnapshots bool
+	CompressManifests bool
+
+	// Serve reads only: no migrations, no scheduler and mutating endpoints rejected, so replicas
+	// can serve dashboards from a database another instance writes to
+	ReadOnly bool
+
+	// Verifies that Slack slash commands come from Slack; the endpoint is disabled without it
+	SlackSigningSecret string
+
+	// Route load testing helpers such as synthetic data generation; never enable in production
+	DevMode bool
+
+	// Relay the integration events of the outbox to this NATS server, on subjects under the prefix
+	OutboxNATSURL       string
+	OutboxSubjectPrefix string
+
+	// Outbound HTTP connection tuning, see httputil.TransportConfig
+	HTTPMaxConnsPerHost         int
+	HTTPProviderMaxConnsPerHost int
+	HTTP2                       bool
+
+	// Periodic check of the GitHub releases of stale for a newer version
+	UpdateCheck              bool
+	UpdateCheckIntervalHours int
+
+	// Defaults of `stale agent`, which scans inside a private network and reports to a central server
+	AgentConfig   string        // configuration document listing the sources to scan
+	AgentName     string        // external source the agent's repositories are reported under
+	AgentInterval time.Duration // time between scans, 0 to scan once
 }
 
 func Load() *Config {
 	return &Config{
 		Port:              getEnv("STALE_PORT", "3000"),
 		DatabasePath:      getEnv("STALE_DB_PATH", "./stale.db"),
+		AutoMigrate:       getEnvBool("STALE_AUTO_MIGRATE", true),
 		ScanIntervalHours: getEnvInt("STALE_SCAN_INTERVAL", 24),
 		LogLevel:          getEnv("STALE_LOG_LEVEL", "info"),
+		OwnershipFile:     getEnv("STALE_OWNERSHIP_FILE", "catalog-info.yaml"),
+		SPIToken:          getEnv("STALE_SPI_TOKEN", ""),
+		InstanceName:      getEnv("STALE_INSTANCE_NAME", ""),
+		ContactURL:        getEnv("STALE_CONTACT_URL", ""),
+
+		DeterministicScans: getEnvBool("STALE_DETERMINISTIC_SCANS", false),
+		PrefetchPackages:   getEnvInt("STALE_PREFETCH_PACKAGES", 200),
+		ManifestSnapshots:  getEnvBool("STALE_MANIFEST_SNAPSHOTS", true),
+		CompressManifests:  getEnvBool("STALE_COMPRESS_MANIFESTS", false),
+
+		ReadOnly: getEnvBool("STALE_READ_ONLY", false),
+
+		SlackSigningSecret: getEnv("STALE_SLACK_SIGNING_SECRET", ""),
+
+		DevMode: getEnvBool("STALE_DEV_MODE", false),
+
+		OutboxNATSURL:       getEnv("STALE_OUTBOX_NATS_URL", ""),
+		OutboxSubjectPrefix: getEnv("STALE_OUTBOX_SUBJECT_PREFIX", "stale"),
+
+		HTTPMaxConnsPerHost:         getEnvInt("STALE_HTTP_MAX_CONNS_PER_HOST", 32),
+		HTTPProviderMaxConnsPerHost: getEnvInt("STALE_HTTP_PROVIDER_MAX_CONNS_PER_HOST", 16),
+		HTTP2:                       getEnvBool("STALE_HTTP2", true),
+
+		UpdateCheck:              getEnvBool("STALE_UPDATE_CHECK", true),
+		UpdateCheckIntervalHours: getEnvInt("STALE_UPDATE_CHECK_INTERVAL", 24),
+
+		AgentConfig:   getEnv("STALE_AGENT_CONFIG", "stale-agent.json"),
+		AgentName:     getEnv("STALE_AGENT_NAME", defaultAgentName()),
+		AgentInterval: getEnvDuration("STALE_AGENT_INTERVAL", 6*time.Hour),
 	}
 }
 
@@ -28,6 +100,33 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+// defaultAgentName names an agent after its host, e.g. agent-build01
+func defaultAgentName() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "agent"
+	}
+	return "agent-" + host
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if i, err := strconv.Atoi(value); err == nil {
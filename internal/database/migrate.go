@@ -0,0 +1,270 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Migrations are embedded as NNN_name.sql, applied in version order. A NNN_name.down.sql file
+// reverts its migration; migrations without one cannot be rolled back.
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// downSuffix marks the file reverting a migration
+const downSuffix = ".down.sql"
+
+// Migration is a versioned schema change
+type Migration struct {
+	Version int
+	Name    string // file of the migration, e.g. migrations/047_package_policies.sql, as recorded once applied
+	Up      string
+	Down    string // empty when the migration cannot be rolled back
+}
+
+// Reversible reports whether the migration can be rolled back
+func (m Migration) Reversible() bool {
+	return m.Down != ""
+}
+
+// MigrationStatus is a known migration with whether it was applied
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrations returns the embedded migrations ordered by version
+func Migrations() ([]Migration, error) {
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	downs := make(map[string]string)
+	var list []Migration
+	for _, file := range files {
+		content, err := migrations.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file %s: %w", file, err)
+		}
+		if up, ok := strings.CutSuffix(file, downSuffix); ok {
+			downs[up+".sql"] = string(content)
+			continue
+		}
+
+		prefix, _, _ := strings.Cut(path.Base(file), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has no version prefix", file)
+		}
+		list = append(list, Migration{Version: version, Name: file, Up: string(content)})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	for i := range list {
+		if i > 0 && list[i].Version == list[i-1].Version {
+			return nil, fmt.Errorf("migrations %s and %s have the same version", list[i-1].Name, list[i].Name)
+		}
+		list[i].Down = downs[list[i].Name]
+		delete(downs, list[i].Name)
+	}
+	for up := range downs {
+		return nil, fmt.Errorf("down migration of %s has no migration", up)
+	}
+	return list, nil
+}
+
+// ensureMigrationTable creates the table recording applied migrations
+func ensureMigrationTable(db *sqlx.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		name TEXT PRIMARY KEY,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// Status returns every known migration with when it was applied, ordered by version
+func Status(db *sqlx.DB) ([]MigrationStatus, error) {
+	list, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationTable(db); err != nil {
+		return nil, err
+	}
+
+	var applied []struct {
+		Name      string    `db:"name"`
+		AppliedAt time.Time `db:"applied_at"`
+	}
+	if err := db.Select(&applied, "SELECT name, applied_at FROM schema_migrations"); err != nil {
+		return nil, fmt.Errorf("failed to load applied migrations: %w", err)
+	}
+	appliedAt := make(map[string]time.Time, len(applied))
+	for _, a := range applied {
+		appliedAt[a.Name] = a.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, len(list))
+	for i, m := range list {
+		statuses[i] = MigrationStatus{Migration: m}
+		if at, ok := appliedAt[m.Name]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations not applied yet, ordered by version
+func Pending(db *sqlx.DB) ([]Migration, error) {
+	statuses, err := Status(db)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// Version returns the schema version, the highest version applied, or 0 for an empty database
+func Version(db *sqlx.DB) (int, error) {
+	statuses, err := Status(db)
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for _, s := range statuses {
+		if s.AppliedAt != nil {
+			version = s.Version
+		}
+	}
+	return version, nil
+}
+
+// Migrate applies the pending migrations in version order
+func Migrate(db *sqlx.DB) error {
+	// Track applied migrations so non-idempotent migrations (table rebuilds)
+	// only run once. Databases created before tracking existed simply re-run
+	// the early migrations, whose first statement then fails with "already exists".
+	pending, err := Pending(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range pending {
+		if err := apply(db, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply runs a migration and records it in one transaction, so a migration failing halfway
+// leaves neither its changes nor its record behind. A migration whose first statement fails
+// because its table or column already exists was applied before migrations were tracked, and is
+// only recorded.
+func apply(db *sqlx.DB, m Migration) error {
+	first, rest := splitFirstStatement(m.Up)
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(first); err != nil {
+		tx.Rollback()
+		if !alreadyApplied(err) {
+			return fmt.Errorf("migration %s failed: %w", m.Name, err)
+		}
+		if _, err := db.Exec("INSERT INTO schema_migrations (name) VALUES (?)", m.Name); err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", m.Name, err)
+		}
+		return nil
+	}
+	if strings.TrimSpace(rest) != "" {
+		if _, err := tx.Exec(rest); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s failed: %w", m.Name, err)
+		}
+	}
+	if _, err := tx.Exec("INSERT INTO schema_migrations (name) VALUES (?)", m.Name); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to record migration %s: %w", m.Name, err)
+	}
+	return tx.Commit()
+}
+
+// alreadyApplied reports whether a migration statement failed because its change already exists
+func alreadyApplied(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already exists") || strings.Contains(msg, "duplicate column")
+}
+
+// splitFirstStatement splits a migration after its first statement, which ends the first line
+// ending with a semicolon. Comment lines before it are skipped.
+func splitFirstStatement(sql string) (first, rest string) {
+	lines := strings.SplitAfter(sql, "\n")
+	var b strings.Builder
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if b.Len() == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+		b.WriteString(line)
+		if strings.HasSuffix(trimmed, ";") {
+			return b.String(), strings.Join(lines[i+1:], "")
+		}
+	}
+	return b.String(), ""
+}
+
+// Rollback reverts the latest steps applied migrations, newest first, and returns those reverted.
+// It stops at the first migration that cannot be rolled back, before changing anything for it.
+func Rollback(db *sqlx.DB, steps int) ([]Migration, error) {
+	statuses, err := Status(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := len(statuses) - 1; i >= 0 && len(reverted) < steps; i-- {
+		m := statuses[i]
+		if m.AppliedAt == nil {
+			continue
+		}
+		if !m.Reversible() {
+			return reverted, fmt.Errorf("migration %s cannot be rolled back", m.Name)
+		}
+
+		tx, err := db.Beginx()
+		if err != nil {
+			return reverted, err
+		}
+		if _, err := tx.Exec(m.Down); err != nil {
+			tx.Rollback()
+			return reverted, fmt.Errorf("rollback of %s failed: %w", m.Name, err)
+		}
+		if _, err := tx.Exec("DELETE FROM schema_migrations WHERE name = ?", m.Name); err != nil {
+			tx.Rollback()
+			return reverted, fmt.Errorf("failed to unrecord migration %s: %w", m.Name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, m.Migration)
+	}
+	return reverted, nil
+}
@@ -0,0 +1,34 @@
+-- Track the manifest file each dependency was parsed from.
+-- The same package may appear in several manifests of a monorepo, so the
+-- unique key now includes the manifest path. SQLite cannot alter constraints,
+-- so the table is rebuilt.
+CREATE TABLE dependencies_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    current_version TEXT NOT NULL,
+    latest_version TEXT,
+    type TEXT NOT NULL DEFAULT 'dependency',
+    ecosystem TEXT NOT NULL DEFAULT 'npm',
+    is_outdated BOOLEAN DEFAULT FALSE,
+    previously_outdated BOOLEAN DEFAULT 0,
+    manifest_path TEXT NOT NULL DEFAULT '',
+    manifest_type TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(repository_id, manifest_path, name, type)
+);
+
+INSERT INTO dependencies_new (id, repository_id, name, current_version, latest_version, type, ecosystem, is_outdated, previously_outdated, updated_at)
+SELECT id, repository_id, name, current_version, latest_version, type, ecosystem, is_outdated, previously_outdated, updated_at
+FROM dependencies;
+
+DROP TABLE dependencies;
+ALTER TABLE dependencies_new RENAME TO dependencies;
+
+CREATE INDEX IF NOT EXISTS idx_dependencies_repository_id ON dependencies(repository_id);
+CREATE INDEX IF NOT EXISTS idx_dependencies_is_outdated ON dependencies(is_outdated);
+CREATE INDEX IF NOT EXISTS idx_dependencies_name ON dependencies(name);
+CREATE INDEX IF NOT EXISTS idx_dependencies_ecosystem ON dependencies(ecosystem);
+CREATE INDEX IF NOT EXISTS idx_dependencies_outdated_ecosystem ON dependencies(is_outdated, ecosystem);
+CREATE INDEX IF NOT EXISTS idx_dependencies_type ON dependencies(type);
+CREATE INDEX IF NOT EXISTS idx_dependencies_repo_manifest ON dependencies(repository_id, manifest_path);
@@ -0,0 +1,2 @@
+-- Add branch_override column to repositories for per-repository scan branch
+ALTER TABLE repositories ADD COLUMN branch_override TEXT DEFAULT '';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS branch_dependencies;
+ALTER TABLE sources DROP COLUMN additional_branches;
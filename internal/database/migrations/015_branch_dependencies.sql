@@ -0,0 +1,21 @@
+-- Dependency sets of additional branches (e.g. release/*) scanned besides the primary branch
+CREATE TABLE IF NOT EXISTS branch_dependencies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    branch TEXT NOT NULL,
+    name TEXT NOT NULL,
+    current_version TEXT NOT NULL,
+    latest_version TEXT,
+    type TEXT NOT NULL DEFAULT 'dependency',
+    ecosystem TEXT NOT NULL DEFAULT 'npm',
+    is_outdated BOOLEAN DEFAULT FALSE,
+    manifest_path TEXT NOT NULL DEFAULT '',
+    manifest_type TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(repository_id, branch, manifest_path, name, type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_branch_dependencies_repo_branch ON branch_dependencies(repository_id, branch);
+
+-- Additional branches to scan per source (comma-separated names or glob patterns)
+ALTER TABLE sources ADD COLUMN additional_branches TEXT DEFAULT '';
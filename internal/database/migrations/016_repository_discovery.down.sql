@@ -0,0 +1,3 @@
+ALTER TABLE repositories DROP COLUMN removed_at;
+ALTER TABLE scan_jobs DROP COLUMN repos_new;
+ALTER TABLE scan_jobs DROP COLUMN repos_removed;
@@ -0,0 +1,4 @@
+-- Track repositories that disappeared from their source and discovery counts per scan
+ALTER TABLE repositories ADD COLUMN removed_at DATETIME;
+ALTER TABLE scan_jobs ADD COLUMN repos_new INTEGER DEFAULT 0;
+ALTER TABLE scan_jobs ADD COLUMN repos_removed INTEGER DEFAULT 0;
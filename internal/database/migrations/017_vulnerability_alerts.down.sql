@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS vulnerability_alerts;
+ALTER TABLE sources DROP COLUMN import_dependabot_alerts;
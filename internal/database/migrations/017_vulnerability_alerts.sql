@@ -0,0 +1,23 @@
+-- Vulnerability alerts imported from the provider (GitHub Dependabot)
+CREATE TABLE IF NOT EXISTS vulnerability_alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    alert_number INTEGER NOT NULL,
+    ecosystem TEXT NOT NULL DEFAULT '',
+    package_name TEXT NOT NULL,
+    manifest_path TEXT NOT NULL DEFAULT '',
+    severity TEXT NOT NULL DEFAULT '',
+    ghsa_id TEXT NOT NULL DEFAULT '',
+    cve_id TEXT NOT NULL DEFAULT '',
+    summary TEXT NOT NULL DEFAULT '',
+    vulnerable_range TEXT NOT NULL DEFAULT '',
+    patched_version TEXT NOT NULL DEFAULT '',
+    html_url TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(repository_id, alert_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_vulnerability_alerts_repo ON vulnerability_alerts(repository_id);
+CREATE INDEX IF NOT EXISTS idx_vulnerability_alerts_package ON vulnerability_alerts(ecosystem, package_name);
+
+ALTER TABLE sources ADD COLUMN import_dependabot_alerts BOOLEAN DEFAULT FALSE;
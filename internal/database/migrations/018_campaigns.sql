@@ -0,0 +1,12 @@
+-- Upgrade campaigns: a package to be upgraded to a target version across all repositories
+CREATE TABLE IF NOT EXISTS campaigns (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    ecosystem TEXT NOT NULL,
+    package_name TEXT NOT NULL,
+    target_version TEXT NOT NULL,
+    due_date DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,4 @@
+ALTER TABLE sources DROP COLUMN token_status;
+ALTER TABLE sources DROP COLUMN token_error;
+ALTER TABLE sources DROP COLUMN token_expires_at;
+ALTER TABLE sources DROP COLUMN token_checked_at;
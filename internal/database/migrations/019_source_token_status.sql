@@ -0,0 +1,5 @@
+-- Token validity of sources, refreshed periodically to warn before scans start failing
+ALTER TABLE sources ADD COLUMN token_status TEXT NOT NULL DEFAULT '';
+ALTER TABLE sources ADD COLUMN token_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE sources ADD COLUMN token_expires_at DATETIME;
+ALTER TABLE sources ADD COLUMN token_checked_at DATETIME;
@@ -0,0 +1,2 @@
+ALTER TABLE sources DROP COLUMN schedule_cron;
+ALTER TABLE sources DROP COLUMN scan_window;
@@ -0,0 +1,3 @@
+-- Per-source scan schedule (cron) and allowed scan window (e.g. "22:00-06:00")
+ALTER TABLE sources ADD COLUMN schedule_cron TEXT NOT NULL DEFAULT '';
+ALTER TABLE sources ADD COLUMN scan_window TEXT NOT NULL DEFAULT '';
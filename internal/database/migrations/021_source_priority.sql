@@ -0,0 +1,2 @@
+-- Scan priority of sources: higher priorities are scanned first
+ALTER TABLE sources ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS scan_source_usage;
+ALTER TABLE scan_jobs DROP COLUMN api_calls;
+ALTER TABLE scan_jobs DROP COLUMN bytes_fetched;
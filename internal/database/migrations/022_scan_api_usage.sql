@@ -0,0 +1,14 @@
+-- Provider API usage of scans, in total and per source
+ALTER TABLE scan_jobs ADD COLUMN api_calls INTEGER DEFAULT 0;
+ALTER TABLE scan_jobs ADD COLUMN bytes_fetched INTEGER DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS scan_source_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    scan_id INTEGER NOT NULL REFERENCES scan_jobs(id) ON DELETE CASCADE,
+    source_id INTEGER NOT NULL,
+    source_name TEXT NOT NULL DEFAULT '',
+    api_calls INTEGER NOT NULL DEFAULT 0,
+    bytes_fetched INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(scan_id, source_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE dependencies DROP COLUMN first_outdated_at;
+DELETE FROM settings WHERE key IN ('sla_major_days', 'sla_minor_days', 'sla_patch_days');
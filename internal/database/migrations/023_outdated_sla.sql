@@ -0,0 +1,8 @@
+-- When each dependency became outdated, kept across scans until it is up to date again
+ALTER TABLE dependencies ADD COLUMN first_outdated_at DATETIME;
+UPDATE dependencies SET first_outdated_at = updated_at WHERE is_outdated = 1;
+
+-- Days allowed to fix an outdated dependency by upgrade type (0 disables the SLA)
+INSERT OR IGNORE INTO settings (key, value) VALUES ('sla_major_days', '90');
+INSERT OR IGNORE INTO settings (key, value) VALUES ('sla_minor_days', '60');
+INSERT OR IGNORE INTO settings (key, value) VALUES ('sla_patch_days', '30');
@@ -0,0 +1 @@
+ALTER TABLE ignored_dependencies DROP COLUMN expires_at;
@@ -0,0 +1,2 @@
+-- Ignore rules can expire, after which the dependency is reported again
+ALTER TABLE ignored_dependencies ADD COLUMN expires_at DATETIME;
@@ -0,0 +1,2 @@
+ALTER TABLE repositories DROP COLUMN owners;
+ALTER TABLE repositories DROP COLUMN owners_source;
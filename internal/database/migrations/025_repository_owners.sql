@@ -0,0 +1,3 @@
+-- Owners of a repository read from its CODEOWNERS or metadata file during scans
+ALTER TABLE repositories ADD COLUMN owners TEXT DEFAULT '';
+ALTER TABLE repositories ADD COLUMN owners_source TEXT DEFAULT '';
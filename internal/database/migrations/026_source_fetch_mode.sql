@@ -0,0 +1,2 @@
+-- How manifests of a source are fetched: api (default, empty) or archive
+ALTER TABLE sources ADD COLUMN fetch_mode TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+-- Content hashes of the manifests of the primary scan branch, used to skip unchanged manifests
+CREATE TABLE IF NOT EXISTS manifest_hashes (
+    repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    manifest_path TEXT NOT NULL,
+    content_hash TEXT NOT NULL,
+    hashed_at DATETIME NOT NULL,
+    PRIMARY KEY (repository_id, manifest_path)
+);
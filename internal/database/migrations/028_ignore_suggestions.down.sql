@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS ignore_suggestions;
+
+CREATE TABLE ignored_dependencies_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    ecosystem TEXT,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME,
+    UNIQUE(name, ecosystem)
+);
+
+INSERT OR IGNORE INTO ignored_dependencies_old (id, name, ecosystem, reason, created_at, expires_at)
+SELECT id, name, ecosystem, reason, created_at, expires_at
+FROM ignored_dependencies ORDER BY repository != '', id;
+
+DROP TABLE ignored_dependencies;
+ALTER TABLE ignored_dependencies_old RENAME TO ignored_dependencies;
@@ -0,0 +1,33 @@
+-- Ignore rules can be scoped to a single repository (by full name; empty applies everywhere).
+-- The unique key now includes the repository. SQLite cannot alter constraints, so the table is rebuilt.
+CREATE TABLE ignored_dependencies_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    ecosystem TEXT,
+    reason TEXT,
+    repository TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME,
+    UNIQUE(name, ecosystem, repository)
+);
+
+INSERT INTO ignored_dependencies_new (id, name, ecosystem, reason, created_at, expires_at)
+SELECT id, name, ecosystem, reason, created_at, expires_at
+FROM ignored_dependencies;
+
+DROP TABLE ignored_dependencies;
+ALTER TABLE ignored_dependencies_new RENAME TO ignored_dependencies;
+
+-- Dependencies excluded by the Renovate or Dependabot config of a repository, offered for
+-- import as repository-scoped ignore rules. Dismissed suggestions are kept so they are not offered again.
+CREATE TABLE IF NOT EXISTS ignore_suggestions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    ecosystem TEXT NOT NULL DEFAULT '',
+    config_path TEXT NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    dismissed BOOLEAN NOT NULL DEFAULT 0,
+    detected_at DATETIME NOT NULL,
+    UNIQUE(repository_id, name, ecosystem)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notification_threads;
+DROP TABLE IF EXISTS notified_dependencies;
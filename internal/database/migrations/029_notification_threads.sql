@@ -0,0 +1,15 @@
+-- Dependencies reported by new outdated dependency emails, so they are not repeated within the reminder window
+CREATE TABLE IF NOT EXISTS notified_dependencies (
+    repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    ecosystem TEXT NOT NULL,
+    notified_at DATETIME NOT NULL,
+    PRIMARY KEY (repository_id, name, ecosystem)
+);
+
+-- First email of each notification thread; later emails of the thread reply to it
+CREATE TABLE IF NOT EXISTS notification_threads (
+    thread TEXT PRIMARY KEY,
+    message_id TEXT NOT NULL,
+    started_at DATETIME NOT NULL
+);
@@ -0,0 +1,2 @@
+-- Timezone of a source's own schedule and scan window (empty = server timezone)
+ALTER TABLE sources ADD COLUMN schedule_timezone TEXT NOT NULL DEFAULT '';
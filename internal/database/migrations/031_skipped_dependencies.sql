@@ -0,0 +1,15 @@
+-- Dependencies of the primary branch that could not be checked, with the reason
+CREATE TABLE IF NOT EXISTS skipped_dependencies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    ecosystem TEXT NOT NULL,
+    version TEXT NOT NULL DEFAULT '',
+    manifest_path TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    detected_at DATETIME NOT NULL,
+    UNIQUE(repository_id, manifest_path, name, ecosystem)
+);
+
+CREATE INDEX IF NOT EXISTS idx_skipped_dependencies_repository ON skipped_dependencies(repository_id);
@@ -0,0 +1,6 @@
+-- Feature flags enabled or disabled on this instance; flags without a row use their default
+CREATE TABLE IF NOT EXISTS feature_flags (
+    name TEXT PRIMARY KEY,
+    enabled BOOLEAN NOT NULL,
+    updated_at DATETIME NOT NULL
+);
@@ -0,0 +1,2 @@
+ALTER TABLE dependencies DROP COLUMN scope;
+ALTER TABLE branch_dependencies DROP COLUMN scope;
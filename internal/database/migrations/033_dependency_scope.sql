@@ -0,0 +1,4 @@
+-- Scope a dependency is declared with, e.g. compile, provided, runtime, test or import for Maven;
+-- empty for ecosystems without scopes
+ALTER TABLE dependencies ADD COLUMN scope TEXT NOT NULL DEFAULT '';
+ALTER TABLE branch_dependencies ADD COLUMN scope TEXT NOT NULL DEFAULT '';
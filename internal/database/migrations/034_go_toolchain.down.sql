@@ -0,0 +1,5 @@
+ALTER TABLE repositories DROP COLUMN go_version;
+ALTER TABLE repositories DROP COLUMN go_toolchain;
+ALTER TABLE repositories DROP COLUMN go_vendored;
+ALTER TABLE repositories DROP COLUMN go_latest_version;
+ALTER TABLE repositories DROP COLUMN go_toolchain_outdated;
@@ -0,0 +1,7 @@
+-- Go runtime of a repository, read from its root-most go.mod; the toolchain is compared against
+-- the latest Go release at scan time
+ALTER TABLE repositories ADD COLUMN go_version TEXT NOT NULL DEFAULT '';
+ALTER TABLE repositories ADD COLUMN go_toolchain TEXT NOT NULL DEFAULT '';
+ALTER TABLE repositories ADD COLUMN go_vendored BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE repositories ADD COLUMN go_latest_version TEXT NOT NULL DEFAULT '';
+ALTER TABLE repositories ADD COLUMN go_toolchain_outdated BOOLEAN NOT NULL DEFAULT FALSE;
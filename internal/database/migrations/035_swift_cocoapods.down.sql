@@ -0,0 +1,2 @@
+ALTER TABLE repositories DROP COLUMN has_swiftpm;
+ALTER TABLE repositories DROP COLUMN has_podfile;
@@ -0,0 +1,3 @@
+-- Repositories with Swift packages (Package.swift or Package.resolved) and CocoaPods (Podfile or Podfile.lock)
+ALTER TABLE repositories ADD COLUMN has_swiftpm BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE repositories ADD COLUMN has_podfile BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,11 @@
+-- Maintainers the registries list for the packages in use, refreshed periodically
+CREATE TABLE IF NOT EXISTS package_maintainers (
+    ecosystem TEXT NOT NULL,
+    name TEXT NOT NULL,
+    maintainers TEXT NOT NULL DEFAULT '',
+    maintainer_count INTEGER NOT NULL DEFAULT 0,
+    fetched_at DATETIME NOT NULL,
+    PRIMARY KEY (ecosystem, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_package_maintainers_count ON package_maintainers(maintainer_count);
@@ -0,0 +1,2 @@
+ALTER TABLE dependencies DROP COLUMN risks;
+ALTER TABLE dependencies DROP COLUMN risk_detail;
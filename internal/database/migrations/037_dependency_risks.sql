@@ -0,0 +1,3 @@
+-- Supply-chain risks found for a dependency, e.g. install_script,typosquat, with a description of each
+ALTER TABLE dependencies ADD COLUMN risks TEXT NOT NULL DEFAULT '';
+ALTER TABLE dependencies ADD COLUMN risk_detail TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS failure_notifications;
+
+ALTER TABLE sources DROP COLUMN last_scan_error;
+ALTER TABLE sources DROP COLUMN last_scan_error_at;
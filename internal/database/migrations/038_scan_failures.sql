@@ -0,0 +1,9 @@
+-- Error of the last failed scan of a source, cleared once the source scans successfully
+ALTER TABLE sources ADD COLUMN last_scan_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE sources ADD COLUMN last_scan_error_at DATETIME;
+
+-- When a scan failure was last notified, per source (0 for failures not tied to a source)
+CREATE TABLE IF NOT EXISTS failure_notifications (
+    source_id INTEGER PRIMARY KEY,
+    notified_at DATETIME NOT NULL
+);
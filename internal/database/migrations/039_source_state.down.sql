@@ -0,0 +1,3 @@
+ALTER TABLE sources DROP COLUMN state;
+ALTER TABLE sources DROP COLUMN consecutive_failures;
+ALTER TABLE sources DROP COLUMN disabled_at;
@@ -0,0 +1,4 @@
+-- Sources failing too many scans in a row are disabled (state error) until they are re-enabled
+ALTER TABLE sources ADD COLUMN state TEXT NOT NULL DEFAULT 'active';
+ALTER TABLE sources ADD COLUMN consecutive_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sources ADD COLUMN disabled_at DATETIME;
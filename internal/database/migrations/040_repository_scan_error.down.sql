@@ -0,0 +1,2 @@
+ALTER TABLE repositories DROP COLUMN last_scan_error;
+ALTER TABLE repositories DROP COLUMN last_scan_error_at;
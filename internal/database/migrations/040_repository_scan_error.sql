@@ -0,0 +1,3 @@
+-- Error of the last scan of a repository, e.g. a missing scan branch or a manifest that failed to parse
+ALTER TABLE repositories ADD COLUMN last_scan_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE repositories ADD COLUMN last_scan_error_at DATETIME;
@@ -0,0 +1 @@
+ALTER TABLE dependencies DROP COLUMN latest_in_major;
@@ -0,0 +1,2 @@
+-- Newest stable version within the current major version of a dependency, for upgrades without breaking changes
+ALTER TABLE dependencies ADD COLUMN latest_in_major TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,12 @@
+-- Saved views: named filter combinations of the dependency list
+CREATE TABLE IF NOT EXISTS saved_views (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    status TEXT NOT NULL DEFAULT '',
+    repo TEXT NOT NULL DEFAULT '',
+    ecosystem TEXT NOT NULL DEFAULT '',
+    search TEXT NOT NULL DEFAULT '',
+    sort TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
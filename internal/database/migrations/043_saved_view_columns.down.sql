@@ -0,0 +1 @@
+ALTER TABLE saved_views DROP COLUMN columns;
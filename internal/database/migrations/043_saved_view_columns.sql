@@ -0,0 +1,2 @@
+-- Columns of the exports of a saved view, comma-separated in order (empty = default columns)
+ALTER TABLE saved_views ADD COLUMN columns TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+-- Events: log of changes to ignore rules, sources and repositories, with snapshots of deleted
+-- entities so destructive actions can be undone
+CREATE TABLE IF NOT EXISTS events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,
+    entity_id INTEGER NOT NULL,
+    summary TEXT NOT NULL DEFAULT '',
+    payload TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    undone_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_created_at ON events(created_at);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS dependency_history_insert;
+DROP TRIGGER IF EXISTS dependency_history_update;
+DROP TABLE IF EXISTS dependency_history;
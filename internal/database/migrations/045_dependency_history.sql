@@ -0,0 +1,33 @@
+-- Dependency history: the versions a dependency was found at, recorded whenever its current
+-- version changes. Entries are kept after the dependency is removed from its manifest and deleted
+-- with the repository.
+CREATE TABLE IF NOT EXISTS dependency_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    manifest_path TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT '',
+    version TEXT NOT NULL DEFAULT '',
+    latest_version TEXT NOT NULL DEFAULT '',
+    recorded_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_dependency_history_dependency
+    ON dependency_history(repository_id, manifest_path, name, type, recorded_at);
+
+CREATE TRIGGER IF NOT EXISTS dependency_history_insert AFTER INSERT ON dependencies
+BEGIN
+    INSERT INTO dependency_history (repository_id, manifest_path, name, type, version, latest_version)
+    VALUES (NEW.repository_id, NEW.manifest_path, NEW.name, NEW.type, NEW.current_version, COALESCE(NEW.latest_version, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS dependency_history_update AFTER UPDATE OF current_version ON dependencies
+WHEN OLD.current_version IS NOT NEW.current_version
+BEGIN
+    INSERT INTO dependency_history (repository_id, manifest_path, name, type, version, latest_version)
+    VALUES (NEW.repository_id, NEW.manifest_path, NEW.name, NEW.type, NEW.current_version, COALESCE(NEW.latest_version, ''));
+END;
+
+-- Existing dependencies start their history at their last update
+INSERT INTO dependency_history (repository_id, manifest_path, name, type, version, latest_version, recorded_at)
+SELECT repository_id, manifest_path, name, type, current_version, COALESCE(latest_version, ''), updated_at FROM dependencies;
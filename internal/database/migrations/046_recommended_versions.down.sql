@@ -0,0 +1,2 @@
+ALTER TABLE dependencies DROP COLUMN recommended_version;
+DROP TABLE IF EXISTS recommended_versions;
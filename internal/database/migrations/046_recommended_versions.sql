@@ -0,0 +1,14 @@
+-- Recommended versions: versions pinned by admins per package, e.g. an internally approved one.
+-- Dependencies are outdated compared to the recommended version of their package when set.
+CREATE TABLE IF NOT EXISTS recommended_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ecosystem TEXT NOT NULL,
+    name TEXT NOT NULL,
+    version TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(ecosystem, name)
+);
+
+ALTER TABLE dependencies ADD COLUMN recommended_version TEXT NOT NULL DEFAULT '';
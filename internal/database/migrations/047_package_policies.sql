@@ -0,0 +1,11 @@
+-- Package policies: packages approved (allow) or banned (deny) by governance. Names are GLOB
+-- patterns, e.g. @acme/*, and an empty ecosystem applies to every ecosystem.
+CREATE TABLE IF NOT EXISTS package_policies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    list TEXT NOT NULL,
+    ecosystem TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(list, ecosystem, name)
+);
@@ -0,0 +1 @@
+ALTER TABLE scan_jobs DROP COLUMN repos_total;
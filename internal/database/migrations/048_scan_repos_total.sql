@@ -0,0 +1,2 @@
+-- Repositories listed for a scan so far, the denominator of its progress
+ALTER TABLE scan_jobs ADD COLUMN repos_total INTEGER NOT NULL DEFAULT 0;
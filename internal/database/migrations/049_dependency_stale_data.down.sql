@@ -0,0 +1 @@
+ALTER TABLE dependencies DROP COLUMN stale_data;
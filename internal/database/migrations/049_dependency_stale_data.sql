@@ -0,0 +1,2 @@
+-- Set when the latest version is the last known one, kept because the registry could not be reached
+ALTER TABLE dependencies ADD COLUMN stale_data BOOLEAN NOT NULL DEFAULT FALSE;
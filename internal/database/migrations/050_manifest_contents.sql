@@ -0,0 +1,10 @@
+-- Content of the manifests of the primary scan branch as last processed, gzipped when compressed
+CREATE TABLE IF NOT EXISTS manifest_contents (
+    repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    manifest_path TEXT NOT NULL,
+    content BLOB NOT NULL,
+    compressed BOOLEAN NOT NULL DEFAULT FALSE,
+    size INTEGER NOT NULL,
+    stored_at DATETIME NOT NULL,
+    PRIMARY KEY (repository_id, manifest_path)
+);
@@ -0,0 +1,16 @@
+-- Outbox: append-only integration events (dependency became outdated, scan completed,
+-- vulnerability found) polled by downstream systems or relayed to a message broker
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,
+    data TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at ON outbox_events(created_at);
+
+-- Last event relayed by each outbox publisher
+CREATE TABLE IF NOT EXISTS outbox_cursors (
+    name TEXT PRIMARY KEY,
+    last_id INTEGER NOT NULL
+);
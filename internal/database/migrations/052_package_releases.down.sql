@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS package_releases_insert;
+DROP TRIGGER IF EXISTS package_releases_update;
+DROP TABLE IF EXISTS package_releases;
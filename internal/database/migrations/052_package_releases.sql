@@ -0,0 +1,29 @@
+-- Package releases: when each latest version of a package was first seen by a scan, giving the
+-- release cadence of packages in use. Versions are recorded once per package.
+CREATE TABLE IF NOT EXISTS package_releases (
+    ecosystem TEXT NOT NULL,
+    name TEXT NOT NULL,
+    version TEXT NOT NULL,
+    first_seen_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (ecosystem, name, version)
+);
+
+CREATE TRIGGER IF NOT EXISTS package_releases_insert AFTER INSERT ON dependencies
+WHEN COALESCE(NEW.latest_version, '') != ''
+BEGIN
+    INSERT OR IGNORE INTO package_releases (ecosystem, name, version)
+    VALUES (NEW.ecosystem, NEW.name, NEW.latest_version);
+END;
+
+CREATE TRIGGER IF NOT EXISTS package_releases_update AFTER UPDATE OF latest_version ON dependencies
+WHEN COALESCE(NEW.latest_version, '') != '' AND OLD.latest_version IS NOT NEW.latest_version
+BEGIN
+    INSERT OR IGNORE INTO package_releases (ecosystem, name, version)
+    VALUES (NEW.ecosystem, NEW.name, NEW.latest_version);
+END;
+
+-- Latest versions known so far were seen at the last update of their dependencies
+INSERT OR IGNORE INTO package_releases (ecosystem, name, version, first_seen_at)
+SELECT ecosystem, name, latest_version, MIN(updated_at) FROM dependencies
+WHERE COALESCE(latest_version, '') != ''
+GROUP BY ecosystem, name, latest_version;
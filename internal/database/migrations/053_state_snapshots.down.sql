@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS snapshot_outdated;
+DROP TABLE IF EXISTS state_snapshots;
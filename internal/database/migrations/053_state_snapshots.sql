@@ -0,0 +1,24 @@
+-- State snapshots: the outdated dependencies as of each day with a completed scan, archived so
+-- the comparison report can tell what changed over the last 30 and 90 days
+CREATE TABLE IF NOT EXISTS state_snapshots (
+    day TEXT PRIMARY KEY, -- YYYY-MM-DD in the server timezone
+    dependencies INTEGER NOT NULL DEFAULT 0,
+    outdated INTEGER NOT NULL DEFAULT 0,
+    taken_at DATETIME NOT NULL
+);
+
+-- Repositories are archived by name so snapshots outlive them
+CREATE TABLE IF NOT EXISTS snapshot_outdated (
+    day TEXT NOT NULL,
+    repository TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT '',
+    owners TEXT NOT NULL DEFAULT '',
+    manifest_path TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT '',
+    ecosystem TEXT NOT NULL DEFAULT '',
+    current_version TEXT NOT NULL DEFAULT '',
+    latest_version TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_snapshot_outdated_day ON snapshot_outdated(day);
@@ -0,0 +1,5 @@
+-- When a token warning was last notified, per source, so restarts do not repeat the daily email
+CREATE TABLE IF NOT EXISTS token_notifications (
+    source_id INTEGER PRIMARY KEY,
+    notified_at DATETIME NOT NULL
+);
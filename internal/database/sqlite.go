@@ -1,17 +1,12 @@
 package database
 
 import (
-	"embed"
 	"fmt"
-	"strings"
 
 	"github.com/jmoiron/sqlx"
 	_ "modernc.org/sqlite"
 )
 
-//go:embed migrations/*.sql
-var migrations embed.FS
-
 func New(dbPath string) (*sqlx.DB, error) {
 	db, err := sqlx.Connect("sqlite", dbPath+"?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
 	if err != nil {
@@ -24,43 +19,3 @@ func New(dbPath string) (*sqlx.DB, error) {
 
 	return db, nil
 }
-
-func Migrate(db *sqlx.DB) error {
-	// Run all migrations in order
-	migrationFiles := []string{
-		"migrations/001_initial.sql",
-		"migrations/002_add_ecosystem.sql",
-		"migrations/003_add_gitlab.sql",
-		"migrations/004_add_repositories.sql",
-		"migrations/005_add_go_mod.sql",
-		"migrations/006_performance_indexes.sql",
-		"migrations/007_settings.sql",
-		"migrations/008_ignored_dependencies.sql",
-		"migrations/009_add_insecure_skip_verify.sql",
-		"migrations/010_add_membership_only.sql",
-		"migrations/011_add_owner_only.sql",
-	}
-
-	for _, file := range migrationFiles {
-		migrationSQL, err := migrations.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("failed to read migration file %s: %w", file, err)
-		}
-
-		_, err = db.Exec(string(migrationSQL))
-		if err != nil {
-			// Only ignore specific expected errors that occur when re-running migrations
-			errStr := strings.ToLower(err.Error())
-			isExpectedError := strings.Contains(errStr, "already exists") ||
-				strings.Contains(errStr, "duplicate column") ||
-				strings.Contains(errStr, "table") && strings.Contains(errStr, "exists")
-
-			if !isExpectedError {
-				return fmt.Errorf("migration %s failed: %w", file, err)
-			}
-			// Expected error (table/column already exists) - continue to next migration
-		}
-	}
-
-	return nil
-}
@@ -0,0 +1,215 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func setupTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test db: %v", err)
+	}
+	// Each connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+	return db
+}
+
+func TestMigrate_Idempotent(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	// Running again must skip already applied migrations
+	if err := Migrate(db); err != nil {
+		t.Fatalf("second Migrate() error = %v", err)
+	}
+
+	var applied int
+	if err := db.Get(&applied, "SELECT COUNT(*) FROM schema_migrations"); err != nil {
+		t.Fatalf("failed to count migrations: %v", err)
+	}
+	if applied == 0 {
+		t.Error("expected applied migrations to be recorded")
+	}
+}
+
+func TestMigrate_DependencyManifestColumns(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	_, err := db.Exec(`INSERT INTO sources (name, type, token) VALUES ('src', 'github', 'x')`)
+	if err != nil {
+		t.Fatalf("failed to insert source: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO repositories (source_id, name, full_name, html_url) VALUES (1, 'repo', 'org/repo', 'https://example.com')`)
+	if err != nil {
+		t.Fatalf("failed to insert repository: %v", err)
+	}
+
+	// Same package in two manifests of one repository must be allowed
+	for _, path := range []string{"package.json", "web/package.json"} {
+		_, err = db.Exec(`INSERT INTO dependencies (repository_id, name, current_version, type, manifest_path, manifest_type)
+			VALUES (1, 'lodash', '4.17.0', 'dependency', ?, 'package.json')`, path)
+		if err != nil {
+			t.Fatalf("failed to insert dependency for %s: %v", path, err)
+		}
+	}
+}
+
+func TestMigrate_DependencyHistory(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	stmts := []string{
+		`INSERT INTO sources (name, type, token) VALUES ('src', 'github', 'x')`,
+		`INSERT INTO repositories (source_id, name, full_name, html_url) VALUES (1, 'repo', 'org/repo', 'https://example.com')`,
+		`INSERT INTO dependencies (repository_id, name, current_version, type, manifest_path, manifest_type)
+			VALUES (1, 'lodash', '4.17.0', 'dependency', 'package.json', 'package.json')`,
+		// Unchanged version is not recorded again
+		`UPDATE dependencies SET current_version = '4.17.0', latest_version = '4.17.21'`,
+		`UPDATE dependencies SET current_version = '4.17.21'`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	var versions []string
+	if err := db.Select(&versions, "SELECT version FROM dependency_history ORDER BY id"); err != nil {
+		t.Fatalf("failed to read history: %v", err)
+	}
+	if len(versions) != 2 || versions[0] != "4.17.0" || versions[1] != "4.17.21" {
+		t.Errorf("history = %v, want [4.17.0 4.17.21]", versions)
+	}
+}
+
+func TestMigrations_Versioned(t *testing.T) {
+	list, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations() error = %v", err)
+	}
+	for i, m := range list {
+		if m.Version != i+1 {
+			t.Fatalf("migration %s has version %d, want %d", m.Name, m.Version, i+1)
+		}
+		if strings.HasSuffix(m.Name, ".down.sql") {
+			t.Errorf("down migration %s listed as a migration", m.Name)
+		}
+	}
+	// Migrations before 013 predate down migrations
+	for _, m := range list[12:] {
+		if !m.Reversible() {
+			t.Errorf("migration %s has no down migration", m.Name)
+		}
+	}
+}
+
+func TestApply_FailedMigrationNotRecorded(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	if err := ensureMigrationTable(db); err != nil {
+		t.Fatal(err)
+	}
+
+	m := Migration{Version: 1, Name: "migrations/001_test.sql", Up: `-- Test tables
+CREATE TABLE first (id INTEGER);
+CREATE TABLE first (id INTEGER);
+`}
+	if err := apply(db, m); err == nil {
+		t.Fatal("apply() error = nil, want the failure of the second statement")
+	}
+	var count int
+	db.Get(&count, "SELECT COUNT(*) FROM schema_migrations")
+	if count != 0 {
+		t.Error("failed migration recorded as applied")
+	}
+	db.Get(&count, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'first'")
+	if count != 0 {
+		t.Error("changes of the failed migration kept")
+	}
+
+	// A database created before migrations were tracked already has the first table
+	if _, err := db.Exec("CREATE TABLE first (id INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := apply(db, m); err != nil {
+		t.Fatalf("apply() on an untracked database error = %v", err)
+	}
+	db.Get(&count, "SELECT COUNT(*) FROM schema_migrations")
+	if count != 1 {
+		t.Error("migration applied before tracking not recorded")
+	}
+}
+
+func TestSplitFirstStatement(t *testing.T) {
+	first, rest := splitFirstStatement("-- Comment\n\nCREATE TABLE a (\n    id INTEGER\n);\nCREATE TABLE b (id INTEGER);\n")
+	if first != "CREATE TABLE a (\n    id INTEGER\n);\n" {
+		t.Errorf("first = %q", first)
+	}
+	if rest != "CREATE TABLE b (id INTEGER);\n" {
+		t.Errorf("rest = %q", rest)
+	}
+}
+
+func TestMigrate_StatusAndRollback(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	pending, err := Pending(db)
+	if err != nil {
+		t.Fatalf("Pending() error = %v", err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	latest, err := Version(db)
+	if err != nil || latest != pending[len(pending)-1].Version {
+		t.Fatalf("Version() = %d, %v, want %d", latest, err, pending[len(pending)-1].Version)
+	}
+	if left, _ := Pending(db); len(left) != 0 {
+		t.Fatalf("Pending() after Migrate() = %d migrations, want none", len(left))
+	}
+
+	// Roll back every reversible migration; the first irreversible one stops the rollback
+	reverted, err := Rollback(db, len(pending))
+	if err == nil || !strings.Contains(err.Error(), "012_add_scan_branch.sql cannot be rolled back") || len(reverted) == 0 {
+		t.Fatalf("Rollback() = %d reverted, %v, want reverted migrations down to 012", len(reverted), err)
+	}
+	if reverted[0].Version != latest {
+		t.Errorf("first reverted = %d, want the latest %d", reverted[0].Version, latest)
+	}
+	version, _ := Version(db)
+	if version != latest-len(reverted) {
+		t.Errorf("Version() after rollback = %d, want %d", version, latest-len(reverted))
+	}
+	var tables int
+	db.Get(&tables, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'package_policies'")
+	if tables != 0 {
+		t.Error("package_policies still exists after rollback")
+	}
+
+	// Reverted migrations apply again
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate() after rollback error = %v", err)
+	}
+	if version, _ := Version(db); version != latest {
+		t.Errorf("Version() after migrating again = %d, want %d", version, latest)
+	}
+	if _, err := db.Exec(`INSERT INTO package_policies (list, name) VALUES ('deny', 'left-pad')`); err != nil {
+		t.Errorf("package_policies not recreated: %v", err)
+	}
+}
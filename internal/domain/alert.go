@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// VulnerabilityAlert is an open vulnerability alert imported from the provider (GitHub Dependabot)
+type VulnerabilityAlert struct {
+	ID              int64     `db:"id" json:"id"`
+	RepositoryID    int64     `db:"repository_id" json:"repository_id"`
+	AlertNumber     int       `db:"alert_number" json:"alert_number"`
+	Ecosystem       string    `db:"ecosystem" json:"ecosystem"`
+	PackageName     string    `db:"package_name" json:"package_name"`
+	ManifestPath    string    `db:"manifest_path" json:"manifest_path"`
+	Severity        string    `db:"severity" json:"severity"` // low, medium, high, critical
+	GHSAID          string    `db:"ghsa_id" json:"ghsa_id"`
+	CVEID           string    `db:"cve_id" json:"cve_id,omitempty"`
+	Summary         string    `db:"summary" json:"summary"`
+	VulnerableRange string    `db:"vulnerable_range" json:"vulnerable_range"`
+	PatchedVersion  string    `db:"patched_version" json:"patched_version,omitempty"`
+	HTMLURL         string    `db:"html_url" json:"html_url"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// VulnerabilityAlertWithDependency merges an alert with the repository and the
+// matching dependency tracked by stale (versions are empty when none matches)
+type VulnerabilityAlertWithDependency struct {
+	VulnerabilityAlert
+	RepoFullName   string `db:"repo_full_name" json:"repo_full_name"`
+	CurrentVersion string `db:"current_version" json:"current_version"`
+	LatestVersion  string `db:"latest_version" json:"latest_version"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// Campaign tracks the upgrade of a package to a target version across all repositories
+type Campaign struct {
+	ID            int64      `db:"id" json:"id"`
+	Name          string     `db:"name" json:"name"`
+	Description   string     `db:"description" json:"description"`
+	Ecosystem     string     `db:"ecosystem" json:"ecosystem"`
+	PackageName   string     `db:"package_name" json:"package_name"`
+	TargetVersion string     `db:"target_version" json:"target_version"`
+	DueDate       *time.Time `db:"due_date" json:"due_date,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+type CampaignInput struct {
+	Name          string     `json:"name"`
+	Description   string     `json:"description"`
+	Ecosystem     string     `json:"ecosystem"`
+	PackageName   string     `json:"package_name"`
+	TargetVersion string     `json:"target_version"`
+	DueDate       *time.Time `json:"due_date,omitempty"`
+}
+
+// CampaignProgress is the state of a campaign derived from the latest scan data
+type CampaignProgress struct {
+	Campaign
+	TotalRepositories int                  `json:"total_repositories"`
+	Completed         int                  `json:"completed"`
+	Pending           int                  `json:"pending"`
+	Unknown           int                  `json:"unknown"`
+	ProgressPercent   float64              `json:"progress_percent"`
+	Repositories      []CampaignRepository `json:"repositories,omitempty"`
+}
+
+// CampaignRepository is the campaign status of a repository using the package
+type CampaignRepository struct {
+	RepositoryID int64    `json:"repository_id"`
+	RepoFullName string   `json:"repo_full_name"`
+	Versions     []string `json:"versions"` // distinct declared versions across manifests
+	Status       string   `json:"status"`   // completed, pending or unknown
+}
@@ -0,0 +1,42 @@
+package domain
+
+// ConfigDocument declaratively describes the configuration of an instance so it can be managed
+// as code. Sources are identified by name and ignore rules by name, ecosystem and repository.
+// Email delivery (SMTP server and recipients) and the other settings are not part of it.
+type ConfigDocument struct {
+	Sources       []SourceInput            `json:"sources"`
+	Schedule      *ScheduleConfig          `json:"schedule,omitempty"` // left unchanged when omitted
+	Ignores       []IgnoredDependencyInput `json:"ignores"`
+	Notifications *NotificationsConfig     `json:"notifications,omitempty"` // left unchanged when omitted
+}
+
+// ScheduleConfig is the automatic scan schedule
+type ScheduleConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Cron     string `json:"cron"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// NotificationsConfig is how failing source scans are notified
+type NotificationsConfig struct {
+	FailureNotifyEmail bool   `json:"failure_notify_email"`
+	FailureWebhookURL  string `json:"failure_webhook_url,omitempty"` // exported masked; the masked value keeps the stored URL
+	FailureNotifyHours int    `json:"failure_notify_hours"`
+}
+
+// ConfigApplyResult reports the changes made (or planned, for a dry run) by applying a ConfigDocument
+type ConfigApplyResult struct {
+	DryRun               bool          `json:"dry_run"`
+	Sources              ConfigChanges `json:"sources"`
+	Ignores              ConfigChanges `json:"ignores"`
+	ScheduleChanged      bool          `json:"schedule_changed"`
+	NotificationsChanged bool          `json:"notifications_changed"`
+}
+
+// ConfigChanges lists the names of created, updated and deleted items
+type ConfigChanges struct {
+	Created   []string `json:"created"`
+	Updated   []string `json:"updated"`
+	Deleted   []string `json:"deleted"`
+	Unchanged int      `json:"unchanged"`
+}
@@ -2,31 +2,125 @@ package domain
 
 import "time"
 
+// Dependency types. Production and development dependencies are found in every ecosystem; the
+// others are npm specific.
+const (
+	DependencyTypeProd     = "dependency"
+	DependencyTypeDev      = "devDependency"
+	DependencyTypePeer     = "peerDependency"     // expected to be provided by the package's consumer
+	DependencyTypeOptional = "optionalDependency" // installation failures are tolerated
+	DependencyTypeBundled  = "bundledDependency"  // shipped inside the published package
+)
+
+// DependencyTypes are the known dependency types
+var DependencyTypes = []string{
+	DependencyTypeProd, DependencyTypeDev, DependencyTypePeer, DependencyTypeOptional, DependencyTypeBundled,
+}
+
+// DependencyScopes are the known dependency scopes, those of Maven
+var DependencyScopes = []string{"compile", "provided", "runtime", "test", "system", "import"}
+
 type Dependency struct {
-	ID                 int64     `db:"id" json:"id"`
-	RepositoryID       int64     `db:"repository_id" json:"repository_id"`
-	Name               string    `db:"name" json:"name"`
-	CurrentVersion     string    `db:"current_version" json:"current_version"`
-	LatestVersion      string    `db:"latest_version" json:"latest_version"`
-	Type               string    `db:"type" json:"type"`
-	Ecosystem          string    `db:"ecosystem" json:"ecosystem"` // npm, maven, gradle
-	IsOutdated         bool      `db:"is_outdated" json:"is_outdated"`
-	PreviouslyOutdated bool      `db:"previously_outdated" json:"-"`
-	UpdatedAt          time.Time `db:"updated_at" json:"updated_at"`
+	ID                 int64      `db:"id" json:"id"`
+	RepositoryID       int64      `db:"repository_id" json:"repository_id"`
+	Name               string     `db:"name" json:"name"`
+	CurrentVersion     string     `db:"current_version" json:"current_version"`
+	LatestVersion      string     `db:"latest_version" json:"latest_version"`
+	LatestInMajor      string     `db:"latest_in_major" json:"latest_in_major,omitempty"`         // Newest stable version within the current major version
+	RecommendedVersion string     `db:"recommended_version" json:"recommended_version,omitempty"` // Version pinned for the package, compared instead of the latest one
+	Type               string     `db:"type" json:"type"`
+	Scope              string     `db:"scope" json:"scope,omitempty"` // Maven scope, e.g. compile, provided, runtime, test or import
+	Ecosystem          string     `db:"ecosystem" json:"ecosystem"`   // npm, maven, gradle
+	Risks              string     `db:"risks" json:"risks,omitempty"` // Comma-separated supply-chain risks, e.g. install_script,typosquat
+	RiskDetail         string     `db:"risk_detail" json:"risk_detail,omitempty"`
+	IsOutdated         bool       `db:"is_outdated" json:"is_outdated"`
+	StaleData          bool       `db:"stale_data" json:"stale_data,omitempty"` // The latest version is the last known one, as the registry could not be reached
+	PreviouslyOutdated bool       `db:"previously_outdated" json:"-"`
+	ManifestPath       string     `db:"manifest_path" json:"manifest_path"`                   // e.g. services/api/package.json
+	ManifestType       string     `db:"manifest_type" json:"manifest_type"`                   // package.json, pom.xml, build.gradle, go.mod
+	Branch             string     `db:"branch" json:"branch,omitempty"`                       // Set for dependencies of additional branches
+	FirstOutdatedAt    *time.Time `db:"first_outdated_at" json:"first_outdated_at,omitempty"` // Start of the current outdated period
+	UpdatedAt          time.Time  `db:"updated_at" json:"updated_at"`
 }
 
 type DependencyWithRepo struct {
 	Dependency
 	RepoName     string `db:"repo_name" json:"repo_name"`
 	RepoFullName string `db:"repo_full_name" json:"repo_full_name"`
+	RepoOwners   string `db:"repo_owners" json:"repo_owners,omitempty"`
 	SourceName   string `db:"source_name" json:"source_name"`
 }
 
+// DependencyStats summarizes the dependencies. Totals count occurrences (a package used by ten
+// repositories counts ten times); the Distinct counts count each package once.
 type DependencyStats struct {
-	TotalDependencies int            `json:"total_dependencies"`
-	OutdatedCount     int            `json:"outdated_count"`
-	UpToDateCount     int            `json:"up_to_date_count"`
-	ByType            map[string]int `json:"by_type"`
+	TotalDependencies        int                    `json:"total_dependencies"`
+	OutdatedCount            int                    `json:"outdated_count"`
+	UpToDateCount            int                    `json:"up_to_date_count"`
+	DistinctPackages         int                    `json:"distinct_packages"`
+	DistinctOutdatedPackages int                    `json:"distinct_outdated_packages"`
+	ByType                   map[string]int         `json:"by_type"`
+	ByScope                  map[string]int         `json:"by_scope"` // Dependencies declared with a scope, e.g. Maven ones
+	ByEcosystem              []StatsBreakdown       `json:"by_ecosystem"`
+	BySource                 []StatsBreakdown       `json:"by_source"`
+	TopRepositories          []StatsBreakdown       `json:"top_repositories"`   // Repositories with the most outdated dependencies
+	MostUsedOutdated         []OutdatedPackageUsage `json:"most_used_outdated"` // Outdated packages used by the most repositories
+	SLABreaches              SLABreachStats         `json:"sla_breaches"`
+	PolicyViolations         PolicyViolationStats   `json:"policy_violations"`         // Counted apart from staleness
+	ExcludedTypes            []string               `json:"excluded_types,omitempty"`  // Types left out of every count but ByType
+	ExcludedScopes           []string               `json:"excluded_scopes,omitempty"` // Scopes left out of every count but ByScope
+}
+
+// StatsExclusions are the dependencies left out of the staleness stats
+type StatsExclusions struct {
+	Types  []string // e.g. peerDependency
+	Scopes []string // e.g. provided
+}
+
+// OutdatedPackageUsage reports how widely an outdated package is used
+type OutdatedPackageUsage struct {
+	Name          string `db:"name" json:"name"`
+	Ecosystem     string `db:"ecosystem" json:"ecosystem"`
+	LatestVersion string `db:"latest_version" json:"latest_version"`
+	Repositories  int    `db:"repositories" json:"repositories"` // Repositories using an outdated version
+	Occurrences   int    `db:"occurrences" json:"occurrences"`   // Outdated occurrences, e.g. across manifests of a monorepo
+}
+
+// StatsBreakdown counts the dependencies of one group, e.g. an ecosystem or a repository
+type StatsBreakdown struct {
+	Name     string `db:"name" json:"name"`
+	Total    int    `db:"total" json:"total"`
+	Outdated int    `db:"outdated" json:"outdated"`
+}
+
+// SLABreachStats counts the outdated dependencies that have been outdated for longer than
+// the SLA configured for their upgrade type
+type SLABreachStats struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+	Total int `json:"total"`
+}
+
+// Groupings of the grouped dependency view
+const (
+	GroupByPackage   = "package"
+	GroupByRepo      = "repo"
+	GroupByEcosystem = "ecosystem"
+)
+
+// DependencyGroup aggregates the dependencies of a package, repository or ecosystem. Versions
+// are only set for packages, since versions of different packages cannot be compared.
+type DependencyGroup struct {
+	Name          string `db:"name" json:"name"`                     // Package name, repository full name or ecosystem
+	Ecosystem     string `db:"ecosystem" json:"ecosystem,omitempty"` // Set when grouped by package
+	Dependencies  int    `db:"dependencies" json:"dependencies"`     // Occurrences across manifests
+	Outdated      int    `db:"outdated" json:"outdated"`
+	Repositories  int    `db:"repositories" json:"repositories"`
+	Packages      int    `db:"packages" json:"packages"`
+	MinVersion    string `db:"-" json:"min_version,omitempty"`    // Oldest version in use
+	MaxVersion    string `db:"-" json:"max_version,omitempty"`    // Newest version in use
+	LatestVersion string `db:"-" json:"latest_version,omitempty"` // Latest version in the registry
 }
 
 type PaginatedDependencies struct {
@@ -36,3 +130,212 @@ type PaginatedDependencies struct {
 	Limit      int                  `json:"limit"`
 	TotalPages int                  `json:"total_pages"`
 }
+
+// ManifestSummary describes a manifest file found in a repository
+// along with the dependencies parsed from it
+type ManifestSummary struct {
+	Path            string `db:"manifest_path" json:"path"`
+	Type            string `db:"manifest_type" json:"type"`
+	Ecosystem       string `db:"ecosystem" json:"ecosystem"`
+	DependencyCount int    `db:"dependency_count" json:"dependency_count"`
+	OutdatedCount   int    `db:"outdated_count" json:"outdated_count"`
+}
+
+// ManifestHash is the content hash of a manifest as of the last time its dependencies were
+// fully processed
+type ManifestHash struct {
+	RepositoryID int64     `db:"repository_id" json:"repository_id"`
+	ManifestPath string    `db:"manifest_path" json:"manifest_path"`
+	ContentHash  string    `db:"content_hash" json:"content_hash"`
+	HashedAt     time.Time `db:"hashed_at" json:"hashed_at"`
+}
+
+// ManifestContent is the content of a manifest as processed by the last scan that parsed it
+type ManifestContent struct {
+	RepositoryID int64     `db:"repository_id" json:"repository_id"`
+	ManifestPath string    `db:"manifest_path" json:"manifest_path"`
+	Content      []byte    `db:"content" json:"-"` // uncompressed
+	Compressed   bool      `db:"compressed" json:"compressed"`
+	Size         int64     `db:"size" json:"size"` // uncompressed size in bytes
+	StoredAt     time.Time `db:"stored_at" json:"stored_at"`
+}
+
+// Supply-chain risks of a dependency
+const (
+	RiskInstallScript = "install_script" // the installed version runs a preinstall, install or postinstall script
+	RiskTyposquat     = "typosquat"      // the name is a few edits away from a popular package
+)
+
+// Reasons a dependency was skipped or could not be checked
+const (
+	SkipReasonUnresolvedProperty = "unresolved_property" // version is a property reference such as ${spring.version}
+	SkipReasonInvalidVersion     = "invalid_version"     // version is missing or not a semantic version
+	SkipReasonRegistryError      = "registry_error"      // latest version lookup failed
+)
+
+// SkippedDependency is a dependency of a repository's primary branch that was left out of the scan
+// or whose outdated status is unknown
+type SkippedDependency struct {
+	ID           int64     `db:"id" json:"id"`
+	RepositoryID int64     `db:"repository_id" json:"repository_id"`
+	Name         string    `db:"name" json:"name"`
+	Ecosystem    string    `db:"ecosystem" json:"ecosystem"`
+	Version      string    `db:"version" json:"version"`
+	ManifestPath string    `db:"manifest_path" json:"manifest_path"`
+	Reason       string    `db:"reason" json:"reason"`
+	Detail       string    `db:"detail" json:"detail,omitempty"`
+	DetectedAt   time.Time `db:"detected_at" json:"detected_at"`
+}
+
+// BranchSummary describes the dependency set of an additional branch of a repository
+type BranchSummary struct {
+	Branch          string `db:"branch" json:"branch"`
+	DependencyCount int    `db:"dependency_count" json:"dependency_count"`
+	OutdatedCount   int    `db:"outdated_count" json:"outdated_count"`
+}
+
+// DependencyDiff is the difference between the dependency sets of two refs of a repository
+type DependencyDiff struct {
+	Base      string             `json:"base"`
+	Head      string             `json:"head"`
+	Added     []DependencyChange `json:"added"`
+	Removed   []DependencyChange `json:"removed"`
+	Changed   []DependencyChange `json:"changed"`
+	Unchanged int                `json:"unchanged"`
+}
+
+// DependencyChange is a dependency that was added, removed or changed between two refs.
+// BaseVersion is empty for added dependencies and HeadVersion is empty for removed ones.
+type DependencyChange struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Ecosystem    string `json:"ecosystem"`
+	ManifestPath string `json:"manifest_path"`
+	BaseVersion  string `json:"base_version,omitempty"`
+	HeadVersion  string `json:"head_version,omitempty"`
+}
+
+// PackageImpact lists the usages of a package that need a change to reach a target version,
+// grouped by the size of the version jump
+type PackageImpact struct {
+	Ecosystem         string         `json:"ecosystem"`
+	Name              string         `json:"name"`
+	Target            string         `json:"target"`
+	TotalRepositories int            `json:"total_repositories"` // repositories needing a change
+	Major             []PackageUsage `json:"major"`
+	Minor             []PackageUsage `json:"minor"`
+	Patch             []PackageUsage `json:"patch"`
+	UpToDate          []PackageUsage `json:"up_to_date"` // already at or beyond the target
+	Unknown           []PackageUsage `json:"unknown"`    // current version could not be compared
+}
+
+// PackageUsage is a declaration of a package in a repository manifest
+type PackageUsage struct {
+	RepositoryID   int64  `json:"repository_id"`
+	RepoFullName   string `json:"repo_full_name"`
+	ManifestPath   string `json:"manifest_path"`
+	Type           string `json:"type"`
+	CurrentVersion string `json:"current_version"`
+}
+
+// PackageMaintainers are the maintainers a registry lists for a package, along with the number of
+// repositories depending on it
+type PackageMaintainers struct {
+	Ecosystem    string    `db:"ecosystem" json:"ecosystem"`
+	Name         string    `db:"name" json:"name"`
+	Maintainers  string    `db:"maintainers" json:"maintainers"` // Comma-separated, e.g. alice,bob
+	Count        int       `db:"maintainer_count" json:"maintainer_count"`
+	Repositories int       `db:"repositories" json:"repositories"`
+	FetchedAt    time.Time `db:"fetched_at" json:"fetched_at"`
+}
+
+// DependencyVersion is a version a dependency was found at, recorded when its current version changed
+type DependencyVersion struct {
+	Version       string    `db:"version" json:"version"`
+	LatestVersion string    `db:"latest_version" json:"latest_version"` // Latest version known at the time
+	RecordedAt    time.Time `db:"recorded_at" json:"recorded_at"`
+}
+
+// DependencyDetail is a dependency with its version history, oldest first, and the ignore rules
+// and vulnerability alerts of its package in the repository
+type DependencyDetail struct {
+	DependencyWithRepo
+	History []DependencyVersion                `json:"history"`
+	Ignores []IgnoredDependency                `json:"ignores"`
+	Alerts  []VulnerabilityAlertWithDependency `json:"alerts"`
+}
+
+// PackageConvergence reports the spread of versions of a package across repositories. Versions
+// are compared without range prefixes, so ^1.2.3 and 1.2.3 count as the same version.
+type PackageConvergence struct {
+	Ecosystem        string         `json:"ecosystem"`
+	Name             string         `json:"name"`
+	Repositories     int            `json:"repositories"`
+	Usages           int            `json:"usages"` // declarations across manifests
+	DistinctVersions int            `json:"distinct_versions"`
+	MinVersion       string         `json:"min_version,omitempty"` // empty when no version can be parsed
+	MaxVersion       string         `json:"max_version,omitempty"`
+	ModeVersion      string         `json:"mode_version"` // most used version, the newest on a tie
+	Score            float64        `json:"score"`        // share of usages at the mode version, 1 when converged
+	Versions         []VersionUsage `json:"versions"`     // newest first, unparsable versions last
+}
+
+// VersionUsage counts the usages of a version of a package
+type VersionUsage struct {
+	Version      string `json:"version"`
+	Usages       int    `json:"usages"`
+	Repositories int    `json:"repositories"`
+}
+
+// PackageRelease is a version of a package with when a scan first found it as the latest version
+type PackageRelease struct {
+	Ecosystem   string    `db:"ecosystem" json:"ecosystem"`
+	Name        string    `db:"name" json:"name"`
+	Version     string    `db:"version" json:"version"`
+	FirstSeenAt time.Time `db:"first_seen_at" json:"first_seen_at"`
+}
+
+// FreshnessForecast estimates when packages in use release next and which dependencies will fall
+// at least Majors major versions behind within Months months if they are not upgraded
+type FreshnessForecast struct {
+	Months   int                  `json:"months"`
+	Majors   int                  `json:"majors"`
+	Horizon  time.Time            `json:"horizon"`
+	Packages []PackageForecast    `json:"packages"` // packages with a known release cadence
+	AtRisk   []DependencyForecast `json:"at_risk"`  // most major versions behind at the horizon first
+}
+
+// PackageForecast is the release cadence of a package and its likely next release window
+type PackageForecast struct {
+	Ecosystem        string         `json:"ecosystem"`
+	Name             string         `json:"name"`
+	ReleasesObserved int            `json:"releases_observed"`
+	LastReleaseAt    time.Time      `json:"last_release_at"`
+	CadenceDays      float64        `json:"cadence_days"`                 // median time between recent releases
+	MajorCadenceDays float64        `json:"major_cadence_days,omitempty"` // median time between major releases, 0 when unknown
+	NextRelease      *ReleaseWindow `json:"next_release,omitempty"`
+}
+
+// ReleaseWindow is when the next release of a package is likely, between the shortest and the
+// longest recent gap between releases after the last one
+type ReleaseWindow struct {
+	From     time.Time `json:"from"`
+	Expected time.Time `json:"expected"` // after the median gap
+	To       time.Time `json:"to"`
+	Overdue  bool      `json:"overdue"` // the window passed without a new release
+}
+
+// DependencyForecast is how many major versions a dependency is behind now and is projected to be
+// behind at the horizon of a forecast
+type DependencyForecast struct {
+	RepositoryID          int64          `json:"repository_id"`
+	Repository            string         `json:"repository"`
+	Name                  string         `json:"name"`
+	Ecosystem             string         `json:"ecosystem"`
+	ManifestPath          string         `json:"manifest_path"`
+	CurrentVersion        string         `json:"current_version"`
+	LatestVersion         string         `json:"latest_version"`
+	MajorsBehind          int            `json:"majors_behind"`
+	ProjectedMajorsBehind int            `json:"projected_majors_behind"`
+	NextRelease           *ReleaseWindow `json:"next_release,omitempty"`
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// Types of domain change events
+const (
+	EventIgnoreCreated     = "ignore.created"
+	EventIgnoreDeleted     = "ignore.deleted"
+	EventSourceCreated     = "source.created"
+	EventSourceDeleted     = "source.deleted"
+	EventRepositoryDeleted = "repository.deleted"
+)
+
+// EventUndoWindow is how long a destructive action can be undone
+const EventUndoWindow = time.Hour
+
+// undoableEvents are the event types whose change can be reverted
+var undoableEvents = map[string]bool{
+	EventIgnoreDeleted:     true,
+	EventSourceDeleted:     true,
+	EventRepositoryDeleted: true,
+}
+
+// Event records a change of ignore rules, sources or repositories. Events of destructive actions
+// keep a snapshot of the deleted entity so the action can be undone within EventUndoWindow.
+type Event struct {
+	ID        int64      `db:"id" json:"id"`
+	Type      string     `db:"type" json:"type"`
+	EntityID  int64      `db:"entity_id" json:"entity_id"`
+	Summary   string     `db:"summary" json:"summary"` // e.g. the name of the deleted source
+	Payload   string     `db:"payload" json:"-"`       // JSON snapshot of the deleted entity
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UndoneAt  *time.Time `db:"undone_at" json:"undone_at,omitempty"`
+	Undoable  bool       `db:"-" json:"undoable"`
+}
+
+// CanUndo reports whether the change of the event can still be reverted at now
+func (e *Event) CanUndo(now time.Time) bool {
+	return undoableEvents[e.Type] && e.UndoneAt == nil && now.Sub(e.CreatedAt) < EventUndoWindow
+}
+
+// DeletedRepository is the snapshot of a deleted repository and its dependencies
+type DeletedRepository struct {
+	Repository   Repository   `json:"repository"`
+	Dependencies []Dependency `json:"dependencies"`
+}
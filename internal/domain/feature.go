@@ -0,0 +1,17 @@
+package domain
+
+// Where the state of a feature flag comes from, in order of precedence
+const (
+	FeatureSourceEnvironment = "environment" // STALE_FEATURE_<NAME> environment variable
+	FeatureSourceDatabase    = "database"    // set through the API
+	FeatureSourceDefault     = "default"
+)
+
+// FeatureFlag is the state of a feature flag on this instance
+type FeatureFlag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Default     bool   `json:"default"`
+	Source      string `json:"source"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+// DependencyGraph connects repositories with the packages they depend on
+type DependencyGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a repository or a package
+type GraphNode struct {
+	ID        string `json:"id"`
+	Label     string `json:"label"`
+	Kind      string `json:"kind"` // repository or package
+	Ecosystem string `json:"ecosystem,omitempty"`
+	RepoCount int    `json:"repo_count,omitempty"` // packages only: number of repositories using it
+}
+
+// GraphEdge links a repository (source) to a package (target) it depends on
+type GraphEdge struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Version  string `json:"version"`
+	Outdated bool   `json:"outdated"`
+}
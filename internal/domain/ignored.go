@@ -2,16 +2,55 @@ package domain
 
 import "time"
 
+// IgnoreExpiryReminderDays is how far ahead reminders list ignore rules about to expire
+const IgnoreExpiryReminderDays = 7
+
 type IgnoredDependency struct {
-	ID        int64     `db:"id" json:"id"`
-	Name      string    `db:"name" json:"name"`
-	Ecosystem string    `db:"ecosystem" json:"ecosystem,omitempty"`
-	Reason    string    `db:"reason" json:"reason,omitempty"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ID         int64      `db:"id" json:"id"`
+	Name       string     `db:"name" json:"name"`
+	Ecosystem  string     `db:"ecosystem" json:"ecosystem,omitempty"`
+	Reason     string     `db:"reason" json:"reason,omitempty"`
+	Repository string     `db:"repository" json:"repository,omitempty"` // Full name of the only repository the rule applies to (empty for all)
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at,omitempty"` // The rule is removed once it expires
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
 }
 
 type IgnoredDependencyInput struct {
+	Name       string     `json:"name"`
+	Ecosystem  string     `json:"ecosystem,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	Repository string     `json:"repository,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// IgnoreImportResult reports the outcome of an ignore list import
+type IgnoreImportResult struct {
+	DryRun  bool              `json:"dry_run"`
+	Created int               `json:"created"` // rules created (or valid, for a dry run)
+	Skipped int               `json:"skipped"`
+	Failed  int               `json:"failed"`
+	Rows    []IgnoreImportRow `json:"rows"`
+}
+
+// IgnoreImportRow is the outcome of a single row of an ignore list import
+type IgnoreImportRow struct {
+	Row       int    `json:"row"` // 1-based, excluding the CSV header
 	Name      string `json:"name"`
-	Ecosystem string `json:"ecosystem,omitempty"`
-	Reason    string `json:"reason,omitempty"`
+	Status    string `json:"status"` // created, valid (dry run), skipped, invalid or failed
+	IgnoredID int64  `json:"ignored_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// IgnoreSuggestion is a dependency excluded from updates by the Renovate or Dependabot config of a
+// repository, offered for import as an ignore rule scoped to that repository
+type IgnoreSuggestion struct {
+	ID           int64     `db:"id" json:"id"`
+	RepositoryID int64     `db:"repository_id" json:"repository_id"`
+	RepoFullName string    `db:"repo_full_name" json:"repo_full_name"`
+	Name         string    `db:"name" json:"name"`
+	Ecosystem    string    `db:"ecosystem" json:"ecosystem,omitempty"`
+	ConfigPath   string    `db:"config_path" json:"config_path"` // e.g. renovate.json or .github/dependabot.yml
+	Detail       string    `db:"detail" json:"detail,omitempty"` // the setting that excludes it, e.g. ignoreDeps
+	Dismissed    bool      `db:"dismissed" json:"dismissed"`     // dismissed suggestions are not offered again
+	DetectedAt   time.Time `db:"detected_at" json:"detected_at"`
 }
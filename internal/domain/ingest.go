@@ -0,0 +1,53 @@
+package domain
+
+// IngestRequest is a dependency list of a repository pushed by an external scanner, e.g. a CI job
+// in a network stale cannot reach. It replaces the dependencies previously recorded for the
+// repository.
+type IngestRequest struct {
+	Source       string             `json:"source"`     // name of the external source, "external" when empty
+	Repository   string             `json:"repository"` // full name, e.g. owner/repo
+	HTMLURL      string             `json:"html_url"`
+	Branch       string             `json:"branch"`
+	Dependencies []IngestDependency `json:"dependencies"`
+}
+
+// IngestDependency is a dependency of an ingested repository
+type IngestDependency struct {
+	Name          string `json:"name"`
+	Version       string `json:"version"`
+	LatestVersion string `json:"latest_version"` // looked up by the reporter, e.g. an agent; looked up on ingestion when empty
+	Ecosystem     string `json:"ecosystem"`
+	Type          string `json:"type"` // dependency when empty
+	Scope         string `json:"scope"`
+	ManifestPath  string `json:"manifest_path"` // path of the manifest the dependency is declared in
+	ManifestType  string `json:"manifest_type"`
+}
+
+// IngestResult is the outcome of an ingested dependency list
+type IngestResult struct {
+	Source        Source     `json:"source"`
+	Repository    Repository `json:"repository"`
+	Dependencies  int        `json:"dependencies"`
+	OutdatedCount int        `json:"outdated_count"`
+	Removed       int64      `json:"removed"`           // dependencies no longer in the list
+	Skipped       int        `json:"skipped,omitempty"` // SBOM components without a package URL of a supported ecosystem, or listed again
+}
+
+// IngestBatchRequest is the dependency lists of several repositories pushed at once, e.g. by an
+// agent, so that a source with many repositories counts once against the ingest rate limit
+type IngestBatchRequest struct {
+	Repositories []IngestRequest `json:"repositories"`
+}
+
+// IngestBatchItem is the outcome of a repository of a batch. Error is set when the list of the
+// repository was rejected; the other repositories of the batch are recorded regardless.
+type IngestBatchItem struct {
+	Repository string        `json:"repository"`
+	Result     *IngestResult `json:"result,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// IngestBatchResult is the outcome of an ingested batch, in the order of the request
+type IngestBatchResult struct {
+	Repositories []IngestBatchItem `json:"repositories"`
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Types of integration events appended to the outbox
+const (
+	OutboxDependencyOutdated = "dependency.outdated" // data: DependencyWithRepo
+	OutboxScanCompleted      = "scan.completed"      // data: ScanJob, completed or failed
+	OutboxVulnerabilityFound = "vulnerability.found" // data: FoundVulnerability
+)
+
+// OutboxEvent is an integration event for downstream systems. Events are never changed once
+// appended and are read in ID order.
+type OutboxEvent struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// NewOutboxEvent returns an event of the given type with data encoded as JSON
+func NewOutboxEvent(eventType string, data any) (OutboxEvent, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+	return OutboxEvent{Type: eventType, Data: encoded}, nil
+}
+
+// OutboxPage is a page of outbox events after a cursor. Next is the cursor of the following page.
+type OutboxPage struct {
+	Events []OutboxEvent `json:"events"`
+	Next   int64         `json:"next"`
+}
+
+// FoundVulnerability is a vulnerability alert first imported during a scan
+type FoundVulnerability struct {
+	VulnerabilityAlert
+	Repository string `json:"repository"` // full name
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// Package policy lists
+const (
+	PolicyAllow = "allow" // once an ecosystem has an allowlist, its other packages are violations
+	PolicyDeny  = "deny"  // packages that must not be used
+)
+
+// Policy violations
+const (
+	ViolationDenied     = "denied"      // the package is on the denylist
+	ViolationNotAllowed = "not_allowed" // the ecosystem has an allowlist the package is not on
+)
+
+// PackagePolicy puts packages on the allowlist or denylist. Name is a GLOB pattern (e.g. @acme/*)
+// and an empty ecosystem applies to every ecosystem.
+type PackagePolicy struct {
+	ID        int64     `db:"id" json:"id"`
+	List      string    `db:"list" json:"list"`
+	Ecosystem string    `db:"ecosystem" json:"ecosystem,omitempty"`
+	Name      string    `db:"name" json:"name"`
+	Reason    string    `db:"reason" json:"reason,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type PackagePolicyInput struct {
+	List      string `json:"list"`
+	Ecosystem string `json:"ecosystem,omitempty"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// PolicyViolation is a dependency on a denied package or on a package missing from the allowlist
+// of its ecosystem
+type PolicyViolation struct {
+	DependencyWithRepo
+	Violation    string `db:"violation" json:"violation"`
+	PolicyReason string `db:"policy_reason" json:"policy_reason,omitempty"` // reason of the matching deny rule
+}
+
+// PolicyViolationStats counts the dependencies violating the package policies
+type PolicyViolationStats struct {
+	Denied     int `json:"denied"`
+	NotAllowed int `json:"not_allowed"`
+	Total      int `json:"total"`
+}
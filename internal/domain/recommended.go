@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// RecommendedVersion is a version pinned by an admin for a package, e.g. an internally approved
+// one. Dependencies of the package are outdated when older than it, whatever the registry's latest
+// version is.
+type RecommendedVersion struct {
+	ID        int64     `db:"id" json:"id"`
+	Ecosystem string    `db:"ecosystem" json:"ecosystem"`
+	Name      string    `db:"name" json:"name"`
+	Version   string    `db:"version" json:"version"`
+	Reason    string    `db:"reason" json:"reason,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type RecommendedVersionInput struct {
+	Version string `json:"version"`
+	Reason  string `json:"reason,omitempty"`
+}
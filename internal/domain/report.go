@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+// ComparisonReportDays are the periods the comparison report looks back over
+var ComparisonReportDays = []int{30, 90}
+
+// UnownedTeam groups the outdated dependencies of repositories without owners
+const UnownedTeam = "unowned"
+
+// SnapshotDay returns the day a state snapshot taken at t is archived under
+func SnapshotDay(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+// StateSnapshot is the archived state of the dependencies at the end of a day
+type StateSnapshot struct {
+	Day          string          `db:"day" json:"day"` // YYYY-MM-DD
+	Dependencies int             `db:"dependencies" json:"dependencies"`
+	Outdated     int             `db:"outdated" json:"outdated"`
+	TakenAt      time.Time       `db:"taken_at" json:"taken_at"`
+	Entries      []OutdatedEntry `db:"-" json:"-"`
+}
+
+// OutdatedEntry is an outdated dependency as archived in a state snapshot
+type OutdatedEntry struct {
+	Repository     string `db:"repository" json:"repository"` // full name
+	Source         string `db:"source" json:"source"`
+	Owners         string `db:"owners" json:"owners,omitempty"` // comma-separated, as on the repository
+	ManifestPath   string `db:"manifest_path" json:"manifest_path"`
+	Name           string `db:"name" json:"name"`
+	Type           string `db:"type" json:"type"`
+	Ecosystem      string `db:"ecosystem" json:"ecosystem"`
+	CurrentVersion string `db:"current_version" json:"current_version"`
+	LatestVersion  string `db:"latest_version" json:"latest_version"`
+}
+
+// Key identifies the dependency of an entry across snapshots
+func (e OutdatedEntry) Key() string {
+	return e.Repository + "\x00" + e.ManifestPath + "\x00" + e.Name + "\x00" + e.Type
+}
+
+// ComparisonReport compares the outdated dependencies now with the archived state of earlier days
+type ComparisonReport struct {
+	GeneratedAt time.Time          `json:"generated_at"`
+	Outdated    int                `json:"outdated"`
+	Periods     []ComparisonPeriod `json:"periods"`
+}
+
+// ComparisonPeriod is what changed since the state archived Days days ago, in total and per source
+// and team. Net is the change in outdated dependencies, negative when fewer are outdated now.
+type ComparisonPeriod struct {
+	Days          int               `json:"days"`
+	SnapshotDay   string            `json:"snapshot_day,omitempty"` // empty when no state that old is archived
+	Before        int               `json:"before"`
+	After         int               `json:"after"`
+	NewlyOutdated int               `json:"newly_outdated"`
+	Fixed         int               `json:"fixed"` // upgraded, ignored or removed since
+	Net           int               `json:"net"`
+	BySource      []ComparisonGroup `json:"by_source"`
+	ByTeam        []ComparisonGroup `json:"by_team"` // a dependency counts for each owner of its repository
+}
+
+// ComparisonGroup is the change in outdated dependencies of a source or team
+type ComparisonGroup struct {
+	Name          string `json:"name"`
+	Before        int    `json:"before"`
+	After         int    `json:"after"`
+	NewlyOutdated int    `json:"newly_outdated"`
+	Fixed         int    `json:"fixed"`
+	Net           int    `json:"net"`
+}
@@ -13,10 +13,47 @@ type Repository struct {
 	HasPomXML      bool       `db:"has_pom_xml" json:"has_pom_xml"`
 	HasBuildGradle bool       `db:"has_build_gradle" json:"has_build_gradle"`
 	HasGoMod       bool       `db:"has_go_mod" json:"has_go_mod"`
+	HasSwiftPM     bool       `db:"has_swiftpm" json:"has_swiftpm"`
+	HasPodfile     bool       `db:"has_podfile" json:"has_podfile"`
+	BranchOverride string     `db:"branch_override" json:"branch_override,omitempty"` // Branch to scan for this repository (overrides source scan branch)
+	Owners         string     `db:"owners" json:"owners,omitempty"`                   // Comma-separated owners, e.g. @org/team-a,alice@example.com
+	OwnersSource   string     `db:"owners_source" json:"owners_source,omitempty"`     // File the owners were read from
 	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
 	LastScanAt     *time.Time `db:"last_scan_at" json:"last_scan_at,omitempty"`
+	RemovedAt      *time.Time `db:"removed_at" json:"removed_at,omitempty"` // Set when the repository was no longer found in its source
+	// Error of the last scan, e.g. a missing scan branch or a manifest that failed to parse
+	LastScanError   string     `db:"last_scan_error" json:"last_scan_error,omitempty"`
+	LastScanErrorAt *time.Time `db:"last_scan_error_at" json:"last_scan_error_at,omitempty"`
+	// Go runtime of the root-most go.mod
+	GoVersion           string `db:"go_version" json:"go_version,omitempty"`               // go directive, e.g. 1.22
+	GoToolchain         string `db:"go_toolchain" json:"go_toolchain,omitempty"`           // toolchain directive, e.g. go1.22.3
+	GoVendored          bool   `db:"go_vendored" json:"go_vendored"`                       // Dependencies are vendored in vendor/
+	GoLatestVersion     string `db:"go_latest_version" json:"go_latest_version,omitempty"` // Latest Go release when the repository was scanned
+	GoToolchainOutdated bool   `db:"go_toolchain_outdated" json:"go_toolchain_outdated"`   // The toolchain (or go version) is older than GoLatestVersion
 	// Computed fields (not in DB)
 	DependencyCount int `db:"dependency_count" json:"dependency_count"`
 	OutdatedCount   int `db:"outdated_count" json:"outdated_count"`
 }
+
+// RepositoryPatch holds the user-editable fields of a repository
+type RepositoryPatch struct {
+	BranchOverride *string `json:"branch_override,omitempty"` // Empty string clears the override
+}
+
+// RepositoryPreview describes what the first scan of a repository would pick up,
+// used to onboard repositories before they are persisted
+type RepositoryPreview struct {
+	Repository            Repository         `json:"repository"`
+	Tracked               bool               `json:"tracked"` // Repository was already scanned before
+	Manifests             []ManifestSummary  `json:"manifests"`
+	EstimatedDependencies int                `json:"estimated_dependencies"`
+	Settings              RepositorySettings `json:"settings"`
+}
+
+// RepositorySettings are the effective scan settings of a repository
+type RepositorySettings struct {
+	ScanBranch         string `json:"scan_branch"` // Branch a scan uses: the override, or the first fallback branch with manifests
+	BranchOverride     string `json:"branch_override,omitempty"`
+	AdditionalBranches string `json:"additional_branches,omitempty"`
+}
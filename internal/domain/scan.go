@@ -12,13 +12,91 @@ const (
 )
 
 type ScanJob struct {
-	ID         int64      `db:"id" json:"id"`
-	SourceID   *int64     `db:"source_id" json:"source_id,omitempty"`
-	Status     ScanStatus `db:"status" json:"status"`
-	ReposFound int        `db:"repos_found" json:"repos_found"`
-	DepsFound  int        `db:"deps_found" json:"deps_found"`
-	Error      *string    `db:"error" json:"error,omitempty"`
-	StartedAt  *time.Time `db:"started_at" json:"started_at,omitempty"`
-	FinishedAt *time.Time `db:"finished_at" json:"finished_at,omitempty"`
-	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	ID           int64      `db:"id" json:"id"`
+	SourceID     *int64     `db:"source_id" json:"source_id,omitempty"`
+	Status       ScanStatus `db:"status" json:"status"`
+	ReposFound   int        `db:"repos_found" json:"repos_found"`
+	ReposTotal   int        `db:"repos_total" json:"repos_total"` // Repositories listed for the scan so far
+	DepsFound    int        `db:"deps_found" json:"deps_found"`
+	ReposNew     int        `db:"repos_new" json:"repos_new"`         // Repositories discovered since the previous scan
+	ReposRemoved int        `db:"repos_removed" json:"repos_removed"` // Repositories no longer found in their source
+	APICalls     int64      `db:"api_calls" json:"api_calls"`         // Provider API calls (including retries)
+	BytesFetched int64      `db:"bytes_fetched" json:"bytes_fetched"` // Provider response bytes
+	Error        *string    `db:"error" json:"error,omitempty"`
+	StartedAt    *time.Time `db:"started_at" json:"started_at,omitempty"`
+	FinishedAt   *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+// RunningScan is a pending or running scan with an estimate of its progress
+type RunningScan struct {
+	ScanJob
+	Progress ScanProgress `json:"progress"`
+}
+
+// ScanProgress estimates how far a scan is and when it finishes. Durations are 0 when they cannot
+// be estimated yet, i.e. without previous scans before the first repository was scanned.
+type ScanProgress struct {
+	ReposDone         int        `json:"repos_done"`
+	ReposTotal        int        `json:"repos_total"` // Repositories the scan is expected to cover
+	Percent           float64    `json:"percent"`
+	SecondsPerRepo    float64    `json:"seconds_per_repo"`
+	ElapsedSeconds    float64    `json:"elapsed_seconds"`
+	EstimatedSeconds  float64    `json:"estimated_seconds"` // Estimated duration of the whole scan
+	RemainingSeconds  float64    `json:"remaining_seconds"`
+	EstimatedFinishAt *time.Time `json:"estimated_finish_at,omitempty"`
+}
+
+// ScanSourceUsage is the provider API usage of a source during a scan
+type ScanSourceUsage struct {
+	ScanID       int64  `db:"scan_id" json:"scan_id"`
+	SourceID     int64  `db:"source_id" json:"source_id"`
+	SourceName   string `db:"source_name" json:"source_name"`
+	APICalls     int64  `db:"api_calls" json:"api_calls"`
+	BytesFetched int64  `db:"bytes_fetched" json:"bytes_fetched"`
+}
+
+// AdhocScanRequest describes a single repository to scan on demand without a configured source
+type AdhocScanRequest struct {
+	URL                string `json:"url"` // e.g. https://github.com/owner/repo or https://gitlab.example.com/group/project
+	Token              string `json:"token"`
+	Type               string `json:"type"`   // github or gitlab; detected from the URL when empty
+	Branch             string `json:"branch"` // defaults to the repository's default branch
+	Persist            bool   `json:"persist"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+}
+
+// AdhocScanResult is the outcome of an ad-hoc repository scan
+type AdhocScanResult struct {
+	Repository    Repository   `json:"repository"`
+	Manifests     []string     `json:"manifests"`
+	Dependencies  []Dependency `json:"dependencies"`
+	OutdatedCount int          `json:"outdated_count"`
+	Persisted     bool         `json:"persisted"`
+}
+
+// SourceScanFailure is a failed scan of a source reported by scan failure notifications. SourceID
+// is 0 when the scan failed without reaching a source.
+type SourceScanFailure struct {
+	ScanID     int64     `json:"scan_id"`
+	SourceID   int64     `json:"source_id,omitempty"`
+	SourceName string    `json:"source_name,omitempty"`
+	Error      string    `json:"error"`
+	FailedAt   time.Time `json:"failed_at"`
+
+	ConsecutiveFailures int  `json:"consecutive_failures,omitempty"` // Scans of the source failed in a row
+	Disabled            bool `json:"disabled,omitempty"`             // The source was disabled by this failure
+}
+
+// ScanSummary is the outcome of a scan reported to observability integrations
+type ScanSummary struct {
+	ScanID          int64      `json:"scan_id"`
+	Status          ScanStatus `json:"status"`
+	Repositories    int        `json:"repositories"`
+	Dependencies    int        `json:"dependencies"`
+	Outdated        int        `json:"outdated"`
+	SLABreaches     int        `json:"sla_breaches"`
+	APICalls        int64      `json:"api_calls"`
+	DurationSeconds float64    `json:"duration_seconds"`
+	FinishedAt      time.Time  `json:"finished_at"`
 }
@@ -1,9 +1,17 @@
 package domain
 
+import (
+	"strings"
+	"time"
+)
+
 type Settings struct {
 	// Schedule settings
 	ScheduleEnabled bool   `json:"schedule_enabled"`
 	ScheduleCron    string `json:"schedule_cron"`
+	ScheduleTimezone string `json:"schedule_timezone"` // IANA timezone of the schedule, e.g. "Asia/Seoul" (empty = server timezone)
+	ScheduleQueueWhenBusy bool `json:"schedule_queue_when_busy"` // Run scheduled scans after a running scan instead of skipping them
+	RescanIntervalMinutes int `json:"rescan_interval_minutes"` // Minutes before a requested rescan of the same repository runs again (0 = no limit)
 
 	// Email settings
 	EmailEnabled          bool   `json:"email_enabled"`
@@ -14,12 +22,68 @@ type Settings struct {
 	EmailFrom             string `json:"email_from"`
 	EmailTo               string `json:"email_to"`
 	EmailNotifyNewOutdated bool  `json:"email_notify_new_outdated"`
+	EmailAttachCSV         bool  `json:"email_attach_csv"` // Attach the newly outdated dependencies as CSV
+	EmailRemindDays        int   `json:"email_remind_days"` // Days before a dependency is reported again (0 always reports it)
+	EmailRecipientLocales  string `json:"email_recipient_locales"` // Comma-separated recipient=locale pairs overriding Locale, e.g. kim@example.com=ko
+	EmailNotifyViewID      int64  `json:"email_notify_view_id"`    // Saved view the newly outdated dependencies must match (0 = all)
+
+	// Email a report comparing the outdated dependencies with 30 and 90 days earlier on the first
+	// day of each month
+	EmailMonthlyReport bool `json:"email_monthly_report"`
+
+	// Scan failure notification settings
+	FailureNotifyEmail bool   `json:"failure_notify_email"` // Email the failing sources when email is enabled
+	FailureWebhookURL  string `json:"failure_webhook_url"`  // Slack incoming webhook or URL receiving a JSON payload (empty = disabled)
+	FailureNotifyHours int    `json:"failure_notify_hours"` // Hours before a failing source is notified again (0 notifies every failure)
+	SourceDisableAfter int    `json:"source_disable_after"` // Consecutive failed scans after which a source is disabled (0 never disables)
+
+	// Locale of notification emails and CSV exports, e.g. en or ko
+	Locale string `json:"locale"`
+
+	// Days allowed to fix an outdated dependency by upgrade type (0 disables the SLA)
+	SLAMajorDays int `json:"sla_major_days"`
+	SLAMinorDays int `json:"sla_minor_days"`
+	SLAPatchDays int `json:"sla_patch_days"`
+
+	// Version comparison settings; changing them recomputes the outdated status of stored dependencies
+	IgnorePrereleases bool `json:"ignore_prereleases"` // A newer prerelease (e.g. 2.0.0-rc.1) does not make a dependency outdated
+
+	// Scans compare dependencies whose registry cannot be reached with the last known latest
+	// version of their package, marked stale_data, instead of leaving it unknown
+	RegistryFallback bool `json:"registry_fallback"`
+
+	// Hours scans reuse the dependencies parsed from a manifest whose content is unchanged before
+	// parsing it again; 0 parses it on every scan. Latest versions are looked up on every scan.
+	ManifestRefreshHours int `json:"manifest_refresh_hours"`
+
+	// Cache lifetimes in seconds, applied immediately; 0 disables a cache. Registry lookups can be
+	// overridden per ecosystem, e.g. maven=7200,npm=300. The API caches dependency stats and
+	// repository names.
+	RegistryCacheSeconds   int    `json:"registry_cache_seconds"`
+	RegistryCacheOverrides string `json:"registry_cache_overrides"`
+	StatsCacheSeconds      int    `json:"stats_cache_seconds"`
+	ReposCacheSeconds      int    `json:"repos_cache_seconds"`
+
+	// Comma-separated dependency types and scopes left out of the staleness stats, e.g. peerDependency
+	// or provided
+	StatsExcludedTypes  string `json:"stats_excluded_types"`
+	StatsExcludedScopes string `json:"stats_excluded_scopes"`
+
+	// Observability integration settings
+	ObservabilityProvider  string `json:"observability_provider"` // datadog, newrelic or empty when disabled
+	ObservabilityAPIKey    string `json:"observability_api_key,omitempty"`
+	ObservabilitySite      string `json:"observability_site"`       // DataDog site (e.g. datadoghq.eu) or New Relic region (us, eu)
+	ObservabilityAccountID string `json:"observability_account_id"` // New Relic account ID
+	ObservabilityTags      string `json:"observability_tags"`       // Comma-separated key:value tags, e.g. env:prod
 }
 
 type SettingsInput struct {
 	// Schedule settings
 	ScheduleEnabled *bool   `json:"schedule_enabled,omitempty"`
 	ScheduleCron    *string `json:"schedule_cron,omitempty"`
+	ScheduleTimezone *string `json:"schedule_timezone,omitempty"`
+	ScheduleQueueWhenBusy *bool `json:"schedule_queue_when_busy,omitempty"`
+	RescanIntervalMinutes *int `json:"rescan_interval_minutes,omitempty"`
 
 	// Email settings
 	EmailEnabled          *bool   `json:"email_enabled,omitempty"`
@@ -30,10 +94,125 @@ type SettingsInput struct {
 	EmailFrom             *string `json:"email_from,omitempty"`
 	EmailTo               *string `json:"email_to,omitempty"`
 	EmailNotifyNewOutdated *bool  `json:"email_notify_new_outdated,omitempty"`
+	EmailAttachCSV         *bool  `json:"email_attach_csv,omitempty"`
+	EmailRemindDays        *int   `json:"email_remind_days,omitempty"`
+	EmailRecipientLocales  *string `json:"email_recipient_locales,omitempty"`
+	EmailNotifyViewID      *int64  `json:"email_notify_view_id,omitempty"`
+
+	// Monthly report settings
+	EmailMonthlyReport *bool `json:"email_monthly_report,omitempty"`
+
+	// Scan failure notification settings
+	FailureNotifyEmail *bool   `json:"failure_notify_email,omitempty"`
+	FailureWebhookURL  *string `json:"failure_webhook_url,omitempty"`
+	FailureNotifyHours *int    `json:"failure_notify_hours,omitempty"`
+	SourceDisableAfter *int    `json:"source_disable_after,omitempty"`
+
+	// Localization settings
+	Locale *string `json:"locale,omitempty"`
+
+	// Upgrade SLA settings
+	SLAMajorDays *int `json:"sla_major_days,omitempty"`
+	SLAMinorDays *int `json:"sla_minor_days,omitempty"`
+	SLAPatchDays *int `json:"sla_patch_days,omitempty"`
+
+	// Version comparison settings
+	IgnorePrereleases *bool `json:"ignore_prereleases,omitempty"`
+
+	// Registry outage settings
+	RegistryFallback *bool `json:"registry_fallback,omitempty"`
+
+	// Unchanged manifest settings
+	ManifestRefreshHours *int `json:"manifest_refresh_hours,omitempty"`
+
+	// Cache settings
+	RegistryCacheSeconds   *int    `json:"registry_cache_seconds,omitempty"`
+	RegistryCacheOverrides *string `json:"registry_cache_overrides,omitempty"`
+	StatsCacheSeconds      *int    `json:"stats_cache_seconds,omitempty"`
+	ReposCacheSeconds      *int    `json:"repos_cache_seconds,omitempty"`
+
+	// Stats settings
+	StatsExcludedTypes  *string `json:"stats_excluded_types,omitempty"`
+	StatsExcludedScopes *string `json:"stats_excluded_scopes,omitempty"`
+
+	// Observability integration settings
+	ObservabilityProvider  *string `json:"observability_provider,omitempty"`
+	ObservabilityAPIKey    *string `json:"observability_api_key,omitempty"`
+	ObservabilitySite      *string `json:"observability_site,omitempty"`
+	ObservabilityAccountID *string `json:"observability_account_id,omitempty"`
+	ObservabilityTags      *string `json:"observability_tags,omitempty"`
+}
+
+// StatsExclusions returns the dependency types and scopes left out of the staleness stats
+func (s *Settings) StatsExclusions() StatsExclusions {
+	return StatsExclusions{
+		Types:  splitList(s.StatsExcludedTypes),
+		Scopes: splitList(s.StatsExcludedScopes),
+	}
+}
+
+// splitList splits a comma-separated setting, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// NotificationPreview is a rendered notification that was not sent
+type NotificationPreview struct {
+	Channel     string   `json:"channel"` // email
+	Enabled     bool     `json:"enabled"`
+	WouldSend   bool     `json:"would_send"`
+	Reason      string   `json:"reason,omitempty"` // why nothing would be sent
+	ScanID      int64    `json:"scan_id,omitempty"`
+	Locale      string   `json:"locale"` // locale the notification is rendered in
+	From        string   `json:"from,omitempty"`
+	To          []string `json:"to"`
+	Subject     string   `json:"subject,omitempty"`
+	Body        string   `json:"body,omitempty"`
+	Attachments []string `json:"attachments,omitempty"` // file names
+}
+
+// NotificationThreadNewOutdated threads the new outdated dependency notifications
+const NotificationThreadNewOutdated = "new_outdated"
+
+// NotificationThread is the first email of a notification thread; later emails reply to it
+type NotificationThread struct {
+	Thread    string    `db:"thread" json:"thread"`
+	MessageID string    `db:"message_id" json:"message_id"`
+	StartedAt time.Time `db:"started_at" json:"started_at"`
 }
 
 type NewOutdatedReport struct {
 	ScanID       int64                `json:"scan_id"`
 	NewOutdated  []DependencyWithRepo `json:"new_outdated"`
+	NewRepos     int                  `json:"new_repos"`     // Repositories discovered during the scan
+	RemovedRepos int                  `json:"removed_repos"` // Repositories no longer found in their source
 	TotalScanned int                  `json:"total_scanned"`
+	SLABreaches  int                  `json:"sla_breaches"` // Outdated dependencies past their upgrade SLA
+}
+
+// SchedulerState tells whether scheduled scans are paused. The cron configuration is kept while
+// paused. Maintenance mode is a pause that also rejects manual scans.
+type SchedulerState struct {
+	Paused          bool       `json:"paused"`
+	PausedAt        *time.Time `json:"paused_at,omitempty"`
+	PausedUntil     *time.Time `json:"paused_until,omitempty"` // scans resume by themselves at this time
+	PauseReason     string     `json:"pause_reason,omitempty"`
+	MaintenanceMode bool       `json:"maintenance_mode"`
+	QueuedRuns      int        `json:"queued_runs"` // scheduled runs waiting for the running scan to complete
+}
+
+// ScansPaused reports whether scheduled scans are paused at the given time
+func (s *SchedulerState) ScansPaused(now time.Time) bool {
+	return s.Paused && (s.PausedUntil == nil || now.Before(*s.PausedUntil))
+}
+
+// InMaintenance reports whether manual scans are rejected at the given time
+func (s *SchedulerState) InMaintenance(now time.Time) bool {
+	return s.MaintenanceMode && s.ScansPaused(now)
 }
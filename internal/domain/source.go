@@ -5,29 +5,116 @@ import "time"
 type Source struct {
 	ID                 int64      `db:"id" json:"id"`
 	Name               string     `db:"name" json:"name"`
-	Type               string     `db:"type" json:"type"`       // github or gitlab
+	Type               string     `db:"type" json:"type"`       // github, gitlab, git (plain git server) or external (dependencies pushed through the ingest API)
 	Token              string     `db:"token" json:"-"`
+	TokenFingerprint   string     `db:"-" json:"token_fingerprint,omitempty"` // Short hash identifying the token without revealing it
+	TokenLast4         string     `db:"-" json:"token_last4,omitempty"`
 	Organization       string     `db:"organization" json:"organization,omitempty"` // GitHub org or GitLab group
-	URL                string     `db:"url" json:"url,omitempty"`                   // For self-hosted GitLab
+	URL                string     `db:"url" json:"url,omitempty"`                   // For self-hosted GitLab, or the base clone URL of a git source
 	Repositories       string     `db:"repositories" json:"repositories,omitempty"` // Comma-separated list of repos to scan (empty = all)
-	ScanBranch         string     `db:"scan_branch" json:"scan_branch,omitempty"` // Branch to scan (empty = use repo's default branch)
+	ScanBranch         string     `db:"scan_branch" json:"scan_branch,omitempty"` // Branch to scan, or comma-separated branches tried in order until one has manifests, e.g. "develop,main" (empty = use repo's default branch)
+	AdditionalBranches string     `db:"additional_branches" json:"additional_branches,omitempty"` // Comma-separated extra branches or patterns to scan (e.g. "release/*")
+	FetchMode          string     `db:"fetch_mode" json:"fetch_mode,omitempty"` // How manifests are fetched: api (default) or archive
 	InsecureSkipVerify bool       `db:"insecure_skip_verify" json:"insecure_skip_verify,omitempty"` // Skip TLS verification for self-hosted instances
 	MembershipOnly     bool       `db:"membership_only" json:"membership_only,omitempty"` // GitLab: only show projects where user is a member
 	OwnerOnly          bool       `db:"owner_only" json:"owner_only,omitempty"` // GitHub: only show repos owned by user (exclude collaborator repos)
+	ImportDependabotAlerts bool   `db:"import_dependabot_alerts" json:"import_dependabot_alerts,omitempty"` // GitHub: import open Dependabot alerts during scans
+	ScheduleCron       string     `db:"schedule_cron" json:"schedule_cron,omitempty"` // Own scan schedule (empty = scanned by the global schedule)
+	ScanWindow         string     `db:"scan_window" json:"scan_window,omitempty"` // Local time window for scheduled scans, e.g. "22:00-06:00" (empty = any time)
+	ScheduleTimezone   string     `db:"schedule_timezone" json:"schedule_timezone,omitempty"` // IANA timezone of the schedule and scan window, e.g. "Asia/Seoul" (empty = server timezone)
+	Priority           int        `db:"priority" json:"priority"` // Sources with a higher priority are scanned first
 	CreatedAt          time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt          time.Time  `db:"updated_at" json:"updated_at"`
 	LastScanAt         *time.Time `db:"last_scan_at" json:"last_scan_at,omitempty"`
+	TokenStatus        string     `db:"token_status" json:"token_status,omitempty"` // valid or invalid (empty = not checked yet)
+	TokenError         string     `db:"token_error" json:"token_error,omitempty"`
+	TokenExpiresAt     *time.Time `db:"token_expires_at" json:"token_expires_at,omitempty"` // GitHub/GitLab token expiration, if any
+	TokenCheckedAt     *time.Time `db:"token_checked_at" json:"token_checked_at,omitempty"`
+	LastScanError      string     `db:"last_scan_error" json:"last_scan_error,omitempty"` // Error of the last scan, cleared by a successful scan
+	LastScanErrorAt    *time.Time `db:"last_scan_error_at" json:"last_scan_error_at,omitempty"`
+	State              string     `db:"state" json:"state"` // active, or error once disabled after repeated scan failures
+	ConsecutiveFailures int       `db:"consecutive_failures" json:"consecutive_failures"` // Scans failed in a row
+	DisabledAt         *time.Time `db:"disabled_at" json:"disabled_at,omitempty"`
+}
+
+const (
+	TokenStatusValid   = "valid"
+	TokenStatusInvalid = "invalid"
+)
+
+const (
+	SourceStateActive = "active"
+	SourceStateError  = "error" // Disabled after repeated scan failures; skipped by scans until re-enabled
+)
+
+// Disabled reports whether the source was disabled after repeated scan failures
+func (s *Source) Disabled() bool {
+	return s.State == SourceStateError
+}
+
+// SourceTypeExternal is the type of sources whose dependencies are pushed by external scanners
+// instead of being fetched from a Git provider
+const SourceTypeExternal = "external"
+
+// External reports whether the dependencies of the source are pushed through the ingest API.
+// External sources have no provider and are never scanned.
+func (s *Source) External() bool {
+	return s.Type == SourceTypeExternal
+}
+
+// Manifest fetch modes of a source
+const (
+	FetchModeAPI     = "api"     // list the tree and fetch each manifest through the contents API
+	FetchModeArchive = "archive" // download the repository tarball once and extract the manifests
+)
+
+// TokenExpiryWarningDays is how long before expiration a token is reported as expiring
+const TokenExpiryWarningDays = 14
+
+// SourceTokenWarning reports a source whose token is invalid or about to expire
+type SourceTokenWarning struct {
+	SourceID   int64      `json:"source_id"`
+	SourceName string     `json:"source_name"`
+	Status     string     `json:"status"` // invalid, expired or expiring
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	DaysLeft   *int       `json:"days_left,omitempty"`
+	Error      string     `json:"error,omitempty"`
 }
 
 type SourceInput struct {
 	Name               string `json:"name"`
-	Type               string `json:"type"`                             // github or gitlab
+	Type               string `json:"type"`                             // github, gitlab or git (plain git server)
 	Token              string `json:"token"`
 	Organization       string `json:"organization,omitempty"`           // GitHub org or GitLab group
-	URL                string `json:"url,omitempty"`                    // For self-hosted GitLab
+	URL                string `json:"url,omitempty"`                    // For self-hosted GitLab, or the base clone URL of a git source
 	Repositories       string `json:"repositories,omitempty"`           // Comma-separated list of repos to scan (empty = all)
-	ScanBranch         string `json:"scan_branch,omitempty"`            // Branch to scan (empty = use repo's default branch)
+	ScanBranch         string `json:"scan_branch,omitempty"`            // Branch to scan, or comma-separated fallback branches (empty = use repo's default branch)
+	AdditionalBranches string `json:"additional_branches,omitempty"`    // Comma-separated extra branches or patterns to scan (e.g. "release/*")
+	FetchMode          string `json:"fetch_mode,omitempty"`             // How manifests are fetched: api (default) or archive
 	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`   // Skip TLS verification for self-hosted instances
 	MembershipOnly     bool   `json:"membership_only,omitempty"`        // GitLab: only show projects where user is a member
 	OwnerOnly          bool   `json:"owner_only,omitempty"`             // GitHub: only show repos owned by user (exclude collaborator repos)
+	ImportDependabotAlerts bool `json:"import_dependabot_alerts,omitempty"` // GitHub: import open Dependabot alerts during scans
+	ScheduleCron       string `json:"schedule_cron,omitempty"`          // Own scan schedule (empty = scanned by the global schedule)
+	ScanWindow         string `json:"scan_window,omitempty"`            // Local time window for scheduled scans, e.g. "22:00-06:00"
+	ScheduleTimezone   string `json:"schedule_timezone,omitempty"`      // IANA timezone of the schedule and scan window (empty = server timezone)
+	Priority           int    `json:"priority,omitempty"`               // Sources with a higher priority are scanned first
+}
+
+// SourceImportResult reports the outcome of a bulk source import
+type SourceImportResult struct {
+	DryRun  bool              `json:"dry_run"`
+	Created int               `json:"created"` // sources created (or valid, for a dry run)
+	Skipped int               `json:"skipped"`
+	Failed  int               `json:"failed"`
+	Rows    []SourceImportRow `json:"rows"`
+}
+
+// SourceImportRow is the outcome of a single row of an import file
+type SourceImportRow struct {
+	Row      int    `json:"row"` // 1-based, excluding the CSV header
+	Name     string `json:"name"`
+	Status   string `json:"status"` // created, valid (dry run), skipped, invalid or failed
+	SourceID int64  `json:"source_id,omitempty"`
+	Error    string `json:"error,omitempty"`
 }
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// Orders of the dependency list
+const (
+	SortName          = "name"
+	SortRepo          = "repo"
+	SortEcosystem     = "ecosystem"
+	SortOutdatedSince = "outdated_since" // Longest outdated first
+)
+
+// DependencySorts are the orders the dependency list accepts
+var DependencySorts = []string{SortName, SortRepo, SortEcosystem, SortOutdatedSince}
+
+// SavedView is a named combination of dependency list filters, which exports and the new
+// outdated notification can refer to
+type SavedView struct {
+	ID        int64     `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Status    string    `db:"status" json:"status"` // e.g. upgradable or prod, empty for all
+	Repo      string    `db:"repo" json:"repo"`     // Repository full name
+	Ecosystem string    `db:"ecosystem" json:"ecosystem"`
+	Search    string    `db:"search" json:"search"`
+	Sort      string    `db:"sort" json:"sort"`
+	Columns   string    `db:"columns" json:"columns"` // Comma-separated columns of exports, empty for the default ones
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type SavedViewInput struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Repo      string `json:"repo"`
+	Ecosystem string `json:"ecosystem"`
+	Search    string `json:"search"`
+	Sort      string `json:"sort"`
+	Columns   string `json:"columns"`
+}
@@ -0,0 +1,135 @@
+package i18n
+
+// en is the English catalog; every key must be present here since other locales fall back to it
+var en = map[string]string{
+	// New outdated dependencies notification
+	"new_outdated.subject":               "[Stale] %s new outdated dependencies found",
+	"new_outdated.subject_with_repos":    "[Stale] %s new outdated dependencies found, %s new repositories discovered",
+	"new_outdated.subject_new_repos":     "[Stale] %s new repositories discovered",
+	"new_outdated.subject_removed_repos": "[Stale] %s repositories no longer found",
+	"new_outdated.title":                 "New Outdated Dependencies Found",
+	"new_outdated.title_repos":           "Repository Changes Detected",
+	"new_outdated.summary":               "%s new outdated dependencies were detected during scan #%d.",
+	"new_outdated.new_repos":             "%s new repositories discovered during scan #%d.",
+	"new_outdated.removed_repos":         "%s repositories are no longer found in their source.",
+	"new_outdated.sla_breaches":          "%s outdated dependencies have exceeded their upgrade SLA.",
+	"new_outdated.footer":                "This report was generated by Stale - Dependency Version Dashboard",
+
+	// Notification preview
+	"preview.email_disabled":    "email notifications are disabled",
+	"preview.notify_disabled":   "new outdated dependency notifications are disabled",
+	"preview.no_recipients":     "no recipients configured",
+	"preview.nothing_to_report": "no new outdated dependencies or repository changes to report",
+
+	// Source token warnings
+	"token.subject":         "[Stale] %s source tokens need attention",
+	"token.subject_one":     "[Stale] Token of source %q needs attention",
+	"token.title":           "Source Tokens Need Attention",
+	"token.intro":           "Scans of these sources will fail once their token is no longer valid. Update the tokens in the source settings.",
+	"token.expires":         "expires %s",
+	"token.days_left":       "(%s days left)",
+	"token.status.expiring": "expiring",
+	"token.status.expired":  "expired",
+	"token.status.invalid":  "invalid",
+
+	// Scan failure notifications
+	"failure.subject":          "[Stale] %s sources failed to scan",
+	"failure.subject_one":      "[Stale] Source %q failed to scan",
+	"failure.subject_scan":     "[Stale] Scan #%d failed",
+	"failure.title":            "Scans Failed",
+	"failure.intro":            "These sources could not be scanned. Their dependencies are not updated until the error is fixed.",
+	"failure.throttle":         "Failures of the same source are reported at most once every %s hours.",
+	"failure.scan":             "Scan #%d",
+	"failure.disabled":         "Disabled after %s failed scans in a row. Re-enable the source once the error is fixed.",
+	"failure.subject_disabled": "[Stale] Source %q was disabled after repeated scan failures",
+
+	// Ignore rule expiry reminder
+	"ignore.subject":          "[Stale] %s ignore rules expired, %s expire this week",
+	"ignore.subject_expired":  "[Stale] %s ignore rules expired",
+	"ignore.subject_expiring": "[Stale] %s ignore rules expire this week",
+	"ignore.expired_title":    "Expired Ignore Rules",
+	"ignore.expired_intro":    "These dependencies are no longer ignored and are reported again.",
+	"ignore.expiring_title":   "Ignore Rules Expiring Soon",
+	"ignore.expiring_intro":   "Extend these rules before they expire if the dependencies should stay ignored.",
+
+	// Monthly comparison report
+	"comparison.subject":     "[Stale] Monthly report: %s outdated dependencies",
+	"comparison.title":       "Monthly Dependency Report",
+	"comparison.intro":       "%s dependencies are outdated now.",
+	"comparison.period":      "Last %s days",
+	"comparison.since":       "Compared with %s: %s newly outdated, %s fixed, net %s.",
+	"comparison.no_snapshot": "No state from %s days ago is archived yet.",
+	"comparison.by_source":   "By source",
+	"comparison.by_team":     "By team",
+
+	// Table and CSV columns
+	"column.no":              "No.",
+	"column.repository":      "Repository",
+	"column.owners":          "Owners",
+	"column.source":          "Source",
+	"column.dependency":      "Dependency",
+	"column.ecosystem":       "Ecosystem",
+	"column.type":            "Type",
+	"column.current":         "Current",
+	"column.latest":          "Latest",
+	"column.current_version": "Current Version",
+	"column.latest_version":  "Latest Version",
+	"column.upgradable":      "Upgradable",
+	"column.scope":           "Scope",
+	"column.latest_in_major": "Latest in Major",
+	"column.outdated_since":  "Outdated Since",
+	"column.manifest":        "Manifest",
+	"column.risks":           "Risks",
+	"column.status":          "Status",
+	"column.details":         "Details",
+	"column.reason":          "Reason",
+	"column.expired":         "Expired",
+	"column.expires":         "Expires",
+	"column.ignored_since":   "Ignored Since",
+	"column.setting":         "Setting",
+	"column.value":           "Value",
+	"column.before":          "Before",
+	"column.after":           "Now",
+	"column.newly_outdated":  "Newly Outdated",
+	"column.fixed":           "Fixed",
+	"column.net":             "Net",
+	"column.team":            "Team",
+
+	// Values
+	"value.yes":   "Yes",
+	"value.no":    "No",
+	"value.never": "Never",
+	"value.all":   "all",
+
+	// CSV export appendix
+	"export.ignored_title":  "Ignored Dependencies",
+	"export.settings_title": "Report Settings",
+	"export.generated_at":   "Generated At",
+	"export.locale":         "Language",
+	"export.filter":         "%s Filter",
+	"export.sla_major":      "Major Upgrade SLA (days)",
+	"export.sla_minor":      "Minor Upgrade SLA (days)",
+	"export.sla_patch":      "Patch Upgrade SLA (days)",
+	"filter.status":         "Status",
+	"filter.repository":     "Repository",
+	"filter.package":        "Package",
+	"filter.ecosystem":      "Ecosystem",
+	"filter.search":         "Search",
+	"filter.view":           "Saved View",
+
+	// API error titles, by error code
+	"error.bad_request":       "Invalid request",
+	"error.unauthorized":      "Authentication required",
+	"error.forbidden":         "Access denied",
+	"error.not_found":         "Not found",
+	"error.conflict":          "Conflict with the current state",
+	"error.request_too_large": "Request too large",
+	"error.rate_limited":      "Too many requests",
+	"error.internal_error":    "Internal error",
+	"error.upstream_error":    "A provider or registry failed",
+	"error.unavailable":       "Temporarily unavailable",
+	"error.invalid_api_key":   "Invalid or missing API key",
+	"error.feature_disabled":  "Feature disabled",
+	"error.scan_running":      "A scan is already running",
+	"error.read_only":         "This instance is read-only",
+}
@@ -0,0 +1,135 @@
+// Package i18n translates the strings of notification emails, exports and API error titles and
+// formats dates and numbers for a locale. Locales are identified by their language code, e.g.
+// "en" or "ko".
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default is the locale used when none is configured or requested
+const Default = "en"
+
+// locale holds the messages and formatting conventions of a language
+type locale struct {
+	dateLayout string // time.Format layout of dates
+	groupSep   string // thousands separator
+	messages   map[string]string
+}
+
+var locales = map[string]locale{
+	"en": {dateLayout: "2006-01-02", groupSep: ",", messages: en},
+	"ko": {dateLayout: "2006년 1월 2일", groupSep: ",", messages: ko},
+}
+
+// Supported returns the supported locales, sorted
+func Supported() []string {
+	codes := make([]string, 0, len(locales))
+	for code := range locales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Normalize returns the supported locale of a language tag (e.g. "ko-KR" or "ko_KR" is "ko"),
+// or an empty string when the language is not supported
+func Normalize(tag string) string {
+	lang := strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := locales[lang]; ok {
+		return lang
+	}
+	return ""
+}
+
+// Resolve returns the first supported of the given locales, falling back to Default
+func Resolve(tags ...string) string {
+	for _, tag := range tags {
+		if l := Normalize(tag); l != "" {
+			return l
+		}
+	}
+	return Default
+}
+
+// FromAcceptLanguage returns the supported locale preferred by an Accept-Language header, or an
+// empty string when it names none. Languages are taken in order of their quality value.
+func FromAcceptLanguage(header string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if tag != "" && q > 0 {
+			candidates = append(candidates, candidate{tag, q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if l := Normalize(c.tag); l != "" {
+			return l
+		}
+	}
+	return ""
+}
+
+// T returns the message for key in the locale, formatted with args like fmt.Sprintf. Messages
+// missing from the locale fall back to Default, and unknown keys are returned as is.
+func T(loc, key string, args ...interface{}) string {
+	msg, ok := get(loc).messages[key]
+	if !ok {
+		if msg, ok = locales[Default].messages[key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Date formats a date in the locale
+func Date(loc string, t time.Time) string {
+	return t.Format(get(loc).dateLayout)
+}
+
+// Number formats an integer with the thousands separator of the locale
+func Number(loc string, n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(get(loc).groupSep)
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
+
+func get(loc string) locale {
+	if l, ok := locales[loc]; ok {
+		return l
+	}
+	return locales[Default]
+}
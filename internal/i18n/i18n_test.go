@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"en":    "en",
+		"ko-KR": "ko",
+		"ko_kr": "ko",
+		" EN ":  "en",
+		"fr":    "",
+		"":      "",
+	}
+	for tag, want := range tests {
+		if got := Normalize(tag); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	if got := Resolve("", "fr", "ko"); got != "ko" {
+		t.Errorf("Resolve() = %q, want ko", got)
+	}
+	if got := Resolve("fr"); got != Default {
+		t.Errorf("Resolve() = %q, want %q", got, Default)
+	}
+}
+
+func TestFromAcceptLanguage(t *testing.T) {
+	tests := map[string]string{
+		"ko-KR,ko;q=0.9,en;q=0.8": "ko",
+		"fr-FR,en;q=0.5,ko;q=0.7": "ko",
+		"fr, de":                  "",
+		"en;q=0, ko;q=0.1":        "ko",
+		"":                        "",
+	}
+	for header, want := range tests {
+		if got := FromAcceptLanguage(header); got != want {
+			t.Errorf("FromAcceptLanguage(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T("en", "new_outdated.subject", "3"); got != "[Stale] 3 new outdated dependencies found" {
+		t.Errorf("T(en) = %q", got)
+	}
+	if got := T("ko", "new_outdated.summary", "3", 12); got != "스캔 #12에서 새로 오래된 의존성 3개가 감지되었습니다." {
+		t.Errorf("T(ko) = %q", got)
+	}
+	if got := T("fr", "value.yes"); got != "Yes" {
+		t.Errorf("T() of unsupported locale = %q, want English", got)
+	}
+	if got := T("en", "no.such.key"); got != "no.such.key" {
+		t.Errorf("T() of unknown key = %q", got)
+	}
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	for code, l := range locales {
+		for key := range l.messages {
+			if _, ok := en[key]; !ok {
+				t.Errorf("%s message %q is missing from the English catalog", code, key)
+			}
+		}
+		for key := range en {
+			if _, ok := l.messages[key]; !ok {
+				t.Errorf("%s catalog is missing %q", code, key)
+			}
+		}
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := map[int]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567", -12345: "-12,345"}
+	for n, want := range tests {
+		if got := Number("en", n); got != want {
+			t.Errorf("Number(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestDate(t *testing.T) {
+	d := time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC)
+	if got := Date("en", d); got != "2025-03-09" {
+		t.Errorf("Date(en) = %q", got)
+	}
+	if got := Date("ko", d); got != "2025년 3월 9일" {
+		t.Errorf("Date(ko) = %q", got)
+	}
+}
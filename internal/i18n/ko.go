@@ -0,0 +1,135 @@
+package i18n
+
+// ko is the Korean catalog
+var ko = map[string]string{
+	// New outdated dependencies notification
+	"new_outdated.subject":               "[Stale] 새로 오래된 의존성 %s개 발견",
+	"new_outdated.subject_with_repos":    "[Stale] 새로 오래된 의존성 %s개 발견, 새 저장소 %s개 발견",
+	"new_outdated.subject_new_repos":     "[Stale] 새 저장소 %s개 발견",
+	"new_outdated.subject_removed_repos": "[Stale] 더 이상 찾을 수 없는 저장소 %s개",
+	"new_outdated.title":                 "새로 오래된 의존성이 발견되었습니다",
+	"new_outdated.title_repos":           "저장소 변경이 감지되었습니다",
+	"new_outdated.summary":               "스캔 #%[2]d에서 새로 오래된 의존성 %[1]s개가 감지되었습니다.",
+	"new_outdated.new_repos":             "스캔 #%[2]d에서 새 저장소 %[1]s개가 발견되었습니다.",
+	"new_outdated.removed_repos":         "저장소 %s개를 소스에서 더 이상 찾을 수 없습니다.",
+	"new_outdated.sla_breaches":          "오래된 의존성 %s개가 업그레이드 SLA를 초과했습니다.",
+	"new_outdated.footer":                "이 보고서는 Stale - 의존성 버전 대시보드에서 생성되었습니다",
+
+	// Notification preview
+	"preview.email_disabled":    "이메일 알림이 비활성화되어 있습니다",
+	"preview.notify_disabled":   "새로 오래된 의존성 알림이 비활성화되어 있습니다",
+	"preview.no_recipients":     "수신자가 설정되지 않았습니다",
+	"preview.nothing_to_report": "보고할 새로 오래된 의존성이나 저장소 변경이 없습니다",
+
+	// Source token warnings
+	"token.subject":         "[Stale] 소스 토큰 %s개 확인 필요",
+	"token.subject_one":     "[Stale] 소스 %q의 토큰 확인 필요",
+	"token.title":           "소스 토큰 확인이 필요합니다",
+	"token.intro":           "토큰이 유효하지 않게 되면 이 소스들의 스캔이 실패합니다. 소스 설정에서 토큰을 업데이트하세요.",
+	"token.expires":         "%s 만료",
+	"token.days_left":       "(%s일 남음)",
+	"token.status.expiring": "만료 예정",
+	"token.status.expired":  "만료됨",
+	"token.status.invalid":  "유효하지 않음",
+
+	// Scan failure notifications
+	"failure.subject":          "[Stale] 소스 %s개 스캔 실패",
+	"failure.subject_one":      "[Stale] 소스 %q 스캔 실패",
+	"failure.subject_scan":     "[Stale] 스캔 #%d 실패",
+	"failure.title":            "스캔 실패",
+	"failure.intro":            "이 소스들을 스캔하지 못했습니다. 오류가 해결될 때까지 의존성 정보가 갱신되지 않습니다.",
+	"failure.throttle":         "같은 소스의 실패는 최대 %s시간에 한 번 보고됩니다.",
+	"failure.scan":             "스캔 #%d",
+	"failure.disabled":         "연속 %s회 스캔에 실패하여 비활성화되었습니다. 오류를 해결한 후 소스를 다시 활성화하세요.",
+	"failure.subject_disabled": "[Stale] 반복된 스캔 실패로 소스 %q 비활성화",
+
+	// Ignore rule expiry reminder
+	"ignore.subject":          "[Stale] 무시 규칙 %s개 만료, %s개 이번 주 만료 예정",
+	"ignore.subject_expired":  "[Stale] 무시 규칙 %s개 만료",
+	"ignore.subject_expiring": "[Stale] 무시 규칙 %s개 이번 주 만료 예정",
+	"ignore.expired_title":    "만료된 무시 규칙",
+	"ignore.expired_intro":    "이 의존성들은 더 이상 무시되지 않으며 다시 보고됩니다.",
+	"ignore.expiring_title":   "곧 만료되는 무시 규칙",
+	"ignore.expiring_intro":   "의존성을 계속 무시하려면 만료 전에 규칙을 연장하세요.",
+
+	// Monthly comparison report
+	"comparison.subject":     "[Stale] 월간 보고서: 구버전 의존성 %s개",
+	"comparison.title":       "월간 의존성 보고서",
+	"comparison.intro":       "현재 구버전 의존성은 %s개입니다.",
+	"comparison.period":      "최근 %s일",
+	"comparison.since":       "%s 대비: 새로 구버전 %s개, 해결 %s개, 순변화 %s.",
+	"comparison.no_snapshot": "%s일 전의 상태가 아직 보관되어 있지 않습니다.",
+	"comparison.by_source":   "소스별",
+	"comparison.by_team":     "팀별",
+
+	// Table and CSV columns
+	"column.no":              "번호",
+	"column.repository":      "저장소",
+	"column.owners":          "담당자",
+	"column.source":          "소스",
+	"column.dependency":      "의존성",
+	"column.ecosystem":       "생태계",
+	"column.type":            "유형",
+	"column.current":         "현재",
+	"column.latest":          "최신",
+	"column.current_version": "현재 버전",
+	"column.latest_version":  "최신 버전",
+	"column.upgradable":      "업그레이드 가능",
+	"column.scope":           "범위",
+	"column.latest_in_major": "메이저 내 최신",
+	"column.outdated_since":  "구버전 시작일",
+	"column.manifest":        "매니페스트",
+	"column.risks":           "위험",
+	"column.status":          "상태",
+	"column.details":         "상세",
+	"column.reason":          "사유",
+	"column.expired":         "만료일",
+	"column.expires":         "만료 예정일",
+	"column.ignored_since":   "무시 시작일",
+	"column.setting":         "설정",
+	"column.value":           "값",
+	"column.before":          "이전",
+	"column.after":           "현재",
+	"column.newly_outdated":  "새로 구버전",
+	"column.fixed":           "해결",
+	"column.net":             "순변화",
+	"column.team":            "팀",
+
+	// Values
+	"value.yes":   "예",
+	"value.no":    "아니요",
+	"value.never": "없음",
+	"value.all":   "전체",
+
+	// CSV export appendix
+	"export.ignored_title":  "무시된 의존성",
+	"export.settings_title": "보고서 설정",
+	"export.generated_at":   "생성 시각",
+	"export.locale":         "언어",
+	"export.filter":         "%s 필터",
+	"export.sla_major":      "메이저 업그레이드 SLA (일)",
+	"export.sla_minor":      "마이너 업그레이드 SLA (일)",
+	"export.sla_patch":      "패치 업그레이드 SLA (일)",
+	"filter.status":         "상태",
+	"filter.repository":     "저장소",
+	"filter.package":        "패키지",
+	"filter.ecosystem":      "생태계",
+	"filter.search":         "검색",
+	"filter.view":           "저장된 보기",
+
+	// API error titles, by error code
+	"error.bad_request":       "잘못된 요청",
+	"error.unauthorized":      "인증 필요",
+	"error.forbidden":         "접근 거부",
+	"error.not_found":         "찾을 수 없음",
+	"error.conflict":          "현재 상태와 충돌",
+	"error.request_too_large": "요청이 너무 큼",
+	"error.rate_limited":      "요청이 너무 많음",
+	"error.internal_error":    "내부 오류",
+	"error.upstream_error":    "프로바이더 또는 레지스트리 오류",
+	"error.unavailable":       "일시적으로 사용할 수 없음",
+	"error.invalid_api_key":   "API 키가 없거나 올바르지 않음",
+	"error.feature_disabled":  "비활성화된 기능",
+	"error.scan_running":      "이미 스캔이 실행 중",
+	"error.read_only":         "읽기 전용 인스턴스",
+}
@@ -0,0 +1,86 @@
+// Package render writes command output as JSON, an aligned text table or CSV,
+// so CLI output is usable both by scripts and by humans.
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Format is an output format
+type Format string
+
+const (
+	JSON  Format = "json"
+	Table Format = "table"
+	CSV   Format = "csv"
+)
+
+// ParseFormat parses an --output flag value (case-insensitive)
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case JSON, Table, CSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid output format %q (expected json, table or csv)", s)
+	}
+}
+
+// Tabular is data with a row/column representation for table and CSV output
+type Tabular interface {
+	Headers() []string
+	Rows() [][]string
+}
+
+// Write renders data in the given format. JSON output encodes data as-is (nil slices as []);
+// table and CSV output require data to implement Tabular.
+func Write(w io.Writer, format Format, data any) error {
+	if format == JSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	}
+
+	tab, ok := data.(Tabular)
+	if !ok {
+		return fmt.Errorf("%s output is not supported for %T", format, data)
+	}
+
+	switch format {
+	case Table:
+		return writeTable(w, tab)
+	case CSV:
+		return writeCSV(w, tab)
+	default:
+		return fmt.Errorf("invalid output format %q", format)
+	}
+}
+
+func writeTable(w io.Writer, tab Tabular) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(tab.Headers(), "\t"))
+	for _, row := range tab.Rows() {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			// Tabs and newlines would break the column layout
+			cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(cell)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeCSV(w io.Writer, tab Tabular) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(tab.Headers()); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(tab.Rows()); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,73 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+type people []struct {
+	Name string `json:"name"`
+	Age  string `json:"age"`
+}
+
+func (p people) Headers() []string { return []string{"NAME", "AGE"} }
+
+func (p people) Rows() [][]string {
+	rows := make([][]string, len(p))
+	for i, person := range p {
+		rows[i] = []string{person.Name, person.Age}
+	}
+	return rows
+}
+
+var testData = people{{"alice", "30"}, {"bob, jr", "4"}}
+
+func TestParseFormat(t *testing.T) {
+	for _, s := range []string{"json", "TABLE", " csv "} {
+		if _, err := ParseFormat(s); err != nil {
+			t.Errorf("ParseFormat(%q) error = %v", s, err)
+		}
+	}
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestWrite_JSON(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, JSON, testData); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `"name": "alice"`) {
+		t.Errorf("unexpected JSON output:\n%s", b.String())
+	}
+}
+
+func TestWrite_Table(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, Table, testData); err != nil {
+		t.Fatal(err)
+	}
+	expected := "NAME     AGE\nalice    30\nbob, jr  4\n"
+	if b.String() != expected {
+		t.Errorf("table output =\n%q\nwant\n%q", b.String(), expected)
+	}
+}
+
+func TestWrite_CSV(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, CSV, testData); err != nil {
+		t.Fatal(err)
+	}
+	expected := "NAME,AGE\nalice,30\n\"bob, jr\",4\n"
+	if b.String() != expected {
+		t.Errorf("CSV output =\n%q\nwant\n%q", b.String(), expected)
+	}
+}
+
+func TestWrite_NotTabular(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, Table, map[string]int{"a": 1}); err == nil {
+		t.Error("expected error for non-tabular data")
+	}
+}
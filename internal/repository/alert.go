@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type AlertRepository struct {
+	db *sqlx.DB
+}
+
+func NewAlertRepository(db *sqlx.DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+// alertSelect merges each alert with the matching dependency of the repository,
+// preferring the dependency declared in the same manifest. Dependabot reports Gradle
+// dependencies under the maven ecosystem.
+const alertSelect = `SELECT a.*, r.full_name as repo_full_name,
+	COALESCE(d.current_version, '') as current_version,
+	COALESCE(d.latest_version, '') as latest_version
+	FROM vulnerability_alerts a
+	JOIN repositories r ON a.repository_id = r.id
+	LEFT JOIN dependencies d ON d.id = COALESCE(
+		(SELECT d2.id FROM dependencies d2
+		 WHERE d2.repository_id = a.repository_id AND d2.name = a.package_name AND d2.manifest_path = a.manifest_path
+		   AND (d2.ecosystem = a.ecosystem OR (a.ecosystem = 'maven' AND d2.ecosystem = 'gradle'))
+		 LIMIT 1),
+		(SELECT d2.id FROM dependencies d2
+		 WHERE d2.repository_id = a.repository_id AND d2.name = a.package_name
+		   AND (d2.ecosystem = a.ecosystem OR (a.ecosystem = 'maven' AND d2.ecosystem = 'gradle'))
+		 LIMIT 1)
+	)`
+
+// ReplaceForRepo replaces the alerts of a repository with the given set, returning the alerts
+// that were not stored before
+func (r *AlertRepository) ReplaceForRepo(ctx context.Context, repoID int64, alerts []domain.VulnerabilityAlert) ([]domain.VulnerabilityAlert, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var stored []int
+	if err := tx.SelectContext(ctx, &stored, "SELECT alert_number FROM vulnerability_alerts WHERE repository_id = ?", repoID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM vulnerability_alerts WHERE repository_id = ?", repoID); err != nil {
+		return nil, err
+	}
+
+	query := `INSERT INTO vulnerability_alerts (repository_id, alert_number, ecosystem, package_name, manifest_path, severity, ghsa_id, cve_id, summary, vulnerable_range, patched_version, html_url, updated_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	now := time.Now()
+	for _, a := range alerts {
+		if _, err := tx.ExecContext(ctx, query,
+			repoID, a.AlertNumber, a.Ecosystem, a.PackageName, a.ManifestPath, a.Severity,
+			a.GHSAID, a.CVEID, a.Summary, a.VulnerableRange, a.PatchedVersion, a.HTMLURL, now); err != nil {
+			return nil, err
+		}
+	}
+
+	var added []domain.VulnerabilityAlert
+	for _, a := range alerts {
+		if !slices.Contains(stored, a.AlertNumber) {
+			added = append(added, a)
+		}
+	}
+	return added, tx.Commit()
+}
+
+// GetByRepoID returns the alerts of a repository, most severe first
+func (r *AlertRepository) GetByRepoID(ctx context.Context, repoID int64) ([]domain.VulnerabilityAlertWithDependency, error) {
+	var alerts []domain.VulnerabilityAlertWithDependency
+	query := alertSelect + ` WHERE a.repository_id = ? ORDER BY ` + severityOrder + `, a.package_name`
+	if err := r.db.SelectContext(ctx, &alerts, query, repoID); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// GetAll returns all alerts, optionally filtered by severity, most severe first
+func (r *AlertRepository) GetAll(ctx context.Context, severity string) ([]domain.VulnerabilityAlertWithDependency, error) {
+	query := alertSelect
+	var args []interface{}
+	if severity != "" {
+		query += " WHERE a.severity = ?"
+		args = append(args, severity)
+	}
+	query += " ORDER BY " + severityOrder + ", r.full_name, a.package_name"
+
+	var alerts []domain.VulnerabilityAlertWithDependency
+	if err := r.db.SelectContext(ctx, &alerts, query, args...); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// GetByPackage returns the alerts of a package in a repository, most severe first. Dependabot
+// reports Gradle dependencies under the maven ecosystem.
+func (r *AlertRepository) GetByPackage(ctx context.Context, repoID int64, ecosystem, name string) ([]domain.VulnerabilityAlertWithDependency, error) {
+	var alerts []domain.VulnerabilityAlertWithDependency
+	query := alertSelect + ` WHERE a.repository_id = ? AND a.package_name = ?
+		AND (a.ecosystem = ? OR (a.ecosystem = 'maven' AND ? = 'gradle'))
+		ORDER BY ` + severityOrder + `, a.manifest_path`
+	if err := r.db.SelectContext(ctx, &alerts, query, repoID, name, ecosystem, ecosystem); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+const severityOrder = `CASE a.severity WHEN 'critical' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2 WHEN 'low' THEN 3 ELSE 4 END`
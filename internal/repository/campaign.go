@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type CampaignRepository struct {
+	db *sqlx.DB
+}
+
+func NewCampaignRepository(db *sqlx.DB) *CampaignRepository {
+	return &CampaignRepository{db: db}
+}
+
+func (r *CampaignRepository) GetAll(ctx context.Context) ([]domain.Campaign, error) {
+	var campaigns []domain.Campaign
+	if err := r.db.SelectContext(ctx, &campaigns, "SELECT * FROM campaigns ORDER BY created_at DESC"); err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
+
+func (r *CampaignRepository) GetByID(ctx context.Context, id int64) (*domain.Campaign, error) {
+	var campaign domain.Campaign
+	if err := r.db.GetContext(ctx, &campaign, "SELECT * FROM campaigns WHERE id = ?", id); err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *CampaignRepository) Create(ctx context.Context, input domain.CampaignInput) (*domain.Campaign, error) {
+	query := `INSERT INTO campaigns (name, description, ecosystem, package_name, target_version, due_date, created_at, updated_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+              RETURNING *`
+
+	now := time.Now()
+	var campaign domain.Campaign
+	err := r.db.GetContext(ctx, &campaign, query, input.Name, input.Description, input.Ecosystem, input.PackageName, input.TargetVersion, input.DueDate, now, now)
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *CampaignRepository) Update(ctx context.Context, id int64, input domain.CampaignInput) (*domain.Campaign, error) {
+	query := `UPDATE campaigns SET name = ?, description = ?, ecosystem = ?, package_name = ?, target_version = ?, due_date = ?, updated_at = ?
+              WHERE id = ?
+              RETURNING *`
+
+	var campaign domain.Campaign
+	err := r.db.GetContext(ctx, &campaign, query, input.Name, input.Description, input.Ecosystem, input.PackageName, input.TargetVersion, input.DueDate, time.Now(), id)
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *CampaignRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM campaigns WHERE id = ?", id)
+	return err
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// queryer is implemented by *sqlx.DB and *sqlx.Tx, so writes can be part of a transaction
+type queryer interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+// SourceUpdate is the new configuration of an existing source
+type SourceUpdate struct {
+	ID    int64
+	Input domain.SourceInput
+}
+
+// IgnoreUpdate is the new reason and expiry of an existing ignore rule
+type IgnoreUpdate struct {
+	ID    int64
+	Input domain.IgnoredDependencyInput
+}
+
+// ConfigChangeSet is the changes reconciling the instance with a configuration document
+type ConfigChangeSet struct {
+	CreateSources []domain.SourceInput
+	UpdateSources []SourceUpdate
+	DeleteSources []int64 // with their repositories and dependencies
+	CreateIgnores []domain.IgnoredDependencyInput
+	UpdateIgnores []IgnoreUpdate
+	DeleteIgnores []int64
+	Settings      *domain.SettingsInput // nil to keep the settings
+}
+
+// ConfigRepository applies configuration documents
+type ConfigRepository struct {
+	db *sqlx.DB
+}
+
+func NewConfigRepository(db *sqlx.DB) *ConfigRepository {
+	return &ConfigRepository{db: db}
+}
+
+// Apply makes all changes in a single transaction, so a failing change leaves the instance as it
+// was. It returns the created sources and ignore rules.
+func (r *ConfigRepository) Apply(ctx context.Context, changes ConfigChangeSet) ([]domain.Source, []domain.IgnoredDependency, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	var sources []domain.Source
+	for _, input := range changes.CreateSources {
+		source, err := createSource(ctx, tx, input)
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, *source)
+	}
+	for _, u := range changes.UpdateSources {
+		if _, err := updateSource(ctx, tx, u.ID, u.Input); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, id := range changes.DeleteSources {
+		// Cascade delete: dependencies -> repositories -> source
+		for _, query := range []string{
+			"DELETE FROM dependencies WHERE repository_id IN (SELECT id FROM repositories WHERE source_id = ?)",
+			"DELETE FROM repositories WHERE source_id = ?",
+			"DELETE FROM sources WHERE id = ?",
+		} {
+			if _, err := tx.ExecContext(ctx, query, id); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	var ignored []domain.IgnoredDependency
+	for i := range changes.CreateIgnores {
+		ig, err := createIgnored(ctx, tx, &changes.CreateIgnores[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		ignored = append(ignored, *ig)
+	}
+	for _, u := range changes.UpdateIgnores {
+		if err := updateIgnored(ctx, tx, u.ID, &u.Input); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, id := range changes.DeleteIgnores {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM ignored_dependencies WHERE id = ?", id); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if changes.Settings != nil {
+		if err := updateSettings(ctx, tx, changes.Settings); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return sources, ignored, nil
+}
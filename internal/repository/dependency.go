@@ -1,10 +1,20 @@
 package repository
 
 import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/service/fuzzy"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -17,13 +27,23 @@ func NewDependencyRepository(db *sqlx.DB) *DependencyRepository {
 }
 
 func (r *DependencyRepository) Upsert(ctx context.Context, dep domain.Dependency) error {
-	query := `INSERT INTO dependencies (repository_id, name, current_version, latest_version, type, ecosystem, is_outdated, updated_at)
-              VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-              ON CONFLICT(repository_id, name, type) DO UPDATE SET
+	// first_outdated_at is kept while the dependency stays outdated and cleared once it is up to date
+	query := `INSERT INTO dependencies (repository_id, name, current_version, latest_version, latest_in_major, recommended_version, type, scope, ecosystem, risks, risk_detail, is_outdated, stale_data, manifest_path, manifest_type, first_outdated_at, updated_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+              ON CONFLICT(repository_id, manifest_path, name, type) DO UPDATE SET
                   current_version = excluded.current_version,
                   latest_version = excluded.latest_version,
+                  latest_in_major = excluded.latest_in_major,
+                  recommended_version = excluded.recommended_version,
+                  scope = excluded.scope,
                   ecosystem = excluded.ecosystem,
+                  risks = excluded.risks,
+                  risk_detail = excluded.risk_detail,
                   is_outdated = excluded.is_outdated,
+                  stale_data = excluded.stale_data,
+                  manifest_type = excluded.manifest_type,
+                  first_outdated_at = CASE WHEN excluded.is_outdated
+                      THEN COALESCE(dependencies.first_outdated_at, excluded.first_outdated_at) ELSE NULL END,
                   updated_at = excluded.updated_at`
 
 	ecosystem := dep.Ecosystem
@@ -31,24 +51,548 @@ func (r *DependencyRepository) Upsert(ctx context.Context, dep domain.Dependency
 		ecosystem = "npm"
 	}
 
+	now := time.Now()
+	var firstOutdatedAt *time.Time
+	if dep.IsOutdated {
+		// A known start, e.g. of a restored dependency, is kept
+		firstOutdatedAt = cmp.Or(dep.FirstOutdatedAt, &now)
+	}
+
+	if dep.ManifestPath != "" {
+		if err := r.claimLegacyRow(ctx, dep); err != nil {
+			return err
+		}
+	}
+
 	_, err := r.db.ExecContext(ctx, query,
-		dep.RepositoryID, dep.Name, dep.CurrentVersion, dep.LatestVersion,
-		dep.Type, ecosystem, dep.IsOutdated, time.Now())
+		dep.RepositoryID, dep.Name, dep.CurrentVersion, dep.LatestVersion, dep.LatestInMajor, dep.RecommendedVersion,
+		dep.Type, dep.Scope, ecosystem, dep.Risks, dep.RiskDetail, dep.IsOutdated, dep.StaleData, dep.ManifestPath, dep.ManifestType, firstOutdatedAt, now)
+	return err
+}
+
+// claimLegacyRow moves a dependency recorded before manifest paths were tracked (migration 013)
+// to the manifest it is now found in, so the upsert keeps its previously_outdated flag and
+// first_outdated_at instead of reporting it as newly outdated. The first manifest declaring the
+// dependency claims it.
+func (r *DependencyRepository) claimLegacyRow(ctx context.Context, dep domain.Dependency) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE dependencies SET manifest_path = ?
+		 WHERE repository_id = ? AND manifest_path = '' AND name = ? AND type = ?
+		   AND NOT EXISTS (SELECT 1 FROM dependencies d
+		                   WHERE d.repository_id = ? AND d.manifest_path = ? AND d.name = ? AND d.type = ?)`,
+		dep.ManifestPath, dep.RepositoryID, dep.Name, dep.Type,
+		dep.RepositoryID, dep.ManifestPath, dep.Name, dep.Type)
+	return err
+}
+
+// UpsertBranch stores a dependency of an additional branch of a repository
+func (r *DependencyRepository) UpsertBranch(ctx context.Context, dep domain.Dependency) error {
+	query := `INSERT INTO branch_dependencies (repository_id, branch, name, current_version, latest_version, type, scope, ecosystem, is_outdated, manifest_path, manifest_type, updated_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+              ON CONFLICT(repository_id, branch, manifest_path, name, type) DO UPDATE SET
+                  current_version = excluded.current_version,
+                  latest_version = excluded.latest_version,
+                  scope = excluded.scope,
+                  ecosystem = excluded.ecosystem,
+                  is_outdated = excluded.is_outdated,
+                  manifest_type = excluded.manifest_type,
+                  updated_at = excluded.updated_at`
+
+	_, err := r.db.ExecContext(ctx, query,
+		dep.RepositoryID, dep.Branch, dep.Name, dep.CurrentVersion, dep.LatestVersion,
+		dep.Type, dep.Scope, dep.Ecosystem, dep.IsOutdated, dep.ManifestPath, dep.ManifestType, time.Now())
+	return err
+}
+
+// GetByRepoBranch returns the dependencies of an additional branch of a repository
+func (r *DependencyRepository) GetByRepoBranch(ctx context.Context, repoID int64, branch string) ([]domain.Dependency, error) {
+	var deps []domain.Dependency
+	err := r.db.SelectContext(ctx, &deps,
+		"SELECT * FROM branch_dependencies WHERE repository_id = ? AND branch = ? ORDER BY name", repoID, branch)
+	if err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+// GetBranchesByRepoID returns the additional branches of a repository with dependency counts
+func (r *DependencyRepository) GetBranchesByRepoID(ctx context.Context, repoID int64) ([]domain.BranchSummary, error) {
+	query := `SELECT branch, COUNT(*) as dependency_count,
+                     COALESCE(SUM(CASE WHEN is_outdated = TRUE THEN 1 ELSE 0 END), 0) as outdated_count
+              FROM branch_dependencies
+              WHERE repository_id = ?
+              GROUP BY branch
+              ORDER BY branch`
+
+	var branches []domain.BranchSummary
+	err := r.db.SelectContext(ctx, &branches, query, repoID)
+	if err != nil {
+		return nil, err
+	}
+	return branches, nil
+}
+
+// DeleteStaleBranchDependencies deletes dependencies of a branch that weren't updated after the given time
+func (r *DependencyRepository) DeleteStaleBranchDependencies(ctx context.Context, repoID int64, branch string, updatedAfter time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM branch_dependencies WHERE repository_id = ? AND branch = ? AND updated_at < ?",
+		repoID, branch, updatedAfter)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteBranchesExcept removes dependency sets of branches of a repository that are not in keep
+func (r *DependencyRepository) DeleteBranchesExcept(ctx context.Context, repoID int64, keep []string) error {
+	if len(keep) == 0 {
+		_, err := r.db.ExecContext(ctx, "DELETE FROM branch_dependencies WHERE repository_id = ?", repoID)
+		return err
+	}
+
+	query, args, err := sqlx.In("DELETE FROM branch_dependencies WHERE repository_id = ? AND branch NOT IN (?)", repoID, keep)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, r.db.Rebind(query), args...)
 	return err
 }
 
+// GetManifestHashes returns the recorded content hashes of the manifests of a repository by path
+func (r *DependencyRepository) GetManifestHashes(ctx context.Context, repoID int64) (map[string]domain.ManifestHash, error) {
+	var rows []domain.ManifestHash
+	err := r.db.SelectContext(ctx, &rows, "SELECT * FROM manifest_hashes WHERE repository_id = ?", repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]domain.ManifestHash, len(rows))
+	for _, h := range rows {
+		hashes[h.ManifestPath] = h
+	}
+	return hashes, nil
+}
+
+// SetManifestHash records the content hash of a manifest whose dependencies were just processed
+func (r *DependencyRepository) SetManifestHash(ctx context.Context, repoID int64, path, hash string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO manifest_hashes (repository_id, manifest_path, content_hash, hashed_at) VALUES (?, ?, ?, ?)
+         ON CONFLICT(repository_id, manifest_path) DO UPDATE SET content_hash = excluded.content_hash, hashed_at = excluded.hashed_at`,
+		repoID, path, hash, time.Now())
+	return err
+}
+
+// DeleteManifestHashesExcept removes the hashes of manifests of a repository that are not in keep
+func (r *DependencyRepository) DeleteManifestHashesExcept(ctx context.Context, repoID int64, keep []string) error {
+	if len(keep) == 0 {
+		_, err := r.db.ExecContext(ctx, "DELETE FROM manifest_hashes WHERE repository_id = ?", repoID)
+		return err
+	}
+
+	query, args, err := sqlx.In("DELETE FROM manifest_hashes WHERE repository_id = ? AND manifest_path NOT IN (?)", repoID, keep)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	return err
+}
+
+// SetManifestContent stores the content of a manifest whose dependencies were just processed,
+// gzipped when compress is set
+func (r *DependencyRepository) SetManifestContent(ctx context.Context, repoID int64, path string, content []byte, compress bool) error {
+	stored := content
+	if compress {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(content); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		stored = buf.Bytes()
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO manifest_contents (repository_id, manifest_path, content, compressed, size, stored_at) VALUES (?, ?, ?, ?, ?, ?)
+         ON CONFLICT(repository_id, manifest_path) DO UPDATE SET
+            content = excluded.content, compressed = excluded.compressed, size = excluded.size, stored_at = excluded.stored_at`,
+		repoID, path, stored, compress, len(content), time.Now())
+	return err
+}
+
+// GetManifestContent returns the stored content of a manifest of a repository, decompressed
+func (r *DependencyRepository) GetManifestContent(ctx context.Context, repoID int64, path string) (*domain.ManifestContent, error) {
+	var mc domain.ManifestContent
+	err := r.db.GetContext(ctx, &mc,
+		"SELECT * FROM manifest_contents WHERE repository_id = ? AND manifest_path = ?", repoID, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if mc.Compressed {
+		gz, err := gzip.NewReader(bytes.NewReader(mc.Content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress manifest content: %w", err)
+		}
+		defer gz.Close()
+		if mc.Content, err = io.ReadAll(gz); err != nil {
+			return nil, fmt.Errorf("failed to decompress manifest content: %w", err)
+		}
+	}
+	return &mc, nil
+}
+
+// DeleteManifestContentsExcept removes the stored content of manifests of a repository that are
+// not in keep
+func (r *DependencyRepository) DeleteManifestContentsExcept(ctx context.Context, repoID int64, keep []string) error {
+	if len(keep) == 0 {
+		_, err := r.db.ExecContext(ctx, "DELETE FROM manifest_contents WHERE repository_id = ?", repoID)
+		return err
+	}
+
+	query, args, err := sqlx.In("DELETE FROM manifest_contents WHERE repository_id = ? AND manifest_path NOT IN (?)", repoID, keep)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	return err
+}
+
+// ReplaceSkipped replaces the skipped dependencies recorded for a manifest of a repository
+func (r *DependencyRepository) ReplaceSkipped(ctx context.Context, repoID int64, manifestPath string, skipped []domain.SkippedDependency) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM skipped_dependencies WHERE repository_id = ? AND manifest_path = ?", repoID, manifestPath); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, sd := range skipped {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO skipped_dependencies (repository_id, name, ecosystem, version, manifest_path, reason, detail, detected_at)
+             VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+             ON CONFLICT(repository_id, manifest_path, name, ecosystem) DO UPDATE SET
+                version = excluded.version, reason = excluded.reason, detail = excluded.detail`,
+			repoID, sd.Name, sd.Ecosystem, sd.Version, manifestPath, sd.Reason, sd.Detail, now); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteSkippedExcept removes the skipped dependencies of manifests of a repository that are not in keep
+func (r *DependencyRepository) DeleteSkippedExcept(ctx context.Context, repoID int64, keep []string) error {
+	if len(keep) == 0 {
+		_, err := r.db.ExecContext(ctx, "DELETE FROM skipped_dependencies WHERE repository_id = ?", repoID)
+		return err
+	}
+
+	query, args, err := sqlx.In("DELETE FROM skipped_dependencies WHERE repository_id = ? AND manifest_path NOT IN (?)", repoID, keep)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	return err
+}
+
+// GetSkippedByRepoID returns the skipped dependencies of a repository, ordered by manifest and name
+func (r *DependencyRepository) GetSkippedByRepoID(ctx context.Context, repoID int64) ([]domain.SkippedDependency, error) {
+	var skipped []domain.SkippedDependency
+	err := r.db.SelectContext(ctx, &skipped,
+		"SELECT * FROM skipped_dependencies WHERE repository_id = ? ORDER BY manifest_path, name, ecosystem", repoID)
+	return skipped, err
+}
+
+// GetStaleMaintainers returns up to limit packages in use of the given ecosystems whose maintainers
+// were never fetched or were fetched before the given time, least recently fetched first
+func (r *DependencyRepository) GetStaleMaintainers(ctx context.Context, ecosystems []string, fetchedBefore time.Time, limit int) ([]domain.PackageMaintainers, error) {
+	query, args, err := sqlx.In(`
+		SELECT d.ecosystem, d.name
+		FROM dependencies d
+		LEFT JOIN package_maintainers pm ON pm.ecosystem = d.ecosystem AND pm.name = d.name
+		WHERE d.ecosystem IN (?) AND (pm.fetched_at IS NULL OR pm.fetched_at < ?)
+		GROUP BY d.ecosystem, d.name
+		ORDER BY pm.fetched_at, d.ecosystem, d.name
+		LIMIT ?`, ecosystems, fetchedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []domain.PackageMaintainers
+	err = r.db.SelectContext(ctx, &packages, r.db.Rebind(query), args...)
+	return packages, err
+}
+
+// GetPopularPackages returns the packages used by the most repositories, most used first, as
+// dependencies with only their name and ecosystem set
+func (r *DependencyRepository) GetPopularPackages(ctx context.Context, limit int) ([]domain.Dependency, error) {
+	var packages []domain.Dependency
+	err := r.db.SelectContext(ctx, &packages,
+		`SELECT d.ecosystem, d.name
+		 FROM dependencies d
+		 GROUP BY d.ecosystem, d.name
+		 ORDER BY COUNT(DISTINCT d.repository_id) DESC, COUNT(*) DESC, d.ecosystem, d.name
+		 LIMIT ?`, limit)
+	return packages, err
+}
+
+// SetMaintainers records the maintainers of a package
+func (r *DependencyRepository) SetMaintainers(ctx context.Context, ecosystem, name string, maintainers []string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO package_maintainers (ecosystem, name, maintainers, maintainer_count, fetched_at) VALUES (?, ?, ?, ?, ?)
+         ON CONFLICT(ecosystem, name) DO UPDATE SET
+             maintainers = excluded.maintainers, maintainer_count = excluded.maintainer_count, fetched_at = excluded.fetched_at`,
+		ecosystem, name, strings.Join(maintainers, ","), len(maintainers), time.Now())
+	return err
+}
+
+// GetMaintainers returns the packages in use with at least one and at most maxMaintainers known
+// maintainers, fewest maintainers and most depended on first
+func (r *DependencyRepository) GetMaintainers(ctx context.Context, maxMaintainers int) ([]domain.PackageMaintainers, error) {
+	var packages []domain.PackageMaintainers
+	err := r.db.SelectContext(ctx, &packages, `
+		SELECT pm.ecosystem, pm.name, pm.maintainers, pm.maintainer_count, pm.fetched_at,
+		       COUNT(DISTINCT d.repository_id) AS repositories
+		FROM package_maintainers pm
+		JOIN dependencies d ON d.ecosystem = pm.ecosystem AND d.name = pm.name
+		WHERE pm.maintainer_count BETWEEN 1 AND ?
+		GROUP BY pm.ecosystem, pm.name
+		ORDER BY pm.maintainer_count, repositories DESC, pm.name`, maxMaintainers)
+	return packages, err
+}
+
+// GetLastKnownLatestVersion returns the latest version of a package recorded by the most recent
+// scan that found one, or an empty string when no scan did
+func (r *DependencyRepository) GetLastKnownLatestVersion(ctx context.Context, ecosystem, name string) (string, error) {
+	var latest string
+	err := r.db.GetContext(ctx, &latest,
+		`SELECT latest_version FROM dependencies
+		WHERE ecosystem = ? AND name = ? AND latest_version <> ''
+		ORDER BY updated_at DESC LIMIT 1`, ecosystem, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return latest, err
+}
+
+// GetRecommendedVersions returns the versions pinned for packages, by ecosystem and name
+func (r *DependencyRepository) GetRecommendedVersions(ctx context.Context) ([]domain.RecommendedVersion, error) {
+	var recommended []domain.RecommendedVersion
+	err := r.db.SelectContext(ctx, &recommended, "SELECT * FROM recommended_versions ORDER BY ecosystem, name")
+	if err != nil {
+		return nil, err
+	}
+	return recommended, nil
+}
+
+// SetRecommendedVersion pins the recommended version of a package and records it on the stored
+// dependencies of the package. RecomputeOutdated applies it to their outdated status.
+func (r *DependencyRepository) SetRecommendedVersion(ctx context.Context, ecosystem, name string, input domain.RecommendedVersionInput) (*domain.RecommendedVersion, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var recommended domain.RecommendedVersion
+	err = tx.GetContext(ctx, &recommended,
+		`INSERT INTO recommended_versions (ecosystem, name, version, reason, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)
+         ON CONFLICT(ecosystem, name) DO UPDATE SET
+             version = excluded.version, reason = excluded.reason, updated_at = excluded.updated_at
+         RETURNING *`,
+		ecosystem, name, input.Version, input.Reason, now, now)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE dependencies SET recommended_version = ? WHERE ecosystem = ? AND name = ?",
+		input.Version, ecosystem, name); err != nil {
+		return nil, err
+	}
+	return &recommended, tx.Commit()
+}
+
+// DeleteRecommendedVersion unpins the recommended version of a package, reporting whether one was
+// pinned. RecomputeOutdated compares its dependencies with the latest version again.
+func (r *DependencyRepository) DeleteRecommendedVersion(ctx context.Context, ecosystem, name string) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM recommended_versions WHERE ecosystem = ? AND name = ?", ecosystem, name)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE dependencies SET recommended_version = '' WHERE ecosystem = ? AND name = ?", ecosystem, name); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// RecomputeOutdated re-evaluates is_outdated of every stored dependency from its current and
+// recommended or latest versions without looking anything up, returning the number of dependencies changed.
+// first_outdated_at starts now for dependencies that became outdated and is cleared for those
+// that no longer are.
+func (r *DependencyRepository) RecomputeOutdated(ctx context.Context, isOutdated func(current, latest string) bool) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	type row struct {
+		ID             int64   `db:"id"`
+		CurrentVersion string  `db:"current_version"`
+		LatestVersion  *string `db:"latest_version"`
+		IsOutdated     bool    `db:"is_outdated"`
+	}
+
+	now := time.Now()
+	var changed int64
+	for _, table := range []string{"dependencies", "branch_dependencies"} {
+		var rows []row
+		// The recommended version of the package, when pinned, replaces the latest one
+		if err := tx.SelectContext(ctx, &rows,
+			`SELECT t.id, t.current_version, COALESCE(rv.version, t.latest_version) AS latest_version,
+			        COALESCE(t.is_outdated, FALSE) AS is_outdated
+			 FROM `+table+` t
+			 LEFT JOIN recommended_versions rv ON rv.ecosystem = t.ecosystem AND rv.name = t.name`); err != nil {
+			return 0, err
+		}
+
+		update := "UPDATE " + table + " SET is_outdated = ? WHERE id = ?"
+		if table == "dependencies" {
+			update = "UPDATE dependencies SET is_outdated = ?, first_outdated_at = CASE WHEN ? THEN ? ELSE NULL END WHERE id = ?"
+		}
+
+		for _, d := range rows {
+			latest := ""
+			if d.LatestVersion != nil {
+				latest = *d.LatestVersion
+			}
+			outdated := isOutdated(d.CurrentVersion, latest)
+			if outdated == d.IsOutdated {
+				continue
+			}
+
+			args := []interface{}{outdated, d.ID}
+			if table == "dependencies" {
+				args = []interface{}{outdated, outdated, now, d.ID}
+			}
+			if _, err := tx.ExecContext(ctx, update, args...); err != nil {
+				return 0, err
+			}
+			changed++
+		}
+	}
+
+	return changed, tx.Commit()
+}
+
+// GetByManifest returns the dependencies of a repository declared in a manifest
+func (r *DependencyRepository) GetByManifest(ctx context.Context, repoID int64, manifestPath string) ([]domain.Dependency, error) {
+	var deps []domain.Dependency
+	err := r.db.SelectContext(ctx, &deps,
+		"SELECT * FROM dependencies WHERE repository_id = ? AND manifest_path = ? ORDER BY name, type", repoID, manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
 func (r *DependencyRepository) GetByRepoID(ctx context.Context, repoID int64) ([]domain.Dependency, error) {
 	var deps []domain.Dependency
 	err := r.db.SelectContext(ctx, &deps,
-		"SELECT * FROM dependencies WHERE repository_id = ? ORDER BY name", repoID)
+		"SELECT * FROM dependencies WHERE repository_id = ? ORDER BY name, manifest_path, type", repoID)
 	if err != nil {
 		return nil, err
 	}
 	return deps, nil
 }
 
+// GetManifestsByRepoID returns the manifests of a repository with per-manifest dependency counts
+func (r *DependencyRepository) GetManifestsByRepoID(ctx context.Context, repoID int64) ([]domain.ManifestSummary, error) {
+	query := `SELECT manifest_path, manifest_type, MIN(ecosystem) as ecosystem,
+                     COUNT(*) as dependency_count,
+                     COALESCE(SUM(CASE WHEN is_outdated = TRUE THEN 1 ELSE 0 END), 0) as outdated_count
+              FROM dependencies
+              WHERE repository_id = ?
+              GROUP BY manifest_path, manifest_type
+              ORDER BY manifest_path`
+
+	var manifests []domain.ManifestSummary
+	err := r.db.SelectContext(ctx, &manifests, query, repoID)
+	if err != nil {
+		return nil, err
+	}
+	return manifests, nil
+}
+
+// GetByID returns a dependency of the default branch with its repository
+func (r *DependencyRepository) GetByID(ctx context.Context, id int64) (*domain.DependencyWithRepo, error) {
+	query := `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, r.owners as repo_owners, s.name as source_name
+              FROM dependencies d
+              JOIN repositories r ON d.repository_id = r.id
+              JOIN sources s ON r.source_id = s.id
+              WHERE d.id = ?`
+
+	var dep domain.DependencyWithRepo
+	if err := r.db.GetContext(ctx, &dep, query, id); err != nil {
+		return nil, err
+	}
+	return &dep, nil
+}
+
+// GetHistory returns the versions a dependency was found at, oldest first
+func (r *DependencyRepository) GetHistory(ctx context.Context, dep domain.Dependency) ([]domain.DependencyVersion, error) {
+	var history []domain.DependencyVersion
+	err := r.db.SelectContext(ctx, &history,
+		`SELECT version, latest_version, recorded_at FROM dependency_history
+		 WHERE repository_id = ? AND manifest_path = ? AND name = ? AND type = ?
+		 ORDER BY recorded_at, id`,
+		dep.RepositoryID, dep.ManifestPath, dep.Name, dep.Type)
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// GetPackageReleases returns the releases of packages in use, optionally of one ecosystem, oldest
+// first for each package
+func (r *DependencyRepository) GetPackageReleases(ctx context.Context, ecosystem string) ([]domain.PackageRelease, error) {
+	query := `SELECT pr.ecosystem, pr.name, pr.version, pr.first_seen_at FROM package_releases pr
+	          WHERE EXISTS (SELECT 1 FROM dependencies d WHERE d.ecosystem = pr.ecosystem AND d.name = pr.name)`
+	var args []interface{}
+	if ecosystem != "" {
+		query += " AND pr.ecosystem = ?"
+		args = append(args, ecosystem)
+	}
+	query += " ORDER BY pr.ecosystem, pr.name, pr.first_seen_at, pr.version"
+
+	var releases []domain.PackageRelease
+	if err := r.db.SelectContext(ctx, &releases, query, args...); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
 func (r *DependencyRepository) GetAll(ctx context.Context) ([]domain.DependencyWithRepo, error) {
-	query := `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, s.name as source_name
+	query := `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, r.owners as repo_owners, s.name as source_name
               FROM dependencies d
               JOIN repositories r ON d.repository_id = r.id
               JOIN sources s ON r.source_id = s.id
@@ -62,7 +606,7 @@ func (r *DependencyRepository) GetAll(ctx context.Context) ([]domain.DependencyW
 	return deps, nil
 }
 
-func (r *DependencyRepository) GetPaginated(ctx context.Context, page, limit int, statusFilter, repoFilter, ecosystemFilter, search string) (*domain.PaginatedDependencies, error) {
+func (r *DependencyRepository) GetPaginated(ctx context.Context, page, limit int, statusFilter, repoFilter, ecosystemFilter, search, sort string) (*domain.PaginatedDependencies, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -71,53 +615,29 @@ func (r *DependencyRepository) GetPaginated(ctx context.Context, page, limit int
 	}
 	offset := (page - 1) * limit
 
-	// Build WHERE clause
-	where := "1=1"
-	args := []interface{}{}
-
-	// Status filter
-	switch statusFilter {
-	case "upgradable":
-		where += " AND d.is_outdated = TRUE"
-	case "uptodate":
-		where += " AND d.is_outdated = FALSE"
-	case "prod":
-		where += " AND d.type = 'dependency'"
-	case "dev":
-		where += " AND d.type = 'devDependency'"
-	}
-
-	if repoFilter != "" {
-		where += " AND r.full_name = ?"
-		args = append(args, repoFilter)
-	}
-	if ecosystemFilter != "" {
-		where += " AND d.ecosystem = ?"
-		args = append(args, ecosystemFilter)
-	}
-	if search != "" {
-		where += " AND (d.name LIKE ? OR r.full_name LIKE ?)"
-		searchPattern := "%" + search + "%"
-		args = append(args, searchPattern, searchPattern)
+	match, err := r.matchSearch(ctx, search)
+	if err != nil {
+		return nil, err
 	}
+	where, args := paginatedWhere(statusFilter, repoFilter, ecosystemFilter, match)
 
 	// Get total count
 	countQuery := `SELECT COUNT(*) FROM dependencies d
                    JOIN repositories r ON d.repository_id = r.id
                    WHERE ` + where
 	var total int
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	err = r.db.GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get paginated data
-	dataQuery := `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, s.name as source_name
+	dataQuery := `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, r.owners as repo_owners, s.name as source_name
                   FROM dependencies d
                   JOIN repositories r ON d.repository_id = r.id
                   JOIN sources s ON r.source_id = s.id
                   WHERE ` + where + `
-                  ORDER BY d.name
+                  ORDER BY ` + dependencyOrder(sort) + `
                   LIMIT ? OFFSET ?`
 	args = append(args, limit, offset)
 
@@ -141,12 +661,175 @@ func (r *DependencyRepository) GetPaginated(ctx context.Context, page, limit int
 	}, nil
 }
 
+// dependencyTieBreak orders the dependencies a sort considers equal by their unique key, so lists
+// and exports of unchanged data come out in the same order every time
+const dependencyTieBreak = "r.full_name, d.manifest_path, d.name, d.type, d.repository_id"
+
+// dependencyOrder returns the ORDER BY clause of a sort of the dependency list (see
+// domain.DependencySorts), sorting by name by default
+func dependencyOrder(sort string) string {
+	switch sort {
+	case domain.SortRepo:
+		return "r.full_name, d.name, " + dependencyTieBreak
+	case domain.SortEcosystem:
+		return "d.ecosystem, d.name, " + dependencyTieBreak
+	case domain.SortOutdatedSince:
+		return "d.first_outdated_at IS NULL, d.first_outdated_at, d.name, " + dependencyTieBreak
+	}
+	return "d.name, " + dependencyTieBreak
+}
+
+// maxFuzzyNames caps the package names a search matches despite typos
+const maxFuzzyNames = 500
+
+// searchMatch is a search of the dependency list: dependencies match when the text is part of
+// their package or repository name, or when their package is one of names, matched with typos
+type searchMatch struct {
+	text  string
+	names []string
+}
+
+// matchSearch finds the package names matching a search despite typos or partial words (see
+// fuzzy.Match)
+func (r *DependencyRepository) matchSearch(ctx context.Context, search string) (searchMatch, error) {
+	if search == "" {
+		return searchMatch{}, nil
+	}
+	names, err := r.GetPackageNames(ctx)
+	if err != nil {
+		return searchMatch{}, err
+	}
+	return searchMatch{text: search, names: fuzzy.Rank(search, names, maxFuzzyNames)}, nil
+}
+
+// condition returns the condition of the search over dependencies d joined with their
+// repositories r, empty when there is no search
+func (m searchMatch) condition() (string, []interface{}) {
+	if m.text == "" {
+		return "", nil
+	}
+	pattern := "%" + m.text + "%"
+	cond, args := "d.name LIKE ? OR r.full_name LIKE ?", []interface{}{pattern, pattern}
+	if len(m.names) > 0 {
+		cond += " OR d.name IN (?" + strings.Repeat(", ?", len(m.names)-1) + ")"
+		for _, name := range m.names {
+			args = append(args, name)
+		}
+	}
+	return "(" + cond + ")", args
+}
+
+// paginatedWhere builds the WHERE clause of the filters of the dependency list, over
+// dependencies d joined with their repositories r
+func paginatedWhere(statusFilter, repoFilter, ecosystemFilter string, search searchMatch) (string, []interface{}) {
+	where := "1=1"
+	args := []interface{}{}
+
+	// Status filter
+	if cond := statusCondition(statusFilter); cond != "" {
+		where += " AND " + cond
+	}
+
+	if repoFilter != "" {
+		where += " AND r.full_name = ?"
+		args = append(args, repoFilter)
+	}
+	if ecosystemFilter != "" {
+		where += " AND d.ecosystem = ?"
+		args = append(args, ecosystemFilter)
+	}
+	if cond, searchArgs := search.condition(); cond != "" {
+		where += " AND " + cond
+		args = append(args, searchArgs...)
+	}
+	return where, args
+}
+
+// GetGrouped aggregates the dependencies matching the filters of GetPaginated by package,
+// repository or ecosystem (see domain.GroupByPackage), ordered by outdated dependencies. The
+// version range of a package only considers semantic versions.
+func (r *DependencyRepository) GetGrouped(ctx context.Context, by, statusFilter, repoFilter, ecosystemFilter, search string) ([]domain.DependencyGroup, error) {
+	var name, ecosystem string
+	switch by {
+	case domain.GroupByPackage:
+		name, ecosystem = "d.name", "d.ecosystem"
+	case domain.GroupByRepo:
+		name, ecosystem = "r.full_name", "''"
+	case domain.GroupByEcosystem:
+		name, ecosystem = "d.ecosystem", "''"
+	default:
+		return nil, fmt.Errorf("unknown grouping %q", by)
+	}
+
+	match, err := r.matchSearch(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+	where, args := paginatedWhere(statusFilter, repoFilter, ecosystemFilter, match)
+
+	groups := []domain.DependencyGroup{}
+	err = r.db.SelectContext(ctx, &groups,
+		`SELECT `+name+` AS name, `+ecosystem+` AS ecosystem, COUNT(*) AS dependencies,
+                COALESCE(SUM(CASE WHEN d.is_outdated = TRUE THEN 1 ELSE 0 END), 0) AS outdated,
+                COUNT(DISTINCT d.repository_id) AS repositories,
+                COUNT(DISTINCT d.ecosystem || ':' || d.name) AS packages
+         FROM dependencies d
+         JOIN repositories r ON d.repository_id = r.id
+         WHERE `+where+`
+         GROUP BY 1, 2
+         ORDER BY outdated DESC, dependencies DESC, name`, args...)
+	if err != nil || by != domain.GroupByPackage {
+		return groups, err
+	}
+
+	var versions []struct {
+		Name           string `db:"name"`
+		Ecosystem      string `db:"ecosystem"`
+		CurrentVersion string `db:"current_version"`
+		LatestVersion  string `db:"latest_version"`
+	}
+	err = r.db.SelectContext(ctx, &versions,
+		`SELECT DISTINCT d.name, d.ecosystem, d.current_version, COALESCE(d.latest_version, '') AS latest_version
+         FROM dependencies d
+         JOIN repositories r ON d.repository_id = r.id
+         WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]*domain.DependencyGroup, len(groups))
+	for i := range groups {
+		index[groups[i].Ecosystem+":"+groups[i].Name] = &groups[i]
+	}
+	for _, v := range versions {
+		g := index[v.Ecosystem+":"+v.Name]
+		if g == nil {
+			continue
+		}
+		if current, err := semver.NewVersion(v.CurrentVersion); err == nil {
+			if g.MinVersion == "" || current.LessThan(semver.MustParse(g.MinVersion)) {
+				g.MinVersion = v.CurrentVersion
+			}
+			if g.MaxVersion == "" || current.GreaterThan(semver.MustParse(g.MaxVersion)) {
+				g.MaxVersion = v.CurrentVersion
+			}
+		}
+		if latest, err := semver.NewVersion(v.LatestVersion); err == nil {
+			if g.LatestVersion == "" || latest.GreaterThan(semver.MustParse(g.LatestVersion)) {
+				g.LatestVersion = v.LatestVersion
+			}
+		}
+	}
+	return groups, nil
+}
+
+// GetUpgradable returns the outdated dependencies of the repositories still present in their source
 func (r *DependencyRepository) GetUpgradable(ctx context.Context) ([]domain.DependencyWithRepo, error) {
-	query := `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, s.name as source_name
+	query := `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, r.owners as repo_owners, s.name as source_name
               FROM dependencies d
               JOIN repositories r ON d.repository_id = r.id
               JOIN sources s ON r.source_id = s.id
-              WHERE d.is_outdated = TRUE
+              WHERE d.is_outdated = TRUE AND r.removed_at IS NULL
               ORDER BY d.name`
 
 	var deps []domain.DependencyWithRepo
@@ -157,15 +840,34 @@ func (r *DependencyRepository) GetUpgradable(ctx context.Context) ([]domain.Depe
 	return deps, nil
 }
 
-func (r *DependencyRepository) GetStats(ctx context.Context) (*domain.DependencyStats, error) {
+// statsFrom joins dependencies with their repository, so repositories removed from their source
+// can be left out of the stats
+const statsFrom = "dependencies d JOIN repositories r ON d.repository_id = r.id"
+
+// GetStats summarizes the dependencies of the repositories still present in their source.
+// Excluded dependencies are left out of every count but the per-type and per-scope counts.
+func (r *DependencyRepository) GetStats(ctx context.Context, exclude domain.StatsExclusions) (*domain.DependencyStats, error) {
 	var total, outdated int
 
-	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM dependencies")
+	where, args := "r.removed_at IS NULL", []interface{}{}
+	notIn := func(column string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		where += " AND " + column + " NOT IN (?" + strings.Repeat(", ?", len(values)-1) + ")"
+		for _, v := range values {
+			args = append(args, v)
+		}
+	}
+	notIn("d.type", exclude.Types)
+	notIn("d.scope", exclude.Scopes)
+
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM "+statsFrom+" WHERE "+where, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	err = r.db.GetContext(ctx, &outdated, "SELECT COUNT(*) FROM dependencies WHERE is_outdated = TRUE")
+	err = r.db.GetContext(ctx, &outdated, "SELECT COUNT(*) FROM "+statsFrom+" WHERE d.is_outdated = TRUE AND "+where, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +878,7 @@ func (r *DependencyRepository) GetStats(ctx context.Context) (*domain.Dependency
 	}
 	var typeCounts []typeCount
 	err = r.db.SelectContext(ctx, &typeCounts,
-		"SELECT type, COUNT(*) as count FROM dependencies GROUP BY type")
+		"SELECT d.type, COUNT(*) as count FROM "+statsFrom+" WHERE r.removed_at IS NULL GROUP BY d.type")
 	if err != nil {
 		return nil, err
 	}
@@ -186,12 +888,90 @@ func (r *DependencyRepository) GetStats(ctx context.Context) (*domain.Dependency
 		byType[tc.Type] = tc.Count
 	}
 
-	return &domain.DependencyStats{
+	var scopeCounts []typeCount
+	err = r.db.SelectContext(ctx, &scopeCounts,
+		"SELECT d.scope as type, COUNT(*) as count FROM "+statsFrom+" WHERE d.scope <> '' AND r.removed_at IS NULL GROUP BY d.scope")
+	if err != nil {
+		return nil, err
+	}
+	byScope := make(map[string]int)
+	for _, sc := range scopeCounts {
+		byScope[sc.Type] = sc.Count
+	}
+
+	stats := &domain.DependencyStats{
 		TotalDependencies: total,
 		OutdatedCount:     outdated,
 		UpToDateCount:     total - outdated,
 		ByType:            byType,
-	}, nil
+		ByScope:           byScope,
+		ExcludedTypes:     exclude.Types,
+		ExcludedScopes:    exclude.Scopes,
+	}
+
+	// A package is identified by its name within an ecosystem
+	err = r.db.GetContext(ctx, &stats.DistinctPackages,
+		"SELECT COUNT(*) FROM (SELECT DISTINCT d.name, d.ecosystem FROM "+statsFrom+" WHERE "+where+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.GetContext(ctx, &stats.DistinctOutdatedPackages,
+		"SELECT COUNT(*) FROM (SELECT DISTINCT d.name, d.ecosystem FROM "+statsFrom+" WHERE d.is_outdated = TRUE AND "+where+")", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.MostUsedOutdated = []domain.OutdatedPackageUsage{}
+	err = r.db.SelectContext(ctx, &stats.MostUsedOutdated,
+		`SELECT d.name, d.ecosystem, MAX(COALESCE(d.latest_version, '')) as latest_version,
+                COUNT(DISTINCT d.repository_id) as repositories, COUNT(*) as occurrences
+         FROM `+statsFrom+`
+         WHERE d.is_outdated = TRUE AND `+where+`
+         GROUP BY d.name, d.ecosystem
+         ORDER BY repositories DESC, occurrences DESC, d.name
+         LIMIT ?`, append(args, statsTopPackages)...)
+	if err != nil {
+		return nil, err
+	}
+
+	const counts = `COUNT(*) as total, COALESCE(SUM(CASE WHEN d.is_outdated = TRUE THEN 1 ELSE 0 END), 0) as outdated`
+	stats.ByEcosystem, err = r.breakdown(ctx, `SELECT d.ecosystem as name, `+counts+`
+        FROM `+statsFrom+` WHERE `+where+` GROUP BY d.ecosystem ORDER BY total DESC, name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	stats.BySource, err = r.breakdown(ctx, `SELECT s.name as name, `+counts+`
+        FROM `+statsFrom+`
+        JOIN sources s ON r.source_id = s.id
+        WHERE `+where+`
+        GROUP BY s.id ORDER BY total DESC, name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	stats.TopRepositories, err = r.breakdown(ctx, `SELECT r.full_name as name, `+counts+`
+        FROM `+statsFrom+`
+        WHERE `+where+`
+        GROUP BY r.id ORDER BY outdated DESC, total DESC, name
+        LIMIT ?`, append(args, statsTopRepositories)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// Number of repositories and packages listed in the stats rankings
+const (
+	statsTopRepositories = 10
+	statsTopPackages     = 10
+)
+
+func (r *DependencyRepository) breakdown(ctx context.Context, query string, args ...interface{}) ([]domain.StatsBreakdown, error) {
+	groups := []domain.StatsBreakdown{}
+	if err := r.db.SelectContext(ctx, &groups, query, args...); err != nil {
+		return nil, err
+	}
+	return groups, nil
 }
 
 func (r *DependencyRepository) DeleteByRepoID(ctx context.Context, repoID int64) error {
@@ -211,9 +991,10 @@ func (r *DependencyRepository) DeleteStaleByRepoID(ctx context.Context, repoID i
 	return result.RowsAffected()
 }
 
+// Count returns the number of dependencies of the repositories still present in their source
 func (r *DependencyRepository) Count(ctx context.Context) (int, error) {
 	var count int
-	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM dependencies")
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM "+statsFrom+" WHERE r.removed_at IS NULL")
 	return count, err
 }
 
@@ -226,7 +1007,7 @@ func (r *DependencyRepository) DeleteBySourceID(ctx context.Context, sourceID in
 
 // GetFiltered returns dependencies with database-level filtering for better performance
 func (r *DependencyRepository) GetFiltered(ctx context.Context, filter, repoFilter string) ([]domain.DependencyWithRepo, error) {
-	query := `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, s.name as source_name
+	query := `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, r.owners as repo_owners, s.name as source_name
               FROM dependencies d
               JOIN repositories r ON d.repository_id = r.id
               JOIN sources s ON r.source_id = s.id
@@ -240,15 +1021,8 @@ func (r *DependencyRepository) GetFiltered(ctx context.Context, filter, repoFilt
 	}
 
 	// Apply status filter
-	switch filter {
-	case "upgradable":
-		query += " AND d.is_outdated = TRUE"
-	case "uptodate":
-		query += " AND d.is_outdated = FALSE"
-	case "prod":
-		query += " AND d.type = 'dependency'"
-	case "dev":
-		query += " AND d.type = 'devDependency'"
+	if cond := statusCondition(filter); cond != "" {
+		query += " AND " + cond
 	}
 
 	query += " ORDER BY d.name"
@@ -261,6 +1035,42 @@ func (r *DependencyRepository) GetFiltered(ctx context.Context, filter, repoFilt
 	return deps, nil
 }
 
+// statusCondition returns the SQL condition on the dependencies table (aliased d) of a status
+// filter, or an empty string when the filter is unknown or empty
+func statusCondition(filter string) string {
+	switch filter {
+	case "upgradable":
+		return "d.is_outdated = TRUE"
+	case "uptodate":
+		return "d.is_outdated = FALSE"
+	case "prod":
+		// Provided dependencies are supplied by the runtime environment and are not shipped
+		return "d.type = 'dependency' AND d.scope <> 'provided'"
+	case "dev":
+		return "d.type = 'devDependency'"
+	case "peer":
+		return "d.type = 'peerDependency'"
+	case "optional":
+		return "d.type = 'optionalDependency'"
+	case "bundled":
+		return "d.type = 'bundledDependency'"
+	case "provided":
+		return "d.scope = 'provided'"
+	case "risky":
+		return "d.risks <> ''"
+	case "single_maintainer":
+		return `EXISTS (SELECT 1 FROM package_maintainers pm
+			WHERE pm.ecosystem = d.ecosystem AND pm.name = d.name AND pm.maintainer_count = 1)`
+	}
+	return ""
+}
+
+// IsStatusFilter reports whether a status filter of the dependency list is known. An empty
+// filter or "all" lists every dependency.
+func IsStatusFilter(filter string) bool {
+	return filter == "" || filter == "all" || statusCondition(filter) != ""
+}
+
 // GetRepositoryNames returns all repository full names for dropdowns
 func (r *DependencyRepository) GetRepositoryNames(ctx context.Context) ([]string, error) {
 	query := `SELECT full_name FROM repositories ORDER BY full_name`
@@ -293,7 +1103,7 @@ func (r *DependencyRepository) MarkPreviouslyOutdated(ctx context.Context) error
 
 // GetNewlyOutdated returns dependencies that became outdated in the latest scan
 func (r *DependencyRepository) GetNewlyOutdated(ctx context.Context) ([]domain.DependencyWithRepo, error) {
-	query := `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, s.name as source_name
+	query := `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, r.owners as repo_owners, s.name as source_name
               FROM dependencies d
               JOIN repositories r ON d.repository_id = r.id
               JOIN sources s ON r.source_id = s.id
@@ -392,7 +1202,12 @@ func (r *DependencyRepository) GetFilterOptions(ctx context.Context, repoFilter,
 
 // GetFilteredWithAll returns dependencies with all filter options for CSV export
 func (r *DependencyRepository) GetFilteredWithAll(ctx context.Context, filter, repoFilter, packageFilter, ecosystemFilter, searchFilter string) ([]domain.DependencyWithRepo, error) {
-	query := `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, s.name as source_name
+	return r.GetFilteredSorted(ctx, filter, repoFilter, packageFilter, ecosystemFilter, searchFilter, "")
+}
+
+// GetFilteredSorted is GetFilteredWithAll in one of the orders of domain.DependencySorts
+func (r *DependencyRepository) GetFilteredSorted(ctx context.Context, filter, repoFilter, packageFilter, ecosystemFilter, searchFilter, sort string) ([]domain.DependencyWithRepo, error) {
+	query := `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, r.owners as repo_owners, s.name as source_name
               FROM dependencies d
               JOIN repositories r ON d.repository_id = r.id
               JOIN sources s ON r.source_id = s.id
@@ -418,28 +1233,24 @@ func (r *DependencyRepository) GetFilteredWithAll(ctx context.Context, filter, r
 	}
 
 	// Apply search filter
-	if searchFilter != "" {
-		query += " AND (d.name LIKE ? OR r.full_name LIKE ?)"
-		searchPattern := "%" + searchFilter + "%"
-		args = append(args, searchPattern, searchPattern)
+	match, err := r.matchSearch(ctx, searchFilter)
+	if err != nil {
+		return nil, err
+	}
+	if cond, searchArgs := match.condition(); cond != "" {
+		query += " AND " + cond
+		args = append(args, searchArgs...)
 	}
 
 	// Apply status filter
-	switch filter {
-	case "upgradable":
-		query += " AND d.is_outdated = TRUE"
-	case "uptodate":
-		query += " AND d.is_outdated = FALSE"
-	case "prod":
-		query += " AND d.type = 'dependency'"
-	case "dev":
-		query += " AND d.type = 'devDependency'"
+	if cond := statusCondition(filter); cond != "" {
+		query += " AND " + cond
 	}
 
-	query += " ORDER BY d.name"
+	query += " ORDER BY " + dependencyOrder(sort)
 
 	var deps []domain.DependencyWithRepo
-	err := r.db.SelectContext(ctx, &deps, query, args...)
+	err = r.db.SelectContext(ctx, &deps, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/util"
+	"github.com/jmoiron/sqlx"
+)
+
+type EventRepository struct {
+	db *sqlx.DB
+}
+
+func NewEventRepository(db *sqlx.DB) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+// Create records an event. Payloads may hold source tokens, so they are stored encrypted. The
+// payloads of events that can no longer be undone are dropped.
+func (r *EventRepository) Create(ctx context.Context, event domain.Event) (*domain.Event, error) {
+	payload, err := util.Encrypt(event.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if _, err := r.db.ExecContext(ctx, "UPDATE events SET payload = '' WHERE payload != '' AND created_at < ?",
+		now.Add(-domain.EventUndoWindow)); err != nil {
+		return nil, err
+	}
+
+	query := `INSERT INTO events (type, entity_id, summary, payload, created_at)
+              VALUES (?, ?, ?, ?, ?)
+              RETURNING *`
+
+	var created domain.Event
+	if err := r.db.GetContext(ctx, &created, query, event.Type, event.EntityID, event.Summary, payload, now); err != nil {
+		return nil, err
+	}
+	created.Payload = event.Payload
+	return &created, nil
+}
+
+// GetRecent returns the latest events, newest first, without their payloads
+func (r *EventRepository) GetRecent(ctx context.Context, limit int) ([]domain.Event, error) {
+	var events []domain.Event
+	err := r.db.SelectContext(ctx, &events,
+		"SELECT id, type, entity_id, summary, '' AS payload, created_at, undone_at FROM events ORDER BY created_at DESC, id DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *EventRepository) GetByID(ctx context.Context, id int64) (*domain.Event, error) {
+	var event domain.Event
+	if err := r.db.GetContext(ctx, &event, "SELECT * FROM events WHERE id = ?", id); err != nil {
+		return nil, err
+	}
+
+	payload, err := util.Decrypt(event.Payload)
+	if err != nil {
+		return nil, err
+	}
+	event.Payload = payload
+	return &event, nil
+}
+
+// MarkUndone records that the change of an event was reverted. It returns false when the event
+// was already undone, so concurrent undos revert a change once.
+func (r *EventRepository) MarkUndone(ctx context.Context, id int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE events SET undone_at = ? WHERE id = ? AND undone_at IS NULL", time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// UnmarkUndone reverts MarkUndone when the change of an event could not be reverted
+func (r *EventRepository) UnmarkUndone(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE events SET undone_at = NULL WHERE id = ?", id)
+	return err
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func setupEventTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test db: %v", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL,
+			entity_id INTEGER NOT NULL,
+			summary TEXT NOT NULL DEFAULT '',
+			payload TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			undone_at DATETIME
+		)
+	`)
+	if err != nil {
+		t.Fatalf("failed to create tables: %v", err)
+	}
+	return db
+}
+
+func TestEventRepository_Payload(t *testing.T) {
+	db := setupEventTestDB(t)
+	defer db.Close()
+	repo := NewEventRepository(db)
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, domain.Event{Type: domain.EventSourceDeleted, EntityID: 3, Summary: "acme", Payload: `{"token":"secret"}`})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	var stored string
+	if err := db.Get(&stored, "SELECT payload FROM events WHERE id = ?", created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if stored == `{"token":"secret"}` {
+		t.Error("payload is stored in plaintext")
+	}
+
+	event, err := repo.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if event.Payload != `{"token":"secret"}` || event.Summary != "acme" || event.EntityID != 3 {
+		t.Errorf("GetByID() = %+v", event)
+	}
+
+	events, err := repo.GetRecent(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetRecent() error = %v", err)
+	}
+	if len(events) != 1 || events[0].Payload != "" {
+		t.Errorf("GetRecent() = %+v, want one event without payload", events)
+	}
+}
+
+func TestEventRepository_DropsExpiredPayloads(t *testing.T) {
+	db := setupEventTestDB(t)
+	defer db.Close()
+	repo := NewEventRepository(db)
+	ctx := context.Background()
+
+	old, err := repo.Create(ctx, domain.Event{Type: domain.EventIgnoreDeleted, EntityID: 1, Payload: `{"name":"lodash"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("UPDATE events SET created_at = ? WHERE id = ?", time.Now().Add(-2*domain.EventUndoWindow), old.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Create(ctx, domain.Event{Type: domain.EventIgnoreCreated, EntityID: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := repo.GetByID(ctx, old.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Payload != "" {
+		t.Errorf("payload = %q, want it dropped after the undo window", event.Payload)
+	}
+}
+
+func TestEventRepository_MarkUndone(t *testing.T) {
+	db := setupEventTestDB(t)
+	defer db.Close()
+	repo := NewEventRepository(db)
+	ctx := context.Background()
+
+	event, err := repo.Create(ctx, domain.Event{Type: domain.EventIgnoreDeleted, EntityID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := repo.MarkUndone(ctx, event.ID); err != nil || !ok {
+		t.Fatalf("MarkUndone() = %v, %v, want true", ok, err)
+	}
+	if ok, err := repo.MarkUndone(ctx, event.ID); err != nil || ok {
+		t.Errorf("second MarkUndone() = %v, %v, want false", ok, err)
+	}
+
+	if err := repo.UnmarkUndone(ctx, event.ID); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := repo.MarkUndone(ctx, event.ID); !ok {
+		t.Error("MarkUndone() after UnmarkUndone() = false, want true")
+	}
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type FeatureFlagRepository struct {
+	db *sqlx.DB
+}
+
+func NewFeatureFlagRepository(db *sqlx.DB) *FeatureFlagRepository {
+	return &FeatureFlagRepository{db: db}
+}
+
+// GetAll returns the flags set on this instance by name
+func (r *FeatureFlagRepository) GetAll(ctx context.Context) (map[string]bool, error) {
+	var rows []struct {
+		Name    string `db:"name"`
+		Enabled bool   `db:"enabled"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, "SELECT name, enabled FROM feature_flags"); err != nil {
+		return nil, err
+	}
+
+	flags := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		flags[row.Name] = row.Enabled
+	}
+	return flags, nil
+}
+
+// Set enables or disables a flag
+func (r *FeatureFlagRepository) Set(ctx context.Context, name string, enabled bool) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO feature_flags (name, enabled, updated_at) VALUES (?, ?, ?)
+         ON CONFLICT(name) DO UPDATE SET enabled = excluded.enabled, updated_at = excluded.updated_at`,
+		name, enabled, time.Now())
+	return err
+}
+
+// Delete removes a flag so its default applies again
+func (r *FeatureFlagRepository) Delete(ctx context.Context, name string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM feature_flags WHERE name = ?", name)
+	return err
+}
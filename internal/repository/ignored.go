@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/jiin/stale/internal/domain"
 	"github.com/jmoiron/sqlx"
@@ -18,50 +19,100 @@ func NewIgnoredRepository(db *sqlx.DB) *IgnoredRepository {
 func (r *IgnoredRepository) GetAll(ctx context.Context) ([]domain.IgnoredDependency, error) {
 	var ignored []domain.IgnoredDependency
 	err := r.db.SelectContext(ctx, &ignored,
-		"SELECT * FROM ignored_dependencies ORDER BY name")
+		"SELECT * FROM ignored_dependencies ORDER BY name, ecosystem, repository")
 	if err != nil {
 		return nil, err
 	}
 	return ignored, nil
 }
 
+func (r *IgnoredRepository) GetByID(ctx context.Context, id int64) (*domain.IgnoredDependency, error) {
+	var ignored domain.IgnoredDependency
+	if err := r.db.GetContext(ctx, &ignored, "SELECT * FROM ignored_dependencies WHERE id = ?", id); err != nil {
+		return nil, err
+	}
+	return &ignored, nil
+}
+
 func (r *IgnoredRepository) Create(ctx context.Context, input *domain.IgnoredDependencyInput) (*domain.IgnoredDependency, error) {
-	result, err := r.db.ExecContext(ctx,
-		"INSERT INTO ignored_dependencies (name, ecosystem, reason) VALUES (?, ?, ?)",
-		input.Name, input.Ecosystem, input.Reason)
+	return createIgnored(ctx, r.db, input)
+}
+
+func createIgnored(ctx context.Context, q queryer, input *domain.IgnoredDependencyInput) (*domain.IgnoredDependency, error) {
+	// Expiry times are stored in UTC so they compare correctly with time.Now().UTC()
+	var expiresAt *time.Time
+	if input.ExpiresAt != nil {
+		utc := input.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	result, err := q.ExecContext(ctx,
+		"INSERT INTO ignored_dependencies (name, ecosystem, reason, repository, expires_at) VALUES (?, ?, ?, ?, ?)",
+		input.Name, input.Ecosystem, input.Reason, input.Repository, expiresAt)
 	if err != nil {
 		return nil, err
 	}
 
 	id, _ := result.LastInsertId()
 	return &domain.IgnoredDependency{
-		ID:        id,
-		Name:      input.Name,
-		Ecosystem: input.Ecosystem,
-		Reason:    input.Reason,
+		ID:         id,
+		Name:       input.Name,
+		Ecosystem:  input.Ecosystem,
+		Reason:     input.Reason,
+		Repository: input.Repository,
+		ExpiresAt:  expiresAt,
 	}, nil
 }
 
+// updateIgnored changes the reason and expiry of an ignore rule
+func updateIgnored(ctx context.Context, q queryer, id int64, input *domain.IgnoredDependencyInput) error {
+	var expiresAt *time.Time
+	if input.ExpiresAt != nil {
+		utc := input.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+	_, err := q.ExecContext(ctx, "UPDATE ignored_dependencies SET reason = ?, expires_at = ? WHERE id = ?", input.Reason, expiresAt, id)
+	return err
+}
+
 func (r *IgnoredRepository) Delete(ctx context.Context, id int64) error {
 	_, err := r.db.ExecContext(ctx, "DELETE FROM ignored_dependencies WHERE id = ?", id)
 	return err
 }
 
+// IsIgnored reports whether a dependency is ignored in every repository
 func (r *IgnoredRepository) IsIgnored(ctx context.Context, name, ecosystem string) (bool, error) {
 	var count int
 	err := r.db.GetContext(ctx, &count,
-		"SELECT COUNT(*) FROM ignored_dependencies WHERE name = ? AND (ecosystem = ? OR ecosystem = '' OR ecosystem IS NULL)",
-		name, ecosystem)
+		"SELECT COUNT(*) FROM ignored_dependencies WHERE name = ? AND (ecosystem = ? OR ecosystem = '' OR ecosystem IS NULL) AND repository = '' AND (expires_at IS NULL OR expires_at > ?)",
+		name, ecosystem, time.Now().UTC())
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-// GetIgnoredNames returns a set of ignored dependency names for quick lookup
+// GetForDependency returns the unexpired ignore rules matching a dependency of a repository: those
+// of its package in any ecosystem or its own, for every repository or this one
+func (r *IgnoredRepository) GetForDependency(ctx context.Context, name, ecosystem, repoFullName string) ([]domain.IgnoredDependency, error) {
+	var ignored []domain.IgnoredDependency
+	err := r.db.SelectContext(ctx, &ignored,
+		`SELECT * FROM ignored_dependencies
+		 WHERE name = ? AND (ecosystem = ? OR ecosystem = '' OR ecosystem IS NULL)
+		   AND (repository = '' OR repository = ?) AND (expires_at IS NULL OR expires_at > ?)
+		 ORDER BY repository, ecosystem`,
+		name, ecosystem, repoFullName, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return ignored, nil
+}
+
+// GetIgnoredNames returns a set of the dependency names ignored in every repository for quick lookup
 func (r *IgnoredRepository) GetIgnoredNames(ctx context.Context) (map[string]bool, error) {
 	var ignored []domain.IgnoredDependency
-	err := r.db.SelectContext(ctx, &ignored, "SELECT name, ecosystem FROM ignored_dependencies")
+	err := r.db.SelectContext(ctx, &ignored,
+		"SELECT name, ecosystem FROM ignored_dependencies WHERE repository = '' AND (expires_at IS NULL OR expires_at > ?)", time.Now().UTC())
 	if err != nil {
 		return nil, err
 	}
@@ -76,3 +127,129 @@ func (r *IgnoredRepository) GetIgnoredNames(ctx context.Context) (map[string]boo
 	}
 	return result, nil
 }
+
+// GetExpiring returns the ignore rules that have not expired yet but expire before the given time,
+// soonest first
+func (r *IgnoredRepository) GetExpiring(ctx context.Context, before time.Time) ([]domain.IgnoredDependency, error) {
+	var ignored []domain.IgnoredDependency
+	err := r.db.SelectContext(ctx, &ignored,
+		`SELECT * FROM ignored_dependencies
+		 WHERE expires_at IS NOT NULL AND expires_at > ? AND expires_at <= ?
+		 ORDER BY expires_at, name`,
+		time.Now().UTC(), before.UTC())
+	if err != nil {
+		return nil, err
+	}
+	return ignored, nil
+}
+
+// DeleteExpired removes the ignore rules that expired at or before now so the dependencies are
+// reported again, and returns the removed rules
+func (r *IgnoredRepository) DeleteExpired(ctx context.Context, now time.Time) ([]domain.IgnoredDependency, error) {
+	var expired []domain.IgnoredDependency
+	err := r.db.SelectContext(ctx, &expired,
+		"DELETE FROM ignored_dependencies WHERE expires_at IS NOT NULL AND expires_at <= ? RETURNING *", now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// ReplaceSuggestions sets the ignore suggestions read from the configs of a repository. Suggestions
+// that are still present keep their dismissed state; the others are removed.
+func (r *IgnoredRepository) ReplaceSuggestions(ctx context.Context, repoID int64, suggestions []domain.IgnoreSuggestion) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var existing []domain.IgnoreSuggestion
+	if err := tx.SelectContext(ctx, &existing,
+		"SELECT id, name, ecosystem FROM ignore_suggestions WHERE repository_id = ?", repoID); err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool, len(suggestions))
+	for _, sg := range suggestions {
+		keep[sg.Name+":"+sg.Ecosystem] = true
+	}
+	for _, sg := range existing {
+		if !keep[sg.Name+":"+sg.Ecosystem] {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM ignore_suggestions WHERE id = ?", sg.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, sg := range suggestions {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO ignore_suggestions (repository_id, name, ecosystem, config_path, detail, detected_at)
+			 VALUES (?, ?, ?, ?, ?, ?)
+			 ON CONFLICT(repository_id, name, ecosystem) DO UPDATE SET
+			   config_path = excluded.config_path,
+			   detail = excluded.detail`,
+			repoID, sg.Name, sg.Ecosystem, sg.ConfigPath, sg.Detail, now); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetSuggestions returns the ignore suggestions not yet covered by an active ignore rule, by
+// repository and name. Dismissed suggestions are only included with includeDismissed.
+func (r *IgnoredRepository) GetSuggestions(ctx context.Context, includeDismissed bool) ([]domain.IgnoreSuggestion, error) {
+	query := `SELECT s.*, r.full_name AS repo_full_name
+		FROM ignore_suggestions s
+		JOIN repositories r ON r.id = s.repository_id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM ignored_dependencies ig
+			WHERE ig.name = s.name
+			  AND (ig.ecosystem = s.ecosystem OR ig.ecosystem = '' OR ig.ecosystem IS NULL)
+			  AND (ig.repository = '' OR ig.repository = r.full_name)
+			  AND (ig.expires_at IS NULL OR ig.expires_at > ?)
+		)`
+	if !includeDismissed {
+		query += " AND s.dismissed = 0"
+	}
+	query += " ORDER BY r.full_name, s.name"
+
+	var suggestions []domain.IgnoreSuggestion
+	if err := r.db.SelectContext(ctx, &suggestions, query, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// GetSuggestionsByIDs returns the ignore suggestions with the given IDs
+func (r *IgnoredRepository) GetSuggestionsByIDs(ctx context.Context, ids []int64) ([]domain.IgnoreSuggestion, error) {
+	query, args, err := sqlx.In(`SELECT s.*, r.full_name AS repo_full_name
+		FROM ignore_suggestions s
+		JOIN repositories r ON r.id = s.repository_id
+		WHERE s.id IN (?)
+		ORDER BY r.full_name, s.name`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var suggestions []domain.IgnoreSuggestion
+	if err := r.db.SelectContext(ctx, &suggestions, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// SetSuggestionsDismissed dismisses (or restores) ignore suggestions and returns how many were updated
+func (r *IgnoredRepository) SetSuggestionsDismissed(ctx context.Context, ids []int64, dismissed bool) (int64, error) {
+	query, args, err := sqlx.In("UPDATE ignore_suggestions SET dismissed = ? WHERE id IN (?)", dismissed, ids)
+	if err != nil {
+		return 0, err
+	}
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
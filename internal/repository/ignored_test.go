@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jiin/stale/internal/domain"
 	"github.com/jmoiron/sqlx"
@@ -22,8 +23,25 @@ func setupTestDB(t *testing.T) *sqlx.DB {
 			name TEXT NOT NULL,
 			ecosystem TEXT,
 			reason TEXT,
+			repository TEXT NOT NULL DEFAULT '',
+			expires_at DATETIME,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			UNIQUE(name, ecosystem)
+			UNIQUE(name, ecosystem, repository)
+		);
+		CREATE TABLE IF NOT EXISTS repositories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			full_name TEXT NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS ignore_suggestions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			ecosystem TEXT NOT NULL DEFAULT '',
+			config_path TEXT NOT NULL,
+			detail TEXT NOT NULL DEFAULT '',
+			dismissed BOOLEAN NOT NULL DEFAULT 0,
+			detected_at DATETIME NOT NULL,
+			UNIQUE(repository_id, name, ecosystem)
 		)
 	`)
 	if err != nil {
@@ -212,3 +230,117 @@ func TestIgnoredRepository_UniqueConstraint(t *testing.T) {
 		t.Errorf("Different ecosystem Create() should succeed, got error = %v", err)
 	}
 }
+
+func TestIgnoredRepository_Expiration(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewIgnoredRepository(db)
+	ctx := context.Background()
+
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	soon := now.Add(3 * 24 * time.Hour)
+	later := now.Add(30 * 24 * time.Hour)
+	repo.Create(ctx, &domain.IgnoredDependencyInput{Name: "expired", ExpiresAt: &past})
+	repo.Create(ctx, &domain.IgnoredDependencyInput{Name: "soon", ExpiresAt: &soon})
+	repo.Create(ctx, &domain.IgnoredDependencyInput{Name: "later", ExpiresAt: &later})
+	repo.Create(ctx, &domain.IgnoredDependencyInput{Name: "forever"})
+
+	if ignored, _ := repo.IsIgnored(ctx, "expired", "npm"); ignored {
+		t.Error("IsIgnored() should be false for an expired rule")
+	}
+	if ignored, _ := repo.IsIgnored(ctx, "soon", "npm"); !ignored {
+		t.Error("IsIgnored() should be true until the rule expires")
+	}
+
+	expiring, err := repo.GetExpiring(ctx, now.Add(7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("GetExpiring() error = %v", err)
+	}
+	if len(expiring) != 1 || expiring[0].Name != "soon" {
+		t.Errorf("GetExpiring() = %+v, want only soon", expiring)
+	}
+
+	expired, err := repo.DeleteExpired(ctx, now)
+	if err != nil {
+		t.Fatalf("DeleteExpired() error = %v", err)
+	}
+	if len(expired) != 1 || expired[0].Name != "expired" {
+		t.Errorf("DeleteExpired() = %+v, want only expired", expired)
+	}
+
+	list, _ := repo.GetAll(ctx)
+	if len(list) != 3 {
+		t.Errorf("GetAll() after DeleteExpired() returned %d items, want 3", len(list))
+	}
+}
+
+func TestIgnoredRepository_Suggestions(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewIgnoredRepository(db)
+	ctx := context.Background()
+
+	db.MustExec("INSERT INTO repositories (id, full_name) VALUES (1, 'org/web'), (2, 'org/api')")
+	web := []domain.IgnoreSuggestion{
+		{Name: "lodash", Ecosystem: "npm", ConfigPath: "renovate.json", Detail: "ignoreDeps"},
+		{Name: "react", Ecosystem: "npm", ConfigPath: "renovate.json", Detail: "packageRules"},
+		{Name: "moment", ConfigPath: "renovate.json", Detail: "ignoreDeps"},
+	}
+	if err := repo.ReplaceSuggestions(ctx, 1, web); err != nil {
+		t.Fatalf("ReplaceSuggestions() error = %v", err)
+	}
+	if err := repo.ReplaceSuggestions(ctx, 2, []domain.IgnoreSuggestion{{Name: "lodash", Ecosystem: "npm", ConfigPath: ".github/dependabot.yml"}}); err != nil {
+		t.Fatalf("ReplaceSuggestions() error = %v", err)
+	}
+
+	suggestions, err := repo.GetSuggestions(ctx, false)
+	if err != nil {
+		t.Fatalf("GetSuggestions() error = %v", err)
+	}
+	if len(suggestions) != 4 || suggestions[0].RepoFullName != "org/api" {
+		t.Fatalf("GetSuggestions() = %+v, want 4 ordered by repository", suggestions)
+	}
+
+	// A rule scoped to org/web covers only that repository's suggestion
+	if _, err := repo.Create(ctx, &domain.IgnoredDependencyInput{Name: "lodash", Ecosystem: "npm", Repository: "org/web"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// The same dependency can be ignored in another repository
+	if _, err := repo.Create(ctx, &domain.IgnoredDependencyInput{Name: "lodash", Ecosystem: "npm", Repository: "org/other"}); err != nil {
+		t.Fatalf("Create() for another repository error = %v", err)
+	}
+	suggestions, _ = repo.GetSuggestions(ctx, false)
+	if len(suggestions) != 3 {
+		t.Fatalf("GetSuggestions() after scoped rule = %+v, want 3", suggestions)
+	}
+
+	// Dismissed suggestions keep their state when the configs are read again
+	var reactID int64
+	for _, sg := range suggestions {
+		if sg.Name == "react" {
+			reactID = sg.ID
+		}
+	}
+	if n, err := repo.SetSuggestionsDismissed(ctx, []int64{reactID}, true); err != nil || n != 1 {
+		t.Fatalf("SetSuggestionsDismissed() = %d, %v", n, err)
+	}
+	if err := repo.ReplaceSuggestions(ctx, 1, web[1:]); err != nil {
+		t.Fatalf("ReplaceSuggestions() error = %v", err)
+	}
+	suggestions, _ = repo.GetSuggestions(ctx, false)
+	if len(suggestions) != 2 {
+		t.Errorf("GetSuggestions() = %+v, want the org/api and moment suggestions", suggestions)
+	}
+	all, _ := repo.GetSuggestions(ctx, true)
+	if len(all) != 3 {
+		t.Errorf("GetSuggestions(includeDismissed) = %+v, want 3", all)
+	}
+
+	byID, err := repo.GetSuggestionsByIDs(ctx, []int64{reactID})
+	if err != nil || len(byID) != 1 || byID[0].RepoFullName != "org/web" || !byID[0].Dismissed {
+		t.Errorf("GetSuggestionsByIDs() = %+v, %v", byID, err)
+	}
+}
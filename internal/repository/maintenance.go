@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// orphanTables are the tables whose rows belong to a repository. Foreign keys remove these rows
+// with the repository, but databases written with foreign keys disabled can still hold orphans.
+var orphanTables = []string{
+	"dependencies",
+	"branch_dependencies",
+	"vulnerability_alerts",
+	"manifest_hashes",
+	"ignore_suggestions",
+	"notified_dependencies",
+	"skipped_dependencies",
+}
+
+// MaintenanceRepository runs integrity tasks over the whole database
+type MaintenanceRepository struct {
+	db *sqlx.DB
+}
+
+func NewMaintenanceRepository(db *sqlx.DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+// DeleteOrphans removes repositories of deleted sources and rows of deleted repositories,
+// returning the number of rows removed by table. Tables without orphans are left out.
+func (r *MaintenanceRepository) DeleteOrphans(ctx context.Context) (map[string]int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	removed := make(map[string]int64)
+	count := func(table string, result interface{ RowsAffected() (int64, error) }) {
+		if n, _ := result.RowsAffected(); n > 0 {
+			removed[table] = n
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM repositories WHERE source_id NOT IN (SELECT id FROM sources)")
+	if err != nil {
+		return nil, err
+	}
+	count("repositories", result)
+
+	for _, table := range orphanTables {
+		result, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE repository_id NOT IN (SELECT id FROM repositories)")
+		if err != nil {
+			return nil, err
+		}
+		count(table, result)
+	}
+
+	return removed, tx.Commit()
+}
+
+// Optimize refreshes the query planner statistics and rebuilds the database file to reclaim
+// the space of deleted rows
+func (r *MaintenanceRepository) Optimize(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, "ANALYZE"); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, "VACUUM")
+	return err
+}
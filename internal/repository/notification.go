@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// NotificationRepository remembers what notification emails reported and how they are threaded
+type NotificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewNotificationRepository(db *sqlx.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// notifiedKey identifies a reported dependency
+func notifiedKey(repoID int64, name, ecosystem string) string {
+	return strconv.FormatInt(repoID, 10) + ":" + ecosystem + ":" + name
+}
+
+// FilterNotified returns the dependencies that were not reported since the given time
+func (r *NotificationRepository) FilterNotified(ctx context.Context, deps []domain.DependencyWithRepo, since time.Time) ([]domain.DependencyWithRepo, error) {
+	var notified []struct {
+		RepositoryID int64  `db:"repository_id"`
+		Name         string `db:"name"`
+		Ecosystem    string `db:"ecosystem"`
+	}
+	if err := r.db.SelectContext(ctx, &notified,
+		"SELECT repository_id, name, ecosystem FROM notified_dependencies WHERE notified_at > ?", since.UTC()); err != nil {
+		return nil, err
+	}
+
+	recent := make(map[string]bool, len(notified))
+	for _, n := range notified {
+		recent[notifiedKey(n.RepositoryID, n.Name, n.Ecosystem)] = true
+	}
+
+	var filtered []domain.DependencyWithRepo
+	for _, dep := range deps {
+		if !recent[notifiedKey(dep.RepositoryID, dep.Name, dep.Ecosystem)] {
+			filtered = append(filtered, dep)
+		}
+	}
+	return filtered, nil
+}
+
+// MarkNotified records that the dependencies were reported at the given time
+func (r *NotificationRepository) MarkNotified(ctx context.Context, deps []domain.DependencyWithRepo, at time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, dep := range deps {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO notified_dependencies (repository_id, name, ecosystem, notified_at) VALUES (?, ?, ?, ?)
+			 ON CONFLICT(repository_id, name, ecosystem) DO UPDATE SET notified_at = excluded.notified_at`,
+			dep.RepositoryID, dep.Name, dep.Ecosystem, at.UTC()); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FilterFailuresNotified returns the scan failures whose source was not notified of a failure
+// since the given time
+func (r *NotificationRepository) FilterFailuresNotified(ctx context.Context, failures []domain.SourceScanFailure, since time.Time) ([]domain.SourceScanFailure, error) {
+	var notified []int64
+	if err := r.db.SelectContext(ctx, &notified,
+		"SELECT source_id FROM failure_notifications WHERE notified_at > ?", since.UTC()); err != nil {
+		return nil, err
+	}
+
+	recent := make(map[int64]bool, len(notified))
+	for _, id := range notified {
+		recent[id] = true
+	}
+
+	var filtered []domain.SourceScanFailure
+	for _, f := range failures {
+		if !recent[f.SourceID] {
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered, nil
+}
+
+// MarkFailuresNotified records that the failures of the sources were notified at the given time
+func (r *NotificationRepository) MarkFailuresNotified(ctx context.Context, failures []domain.SourceScanFailure, at time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, f := range failures {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT OR REPLACE INTO failure_notifications (source_id, notified_at) VALUES (?, ?)",
+			f.SourceID, at.UTC()); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FilterTokenWarningsNotified returns the token warnings whose source was not warned about since
+// the given time
+func (r *NotificationRepository) FilterTokenWarningsNotified(ctx context.Context, warnings []domain.SourceTokenWarning, since time.Time) ([]domain.SourceTokenWarning, error) {
+	var notified []int64
+	if err := r.db.SelectContext(ctx, &notified,
+		"SELECT source_id FROM token_notifications WHERE notified_at > ?", since.UTC()); err != nil {
+		return nil, err
+	}
+
+	recent := make(map[int64]bool, len(notified))
+	for _, id := range notified {
+		recent[id] = true
+	}
+
+	var filtered []domain.SourceTokenWarning
+	for _, w := range warnings {
+		if !recent[w.SourceID] {
+			filtered = append(filtered, w)
+		}
+	}
+	return filtered, nil
+}
+
+// MarkTokenWarningsNotified records that the sources of the token warnings were warned at the
+// given time
+func (r *NotificationRepository) MarkTokenWarningsNotified(ctx context.Context, warnings []domain.SourceTokenWarning, at time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, w := range warnings {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT OR REPLACE INTO token_notifications (source_id, notified_at) VALUES (?, ?)",
+			w.SourceID, at.UTC()); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetThread returns the first email of a notification thread, or nil when none was sent
+func (r *NotificationRepository) GetThread(ctx context.Context, thread string) (*domain.NotificationThread, error) {
+	var t domain.NotificationThread
+	err := r.db.GetContext(ctx, &t, "SELECT * FROM notification_threads WHERE thread = ?", thread)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SetThread starts a notification thread, replacing the previous one
+func (r *NotificationRepository) SetThread(ctx context.Context, t domain.NotificationThread) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT OR REPLACE INTO notification_threads (thread, message_id, started_at) VALUES (?, ?, ?)",
+		t.Thread, t.MessageID, t.StartedAt.UTC())
+	return err
+}
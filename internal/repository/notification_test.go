@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func setupNotificationTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test db: %v", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE notified_dependencies (
+			repository_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			ecosystem TEXT NOT NULL,
+			notified_at DATETIME NOT NULL,
+			PRIMARY KEY (repository_id, name, ecosystem)
+		);
+		CREATE TABLE notification_threads (
+			thread TEXT PRIMARY KEY,
+			message_id TEXT NOT NULL,
+			started_at DATETIME NOT NULL
+		);
+		CREATE TABLE failure_notifications (
+			source_id INTEGER PRIMARY KEY,
+			notified_at DATETIME NOT NULL
+		);
+		CREATE TABLE token_notifications (
+			source_id INTEGER PRIMARY KEY,
+			notified_at DATETIME NOT NULL
+		)
+	`)
+	if err != nil {
+		t.Fatalf("failed to create tables: %v", err)
+	}
+	return db
+}
+
+func TestNotificationRepository_FilterNotified(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	defer db.Close()
+
+	repo := NewNotificationRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	dep := func(repoID int64, name string) domain.DependencyWithRepo {
+		return domain.DependencyWithRepo{Dependency: domain.Dependency{RepositoryID: repoID, Name: name, Ecosystem: "npm"}}
+	}
+	if err := repo.MarkNotified(ctx, []domain.DependencyWithRepo{dep(1, "lodash")}, now.AddDate(0, 0, -10)); err != nil {
+		t.Fatalf("MarkNotified() error = %v", err)
+	}
+	if err := repo.MarkNotified(ctx, []domain.DependencyWithRepo{dep(1, "react")}, now.AddDate(0, 0, -2)); err != nil {
+		t.Fatalf("MarkNotified() error = %v", err)
+	}
+
+	deps := []domain.DependencyWithRepo{dep(1, "lodash"), dep(1, "react"), dep(2, "react"), dep(1, "vue")}
+	filtered, err := repo.FilterNotified(ctx, deps, now.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("FilterNotified() error = %v", err)
+	}
+	var names []string
+	for _, d := range filtered {
+		names = append(names, d.Name)
+	}
+	if len(filtered) != 3 || filtered[0].Name != "lodash" || filtered[1].RepositoryID != 2 || filtered[2].Name != "vue" {
+		t.Errorf("FilterNotified() = %v, want react of repository 1 left out", names)
+	}
+
+	// Reporting again moves the dependency into the window
+	if err := repo.MarkNotified(ctx, []domain.DependencyWithRepo{dep(1, "lodash")}, now); err != nil {
+		t.Fatalf("MarkNotified() error = %v", err)
+	}
+	filtered, _ = repo.FilterNotified(ctx, deps, now.AddDate(0, 0, -7))
+	if len(filtered) != 2 {
+		t.Errorf("FilterNotified() after reporting again = %d dependencies, want 2", len(filtered))
+	}
+}
+
+func TestNotificationRepository_FilterFailuresNotified(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	defer db.Close()
+
+	repo := NewNotificationRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	failures := []domain.SourceScanFailure{
+		{SourceID: 1, SourceName: "github", Error: "401 Unauthorized"},
+		{SourceID: 2, SourceName: "gitlab", Error: "connection refused"},
+		{Error: "database is locked"},
+	}
+	if err := repo.MarkFailuresNotified(ctx, failures[:1], now.Add(-time.Hour)); err != nil {
+		t.Fatalf("MarkFailuresNotified() error = %v", err)
+	}
+	if err := repo.MarkFailuresNotified(ctx, failures[1:2], now.Add(-12*time.Hour)); err != nil {
+		t.Fatalf("MarkFailuresNotified() error = %v", err)
+	}
+
+	filtered, err := repo.FilterFailuresNotified(ctx, failures, now.Add(-6*time.Hour))
+	if err != nil {
+		t.Fatalf("FilterFailuresNotified() error = %v", err)
+	}
+	if len(filtered) != 2 || filtered[0].SourceID != 2 || filtered[1].SourceID != 0 {
+		t.Errorf("FilterFailuresNotified() = %+v, want the source notified an hour ago left out", filtered)
+	}
+}
+
+func TestNotificationRepository_FilterTokenWarningsNotified(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	defer db.Close()
+
+	repo := NewNotificationRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	warnings := []domain.SourceTokenWarning{
+		{SourceID: 1, SourceName: "github", Status: "invalid"},
+		{SourceID: 2, SourceName: "gitlab", Status: "expiring"},
+	}
+	if err := repo.MarkTokenWarningsNotified(ctx, warnings[:1], now.Add(-time.Hour)); err != nil {
+		t.Fatalf("MarkTokenWarningsNotified() error = %v", err)
+	}
+	if err := repo.MarkTokenWarningsNotified(ctx, warnings[1:], now.Add(-30*time.Hour)); err != nil {
+		t.Fatalf("MarkTokenWarningsNotified() error = %v", err)
+	}
+
+	filtered, err := repo.FilterTokenWarningsNotified(ctx, warnings, now.Add(-20*time.Hour))
+	if err != nil {
+		t.Fatalf("FilterTokenWarningsNotified() error = %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].SourceID != 2 {
+		t.Errorf("FilterTokenWarningsNotified() = %+v, want the source warned an hour ago left out", filtered)
+	}
+}
+
+func TestNotificationRepository_Thread(t *testing.T) {
+	db := setupNotificationTestDB(t)
+	defer db.Close()
+
+	repo := NewNotificationRepository(db)
+	ctx := context.Background()
+
+	thread, err := repo.GetThread(ctx, domain.NotificationThreadNewOutdated)
+	if err != nil || thread != nil {
+		t.Fatalf("GetThread() = %+v, %v, want none", thread, err)
+	}
+
+	started := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, id := range []string{"<first@example.com>", "<second@example.com>"} {
+		if err := repo.SetThread(ctx, domain.NotificationThread{Thread: domain.NotificationThreadNewOutdated, MessageID: id, StartedAt: started}); err != nil {
+			t.Fatalf("SetThread() error = %v", err)
+		}
+	}
+
+	thread, err = repo.GetThread(ctx, domain.NotificationThreadNewOutdated)
+	if err != nil || thread == nil || thread.MessageID != "<second@example.com>" || !thread.StartedAt.Equal(started) {
+		t.Errorf("GetThread() = %+v, %v", thread, err)
+	}
+}
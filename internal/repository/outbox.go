@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// OutboxRepository stores integration events for polling and publishing
+type OutboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Append adds events to the outbox in order
+func (r *OutboxRepository) Append(ctx context.Context, events []domain.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, e := range events {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO outbox_events (type, data, created_at) VALUES (?, ?, ?)",
+			e.Type, string(e.Data), now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// outboxRow is a stored event; SQLite returns the TEXT data as a string
+type outboxRow struct {
+	ID        int64     `db:"id"`
+	Type      string    `db:"type"`
+	Data      string    `db:"data"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// GetSince returns up to limit events with an ID above since, oldest first
+func (r *OutboxRepository) GetSince(ctx context.Context, since int64, limit int) ([]domain.OutboxEvent, error) {
+	var rows []outboxRow
+	err := r.db.SelectContext(ctx, &rows,
+		"SELECT id, type, data, created_at FROM outbox_events WHERE id > ? ORDER BY id LIMIT ?", since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []domain.OutboxEvent
+	for _, row := range rows {
+		events = append(events, domain.OutboxEvent{
+			ID:        row.ID,
+			Type:      row.Type,
+			Data:      json.RawMessage(row.Data),
+			CreatedAt: row.CreatedAt,
+		})
+	}
+	return events, nil
+}
+
+// DeleteBefore removes the events appended before the given time, returning how many were removed
+func (r *OutboxRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM outbox_events WHERE created_at < ?", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetCursor returns the ID of the last event relayed by the named publisher, 0 when it has not
+// relayed any
+func (r *OutboxRepository) GetCursor(ctx context.Context, name string) (int64, error) {
+	var id int64
+	err := r.db.GetContext(ctx, &id, "SELECT last_id FROM outbox_cursors WHERE name = ?", name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, err
+}
+
+// SetCursor records the ID of the last event relayed by the named publisher
+func (r *OutboxRepository) SetCursor(ctx context.Context, name string, id int64) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO outbox_cursors (name, last_id) VALUES (?, ?)
+		 ON CONFLICT(name) DO UPDATE SET last_id = excluded.last_id`, name, id)
+	return err
+}
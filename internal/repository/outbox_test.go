@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func setupOutboxTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test db: %v", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE outbox_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL,
+			data TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE outbox_cursors (
+			name TEXT PRIMARY KEY,
+			last_id INTEGER NOT NULL DEFAULT 0
+		)
+	`)
+	if err != nil {
+		t.Fatalf("failed to create tables: %v", err)
+	}
+	return db
+}
+
+func TestOutboxRepository_AppendAndGetSince(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	defer db.Close()
+	repo := NewOutboxRepository(db)
+	ctx := context.Background()
+
+	var events []domain.OutboxEvent
+	for _, name := range []string{"lodash", "react", "vue"} {
+		event, err := domain.NewOutboxEvent(domain.OutboxDependencyOutdated, map[string]string{"name": name})
+		if err != nil {
+			t.Fatalf("NewOutboxEvent() error = %v", err)
+		}
+		events = append(events, event)
+	}
+	if err := repo.Append(ctx, events); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	page, err := repo.GetSince(ctx, 0, 2)
+	if err != nil {
+		t.Fatalf("GetSince() error = %v", err)
+	}
+	if len(page) != 2 || string(page[0].Data) != `{"name":"lodash"}` || page[0].Type != domain.OutboxDependencyOutdated {
+		t.Fatalf("GetSince(0, 2) = %+v, want the first two events", page)
+	}
+
+	rest, err := repo.GetSince(ctx, page[1].ID, 100)
+	if err != nil {
+		t.Fatalf("GetSince() error = %v", err)
+	}
+	if len(rest) != 1 || string(rest[0].Data) != `{"name":"vue"}` {
+		t.Errorf("GetSince(%d) = %+v, want the last event", page[1].ID, rest)
+	}
+
+	removed, err := repo.DeleteBefore(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("DeleteBefore() error = %v", err)
+	}
+	if removed != 3 {
+		t.Errorf("DeleteBefore() removed %d, want 3", removed)
+	}
+}
+
+func TestOutboxRepository_Cursor(t *testing.T) {
+	db := setupOutboxTestDB(t)
+	defer db.Close()
+	repo := NewOutboxRepository(db)
+	ctx := context.Background()
+
+	if id, err := repo.GetCursor(ctx, "nats"); err != nil || id != 0 {
+		t.Fatalf("GetCursor() = %d, %v, want 0 before any event was relayed", id, err)
+	}
+	for _, id := range []int64{5, 9} {
+		if err := repo.SetCursor(ctx, "nats", id); err != nil {
+			t.Fatalf("SetCursor(%d) error = %v", id, err)
+		}
+	}
+	if id, err := repo.GetCursor(ctx, "nats"); err != nil || id != 9 {
+		t.Errorf("GetCursor() = %d, %v, want 9", id, err)
+	}
+}
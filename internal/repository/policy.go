@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type PolicyRepository struct {
+	db *sqlx.DB
+}
+
+func NewPolicyRepository(db *sqlx.DB) *PolicyRepository {
+	return &PolicyRepository{db: db}
+}
+
+func (r *PolicyRepository) GetAll(ctx context.Context) ([]domain.PackagePolicy, error) {
+	var policies []domain.PackagePolicy
+	err := r.db.SelectContext(ctx, &policies, "SELECT * FROM package_policies ORDER BY list, ecosystem, name")
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *PolicyRepository) Create(ctx context.Context, input domain.PackagePolicyInput) (*domain.PackagePolicy, error) {
+	var policy domain.PackagePolicy
+	err := r.db.GetContext(ctx, &policy,
+		"INSERT INTO package_policies (list, ecosystem, name, reason, created_at) VALUES (?, ?, ?, ?, ?) RETURNING *",
+		input.List, input.Ecosystem, input.Name, input.Reason, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Delete removes a policy, reporting whether it existed
+func (r *PolicyRepository) Delete(ctx context.Context, id int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM package_policies WHERE id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// policyMatch matches the policies of a list that apply to the dependency d
+const policyMatch = `FROM package_policies p
+	WHERE p.list = ? AND (p.ecosystem = '' OR p.ecosystem = d.ecosystem) AND d.name GLOB p.name`
+
+// violationSelect adds the policy violation of each dependency: denied when a deny rule matches,
+// not_allowed when allow rules apply to its ecosystem but none matches, empty otherwise
+const violationSelect = `SELECT d.*, r.name as repo_name, r.full_name as repo_full_name, r.owners as repo_owners, s.name as source_name,
+	CASE
+		WHEN EXISTS (SELECT 1 ` + policyMatch + `) THEN 'denied'
+		WHEN EXISTS (SELECT 1 FROM package_policies p WHERE p.list = 'allow' AND (p.ecosystem = '' OR p.ecosystem = d.ecosystem))
+		 AND NOT EXISTS (SELECT 1 ` + policyMatch + `) THEN 'not_allowed'
+		ELSE ''
+	END as violation,
+	COALESCE((SELECT p.reason ` + policyMatch + ` ORDER BY p.ecosystem DESC LIMIT 1), '') as policy_reason
+	FROM dependencies d
+	JOIN repositories r ON d.repository_id = r.id
+	JOIN sources s ON r.source_id = s.id`
+
+// violationArgs are the policy lists of the placeholders of violationSelect
+var violationArgs = []interface{}{domain.PolicyDeny, domain.PolicyAllow, domain.PolicyDeny}
+
+// GetViolations returns the dependencies violating the package policies, optionally only those of
+// one kind of violation or ecosystem, denied ones first
+func (r *PolicyRepository) GetViolations(ctx context.Context, violation, ecosystem string) ([]domain.PolicyViolation, error) {
+	query := `SELECT * FROM (` + violationSelect + `) WHERE violation <> ''`
+	args := append([]interface{}{}, violationArgs...)
+	if violation != "" {
+		query += " AND violation = ?"
+		args = append(args, violation)
+	}
+	if ecosystem != "" {
+		query += " AND ecosystem = ?"
+		args = append(args, ecosystem)
+	}
+	query += " ORDER BY violation, name, repo_full_name, manifest_path"
+
+	var violations []domain.PolicyViolation
+	if err := r.db.SelectContext(ctx, &violations, query, args...); err != nil {
+		return nil, err
+	}
+	return violations, nil
+}
+
+// CountViolations counts the dependencies violating the package policies in the repositories still
+// present in their source
+func (r *PolicyRepository) CountViolations(ctx context.Context) (domain.PolicyViolationStats, error) {
+	var rows []struct {
+		Violation string `db:"violation"`
+		Count     int    `db:"count"`
+	}
+	err := r.db.SelectContext(ctx, &rows,
+		`SELECT violation, COUNT(*) as count FROM (`+violationSelect+` WHERE r.removed_at IS NULL) WHERE violation <> '' GROUP BY violation`,
+		violationArgs...)
+	if err != nil {
+		return domain.PolicyViolationStats{}, err
+	}
+
+	var stats domain.PolicyViolationStats
+	for _, row := range rows {
+		switch row.Violation {
+		case domain.ViolationDenied:
+			stats.Denied = row.Count
+		case domain.ViolationNotAllowed:
+			stats.NotAllowed = row.Count
+		}
+		stats.Total += row.Count
+	}
+	return stats, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func setupPolicyTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test db: %v", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE sources (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+		CREATE TABLE repositories (
+			id INTEGER PRIMARY KEY,
+			source_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			full_name TEXT NOT NULL,
+			owners TEXT NOT NULL DEFAULT '',
+			removed_at DATETIME
+		);
+		CREATE TABLE dependencies (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repository_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			ecosystem TEXT NOT NULL,
+			current_version TEXT NOT NULL DEFAULT '',
+			manifest_path TEXT NOT NULL DEFAULT ''
+		);
+		CREATE TABLE package_policies (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			list TEXT NOT NULL,
+			ecosystem TEXT NOT NULL DEFAULT '',
+			name TEXT NOT NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(list, ecosystem, name)
+		);
+		INSERT INTO sources (id, name) VALUES (1, 'github');
+		INSERT INTO repositories (id, source_id, name, full_name) VALUES (1, 1, 'web', 'acme/web');
+		INSERT INTO repositories (id, source_id, name, full_name, removed_at) VALUES (2, 1, 'old', 'acme/old', '2024-01-01');
+		INSERT INTO dependencies (repository_id, name, ecosystem) VALUES
+			(1, 'left-pad', 'npm'), (1, '@acme/ui', 'npm'), (1, 'react', 'npm'),
+			(1, 'com.acme:core', 'maven'), (1, 'log4j:log4j', 'maven'), (2, 'left-pad', 'npm');
+	`)
+	if err != nil {
+		t.Fatalf("failed to create tables: %v", err)
+	}
+	return db
+}
+
+func TestPolicyRepository_Violations(t *testing.T) {
+	db := setupPolicyTestDB(t)
+	defer db.Close()
+	repo := NewPolicyRepository(db)
+	ctx := context.Background()
+
+	for _, input := range []domain.PackagePolicyInput{
+		{List: domain.PolicyDeny, Name: "left-pad", Reason: "unmaintained"},
+		{List: domain.PolicyDeny, Ecosystem: "maven", Name: "log4j:*", Reason: "use logback"},
+		{List: domain.PolicyAllow, Ecosystem: "npm", Name: "@acme/*"},
+		{List: domain.PolicyAllow, Ecosystem: "npm", Name: "left-pad"},
+	} {
+		if _, err := repo.Create(ctx, input); err != nil {
+			t.Fatalf("Create(%+v) error = %v", input, err)
+		}
+	}
+
+	violations, err := repo.GetViolations(ctx, "", "")
+	if err != nil {
+		t.Fatalf("GetViolations() error = %v", err)
+	}
+	got := make(map[string]string)
+	for _, v := range violations {
+		got[v.Name] = v.Violation
+		if v.Violation == domain.ViolationDenied && v.PolicyReason == "" {
+			t.Errorf("%s: missing policy reason", v.Name)
+		}
+	}
+	// A deny rule wins over an allow rule; maven has no allowlist
+	want := map[string]string{
+		"left-pad":    domain.ViolationDenied,
+		"log4j:log4j": domain.ViolationDenied,
+		"react":       domain.ViolationNotAllowed,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("violations = %v, want %v", got, want)
+	}
+	for name, violation := range want {
+		if got[name] != violation {
+			t.Errorf("%s: violation = %q, want %q", name, got[name], violation)
+		}
+	}
+
+	denied, err := repo.GetViolations(ctx, domain.ViolationDenied, "maven")
+	if err != nil {
+		t.Fatalf("GetViolations(denied, maven) error = %v", err)
+	}
+	if len(denied) != 1 || denied[0].Name != "log4j:log4j" || denied[0].RepoFullName != "acme/web" {
+		t.Errorf("GetViolations(denied, maven) = %+v", denied)
+	}
+
+	// Repositories removed from their source are not counted
+	stats, err := repo.CountViolations(ctx)
+	if err != nil {
+		t.Fatalf("CountViolations() error = %v", err)
+	}
+	if stats != (domain.PolicyViolationStats{Denied: 2, NotAllowed: 1, Total: 3}) {
+		t.Errorf("CountViolations() = %+v", stats)
+	}
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/jiin/stale/internal/domain"
@@ -17,8 +19,9 @@ func NewRepoRepository(db *sqlx.DB) *RepoRepository {
 }
 
 func (r *RepoRepository) Upsert(ctx context.Context, repo domain.Repository) (int64, error) {
-	query := `INSERT INTO repositories (source_id, name, full_name, default_branch, html_url, has_package_json, has_pom_xml, has_build_gradle, has_go_mod, created_at, updated_at, last_scan_at)
-              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	query := `INSERT INTO repositories (source_id, name, full_name, default_branch, html_url, has_package_json, has_pom_xml, has_build_gradle, has_go_mod, has_swiftpm, has_podfile,
+                  go_version, go_toolchain, go_vendored, go_latest_version, go_toolchain_outdated, owners, owners_source, created_at, updated_at, last_scan_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
               ON CONFLICT(full_name) DO UPDATE SET
                   name = excluded.name,
                   default_branch = excluded.default_branch,
@@ -27,21 +30,56 @@ func (r *RepoRepository) Upsert(ctx context.Context, repo domain.Repository) (in
                   has_pom_xml = excluded.has_pom_xml,
                   has_build_gradle = excluded.has_build_gradle,
                   has_go_mod = excluded.has_go_mod,
+                  has_swiftpm = excluded.has_swiftpm,
+                  has_podfile = excluded.has_podfile,
+                  go_version = excluded.go_version,
+                  go_toolchain = excluded.go_toolchain,
+                  go_vendored = excluded.go_vendored,
+                  go_latest_version = excluded.go_latest_version,
+                  go_toolchain_outdated = excluded.go_toolchain_outdated,
+                  owners = excluded.owners,
+                  owners_source = excluded.owners_source,
                   updated_at = excluded.updated_at,
-                  last_scan_at = excluded.last_scan_at
+                  last_scan_at = excluded.last_scan_at,
+                  last_scan_error = '',
+                  last_scan_error_at = NULL,
+                  removed_at = NULL
               RETURNING id`
 
 	now := time.Now()
 	var id int64
 	err := r.db.GetContext(ctx, &id, query,
 		repo.SourceID, repo.Name, repo.FullName, repo.DefaultBranch,
-		repo.HTMLURL, repo.HasPackageJSON, repo.HasPomXML, repo.HasBuildGradle, repo.HasGoMod, now, now, now)
+		repo.HTMLURL, repo.HasPackageJSON, repo.HasPomXML, repo.HasBuildGradle, repo.HasGoMod, repo.HasSwiftPM, repo.HasPodfile,
+		repo.GoVersion, repo.GoToolchain, repo.GoVendored, repo.GoLatestVersion, repo.GoToolchainOutdated,
+		repo.Owners, repo.OwnersSource, now, now, now)
 	if err != nil {
 		return 0, err
 	}
 	return id, nil
 }
 
+// SetScanError records the error of the last scan of a repository, adding the repository when it
+// was never scanned successfully so the error is listed with it
+func (r *RepoRepository) SetScanError(ctx context.Context, repo domain.Repository, scanErr string) error {
+	query := `INSERT INTO repositories (source_id, name, full_name, default_branch, html_url, last_scan_error, last_scan_error_at, created_at, updated_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+              ON CONFLICT(full_name) DO UPDATE SET
+                  last_scan_error = excluded.last_scan_error,
+                  last_scan_error_at = excluded.last_scan_error_at,
+                  removed_at = NULL`
+
+	now := time.Now()
+	_, err := r.db.ExecContext(ctx, query, repo.SourceID, repo.Name, repo.FullName, repo.DefaultBranch, repo.HTMLURL, scanErr, now, now, now)
+	return err
+}
+
+// ClearScanError removes the error of the last scan of a repository
+func (r *RepoRepository) ClearScanError(ctx context.Context, fullName string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE repositories SET last_scan_error = '', last_scan_error_at = NULL WHERE full_name = ? AND last_scan_error != ''", fullName)
+	return err
+}
+
 func (r *RepoRepository) GetAll(ctx context.Context) ([]domain.Repository, error) {
 	query := `SELECT r.*,
 		COALESCE((SELECT COUNT(*) FROM dependencies d WHERE d.repository_id = r.id), 0) as dependency_count,
@@ -80,14 +118,97 @@ func (r *RepoRepository) GetByID(ctx context.Context, id int64) (*domain.Reposit
 	return &repo, nil
 }
 
+// GetByFullName returns the repository with the given full name (case-insensitive),
+// preferring repositories that are still present in their source
+func (r *RepoRepository) GetByFullName(ctx context.Context, fullName string) (*domain.Repository, error) {
+	var repo domain.Repository
+	err := r.db.GetContext(ctx, &repo,
+		"SELECT * FROM repositories WHERE LOWER(full_name) = LOWER(?) ORDER BY removed_at IS NOT NULL, id LIMIT 1", fullName)
+	if err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+// GetBranchOverrides returns per-repository branch overrides for a source keyed by full name
+func (r *RepoRepository) GetBranchOverrides(ctx context.Context, sourceID int64) (map[string]string, error) {
+	type override struct {
+		FullName string `db:"full_name"`
+		Branch   string `db:"branch_override"`
+	}
+	var rows []override
+	err := r.db.SelectContext(ctx, &rows,
+		"SELECT full_name, branch_override FROM repositories WHERE source_id = ? AND branch_override != ''", sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[string]string, len(rows))
+	for _, row := range rows {
+		overrides[row.FullName] = row.Branch
+	}
+	return overrides, nil
+}
+
+// UpdateBranchOverride sets the branch to scan for a repository (empty clears the override)
+func (r *RepoRepository) UpdateBranchOverride(ctx context.Context, id int64, branch string) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE repositories SET branch_override = ?, updated_at = ? WHERE id = ?",
+		branch, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkRemoved flags the repositories of a source that are not in present (full names) as removed
+// and returns the full names of repositories that were newly flagged
+func (r *RepoRepository) MarkRemoved(ctx context.Context, sourceID int64, present []string) ([]string, error) {
+	var repos []struct {
+		ID       int64  `db:"id"`
+		FullName string `db:"full_name"`
+	}
+	err := r.db.SelectContext(ctx, &repos,
+		"SELECT id, full_name FROM repositories WHERE source_id = ? AND removed_at IS NULL", sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	presentSet := make(map[string]bool, len(present))
+	for _, name := range present {
+		presentSet[strings.ToLower(name)] = true
+	}
+
+	var removed []string
+	now := time.Now()
+	for _, repo := range repos {
+		if presentSet[strings.ToLower(repo.FullName)] {
+			continue
+		}
+		if _, err := r.db.ExecContext(ctx, "UPDATE repositories SET removed_at = ? WHERE id = ?", now, repo.ID); err != nil {
+			return removed, err
+		}
+		removed = append(removed, repo.FullName)
+	}
+	return removed, nil
+}
+
 func (r *RepoRepository) DeleteBySourceID(ctx context.Context, sourceID int64) error {
 	_, err := r.db.ExecContext(ctx, "DELETE FROM repositories WHERE source_id = ?", sourceID)
 	return err
 }
 
+// Count returns the number of repositories still present in their source
 func (r *RepoRepository) Count(ctx context.Context) (int, error) {
 	var count int
-	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM repositories")
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM repositories WHERE removed_at IS NULL")
 	return count, err
 }
 
@@ -76,6 +76,84 @@ func (r *ScanRepository) UpdateStats(ctx context.Context, id int64, reposFound,
 	return err
 }
 
+// AddReposTotal adds the repositories listed for a source to the repositories a scan covers
+func (r *ScanRepository) AddReposTotal(ctx context.Context, id int64, repos int) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE scan_jobs SET repos_total = repos_total + ? WHERE id = ?", repos, id)
+	return err
+}
+
+// AddDiscovery adds the number of newly discovered and removed repositories to a scan's stats
+func (r *ScanRepository) AddDiscovery(ctx context.Context, id int64, reposNew, reposRemoved int) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE scan_jobs SET repos_new = repos_new + ?, repos_removed = repos_removed + ? WHERE id = ?",
+		reposNew, reposRemoved, id)
+	return err
+}
+
+// AddSourceUsage records the provider API usage of a source during a scan and adds it to the scan's totals
+func (r *ScanRepository) AddSourceUsage(ctx context.Context, usage domain.ScanSourceUsage) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO scan_source_usage (scan_id, source_id, source_name, api_calls, bytes_fetched, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(scan_id, source_id) DO UPDATE SET
+		   api_calls = api_calls + excluded.api_calls, bytes_fetched = bytes_fetched + excluded.bytes_fetched`,
+		usage.ScanID, usage.SourceID, usage.SourceName, usage.APICalls, usage.BytesFetched, time.Now()); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE scan_jobs SET api_calls = api_calls + ?, bytes_fetched = bytes_fetched + ? WHERE id = ?",
+		usage.APICalls, usage.BytesFetched, usage.ScanID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetSourceUsage returns the provider API usage per source of a scan, heaviest first
+func (r *ScanRepository) GetSourceUsage(ctx context.Context, scanID int64) ([]domain.ScanSourceUsage, error) {
+	var usage []domain.ScanSourceUsage
+	err := r.db.SelectContext(ctx, &usage,
+		`SELECT scan_id, source_id, source_name, api_calls, bytes_fetched FROM scan_source_usage
+		 WHERE scan_id = ? ORDER BY api_calls DESC, source_name`, scanID)
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// GetLatestCompleted returns the most recent completed scan
+func (r *ScanRepository) GetLatestCompleted(ctx context.Context) (*domain.ScanJob, error) {
+	var scan domain.ScanJob
+	err := r.db.GetContext(ctx, &scan,
+		"SELECT * FROM scan_jobs WHERE status = ? ORDER BY created_at DESC, id DESC LIMIT 1",
+		domain.ScanStatusCompleted)
+	if err != nil {
+		return nil, err
+	}
+	return &scan, nil
+}
+
+// GetRecentCompleted returns the latest completed scans of the same sources as a scan of sourceID
+// (nil for scans of all sources) that found repositories, newest first
+func (r *ScanRepository) GetRecentCompleted(ctx context.Context, sourceID *int64, limit int) ([]domain.ScanJob, error) {
+	var scans []domain.ScanJob
+	err := r.db.SelectContext(ctx, &scans,
+		`SELECT * FROM scan_jobs
+		 WHERE status = ? AND source_id IS ? AND repos_found > 0 AND started_at IS NOT NULL AND finished_at IS NOT NULL
+		 ORDER BY created_at DESC, id DESC LIMIT ?`,
+		domain.ScanStatusCompleted, sourceID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scans, nil
+}
+
 func (r *ScanRepository) GetLatestRunning(ctx context.Context) (*domain.ScanJob, error) {
 	var scan domain.ScanJob
 	err := r.db.GetContext(ctx, &scan,
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"strconv"
+	"time"
 
 	"github.com/jiin/stale/internal/domain"
 	"github.com/jiin/stale/internal/util"
@@ -19,20 +20,10 @@ func NewSettingsRepository(db *sqlx.DB) *SettingsRepository {
 }
 
 func (r *SettingsRepository) Get(ctx context.Context) (*domain.Settings, error) {
-	rows, err := r.db.QueryxContext(ctx, "SELECT key, value FROM settings")
+	values, err := r.values(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	values := make(map[string]string)
-	for rows.Next() {
-		var key, value string
-		if err := rows.Scan(&key, &value); err != nil {
-			return nil, err
-		}
-		values[key] = value
-	}
 
 	// Decrypt SMTP password
 	smtpPass := values["email_smtp_pass"]
@@ -45,9 +36,34 @@ func (r *SettingsRepository) Get(ctx context.Context) (*domain.Settings, error)
 		}
 	}
 
+	// Decrypt observability API key
+	observabilityKey := values["observability_api_key"]
+	if observabilityKey != "" {
+		decrypted, err := util.Decrypt(observabilityKey)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to decrypt observability API key, using as-is")
+		} else {
+			observabilityKey = decrypted
+		}
+	}
+
+	// Decrypt failure webhook URL, which embeds the credentials of Slack webhooks
+	failureWebhook := values["failure_webhook_url"]
+	if failureWebhook != "" {
+		decrypted, err := util.Decrypt(failureWebhook)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to decrypt failure webhook URL, using as-is")
+		} else {
+			failureWebhook = decrypted
+		}
+	}
+
 	settings := &domain.Settings{
 		ScheduleEnabled:        values["schedule_enabled"] == "true",
 		ScheduleCron:           values["schedule_cron"],
+		ScheduleTimezone:       values["schedule_timezone"],
+		ScheduleQueueWhenBusy:  values["schedule_queue_when_busy"] != "false",
+		RescanIntervalMinutes:  parseIntOrDefault(values["rescan_interval_minutes"], 5),
 		EmailEnabled:           values["email_enabled"] == "true",
 		EmailSMTPHost:          values["email_smtp_host"],
 		EmailSMTPPort:          parseIntOrDefault(values["email_smtp_port"], 587),
@@ -56,8 +72,41 @@ func (r *SettingsRepository) Get(ctx context.Context) (*domain.Settings, error)
 		EmailFrom:              values["email_from"],
 		EmailTo:                values["email_to"],
 		EmailNotifyNewOutdated: values["email_notify_new_outdated"] != "false",
+		EmailAttachCSV:         values["email_attach_csv"] == "true",
+		EmailRemindDays:        parseIntOrDefault(values["email_remind_days"], 7),
+		EmailRecipientLocales:  values["email_recipient_locales"],
+		EmailNotifyViewID:      int64(parseIntOrDefault(values["email_notify_view_id"], 0)),
+		EmailMonthlyReport:     values["email_monthly_report"] == "true",
+		FailureNotifyEmail:     values["failure_notify_email"] != "false",
+		FailureWebhookURL:      failureWebhook,
+		FailureNotifyHours:     parseIntOrDefault(values["failure_notify_hours"], 6),
+		SourceDisableAfter:     parseIntOrDefault(values["source_disable_after"], 5),
+		Locale:                 stringOrDefault(values["locale"], "en"),
+		SLAMajorDays:           parseIntOrDefault(values["sla_major_days"], 90),
+		SLAMinorDays:           parseIntOrDefault(values["sla_minor_days"], 60),
+		SLAPatchDays:           parseIntOrDefault(values["sla_patch_days"], 30),
+		IgnorePrereleases:      values["ignore_prereleases"] == "true",
+		RegistryFallback:       values["registry_fallback"] == "true",
+		ManifestRefreshHours:   parseIntOrDefault(values["manifest_refresh_hours"], 168),
+		RegistryCacheSeconds:   parseIntOrDefault(values["registry_cache_seconds"], 3600),
+		RegistryCacheOverrides: values["registry_cache_overrides"],
+		StatsCacheSeconds:      parseIntOrDefault(values["stats_cache_seconds"], 120),
+		ReposCacheSeconds:      parseIntOrDefault(values["repos_cache_seconds"], 300),
+		StatsExcludedTypes:     domain.DependencyTypePeer,
+		ObservabilityProvider:  values["observability_provider"],
+		ObservabilityAPIKey:    observabilityKey,
+		ObservabilitySite:      values["observability_site"],
+		ObservabilityAccountID: values["observability_account_id"],
+		ObservabilityTags:      values["observability_tags"],
 	}
 
+	// Peer dependency ranges declare compatibility rather than an installed version, so they are
+	// left out of the stats unless configured otherwise; an empty value includes every type
+	if v, ok := values["stats_excluded_types"]; ok {
+		settings.StatsExcludedTypes = v
+	}
+	settings.StatsExcludedScopes = values["stats_excluded_scopes"]
+
 	return settings, nil
 }
 
@@ -68,6 +117,14 @@ func (r *SettingsRepository) Update(ctx context.Context, input *domain.SettingsI
 	}
 	defer tx.Rollback()
 
+	if err := updateSettings(ctx, tx, input); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// updateSettings writes the fields of input that are set within tx
+func updateSettings(ctx context.Context, tx *sqlx.Tx, input *domain.SettingsInput) error {
 	updateSetting := func(key string, value string) error {
 		_, err := tx.ExecContext(ctx,
 			"INSERT OR REPLACE INTO settings (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)",
@@ -85,6 +142,21 @@ func (r *SettingsRepository) Update(ctx context.Context, input *domain.SettingsI
 			return err
 		}
 	}
+	if input.ScheduleTimezone != nil {
+		if err := updateSetting("schedule_timezone", *input.ScheduleTimezone); err != nil {
+			return err
+		}
+	}
+	if input.ScheduleQueueWhenBusy != nil {
+		if err := updateSetting("schedule_queue_when_busy", boolToStr(*input.ScheduleQueueWhenBusy)); err != nil {
+			return err
+		}
+	}
+	if input.RescanIntervalMinutes != nil {
+		if err := updateSetting("rescan_interval_minutes", strconv.Itoa(*input.RescanIntervalMinutes)); err != nil {
+			return err
+		}
+	}
 	if input.EmailEnabled != nil {
 		if err := updateSetting("email_enabled", boolToStr(*input.EmailEnabled)); err != nil {
 			return err
@@ -130,10 +202,232 @@ func (r *SettingsRepository) Update(ctx context.Context, input *domain.SettingsI
 			return err
 		}
 	}
+	if input.EmailAttachCSV != nil {
+		if err := updateSetting("email_attach_csv", boolToStr(*input.EmailAttachCSV)); err != nil {
+			return err
+		}
+	}
+	if input.EmailRemindDays != nil {
+		if err := updateSetting("email_remind_days", strconv.Itoa(*input.EmailRemindDays)); err != nil {
+			return err
+		}
+	}
+	if input.EmailRecipientLocales != nil {
+		if err := updateSetting("email_recipient_locales", *input.EmailRecipientLocales); err != nil {
+			return err
+		}
+	}
+	if input.EmailNotifyViewID != nil {
+		if err := updateSetting("email_notify_view_id", strconv.FormatInt(*input.EmailNotifyViewID, 10)); err != nil {
+			return err
+		}
+	}
+	if input.EmailMonthlyReport != nil {
+		if err := updateSetting("email_monthly_report", boolToStr(*input.EmailMonthlyReport)); err != nil {
+			return err
+		}
+	}
+	if input.FailureNotifyEmail != nil {
+		if err := updateSetting("failure_notify_email", boolToStr(*input.FailureNotifyEmail)); err != nil {
+			return err
+		}
+	}
+	if input.FailureWebhookURL != nil {
+		// Encrypt webhook URL before storing
+		encryptedURL, err := util.Encrypt(*input.FailureWebhookURL)
+		if err != nil {
+			return err
+		}
+		if err := updateSetting("failure_webhook_url", encryptedURL); err != nil {
+			return err
+		}
+	}
+	if input.FailureNotifyHours != nil {
+		if err := updateSetting("failure_notify_hours", strconv.Itoa(*input.FailureNotifyHours)); err != nil {
+			return err
+		}
+	}
+	if input.SourceDisableAfter != nil {
+		if err := updateSetting("source_disable_after", strconv.Itoa(*input.SourceDisableAfter)); err != nil {
+			return err
+		}
+	}
+
+	if input.Locale != nil {
+		if err := updateSetting("locale", *input.Locale); err != nil {
+			return err
+		}
+	}
+
+	if input.SLAMajorDays != nil {
+		if err := updateSetting("sla_major_days", strconv.Itoa(*input.SLAMajorDays)); err != nil {
+			return err
+		}
+	}
+	if input.SLAMinorDays != nil {
+		if err := updateSetting("sla_minor_days", strconv.Itoa(*input.SLAMinorDays)); err != nil {
+			return err
+		}
+	}
+	if input.SLAPatchDays != nil {
+		if err := updateSetting("sla_patch_days", strconv.Itoa(*input.SLAPatchDays)); err != nil {
+			return err
+		}
+	}
+	if input.IgnorePrereleases != nil {
+		if err := updateSetting("ignore_prereleases", boolToStr(*input.IgnorePrereleases)); err != nil {
+			return err
+		}
+	}
+	if input.RegistryFallback != nil {
+		if err := updateSetting("registry_fallback", boolToStr(*input.RegistryFallback)); err != nil {
+			return err
+		}
+	}
+	if input.ManifestRefreshHours != nil {
+		if err := updateSetting("manifest_refresh_hours", strconv.Itoa(*input.ManifestRefreshHours)); err != nil {
+			return err
+		}
+	}
+	if input.RegistryCacheSeconds != nil {
+		if err := updateSetting("registry_cache_seconds", strconv.Itoa(*input.RegistryCacheSeconds)); err != nil {
+			return err
+		}
+	}
+	if input.RegistryCacheOverrides != nil {
+		if err := updateSetting("registry_cache_overrides", *input.RegistryCacheOverrides); err != nil {
+			return err
+		}
+	}
+	if input.StatsCacheSeconds != nil {
+		if err := updateSetting("stats_cache_seconds", strconv.Itoa(*input.StatsCacheSeconds)); err != nil {
+			return err
+		}
+	}
+	if input.ReposCacheSeconds != nil {
+		if err := updateSetting("repos_cache_seconds", strconv.Itoa(*input.ReposCacheSeconds)); err != nil {
+			return err
+		}
+	}
+	if input.StatsExcludedTypes != nil {
+		if err := updateSetting("stats_excluded_types", *input.StatsExcludedTypes); err != nil {
+			return err
+		}
+	}
+	if input.StatsExcludedScopes != nil {
+		if err := updateSetting("stats_excluded_scopes", *input.StatsExcludedScopes); err != nil {
+			return err
+		}
+	}
+
+	if input.ObservabilityProvider != nil {
+		if err := updateSetting("observability_provider", *input.ObservabilityProvider); err != nil {
+			return err
+		}
+	}
+	if input.ObservabilityAPIKey != nil {
+		// Encrypt API key before storing
+		encryptedKey, err := util.Encrypt(*input.ObservabilityAPIKey)
+		if err != nil {
+			return err
+		}
+		if err := updateSetting("observability_api_key", encryptedKey); err != nil {
+			return err
+		}
+	}
+	if input.ObservabilitySite != nil {
+		if err := updateSetting("observability_site", *input.ObservabilitySite); err != nil {
+			return err
+		}
+	}
+	if input.ObservabilityAccountID != nil {
+		if err := updateSetting("observability_account_id", *input.ObservabilityAccountID); err != nil {
+			return err
+		}
+	}
+	if input.ObservabilityTags != nil {
+		if err := updateSetting("observability_tags", *input.ObservabilityTags); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetSchedulerState returns whether scheduled scans are paused or the server is in maintenance mode
+func (r *SettingsRepository) GetSchedulerState(ctx context.Context) (*domain.SchedulerState, error) {
+	values, err := r.values(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SchedulerState{
+		Paused:          values["scheduler_paused"] == "true",
+		PausedAt:        parseTimeOrNil(values["scheduler_paused_at"]),
+		PausedUntil:     parseTimeOrNil(values["scheduler_paused_until"]),
+		PauseReason:     values["scheduler_pause_reason"],
+		MaintenanceMode: values["maintenance_mode"] == "true",
+	}, nil
+}
+
+// SetSchedulerState stores the pause and maintenance mode state of the scheduler
+func (r *SettingsRepository) SetSchedulerState(ctx context.Context, state *domain.SchedulerState) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	for key, value := range map[string]string{
+		"scheduler_paused":       boolToStr(state.Paused),
+		"scheduler_paused_at":    formatTime(state.PausedAt),
+		"scheduler_paused_until": formatTime(state.PausedUntil),
+		"scheduler_pause_reason": state.PauseReason,
+		"maintenance_mode":       boolToStr(state.MaintenanceMode),
+	} {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT OR REPLACE INTO settings (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)",
+			key, value); err != nil {
+			return err
+		}
+	}
 
 	return tx.Commit()
 }
 
+// values returns every stored setting by key
+func (r *SettingsRepository) values(ctx context.Context) (map[string]string, error) {
+	rows, err := r.db.QueryxContext(ctx, "SELECT key, value FROM settings")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	return values, rows.Err()
+}
+
+func parseTimeOrNil(s string) *time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
 func parseIntOrDefault(s string, def int) int {
 	if i, err := strconv.Atoi(s); err == nil {
 		return i
@@ -141,6 +435,13 @@ func parseIntOrDefault(s string, def int) int {
 	return def
 }
 
+func stringOrDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
 func boolToStr(b bool) string {
 	if b {
 		return "true"
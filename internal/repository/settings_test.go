@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jiin/stale/internal/domain"
 	"github.com/jmoiron/sqlx"
@@ -75,6 +76,38 @@ func TestSettingsRepository_Get(t *testing.T) {
 	if !settings.EmailNotifyNewOutdated {
 		t.Error("Get() EmailNotifyNewOutdated should be true by default")
 	}
+	if settings.SLAMajorDays != 90 || settings.SLAMinorDays != 60 || settings.SLAPatchDays != 30 {
+		t.Errorf("Get() SLA days = %d/%d/%d, want 90/60/30", settings.SLAMajorDays, settings.SLAMinorDays, settings.SLAPatchDays)
+	}
+	if !settings.ScheduleQueueWhenBusy {
+		t.Error("Get() ScheduleQueueWhenBusy should be true by default")
+	}
+	if settings.IgnorePrereleases {
+		t.Error("Get() IgnorePrereleases should be false by default")
+	}
+	if settings.StatsExcludedTypes != "peerDependency" {
+		t.Errorf("Get() StatsExcludedTypes = %q, want peerDependency by default", settings.StatsExcludedTypes)
+	}
+}
+
+func TestSettingsRepository_StatsExcludedTypesEmpty(t *testing.T) {
+	db := setupSettingsTestDB(t)
+	defer db.Close()
+
+	repo := NewSettingsRepository(db)
+	ctx := context.Background()
+
+	// An empty value is kept rather than replaced by the default
+	if err := repo.Update(ctx, &domain.SettingsInput{StatsExcludedTypes: strPtr("")}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	settings, err := repo.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if settings.StatsExcludedTypes != "" || len(settings.StatsExclusions().Types) != 0 {
+		t.Errorf("StatsExcludedTypes = %q, want empty", settings.StatsExcludedTypes)
+	}
 }
 
 func TestSettingsRepository_Update(t *testing.T) {
@@ -239,6 +272,56 @@ func TestSettingsRepository_EmptyUpdate(t *testing.T) {
 	}
 }
 
+func TestSettingsRepository_SchedulerState(t *testing.T) {
+	db := setupSettingsTestDB(t)
+	defer db.Close()
+
+	repo := NewSettingsRepository(db)
+	ctx := context.Background()
+
+	state, err := repo.GetSchedulerState(ctx)
+	if err != nil {
+		t.Fatalf("GetSchedulerState() error = %v", err)
+	}
+	if state.Paused || state.MaintenanceMode || state.PausedAt != nil {
+		t.Errorf("default state = %+v, want running", state)
+	}
+
+	pausedAt := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	until := pausedAt.Add(2 * time.Hour)
+	err = repo.SetSchedulerState(ctx, &domain.SchedulerState{
+		Paused:          true,
+		PausedAt:        &pausedAt,
+		PausedUntil:     &until,
+		PauseReason:     "database migration",
+		MaintenanceMode: true,
+	})
+	if err != nil {
+		t.Fatalf("SetSchedulerState() error = %v", err)
+	}
+
+	state, err = repo.GetSchedulerState(ctx)
+	if err != nil {
+		t.Fatalf("GetSchedulerState() error = %v", err)
+	}
+	if !state.Paused || !state.MaintenanceMode || state.PauseReason != "database migration" {
+		t.Errorf("state = %+v", state)
+	}
+	if state.PausedAt == nil || !state.PausedAt.Equal(pausedAt) || state.PausedUntil == nil || !state.PausedUntil.Equal(until) {
+		t.Errorf("state times = %v, %v", state.PausedAt, state.PausedUntil)
+	}
+	if !state.ScansPaused(pausedAt.Add(time.Hour)) || state.ScansPaused(until) {
+		t.Error("scans should be paused until the pause ends")
+	}
+
+	if err := repo.SetSchedulerState(ctx, &domain.SchedulerState{}); err != nil {
+		t.Fatalf("SetSchedulerState() error = %v", err)
+	}
+	if state, _ = repo.GetSchedulerState(ctx); state.Paused || state.PausedUntil != nil || state.PauseReason != "" {
+		t.Errorf("resumed state = %+v", state)
+	}
+}
+
 func TestParseIntOrDefault(t *testing.T) {
 	tests := []struct {
 		name     string
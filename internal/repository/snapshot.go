@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// SnapshotRepository archives the outdated dependencies of each day
+type SnapshotRepository struct {
+	db *sqlx.DB
+}
+
+func NewSnapshotRepository(db *sqlx.DB) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+// Save archives a snapshot, replacing the one of the same day
+func (r *SnapshotRepository) Save(ctx context.Context, snapshot domain.StateSnapshot) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM snapshot_outdated WHERE day = ?", snapshot.Day); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO state_snapshots (day, dependencies, outdated, taken_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(day) DO UPDATE SET dependencies = excluded.dependencies, outdated = excluded.outdated, taken_at = excluded.taken_at`,
+		snapshot.Day, snapshot.Dependencies, len(snapshot.Entries), snapshot.TakenAt); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO snapshot_outdated (day, repository, source, owners, manifest_path, name, type, ecosystem, current_version, latest_version)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	for _, e := range snapshot.Entries {
+		if _, err := tx.ExecContext(ctx, query, snapshot.Day, e.Repository, e.Source, e.Owners, e.ManifestPath,
+			e.Name, e.Type, e.Ecosystem, e.CurrentVersion, e.LatestVersion); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetOnOrBefore returns the latest snapshot archived on or before the given day with its entries,
+// or nil when there is none
+func (r *SnapshotRepository) GetOnOrBefore(ctx context.Context, day string) (*domain.StateSnapshot, error) {
+	var snapshot domain.StateSnapshot
+	err := r.db.GetContext(ctx, &snapshot,
+		"SELECT day, dependencies, outdated, taken_at FROM state_snapshots WHERE day <= ? ORDER BY day DESC LIMIT 1", day)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.SelectContext(ctx, &snapshot.Entries,
+		`SELECT repository, source, owners, manifest_path, name, type, ecosystem, current_version, latest_version
+		 FROM snapshot_outdated WHERE day = ?`, snapshot.Day)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// DeleteBefore removes the snapshots of days before the given one, returning how many were removed
+func (r *SnapshotRepository) DeleteBefore(ctx context.Context, day string) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM snapshot_outdated WHERE day < ?", day); err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx, "DELETE FROM state_snapshots WHERE day < ?", day)
+	if err != nil {
+		return 0, err
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return removed, tx.Commit()
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func setupSnapshotTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test db: %v", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE state_snapshots (
+			day TEXT PRIMARY KEY,
+			dependencies INTEGER NOT NULL DEFAULT 0,
+			outdated INTEGER NOT NULL DEFAULT 0,
+			taken_at DATETIME NOT NULL
+		);
+		CREATE TABLE snapshot_outdated (
+			day TEXT NOT NULL,
+			repository TEXT NOT NULL,
+			source TEXT NOT NULL DEFAULT '',
+			owners TEXT NOT NULL DEFAULT '',
+			manifest_path TEXT NOT NULL DEFAULT '',
+			name TEXT NOT NULL,
+			type TEXT NOT NULL DEFAULT '',
+			ecosystem TEXT NOT NULL DEFAULT '',
+			current_version TEXT NOT NULL DEFAULT '',
+			latest_version TEXT NOT NULL DEFAULT ''
+		)
+	`)
+	if err != nil {
+		t.Fatalf("failed to create tables: %v", err)
+	}
+	return db
+}
+
+func TestSnapshotRepository_SaveReplacesDay(t *testing.T) {
+	db := setupSnapshotTestDB(t)
+	defer db.Close()
+	repo := NewSnapshotRepository(db)
+	ctx := context.Background()
+
+	lodash := domain.OutdatedEntry{Repository: "org/web", Source: "github", Name: "lodash", Ecosystem: "npm", CurrentVersion: "4.0.0", LatestVersion: "4.17.21"}
+	react := domain.OutdatedEntry{Repository: "org/web", Source: "github", Owners: "frontend", Name: "react", Ecosystem: "npm", CurrentVersion: "17.0.0", LatestVersion: "18.2.0"}
+
+	if err := repo.Save(ctx, domain.StateSnapshot{Day: "2026-03-01", Dependencies: 10, TakenAt: time.Now(), Entries: []domain.OutdatedEntry{lodash, react}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := repo.Save(ctx, domain.StateSnapshot{Day: "2026-03-01", Dependencies: 12, TakenAt: time.Now(), Entries: []domain.OutdatedEntry{react}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	snapshot, err := repo.GetOnOrBefore(ctx, "2026-03-01")
+	if err != nil {
+		t.Fatalf("GetOnOrBefore() error = %v", err)
+	}
+	if snapshot == nil || snapshot.Dependencies != 12 || snapshot.Outdated != 1 || len(snapshot.Entries) != 1 {
+		t.Fatalf("GetOnOrBefore() = %+v, want the second snapshot of the day", snapshot)
+	}
+	if snapshot.Entries[0] != react {
+		t.Errorf("entry = %+v, want %+v", snapshot.Entries[0], react)
+	}
+}
+
+func TestSnapshotRepository_GetOnOrBeforeAndDeleteBefore(t *testing.T) {
+	db := setupSnapshotTestDB(t)
+	defer db.Close()
+	repo := NewSnapshotRepository(db)
+	ctx := context.Background()
+
+	entry := domain.OutdatedEntry{Repository: "org/api", Name: "gin", Ecosystem: "go"}
+	for _, day := range []string{"2026-01-10", "2026-02-10", "2026-03-10"} {
+		if err := repo.Save(ctx, domain.StateSnapshot{Day: day, TakenAt: time.Now(), Entries: []domain.OutdatedEntry{entry}}); err != nil {
+			t.Fatalf("Save(%s) error = %v", day, err)
+		}
+	}
+
+	snapshot, err := repo.GetOnOrBefore(ctx, "2026-03-01")
+	if err != nil {
+		t.Fatalf("GetOnOrBefore() error = %v", err)
+	}
+	if snapshot == nil || snapshot.Day != "2026-02-10" {
+		t.Fatalf("GetOnOrBefore(2026-03-01) = %+v, want the snapshot of 2026-02-10", snapshot)
+	}
+
+	removed, err := repo.DeleteBefore(ctx, "2026-02-10")
+	if err != nil {
+		t.Fatalf("DeleteBefore() error = %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("DeleteBefore() removed %d, want 1", removed)
+	}
+	var entries int
+	if err := db.Get(&entries, "SELECT COUNT(*) FROM snapshot_outdated"); err != nil {
+		t.Fatal(err)
+	}
+	if entries != 2 {
+		t.Errorf("%d archived entries left, want 2", entries)
+	}
+
+	snapshot, err = repo.GetOnOrBefore(ctx, "2026-01-31")
+	if err != nil {
+		t.Fatalf("GetOnOrBefore() error = %v", err)
+	}
+	if snapshot != nil {
+		t.Errorf("GetOnOrBefore(2026-01-31) = %+v, want nil after pruning", snapshot)
+	}
+}
@@ -19,29 +19,28 @@ func NewSourceRepository(db *sqlx.DB) *SourceRepository {
 }
 
 func (r *SourceRepository) Create(ctx context.Context, input domain.SourceInput) (*domain.Source, error) {
+	return createSource(ctx, r.db, input)
+}
+
+func createSource(ctx context.Context, q queryer, input domain.SourceInput) (*domain.Source, error) {
 	// Encrypt token before storing
 	encryptedToken, err := util.Encrypt(input.Token)
 	if err != nil {
 		return nil, err
 	}
 
-	query := `INSERT INTO sources (name, type, token, organization, url, repositories, scan_branch, insecure_skip_verify, membership_only, owner_only, created_at, updated_at)
-              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-              RETURNING id, name, type, token, organization, url, repositories, scan_branch, insecure_skip_verify, membership_only, owner_only, created_at, updated_at, last_scan_at`
+	query := `INSERT INTO sources (name, type, token, organization, url, repositories, scan_branch, additional_branches, fetch_mode, insecure_skip_verify, membership_only, owner_only, import_dependabot_alerts, schedule_cron, scan_window, schedule_timezone, priority, created_at, updated_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+              RETURNING id, name, type, token, organization, url, repositories, scan_branch, additional_branches, fetch_mode, insecure_skip_verify, membership_only, owner_only, import_dependabot_alerts, schedule_cron, scan_window, schedule_timezone, priority, created_at, updated_at, last_scan_at, state`
 
 	now := time.Now()
 	var source domain.Source
-	err = r.db.GetContext(ctx, &source, query, input.Name, input.Type, encryptedToken, input.Organization, input.URL, input.Repositories, input.ScanBranch, input.InsecureSkipVerify, input.MembershipOnly, input.OwnerOnly, now, now)
+	err = q.GetContext(ctx, &source, query, input.Name, input.Type, encryptedToken, input.Organization, input.URL, input.Repositories, input.ScanBranch, input.AdditionalBranches, input.FetchMode, input.InsecureSkipVerify, input.MembershipOnly, input.OwnerOnly, input.ImportDependabotAlerts, input.ScheduleCron, input.ScanWindow, input.ScheduleTimezone, input.Priority, now, now)
 	if err != nil {
 		return nil, err
 	}
 
-	// Decrypt token for return value
-	decrypted, err := util.Decrypt(source.Token)
-	if err != nil {
-		log.Warn().Err(err).Int64("source_id", source.ID).Msg("failed to decrypt token, using as-is")
-	}
-	source.Token = decrypted
+	decryptSourceToken(&source)
 	return &source, nil
 }
 
@@ -52,13 +51,8 @@ func (r *SourceRepository) GetAll(ctx context.Context) ([]domain.Source, error)
 		return nil, err
 	}
 
-	// Decrypt tokens
 	for i := range sources {
-		decrypted, err := util.Decrypt(sources[i].Token)
-		if err != nil {
-			log.Warn().Err(err).Int64("source_id", sources[i].ID).Msg("failed to decrypt token, using as-is")
-		}
-		sources[i].Token = decrypted
+		decryptSourceToken(&sources[i])
 	}
 	return sources, nil
 }
@@ -70,12 +64,7 @@ func (r *SourceRepository) GetByID(ctx context.Context, id int64) (*domain.Sourc
 		return nil, err
 	}
 
-	// Decrypt token
-	decrypted, err := util.Decrypt(source.Token)
-	if err != nil {
-		log.Warn().Err(err).Int64("source_id", source.ID).Msg("failed to decrypt token, using as-is")
-	}
-	source.Token = decrypted
+	decryptSourceToken(&source)
 	return &source, nil
 }
 
@@ -85,33 +74,107 @@ func (r *SourceRepository) Delete(ctx context.Context, id int64) error {
 }
 
 func (r *SourceRepository) UpdateLastScan(ctx context.Context, id int64) error {
-	_, err := r.db.ExecContext(ctx, "UPDATE sources SET last_scan_at = ?, updated_at = ? WHERE id = ?",
+	_, err := r.db.ExecContext(ctx, "UPDATE sources SET last_scan_at = ?, last_scan_error = '', last_scan_error_at = NULL, consecutive_failures = 0, updated_at = ? WHERE id = ?",
 		time.Now(), time.Now(), id)
 	return err
 }
 
+// SetScanError records the error of a failed scan of a source and counts the failure
+func (r *SourceRepository) SetScanError(ctx context.Context, id int64, scanErr string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE sources SET last_scan_error = ?, last_scan_error_at = ?, consecutive_failures = consecutive_failures + 1 WHERE id = ?",
+		scanErr, time.Now(), id)
+	return err
+}
+
+// Disable puts a source in the error state so scans skip it. It returns false when the source
+// was already disabled.
+func (r *SourceRepository) Disable(ctx context.Context, id int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE sources SET state = ?, disabled_at = ? WHERE id = ? AND state != ?",
+		domain.SourceStateError, time.Now(), id, domain.SourceStateError)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// Enable makes a disabled source active again, resetting its failure count
+func (r *SourceRepository) Enable(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE sources SET state = ?, consecutive_failures = 0, disabled_at = NULL WHERE id = ?",
+		domain.SourceStateActive, id)
+	return err
+}
+
 func (r *SourceRepository) Update(ctx context.Context, id int64, input domain.SourceInput) (*domain.Source, error) {
+	return updateSource(ctx, r.db, id, input)
+}
+
+func updateSource(ctx context.Context, q queryer, id int64, input domain.SourceInput) (*domain.Source, error) {
 	// Encrypt token before storing
 	encryptedToken, err := util.Encrypt(input.Token)
 	if err != nil {
 		return nil, err
 	}
 
-	query := `UPDATE sources SET name = ?, type = ?, token = ?, organization = ?, url = ?, repositories = ?, scan_branch = ?, insecure_skip_verify = ?, membership_only = ?, owner_only = ?, updated_at = ?
+	query := `UPDATE sources SET name = ?, type = ?, token = ?, organization = ?, url = ?, repositories = ?, scan_branch = ?, additional_branches = ?, fetch_mode = ?, insecure_skip_verify = ?, membership_only = ?, owner_only = ?, import_dependabot_alerts = ?, schedule_cron = ?, scan_window = ?, schedule_timezone = ?, priority = ?, updated_at = ?,
+                  token_status = '', token_error = '', token_expires_at = NULL, token_checked_at = NULL
               WHERE id = ?
-              RETURNING id, name, type, token, organization, url, repositories, scan_branch, insecure_skip_verify, membership_only, owner_only, created_at, updated_at, last_scan_at`
+              RETURNING id, name, type, token, organization, url, repositories, scan_branch, additional_branches, fetch_mode, insecure_skip_verify, membership_only, owner_only, import_dependabot_alerts, schedule_cron, scan_window, schedule_timezone, priority, created_at, updated_at, last_scan_at,
+                        last_scan_error, last_scan_error_at, state, consecutive_failures, disabled_at`
 
 	var source domain.Source
-	err = r.db.GetContext(ctx, &source, query, input.Name, input.Type, encryptedToken, input.Organization, input.URL, input.Repositories, input.ScanBranch, input.InsecureSkipVerify, input.MembershipOnly, input.OwnerOnly, time.Now(), id)
+	err = q.GetContext(ctx, &source, query, input.Name, input.Type, encryptedToken, input.Organization, input.URL, input.Repositories, input.ScanBranch, input.AdditionalBranches, input.FetchMode, input.InsecureSkipVerify, input.MembershipOnly, input.OwnerOnly, input.ImportDependabotAlerts, input.ScheduleCron, input.ScanWindow, input.ScheduleTimezone, input.Priority, time.Now(), id)
 	if err != nil {
 		return nil, err
 	}
 
-	// Decrypt token for return value
+	decryptSourceToken(&source)
+	return &source, nil
+}
+
+// EncryptPlaintextTokens encrypts tokens that are stored in plaintext (e.g. written before
+// tokens were encrypted) and returns the number of updated sources. Tokens that look encrypted
+// are left alone even when the current key cannot decrypt them, e.g. after a key change.
+func (r *SourceRepository) EncryptPlaintextTokens(ctx context.Context) (int, error) {
+	var rows []struct {
+		ID    int64  `db:"id"`
+		Token string `db:"token"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, "SELECT id, token FROM sources WHERE token != ''"); err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, row := range rows {
+		if util.IsEncrypted(row.Token) {
+			continue
+		}
+		encrypted, err := util.Encrypt(row.Token)
+		if err != nil {
+			return updated, err
+		}
+		if _, err := r.db.ExecContext(ctx, "UPDATE sources SET token = ? WHERE id = ?", encrypted, row.ID); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// UpdateTokenStatus stores the result of a token check
+func (r *SourceRepository) UpdateTokenStatus(ctx context.Context, id int64, status, tokenError string, expiresAt *time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE sources SET token_status = ?, token_error = ?, token_expires_at = ?, token_checked_at = ? WHERE id = ?",
+		status, tokenError, expiresAt, time.Now(), id)
+	return err
+}
+
+// decryptSourceToken decrypts the token of a source and fills the masked token hints
+func decryptSourceToken(source *domain.Source) {
 	decrypted, err := util.Decrypt(source.Token)
 	if err != nil {
 		log.Warn().Err(err).Int64("source_id", source.ID).Msg("failed to decrypt token, using as-is")
 	}
 	source.Token = decrypted
-	return &source, nil
+	source.TokenFingerprint = util.TokenFingerprint(decrypted)
+	source.TokenLast4 = util.TokenLast4(decrypted)
 }
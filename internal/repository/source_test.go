@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/jiin/stale/internal/util"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestSourceRepository_EncryptPlaintextTokens(t *testing.T) {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test db: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE sources (id INTEGER PRIMARY KEY, token TEXT NOT NULL DEFAULT '')`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	// Encrypted with another key: not decryptable now, but must not be encrypted again
+	otherKey := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", 40)))
+	if _, err := db.Exec(`INSERT INTO sources (id, token) VALUES (1, 'ghp_plaintext'), (2, ?), (3, '')`, otherKey); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSourceRepository(db)
+	ctx := context.Background()
+	n, err := repo.EncryptPlaintextTokens(ctx)
+	if err != nil {
+		t.Fatalf("EncryptPlaintextTokens() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("EncryptPlaintextTokens() = %d, want 1", n)
+	}
+
+	var tokens []string
+	if err := db.Select(&tokens, "SELECT token FROM sources ORDER BY id"); err != nil {
+		t.Fatal(err)
+	}
+	if decrypted, _ := util.Decrypt(tokens[0]); tokens[0] == "ghp_plaintext" || decrypted != "ghp_plaintext" {
+		t.Errorf("plaintext token = %q, want it encrypted", tokens[0])
+	}
+	if tokens[1] != otherKey {
+		t.Errorf("token encrypted with another key = %q, want it unchanged", tokens[1])
+	}
+
+	if n, err := repo.EncryptPlaintextTokens(ctx); err != nil || n != 0 {
+		t.Errorf("second EncryptPlaintextTokens() = %d, %v, want nothing left to encrypt", n, err)
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type ViewRepository struct {
+	db *sqlx.DB
+}
+
+func NewViewRepository(db *sqlx.DB) *ViewRepository {
+	return &ViewRepository{db: db}
+}
+
+func (r *ViewRepository) GetAll(ctx context.Context) ([]domain.SavedView, error) {
+	var views []domain.SavedView
+	if err := r.db.SelectContext(ctx, &views, "SELECT * FROM saved_views ORDER BY name"); err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+func (r *ViewRepository) GetByID(ctx context.Context, id int64) (*domain.SavedView, error) {
+	var view domain.SavedView
+	if err := r.db.GetContext(ctx, &view, "SELECT * FROM saved_views WHERE id = ?", id); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+func (r *ViewRepository) Create(ctx context.Context, input domain.SavedViewInput) (*domain.SavedView, error) {
+	query := `INSERT INTO saved_views (name, status, repo, ecosystem, search, sort, columns, created_at, updated_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+              RETURNING *`
+
+	now := time.Now()
+	var view domain.SavedView
+	err := r.db.GetContext(ctx, &view, query, input.Name, input.Status, input.Repo, input.Ecosystem, input.Search, input.Sort, input.Columns, now, now)
+	if err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+func (r *ViewRepository) Update(ctx context.Context, id int64, input domain.SavedViewInput) (*domain.SavedView, error) {
+	query := `UPDATE saved_views SET name = ?, status = ?, repo = ?, ecosystem = ?, search = ?, sort = ?, columns = ?, updated_at = ?
+              WHERE id = ?
+              RETURNING *`
+
+	var view domain.SavedView
+	err := r.db.GetContext(ctx, &view, query, input.Name, input.Status, input.Repo, input.Ecosystem, input.Search, input.Sort, input.Columns, time.Now(), id)
+	if err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+func (r *ViewRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM saved_views WHERE id = ?", id)
+	return err
+}
@@ -0,0 +1,285 @@
+// Package agent scans sources inside a private network and reports their dependencies to a
+// central stale server through its ingest API, for hub-and-spoke deployments where the server
+// cannot reach the Git providers or registries
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/service/httputil"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxReportAttempts bounds the attempts to report a batch while the server is rate
+	// limiting or failing
+	maxReportAttempts = 6
+	// defaultRetryWait is the wait before reporting again, doubled on every attempt
+	defaultRetryWait = 10 * time.Second
+	// maxBatchRepositories bounds the repositories reported in one request, as the server does
+	maxBatchRepositories = 100
+	// maxBatchSize bounds the size of a reported batch below the 16MB the server accepts
+	maxBatchSize = 12 << 20
+)
+
+// Collector fetches, parses and resolves the dependencies of the repositories of a source,
+// see scanner.Scanner.Collect
+type Collector interface {
+	Collect(ctx context.Context, source domain.Source, report func(domain.IngestRequest) error) error
+}
+
+// Config configures an agent
+type Config struct {
+	ServerURL string        // base URL of the central server, e.g. https://stale.example.com
+	APIKey    string        // API key of the central server
+	Name      string        // external source the reported repositories are attributed to
+	Interval  time.Duration // time between runs, 0 to run once
+	Sources   []domain.Source
+}
+
+// Agent collects the dependencies of its sources locally and pushes them to the central server
+type Agent struct {
+	cfg        Config
+	collector  Collector
+	httpClient *http.Client
+	retryWait  time.Duration
+}
+
+// New creates an agent reporting what collector finds in the sources of cfg
+func New(cfg Config, collector Collector) *Agent {
+	cfg.ServerURL = strings.TrimSuffix(cfg.ServerURL, "/")
+	return &Agent{
+		cfg:        cfg,
+		collector:  collector,
+		httpClient: httputil.NewClient(2 * time.Minute),
+		retryWait:  defaultRetryWait,
+	}
+}
+
+// Run collects and reports every source, then again every interval until ctx is canceled. With a
+// zero interval it runs once and returns the error of the run.
+func (a *Agent) Run(ctx context.Context) error {
+	if a.cfg.Interval <= 0 {
+		return a.RunOnce(ctx)
+	}
+
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if err := a.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("agent run failed")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// RunOnce collects and reports every source. Failing sources are logged and skipped; their errors
+// are returned together.
+func (a *Agent) RunOnce(ctx context.Context) error {
+	var errs []error
+	for _, source := range a.cfg.Sources {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Info().Str("source", source.Name).Msg("agent collecting source")
+		b := &batch{}
+		err := a.collector.Collect(ctx, source, func(req domain.IngestRequest) error {
+			return a.add(ctx, b, req)
+		})
+		err = errors.Join(err, a.flush(ctx, b))
+		if err != nil {
+			log.Error().Err(err).Str("source", source.Name).Msg("agent failed to collect source")
+			errs = append(errs, fmt.Errorf("source %s: %w", source.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// batch is the collected dependency lists of a source waiting to be reported
+type batch struct {
+	repos []string
+	lists []json.RawMessage
+	size  int
+}
+
+// add queues the dependencies of a repository, reporting the queued ones first when the batch is
+// full
+func (a *Agent) add(ctx context.Context, b *batch, req domain.IngestRequest) error {
+	req.Source = a.cfg.Name
+	list, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if len(b.lists) == maxBatchRepositories || (len(b.lists) > 0 && b.size+len(list) > maxBatchSize) {
+		if err := a.flush(ctx, b); err != nil {
+			return err
+		}
+	}
+	b.repos = append(b.repos, req.Repository)
+	b.lists = append(b.lists, list)
+	b.size += len(list)
+	return nil
+}
+
+// flush reports the queued dependency lists and empties the batch
+func (a *Agent) flush(ctx context.Context, b *batch) error {
+	if len(b.lists) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(struct {
+		Repositories []json.RawMessage `json:"repositories"`
+	}{b.lists})
+	repos := b.repos
+	*b = batch{}
+	if err != nil {
+		return err
+	}
+	return a.report(ctx, repos, body)
+}
+
+// report pushes a batch of dependency lists to the ingest API of the server, waiting and retrying
+// while the server is rate limiting or failing. Lists the server rejects are logged and skipped;
+// an error is returned when the server refuses the API key or stays unavailable.
+func (a *Agent) report(ctx context.Context, repos []string, body []byte) error {
+	wait := a.retryWait
+	for attempt := 1; ; attempt++ {
+		status, content, err := a.post(ctx, body)
+		switch {
+		case err == nil && status < 300:
+			logReported(repos, content)
+			return nil
+		case err == nil && (status == http.StatusUnauthorized || status == http.StatusForbidden):
+			return fmt.Errorf("server refused the API key: %s", errorMessage(content))
+		case err == nil && status != http.StatusTooManyRequests && status < 500:
+			log.Warn().Strs("repos", repos).Int("status", status).Str("error", errorMessage(content)).Msg("server rejected the dependencies of repositories")
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("server responded %d: %s", status, errorMessage(content))
+		}
+		if attempt == maxReportAttempts {
+			return fmt.Errorf("failed to report %d repositories: %w", len(repos), err)
+		}
+
+		log.Debug().Err(err).Int("repos", len(repos)).Dur("wait", wait).Msg("retrying report to server")
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wait *= 2
+	}
+}
+
+// logReported logs the outcome of every repository of a reported batch
+func logReported(repos []string, content []byte) {
+	var result domain.IngestBatchResult
+	if err := json.Unmarshal(content, &result); err != nil {
+		log.Info().Strs("repos", repos).Msg("agent reported repositories")
+		return
+	}
+	for _, item := range result.Repositories {
+		switch {
+		case item.Error != "":
+			log.Warn().Str("repo", item.Repository).Str("error", item.Error).Msg("server rejected the dependencies of a repository")
+		case item.Result != nil:
+			log.Info().Str("repo", item.Repository).Int("dependencies", item.Result.Dependencies).Msg("agent reported repository")
+		}
+	}
+}
+
+// post sends an ingest batch and returns the response status and body
+func (a *Agent) post(ctx context.Context, body []byte) (int, []byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.ServerURL+"/api/v1/ingest/batch", bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if a.cfg.APIKey != "" {
+		httpReq.Header.Set("X-API-Key", a.cfg.APIKey)
+	}
+
+	resp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, content, nil
+}
+
+// errorMessage returns the error message of a response of the server
+func errorMessage(content []byte) string {
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	if len(content) > 64<<10 {
+		content = content[:64<<10]
+	}
+	if json.Unmarshal(content, &apiErr) == nil && apiErr.Message != "" {
+		return apiErr.Message
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// LoadSources reads the sources to scan from a configuration document as exported by the server
+// (GET /api/v1/config), with their tokens filled in. Other sections of the document are ignored.
+func LoadSources(path string) ([]domain.Source, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc domain.ConfigDocument
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("invalid configuration document: %w", err)
+	}
+	if len(doc.Sources) == 0 {
+		return nil, errors.New("configuration document has no sources")
+	}
+
+	sources := make([]domain.Source, len(doc.Sources))
+	for i, input := range doc.Sources {
+		input.Type = strings.ToLower(input.Type)
+		if input.Type == "" {
+			input.Type = "github"
+		}
+		if input.Name == "" {
+			return nil, fmt.Errorf("sources[%d]: name is required", i)
+		}
+		if input.Type != "github" && input.Type != "gitlab" && input.Type != "git" {
+			return nil, fmt.Errorf("sources[%d]: type must be 'github', 'gitlab' or 'git'", i)
+		}
+		sources[i] = domain.Source{
+			Name:               input.Name,
+			Type:               input.Type,
+			Token:              input.Token,
+			Organization:       input.Organization,
+			URL:                input.URL,
+			Repositories:       input.Repositories,
+			ScanBranch:         input.ScanBranch,
+			FetchMode:          input.FetchMode,
+			InsecureSkipVerify: input.InsecureSkipVerify,
+			MembershipOnly:     input.MembershipOnly,
+			OwnerOnly:          input.OwnerOnly,
+		}
+	}
+	return sources, nil
+}
@@ -0,0 +1,180 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+// fakeCollector reports the same repositories for every source
+type fakeCollector struct {
+	repos []string
+}
+
+func (c *fakeCollector) Collect(ctx context.Context, source domain.Source, report func(domain.IngestRequest) error) error {
+	for _, repo := range c.repos {
+		req := domain.IngestRequest{
+			Repository:   repo,
+			Dependencies: []domain.IngestDependency{{Name: "lodash", Version: "4.17.20", LatestVersion: "4.17.21", Ecosystem: "npm"}},
+		}
+		if err := report(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestAgent(serverURL string, repos ...string) *Agent {
+	a := New(Config{
+		ServerURL: serverURL + "/",
+		APIKey:    "key",
+		Name:      "agent-test",
+		Sources:   []domain.Source{{Name: "github", Type: "github"}},
+	}, &fakeCollector{repos: repos})
+	a.retryWait = time.Millisecond
+	return a
+}
+
+func TestAgent_RunOnce_Reports(t *testing.T) {
+	var batches int
+	var received []domain.IngestRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/ingest/batch" || r.Header.Get("X-API-Key") != "key" {
+			t.Errorf("request %s with key %q, want /api/v1/ingest/batch with key", r.URL.Path, r.Header.Get("X-API-Key"))
+		}
+		var req domain.IngestBatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		batches++
+		received = append(received, req.Repositories...)
+		w.Write([]byte(`{"repositories": [{"repository": "org/a", "result": {"dependencies": 1}}, {"repository": "org/b", "error": "nope"}]}`))
+	}))
+	defer srv.Close()
+
+	if err := newTestAgent(srv.URL, "org/a", "org/b").RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if batches != 1 {
+		t.Errorf("server received %d batches, want 1", batches)
+	}
+	if len(received) != 2 {
+		t.Fatalf("server received %d reports, want 2", len(received))
+	}
+	if received[0].Source != "agent-test" || received[1].Repository != "org/b" {
+		t.Errorf("reports = %+v, want org/a and org/b from agent-test", received)
+	}
+	if received[0].Dependencies[0].LatestVersion != "4.17.21" {
+		t.Errorf("latest version = %q, want the one looked up by the agent", received[0].Dependencies[0].LatestVersion)
+	}
+}
+
+func TestAgent_RunOnce_SplitsBatches(t *testing.T) {
+	var sizes []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req domain.IngestBatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		sizes = append(sizes, len(req.Repositories))
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	repos := make([]string, maxBatchRepositories+20)
+	for i := range repos {
+		repos[i] = fmt.Sprintf("org/repo-%d", i)
+	}
+	if err := newTestAgent(srv.URL, repos...).RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(sizes) != 2 || sizes[0] != maxBatchRepositories || sizes[1] != 20 {
+		t.Errorf("batch sizes = %v, want [%d 20]", sizes, maxBatchRepositories)
+	}
+}
+
+func TestAgent_RunOnce_RetriesWhileRateLimited(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	if err := newTestAgent(srv.URL, "org/a").RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("server called %d times, want 3", calls.Load())
+	}
+}
+
+func TestAgent_RunOnce_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantErr   bool
+		wantCalls int32
+	}{
+		{"refused key stops the source", http.StatusUnauthorized, true, 1},
+		{"rejected batch is skipped", http.StatusBadRequest, false, 1},
+		{"unavailable server gives up", http.StatusServiceUnavailable, true, maxReportAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"message": "nope"}`))
+			}))
+			defer srv.Close()
+
+			err := newTestAgent(srv.URL, "org/a", "org/b").RunOnce(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RunOnce() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("server called %d times, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestLoadSources(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	sources, err := LoadSources(write("ok.json", `{"sources": [{"name": "internal", "type": "GitLab", "token": "glpat", "url": "https://git.corp"}, {"name": "gh", "token": "ghp"}]}`))
+	if err != nil {
+		t.Fatalf("LoadSources() error = %v", err)
+	}
+	if len(sources) != 2 || sources[0].Type != "gitlab" || sources[0].URL != "https://git.corp" || sources[1].Type != "github" {
+		t.Errorf("sources = %+v, want a gitlab and a github source", sources)
+	}
+
+	for _, content := range []string{`not json`, `{"sources": []}`, `{"sources": [{"type": "github"}]}`, `{"sources": [{"name": "x", "type": "svn"}]}`} {
+		if _, err := LoadSources(write("bad.json", content)); err == nil {
+			t.Errorf("LoadSources(%s) succeeded, want error", content)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package android looks up the latest Android Gradle Plugin release and SDK platform
+package android
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jiin/stale/internal/service/cache"
+	"github.com/jiin/stale/internal/service/httputil"
+)
+
+// Names of the dependencies reported for Android Gradle builds
+const (
+	PluginName = "com.android.tools.build:gradle"
+	CompileSDK = "compileSdk"
+	TargetSDK  = "targetSdk"
+)
+
+// googleMavenURL is Google's Maven repository, which publishes the Android Gradle Plugin
+const googleMavenURL = "https://dl.google.com/dl/android/maven2"
+
+// repositoryURL lists the packages the Android SDK manager installs, SDK platforms included
+const repositoryURL = "https://dl.google.com/android/repository/repository2-3.xml"
+
+// stableChannel is the SDK repository channel of stable packages
+const stableChannel = "channel-0"
+
+// Cache TTL: 1 hour - AGP and SDK releases don't change that frequently.
+// Expired entries are refreshed with conditional requests, see httputil.ConditionalCacheTransport
+const cacheTTL = 1 * time.Hour
+
+type Client struct {
+	httpClient    *http.Client
+	retryConfig   httputil.RetryConfig
+	cache         *cache.Cache[string]
+	mavenURL      string
+	repositoryURL string
+}
+
+// Platform is a package of the SDK repository, e.g. platforms;android-34
+type Platform struct {
+	Path    string `xml:"path,attr"`
+	Channel struct {
+		Ref string `xml:"ref,attr"`
+	} `xml:"channelRef"`
+}
+
+func New() *Client {
+	return &Client{
+		httpClient:    httputil.NewCachingClient(10 * time.Second),
+		retryConfig:   httputil.DefaultRetryConfig(),
+		cache:         cache.New[string](cacheTTL),
+		mavenURL:      googleMavenURL,
+		repositoryURL: repositoryURL,
+	}
+}
+
+// SetCacheTTL changes how long latest versions are cached, 0 disables the cache
+func (c *Client) SetCacheTTL(ttl time.Duration) {
+	c.cache.SetTTL(ttl)
+}
+
+// GetLatestVersion returns the latest stable Android Gradle Plugin version for PluginName and the
+// latest stable SDK platform API level for CompileSDK and TargetSDK
+func (c *Client) GetLatestVersion(ctx context.Context, name string) (string, error) {
+	switch name {
+	case PluginName:
+		return c.getLatestPlugin(ctx)
+	case CompileSDK, TargetSDK:
+		return c.getLatestPlatform(ctx)
+	}
+	return "", fmt.Errorf("unknown android dependency %s", name)
+}
+
+func (c *Client) getLatestPlugin(ctx context.Context) (string, error) {
+	if version, found := c.cache.Get("plugin"); found {
+		return version, nil
+	}
+
+	reqURL := c.mavenURL + "/com/android/tools/build/gradle/maven-metadata.xml"
+	var metadata struct {
+		Versions []string `xml:"versioning>versions>version"`
+	}
+	if err := c.getXML(ctx, reqURL, &metadata); err != nil {
+		return "", err
+	}
+
+	latest := LatestStable(metadata.Versions)
+	if latest == "" {
+		return "", fmt.Errorf("no stable Android Gradle Plugin version found")
+	}
+	c.cache.Set("plugin", latest)
+	return latest, nil
+}
+
+func (c *Client) getLatestPlatform(ctx context.Context) (string, error) {
+	if level, found := c.cache.Get("platform"); found {
+		return level, nil
+	}
+
+	var repository struct {
+		Packages []Platform `xml:"remotePackage"`
+	}
+	if err := c.getXML(ctx, c.repositoryURL, &repository); err != nil {
+		return "", err
+	}
+
+	level := LatestPlatform(repository.Packages)
+	if level == 0 {
+		return "", fmt.Errorf("no stable Android SDK platform found")
+	}
+	latest := strconv.Itoa(level)
+	c.cache.Set("platform", latest)
+	return latest, nil
+}
+
+func (c *Client) getXML(ctx context.Context, reqURL string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httputil.DoWithRetry(ctx, c.httpClient, req, c.retryConfig)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", reqURL, resp.StatusCode)
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", reqURL, err)
+	}
+	return nil
+}
+
+// LatestStable returns the highest version that is not a prerelease (e.g. 8.5.0-alpha03), or an
+// empty string when there is none
+func LatestStable(versions []string) string {
+	var latest *semver.Version
+	var name string
+	for _, v := range versions {
+		parsed, err := semver.NewVersion(v)
+		if err != nil || parsed.Prerelease() != "" {
+			continue
+		}
+		if latest == nil || parsed.GreaterThan(latest) {
+			latest, name = parsed, v
+		}
+	}
+	return name
+}
+
+// LatestPlatform returns the highest API level of the stable SDK platforms, or 0 when there is
+// none. Preview platforms, which are named by codename, and extension levels are left out.
+func LatestPlatform(packages []Platform) int {
+	latest := 0
+	for _, p := range packages {
+		level, ok := strings.CutPrefix(p.Path, "platforms;android-")
+		if !ok || p.Channel.Ref != stableChannel {
+			continue
+		}
+		if n, err := strconv.Atoi(level); err == nil && n > latest {
+			latest = n
+		}
+	}
+	return latest
+}
@@ -0,0 +1,76 @@
+package android
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/service/cache"
+	"github.com/jiin/stale/internal/service/httputil"
+)
+
+func newTestClient(baseURL string) *Client {
+	return &Client{
+		httpClient:    &http.Client{Timeout: 5 * time.Second},
+		retryConfig:   httputil.RetryConfig{MaxRetries: 1, BaseDelay: 10 * time.Millisecond},
+		cache:         cache.New[string](time.Minute),
+		mavenURL:      baseURL,
+		repositoryURL: baseURL + "/repository2-3.xml",
+	}
+}
+
+func TestGetLatestVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		switch r.URL.Path {
+		case "/com/android/tools/build/gradle/maven-metadata.xml":
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>com.android.tools.build</groupId>
+  <artifactId>gradle</artifactId>
+  <versioning>
+    <versions>
+      <version>8.2.0</version>
+      <version>8.10.1</version>
+      <version>8.11.0-alpha05</version>
+    </versions>
+  </versioning>
+</metadata>`))
+		case "/repository2-3.xml":
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<sdk:sdk-repository xmlns:sdk="http://schemas.android.com/sdk/android/repo/repository2/03">
+  <channel id="channel-0">stable</channel>
+  <channel id="channel-1">beta</channel>
+  <remotePackage path="platforms;android-34"><channelRef ref="channel-0"/></remotePackage>
+  <remotePackage path="platforms;android-35"><channelRef ref="channel-0"/></remotePackage>
+  <remotePackage path="platforms;android-36"><channelRef ref="channel-1"/></remotePackage>
+  <remotePackage path="platforms;android-35-ext14"><channelRef ref="channel-0"/></remotePackage>
+  <remotePackage path="build-tools;36.0.0"><channelRef ref="channel-0"/></remotePackage>
+</sdk:sdk-repository>`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	tests := map[string]string{PluginName: "8.10.1", CompileSDK: "35", TargetSDK: "35"}
+	for name, want := range tests {
+		got, err := client.GetLatestVersion(context.Background(), name)
+		if err != nil {
+			t.Fatalf("GetLatestVersion(%s) error = %v", name, err)
+		}
+		if got != want {
+			t.Errorf("GetLatestVersion(%s) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGetLatestVersion_Unknown(t *testing.T) {
+	client := newTestClient("http://127.0.0.1:0")
+	if _, err := client.GetLatestVersion(context.Background(), "minSdk"); err == nil {
+		t.Error("GetLatestVersion() expected error for an unknown name")
+	}
+}
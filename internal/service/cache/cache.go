@@ -61,6 +61,22 @@ func (c *Cache[T]) Set(key string, value T) {
 	}
 }
 
+// SetTTL changes the TTL of items stored from now on and shortens the remaining lifetime of
+// cached items to at most ttl. A TTL of 0 disables the cache.
+func (c *Cache[T]) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ttl = ttl
+	limit := time.Now().Add(ttl).UnixNano()
+	for key, item := range c.items {
+		if item.Expiration > limit {
+			item.Expiration = limit
+			c.items[key] = item
+		}
+	}
+}
+
 // Delete removes an item from the cache
 func (c *Cache[T]) Delete(key string) {
 	c.mu.Lock()
@@ -201,3 +201,25 @@ func TestCache_SliceType(t *testing.T) {
 		t.Errorf("Get(names) has %d items, want 3", len(val))
 	}
 }
+
+func TestCache_SetTTL(t *testing.T) {
+	c := New[string](time.Hour)
+	c.Set("key1", "value1")
+
+	c.SetTTL(time.Minute)
+	if c.items["key1"].Expiration > time.Now().Add(time.Minute).UnixNano() {
+		t.Error("SetTTL() should shorten the lifetime of cached items")
+	}
+	if _, found := c.Get("key1"); !found {
+		t.Error("expected to find key1 before its shortened TTL")
+	}
+
+	c.SetTTL(0)
+	c.Set("key2", "value2")
+	if _, found := c.Get("key1"); found {
+		t.Error("key1 should expire when the cache is disabled")
+	}
+	if _, found := c.Get("key2"); found {
+		t.Error("key2 should not be cached with a TTL of 0")
+	}
+}
@@ -0,0 +1,135 @@
+// Package cocoapods looks up pods in the CocoaPods trunk
+package cocoapods
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jiin/stale/internal/service/cache"
+	"github.com/jiin/stale/internal/service/httputil"
+)
+
+const trunkURL = "https://trunk.cocoapods.org/api/v1"
+
+// Cache TTL: 1 hour - pod versions don't change that frequently.
+// Expired entries are refreshed with conditional requests, see httputil.ConditionalCacheTransport
+const cacheTTL = 1 * time.Hour
+
+type Client struct {
+	httpClient  *http.Client
+	retryConfig httputil.RetryConfig
+	cache       *cache.Cache[string]
+	baseURL     string
+}
+
+// PodInfo lists the published versions and the owners of a pod
+type PodInfo struct {
+	Versions []struct {
+		Name string `json:"name"`
+	} `json:"versions"`
+	Owners []struct {
+		Name string `json:"name"`
+	} `json:"owners"`
+}
+
+func New() *Client {
+	return &Client{
+		httpClient:  httputil.NewCachingClient(10 * time.Second),
+		retryConfig: httputil.DefaultRetryConfig(),
+		cache:       cache.New[string](cacheTTL),
+		baseURL:     trunkURL,
+	}
+}
+
+// SetCacheTTL changes how long latest versions are cached, 0 disables the cache
+func (c *Client) SetCacheTTL(ttl time.Duration) {
+	c.cache.SetTTL(ttl)
+}
+
+// GetLatestVersion returns the latest stable version of a pod
+func (c *Client) GetLatestVersion(ctx context.Context, pod string) (string, error) {
+	if version, found := c.cache.Get(pod); found {
+		return version, nil
+	}
+
+	info, err := c.getPod(ctx, pod)
+	if err != nil {
+		return "", err
+	}
+	versions := make([]string, 0, len(info.Versions))
+	for _, v := range info.Versions {
+		versions = append(versions, v.Name)
+	}
+
+	latest := LatestStable(versions)
+	if latest == "" {
+		return "", fmt.Errorf("no stable version found for %s", pod)
+	}
+	c.cache.Set(pod, latest)
+	return latest, nil
+}
+
+// GetMaintainers returns the names of the owners of a pod on trunk
+func (c *Client) GetMaintainers(ctx context.Context, pod string) ([]string, error) {
+	info, err := c.getPod(ctx, pod)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make([]string, 0, len(info.Owners))
+	for _, o := range info.Owners {
+		if o.Name != "" {
+			owners = append(owners, o.Name)
+		}
+	}
+	return owners, nil
+}
+
+func (c *Client) getPod(ctx context.Context, pod string) (*PodInfo, error) {
+	reqURL := fmt.Sprintf("%s/pods/%s", c.baseURL, url.PathEscape(pod))
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httputil.DoWithRetry(ctx, c.httpClient, req, c.retryConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("pod %s not found", pod)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cocoapods trunk returned %d for %s", resp.StatusCode, pod)
+	}
+
+	var info PodInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// LatestStable returns the highest version that is not a prerelease, or an empty string when
+// there is none. Versions that are not semantic versions are left out.
+func LatestStable(versions []string) string {
+	var latest *semver.Version
+	var name string
+	for _, v := range versions {
+		parsed, err := semver.NewVersion(v)
+		if err != nil || parsed.Prerelease() != "" {
+			continue
+		}
+		if latest == nil || parsed.GreaterThan(latest) {
+			latest, name = parsed, v
+		}
+	}
+	return name
+}
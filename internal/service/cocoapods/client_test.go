@@ -0,0 +1,79 @@
+package cocoapods
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/service/cache"
+	"github.com/jiin/stale/internal/service/httputil"
+)
+
+func newTestClient(baseURL string) *Client {
+	return &Client{
+		httpClient:  &http.Client{Timeout: 5 * time.Second},
+		retryConfig: httputil.RetryConfig{MaxRetries: 1, BaseDelay: 10 * time.Millisecond},
+		cache:       cache.New[string](time.Minute),
+		baseURL:     baseURL,
+	}
+}
+
+func TestGetLatestVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pods/Alamofire" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"versions":[{"name":"5.8.1"},{"name":"5.9.0-beta.1"},{"name":"5.10.2"},{"name":"4.9.1"}]}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	version, err := client.GetLatestVersion(context.Background(), "Alamofire")
+	if err != nil {
+		t.Fatalf("GetLatestVersion() error = %v", err)
+	}
+	if version != "5.10.2" {
+		t.Errorf("GetLatestVersion() = %q, want 5.10.2", version)
+	}
+}
+
+func TestGetLatestVersion_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	if _, err := client.GetLatestVersion(context.Background(), "Missing"); err == nil {
+		t.Error("GetLatestVersion() expected error for a missing pod")
+	}
+}
+
+func TestGetMaintainers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"versions":[{"name":"5.10.2"}],"owners":[{"name":"Jon Shier","email":"jon@example.com"},{"name":""}]}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	owners, err := client.GetMaintainers(context.Background(), "Alamofire")
+	if err != nil {
+		t.Fatalf("GetMaintainers() error = %v", err)
+	}
+	if len(owners) != 1 || owners[0] != "Jon Shier" {
+		t.Errorf("GetMaintainers() = %v, want [Jon Shier]", owners)
+	}
+}
+
+func TestLatestStable(t *testing.T) {
+	if got := LatestStable([]string{"1.0.0", "1.1.0-rc.1", "not-a-version"}); got != "1.0.0" {
+		t.Errorf("LatestStable() = %q, want 1.0.0", got)
+	}
+	if got := LatestStable([]string{"2.0.0-beta"}); got != "" {
+		t.Errorf("LatestStable() = %q, want empty", got)
+	}
+}
@@ -2,15 +2,26 @@ package email
 
 import (
 	"bytes"
+	"crypto/rand"
 	"crypto/tls"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"html/template"
+	"mime"
+	"mime/multipart"
 	"net"
+	"net/mail"
 	"net/smtp"
+	"net/textproto"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/i18n"
 	"github.com/rs/zerolog/log"
 )
 
@@ -20,26 +31,244 @@ func New() *Service {
 	return &Service{}
 }
 
-func (s *Service) SendNewOutdatedReport(settings *domain.Settings, report *domain.NewOutdatedReport) error {
+// Thread places an email in a thread: MessageID identifies the email and InReplyTo, when set,
+// is the Message-ID of the email that started the thread
+type Thread struct {
+	MessageID string
+	InReplyTo string
+}
+
+// NewMessageID returns a unique Message-ID in the domain of the sender address
+func NewMessageID(from string) string {
+	domainPart := "stale.localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if _, d, ok := strings.Cut(addr.Address, "@"); ok && d != "" {
+			domainPart = d
+		}
+	}
+	random := make([]byte, 8)
+	rand.Read(random)
+	return fmt.Sprintf("<stale.%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domainPart)
+}
+
+func (s *Service) SendNewOutdatedReport(settings *domain.Settings, report *domain.NewOutdatedReport, thread Thread) error {
 	if !settings.EmailEnabled {
 		return nil
 	}
 
-	if len(report.NewOutdated) == 0 {
+	if len(report.NewOutdated) == 0 && report.NewRepos == 0 && report.RemovedRepos == 0 {
 		log.Debug().Msg("no new outdated dependencies to report")
 		return nil
 	}
 
-	subject := fmt.Sprintf("[Stale] %d new outdated dependencies found", len(report.NewOutdated))
-	body, err := s.buildEmailBody(report)
+	return s.sendLocalized(settings, thread, func(locale string) (string, string, []attachment, error) {
+		body, err := s.buildEmailBody(locale, report)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("failed to build email body: %w", err)
+		}
+		attachments, err := reportAttachments(settings, locale, report)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("failed to build email attachment: %w", err)
+		}
+		return buildSubject(locale, report), body, attachments, nil
+	})
+}
+
+// PreviewNewOutdatedReport renders the new outdated dependencies notification without sending it,
+// reporting whether it would be sent with the given settings. The notification is rendered in the
+// given locale, or the configured one when empty.
+func (s *Service) PreviewNewOutdatedReport(settings *domain.Settings, report *domain.NewOutdatedReport, locale string) (*domain.NotificationPreview, error) {
+	locale = i18n.Resolve(locale, settings.Locale)
+	preview := &domain.NotificationPreview{
+		Channel:   "email",
+		Enabled:   settings.EmailEnabled,
+		WouldSend: true,
+		ScanID:    report.ScanID,
+		Locale:    locale,
+		From:      settings.EmailFrom,
+		To:        parseRecipients(settings.EmailTo),
+	}
+
+	switch {
+	case !settings.EmailEnabled:
+		preview.WouldSend, preview.Reason = false, i18n.T(locale, "preview.email_disabled")
+	case !settings.EmailNotifyNewOutdated:
+		preview.WouldSend, preview.Reason = false, i18n.T(locale, "preview.notify_disabled")
+	case len(preview.To) == 0:
+		preview.WouldSend, preview.Reason = false, i18n.T(locale, "preview.no_recipients")
+	case len(report.NewOutdated) == 0 && report.NewRepos == 0 && report.RemovedRepos == 0:
+		preview.WouldSend, preview.Reason = false, i18n.T(locale, "preview.nothing_to_report")
+		return preview, nil
+	}
+
+	body, err := s.buildEmailBody(locale, report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build email body: %w", err)
+	}
+	attachments, err := reportAttachments(settings, locale, report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build email attachment: %w", err)
+	}
+	preview.Subject = buildSubject(locale, report)
+	preview.Body = body
+	for _, a := range attachments {
+		preview.Attachments = append(preview.Attachments, a.filename)
+	}
+	return preview, nil
+}
+
+// reportAttachments returns the CSV of the newly outdated dependencies when attaching it is enabled
+func reportAttachments(settings *domain.Settings, locale string, report *domain.NewOutdatedReport) ([]attachment, error) {
+	if !settings.EmailAttachCSV || len(report.NewOutdated) == 0 {
+		return nil, nil
+	}
+	content, err := buildReportCSV(locale, report.NewOutdated)
 	if err != nil {
-		return fmt.Errorf("failed to build email body: %w", err)
+		return nil, err
+	}
+	return []attachment{{
+		filename:    fmt.Sprintf("new_outdated_dependencies_scan_%d.csv", report.ScanID),
+		contentType: "text/csv",
+		content:     content,
+	}}, nil
+}
+
+// buildReportCSV writes dependencies with the columns of the dependency CSV export
+func buildReportCSV(locale string, deps []domain.DependencyWithRepo) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	header := []string{"column.no", "column.repository", "column.owners", "column.source", "column.dependency", "column.ecosystem", "column.type", "column.current_version", "column.latest_version"}
+	for i, key := range header {
+		header[i] = i18n.T(locale, key)
 	}
+	writer.Write(header)
+	for i, dep := range deps {
+		writer.Write([]string{
+			strconv.Itoa(i + 1),
+			dep.RepoFullName,
+			dep.RepoOwners,
+			dep.SourceName,
+			dep.Name,
+			dep.Ecosystem,
+			dep.Type,
+			dep.CurrentVersion,
+			dep.LatestVersion,
+		})
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
 
-	return s.sendMail(settings, subject, body)
+// parseRecipients splits a comma-separated recipient list, dropping empty entries
+func parseRecipients(list string) []string {
+	recipients := []string{}
+	for _, r := range strings.Split(list, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			recipients = append(recipients, r)
+		}
+	}
+	return recipients
 }
 
-func (s *Service) buildEmailBody(report *domain.NewOutdatedReport) (string, error) {
+// ParseRecipientLocales parses a comma-separated list of recipient=locale pairs, e.g.
+// "kim@example.com=ko, lee@example.com=en", into locales keyed by lowercased address
+func ParseRecipientLocales(list string) (map[string]string, error) {
+	locales := make(map[string]string)
+	for _, entry := range parseRecipients(list) {
+		recipient, tag, ok := strings.Cut(entry, "=")
+		recipient = strings.ToLower(strings.TrimSpace(recipient))
+		if !ok || recipient == "" {
+			return nil, fmt.Errorf("invalid recipient locale %q, expected recipient=locale", entry)
+		}
+		locale := i18n.Normalize(tag)
+		if locale == "" {
+			return nil, fmt.Errorf("unsupported locale %q for %s", strings.TrimSpace(tag), recipient)
+		}
+		locales[recipient] = locale
+	}
+	return locales, nil
+}
+
+// recipientGroup is the recipients that receive notifications in a locale
+type recipientGroup struct {
+	locale string
+	to     []string
+}
+
+// recipientGroups groups the configured recipients by the locale of their notifications: their
+// own locale in email_recipient_locales, otherwise the global one. Groups are ordered by their
+// first recipient.
+func recipientGroups(settings *domain.Settings) []recipientGroup {
+	overrides, err := ParseRecipientLocales(settings.EmailRecipientLocales)
+	if err != nil {
+		log.Warn().Err(err).Msg("ignoring invalid recipient locales")
+	}
+	global := i18n.Resolve(settings.Locale)
+
+	var groups []recipientGroup
+	index := make(map[string]int)
+	for _, r := range parseRecipients(settings.EmailTo) {
+		locale := global
+		if l, ok := overrides[strings.ToLower(r)]; ok {
+			locale = l
+		}
+		i, ok := index[locale]
+		if !ok {
+			i = len(groups)
+			index[locale] = i
+			groups = append(groups, recipientGroup{locale: locale})
+		}
+		groups[i].to = append(groups[i].to, r)
+	}
+	return groups
+}
+
+// sendLocalized sends an email built by build in the locale of each recipient group. The first
+// email takes the Message-ID of the thread; the others get their own in the same thread.
+func (s *Service) sendLocalized(settings *domain.Settings, thread Thread, build func(locale string) (subject, body string, attachments []attachment, err error)) error {
+	var errs []error
+	for i, group := range recipientGroups(settings) {
+		subject, body, attachments, err := build(group.locale)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			thread.MessageID = ""
+		}
+		if err := s.sendMail(settings, group.to, subject, body, thread, attachments...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// templateFuncs are the functions email templates use to translate and format in a locale
+func templateFuncs(locale string) template.FuncMap {
+	return template.FuncMap{
+		"t":     func(key string, args ...interface{}) string { return i18n.T(locale, key, args...) },
+		"num":   func(n int) string { return i18n.Number(locale, n) },
+		"date":  func(t time.Time) string { return i18n.Date(locale, t) },
+		"deref": func(n *int) int { return *n },
+	}
+}
+
+// buildSubject summarizes the report, falling back to repository discovery when
+// no new outdated dependencies were found
+func buildSubject(locale string, report *domain.NewOutdatedReport) string {
+	num := func(n int) string { return i18n.Number(locale, n) }
+	switch {
+	case len(report.NewOutdated) > 0 && report.NewRepos > 0:
+		return i18n.T(locale, "new_outdated.subject_with_repos", num(len(report.NewOutdated)), num(report.NewRepos))
+	case len(report.NewOutdated) > 0:
+		return i18n.T(locale, "new_outdated.subject", num(len(report.NewOutdated)))
+	case report.NewRepos > 0:
+		return i18n.T(locale, "new_outdated.subject_new_repos", num(report.NewRepos))
+	default:
+		return i18n.T(locale, "new_outdated.subject_removed_repos", num(report.RemovedRepos))
+	}
+}
+
+func (s *Service) buildEmailBody(locale string, report *domain.NewOutdatedReport) (string, error) {
 	tmpl := `<!DOCTYPE html>
 <html>
 <head>
@@ -52,6 +281,7 @@ table { width: 100%; border-collapse: collapse; }
 th { text-align: left; padding: 12px; background: #f8f9fa; border-bottom: 2px solid #dee2e6; color: #495057; font-weight: 600; }
 td { padding: 12px; border-bottom: 1px solid #dee2e6; }
 .repo { color: #0066cc; }
+.owners { color: #666; font-size: 13px; }
 .dep { font-weight: 500; }
 .version { font-family: monospace; padding: 2px 6px; border-radius: 4px; font-size: 13px; }
 .current { background: #fff3cd; color: #856404; }
@@ -66,19 +296,25 @@ td { padding: 12px; border-bottom: 1px solid #dee2e6; }
 </head>
 <body>
 <div class="container">
-<h1>New Outdated Dependencies Found</h1>
-<p class="summary">{{len .NewOutdated}} new outdated dependencies were detected during scan #{{.ScanID}}.</p>
+<h1>{{if .NewOutdated}}{{t "new_outdated.title"}}{{else}}{{t "new_outdated.title_repos"}}{{end}}</h1>
+{{if .NewOutdated}}<p class="summary">{{t "new_outdated.summary" (num (len .NewOutdated)) .ScanID}}</p>{{end}}
+{{if .NewRepos}}<p class="summary">{{t "new_outdated.new_repos" (num .NewRepos) .ScanID}}</p>{{end}}
+{{if .RemovedRepos}}<p class="summary">{{t "new_outdated.removed_repos" (num .RemovedRepos)}}</p>{{end}}
+{{if .SLABreaches}}<p class="summary">{{t "new_outdated.sla_breaches" (num .SLABreaches)}}</p>{{end}}
+{{if .NewOutdated}}
 <table>
 <tr>
-<th>Repository</th>
-<th>Dependency</th>
-<th>Current</th>
-<th>Latest</th>
-<th>Ecosystem</th>
+<th>{{t "column.repository"}}</th>
+<th>{{t "column.owners"}}</th>
+<th>{{t "column.dependency"}}</th>
+<th>{{t "column.current"}}</th>
+<th>{{t "column.latest"}}</th>
+<th>{{t "column.ecosystem"}}</th>
 </tr>
 {{range .NewOutdated}}
 <tr>
 <td class="repo">{{.RepoFullName}}</td>
+<td class="owners">{{.RepoOwners}}</td>
 <td class="dep">{{.Name}}</td>
 <td><span class="version current">{{.CurrentVersion}}</span></td>
 <td><span class="version latest">{{.LatestVersion}}</span></td>
@@ -86,14 +322,15 @@ td { padding: 12px; border-bottom: 1px solid #dee2e6; }
 </tr>
 {{end}}
 </table>
+{{end}}
 <div class="footer">
-This report was generated by Stale - Dependency Version Dashboard
+{{t "new_outdated.footer"}}
 </div>
 </div>
 </body>
 </html>`
 
-	t, err := template.New("email").Parse(tmpl)
+	t, err := template.New("email").Funcs(templateFuncs(locale)).Parse(tmpl)
 	if err != nil {
 		return "", err
 	}
@@ -106,52 +343,384 @@ This report was generated by Stale - Dependency Version Dashboard
 	return buf.String(), nil
 }
 
-func (s *Service) sendMail(settings *domain.Settings, subject, body string) error {
-	recipients := strings.Split(settings.EmailTo, ",")
-	for i, r := range recipients {
-		recipients[i] = strings.TrimSpace(r)
+// SendTokenWarnings notifies about source tokens that are invalid or about to expire
+func (s *Service) SendTokenWarnings(settings *domain.Settings, warnings []domain.SourceTokenWarning) error {
+	if !settings.EmailEnabled || len(warnings) == 0 {
+		return nil
 	}
 
-	msg := fmt.Sprintf("From: %s\r\n"+
-		"To: %s\r\n"+
-		"Subject: %s\r\n"+
-		"MIME-Version: 1.0\r\n"+
-		"Content-Type: text/html; charset=UTF-8\r\n"+
-		"\r\n"+
-		"%s",
-		settings.EmailFrom,
-		settings.EmailTo,
-		subject,
-		body,
-	)
+	return s.sendLocalized(settings, Thread{}, func(locale string) (string, string, []attachment, error) {
+		body, err := buildTokenWarningBody(locale, warnings)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("failed to build email body: %w", err)
+		}
+
+		subject := i18n.T(locale, "token.subject", i18n.Number(locale, len(warnings)))
+		if len(warnings) == 1 {
+			subject = i18n.T(locale, "token.subject_one", warnings[0].SourceName)
+		}
+		return subject, body, nil, nil
+	})
+}
+
+func buildTokenWarningBody(locale string, warnings []domain.SourceTokenWarning) (string, error) {
+	tmpl := `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; padding: 20px;">
+<h2>{{t "token.title"}}</h2>
+<p>{{t "token.intro"}}</p>
+<table style="border-collapse: collapse;">
+<tr><th style="text-align: left; padding: 8px;">{{t "column.source"}}</th><th style="text-align: left; padding: 8px;">{{t "column.status"}}</th><th style="text-align: left; padding: 8px;">{{t "column.details"}}</th></tr>
+{{range .}}
+<tr>
+<td style="padding: 8px;">{{.SourceName}}</td>
+<td style="padding: 8px;">{{t (printf "token.status.%s" .Status)}}</td>
+<td style="padding: 8px;">{{if .ExpiresAt}}{{t "token.expires" (date .ExpiresAt)}}{{if .DaysLeft}} {{t "token.days_left" (num (deref .DaysLeft))}}{{end}}{{end}}{{if .Error}} {{.Error}}{{end}}</td>
+</tr>
+{{end}}
+</table>
+</body>
+</html>`
+
+	t, err := template.New("token-warnings").Funcs(templateFuncs(locale)).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, warnings); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// SendScanFailures notifies about sources that failed to scan
+func (s *Service) SendScanFailures(settings *domain.Settings, failures []domain.SourceScanFailure) error {
+	if !settings.EmailEnabled || !settings.FailureNotifyEmail || len(failures) == 0 {
+		return nil
+	}
+
+	return s.sendLocalized(settings, Thread{}, func(locale string) (string, string, []attachment, error) {
+		body, err := buildScanFailureBody(locale, failures, settings.FailureNotifyHours)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("failed to build email body: %w", err)
+		}
+		return scanFailureSubject(locale, failures), body, nil, nil
+	})
+}
+
+func scanFailureSubject(locale string, failures []domain.SourceScanFailure) string {
+	switch {
+	case len(failures) > 1:
+		return i18n.T(locale, "failure.subject", i18n.Number(locale, len(failures)))
+	case failures[0].Disabled:
+		return i18n.T(locale, "failure.subject_disabled", failures[0].SourceName)
+	case failures[0].SourceName != "":
+		return i18n.T(locale, "failure.subject_one", failures[0].SourceName)
+	default:
+		return i18n.T(locale, "failure.subject_scan", failures[0].ScanID)
+	}
+}
+
+func buildScanFailureBody(locale string, failures []domain.SourceScanFailure, notifyHours int) (string, error) {
+	tmpl := `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; padding: 20px;">
+<h2>{{t "failure.title"}}</h2>
+<p>{{t "failure.intro"}}</p>
+<table style="border-collapse: collapse;">
+<tr><th style="text-align: left; padding: 8px;">{{t "column.source"}}</th><th style="text-align: left; padding: 8px;">{{t "column.details"}}</th></tr>
+{{range .Failures}}
+<tr>
+<td style="padding: 8px;">{{if .SourceName}}{{.SourceName}}{{else}}{{t "failure.scan" .ScanID}}{{end}}</td>
+<td style="padding: 8px;"><span style="font-family: monospace;">{{.Error}}</span>{{if .Disabled}}<br><strong>{{t "failure.disabled" (num .ConsecutiveFailures)}}</strong>{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{if .NotifyHours}}<p style="color: #666; font-size: 12px;">{{t "failure.throttle" (num .NotifyHours)}}</p>{{end}}
+</body>
+</html>`
+
+	t, err := template.New("scan-failures").Funcs(templateFuncs(locale)).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	data := struct {
+		Failures    []domain.SourceScanFailure
+		NotifyHours int
+	}{failures, notifyHours}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// SendIgnoreExpiryReminder lists ignore rules that expire soon and the ones that expired and were
+// removed, so the ignored dependencies are reported again
+func (s *Service) SendIgnoreExpiryReminder(settings *domain.Settings, expiring, expired []domain.IgnoredDependency) error {
+	if !settings.EmailEnabled || (len(expiring) == 0 && len(expired) == 0) {
+		return nil
+	}
+
+	return s.sendLocalized(settings, Thread{}, func(locale string) (string, string, []attachment, error) {
+		body, err := buildIgnoreExpiryBody(locale, expiring, expired)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("failed to build email body: %w", err)
+		}
+
+		numExpired, numExpiring := i18n.Number(locale, len(expired)), i18n.Number(locale, len(expiring))
+		var subject string
+		switch {
+		case len(expired) > 0 && len(expiring) > 0:
+			subject = i18n.T(locale, "ignore.subject", numExpired, numExpiring)
+		case len(expired) > 0:
+			subject = i18n.T(locale, "ignore.subject_expired", numExpired)
+		default:
+			subject = i18n.T(locale, "ignore.subject_expiring", numExpiring)
+		}
+		return subject, body, nil, nil
+	})
+}
+
+func buildIgnoreExpiryBody(locale string, expiring, expired []domain.IgnoredDependency) (string, error) {
+	tmpl := `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; padding: 20px;">
+{{if .Expired}}
+<h2>{{t "ignore.expired_title"}}</h2>
+<p>{{t "ignore.expired_intro"}}</p>
+<table style="border-collapse: collapse;">
+<tr><th style="text-align: left; padding: 8px;">{{t "column.dependency"}}</th><th style="text-align: left; padding: 8px;">{{t "column.expired"}}</th><th style="text-align: left; padding: 8px;">{{t "column.reason"}}</th></tr>
+{{range .Expired}}
+<tr>
+<td style="padding: 8px;">{{.Name}}{{if .Ecosystem}} ({{.Ecosystem}}){{end}}</td>
+<td style="padding: 8px;">{{date .ExpiresAt}}</td>
+<td style="padding: 8px;">{{.Reason}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+{{if .Expiring}}
+<h2>{{t "ignore.expiring_title"}}</h2>
+<p>{{t "ignore.expiring_intro"}}</p>
+<table style="border-collapse: collapse;">
+<tr><th style="text-align: left; padding: 8px;">{{t "column.dependency"}}</th><th style="text-align: left; padding: 8px;">{{t "column.expires"}}</th><th style="text-align: left; padding: 8px;">{{t "column.reason"}}</th></tr>
+{{range .Expiring}}
+<tr>
+<td style="padding: 8px;">{{.Name}}{{if .Ecosystem}} ({{.Ecosystem}}){{end}}</td>
+<td style="padding: 8px;">{{date .ExpiresAt}}</td>
+<td style="padding: 8px;">{{.Reason}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>`
+
+	t, err := template.New("ignore-expiry").Funcs(templateFuncs(locale)).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	data := struct {
+		Expiring []domain.IgnoredDependency
+		Expired  []domain.IgnoredDependency
+	}{expiring, expired}
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// SendComparisonReport emails how the outdated dependencies changed over the periods of the report
+func (s *Service) SendComparisonReport(settings *domain.Settings, report *domain.ComparisonReport) error {
+	if !settings.EmailEnabled {
+		return nil
+	}
+
+	return s.sendLocalized(settings, Thread{}, func(locale string) (string, string, []attachment, error) {
+		body, err := buildComparisonBody(locale, report)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("failed to build email body: %w", err)
+		}
+		return i18n.T(locale, "comparison.subject", i18n.Number(locale, report.Outdated)), body, nil, nil
+	})
+}
+
+func buildComparisonBody(locale string, report *domain.ComparisonReport) (string, error) {
+	tmpl := `{{define "groups"}}
+<table style="border-collapse: collapse;">
+<tr><th style="text-align: left; padding: 8px;">{{.Column}}</th><th style="text-align: right; padding: 8px;">{{t "column.before"}}</th><th style="text-align: right; padding: 8px;">{{t "column.after"}}</th><th style="text-align: right; padding: 8px;">{{t "column.newly_outdated"}}</th><th style="text-align: right; padding: 8px;">{{t "column.fixed"}}</th><th style="text-align: right; padding: 8px;">{{t "column.net"}}</th></tr>
+{{range .Groups}}
+<tr>
+<td style="padding: 8px;">{{.Name}}</td>
+<td style="padding: 8px; text-align: right;">{{num .Before}}</td>
+<td style="padding: 8px; text-align: right;">{{num .After}}</td>
+<td style="padding: 8px; text-align: right;">{{num .NewlyOutdated}}</td>
+<td style="padding: 8px; text-align: right;">{{num .Fixed}}</td>
+<td style="padding: 8px; text-align: right;">{{signed .Net}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; padding: 20px;">
+<h2>{{t "comparison.title"}}</h2>
+<p>{{t "comparison.intro" (num .Outdated)}}</p>
+{{range .Periods}}
+<h3>{{t "comparison.period" (num .Days)}}</h3>
+{{if .SnapshotDay}}
+<p>{{t "comparison.since" (day .SnapshotDay) (num .NewlyOutdated) (num .Fixed) (signed .Net)}}</p>
+{{if .BySource}}<h4>{{t "comparison.by_source"}}</h4>{{template "groups" (groups (t "column.source") .BySource)}}{{end}}
+{{if .ByTeam}}<h4>{{t "comparison.by_team"}}</h4>{{template "groups" (groups (t "column.team") .ByTeam)}}{{end}}
+{{else}}
+<p>{{t "comparison.no_snapshot" (num .Days)}}</p>
+{{end}}
+{{end}}
+<p style="color: #666; font-size: 12px;">{{t "new_outdated.footer"}}</p>
+</body>
+</html>`
+
+	funcs := templateFuncs(locale)
+	funcs["signed"] = func(n int) string {
+		if n > 0 {
+			return "+" + i18n.Number(locale, n)
+		}
+		return i18n.Number(locale, n)
+	}
+	funcs["day"] = func(day string) string {
+		t, err := time.Parse(time.DateOnly, day)
+		if err != nil {
+			return day
+		}
+		return i18n.Date(locale, t)
+	}
+	funcs["groups"] = func(column string, groups []domain.ComparisonGroup) any {
+		return struct {
+			Column string
+			Groups []domain.ComparisonGroup
+		}{column, groups}
+	}
+
+	t, err := template.New("comparison").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, report); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// attachment is a file attached to an email
+type attachment struct {
+	filename    string
+	contentType string
+	content     []byte
+}
+
+func (s *Service) sendMail(settings *domain.Settings, recipients []string, subject, body string, thread Thread, attachments ...attachment) error {
+	msg, err := buildMessage(settings, recipients, subject, body, thread, attachments)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
 
 	log.Info().
 		Int("port", settings.EmailSMTPPort).
 		Str("host", settings.EmailSMTPHost).
 		Msg("sending email")
 
-	var err error
+	to := strings.Join(recipients, ", ")
 	if settings.EmailSMTPPort == 465 {
 		log.Info().Msg("using SSL mode (port 465)")
-		err = s.sendMailSSL(settings, recipients, []byte(msg))
+		err = s.sendMailSSL(settings, recipients, msg)
 	} else {
 		log.Info().Msg("using STARTTLS mode")
-		err = s.sendMailSTARTTLS(settings, recipients, []byte(msg))
+		err = s.sendMailSTARTTLS(settings, recipients, msg)
 	}
 
 	if err != nil {
-		log.Error().Err(err).Str("to", settings.EmailTo).Msg("failed to send email")
+		log.Error().Err(err).Str("to", to).Msg("failed to send email")
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 
 	log.Info().
-		Str("to", settings.EmailTo).
+		Str("to", to).
 		Int("recipients", len(recipients)).
 		Msg("email notification sent")
 
 	return nil
 }
 
+// buildMessage formats an HTML email. With attachments the message is multipart/mixed, with the
+// HTML body as the first part and each attachment base64-encoded. A Message-ID is generated
+// unless the thread sets one; replies reference the first email of the thread.
+func buildMessage(settings *domain.Settings, recipients []string, subject, body string, thread Thread, attachments []attachment) ([]byte, error) {
+	messageID := thread.MessageID
+	if messageID == "" {
+		messageID = NewMessageID(settings.EmailFrom)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n"+
+		"To: %s\r\n"+
+		"Subject: %s\r\n"+
+		"Message-ID: %s\r\n",
+		settings.EmailFrom,
+		strings.Join(recipients, ", "),
+		mime.QEncoding.Encode("utf-8", subject),
+		messageID,
+	)
+	if thread.InReplyTo != "" {
+		fmt.Fprintf(&buf, "In-Reply-To: %s\r\nReferences: %s\r\n", thread.InReplyTo, thread.InReplyTo)
+	}
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if len(attachments) == 0 {
+		buf.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+		buf.WriteString(body)
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html; charset=UTF-8"}})
+	if err != nil {
+		return nil, err
+	}
+	part.Write([]byte(body))
+
+	for _, a := range attachments {
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.contentType + "; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		// Base64 lines are limited to 76 characters (RFC 2045)
+		encoded := base64.StdEncoding.EncodeToString(a.content)
+		for len(encoded) > 76 {
+			part.Write([]byte(encoded[:76] + "\r\n"))
+			encoded = encoded[76:]
+		}
+		part.Write([]byte(encoded + "\r\n"))
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // sendMailSTARTTLS sends email using STARTTLS (port 587)
 func (s *Service) sendMailSTARTTLS(settings *domain.Settings, recipients []string, msg []byte) error {
 	// Use net.JoinHostPort for proper IPv6 support (e.g., [::1]:587)
@@ -306,6 +875,50 @@ func (s *Service) sendMailSSL(settings *domain.Settings, recipients []string, ms
 	return client.Quit()
 }
 
+// CheckConnection connects and authenticates to the SMTP server of the settings without sending
+// an email, giving up after timeout
+func (s *Service) CheckConnection(settings *domain.Settings, timeout time.Duration) error {
+	addr := net.JoinHostPort(settings.EmailSMTPHost, strconv.Itoa(settings.EmailSMTPPort))
+	dialer := &net.Dialer{Timeout: timeout}
+	tlsConfig := &tls.Config{ServerName: settings.EmailSMTPHost}
+
+	// Port 465 uses implicit TLS, other ports STARTTLS
+	var conn net.Conn
+	var err error
+	if settings.EmailSMTPPort == 465 {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	client, err := smtp.NewClient(conn, settings.EmailSMTPHost)
+	if err != nil {
+		return fmt.Errorf("SMTP client creation failed: %w", err)
+	}
+	defer client.Close()
+
+	if settings.EmailSMTPPort != 465 {
+		if err := client.Hello("localhost"); err != nil {
+			return fmt.Errorf("EHLO failed: %w", err)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	if settings.EmailSMTPUser != "" && settings.EmailSMTPPass != "" {
+		auth := smtp.PlainAuth("", settings.EmailSMTPUser, settings.EmailSMTPPass, settings.EmailSMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+	return client.Quit()
+}
+
 func (s *Service) TestConnection(settings *domain.Settings) error {
 	// Send a test email
 	subject := "[Stale] Test Email"
@@ -1,8 +1,16 @@
 package email
 
 import (
+	"encoding/base64"
+	"encoding/csv"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jiin/stale/internal/domain"
 )
@@ -26,7 +34,7 @@ func TestSendNewOutdatedReport_EmailDisabled(t *testing.T) {
 		},
 	}
 
-	err := service.SendNewOutdatedReport(settings, report)
+	err := service.SendNewOutdatedReport(settings, report, Thread{})
 	if err != nil {
 		t.Errorf("expected no error when email disabled, got %v", err)
 	}
@@ -41,7 +49,7 @@ func TestSendNewOutdatedReport_EmptyReport(t *testing.T) {
 		NewOutdated: []domain.DependencyWithRepo{},
 	}
 
-	err := service.SendNewOutdatedReport(settings, report)
+	err := service.SendNewOutdatedReport(settings, report, Thread{})
 	if err != nil {
 		t.Errorf("expected no error for empty report, got %v", err)
 	}
@@ -55,6 +63,7 @@ func TestBuildEmailBody(t *testing.T) {
 			{
 				Dependency:   domain.Dependency{Name: "react", CurrentVersion: "17.0.0", LatestVersion: "18.2.0", Ecosystem: "npm"},
 				RepoFullName: "owner/frontend",
+				RepoOwners:   "@owner/web-team",
 			},
 			{
 				Dependency:   domain.Dependency{Name: "spring-boot", CurrentVersion: "2.7.0", LatestVersion: "3.1.0", Ecosystem: "maven"},
@@ -63,7 +72,7 @@ func TestBuildEmailBody(t *testing.T) {
 		},
 	}
 
-	body, err := service.buildEmailBody(report)
+	body, err := service.buildEmailBody("en", report)
 	if err != nil {
 		t.Fatalf("buildEmailBody failed: %v", err)
 	}
@@ -75,6 +84,7 @@ func TestBuildEmailBody(t *testing.T) {
 		"scan #123",
 		"react",
 		"owner/frontend",
+		"@owner/web-team",
 		"17.0.0",
 		"18.2.0",
 		"npm",
@@ -104,7 +114,7 @@ func TestBuildEmailBody_SingleDependency(t *testing.T) {
 		},
 	}
 
-	body, err := service.buildEmailBody(report)
+	body, err := service.buildEmailBody("en", report)
 	if err != nil {
 		t.Fatalf("buildEmailBody failed: %v", err)
 	}
@@ -126,7 +136,7 @@ func TestBuildEmailBody_HTMLStructure(t *testing.T) {
 		},
 	}
 
-	body, err := service.buildEmailBody(report)
+	body, err := service.buildEmailBody("en", report)
 	if err != nil {
 		t.Fatalf("buildEmailBody failed: %v", err)
 	}
@@ -166,7 +176,7 @@ func TestBuildEmailBody_EcosystemStyles(t *testing.T) {
 			},
 		}
 
-		body, err := service.buildEmailBody(report)
+		body, err := service.buildEmailBody("en", report)
 		if err != nil {
 			t.Fatalf("buildEmailBody failed for ecosystem %s: %v", ecosystem, err)
 		}
@@ -208,3 +218,414 @@ func TestRecipientParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildEmailBody_RepositoryDiscovery(t *testing.T) {
+	service := New()
+	report := &domain.NewOutdatedReport{
+		ScanID:       7,
+		NewRepos:     3,
+		RemovedRepos: 1,
+		SLABreaches:  4,
+	}
+
+	body, err := service.buildEmailBody("en", report)
+	if err != nil {
+		t.Fatalf("buildEmailBody failed: %v", err)
+	}
+
+	for _, s := range []string{"Repository Changes Detected", "3 new repositories discovered", "1 repositories are no longer found", "4 outdated dependencies have exceeded their upgrade SLA"} {
+		if !strings.Contains(body, s) {
+			t.Errorf("expected body to contain %q", s)
+		}
+	}
+	if strings.Contains(body, "<table>") {
+		t.Error("expected no dependency table without new outdated dependencies")
+	}
+}
+
+func TestBuildSubject(t *testing.T) {
+	outdated := []domain.DependencyWithRepo{{}, {}}
+
+	tests := []struct {
+		name     string
+		report   *domain.NewOutdatedReport
+		expected string
+	}{
+		{"outdated only", &domain.NewOutdatedReport{NewOutdated: outdated}, "[Stale] 2 new outdated dependencies found"},
+		{"outdated and new repos", &domain.NewOutdatedReport{NewOutdated: outdated, NewRepos: 4}, "[Stale] 2 new outdated dependencies found, 4 new repositories discovered"},
+		{"new repos only", &domain.NewOutdatedReport{NewRepos: 1}, "[Stale] 1 new repositories discovered"},
+		{"removed only", &domain.NewOutdatedReport{RemovedRepos: 2}, "[Stale] 2 repositories no longer found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildSubject("en", tt.report); got != tt.expected {
+				t.Errorf("buildSubject() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildTokenWarningBody(t *testing.T) {
+	expiresAt := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	days := 5
+	warnings := []domain.SourceTokenWarning{
+		{SourceName: "github-org", Status: "expiring", ExpiresAt: &expiresAt, DaysLeft: &days},
+		{SourceName: "gitlab", Status: "invalid", Error: "401 Unauthorized"},
+	}
+
+	body, err := buildTokenWarningBody("en", warnings)
+	if err != nil {
+		t.Fatalf("buildTokenWarningBody() error = %v", err)
+	}
+
+	for _, want := range []string{"github-org", "expires 2025-03-10 (5 days left)", "gitlab", "invalid", "401 Unauthorized"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body should contain %q", want)
+		}
+	}
+}
+
+func TestBuildScanFailureBody(t *testing.T) {
+	failures := []domain.SourceScanFailure{
+		{ScanID: 7, SourceID: 1, SourceName: "github-org", Error: "401 Bad credentials", ConsecutiveFailures: 5, Disabled: true},
+		{ScanID: 7, Error: "database is locked"},
+	}
+
+	body, err := buildScanFailureBody("en", failures, 6)
+	if err != nil {
+		t.Fatalf("buildScanFailureBody() error = %v", err)
+	}
+
+	for _, want := range []string{"github-org", "401 Bad credentials", "Disabled after 5 failed scans in a row", "Scan #7", "database is locked", "at most once every 6 hours"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body should contain %q", want)
+		}
+	}
+
+	if got := scanFailureSubject("en", failures[:1]); got != `[Stale] Source "github-org" was disabled after repeated scan failures` {
+		t.Errorf("scanFailureSubject() = %q", got)
+	}
+	if got := scanFailureSubject("en", failures); got != "[Stale] 2 sources failed to scan" {
+		t.Errorf("scanFailureSubject() = %q", got)
+	}
+}
+
+func TestBuildIgnoreExpiryBody(t *testing.T) {
+	expiredAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)
+	expired := []domain.IgnoredDependency{{Name: "lodash", Ecosystem: "npm", Reason: "waiting for v5", ExpiresAt: &expiredAt}}
+
+	body, err := buildIgnoreExpiryBody("en", nil, expired)
+	if err != nil {
+		t.Fatalf("buildIgnoreExpiryBody() error = %v", err)
+	}
+	for _, want := range []string{"Expired Ignore Rules", "lodash (npm)", "2025-03-01", "waiting for v5"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body should contain %q", want)
+		}
+	}
+	if strings.Contains(body, "Expiring Soon") {
+		t.Error("body should not list expiring rules when there are none")
+	}
+
+	expiring := []domain.IgnoredDependency{{Name: "junit", ExpiresAt: &expiresAt}}
+	body, err = buildIgnoreExpiryBody("en", expiring, nil)
+	if err != nil {
+		t.Fatalf("buildIgnoreExpiryBody() error = %v", err)
+	}
+	for _, want := range []string{"Ignore Rules Expiring Soon", "junit", "2025-03-05"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body should contain %q", want)
+		}
+	}
+}
+
+func TestBuildComparisonBody(t *testing.T) {
+	report := &domain.ComparisonReport{
+		Outdated: 12,
+		Periods: []domain.ComparisonPeriod{
+			{
+				Days: 30, SnapshotDay: "2025-02-01", Before: 10, After: 12, NewlyOutdated: 4, Fixed: 2, Net: 2,
+				BySource: []domain.ComparisonGroup{{Name: "github-org", Before: 10, After: 12, NewlyOutdated: 4, Fixed: 2, Net: 2}},
+				ByTeam:   []domain.ComparisonGroup{{Name: domain.UnownedTeam, Before: 10, After: 12, NewlyOutdated: 4, Fixed: 2, Net: 2}},
+			},
+			{Days: 90, After: 12},
+		},
+	}
+
+	body, err := buildComparisonBody("en", report)
+	if err != nil {
+		t.Fatalf("buildComparisonBody() error = %v", err)
+	}
+	body = html.UnescapeString(body) // html/template escapes the sign of +2
+	for _, want := range []string{"12 dependencies are outdated now", "Last 30 days", "4 newly outdated, 2 fixed, net +2", "github-org", "unowned", "No state from 90 days ago is archived yet"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body should contain %q", want)
+		}
+	}
+}
+
+func TestPreviewNewOutdatedReport(t *testing.T) {
+	service := New()
+	report := &domain.NewOutdatedReport{
+		ScanID: 7,
+		NewOutdated: []domain.DependencyWithRepo{
+			{Dependency: domain.Dependency{Name: "lodash", CurrentVersion: "4.17.0", LatestVersion: "4.17.21", Ecosystem: "npm"}, RepoFullName: "owner/repo"},
+		},
+	}
+
+	t.Run("would send", func(t *testing.T) {
+		settings := &domain.Settings{EmailEnabled: true, EmailNotifyNewOutdated: true, EmailFrom: "stale@example.com", EmailTo: "a@example.com, ,b@example.com"}
+		preview, err := service.PreviewNewOutdatedReport(settings, report, "")
+		if err != nil {
+			t.Fatalf("PreviewNewOutdatedReport() error = %v", err)
+		}
+		if !preview.WouldSend || preview.Reason != "" {
+			t.Errorf("WouldSend = %v (%s), want true", preview.WouldSend, preview.Reason)
+		}
+		if len(preview.To) != 2 || preview.To[1] != "b@example.com" {
+			t.Errorf("To = %v", preview.To)
+		}
+		if preview.Subject != "[Stale] 1 new outdated dependencies found" || !strings.Contains(preview.Body, "lodash") {
+			t.Errorf("unexpected subject %q or body", preview.Subject)
+		}
+		if len(preview.Attachments) != 0 {
+			t.Errorf("Attachments = %v, want none", preview.Attachments)
+		}
+	})
+
+	t.Run("with CSV attachment", func(t *testing.T) {
+		settings := &domain.Settings{EmailEnabled: true, EmailNotifyNewOutdated: true, EmailAttachCSV: true, EmailTo: "a@example.com"}
+		preview, err := service.PreviewNewOutdatedReport(settings, report, "")
+		if err != nil {
+			t.Fatalf("PreviewNewOutdatedReport() error = %v", err)
+		}
+		if len(preview.Attachments) != 1 || preview.Attachments[0] != "new_outdated_dependencies_scan_7.csv" {
+			t.Errorf("Attachments = %v", preview.Attachments)
+		}
+	})
+
+	t.Run("disabled still renders", func(t *testing.T) {
+		preview, err := service.PreviewNewOutdatedReport(&domain.Settings{}, report, "")
+		if err != nil {
+			t.Fatalf("PreviewNewOutdatedReport() error = %v", err)
+		}
+		if preview.WouldSend || preview.Reason == "" {
+			t.Errorf("WouldSend = %v, want false with a reason", preview.WouldSend)
+		}
+		if preview.Body == "" {
+			t.Error("body should be rendered even when notifications are disabled")
+		}
+	})
+
+	t.Run("nothing to report", func(t *testing.T) {
+		settings := &domain.Settings{EmailEnabled: true, EmailNotifyNewOutdated: true, EmailTo: "a@example.com"}
+		preview, err := service.PreviewNewOutdatedReport(settings, &domain.NewOutdatedReport{ScanID: 8}, "")
+		if err != nil {
+			t.Fatalf("PreviewNewOutdatedReport() error = %v", err)
+		}
+		if preview.WouldSend || preview.Body != "" {
+			t.Errorf("preview = %+v, want nothing to send", preview)
+		}
+	})
+}
+
+func TestBuildMessage(t *testing.T) {
+	settings := &domain.Settings{EmailFrom: "stale@example.com", EmailTo: "a@example.com"}
+
+	t.Run("html only", func(t *testing.T) {
+		msg, err := buildMessage(settings, []string{"a@example.com"}, "Subject", "<p>body</p>", Thread{}, nil)
+		if err != nil {
+			t.Fatalf("buildMessage() error = %v", err)
+		}
+		parsed, err := mail.ReadMessage(strings.NewReader(string(msg)))
+		if err != nil {
+			t.Fatalf("invalid message: %v", err)
+		}
+		if ct := parsed.Header.Get("Content-Type"); ct != "text/html; charset=UTF-8" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		body, _ := io.ReadAll(parsed.Body)
+		if string(body) != "<p>body</p>" {
+			t.Errorf("body = %q", body)
+		}
+	})
+
+	t.Run("with attachment", func(t *testing.T) {
+		report := &domain.NewOutdatedReport{
+			ScanID: 3,
+			NewOutdated: []domain.DependencyWithRepo{
+				{Dependency: domain.Dependency{Name: "lodash", CurrentVersion: "4.17.0", LatestVersion: "4.17.21", Ecosystem: "npm"}, RepoFullName: "owner/repo"},
+			},
+		}
+		attachments, err := reportAttachments(&domain.Settings{EmailAttachCSV: true}, "en", report)
+		if err != nil || len(attachments) != 1 {
+			t.Fatalf("reportAttachments() = %v, %v", attachments, err)
+		}
+
+		msg, err := buildMessage(settings, []string{"a@example.com"}, "Subject", "<p>body</p>", Thread{}, attachments)
+		if err != nil {
+			t.Fatalf("buildMessage() error = %v", err)
+		}
+		parsed, err := mail.ReadMessage(strings.NewReader(string(msg)))
+		if err != nil {
+			t.Fatalf("invalid message: %v", err)
+		}
+		mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/mixed" {
+			t.Fatalf("Content-Type = %q, %v", mediaType, err)
+		}
+
+		mr := multipart.NewReader(parsed.Body, params["boundary"])
+		htmlPart, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("missing HTML part: %v", err)
+		}
+		if body, _ := io.ReadAll(htmlPart); string(body) != "<p>body</p>" {
+			t.Errorf("HTML part = %q", body)
+		}
+
+		csvPart, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("missing CSV part: %v", err)
+		}
+		if csvPart.FileName() != "new_outdated_dependencies_scan_3.csv" {
+			t.Errorf("attachment file name = %q", csvPart.FileName())
+		}
+		// multipart.Reader decodes quoted-printable only, so the base64 body is decoded here
+		encoded, _ := io.ReadAll(csvPart)
+		decoded, err := io.ReadAll(base64Decoder(string(encoded)))
+		if err != nil {
+			t.Fatalf("invalid base64: %v", err)
+		}
+		rows, err := csv.NewReader(strings.NewReader(string(decoded))).ReadAll()
+		if err != nil || len(rows) != 2 || rows[1][1] != "owner/repo" || rows[1][4] != "lodash" || rows[1][8] != "4.17.21" {
+			t.Errorf("CSV rows = %v, %v", rows, err)
+		}
+	})
+
+	t.Run("attachment disabled", func(t *testing.T) {
+		attachments, err := reportAttachments(&domain.Settings{}, "en", &domain.NewOutdatedReport{NewOutdated: []domain.DependencyWithRepo{{}}})
+		if err != nil || attachments != nil {
+			t.Errorf("reportAttachments() = %v, %v, want none", attachments, err)
+		}
+	})
+}
+
+func base64Decoder(s string) io.Reader {
+	return base64.NewDecoder(base64.StdEncoding, strings.NewReader(strings.NewReplacer("\r", "", "\n", "").Replace(s)))
+}
+
+func TestBuildMessage_Thread(t *testing.T) {
+	settings := &domain.Settings{EmailFrom: "Stale <stale@example.com>", EmailTo: "a@example.com"}
+
+	msg, err := buildMessage(settings, []string{"a@example.com"}, "Subject", "body", Thread{}, nil)
+	if err != nil {
+		t.Fatalf("buildMessage() error = %v", err)
+	}
+	parsed, _ := mail.ReadMessage(strings.NewReader(string(msg)))
+	if id := parsed.Header.Get("Message-ID"); !strings.HasPrefix(id, "<stale.") || !strings.HasSuffix(id, "@example.com>") {
+		t.Errorf("Message-ID = %q, want a generated ID in the sender domain", id)
+	}
+	if parsed.Header.Get("In-Reply-To") != "" {
+		t.Error("first email of a thread should not reply to anything")
+	}
+
+	thread := Thread{MessageID: "<second@example.com>", InReplyTo: "<first@example.com>"}
+	msg, _ = buildMessage(settings, []string{"a@example.com"}, "Subject", "body", thread, nil)
+	parsed, _ = mail.ReadMessage(strings.NewReader(string(msg)))
+	if parsed.Header.Get("Message-ID") != "<second@example.com>" || parsed.Header.Get("In-Reply-To") != "<first@example.com>" || parsed.Header.Get("References") != "<first@example.com>" {
+		t.Errorf("thread headers = %v", parsed.Header)
+	}
+}
+
+func TestNewMessageID(t *testing.T) {
+	if a, b := NewMessageID("stale@example.com"), NewMessageID("stale@example.com"); a == b {
+		t.Errorf("NewMessageID() returned %q twice", a)
+	}
+	if id := NewMessageID(""); !strings.HasSuffix(id, "@stale.localhost>") {
+		t.Errorf("NewMessageID() without sender = %q", id)
+	}
+}
+
+func TestParseRecipientLocales(t *testing.T) {
+	locales, err := ParseRecipientLocales("Kim@Example.com=ko-KR, lee@example.com = en")
+	if err != nil {
+		t.Fatalf("ParseRecipientLocales() error = %v", err)
+	}
+	if locales["kim@example.com"] != "ko" || locales["lee@example.com"] != "en" {
+		t.Errorf("ParseRecipientLocales() = %v", locales)
+	}
+
+	for _, invalid := range []string{"kim@example.com", "=ko", "kim@example.com=xx"} {
+		if _, err := ParseRecipientLocales(invalid); err == nil {
+			t.Errorf("ParseRecipientLocales(%q) expected error", invalid)
+		}
+	}
+}
+
+func TestRecipientGroups(t *testing.T) {
+	settings := &domain.Settings{
+		EmailTo:               "a@example.com, kim@example.com, b@example.com",
+		EmailRecipientLocales: "KIM@example.com=ko",
+		Locale:                "en",
+	}
+
+	groups := recipientGroups(settings)
+	if len(groups) != 2 {
+		t.Fatalf("recipientGroups() = %v, want 2 groups", groups)
+	}
+	if groups[0].locale != "en" || strings.Join(groups[0].to, ",") != "a@example.com,b@example.com" {
+		t.Errorf("first group = %+v", groups[0])
+	}
+	if groups[1].locale != "ko" || strings.Join(groups[1].to, ",") != "kim@example.com" {
+		t.Errorf("second group = %+v", groups[1])
+	}
+
+	settings.EmailRecipientLocales = ""
+	settings.Locale = ""
+	if groups := recipientGroups(settings); len(groups) != 1 || groups[0].locale != "en" {
+		t.Errorf("recipientGroups() without locales = %+v", groups)
+	}
+}
+
+func TestBuildEmailBody_Localized(t *testing.T) {
+	report := &domain.NewOutdatedReport{
+		ScanID:      12,
+		NewOutdated: make([]domain.DependencyWithRepo, 1200),
+	}
+
+	body, err := New().buildEmailBody("ko", report)
+	if err != nil {
+		t.Fatalf("buildEmailBody failed: %v", err)
+	}
+	for _, s := range []string{"새로 오래된 의존성이 발견되었습니다", "스캔 #12에서 새로 오래된 의존성 1,200개", "<th>저장소</th>"} {
+		if !strings.Contains(body, s) {
+			t.Errorf("expected body to contain %q", s)
+		}
+	}
+
+	if got := buildSubject("ko", report); got != "[Stale] 새로 오래된 의존성 1,200개 발견" {
+		t.Errorf("buildSubject() = %q", got)
+	}
+}
+
+func TestBuildMessage_EncodesSubject(t *testing.T) {
+	settings := &domain.Settings{EmailFrom: "stale@example.com"}
+	msg, err := buildMessage(settings, []string{"kim@example.com"}, "[Stale] 새 저장소 3개 발견", "body", Thread{}, nil)
+	if err != nil {
+		t.Fatalf("buildMessage() error = %v", err)
+	}
+	parsed, err := mail.ReadMessage(strings.NewReader(string(msg)))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	if raw := parsed.Header.Get("Subject"); !strings.HasPrefix(raw, "=?utf-8?q?") {
+		t.Errorf("Subject = %q, want encoded word", raw)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "[Stale] 새 저장소 3개 발견" {
+		t.Errorf("decoded Subject = %q, %v", subject, err)
+	}
+}
@@ -0,0 +1,173 @@
+// Package feature decides which optional subsystems are enabled on an instance, so risky new
+// ones can be rolled out gradually. A flag is set by the STALE_FEATURE_<NAME> environment
+// variable, then by the database, and otherwise has its default.
+package feature
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// Known flags
+const (
+	VulnerabilityAlerts = "vulnerability_alerts" // import Dependabot alerts of sources that enable it
+	IgnoreSuggestions   = "ignore_suggestions"   // suggest ignore rules from Renovate and Dependabot configs
+	AdditionalBranches  = "additional_branches"  // scan the additional branches configured on sources
+	PackageMaintainers  = "package_maintainers"  // fetch the maintainers of packages in use from their registries
+	SupplyChainRisks    = "supply_chain_risks"   // flag npm dependencies with install scripts or typosquatting names
+)
+
+// Definition describes a known flag
+type Definition struct {
+	Name        string
+	Description string
+	Default     bool
+}
+
+var definitions = []Definition{
+	{VulnerabilityAlerts, "Import Dependabot vulnerability alerts during scans and serve them through the alerts API", true},
+	{IgnoreSuggestions, "Suggest ignore rules from the Renovate and Dependabot configs of scanned repositories", true},
+	{AdditionalBranches, "Scan the additional branches configured on sources", true},
+	{PackageMaintainers, "Fetch the maintainers of the packages in use from their registries", true},
+	{SupplyChainRisks, "Flag npm dependencies that run install scripts or whose name imitates a popular package", true},
+}
+
+// ErrUnknownFlag is returned for flags that are not defined
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// Flags reads and sets the feature flags of the instance. Flags set in the database are cached.
+// A nil *Flags reports every flag with its default.
+type Flags struct {
+	repo *repository.FeatureFlagRepository
+
+	mu     sync.RWMutex
+	stored map[string]bool // nil until loaded
+}
+
+func New(repo *repository.FeatureFlagRepository) *Flags {
+	return &Flags{repo: repo}
+}
+
+// Enabled reports whether a flag is enabled. Unknown flags are disabled.
+func (f *Flags) Enabled(ctx context.Context, name string) bool {
+	def, ok := lookup(name)
+	if !ok {
+		return false
+	}
+	return f.state(ctx, def).Enabled
+}
+
+// List returns the state of every known flag
+func (f *Flags) List(ctx context.Context) []domain.FeatureFlag {
+	flags := make([]domain.FeatureFlag, 0, len(definitions))
+	for _, def := range definitions {
+		flags = append(flags, f.state(ctx, def))
+	}
+	return flags
+}
+
+// Set enables or disables a flag in the database. An environment variable still takes precedence.
+func (f *Flags) Set(ctx context.Context, name string, enabled bool) (domain.FeatureFlag, error) {
+	def, ok := lookup(name)
+	if !ok {
+		return domain.FeatureFlag{}, ErrUnknownFlag
+	}
+	if err := f.repo.Set(ctx, name, enabled); err != nil {
+		return domain.FeatureFlag{}, err
+	}
+	f.invalidate()
+	return f.state(ctx, def), nil
+}
+
+// Reset removes a flag from the database so its default applies again
+func (f *Flags) Reset(ctx context.Context, name string) (domain.FeatureFlag, error) {
+	def, ok := lookup(name)
+	if !ok {
+		return domain.FeatureFlag{}, ErrUnknownFlag
+	}
+	if err := f.repo.Delete(ctx, name); err != nil {
+		return domain.FeatureFlag{}, err
+	}
+	f.invalidate()
+	return f.state(ctx, def), nil
+}
+
+// state resolves a flag from the environment, the database and its default
+func (f *Flags) state(ctx context.Context, def Definition) domain.FeatureFlag {
+	flag := domain.FeatureFlag{
+		Name:        def.Name,
+		Description: def.Description,
+		Enabled:     def.Default,
+		Default:     def.Default,
+		Source:      domain.FeatureSourceDefault,
+	}
+
+	if enabled, ok := fromEnv(def.Name); ok {
+		flag.Enabled, flag.Source = enabled, domain.FeatureSourceEnvironment
+	} else if enabled, ok := f.load(ctx)[def.Name]; ok {
+		flag.Enabled, flag.Source = enabled, domain.FeatureSourceDatabase
+	}
+	return flag
+}
+
+// load returns the flags set in the database, reading them on first use. Flags are left at
+// their defaults when the database cannot be read.
+func (f *Flags) load(ctx context.Context) map[string]bool {
+	if f == nil {
+		return nil
+	}
+
+	f.mu.RLock()
+	stored := f.stored
+	f.mu.RUnlock()
+	if stored != nil {
+		return stored
+	}
+
+	stored, err := f.repo.GetAll(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load feature flags, using defaults")
+		return nil
+	}
+
+	f.mu.Lock()
+	f.stored = stored
+	f.mu.Unlock()
+	return stored
+}
+
+func (f *Flags) invalidate() {
+	f.mu.Lock()
+	f.stored = nil
+	f.mu.Unlock()
+}
+
+// fromEnv returns the state of a flag set by its STALE_FEATURE_<NAME> environment variable
+func fromEnv(name string) (bool, bool) {
+	value := os.Getenv("STALE_FEATURE_" + strings.ToUpper(name))
+	if value == "" {
+		return false, false
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, false
+	}
+	return enabled, true
+}
+
+func lookup(name string) (Definition, bool) {
+	for _, def := range definitions {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
@@ -0,0 +1,92 @@
+package feature
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestFlags(t *testing.T) *Flags {
+	db, err := sqlx.Connect("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS feature_flags (
+			name TEXT PRIMARY KEY,
+			enabled BOOLEAN NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+	`)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	return New(repository.NewFeatureFlagRepository(db))
+}
+
+func TestFlags_Precedence(t *testing.T) {
+	ctx := context.Background()
+	flags := setupTestFlags(t)
+
+	if !flags.Enabled(ctx, IgnoreSuggestions) {
+		t.Error("Enabled() = false, want the default true")
+	}
+
+	flag, err := flags.Set(ctx, IgnoreSuggestions, false)
+	if err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if flag.Enabled || flag.Source != domain.FeatureSourceDatabase {
+		t.Errorf("Set() = %+v, want disabled from the database", flag)
+	}
+	if flags.Enabled(ctx, IgnoreSuggestions) {
+		t.Error("Enabled() = true after disabling the flag")
+	}
+
+	// The environment takes precedence over the database
+	t.Setenv("STALE_FEATURE_IGNORE_SUGGESTIONS", "true")
+	for _, f := range flags.List(ctx) {
+		if f.Name == IgnoreSuggestions && (!f.Enabled || f.Source != domain.FeatureSourceEnvironment) {
+			t.Errorf("List() %s = %+v, want enabled from the environment", f.Name, f)
+		}
+	}
+	t.Setenv("STALE_FEATURE_IGNORE_SUGGESTIONS", "")
+
+	flag, err = flags.Reset(ctx, IgnoreSuggestions)
+	if err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if !flag.Enabled || flag.Source != domain.FeatureSourceDefault {
+		t.Errorf("Reset() = %+v, want the default", flag)
+	}
+}
+
+func TestFlags_Unknown(t *testing.T) {
+	ctx := context.Background()
+	flags := setupTestFlags(t)
+
+	if flags.Enabled(ctx, "does_not_exist") {
+		t.Error("Enabled() = true for an unknown flag")
+	}
+	if _, err := flags.Set(ctx, "does_not_exist", true); !errors.Is(err, ErrUnknownFlag) {
+		t.Errorf("Set() error = %v, want ErrUnknownFlag", err)
+	}
+	if _, err := flags.Reset(ctx, "does_not_exist"); !errors.Is(err, ErrUnknownFlag) {
+		t.Errorf("Reset() error = %v, want ErrUnknownFlag", err)
+	}
+}
+
+func TestFlags_Nil(t *testing.T) {
+	var flags *Flags
+	if !flags.Enabled(context.Background(), VulnerabilityAlerts) {
+		t.Error("Enabled() on nil Flags = false, want the default true")
+	}
+}
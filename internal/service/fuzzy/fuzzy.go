@@ -0,0 +1,130 @@
+// Package fuzzy matches search terms against package names, tolerating typos and partial names.
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Match reports whether every word of query matches a part of name and how far off the best
+// matches are (0 when all words are found as is). Words match parts of names split at
+// separators (e.g. org.springframework.boot:spring-boot), or their beginnings, with up to one
+// typo in words of 4 to 7 characters and two in longer ones, so "sprng boot" matches
+// org.springframework.boot:spring-boot-starter-web. Matching ignores case.
+func Match(query, name string) (distance int, ok bool) {
+	words := split(query)
+	if len(words) == 0 {
+		return 0, false
+	}
+
+	name = strings.ToLower(name)
+	parts := split(name)
+	for _, word := range words {
+		if strings.Contains(name, word) {
+			continue
+		}
+		best := -1
+		for _, part := range parts {
+			if d := partDistance(word, part); best < 0 || d < best {
+				best = d
+			}
+		}
+		if best < 0 || best > allowedTypos(word) {
+			return 0, false
+		}
+		distance += best
+	}
+	return distance, true
+}
+
+// Rank returns the names matching query, closest matches first, at most limit of them (all when
+// limit is 0)
+func Rank(query string, names []string, limit int) []string {
+	type match struct {
+		name     string
+		distance int
+	}
+	var matches []match
+	for _, name := range names {
+		if d, ok := Match(query, name); ok {
+			matches = append(matches, match{name, d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		if len(matches[i].name) != len(matches[j].name) {
+			return len(matches[i].name) < len(matches[j].name)
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	ranked := make([]string, len(matches))
+	for i, m := range matches {
+		ranked[i] = m.name
+	}
+	return ranked
+}
+
+// split lowercases s and splits it into words at anything but letters and digits
+func split(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// allowedTypos is the edit distance tolerated for a search word
+func allowedTypos(word string) int {
+	switch n := len([]rune(word)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// partDistance is the edit distance between word and part or, when shorter, the beginning of part
+// closest to it
+func partDistance(word, part string) int {
+	w, p := []rune(word), []rune(part)
+	best := editDistance(w, p)
+	for n := len(w) - 1; n <= len(w)+1; n++ {
+		if n > 0 && n < len(p) {
+			best = min(best, editDistance(w, p[:n]))
+		}
+	}
+	return best
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of adjacent characters
+// turning a into b
+func editDistance(a, b []rune) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,47 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		name  string
+		ok    bool
+	}{
+		{"spring", "org.springframework.boot:spring-boot", true},
+		{"sprng boot", "org.springframework.boot:spring-boot-starter-web", true},
+		{"springframwork", "org.springframework.boot:spring-boot", true},
+		{"org.springframework", "org.springframework.boot:spring-boot", true},
+		{"sprngfr", "org.springframework:spring-core", true},
+		{"React", "react-dom", true},
+		{"raect", "react", true},
+		{"vue", "vuex", true},
+		{"vua", "vue", false}, // short words must match as is
+		{"lodahs", "react", false},
+		{"sprng boot", "org.springframework:spring-core", false},
+		{"  ", "react", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query+"/"+tt.name, func(t *testing.T) {
+			if _, ok := Match(tt.query, tt.name); ok != tt.ok {
+				t.Errorf("Match(%q, %q) = %v, want %v", tt.query, tt.name, ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	names := []string{"react-router", "preact", "react", "redux", "react-dom"}
+
+	if got, want := Rank("react", names, 0), []string{"react", "preact", "react-dom", "react-router"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank() = %v, want %v", got, want)
+	}
+	// Exact matches rank before typo matches
+	if got, want := Rank("raect", append(names, "raect-lite"), 2), []string{"raect-lite", "react"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank() = %v, want %v", got, want)
+	}
+}
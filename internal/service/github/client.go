@@ -3,6 +3,9 @@ package github
 import (
 	"context"
 	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -19,8 +22,12 @@ type Client struct {
 }
 
 func New(token, org string, ownerOnly bool) *Client {
-	// Use custom transport with connection pooling and retry logic
-	transport := httputil.DefaultTransportWithRetry()
+	// Use custom transport with connection pooling and retry logic, counting API usage per scan
+	// and identifying and tracing each attempt
+	transport := &httputil.RetryTransport{
+		Base:   &httputil.UsageTransport{Base: httputil.OutboundTransport(httputil.ProviderTransport())},
+		Config: httputil.DefaultRetryConfig(),
+	}
 
 	// Wrap with OAuth2 transport
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
@@ -137,6 +144,22 @@ func toRepository(repo *github.Repository) Repository {
 	}
 }
 
+// GetRepository returns a single repository by its full name (owner/repo)
+func (c *Client) GetRepository(ctx context.Context, fullName string) (*Repository, error) {
+	parts := strings.SplitN(fullName, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid repository name %q", fullName)
+	}
+
+	repo, _, err := c.client.Repositories.Get(ctx, parts[0], parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	result := toRepository(repo)
+	return &result, nil
+}
+
 func (c *Client) GetFileContent(ctx context.Context, fullName, path, branch string) ([]byte, error) {
 	parts := strings.SplitN(fullName, "/", 2)
 	owner := parts[0]
@@ -173,6 +196,39 @@ func (c *Client) ValidateToken(ctx context.Context) error {
 	return err
 }
 
+// TokenExpiration validates the token and returns its expiration date, read from the
+// GitHub-Authentication-Token-Expiration header. Tokens without expiration return nil.
+func (c *Client) TokenExpiration(ctx context.Context) (*time.Time, error) {
+	_, resp, err := c.client.Users.Get(ctx, "")
+	if err != nil {
+		return nil, rejected(err)
+	}
+	return parseTokenExpiration(resp.Header.Get("GitHub-Authentication-Token-Expiration")), nil
+}
+
+// rejected wraps errors of responses refusing the token with httputil.ErrCredentialsRejected
+func rejected(err error) error {
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil &&
+		(errResp.Response.StatusCode == http.StatusUnauthorized || errResp.Response.StatusCode == http.StatusForbidden) {
+		return fmt.Errorf("%w: %w", httputil.ErrCredentialsRejected, err)
+	}
+	return err
+}
+
+// parseTokenExpiration parses the token expiration header, e.g. "2025-01-31 09:30:00 UTC"
+func parseTokenExpiration(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
 // ListManifestFiles returns all manifest file paths in the repository
 func (c *Client) ListManifestFiles(ctx context.Context, fullName, branch string) ([]string, error) {
 	parts := strings.SplitN(fullName, "/", 2)
@@ -191,6 +247,10 @@ func (c *Client) ListManifestFiles(ctx context.Context, fullName, branch string)
 		"build.gradle":     true,
 		"build.gradle.kts": true,
 		"go.mod":           true,
+		"Package.swift":    true,
+		"Package.resolved": true,
+		"Podfile":          true,
+		"Podfile.lock":     true,
 	}
 
 	var manifests []string
@@ -209,3 +269,149 @@ func (c *Client) ListManifestFiles(ctx context.Context, fullName, branch string)
 
 	return manifests, nil
 }
+
+// DownloadArchive returns the gzipped tarball of the repository at ref. The caller must close it.
+func (c *Client) DownloadArchive(ctx context.Context, fullName, ref string) (io.ReadCloser, error) {
+	parts := strings.SplitN(fullName, "/", 2)
+	owner := parts[0]
+	repo := parts[1]
+
+	// The API redirects to a short-lived download URL, which the HTTP client follows
+	req, err := c.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/tarball/%s", owner, repo, ref), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.BareDo(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// ListBranches returns the names of all branches in the repository
+func (c *Client) ListBranches(ctx context.Context, fullName string) ([]string, error) {
+	parts := strings.SplitN(fullName, "/", 2)
+	owner := parts[0]
+	repo := parts[1]
+
+	var branches []string
+	opt := &github.BranchListOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		page, resp, err := c.client.Repositories.ListBranches(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, branch := range page {
+			branches = append(branches, branch.GetName())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return branches, nil
+}
+
+// DependabotAlert is an open Dependabot alert of a repository
+type DependabotAlert struct {
+	RepoFullName    string
+	Number          int
+	Ecosystem       string
+	PackageName     string
+	ManifestPath    string
+	Severity        string
+	GHSAID          string
+	CVEID           string
+	Summary         string
+	VulnerableRange string
+	PatchedVersion  string
+	HTMLURL         string
+}
+
+// ListOrgDependabotAlerts returns the open Dependabot alerts of all repositories in the organization
+func (c *Client) ListOrgDependabotAlerts(ctx context.Context) ([]DependabotAlert, error) {
+	return c.listDependabotAlerts(ctx, "", func(opt *github.ListAlertsOptions) ([]*github.DependabotAlert, *github.Response, error) {
+		return c.client.Dependabot.ListOrgAlerts(ctx, c.org, opt)
+	})
+}
+
+// ListRepoDependabotAlerts returns the open Dependabot alerts of a repository
+func (c *Client) ListRepoDependabotAlerts(ctx context.Context, fullName string) ([]DependabotAlert, error) {
+	parts := strings.SplitN(fullName, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid repository name %q", fullName)
+	}
+	return c.listDependabotAlerts(ctx, fullName, func(opt *github.ListAlertsOptions) ([]*github.DependabotAlert, *github.Response, error) {
+		return c.client.Dependabot.ListRepoAlerts(ctx, parts[0], parts[1], opt)
+	})
+}
+
+func (c *Client) listDependabotAlerts(ctx context.Context, fullName string, list func(*github.ListAlertsOptions) ([]*github.DependabotAlert, *github.Response, error)) ([]DependabotAlert, error) {
+	state := "open"
+	opt := &github.ListAlertsOptions{
+		State:             &state,
+		ListCursorOptions: github.ListCursorOptions{PerPage: 100},
+	}
+
+	var alerts []DependabotAlert
+	for page := 0; page < 50; page++ { // Safety limit
+		result, resp, err := list(opt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range result {
+			alert := toDependabotAlert(a)
+			if alert.RepoFullName == "" {
+				alert.RepoFullName = fullName
+			}
+			alerts = append(alerts, alert)
+		}
+
+		if resp.After == "" {
+			break
+		}
+		opt.After = resp.After
+	}
+
+	return alerts, nil
+}
+
+func toDependabotAlert(a *github.DependabotAlert) DependabotAlert {
+	alert := DependabotAlert{
+		Number:  a.GetNumber(),
+		HTMLURL: a.GetHTMLURL(),
+	}
+	if a.Repository != nil {
+		alert.RepoFullName = a.Repository.GetFullName()
+	}
+	if a.Dependency != nil {
+		alert.ManifestPath = a.Dependency.GetManifestPath()
+		if a.Dependency.Package != nil {
+			alert.Ecosystem = a.Dependency.Package.GetEcosystem()
+			alert.PackageName = a.Dependency.Package.GetName()
+		}
+	}
+	if adv := a.SecurityAdvisory; adv != nil {
+		alert.GHSAID = adv.GetGHSAID()
+		alert.CVEID = adv.GetCVEID()
+		alert.Summary = adv.GetSummary()
+		alert.Severity = adv.GetSeverity()
+	}
+	if vuln := a.SecurityVulnerability; vuln != nil {
+		alert.VulnerableRange = vuln.GetVulnerableVersionRange()
+		if vuln.FirstPatchedVersion != nil {
+			alert.PatchedVersion = vuln.FirstPatchedVersion.GetIdentifier()
+		}
+		if alert.Severity == "" {
+			alert.Severity = vuln.GetSeverity()
+		}
+	}
+	return alert
+}
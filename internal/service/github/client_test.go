@@ -2,6 +2,7 @@ package github
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v68/github"
 )
@@ -148,3 +149,72 @@ func TestRepository_Fields(t *testing.T) {
 		t.Errorf("HTMLURL = %q, want %q", repo.HTMLURL, "https://github.com/owner/test")
 	}
 }
+
+func TestToDependabotAlert(t *testing.T) {
+	alert := toDependabotAlert(&github.DependabotAlert{
+		Number: github.Ptr(7),
+		Dependency: &github.Dependency{
+			Package:      &github.VulnerabilityPackage{Ecosystem: github.Ptr("npm"), Name: github.Ptr("lodash")},
+			ManifestPath: github.Ptr("web/package.json"),
+		},
+		SecurityAdvisory: &github.DependabotSecurityAdvisory{
+			GHSAID:   github.Ptr("GHSA-xxxx-yyyy-zzzz"),
+			CVEID:    github.Ptr("CVE-2021-23337"),
+			Summary:  github.Ptr("Command injection in lodash"),
+			Severity: github.Ptr("high"),
+		},
+		SecurityVulnerability: &github.AdvisoryVulnerability{
+			VulnerableVersionRange: github.Ptr("< 4.17.21"),
+			FirstPatchedVersion:    &github.FirstPatchedVersion{Identifier: github.Ptr("4.17.21")},
+		},
+		HTMLURL:    github.Ptr("https://github.com/owner/app/security/dependabot/7"),
+		Repository: &github.Repository{FullName: github.Ptr("owner/app")},
+	})
+
+	expected := DependabotAlert{
+		RepoFullName:    "owner/app",
+		Number:          7,
+		Ecosystem:       "npm",
+		PackageName:     "lodash",
+		ManifestPath:    "web/package.json",
+		Severity:        "high",
+		GHSAID:          "GHSA-xxxx-yyyy-zzzz",
+		CVEID:           "CVE-2021-23337",
+		Summary:         "Command injection in lodash",
+		VulnerableRange: "< 4.17.21",
+		PatchedVersion:  "4.17.21",
+		HTMLURL:         "https://github.com/owner/app/security/dependabot/7",
+	}
+	if alert != expected {
+		t.Errorf("toDependabotAlert() = %+v, want %+v", alert, expected)
+	}
+
+	empty := toDependabotAlert(&github.DependabotAlert{Number: github.Ptr(1)})
+	if empty.Number != 1 || empty.PackageName != "" || empty.Severity != "" {
+		t.Errorf("toDependabotAlert(empty) = %+v", empty)
+	}
+}
+
+func TestParseTokenExpiration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"2025-01-31 09:30:00 UTC", "2025-01-31T09:30:00Z"},
+		{"2025-01-31 09:30:00 +0900", "2025-01-31T00:30:00Z"},
+		{"", ""},
+		{"not a date", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got := ""
+			if expiresAt := parseTokenExpiration(tt.value); expiresAt != nil {
+				got = expiresAt.UTC().Format(time.RFC3339)
+			}
+			if got != tt.want {
+				t.Errorf("parseTokenExpiration(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
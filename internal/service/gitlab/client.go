@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"time"
@@ -40,20 +41,16 @@ func New(token, baseURL, groupPath string, insecureSkipVerify, membershipOnly bo
 	}
 
 	// Use custom transport with retry logic
-	var baseTransport http.RoundTripper
+	baseTransport := httputil.ProviderTransport()
 	if insecureSkipVerify {
-		baseTransport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
+		baseTransport.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: true,
 		}
-	} else {
-		baseTransport = httputil.DefaultTransport()
 	}
 
-	// Wrap with retry transport
+	// Wrap with retry transport, counting API usage per scan and identifying and tracing each attempt
 	transport := &httputil.RetryTransport{
-		Base:   baseTransport,
+		Base:   &httputil.UsageTransport{Base: httputil.OutboundTransport(baseTransport)},
 		Config: httputil.DefaultRetryConfig(),
 	}
 
@@ -87,12 +84,63 @@ func (c *Client) ValidateToken(ctx context.Context) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("%s/api/v4/user: %d", c.baseURL, resp.StatusCode)
+		return statusError(c.baseURL+"/api/v4/user", resp.StatusCode)
 	}
 
 	return nil
 }
 
+// TokenExpiration validates the token and returns its expiration date. Tokens without
+// expiration return nil. GitLab versions without the personal_access_tokens/self endpoint
+// (before 15.5) fall back to a plain validation.
+func (c *Client) TokenExpiration(ctx context.Context) (*time.Time, error) {
+	endpoint := fmt.Sprintf("%s/api/v4/personal_access_tokens/self", c.baseURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, c.ValidateToken(ctx)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(endpoint, resp.StatusCode)
+	}
+
+	var token struct {
+		ExpiresAt *string `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+	if token.ExpiresAt == nil || *token.ExpiresAt == "" {
+		return nil, nil
+	}
+
+	expiresAt, err := time.Parse("2006-01-02", *token.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &expiresAt, nil
+}
+
+// statusError describes an unexpected response status of a token check. Responses refusing the
+// token wrap httputil.ErrCredentialsRejected.
+func statusError(endpoint string, status int) error {
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return fmt.Errorf("%s: %d: %w", endpoint, status, httputil.ErrCredentialsRejected)
+	}
+	return fmt.Errorf("%s: %d", endpoint, status)
+}
+
 func (c *Client) ListRepositories(ctx context.Context) ([]Repository, error) {
 	var allRepos []Repository
 	page := 1
@@ -154,6 +202,33 @@ func (c *Client) ListRepositories(ctx context.Context) ([]Repository, error) {
 	return allRepos, nil
 }
 
+// GetRepository returns a single project by its path (group/project)
+func (c *Client) GetRepository(ctx context.Context, projectPath string) (*Repository, error) {
+	endpoint := fmt.Sprintf("%s/api/v4/projects/%s", c.baseURL, url.PathEscape(projectPath))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gitlab API returned status %d", resp.StatusCode)
+	}
+
+	var repo Repository
+	if err := json.NewDecoder(resp.Body).Decode(&repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
 func (c *Client) GetFileContent(ctx context.Context, projectPath, filePath, ref string) ([]byte, error) {
 	endpoint := fmt.Sprintf("%s/api/v4/projects/%s/repository/files/%s?ref=%s",
 		c.baseURL,
@@ -210,6 +285,10 @@ func (c *Client) ListManifestFiles(ctx context.Context, projectPath, ref string)
 		"build.gradle":     true,
 		"build.gradle.kts": true,
 		"go.mod":           true,
+		"Package.swift":    true,
+		"Package.resolved": true,
+		"Podfile":          true,
+		"Podfile.lock":     true,
 	}
 
 	var manifests []string
@@ -267,3 +346,88 @@ func (c *Client) ListManifestFiles(ctx context.Context, projectPath, ref string)
 
 	return manifests, nil
 }
+
+// DownloadArchive returns the gzipped tarball of the repository at ref. The caller must close it.
+func (c *Client) DownloadArchive(ctx context.Context, projectPath, ref string) (io.ReadCloser, error) {
+	endpoint := fmt.Sprintf("%s/api/v4/projects/%s/repository/archive.tar.gz?sha=%s",
+		c.baseURL,
+		url.PathEscape(projectPath),
+		url.QueryEscape(ref),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("gitlab API returned status %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// Branch represents a repository branch
+type Branch struct {
+	Name string `json:"name"`
+}
+
+// ListBranches returns the names of all branches in the repository
+func (c *Client) ListBranches(ctx context.Context, projectPath string) ([]string, error) {
+	var branches []string
+	page := 1
+	perPage := 100
+
+	for {
+		endpoint := fmt.Sprintf("%s/api/v4/projects/%s/repository/branches?page=%d&per_page=%d",
+			c.baseURL,
+			url.PathEscape(projectPath),
+			page,
+			perPage,
+		)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("gitlab API returned status %d", resp.StatusCode)
+		}
+
+		var entries []Branch
+		if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		resp.Body.Close()
+
+		if len(entries) == 0 {
+			break
+		}
+
+		for _, entry := range entries {
+			branches = append(branches, entry.Name)
+		}
+
+		page++
+		// Safety limit
+		if page > 50 {
+			break
+		}
+	}
+
+	return branches, nil
+}
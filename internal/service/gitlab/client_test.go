@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/jiin/stale/internal/service/httputil"
 )
 
 func TestNew(t *testing.T) {
@@ -117,6 +120,56 @@ func TestValidateToken(t *testing.T) {
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ValidateToken() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			// Only a refused token is reported as such, not a failing server
+			if rejected := errors.Is(err, httputil.ErrCredentialsRejected); rejected != (tt.statusCode == http.StatusUnauthorized) {
+				t.Errorf("ValidateToken() error = %v, rejected = %v", err, rejected)
+			}
+		})
+	}
+}
+
+func TestTokenExpiration(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       string
+		wantErr    bool
+	}{
+		{"expiring token", http.StatusOK, `{"id": 1, "expires_at": "2030-06-01"}`, "2030-06-01", false},
+		{"no expiration", http.StatusOK, `{"id": 1, "expires_at": null}`, "", false},
+		{"invalid token", http.StatusUnauthorized, `{"message": "401 Unauthorized"}`, "", true},
+		{"endpoint not available", http.StatusNotFound, `{"message": "404 Not Found"}`, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v4/user" {
+					w.Write([]byte(`{"id": 1, "username": "test"}`))
+					return
+				}
+				if r.URL.Path != "/api/v4/personal_access_tokens/self" {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := New("test-token", server.URL, "", false, false)
+			expiresAt, err := client.TokenExpiration(context.Background())
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TokenExpiration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := ""
+			if expiresAt != nil {
+				got = expiresAt.Format("2006-01-02")
+			}
+			if got != tt.want {
+				t.Errorf("TokenExpiration() = %q, want %q", got, tt.want)
+			}
 		})
 	}
 }
@@ -478,3 +531,72 @@ func TestListManifestFiles(t *testing.T) {
 		}
 	})
 }
+
+func TestListBranches(t *testing.T) {
+	t.Run("lists branches across pages", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "1" {
+				json.NewEncoder(w).Encode([]Branch{{Name: "main"}, {Name: "release/1.x"}})
+			} else {
+				json.NewEncoder(w).Encode([]Branch{})
+			}
+		}))
+		defer server.Close()
+
+		client := New("test-token", server.URL, "", false, false)
+		branches, err := client.ListBranches(context.Background(), "user/repo")
+
+		if err != nil {
+			t.Fatalf("ListBranches() error = %v", err)
+		}
+		if len(branches) != 2 || branches[0] != "main" || branches[1] != "release/1.x" {
+			t.Errorf("ListBranches() = %v, want [main release/1.x]", branches)
+		}
+	})
+
+	t.Run("API error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := New("test-token", server.URL, "", false, false)
+		if _, err := client.ListBranches(context.Background(), "user/repo"); err == nil {
+			t.Error("expected error for not found response")
+		}
+	})
+}
+
+func TestGetRepository(t *testing.T) {
+	t.Run("returns project", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.EscapedPath() != "/api/v4/projects/group%2Fproject" {
+				t.Errorf("unexpected path %s", r.URL.EscapedPath())
+			}
+			json.NewEncoder(w).Encode(Repository{ID: 7, Name: "project", FullName: "group/project", DefaultBranch: "develop"})
+		}))
+		defer server.Close()
+
+		client := New("test-token", server.URL, "", false, false)
+		repo, err := client.GetRepository(context.Background(), "group/project")
+
+		if err != nil {
+			t.Fatalf("GetRepository() error = %v", err)
+		}
+		if repo.FullName != "group/project" || repo.DefaultBranch != "develop" {
+			t.Errorf("GetRepository() = %+v", repo)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := New("test-token", server.URL, "", false, false)
+		if _, err := client.GetRepository(context.Background(), "group/missing"); err == nil {
+			t.Error("expected error for not found response")
+		}
+	})
+}
@@ -0,0 +1,355 @@
+// Package gitrepo reads repositories from plain git servers (Gerrit, self-hosted bare
+// repositories, ...) that have no REST API, by shallow-cloning them with go-git.
+package gitrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/jiin/stale/internal/service/httputil"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+var manifestNames = map[string]bool{
+	"package.json":     true,
+	"pom.xml":          true,
+	"build.gradle":     true,
+	"build.gradle.kts": true,
+	"go.mod":           true,
+	"Package.swift":    true,
+	"Package.resolved": true,
+	"Podfile":          true,
+	"Podfile.lock":     true,
+}
+
+// Client reads manifests from git repositories over HTTPS or SSH.
+// The token is used as the HTTP password, or as a PEM-encoded private key for SSH URLs.
+// A username can be given in the URL (https://user@host, ssh://user@host); it defaults to "git".
+type Client struct {
+	token              string
+	baseURL            string   // e.g. https://gerrit.example.com or ssh://git@git.example.com:29418
+	repositories       []string // repository paths relative to baseURL, or full clone URLs
+	insecureSkipVerify bool
+	allowLocal         bool // tests clone repositories from local paths
+
+	mu       sync.Mutex
+	cloneKey string // repository and ref of the cached working tree
+	worktree billy.Filesystem
+}
+
+type Repository struct {
+	Name          string
+	FullName      string
+	DefaultBranch string
+	CloneURL      string
+}
+
+func New(token, baseURL, repositories string, insecureSkipVerify bool) *Client {
+	var repos []string
+	for _, r := range strings.Split(repositories, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			repos = append(repos, r)
+		}
+	}
+
+	return &Client{
+		token:              token,
+		baseURL:            strings.TrimSuffix(baseURL, "/"),
+		repositories:       repos,
+		insecureSkipVerify: insecureSkipVerify,
+	}
+}
+
+// ValidateToken checks that the first configured repository can be reached with the credentials.
+// Refused credentials wrap httputil.ErrCredentialsRejected.
+func (c *Client) ValidateToken(ctx context.Context) error {
+	if len(c.repositories) == 0 {
+		return errors.New("no repositories configured")
+	}
+	_, err := c.listRefs(ctx, c.repositories[0])
+	if errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed) ||
+		(err != nil && strings.Contains(err.Error(), "unable to authenticate")) { // SSH key refused
+		return fmt.Errorf("%w: %w", httputil.ErrCredentialsRejected, err)
+	}
+	return err
+}
+
+// ListRepositories returns the configured repositories. Plain git servers cannot be
+// enumerated, so repositories must be listed explicitly on the source.
+func (c *Client) ListRepositories(ctx context.Context) ([]Repository, error) {
+	var repos []Repository
+	for _, repoPath := range c.repositories {
+		repo, err := c.GetRepository(ctx, repoPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", repoPath, err)
+		}
+		repos = append(repos, *repo)
+	}
+	return repos, nil
+}
+
+// GetRepository returns a repository with its default branch (the target of the remote HEAD)
+func (c *Client) GetRepository(ctx context.Context, repoPath string) (*Repository, error) {
+	refs, err := c.listRefs(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultBranch := "main"
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference {
+			defaultBranch = ref.Target().Short()
+			break
+		}
+	}
+
+	name := strings.TrimSuffix(path.Base(strings.TrimSuffix(repoPath, "/")), ".git")
+	return &Repository{
+		Name:          name,
+		FullName:      repoPath,
+		DefaultBranch: defaultBranch,
+		CloneURL:      c.cloneURL(repoPath),
+	}, nil
+}
+
+// ListBranches returns the names of all branches of the repository
+func (c *Client) ListBranches(ctx context.Context, repoPath string) ([]string, error) {
+	refs, err := c.listRefs(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+	for _, ref := range refs {
+		if ref.Name().IsBranch() {
+			branches = append(branches, ref.Name().Short())
+		}
+	}
+	sort.Strings(branches)
+	return branches, nil
+}
+
+// ListManifestFiles returns all manifest file paths in the working tree of the repository at ref
+func (c *Client) ListManifestFiles(ctx context.Context, repoPath, ref string) ([]string, error) {
+	fs, err := c.checkout(ctx, repoPath, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []string
+	err = util.Walk(fs, "/", func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && manifestNames[info.Name()] {
+			manifests = append(manifests, strings.TrimPrefix(p, "/"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(manifests)
+	return manifests, nil
+}
+
+// GetFileContent reads a file from the working tree of the repository at ref
+func (c *Client) GetFileContent(ctx context.Context, repoPath, filePath, ref string) ([]byte, error) {
+	fs, err := c.checkout(ctx, repoPath, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := fs.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+// checkout returns the working tree of the repository at ref, shallow-cloning it into memory.
+// Only the most recent clone is kept since repositories are scanned one after another.
+func (c *Client) checkout(ctx context.Context, repoPath, ref string) (billy.Filesystem, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := repoPath + "@" + ref
+	if c.cloneKey == key && c.worktree != nil {
+		return c.worktree, nil
+	}
+
+	repoURL, err := c.remoteURL(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := c.auth(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &git.CloneOptions{
+		URL:             repoURL,
+		Auth:            auth,
+		Depth:           1,
+		SingleBranch:    true,
+		Tags:            git.NoTags,
+		InsecureSkipTLS: c.insecureSkipVerify,
+	}
+	if ref != "" {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(ref)
+	}
+
+	httputil.UsageFromContext(ctx).AddCall()
+	fs := memfs.New()
+	if _, err := git.CloneContext(ctx, memory.NewStorage(), fs, opts); err != nil {
+		return nil, fmt.Errorf("clone %s: %w", repoPath, err)
+	}
+
+	c.cloneKey = key
+	c.worktree = fs
+	return fs, nil
+}
+
+func (c *Client) listRefs(ctx context.Context, repoPath string) ([]*plumbing.Reference, error) {
+	repoURL, err := c.remoteURL(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := c.auth(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	httputil.UsageFromContext(ctx).AddCall()
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{repoURL},
+	})
+	return remote.ListContext(ctx, &git.ListOptions{
+		Auth:            auth,
+		InsecureSkipTLS: c.insecureSkipVerify,
+	})
+}
+
+// cloneURL builds the clone URL of a repository. Entries that are already full URLs are used as-is.
+func (c *Client) cloneURL(repoPath string) string {
+	if isRemoteURL(repoPath) || c.baseURL == "" {
+		return repoPath
+	}
+	if strings.HasSuffix(c.baseURL, ":") { // scp-like base, e.g. git@host:
+		return c.baseURL + repoPath
+	}
+	return c.baseURL + "/" + strings.TrimPrefix(repoPath, "/")
+}
+
+// remoteURL returns the clone URL of a repository, refusing anything but remote servers
+func (c *Client) remoteURL(repoPath string) (string, error) {
+	repoURL := c.cloneURL(repoPath)
+	if c.allowLocal {
+		return repoURL, nil
+	}
+	if err := ValidateRemote(repoURL); err != nil {
+		return "", err
+	}
+	return repoURL, nil
+}
+
+// ValidateRepositories checks that the base URL and each repository of a git source resolve to
+// remote clone URLs
+func ValidateRepositories(baseURL, repositories string) error {
+	if baseURL != "" {
+		if err := ValidateRemote(baseURL); err != nil {
+			return err
+		}
+	}
+	c := New("", baseURL, repositories, false)
+	for _, repoPath := range c.repositories {
+		if _, err := c.remoteURL(repoPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateRemote returns an error unless s is an https:// or ssh:// URL or an scp-like
+// user@host:path remote. Local paths and file:// URLs would read the server's own filesystem.
+func ValidateRemote(s string) error {
+	if u, err := url.Parse(s); err == nil && u.Scheme != "" {
+		if (u.Scheme == "https" || u.Scheme == "ssh") && u.Host != "" {
+			return nil
+		}
+	} else if isSCPLike(s) {
+		return nil
+	}
+	return fmt.Errorf("%q is not an https://, ssh:// or user@host:path remote", s)
+}
+
+// isSCPLike reports whether s is an scp-like SSH remote, user@host:path
+func isSCPLike(s string) bool {
+	userHost, _, ok := strings.Cut(s, ":")
+	user, host, found := strings.Cut(userHost, "@")
+	return ok && found && user != "" && host != "" && !strings.ContainsAny(userHost, "/\\")
+}
+
+func (c *Client) auth(repoURL string) (transport.AuthMethod, error) {
+	if c.token == "" {
+		return nil, nil
+	}
+
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	user := endpoint.User
+	if user == "" {
+		user = "git"
+	}
+
+	switch endpoint.Protocol {
+	case "ssh":
+		keys, err := gitssh.NewPublicKeys(user, []byte(c.token), "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSH private key: %w", err)
+		}
+		if c.insecureSkipVerify {
+			keys.HostKeyCallback = gossh.InsecureIgnoreHostKey()
+		}
+		return keys, nil
+	case "http", "https":
+		return &githttp.BasicAuth{Username: user, Password: c.token}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// isRemoteURL reports whether s is a full git remote URL (scheme URL or scp-like user@host:path)
+func isRemoteURL(s string) bool {
+	if u, err := url.Parse(s); err == nil && u.Scheme != "" && u.Host != "" {
+		return true
+	}
+	at := strings.Index(s, "@")
+	colon := strings.Index(s, ":")
+	return at > 0 && colon > at
+}
@@ -0,0 +1,197 @@
+package gitrepo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// initRepo creates a repository with a commit on main and one on release/1.x
+func initRepo(t *testing.T, dir string) {
+	t.Helper()
+
+	repo, err := git.PlainInitWithOptions(dir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+
+	commit := func(files map[string]string) {
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := wt.Add(name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+		if _, err := wt.Commit("commit", &git.CommitOptions{Author: sig}); err != nil {
+			t.Fatalf("commit: %v", err)
+		}
+	}
+
+	commit(map[string]string{
+		"package.json":     `{"dependencies":{"react":"^18.2.0"}}`,
+		"services/go.mod":  "module example.com/svc\n",
+		"README.md":        "readme",
+		"docs/pom.xml.bak": "not a manifest",
+	})
+
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("release/1.x"), Create: true}); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	commit(map[string]string{"web/package.json": `{}`})
+
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("main")}); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+}
+
+func TestClient_LocalRepository(t *testing.T) {
+	base := t.TempDir()
+	initRepo(t, filepath.Join(base, "app"))
+
+	client := New("", base, "app", false)
+	client.allowLocal = true
+	ctx := context.Background()
+
+	repos, err := client.ListRepositories(ctx)
+	if err != nil {
+		t.Fatalf("ListRepositories() error = %v", err)
+	}
+	if len(repos) != 1 || repos[0].Name != "app" || repos[0].FullName != "app" || repos[0].DefaultBranch != "main" {
+		t.Fatalf("ListRepositories() = %+v", repos)
+	}
+
+	branches, err := client.ListBranches(ctx, "app")
+	if err != nil {
+		t.Fatalf("ListBranches() error = %v", err)
+	}
+	if len(branches) != 2 || branches[0] != "main" || branches[1] != "release/1.x" {
+		t.Errorf("ListBranches() = %v, want [main release/1.x]", branches)
+	}
+
+	manifests, err := client.ListManifestFiles(ctx, "app", "main")
+	if err != nil {
+		t.Fatalf("ListManifestFiles() error = %v", err)
+	}
+	if len(manifests) != 2 || manifests[0] != "package.json" || manifests[1] != "services/go.mod" {
+		t.Errorf("ListManifestFiles(main) = %v, want [package.json services/go.mod]", manifests)
+	}
+
+	content, err := client.GetFileContent(ctx, "app", "package.json", "main")
+	if err != nil {
+		t.Fatalf("GetFileContent() error = %v", err)
+	}
+	if string(content) != `{"dependencies":{"react":"^18.2.0"}}` {
+		t.Errorf("GetFileContent() = %q", content)
+	}
+
+	manifests, err = client.ListManifestFiles(ctx, "app", "release/1.x")
+	if err != nil {
+		t.Fatalf("ListManifestFiles(release/1.x) error = %v", err)
+	}
+	if len(manifests) != 3 {
+		t.Errorf("ListManifestFiles(release/1.x) = %v, want 3 manifests", manifests)
+	}
+
+	if _, err := client.ListManifestFiles(ctx, "app", "missing"); err == nil {
+		t.Error("expected error for unknown branch")
+	}
+}
+
+func TestClient_RefusesLocalRepository(t *testing.T) {
+	base := t.TempDir()
+	initRepo(t, filepath.Join(base, "app"))
+
+	for _, client := range []*Client{New("", base, "app", false), New("", "", "file://"+filepath.Join(base, "app"), false)} {
+		if _, err := client.ListRepositories(context.Background()); err == nil {
+			t.Errorf("ListRepositories() of %v should fail for a local repository", client.repositories)
+		}
+	}
+}
+
+func TestValidateRemote(t *testing.T) {
+	tests := []struct {
+		remote  string
+		wantErr bool
+	}{
+		{"https://gerrit.example.com/platform/api", false},
+		{"ssh://git@git.example.com:29418/platform/api", false},
+		{"git@git.example.com:team/repo.git", false},
+		{"git@git.example.com:", false},
+		{"http://git.example.com/repo.git", true},
+		{"file:///etc", true},
+		{"file://localhost/etc", true},
+		{"/var/lib/stale", true},
+		{"../other", true},
+		{"repo", true},
+		{"./a@b:c", true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateRemote(tt.remote); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateRemote(%q) error = %v, wantErr %v", tt.remote, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCloneURL(t *testing.T) {
+	tests := []struct {
+		baseURL  string
+		repo     string
+		expected string
+	}{
+		{"https://gerrit.example.com/", "platform/api", "https://gerrit.example.com/platform/api"},
+		{"ssh://git@git.example.com:29418", "/platform/api", "ssh://git@git.example.com:29418/platform/api"},
+		{"git@git.example.com:", "team/repo.git", "git@git.example.com:team/repo.git"},
+		{"https://gerrit.example.com", "https://other.example.com/repo.git", "https://other.example.com/repo.git"},
+		{"https://gerrit.example.com", "git@other.example.com:repo.git", "git@other.example.com:repo.git"},
+		{"", "https://git.example.com/repo.git", "https://git.example.com/repo.git"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repo, func(t *testing.T) {
+			client := New("", tt.baseURL, tt.repo, false)
+			if got := client.cloneURL(tt.repo); got != tt.expected {
+				t.Errorf("cloneURL(%q) = %q, want %q", tt.repo, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAuth(t *testing.T) {
+	client := New("secret", "https://gerrit.example.com", "repo", false)
+	auth, err := client.auth("https://alice@gerrit.example.com/repo")
+	if err != nil {
+		t.Fatalf("auth() error = %v", err)
+	}
+	if auth == nil || auth.Name() != "http-basic-auth" {
+		t.Fatalf("auth() = %v, want basic auth", auth)
+	}
+
+	if _, err := client.auth("ssh://git@gerrit.example.com/repo"); err == nil {
+		t.Error("expected error for token that is not an SSH private key")
+	}
+
+	anonymous := New("", "https://gerrit.example.com", "repo", false)
+	if auth, _ := anonymous.auth("https://gerrit.example.com/repo"); auth != nil {
+		t.Errorf("auth() = %v, want nil without token", auth)
+	}
+}
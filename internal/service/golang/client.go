@@ -14,7 +14,11 @@ import (
 
 const proxyURL = "https://proxy.golang.org"
 
-// Cache TTL: 1 hour - go module versions don't change that frequently
+// releasesURL lists the current Go releases, newest first
+const releasesURL = "https://go.dev/dl/?mode=json"
+
+// Cache TTL: 1 hour - go module versions don't change that frequently.
+// Expired entries are refreshed with conditional requests, see httputil.ConditionalCacheTransport
 const cacheTTL = 1 * time.Hour
 
 type Client struct {
@@ -23,6 +27,12 @@ type Client struct {
 	cache       *cache.Cache[string]
 }
 
+// Release is a Go release listed on go.dev
+type Release struct {
+	Version string `json:"version"` // e.g. go1.22.3
+	Stable  bool   `json:"stable"`
+}
+
 type ModuleInfo struct {
 	Version string `json:"Version"`
 	Time    string `json:"Time"`
@@ -30,12 +40,17 @@ type ModuleInfo struct {
 
 func New() *Client {
 	return &Client{
-		httpClient:  httputil.NewClient(10 * time.Second),
+		httpClient:  httputil.NewCachingClient(10 * time.Second),
 		retryConfig: httputil.DefaultRetryConfig(),
 		cache:       cache.New[string](cacheTTL),
 	}
 }
 
+// SetCacheTTL changes how long latest versions are cached, 0 disables the cache
+func (c *Client) SetCacheTTL(ttl time.Duration) {
+	c.cache.SetTTL(ttl)
+}
+
 func (c *Client) GetLatestVersion(ctx context.Context, modulePath string) (string, error) {
 	// Check cache first
 	if version, found := c.cache.Get(modulePath); found {
@@ -85,3 +100,49 @@ func (c *Client) GetLatestVersion(ctx context.Context, modulePath string) (strin
 	c.cache.Set(modulePath, info.Version)
 	return info.Version, nil
 }
+
+// GetLatestRelease returns the latest stable Go release, e.g. go1.22.3
+func (c *Client) GetLatestRelease(ctx context.Context) (string, error) {
+	const cacheKey = "release:go"
+	if version, found := c.cache.Get(cacheKey); found {
+		return version, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", releasesURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := httputil.DoWithRetry(ctx, c.httpClient, req, c.retryConfig)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("go.dev returned %d for the release list", resp.StatusCode)
+	}
+
+	var releases []Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return "", err
+	}
+	version := LatestStable(releases)
+	if version == "" {
+		return "", fmt.Errorf("go.dev listed no stable release")
+	}
+
+	c.cache.Set(cacheKey, version)
+	return version, nil
+}
+
+// LatestStable returns the first stable release of a release list, which go.dev sorts newest
+// first, or an empty string when there is none
+func LatestStable(releases []Release) string {
+	for _, r := range releases {
+		if r.Stable {
+			return r.Version
+		}
+	}
+	return ""
+}
@@ -151,3 +151,17 @@ func TestModuleInfo_Parsing(t *testing.T) {
 		t.Errorf("expected status 200, got %d", resp.StatusCode)
 	}
 }
+
+func TestLatestStable(t *testing.T) {
+	releases := []Release{
+		{Version: "go1.23rc1", Stable: false},
+		{Version: "go1.22.3", Stable: true},
+		{Version: "go1.21.10", Stable: true},
+	}
+	if got := LatestStable(releases); got != "go1.22.3" {
+		t.Errorf("LatestStable() = %q, want go1.22.3", got)
+	}
+	if got := LatestStable(nil); got != "" {
+		t.Errorf("LatestStable(nil) = %q, want empty", got)
+	}
+}
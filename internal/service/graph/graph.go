@@ -0,0 +1,130 @@
+// Package graph builds a graph of repositories and the packages they share.
+package graph
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+// Build creates a graph with an edge from each repository to each package it depends on.
+// Only packages used by at least minRepos repositories are included, so the graph shows
+// which packages bind repositories together. Repositories without such packages are omitted.
+func Build(deps []domain.DependencyWithRepo, minRepos int) *domain.DependencyGraph {
+	type edgeKey struct{ repo, pkg string }
+
+	edges := make(map[edgeKey]*domain.GraphEdge)
+	packages := make(map[string]domain.GraphNode)
+	reposByPackage := make(map[string]map[string]bool)
+	var order []edgeKey
+
+	for _, dep := range deps {
+		repoID := "repo:" + dep.RepoFullName
+		pkgID := "pkg:" + dep.Ecosystem + ":" + dep.Name
+
+		if _, ok := packages[pkgID]; !ok {
+			packages[pkgID] = domain.GraphNode{ID: pkgID, Label: dep.Name, Kind: "package", Ecosystem: dep.Ecosystem}
+			reposByPackage[pkgID] = make(map[string]bool)
+		}
+		reposByPackage[pkgID][repoID] = true
+
+		// A package declared in several manifests of a repository becomes a single edge
+		key := edgeKey{repoID, pkgID}
+		if edge, ok := edges[key]; ok {
+			if !containsVersion(edge.Version, dep.CurrentVersion) {
+				edge.Version += ", " + dep.CurrentVersion
+			}
+			edge.Outdated = edge.Outdated || dep.IsOutdated
+			continue
+		}
+		edges[key] = &domain.GraphEdge{Source: repoID, Target: pkgID, Version: dep.CurrentVersion, Outdated: dep.IsOutdated}
+		order = append(order, key)
+	}
+
+	graph := &domain.DependencyGraph{Nodes: []domain.GraphNode{}, Edges: []domain.GraphEdge{}}
+	repos := make(map[string]string)
+	for _, key := range order {
+		if len(reposByPackage[key.pkg]) < minRepos {
+			continue
+		}
+		graph.Edges = append(graph.Edges, *edges[key])
+		repos[key.repo] = strings.TrimPrefix(key.repo, "repo:")
+	}
+
+	for id, label := range repos {
+		graph.Nodes = append(graph.Nodes, domain.GraphNode{ID: id, Label: label, Kind: "repository"})
+	}
+	for id, node := range packages {
+		if count := len(reposByPackage[id]); count >= minRepos {
+			node.RepoCount = count
+			graph.Nodes = append(graph.Nodes, node)
+		}
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		if graph.Nodes[i].Kind != graph.Nodes[j].Kind {
+			return graph.Nodes[i].Kind == "repository"
+		}
+		return graph.Nodes[i].ID < graph.Nodes[j].ID
+	})
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].Source != graph.Edges[j].Source {
+			return graph.Edges[i].Source < graph.Edges[j].Source
+		}
+		return graph.Edges[i].Target < graph.Edges[j].Target
+	})
+
+	return graph
+}
+
+func containsVersion(versions, version string) bool {
+	for _, v := range strings.Split(versions, ", ") {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteDOT writes the graph in Graphviz DOT format. Repositories are drawn as boxes,
+// packages as ellipses, and edges to outdated versions in red.
+func WriteDOT(w io.Writer, g *domain.DependencyGraph) error {
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("  rankdir=LR;\n")
+
+	for _, node := range g.Nodes {
+		shape := "ellipse"
+		if node.Kind == "repository" {
+			shape = "box"
+		}
+		label := node.Label
+		if node.RepoCount > 0 {
+			label = fmt.Sprintf("%s (%d)", node.Label, node.RepoCount)
+		}
+		fmt.Fprintf(&b, "  %s [label=%s, shape=%s];\n", quote(node.ID), quote(label), shape)
+	}
+
+	for _, edge := range g.Edges {
+		attrs := "label=" + quote(edge.Version)
+		if edge.Outdated {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", quote(edge.Source), quote(edge.Target), attrs)
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// quote returns s as a DOT double-quoted string
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
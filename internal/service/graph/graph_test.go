@@ -0,0 +1,98 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func dep(repo, ecosystem, name, version string, outdated bool) domain.DependencyWithRepo {
+	return domain.DependencyWithRepo{
+		Dependency: domain.Dependency{
+			Name:           name,
+			Ecosystem:      ecosystem,
+			CurrentVersion: version,
+			IsOutdated:     outdated,
+		},
+		RepoFullName: repo,
+	}
+}
+
+func TestBuild(t *testing.T) {
+	deps := []domain.DependencyWithRepo{
+		dep("org/web", "npm", "react", "^18.2.0", false),
+		dep("org/admin", "npm", "react", "^17.0.0", true),
+		dep("org/admin", "npm", "react", "^17.0.0", true), // second manifest, same version
+		dep("org/web", "npm", "left-pad", "1.0.0", false), // used by a single repository
+		dep("org/cli", "npm", "chalk", "5.0.0", false),
+	}
+
+	g := Build(deps, 2)
+
+	if len(g.Nodes) != 3 {
+		t.Fatalf("expected 3 nodes, got %+v", g.Nodes)
+	}
+	if g.Nodes[0].ID != "repo:org/admin" || g.Nodes[1].ID != "repo:org/web" {
+		t.Errorf("expected repositories first in order, got %+v", g.Nodes)
+	}
+	pkg := g.Nodes[2]
+	if pkg.ID != "pkg:npm:react" || pkg.Kind != "package" || pkg.RepoCount != 2 {
+		t.Errorf("unexpected package node %+v", pkg)
+	}
+
+	if len(g.Edges) != 2 {
+		t.Fatalf("expected 2 edges, got %+v", g.Edges)
+	}
+	if g.Edges[0].Source != "repo:org/admin" || g.Edges[0].Version != "^17.0.0" || !g.Edges[0].Outdated {
+		t.Errorf("unexpected edge %+v", g.Edges[0])
+	}
+
+	all := Build(deps, 1)
+	if len(all.Nodes) != 6 || len(all.Edges) != 4 {
+		t.Errorf("Build(minRepos=1) = %d nodes, %d edges, want 6 and 4", len(all.Nodes), len(all.Edges))
+	}
+}
+
+func TestBuild_MergesVersionsPerRepository(t *testing.T) {
+	g := Build([]domain.DependencyWithRepo{
+		dep("org/mono", "npm", "lodash", "4.17.20", true),
+		dep("org/mono", "npm", "lodash", "4.17.21", false),
+	}, 1)
+
+	if len(g.Edges) != 1 {
+		t.Fatalf("expected 1 edge, got %+v", g.Edges)
+	}
+	if g.Edges[0].Version != "4.17.20, 4.17.21" || !g.Edges[0].Outdated {
+		t.Errorf("unexpected edge %+v", g.Edges[0])
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := Build([]domain.DependencyWithRepo{
+		dep("org/web", "maven", "org.example:lib", "1.0", true),
+	}, 1)
+
+	var b strings.Builder
+	if err := WriteDOT(&b, g); err != nil {
+		t.Fatalf("WriteDOT() error = %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"digraph dependencies {",
+		`"repo:org/web" [label="org/web", shape=box];`,
+		`"pkg:maven:org.example:lib" [label="org.example:lib (1)", shape=ellipse];`,
+		`"repo:org/web" -> "pkg:maven:org.example:lib" [label="1.0", color=red];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	if got := quote(`a"b\c`); got != `"a\"b\\c"` {
+		t.Errorf("quote() = %s", got)
+	}
+}
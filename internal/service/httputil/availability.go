@@ -0,0 +1,190 @@
+package httputil
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AvailabilityWindow is the rolling window availability is computed over
+const AvailabilityWindow = 15 * time.Minute
+
+// maxSamplesPerHost bounds the memory used per host; older samples are dropped first
+const maxSamplesPerHost = 2000
+
+// Availability states, from the error rate of the window
+const (
+	AvailabilityOK       = "ok"
+	AvailabilityDegraded = "degraded" // at least 10% of requests failed
+	AvailabilityDown     = "down"     // at least half of the requests failed
+)
+
+// integrationNames names the hosts of well-known registries and providers
+var integrationNames = map[string]string{
+	"registry.npmjs.org":    "npm",
+	"repo1.maven.org":       "Maven Central",
+	"proxy.golang.org":      "Go module proxy",
+	"go.dev":                "Go releases",
+	"swiftpackageindex.com": "Swift Package Index",
+	"trunk.cocoapods.org":   "CocoaPods trunk",
+	"dl.google.com":         "Google Android repository",
+	"api.github.com":        "GitHub",
+	"gitlab.com":            "GitLab",
+}
+
+// IntegrationStatus summarizes the requests made to a host within the availability window
+type IntegrationStatus struct {
+	Name          string     `json:"name"`
+	Host          string     `json:"host"`
+	Status        string     `json:"status"`
+	Requests      int        `json:"requests"`
+	Errors        int        `json:"errors"`
+	ErrorRate     float64    `json:"error_rate"`
+	AvgLatencyMs  int64      `json:"avg_latency_ms"`
+	P95LatencyMs  int64      `json:"p95_latency_ms"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+}
+
+type sample struct {
+	at      time.Time
+	latency time.Duration
+	err     string // empty for successful requests
+}
+
+// Availability records the outcome and latency of requests per host. Transport errors, 5xx
+// responses and rate limiting count as errors; other responses, such as a 404 for an unknown
+// package, are the registry working as intended.
+type Availability struct {
+	mu      sync.Mutex
+	samples map[string][]sample
+}
+
+func NewAvailability() *Availability {
+	return &Availability{samples: make(map[string][]sample)}
+}
+
+// defaultAvailability records the requests of every client created by this package
+var defaultAvailability = NewAvailability()
+
+// IntegrationStatuses returns the availability of the hosts requested by this instance, see
+// Availability.Statuses
+func IntegrationStatuses(now time.Time) []IntegrationStatus {
+	return defaultAvailability.Statuses(now)
+}
+
+// Record adds the outcome of a request to host that completed at the given time
+func (a *Availability) Record(host string, at time.Time, latency time.Duration, errMsg string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	samples := append(a.samples[host], sample{at: at, latency: latency, err: errMsg})
+	if len(samples) > maxSamplesPerHost {
+		samples = samples[len(samples)-maxSamplesPerHost:]
+	}
+	a.samples[host] = samples
+}
+
+// Statuses returns the availability of each host requested within the window ending at now,
+// ordered by name. Samples older than the window are dropped.
+func (a *Availability) Statuses(now time.Time) []IntegrationStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cutoff := now.Add(-AvailabilityWindow)
+	statuses := make([]IntegrationStatus, 0, len(a.samples))
+	for host, samples := range a.samples {
+		recent := samples[:0]
+		for _, s := range samples {
+			if !s.at.Before(cutoff) {
+				recent = append(recent, s)
+			}
+		}
+		samples = recent
+		if len(samples) == 0 {
+			delete(a.samples, host)
+			continue
+		}
+		a.samples[host] = samples
+		statuses = append(statuses, summarize(host, samples))
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Name != statuses[j].Name {
+			return statuses[i].Name < statuses[j].Name
+		}
+		return statuses[i].Host < statuses[j].Host
+	})
+	return statuses
+}
+
+func summarize(host string, samples []sample) IntegrationStatus {
+	status := IntegrationStatus{Name: integrationName(host), Host: host, Requests: len(samples)}
+
+	latencies := make([]time.Duration, 0, len(samples))
+	var total time.Duration
+	for _, s := range samples {
+		at := s.at
+		if s.err != "" {
+			status.Errors++
+			status.LastError, status.LastErrorAt = s.err, &at
+		} else {
+			status.LastSuccessAt = &at
+		}
+		latencies = append(latencies, s.latency)
+		total += s.latency
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	status.AvgLatencyMs = (total / time.Duration(len(samples))).Milliseconds()
+	status.P95LatencyMs = latencies[(len(latencies)*95+99)/100-1].Milliseconds()
+	status.ErrorRate = float64(status.Errors) / float64(status.Requests)
+
+	switch {
+	case status.ErrorRate >= 0.5:
+		status.Status = AvailabilityDown
+	case status.ErrorRate >= 0.1:
+		status.Status = AvailabilityDegraded
+	default:
+		status.Status = AvailabilityOK
+	}
+	return status
+}
+
+// integrationName returns the name of a well-known host, or the host itself
+func integrationName(host string) string {
+	if name, ok := integrationNames[host]; ok {
+		return name
+	}
+	return host
+}
+
+// AvailabilityTransport records the outcome and latency of each request in an Availability
+type AvailabilityTransport struct {
+	Base         http.RoundTripper
+	Availability *Availability
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *AvailabilityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	latency := time.Since(start)
+
+	errMsg := ""
+	switch {
+	case err != nil:
+		// A request canceled by the caller says nothing about the host
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+		errMsg = err.Error()
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		errMsg = fmt.Sprintf("status %d", resp.StatusCode)
+	}
+	t.Availability.Record(req.URL.Hostname(), start.Add(latency), latency, errMsg)
+	return resp, err
+}
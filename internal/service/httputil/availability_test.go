@@ -0,0 +1,78 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAvailabilityStatuses(t *testing.T) {
+	a := NewAvailability()
+	now := time.Now()
+
+	// npm: 1 error in 10 requests, plus an error that has left the window
+	a.Record("registry.npmjs.org", now.Add(-time.Hour), time.Second, "status 503")
+	for i := 0; i < 9; i++ {
+		a.Record("registry.npmjs.org", now.Add(-time.Minute), 100*time.Millisecond, "")
+	}
+	a.Record("registry.npmjs.org", now, 300*time.Millisecond, "status 502")
+	// an unknown host, failing
+	a.Record("nexus.example.com", now, 50*time.Millisecond, "connection refused")
+
+	statuses := a.Statuses(now)
+	if len(statuses) != 2 {
+		t.Fatalf("Statuses() returned %d hosts, want 2", len(statuses))
+	}
+
+	nexus, npm := statuses[0], statuses[1]
+	if nexus.Name != "nexus.example.com" || nexus.Status != AvailabilityDown || nexus.LastError != "connection refused" {
+		t.Errorf("nexus = %+v", nexus)
+	}
+	if npm.Name != "npm" || npm.Requests != 10 || npm.Errors != 1 || npm.Status != AvailabilityDegraded {
+		t.Errorf("npm = %+v", npm)
+	}
+	if npm.AvgLatencyMs != 120 || npm.P95LatencyMs != 300 {
+		t.Errorf("npm latency avg = %d, p95 = %d, want 120 and 300", npm.AvgLatencyMs, npm.P95LatencyMs)
+	}
+
+	// Hosts without requests in the window are dropped
+	if statuses := a.Statuses(now.Add(time.Hour)); len(statuses) != 0 {
+		t.Errorf("Statuses() after the window = %+v, want none", statuses)
+	}
+}
+
+func TestAvailabilityTransport(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	a := NewAvailability()
+	client := &http.Client{Transport: &AvailabilityTransport{Base: http.DefaultTransport, Availability: a}}
+	for _, status = range []int{http.StatusOK, http.StatusNotFound, http.StatusTooManyRequests, http.StatusBadGateway} {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// Canceled requests are not recorded
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	client.Do(req)
+
+	statuses := a.Statuses(time.Now())
+	u, _ := url.Parse(server.URL)
+	if len(statuses) != 1 || statuses[0].Host != u.Hostname() {
+		t.Fatalf("Statuses() = %+v", statuses)
+	}
+	if statuses[0].Requests != 4 || statuses[0].Errors != 2 || statuses[0].LastError != "status 502" {
+		t.Errorf("status = %+v, want 4 requests with 2 errors", statuses[0])
+	}
+}
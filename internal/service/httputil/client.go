@@ -2,35 +2,98 @@ package httputil
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"sync/atomic"
 	"time"
 )
 
-// DefaultTransport returns an http.Transport with optimized connection pooling
+// ErrCredentialsRejected is wrapped by provider clients when the server refuses their credentials
+// (401 or 403), as opposed to being unreachable or failing
+var ErrCredentialsRejected = errors.New("credentials rejected")
+
+// TransportConfig tunes the connection pools of outbound HTTP clients
+type TransportConfig struct {
+	MaxConnsPerHost         int  // per host for package registries and other services
+	ProviderMaxConnsPerHost int  // per host for Git provider APIs (api.github.com, GitLab)
+	HTTP2                   bool // negotiate HTTP/2 where the server supports it
+}
+
+// DefaultTransportConfig returns connection limits suited to scan workloads. Provider APIs get
+// fewer connections than registries, as GitHub penalizes highly concurrent clients with
+// secondary rate limits.
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		MaxConnsPerHost:         32,
+		ProviderMaxConnsPerHost: 16,
+		HTTP2:                   true,
+	}
+}
+
+var transportConfig atomic.Pointer[TransportConfig]
+
+// SetTransportConfig sets the configuration of transports created afterwards
+func SetTransportConfig(cfg TransportConfig) {
+	transportConfig.Store(&cfg)
+}
+
+func currentTransportConfig() TransportConfig {
+	if cfg := transportConfig.Load(); cfg != nil {
+		return *cfg
+	}
+	return DefaultTransportConfig()
+}
+
+// DefaultTransport returns an http.Transport with optimized connection pooling for registries
+// and other services
 func DefaultTransport() *http.Transport {
-	return &http.Transport{
+	cfg := currentTransportConfig()
+	return newTransport(cfg.MaxConnsPerHost, cfg.HTTP2)
+}
+
+// ProviderTransport returns an http.Transport with optimized connection pooling for Git
+// provider APIs
+func ProviderTransport() *http.Transport {
+	cfg := currentTransportConfig()
+	return newTransport(cfg.ProviderMaxConnsPerHost, cfg.HTTP2)
+}
+
+// newTransport creates a transport allowing maxConnsPerHost connections per host (0 = unlimited)
+func newTransport(maxConnsPerHost int, http2 bool) *http.Transport {
+	maxIdleConnsPerHost := maxConnsPerHost
+	if maxIdleConnsPerHost <= 0 {
+		maxIdleConnsPerHost = 100
+	}
+	transport := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
 		DialContext: (&net.Dialer{
 			Timeout:   30 * time.Second,
 			KeepAlive: 30 * time.Second,
 		}).DialContext,
 		MaxIdleConns:          100,
-		MaxConnsPerHost:       10,
-		MaxIdleConnsPerHost:   10,
+		MaxConnsPerHost:       maxConnsPerHost,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
-		ForceAttemptHTTP2:     true,
+		ForceAttemptHTTP2:     http2,
+	}
+	if !http2 {
+		// A non-nil empty map disables HTTP/2 negotiation
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
 	}
+	return transport
 }
 
-// NewClient creates an HTTP client with connection pooling
+// NewClient creates an HTTP client with connection pooling whose requests are identified
+// and traced, see OutboundTransport
 func NewClient(timeout time.Duration) *http.Client {
 	return &http.Client{
 		Timeout:   timeout,
-		Transport: DefaultTransport(),
+		Transport: OutboundTransport(DefaultTransport()),
 	}
 }
 
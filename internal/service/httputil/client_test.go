@@ -17,15 +17,34 @@ func TestDefaultTransport(t *testing.T) {
 	if transport.MaxIdleConns != 100 {
 		t.Errorf("MaxIdleConns = %d, want 100", transport.MaxIdleConns)
 	}
-	if transport.MaxConnsPerHost != 10 {
-		t.Errorf("MaxConnsPerHost = %d, want 10", transport.MaxConnsPerHost)
+	if transport.MaxConnsPerHost != 32 {
+		t.Errorf("MaxConnsPerHost = %d, want 32", transport.MaxConnsPerHost)
 	}
-	if transport.MaxIdleConnsPerHost != 10 {
-		t.Errorf("MaxIdleConnsPerHost = %d, want 10", transport.MaxIdleConnsPerHost)
+	if transport.MaxIdleConnsPerHost != 32 {
+		t.Errorf("MaxIdleConnsPerHost = %d, want 32", transport.MaxIdleConnsPerHost)
 	}
 	if transport.IdleConnTimeout != 90*time.Second {
 		t.Errorf("IdleConnTimeout = %v, want 90s", transport.IdleConnTimeout)
 	}
+	if !transport.ForceAttemptHTTP2 {
+		t.Error("ForceAttemptHTTP2 should be true")
+	}
+}
+
+func TestSetTransportConfig(t *testing.T) {
+	SetTransportConfig(TransportConfig{MaxConnsPerHost: 64, ProviderMaxConnsPerHost: 4, HTTP2: false})
+	defer SetTransportConfig(DefaultTransportConfig())
+
+	if got := DefaultTransport().MaxConnsPerHost; got != 64 {
+		t.Errorf("DefaultTransport().MaxConnsPerHost = %d, want 64", got)
+	}
+	provider := ProviderTransport()
+	if provider.MaxConnsPerHost != 4 {
+		t.Errorf("ProviderTransport().MaxConnsPerHost = %d, want 4", provider.MaxConnsPerHost)
+	}
+	if provider.ForceAttemptHTTP2 || provider.TLSNextProto == nil {
+		t.Error("HTTP/2 should be disabled")
+	}
 }
 
 func TestNewClient(t *testing.T) {
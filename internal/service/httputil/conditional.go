@@ -0,0 +1,140 @@
+package httputil
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limits of the response cache of a ConditionalCacheTransport
+const (
+	maxCachedResponseSize = 4 << 20  // larger responses are not cached
+	maxCacheSize          = 64 << 20 // total size of the cached response bodies
+)
+
+// ConditionalCacheTransport caches successful GET responses that carry an ETag or Last-Modified
+// validator and revalidates them with If-None-Match / If-Modified-Since on later requests.
+// A 304 Not Modified answer is turned back into the cached 200 response, so refreshing unchanged
+// registry metadata transfers almost no data.
+type ConditionalCacheTransport struct {
+	Base http.RoundTripper
+
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+	size    int
+}
+
+type cachedResponse struct {
+	header       http.Header
+	body         []byte
+	etag         string
+	lastModified string
+}
+
+// NewCachingClient creates an HTTP client like NewClient whose GET responses are cached and
+// revalidated with conditional requests
+func NewCachingClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &ConditionalCacheTransport{Base: OutboundTransport(DefaultTransport())},
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *ConditionalCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.Base.RoundTrip(req)
+	}
+
+	// The same URL can be served in several representations, e.g. full and abbreviated packuments
+	key := req.URL.String() + " " + req.Header.Get("Accept")
+	cached := t.get(key)
+	if cached != nil {
+		req = req.Clone(req.Context())
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		resp.Body.Close()
+		return cached.response(req), nil
+	case resp.StatusCode == http.StatusOK:
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag == "" && lastModified == "" {
+			return resp, nil
+		}
+		if resp.ContentLength > maxCachedResponseSize {
+			return resp, nil
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedResponseSize+1))
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(body) <= maxCachedResponseSize {
+			t.set(key, &cachedResponse{header: resp.Header.Clone(), body: body, etag: etag, lastModified: lastModified})
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	default:
+		return resp, nil
+	}
+}
+
+func (t *ConditionalCacheTransport) get(key string) *cachedResponse {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.entries[key]
+}
+
+func (t *ConditionalCacheTransport) set(key string, entry *cachedResponse) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.entries == nil {
+		t.entries = make(map[string]*cachedResponse)
+	}
+	if old, ok := t.entries[key]; ok {
+		t.size -= len(old.body)
+	}
+	t.entries[key] = entry
+	t.size += len(entry.body)
+
+	// Evict arbitrary entries once over budget; they are simply fetched in full again
+	for k, e := range t.entries {
+		if t.size <= maxCacheSize {
+			break
+		}
+		if k != key {
+			delete(t.entries, k)
+			t.size -= len(e.body)
+		}
+	}
+}
+
+// response builds a 200 response to req from the cached body
+func (c *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        c.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(c.body)),
+		ContentLength: int64(len(c.body)),
+		Request:       req,
+	}
+}
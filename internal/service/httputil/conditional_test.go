@@ -0,0 +1,101 @@
+package httputil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConditionalCacheTransport(t *testing.T) {
+	var requests, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"dist-tags":{"latest":"1.0.0"}}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &ConditionalCacheTransport{Base: http.DefaultTransport}}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL + "/pkg")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("request %d: status = %d, want 200", i, resp.StatusCode)
+		}
+		if string(body) != `{"dist-tags":{"latest":"1.0.0"}}` {
+			t.Errorf("request %d: body = %q", i, body)
+		}
+	}
+
+	if requests.Load() != 3 || notModified.Load() != 2 {
+		t.Errorf("requests = %d, not modified = %d, want 3 and 2", requests.Load(), notModified.Load())
+	}
+}
+
+func TestConditionalCacheTransport_LastModified(t *testing.T) {
+	const lastModified = "Mon, 03 Mar 2025 10:00:00 GMT"
+	var conditional atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte("<metadata/>"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &ConditionalCacheTransport{Base: http.DefaultTransport}}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "<metadata/>" {
+			t.Errorf("request %d: body = %q", i, body)
+		}
+	}
+
+	if conditional.Load() != 1 {
+		t.Errorf("conditional requests = %d, want 1", conditional.Load())
+	}
+}
+
+func TestConditionalCacheTransport_NoValidators(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			t.Error("unexpected conditional request for a response without validators")
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	transport := &ConditionalCacheTransport{Base: http.DefaultTransport}
+	client := &http.Client{Transport: transport}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if len(transport.entries) != 0 {
+		t.Errorf("cached %d responses, want 0", len(transport.entries))
+	}
+}
@@ -0,0 +1,90 @@
+package httputil
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// Usage counts provider API calls and the bytes of their responses.
+// A nil *Usage ignores all updates.
+type Usage struct {
+	calls atomic.Int64
+	bytes atomic.Int64
+}
+
+// Calls returns the number of requests made, including retries
+func (u *Usage) Calls() int64 {
+	if u == nil {
+		return 0
+	}
+	return u.calls.Load()
+}
+
+// Bytes returns the number of response body bytes read
+func (u *Usage) Bytes() int64 {
+	if u == nil {
+		return 0
+	}
+	return u.bytes.Load()
+}
+
+// AddCall records a call whose response size is unknown (e.g. a git fetch)
+func (u *Usage) AddCall() {
+	if u != nil {
+		u.calls.Add(1)
+	}
+}
+
+func (u *Usage) addBytes(n int) {
+	if u != nil {
+		u.bytes.Add(int64(n))
+	}
+}
+
+type usageContextKey struct{}
+
+// WithUsage returns a context whose provider requests are counted in u
+func WithUsage(ctx context.Context, u *Usage) context.Context {
+	return context.WithValue(ctx, usageContextKey{}, u)
+}
+
+// UsageFromContext returns the Usage attached to the context, or nil
+func UsageFromContext(ctx context.Context) *Usage {
+	u, _ := ctx.Value(usageContextKey{}).(*Usage)
+	return u
+}
+
+// UsageTransport counts requests and response bytes in the Usage attached to the request context.
+// Place it below RetryTransport so that retries are counted as well.
+type UsageTransport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *UsageTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := UsageFromContext(req.Context())
+	if u == nil {
+		return t.Base.RoundTrip(req)
+	}
+
+	u.AddCall()
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, usage: u}
+	return resp, nil
+}
+
+type countingBody struct {
+	io.ReadCloser
+	usage *Usage
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.usage.addBytes(n)
+	return n, err
+}
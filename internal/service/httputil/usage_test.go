@@ -0,0 +1,54 @@
+package httputil
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUsageTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &UsageTransport{Base: http.DefaultTransport}}
+	usage := &Usage{}
+	ctx := WithUsage(context.Background(), usage)
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+
+	if usage.Calls() != 2 {
+		t.Errorf("Calls() = %d, want 2", usage.Calls())
+	}
+	if usage.Bytes() != 22 {
+		t.Errorf("Bytes() = %d, want 22", usage.Bytes())
+	}
+
+	// Requests without usage in their context are not counted
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if usage.Calls() != 2 {
+		t.Errorf("Calls() = %d, want 2 after uncounted request", usage.Calls())
+	}
+
+	var nilUsage *Usage
+	nilUsage.AddCall()
+	if nilUsage.Calls() != 0 {
+		t.Error("nil Usage should ignore calls")
+	}
+}
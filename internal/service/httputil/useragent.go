@@ -0,0 +1,74 @@
+package httputil
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jiin/stale/internal/service/tracing"
+)
+
+const defaultUserAgent = "stale"
+
+var userAgent atomic.Value // string
+
+// SetUserAgent sets the User-Agent sent with all outbound requests, e.g.
+// "stale/0.2.0 (instance: prod; +https://wiki.example.com/stale)". Registries such as npm and
+// Maven Central ask automated clients to identify themselves with a way to contact the operator.
+func SetUserAgent(version, instance, contactURL string) {
+	ua := FormatUserAgent(version, instance, contactURL)
+	userAgent.Store(ua)
+
+	// go-git builds its own requests and appends this to its agent string
+	os.Setenv("GO_GIT_USER_AGENT_EXTRA", ua)
+}
+
+// UserAgent returns the User-Agent sent with outbound requests
+func UserAgent() string {
+	if ua, ok := userAgent.Load().(string); ok {
+		return ua
+	}
+	return defaultUserAgent
+}
+
+// FormatUserAgent builds a User-Agent from the version, instance name and contact URL,
+// leaving out the parts that are empty
+func FormatUserAgent(version, instance, contactURL string) string {
+	ua := defaultUserAgent
+	if version != "" {
+		ua += "/" + version
+	}
+
+	var comments []string
+	if instance != "" {
+		comments = append(comments, "instance: "+instance)
+	}
+	if contactURL != "" {
+		comments = append(comments, "+"+contactURL)
+	}
+	if len(comments) > 0 {
+		ua += " (" + strings.Join(comments, "; ") + ")"
+	}
+	return ua
+}
+
+// UserAgentTransport sets the configured User-Agent on each request, replacing any set by
+// client libraries
+type UserAgentTransport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *UserAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", UserAgent())
+	return t.Base.RoundTrip(req)
+}
+
+// OutboundTransport wraps base so each request identifies itself with the configured
+// User-Agent, is traced and is recorded in the availability of its host
+func OutboundTransport(base http.RoundTripper) http.RoundTripper {
+	return &UserAgentTransport{Base: tracing.Transport(&AvailabilityTransport{Base: base, Availability: defaultAvailability})}
+}
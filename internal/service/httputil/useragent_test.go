@@ -0,0 +1,54 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFormatUserAgent(t *testing.T) {
+	tests := []struct {
+		version, instance, contact string
+		want                       string
+	}{
+		{"", "", "", "stale"},
+		{"0.2.0", "", "", "stale/0.2.0"},
+		{"0.2.0", "prod", "", "stale/0.2.0 (instance: prod)"},
+		{"0.2.0", "", "https://example.com/stale", "stale/0.2.0 (+https://example.com/stale)"},
+		{"0.2.0", "prod", "https://example.com/stale", "stale/0.2.0 (instance: prod; +https://example.com/stale)"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatUserAgent(tt.version, tt.instance, tt.contact); got != tt.want {
+			t.Errorf("FormatUserAgent(%q, %q, %q) = %q, want %q", tt.version, tt.instance, tt.contact, got, tt.want)
+		}
+	}
+}
+
+func TestUserAgentTransport(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+
+	t.Setenv("GO_GIT_USER_AGENT_EXTRA", "") // restored after the test
+	SetUserAgent("1.2.3", "test", "")
+	defer userAgent.Store(defaultUserAgent)
+
+	client := &http.Client{Transport: &UserAgentTransport{Base: http.DefaultTransport}}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("User-Agent", "go-github/v68.0.0")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if got != "stale/1.2.3 (instance: test)" {
+		t.Errorf("User-Agent = %q, want %q", got, "stale/1.2.3 (instance: test)")
+	}
+	if req.Header.Get("User-Agent") != "go-github/v68.0.0" {
+		t.Error("transport modified the caller's request")
+	}
+}
@@ -12,7 +12,8 @@ import (
 	"github.com/jiin/stale/internal/service/httputil"
 )
 
-// Cache TTL: 1 hour - maven versions don't change that frequently
+// Cache TTL: 1 hour - maven versions don't change that frequently.
+// Expired entries are refreshed with conditional requests, see httputil.ConditionalCacheTransport
 const cacheTTL = 1 * time.Hour
 
 type Client struct {
@@ -37,12 +38,17 @@ type mavenMetadata struct {
 
 func New() *Client {
 	return &Client{
-		httpClient:  httputil.NewClient(10 * time.Second),
+		httpClient:  httputil.NewCachingClient(10 * time.Second),
 		retryConfig: httputil.DefaultRetryConfig(),
 		cache:       cache.New[string](cacheTTL),
 	}
 }
 
+// SetCacheTTL changes how long latest versions are cached, 0 disables the cache
+func (c *Client) SetCacheTTL(ttl time.Duration) {
+	c.cache.SetTTL(ttl)
+}
+
 // GetLatestVersion fetches the latest version from Maven Central
 // groupID: e.g., "org.springframework.boot"
 // artifactID: e.g., "spring-boot-starter-web"
@@ -55,6 +61,45 @@ func (c *Client) GetLatestVersion(ctx context.Context, groupID, artifactID strin
 		return version, nil
 	}
 
+	metadata, err := c.fetchMetadata(ctx, groupID, artifactID)
+	if err != nil {
+		return "", err
+	}
+
+	// Prefer release version, fallback to latest, then last version in list
+	version := metadata.Versioning.Release
+	if version == "" {
+		version = metadata.Versioning.Latest
+	}
+	if version == "" && len(metadata.Versioning.Versions.Version) > 0 {
+		// Get the last version in the list (usually the newest)
+		version = metadata.Versioning.Versions.Version[len(metadata.Versioning.Versions.Version)-1]
+	}
+
+	if version == "" {
+		return "", fmt.Errorf("no version found for %s:%s", groupID, artifactID)
+	}
+
+	// Store in cache
+	c.cache.Set(cacheKey, version)
+	return version, nil
+}
+
+// GetVersions returns every version of an artifact listed by Maven Central
+func (c *Client) GetVersions(ctx context.Context, groupID, artifactID string) ([]string, error) {
+	if versions, found := c.cache.Get(versionsCacheKey(groupID, artifactID)); found {
+		return strings.Fields(versions), nil
+	}
+
+	metadata, err := c.fetchMetadata(ctx, groupID, artifactID)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.Versioning.Versions.Version, nil
+}
+
+// fetchMetadata fetches the maven-metadata.xml of an artifact, caching its versions
+func (c *Client) fetchMetadata(ctx context.Context, groupID, artifactID string) (mavenMetadata, error) {
 	// Use maven-metadata.xml from Maven Central repository (more accurate than search API)
 	// Convert groupID dots to path separators: org.springframework.boot -> org/springframework/boot
 	groupPath := strings.ReplaceAll(groupID, ".", "/")
@@ -65,39 +110,80 @@ func (c *Client) GetLatestVersion(ctx context.Context, groupID, artifactID strin
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", err
+		return mavenMetadata{}, err
 	}
 
 	resp, err := httputil.DoWithRetry(ctx, c.httpClient, req, c.retryConfig)
 	if err != nil {
-		return "", err
+		return mavenMetadata{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("maven central returned status %d for %s:%s", resp.StatusCode, groupID, artifactID)
+		return mavenMetadata{}, fmt.Errorf("maven central returned status %d for %s:%s", resp.StatusCode, groupID, artifactID)
 	}
 
 	var metadata mavenMetadata
 	if err := xml.NewDecoder(resp.Body).Decode(&metadata); err != nil {
-		return "", fmt.Errorf("failed to parse maven-metadata.xml: %w", err)
+		return mavenMetadata{}, fmt.Errorf("failed to parse maven-metadata.xml: %w", err)
 	}
 
-	// Prefer release version, fallback to latest, then last version in list
-	version := metadata.Versioning.Release
-	if version == "" {
-		version = metadata.Versioning.Latest
+	c.cache.Set(versionsCacheKey(groupID, artifactID), strings.Join(metadata.Versioning.Versions.Version, " "))
+	return metadata, nil
+}
+
+func versionsCacheKey(groupID, artifactID string) string {
+	return "versions:" + groupID + ":" + artifactID
+}
+
+// GetMaintainers returns the developers listed in the POM of the latest version of an artifact,
+// by name or otherwise by id
+func (c *Client) GetMaintainers(ctx context.Context, groupID, artifactID string) ([]string, error) {
+	version, err := c.GetLatestVersion(ctx, groupID, artifactID)
+	if err != nil {
+		return nil, err
 	}
-	if version == "" && len(metadata.Versioning.Versions.Version) > 0 {
-		// Get the last version in the list (usually the newest)
-		version = metadata.Versioning.Versions.Version[len(metadata.Versioning.Versions.Version)-1]
+
+	groupPath := strings.ReplaceAll(groupID, ".", "/")
+	url := fmt.Sprintf(
+		"https://repo1.maven.org/maven2/%s/%s/%s/%s-%s.pom",
+		groupPath, artifactID, version, artifactID, version,
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if version == "" {
-		return "", fmt.Errorf("no version found for %s:%s", groupID, artifactID)
+	resp, err := httputil.DoWithRetry(ctx, c.httpClient, req, c.retryConfig)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	// Store in cache
-	c.cache.Set(cacheKey, version)
-	return version, nil
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("maven central returned status %d for %s:%s:%s", resp.StatusCode, groupID, artifactID, version)
+	}
+
+	var pom struct {
+		Developers []struct {
+			ID   string `xml:"id"`
+			Name string `xml:"name"`
+		} `xml:"developers>developer"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&pom); err != nil {
+		return nil, fmt.Errorf("failed to parse %s-%s.pom: %w", artifactID, version, err)
+	}
+
+	maintainers := make([]string, 0, len(pom.Developers))
+	for _, d := range pom.Developers {
+		name := strings.TrimSpace(d.Name)
+		if name == "" {
+			name = strings.TrimSpace(d.ID)
+		}
+		if name != "" {
+			maintainers = append(maintainers, name)
+		}
+	}
+	return maintainers, nil
 }
@@ -212,3 +212,16 @@ func TestMavenMetadata_Parsing(t *testing.T) {
 		t.Errorf("expected status 200, got %d", resp.StatusCode)
 	}
 }
+
+func TestGetVersions_Cache(t *testing.T) {
+	client := newTestClient()
+	client.cache.Set(versionsCacheKey("org.example", "test-lib"), "1.0.0 1.1.0 2.0.0")
+
+	versions, err := client.GetVersions(context.Background(), "org.example", "test-lib")
+	if err != nil {
+		t.Fatalf("GetVersions() error = %v", err)
+	}
+	if len(versions) != 3 || versions[2] != "2.0.0" {
+		t.Errorf("GetVersions() = %v, want the cached versions", versions)
+	}
+}
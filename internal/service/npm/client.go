@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/jiin/stale/internal/service/cache"
@@ -14,7 +15,8 @@ import (
 
 const registryURL = "https://registry.npmjs.org"
 
-// Cache TTL: 1 hour - npm versions don't change that frequently
+// Cache TTL: 1 hour - npm versions don't change that frequently.
+// Expired entries are refreshed with conditional requests, see httputil.ConditionalCacheTransport
 const cacheTTL = 1 * time.Hour
 
 type Client struct {
@@ -24,56 +26,188 @@ type Client struct {
 }
 
 type PackageInfo struct {
-	DistTags map[string]string `json:"dist-tags"`
+	DistTags map[string]string          `json:"dist-tags"`
+	Versions map[string]json.RawMessage `json:"versions"`
 }
 
 func New() *Client {
 	return &Client{
-		httpClient:  httputil.NewClient(10 * time.Second),
+		httpClient:  httputil.NewCachingClient(10 * time.Second),
 		retryConfig: httputil.DefaultRetryConfig(),
 		cache:       cache.New[string](cacheTTL),
 	}
 }
 
+// SetCacheTTL changes how long latest versions are cached, 0 disables the cache
+func (c *Client) SetCacheTTL(ttl time.Duration) {
+	c.cache.SetTTL(ttl)
+}
+
 func (c *Client) GetLatestVersion(ctx context.Context, packageName string) (string, error) {
 	// Check cache first
 	if version, found := c.cache.Get(packageName); found {
 		return version, nil
 	}
 
+	info, err := c.fetchPackage(ctx, packageName)
+	if err != nil {
+		return "", err
+	}
+
+	if latest, ok := info.DistTags["latest"]; ok {
+		// Store in cache
+		c.cache.Set(packageName, latest)
+		return latest, nil
+	}
+
+	return "", fmt.Errorf("no latest version found for %s", packageName)
+}
+
+// GetVersions returns every published version of a package, in no particular order
+func (c *Client) GetVersions(ctx context.Context, packageName string) ([]string, error) {
+	if versions, found := c.cache.Get(versionsCacheKey(packageName)); found {
+		return strings.Fields(versions), nil
+	}
+
+	info, err := c.fetchPackage(ctx, packageName)
+	if err != nil {
+		return nil, err
+	}
+	return packageVersions(info), nil
+}
+
+// fetchPackage fetches the abbreviated metadata of a package, caching its versions
+func (c *Client) fetchPackage(ctx context.Context, packageName string) (PackageInfo, error) {
 	encodedName := url.PathEscape(packageName)
 	reqURL := fmt.Sprintf("%s/%s", registryURL, encodedName)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
 	if err != nil {
-		return "", err
+		return PackageInfo{}, err
 	}
 	req.Header.Set("Accept", "application/vnd.npm.install-v1+json")
 
 	resp, err := httputil.DoWithRetry(ctx, c.httpClient, req, c.retryConfig)
 	if err != nil {
-		return "", err
+		return PackageInfo{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return "", fmt.Errorf("package %s not found", packageName)
+		return PackageInfo{}, fmt.Errorf("package %s not found", packageName)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("npm registry returned %d for %s", resp.StatusCode, packageName)
+		return PackageInfo{}, fmt.Errorf("npm registry returned %d for %s", resp.StatusCode, packageName)
 	}
 
 	var info PackageInfo
 	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
-		return "", err
+		return PackageInfo{}, err
 	}
 
-	if latest, ok := info.DistTags["latest"]; ok {
-		// Store in cache
-		c.cache.Set(packageName, latest)
-		return latest, nil
+	c.cache.Set(versionsCacheKey(packageName), strings.Join(packageVersions(info), " "))
+	return info, nil
+}
+
+func versionsCacheKey(packageName string) string {
+	return "versions:" + packageName
+}
+
+func packageVersions(info PackageInfo) []string {
+	versions := make([]string, 0, len(info.Versions))
+	for version := range info.Versions {
+		versions = append(versions, version)
 	}
+	return versions
+}
 
-	return "", fmt.Errorf("no latest version found for %s", packageName)
+// GetMaintainers returns the names of the maintainers of the latest version of a package
+func (c *Client) GetMaintainers(ctx context.Context, packageName string) ([]string, error) {
+	reqURL := fmt.Sprintf("%s/%s/latest", registryURL, url.PathEscape(packageName))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httputil.DoWithRetry(ctx, c.httpClient, req, c.retryConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("package %s not found", packageName)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("npm registry returned %d for %s", resp.StatusCode, packageName)
+	}
+
+	var version struct {
+		Maintainers []struct {
+			Name string `json:"name"`
+		} `json:"maintainers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return nil, err
+	}
+
+	maintainers := make([]string, 0, len(version.Maintainers))
+	for _, m := range version.Maintainers {
+		if m.Name != "" {
+			maintainers = append(maintainers, m.Name)
+		}
+	}
+	return maintainers, nil
+}
+
+// installScripts are the lifecycle scripts npm runs when a package is installed
+var installScripts = []string{"preinstall", "install", "postinstall"}
+
+// GetInstallScripts returns the install scripts a version of a package runs, as "name: command"
+func (c *Client) GetInstallScripts(ctx context.Context, packageName, version string) ([]string, error) {
+	cacheKey := "scripts:" + packageName + "@" + version
+	if scripts, found := c.cache.Get(cacheKey); found {
+		if scripts == "" {
+			return nil, nil
+		}
+		return strings.Split(scripts, "\n"), nil
+	}
+
+	reqURL := fmt.Sprintf("%s/%s/%s", registryURL, url.PathEscape(packageName), url.PathEscape(version))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httputil.DoWithRetry(ctx, c.httpClient, req, c.retryConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("version %s of package %s not found", version, packageName)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("npm registry returned %d for %s@%s", resp.StatusCode, packageName, version)
+	}
+
+	var manifest struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, err
+	}
+
+	var scripts []string
+	for _, name := range installScripts {
+		if command, ok := manifest.Scripts[name]; ok {
+			scripts = append(scripts, name+": "+command)
+		}
+	}
+	c.cache.Set(cacheKey, strings.Join(scripts, "\n"))
+	return scripts, nil
 }
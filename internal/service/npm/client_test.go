@@ -120,3 +120,16 @@ func TestNew(t *testing.T) {
 		t.Error("cache should not be nil")
 	}
 }
+
+func TestGetVersions_Cache(t *testing.T) {
+	client := newTestClient("")
+	client.cache.Set(versionsCacheKey("test-package"), "1.0.0 1.1.0 2.0.0")
+
+	versions, err := client.GetVersions(context.Background(), "test-package")
+	if err != nil {
+		t.Fatalf("GetVersions() error = %v", err)
+	}
+	if len(versions) != 3 || versions[2] != "2.0.0" {
+		t.Errorf("GetVersions() = %v, want the cached versions", versions)
+	}
+}
@@ -0,0 +1,225 @@
+// Package observability reports scan summaries to DataDog or New Relic so dependency staleness
+// can be tracked next to other operational dashboards, and scan failures to Slack or a webhook.
+package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/service/httputil"
+)
+
+const (
+	ProviderDatadog  = "datadog"
+	ProviderNewRelic = "newrelic"
+)
+
+const defaultDatadogSite = "datadoghq.com"
+
+// DatadogSites are the DataDog sites scan summaries can be sent to. The API key is sent to the
+// site, so other hosts are refused.
+var DatadogSites = []string{"datadoghq.com", "us3.datadoghq.com", "us5.datadoghq.com", "datadoghq.eu", "ap1.datadoghq.com", "ap2.datadoghq.com", "ddog-gov.com"}
+
+// NewRelicRegions are the New Relic regions scan summaries can be sent to, us when empty
+var NewRelicRegions = []string{"us", "eu"}
+
+// ValidSite reports whether site is empty or a site of the provider. Without a provider, sites of
+// every provider are accepted.
+func ValidSite(provider, site string) bool {
+	switch {
+	case site == "":
+		return true
+	case provider == ProviderDatadog:
+		return slices.Contains(DatadogSites, site)
+	case provider == ProviderNewRelic:
+		return slices.Contains(NewRelicRegions, strings.ToLower(site))
+	default:
+		return slices.Contains(DatadogSites, site) || slices.Contains(NewRelicRegions, strings.ToLower(site))
+	}
+}
+
+type Service struct {
+	httpClient *http.Client
+	baseURL    string // overrides the provider endpoint (used in tests)
+}
+
+func New() *Service {
+	return &Service{httpClient: httputil.NewClient(10 * time.Second)}
+}
+
+// SendScanSummary posts the scan summary as an event (and, for DataDog, as metrics) to the
+// configured provider. It does nothing when no provider is configured.
+func (s *Service) SendScanSummary(ctx context.Context, settings *domain.Settings, summary domain.ScanSummary) error {
+	switch settings.ObservabilityProvider {
+	case "":
+		return nil
+	case ProviderDatadog:
+		if settings.ObservabilityAPIKey == "" {
+			return errors.New("observability API key not configured")
+		}
+		return s.sendDatadog(ctx, settings, summary)
+	case ProviderNewRelic:
+		if settings.ObservabilityAPIKey == "" {
+			return errors.New("observability API key not configured")
+		}
+		if settings.ObservabilityAccountID == "" {
+			return errors.New("New Relic account ID not configured")
+		}
+		return s.sendNewRelic(ctx, settings, summary)
+	default:
+		return fmt.Errorf("unknown observability provider %q", settings.ObservabilityProvider)
+	}
+}
+
+func (s *Service) sendDatadog(ctx context.Context, settings *domain.Settings, summary domain.ScanSummary) error {
+	site := settings.ObservabilitySite
+	if site == "" {
+		site = defaultDatadogSite
+	}
+	if !ValidSite(ProviderDatadog, site) {
+		return fmt.Errorf("unknown DataDog site %q", site)
+	}
+	baseURL := s.baseURL
+	if baseURL == "" {
+		baseURL = "https://api." + site
+	}
+	headers := map[string]string{"DD-API-KEY": settings.ObservabilityAPIKey}
+	tags := append(parseTags(settings.ObservabilityTags), "status:"+string(summary.Status))
+
+	alertType := "info"
+	if summary.Status == domain.ScanStatusFailed {
+		alertType = "error"
+	}
+	event := map[string]interface{}{
+		"title":           fmt.Sprintf("Stale scan #%d %s", summary.ScanID, summary.Status),
+		"text":            summaryText(summary),
+		"tags":            tags,
+		"alert_type":      alertType,
+		"aggregation_key": "stale-scan",
+		"date_happened":   summary.FinishedAt.Unix(),
+	}
+	if err := s.post(ctx, baseURL+"/api/v1/events", headers, event); err != nil {
+		return fmt.Errorf("datadog event: %w", err)
+	}
+
+	type point struct {
+		Timestamp int64   `json:"timestamp"`
+		Value     float64 `json:"value"`
+	}
+	type serie struct {
+		Metric string   `json:"metric"`
+		Type   int      `json:"type"` // 3 = gauge
+		Points []point  `json:"points"`
+		Tags   []string `json:"tags"`
+	}
+	var series []serie
+	for name, value := range summaryMetrics(summary) {
+		series = append(series, serie{
+			Metric: "stale." + name,
+			Type:   3,
+			Points: []point{{Timestamp: summary.FinishedAt.Unix(), Value: value}},
+			Tags:   tags,
+		})
+	}
+	if err := s.post(ctx, baseURL+"/api/v2/series", headers, map[string]interface{}{"series": series}); err != nil {
+		return fmt.Errorf("datadog metrics: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) sendNewRelic(ctx context.Context, settings *domain.Settings, summary domain.ScanSummary) error {
+	baseURL := s.baseURL
+	if baseURL == "" {
+		baseURL = "https://insights-collector.newrelic.com"
+		if strings.EqualFold(settings.ObservabilitySite, "eu") {
+			baseURL = "https://insights-collector.eu01.nr-data.net"
+		}
+	}
+
+	// Metrics are attributes of the event so they can be charted with NRQL
+	event := map[string]interface{}{
+		"eventType": "StaleScan",
+		"scanId":    summary.ScanID,
+		"status":    string(summary.Status),
+		"timestamp": summary.FinishedAt.Unix(),
+	}
+	for name, value := range summaryMetrics(summary) {
+		event[name] = value
+	}
+	for _, tag := range parseTags(settings.ObservabilityTags) {
+		if key, value, ok := strings.Cut(tag, ":"); ok {
+			event[key] = value
+		}
+	}
+
+	reqURL := fmt.Sprintf("%s/v1/accounts/%s/events", baseURL, settings.ObservabilityAccountID)
+	if err := s.post(ctx, reqURL, map[string]string{"Api-Key": settings.ObservabilityAPIKey}, []interface{}{event}); err != nil {
+		return fmt.Errorf("new relic event: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) post(ctx context.Context, reqURL string, headers map[string]string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// summaryMetrics returns the key metrics of a scan by name
+func summaryMetrics(summary domain.ScanSummary) map[string]float64 {
+	return map[string]float64{
+		"repositories":          float64(summary.Repositories),
+		"dependencies":          float64(summary.Dependencies),
+		"dependencies.outdated": float64(summary.Outdated),
+		"sla_breaches":          float64(summary.SLABreaches),
+		"api_calls":             float64(summary.APICalls),
+		"scan.duration":         summary.DurationSeconds,
+	}
+}
+
+func summaryText(summary domain.ScanSummary) string {
+	return fmt.Sprintf("%d repositories, %d dependencies (%d outdated, %d past their upgrade SLA) in %.0fs using %d API calls",
+		summary.Repositories, summary.Dependencies, summary.Outdated, summary.SLABreaches, summary.DurationSeconds, summary.APICalls)
+}
+
+// parseTags splits a comma-separated tag list, dropping empty entries
+func parseTags(list string) []string {
+	tags := []string{}
+	for _, t := range strings.Split(list, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,157 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+var testSummary = domain.ScanSummary{
+	ScanID:          42,
+	Status:          domain.ScanStatusCompleted,
+	Repositories:    10,
+	Dependencies:    250,
+	Outdated:        31,
+	SLABreaches:     4,
+	APICalls:        120,
+	DurationSeconds: 95,
+	FinishedAt:      time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC),
+}
+
+type request struct {
+	path    string
+	headers http.Header
+	body    json.RawMessage
+}
+
+func newRecordingServer(t *testing.T) (*httptest.Server, func() []request) {
+	var mu sync.Mutex
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid JSON body: %v", err)
+		}
+		mu.Lock()
+		requests = append(requests, request{path: r.URL.Path, headers: r.Header, body: body})
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []request {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestSendScanSummary_Datadog(t *testing.T) {
+	server, requests := newRecordingServer(t)
+	s := &Service{httpClient: server.Client(), baseURL: server.URL}
+	settings := &domain.Settings{ObservabilityProvider: "datadog", ObservabilityAPIKey: "dd-key", ObservabilityTags: "env:prod, team:platform"}
+
+	if err := s.SendScanSummary(context.Background(), settings, testSummary); err != nil {
+		t.Fatalf("SendScanSummary() error = %v", err)
+	}
+
+	got := requests()
+	if len(got) != 2 || got[0].path != "/api/v1/events" || got[1].path != "/api/v2/series" {
+		t.Fatalf("requests = %+v, want event and series", got)
+	}
+	if key := got[0].headers.Get("DD-API-KEY"); key != "dd-key" {
+		t.Errorf("DD-API-KEY = %q", key)
+	}
+
+	var event struct {
+		Title string   `json:"title"`
+		Tags  []string `json:"tags"`
+	}
+	json.Unmarshal(got[0].body, &event)
+	if event.Title != "Stale scan #42 completed" {
+		t.Errorf("event title = %q", event.Title)
+	}
+	if len(event.Tags) != 3 || event.Tags[0] != "env:prod" || event.Tags[2] != "status:completed" {
+		t.Errorf("event tags = %v", event.Tags)
+	}
+
+	var series struct {
+		Series []struct {
+			Metric string `json:"metric"`
+			Points []struct {
+				Value float64 `json:"value"`
+			} `json:"points"`
+		} `json:"series"`
+	}
+	json.Unmarshal(got[1].body, &series)
+	values := make(map[string]float64)
+	for _, s := range series.Series {
+		values[s.Metric] = s.Points[0].Value
+	}
+	if values["stale.dependencies.outdated"] != 31 || values["stale.sla_breaches"] != 4 {
+		t.Errorf("metrics = %v", values)
+	}
+}
+
+func TestSendScanSummary_NewRelic(t *testing.T) {
+	server, requests := newRecordingServer(t)
+	s := &Service{httpClient: server.Client(), baseURL: server.URL}
+	settings := &domain.Settings{ObservabilityProvider: "newrelic", ObservabilityAPIKey: "nr-key", ObservabilityAccountID: "12345", ObservabilityTags: "env:prod"}
+
+	if err := s.SendScanSummary(context.Background(), settings, testSummary); err != nil {
+		t.Fatalf("SendScanSummary() error = %v", err)
+	}
+
+	got := requests()
+	if len(got) != 1 || got[0].path != "/v1/accounts/12345/events" {
+		t.Fatalf("requests = %+v, want a single event", got)
+	}
+	if key := got[0].headers.Get("Api-Key"); key != "nr-key" {
+		t.Errorf("Api-Key = %q", key)
+	}
+
+	var events []map[string]interface{}
+	json.Unmarshal(got[0].body, &events)
+	if len(events) != 1 || events[0]["eventType"] != "StaleScan" || events[0]["dependencies.outdated"] != float64(31) || events[0]["env"] != "prod" {
+		t.Errorf("events = %v", events)
+	}
+}
+
+func TestSendScanSummary_Configuration(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+
+	if err := s.SendScanSummary(ctx, &domain.Settings{}, testSummary); err != nil {
+		t.Errorf("SendScanSummary() without provider error = %v, want nil", err)
+	}
+	if err := s.SendScanSummary(ctx, &domain.Settings{ObservabilityProvider: "datadog"}, testSummary); err == nil {
+		t.Error("expected error without API key")
+	}
+	if err := s.SendScanSummary(ctx, &domain.Settings{ObservabilityProvider: "newrelic", ObservabilityAPIKey: "key"}, testSummary); err == nil {
+		t.Error("expected error without New Relic account ID")
+	}
+	if err := s.SendScanSummary(ctx, &domain.Settings{ObservabilityProvider: "datadog", ObservabilityAPIKey: "key", ObservabilitySite: "attacker.example.com/"}, testSummary); err == nil {
+		t.Error("expected error for a host that is not a DataDog site")
+	}
+	if err := s.SendScanSummary(ctx, &domain.Settings{ObservabilityProvider: "splunk", ObservabilityAPIKey: "key"}, testSummary); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
+
+func TestSendScanSummary_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	s := &Service{httpClient: server.Client(), baseURL: server.URL}
+	settings := &domain.Settings{ObservabilityProvider: "datadog", ObservabilityAPIKey: "bad"}
+	if err := s.SendScanSummary(context.Background(), settings, testSummary); err == nil {
+		t.Error("expected error for rejected request")
+	}
+}
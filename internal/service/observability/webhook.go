@@ -0,0 +1,78 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+// slackWebhookHost is the host of Slack incoming webhooks, which take a message instead of the
+// scan failure event
+const slackWebhookHost = "hooks.slack.com"
+
+// SendScanFailures posts the failed sources of a scan to the failure webhook. Slack incoming
+// webhooks receive a message; other URLs receive a scan_failed event with the failures. It does
+// nothing when no webhook is configured.
+func (s *Service) SendScanFailures(ctx context.Context, settings *domain.Settings, failures []domain.SourceScanFailure) error {
+	if settings.FailureWebhookURL == "" || len(failures) == 0 {
+		return nil
+	}
+
+	u, err := url.Parse(settings.FailureWebhookURL)
+	if err != nil {
+		return fmt.Errorf("invalid failure webhook URL: %w", err)
+	}
+
+	var payload interface{}
+	if strings.EqualFold(u.Hostname(), slackWebhookHost) {
+		payload = map[string]string{"text": failureText(failures)}
+	} else {
+		payload = map[string]interface{}{
+			"event":    "scan_failed",
+			"scan_id":  failures[0].ScanID,
+			"text":     failureText(failures),
+			"failures": failures,
+		}
+	}
+
+	if err := s.post(ctx, settings.FailureWebhookURL, nil, payload); err != nil {
+		return fmt.Errorf("failure webhook: %w", err)
+	}
+	return nil
+}
+
+// CheckWebhook reports whether a webhook URL can be reached, without posting an event. Any HTTP
+// response counts, as webhooks commonly reject requests without a payload.
+func (s *Service) CheckWebhook(ctx context.Context, webhookURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, webhookURL, nil)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// failureText summarizes the failures with one line per source
+func failureText(failures []domain.SourceScanFailure) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Stale scan #%d failed", failures[0].ScanID)
+	for _, f := range failures {
+		name := f.SourceName
+		if name == "" {
+			name = "scan"
+		}
+		fmt.Fprintf(&b, "\n• %s: %s", name, f.Error)
+		if f.Disabled {
+			fmt.Fprintf(&b, " (disabled after %d failed scans in a row)", f.ConsecutiveFailures)
+		}
+	}
+	return b.String()
+}
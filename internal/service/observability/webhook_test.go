@@ -0,0 +1,73 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+var testFailures = []domain.SourceScanFailure{
+	{ScanID: 42, SourceID: 1, SourceName: "github-org", Error: "401 Bad credentials"},
+	{ScanID: 42, SourceID: 2, SourceName: "gitlab", Error: "connection refused", ConsecutiveFailures: 5, Disabled: true},
+}
+
+func TestSendScanFailures_Webhook(t *testing.T) {
+	server, requests := newRecordingServer(t)
+	s := &Service{httpClient: server.Client()}
+	settings := &domain.Settings{FailureWebhookURL: server.URL + "/hooks/stale"}
+
+	if err := s.SendScanFailures(context.Background(), settings, testFailures); err != nil {
+		t.Fatalf("SendScanFailures() error = %v", err)
+	}
+
+	got := requests()
+	if len(got) != 1 || got[0].path != "/hooks/stale" {
+		t.Fatalf("requests = %+v, want a single event", got)
+	}
+	var event struct {
+		Event    string                     `json:"event"`
+		ScanID   int64                      `json:"scan_id"`
+		Failures []domain.SourceScanFailure `json:"failures"`
+	}
+	json.Unmarshal(got[0].body, &event)
+	if event.Event != "scan_failed" || event.ScanID != 42 || len(event.Failures) != 2 || event.Failures[1].SourceName != "gitlab" {
+		t.Errorf("event = %+v", event)
+	}
+}
+
+func TestSendScanFailures_NotConfigured(t *testing.T) {
+	s := New()
+	if err := s.SendScanFailures(context.Background(), &domain.Settings{}, testFailures); err != nil {
+		t.Errorf("SendScanFailures() without webhook error = %v, want nil", err)
+	}
+}
+
+func TestCheckWebhook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	s := &Service{httpClient: server.Client()}
+
+	if err := s.CheckWebhook(context.Background(), server.URL+"/hooks/stale"); err != nil {
+		t.Errorf("CheckWebhook() error = %v, want any response to count as reachable", err)
+	}
+
+	server.Close()
+	if err := s.CheckWebhook(context.Background(), server.URL+"/hooks/stale"); err == nil {
+		t.Error("CheckWebhook() error = nil for a closed server")
+	}
+}
+
+func TestFailureText(t *testing.T) {
+	text := failureText(append(testFailures, domain.SourceScanFailure{ScanID: 42, Error: "database is locked"}))
+	for _, want := range []string{"Stale scan #42 failed", "• github-org: 401 Bad credentials", "• gitlab: connection refused (disabled after 5 failed scans in a row)", "• scan: database is locked"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("failureText() = %q, want it to contain %q", text, want)
+		}
+	}
+}
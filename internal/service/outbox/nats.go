@@ -0,0 +1,207 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsTimeout bounds connecting to the NATS server and waiting for its replies
+const natsTimeout = 10 * time.Second
+
+// natsConn is a connection to a NATS server speaking just enough of the client protocol to
+// publish messages: https://docs.nats.io/reference/reference-protocols/nats-protocol. Once
+// connected, a reader answers the PINGs of the server, which closes connections left unanswered.
+type natsConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	mu sync.Mutex // guards w, written by the publisher and the reader answering PINGs
+	w  *bufio.Writer
+
+	pongs chan struct{}
+	done  chan struct{} // closed once reading failed, after err is set
+	err   error
+}
+
+// natsInfo is the part of the server's INFO greeting the client acts on
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+}
+
+// dialNATS connects to the server of a nats:// or tls://[user:password@ or token@]host[:port]
+// URL. The connection is upgraded to TLS for tls:// URLs and servers requiring it, verifying the
+// server with tlsConfig (nil verifies it against the system roots). Credentials are only sent over
+// TLS. NKey and JWT (credentials file) authentication are not supported.
+func dialNATS(ctx context.Context, rawURL string, tlsConfig *tls.Config) (*natsConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	if (u.Scheme != "nats" && u.Scheme != "tls") || u.Hostname() == "" {
+		return nil, errors.New("NATS URL must look like nats://host:4222 or tls://host:4222")
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	dialer := net.Dialer{Timeout: natsTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	c := &natsConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+
+	// The server greets with INFO, then expects CONNECT
+	conn.SetDeadline(time.Now().Add(natsTimeout))
+	line, err := c.readLine()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	infoJSON, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected NATS greeting %q", line)
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(infoJSON), &info); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("invalid NATS INFO: %w", err)
+	}
+
+	secure := u.Scheme == "tls" || info.TLSRequired
+	if secure {
+		config := &tls.Config{MinVersion: tls.VersionTLS12}
+		if tlsConfig != nil {
+			config = tlsConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("NATS TLS handshake: %w", err)
+		}
+		c.conn, c.r, c.w = tlsConn, bufio.NewReader(tlsConn), bufio.NewWriter(tlsConn)
+	} else if u.User != nil {
+		conn.Close()
+		return nil, errors.New("refusing to send NATS credentials without TLS, use a tls:// URL")
+	}
+
+	// A user without a password is a token, as in the URLs of other NATS clients
+	options := map[string]any{"verbose": false, "pedantic": false, "name": "stale"}
+	if u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			options["user"], options["pass"] = u.User.Username(), password
+		} else {
+			options["auth_token"] = u.User.Username()
+		}
+	}
+	encoded, _ := json.Marshal(options)
+	fmt.Fprintf(c.w, "CONNECT %s\r\n", encoded)
+
+	c.conn.SetDeadline(time.Time{})
+	c.pongs, c.done = make(chan struct{}, 1), make(chan struct{})
+	go c.readLoop()
+	if err := c.flush(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// publish queues a message on subject; flush sends the queued messages
+func (c *natsConn) publish(subject string, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(natsTimeout))
+	if _, err := fmt.Fprintf(c.w, "PUB %s %d\r\n", subject, len(payload)); err != nil {
+		return err
+	}
+	if _, err := c.w.Write(payload); err != nil {
+		return err
+	}
+	_, err := c.w.WriteString("\r\n")
+	return err
+}
+
+// flush sends the queued messages and waits for the server to confirm it processed them
+func (c *natsConn) flush() error {
+	if err := c.send("PING\r\n"); err != nil {
+		return err
+	}
+	timeout := time.NewTimer(natsTimeout)
+	defer timeout.Stop()
+	select {
+	case <-c.pongs:
+		return nil
+	case <-c.done:
+		return c.err
+	case <-timeout.C:
+		return errors.New("timed out waiting for the NATS server")
+	}
+}
+
+// send writes a protocol message along with the queued messages
+func (c *natsConn) send(message string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(natsTimeout))
+	if _, err := c.w.WriteString(message); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
+
+// readLoop reads from the server until the connection fails or is closed, answering its PINGs
+// and passing on its PONGs to flush
+func (c *natsConn) readLoop() {
+	defer close(c.done)
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			c.err = err
+			return
+		}
+		switch {
+		case line == "PONG":
+			select {
+			case c.pongs <- struct{}{}:
+			default:
+			}
+		case line == "PING":
+			if err := c.send("PONG\r\n"); err != nil {
+				c.err = err
+				return
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			// The server closes the connection after reporting an error
+			c.err = fmt.Errorf("NATS server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+			return
+		}
+		// +OK and updated INFO need no reply
+	}
+}
+
+func (c *natsConn) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (c *natsConn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,117 @@
+// Package outbox relays the integration events of the outbox to a message broker
+package outbox
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/jiin/stale/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// cursorName records the last event relayed to NATS
+	cursorName = "nats"
+	// batchSize is the number of events published at once
+	batchSize = 100
+	// pollInterval is how often the outbox is checked for new events
+	pollInterval = 5 * time.Second
+)
+
+// Relay publishes the events of the outbox to NATS subjects named <prefix>.<event type>, e.g.
+// stale.scan.completed. Events are published at least once and in order; consumers can use the
+// event ID to skip duplicates after a relay restarted.
+type Relay struct {
+	repo   *repository.OutboxRepository
+	url    string
+	prefix string
+
+	tlsConfig *tls.Config // verifies TLS servers; nil uses the system roots
+
+	conn *natsConn
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewRelay creates a relay publishing to the NATS server at url once started
+func NewRelay(repo *repository.OutboxRepository, url, prefix string) *Relay {
+	return &Relay{repo: repo, url: url, prefix: prefix, stop: make(chan struct{})}
+}
+
+// Start relays new events every pollInterval until Stop is called
+func (r *Relay) Start() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	defer r.disconnect()
+
+	for {
+		for {
+			relayed, err := r.Relay(context.Background())
+			if err != nil {
+				log.Warn().Err(err).Msg("failed to relay outbox events to NATS")
+				break
+			}
+			if relayed < batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// Stop ends relaying
+func (r *Relay) Stop() {
+	r.once.Do(func() { close(r.stop) })
+}
+
+// Relay publishes the next batch of events not relayed yet and returns how many were published
+func (r *Relay) Relay(ctx context.Context) (int, error) {
+	cursor, err := r.repo.GetCursor(ctx, cursorName)
+	if err != nil {
+		return 0, err
+	}
+	events, err := r.repo.GetSince(ctx, cursor, batchSize)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	if r.conn == nil {
+		if r.conn, err = dialNATS(ctx, r.url, r.tlsConfig); err != nil {
+			return 0, err
+		}
+	}
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return 0, err
+		}
+		if err := r.conn.publish(r.prefix+"."+event.Type, payload); err != nil {
+			r.disconnect()
+			return 0, err
+		}
+	}
+	if err := r.conn.flush(); err != nil {
+		r.disconnect()
+		return 0, err
+	}
+
+	if err := r.repo.SetCursor(ctx, cursorName, events[len(events)-1].ID); err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+func (r *Relay) disconnect() {
+	if r.conn != nil {
+		r.conn.Close()
+		r.conn = nil
+	}
+}
@@ -0,0 +1,283 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/jiin/stale/internal/testutil"
+)
+
+// fakeNATS is a NATS server recording the subjects and payloads published to it. With a TLS
+// config it requires clients to upgrade to TLS after its INFO greeting.
+type fakeNATS struct {
+	listener net.Listener
+	tls      *tls.Config
+
+	mu        sync.Mutex
+	conns     []net.Conn
+	connect   string
+	published []string // subject and payload separated by a space
+	pongs     int
+}
+
+func newFakeNATS(t *testing.T, tlsConfig *tls.Config) *fakeNATS {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	s := &fakeNATS{listener: listener, tls: tlsConfig}
+	go s.serve()
+	return s
+}
+
+func (s *fakeNATS) url(scheme, userinfo string) string {
+	return scheme + "://" + userinfo + s.listener.Addr().String()
+}
+
+// selfSignedTLS returns a server config with a certificate for 127.0.0.1 and a client config
+// trusting it
+func selfSignedTLS(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fake-nats"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}},
+		&tls.Config{RootCAs: roots}
+}
+
+func (s *fakeNATS) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeNATS) handle(conn net.Conn) {
+	defer conn.Close()
+	if s.tls == nil {
+		conn.Write([]byte(`INFO {"server_id":"fake","max_payload":1048576}` + "\r\n"))
+	} else {
+		conn.Write([]byte(`INFO {"server_id":"fake","max_payload":1048576,"tls_required":true}` + "\r\n"))
+		tlsConn := tls.Server(conn, s.tls)
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		conn = tlsConn
+	}
+	s.mu.Lock()
+	s.conns = append(s.conns, conn)
+	s.mu.Unlock()
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "CONNECT "):
+			s.mu.Lock()
+			s.connect = strings.TrimPrefix(line, "CONNECT ")
+			s.mu.Unlock()
+		case line == "PING":
+			conn.Write([]byte("PONG\r\n"))
+		case line == "PONG":
+			s.mu.Lock()
+			s.pongs++
+			s.mu.Unlock()
+		case strings.HasPrefix(line, "PUB "):
+			fields := strings.Fields(line)
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.published = append(s.published, fields[1]+" "+string(payload[:size]))
+			s.mu.Unlock()
+		}
+	}
+}
+
+// ping sends a PING to the connected clients, as servers do to detect stale connections
+func (s *fakeNATS) ping() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Write([]byte("PING\r\n"))
+	}
+}
+
+func (s *fakeNATS) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.published...)
+}
+
+func TestRelay_PublishesEventsOnce(t *testing.T) {
+	serverTLS, clientTLS := selfSignedTLS(t)
+	server := newFakeNATS(t, serverTLS)
+	repo := repository.NewOutboxRepository(testutil.NewDB(t))
+	ctx := context.Background()
+
+	scan, _ := domain.NewOutboxEvent(domain.OutboxScanCompleted, map[string]int{"id": 7})
+	if err := repo.Append(ctx, []domain.OutboxEvent{scan}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	relay := NewRelay(repo, server.url("tls", "stale:secret@"), "stale")
+	relay.tlsConfig = clientTLS
+	defer relay.disconnect()
+	if n, err := relay.Relay(ctx); err != nil || n != 1 {
+		t.Fatalf("Relay() = %d, %v, want 1 event relayed", n, err)
+	}
+	if n, err := relay.Relay(ctx); err != nil || n != 0 {
+		t.Fatalf("Relay() = %d, %v, want nothing left to relay", n, err)
+	}
+
+	messages := server.messages()
+	if len(messages) != 1 || !strings.HasPrefix(messages[0], `stale.scan.completed {"id":1,"type":"scan.completed","data":{"id":7}`) {
+		t.Errorf("published %q, want one scan.completed message", messages)
+	}
+	server.mu.Lock()
+	connect := server.connect
+	server.mu.Unlock()
+	if !strings.Contains(connect, `"user":"stale"`) || !strings.Contains(connect, `"pass":"secret"`) {
+		t.Errorf("CONNECT %s, want the credentials of the URL", connect)
+	}
+	if cursor, _ := repo.GetCursor(ctx, cursorName); cursor != 1 {
+		t.Errorf("cursor = %d, want 1", cursor)
+	}
+}
+
+func TestDialNATS_InvalidURL(t *testing.T) {
+	for _, url := range []string{"http://localhost:4222", "nats://", "::"} {
+		if _, err := dialNATS(context.Background(), url, nil); err == nil {
+			t.Errorf("dialNATS(%q) succeeded, want an error", url)
+		}
+	}
+}
+
+func TestDialNATS_TLS(t *testing.T) {
+	ctx := context.Background()
+	serverTLS, clientTLS := selfSignedTLS(t)
+
+	// Servers requiring TLS are upgraded to it even for nats:// URLs
+	required := newFakeNATS(t, serverTLS)
+	conn, err := dialNATS(ctx, required.url("nats", ""), clientTLS)
+	if err != nil {
+		t.Fatalf("dialNATS() error = %v", err)
+	}
+	conn.Close()
+
+	// The server certificate is verified
+	if _, err := dialNATS(ctx, required.url("tls", ""), nil); err == nil {
+		t.Error("dialNATS() should fail for an untrusted server certificate")
+	}
+
+	// Credentials are never sent in cleartext
+	plain := newFakeNATS(t, nil)
+	if _, err := dialNATS(ctx, plain.url("nats", "stale:secret@"), nil); err == nil {
+		t.Error("dialNATS() should refuse credentials without TLS")
+	}
+	plain.mu.Lock()
+	connect := plain.connect
+	plain.mu.Unlock()
+	if connect != "" {
+		t.Errorf("CONNECT %s was sent without TLS", connect)
+	}
+	conn, err = dialNATS(ctx, plain.url("nats", ""), nil)
+	if err != nil {
+		t.Fatalf("dialNATS() without credentials error = %v", err)
+	}
+	conn.Close()
+}
+
+func TestNATSConn_AnswersServerPings(t *testing.T) {
+	server := newFakeNATS(t, nil)
+	conn, err := dialNATS(context.Background(), server.url("nats", ""), nil)
+	if err != nil {
+		t.Fatalf("dialNATS() error = %v", err)
+	}
+	defer conn.Close()
+
+	// The idle connection answers without anything being published
+	server.ping()
+	answered := func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return server.pongs == 1
+	}
+	for deadline := time.Now().Add(time.Second); !answered() && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !answered() {
+		t.Fatal("server PING was not answered")
+	}
+
+	if err := conn.publish("stale.test", []byte("{}")); err != nil {
+		t.Fatalf("publish() error = %v", err)
+	}
+	if err := conn.flush(); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+	if messages := server.messages(); len(messages) != 1 || messages[0] != "stale.test {}" {
+		t.Errorf("published %q, want the message sent after the PING", messages)
+	}
+}
+
+func TestDialNATS_Token(t *testing.T) {
+	serverTLS, clientTLS := selfSignedTLS(t)
+	server := newFakeNATS(t, serverTLS)
+	conn, err := dialNATS(context.Background(), server.url("tls", "s3cr3t@"), clientTLS)
+	if err != nil {
+		t.Fatalf("dialNATS() error = %v", err)
+	}
+	conn.Close()
+
+	server.mu.Lock()
+	connect := server.connect
+	server.mu.Unlock()
+	if !strings.Contains(connect, `"auth_token":"s3cr3t"`) || strings.Contains(connect, `"user"`) {
+		t.Errorf("CONNECT %s, want the token of the URL", connect)
+	}
+}
@@ -0,0 +1,68 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/service/android"
+)
+
+var (
+	// agpClasspathPattern matches the plugin on a buildscript classpath,
+	// e.g. classpath 'com.android.tools.build:gradle:8.2.0'
+	agpClasspathPattern = regexp.MustCompile(`classpath\s*\(?\s*['"]com\.android\.tools\.build:gradle:([^'"]+)['"]`)
+	// agpPluginPattern matches the plugins block form, e.g. id 'com.android.application' version '8.2.0'
+	agpPluginPattern = regexp.MustCompile(`id\s*\(?\s*['"]com\.android\.(?:application|library|test|dynamic-feature)['"]\s*\)?\s*version\s*\(?\s*['"]([^'"]+)['"]`)
+	// androidSDKPattern matches compileSdk and targetSdk in both their current and Version forms,
+	// e.g. compileSdk = 34, targetSdkVersion 33 or compileSdkVersion "android-34"
+	androidSDKPattern = regexp.MustCompile(`\b(compileSdk|targetSdk)(?:Version)?\s*(?:=\s*)?\(?\s*['"]?(?:android-)?(\d+)\b`)
+)
+
+// androidDependencies returns the Android Gradle Plugin version and the compileSdk and targetSdk
+// API levels set by a Gradle build script, as dependencies of the android ecosystem. A plugin
+// version that is a property reference is returned as skipped; SDK levels that are not literal
+// numbers are left out.
+func androidDependencies(content string) ([]domain.Dependency, []domain.SkippedDependency) {
+	var deps []domain.Dependency
+	var skipped []domain.SkippedDependency
+
+	plugin := agpClasspathPattern.FindStringSubmatch(content)
+	if plugin == nil {
+		plugin = agpPluginPattern.FindStringSubmatch(content)
+	}
+	if plugin != nil {
+		if strings.Contains(plugin[1], "$") {
+			skipped = append(skipped, domain.SkippedDependency{
+				Name:      android.PluginName,
+				Ecosystem: "android",
+				Version:   plugin[1],
+				Reason:    domain.SkipReasonUnresolvedProperty,
+				Detail:    "property " + plugin[1] + " is not resolved",
+			})
+		} else {
+			deps = append(deps, androidDependency(android.PluginName, plugin[1]))
+		}
+	}
+
+	// Build types and flavors may set the levels again; the first one is the default config's
+	seen := make(map[string]bool)
+	for _, m := range androidSDKPattern.FindAllStringSubmatch(content, -1) {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		deps = append(deps, androidDependency(m[1], m[2]))
+	}
+
+	return deps, skipped
+}
+
+func androidDependency(name, version string) domain.Dependency {
+	return domain.Dependency{
+		Name:           name,
+		CurrentVersion: version,
+		Type:           domain.DependencyTypeProd,
+		Ecosystem:      "android",
+	}
+}
@@ -0,0 +1,77 @@
+package scanner
+
+import "testing"
+
+func TestAndroidDependencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		skipped int
+	}{
+		{
+			name: "groovy buildscript",
+			content: `buildscript {
+    dependencies {
+        classpath 'com.android.tools.build:gradle:7.4.2'
+    }
+}
+android {
+    compileSdkVersion 33
+    defaultConfig {
+        targetSdkVersion 31
+    }
+    productFlavors {
+        legacy { targetSdkVersion 28 }
+    }
+}`,
+			want: map[string]string{"com.android.tools.build:gradle": "7.4.2", "compileSdk": "33", "targetSdk": "31"},
+		},
+		{
+			name: "kotlin plugins block",
+			content: `plugins {
+    id("com.android.application") version "8.2.0" apply false
+}
+android {
+    compileSdk = 34
+    compileSdkPreview = "VanillaIceCream"
+    defaultConfig {
+        minSdk = 24
+        targetSdk = libs.versions.targetSdk.get().toInt()
+    }
+}`,
+			want: map[string]string{"com.android.tools.build:gradle": "8.2.0", "compileSdk": "34"},
+		},
+		{
+			name:    "plugin version from a property",
+			content: `classpath "com.android.tools.build:gradle:$agp_version"`,
+			want:    map[string]string{},
+			skipped: 1,
+		},
+		{
+			name:    "not an android build",
+			content: `dependencies { implementation 'com.google.guava:guava:33.0.0-jre' }`,
+			want:    map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, skipped := androidDependencies(tt.content)
+			if len(deps) != len(tt.want) {
+				t.Fatalf("androidDependencies() returned %d dependencies, want %d: %+v", len(deps), len(tt.want), deps)
+			}
+			for _, d := range deps {
+				if d.Ecosystem != "android" {
+					t.Errorf("%s ecosystem = %q, want android", d.Name, d.Ecosystem)
+				}
+				if d.CurrentVersion != tt.want[d.Name] {
+					t.Errorf("%s = %q, want %q", d.Name, d.CurrentVersion, tt.want[d.Name])
+				}
+			}
+			if len(skipped) != tt.skipped {
+				t.Errorf("androidDependencies() skipped %d, want %d", len(skipped), tt.skipped)
+			}
+		})
+	}
+}
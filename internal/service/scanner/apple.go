@@ -0,0 +1,237 @@
+package scanner
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/service/swift"
+	"gopkg.in/yaml.v3"
+)
+
+// lockfiles maps manifests to the lockfile written next to them. When both are found the lockfile
+// is scanned since it has the resolved versions rather than version requirements.
+var lockfiles = map[string]string{
+	"Package.swift": "Package.resolved",
+	"Podfile":       "Podfile.lock",
+}
+
+// preferLockfiles drops the manifests whose lockfile is listed too
+func preferLockfiles(paths []string) []string {
+	listed := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		listed[p] = true
+	}
+
+	result := paths[:0:0]
+	for _, p := range paths {
+		if lockfile, ok := lockfiles[manifestType(p)]; ok && listed[path.Join(path.Dir(p), lockfile)] {
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+var (
+	// swiftPackagePattern matches the arguments of .package(...) declarations, allowing one level
+	// of nested parentheses, e.g. .package(url: "...", .upToNextMajor(from: "1.0.0"))
+	swiftPackagePattern = regexp.MustCompile(`\.package\s*\(((?:[^()]|\([^()]*\))*)\)`)
+	swiftURLPattern     = regexp.MustCompile(`url:\s*"([^"]+)"`)
+	swiftVersionPattern = regexp.MustCompile(`"(\d+\.\d+(?:\.\d+)?[^"]*)"`)
+)
+
+// parsePackageSwift returns the remote packages a Package.swift depends on, with the lower bound
+// of their version requirement. Packages required by branch or revision are returned as skipped;
+// local and registry packages are left out.
+func parsePackageSwift(content string) ([]domain.Dependency, []domain.SkippedDependency) {
+	var deps []domain.Dependency
+	var skipped []domain.SkippedDependency
+
+	for _, m := range swiftPackagePattern.FindAllStringSubmatch(content, -1) {
+		args := m[1]
+		u := swiftURLPattern.FindStringSubmatch(args)
+		if u == nil {
+			continue
+		}
+		name := swift.PackageName(u[1])
+
+		rest := args[strings.Index(args, u[0])+len(u[0]):]
+		v := swiftVersionPattern.FindStringSubmatch(rest)
+		if v == nil || strings.Contains(rest, "branch:") || strings.Contains(rest, "revision:") {
+			skipped = append(skipped, domain.SkippedDependency{
+				Name: name, Ecosystem: "swift", Reason: domain.SkipReasonInvalidVersion, Detail: "package is required by branch or revision",
+			})
+			continue
+		}
+
+		deps = append(deps, domain.Dependency{
+			Name:           name,
+			CurrentVersion: v[1],
+			Type:           domain.DependencyTypeProd,
+			Ecosystem:      "swift",
+		})
+	}
+
+	return deps, skipped
+}
+
+// swiftPin is a package pinned in Package.resolved
+type swiftPin struct {
+	Location      string `json:"location"`      // version 2 and later
+	RepositoryURL string `json:"repositoryURL"` // version 1
+	State         struct {
+		Version  string `json:"version"`
+		Branch   string `json:"branch"`
+		Revision string `json:"revision"`
+	} `json:"state"`
+}
+
+// parsePackageResolved returns the packages pinned in a Package.resolved. Packages pinned to a
+// branch or revision are returned as skipped.
+func parsePackageResolved(content []byte) ([]domain.Dependency, []domain.SkippedDependency, error) {
+	var resolved struct {
+		Pins   []swiftPin `json:"pins"`
+		Object struct {
+			Pins []swiftPin `json:"pins"`
+		} `json:"object"`
+	}
+	if err := json.Unmarshal(content, &resolved); err != nil {
+		return nil, nil, err
+	}
+
+	var deps []domain.Dependency
+	var skipped []domain.SkippedDependency
+	for _, pin := range append(resolved.Pins, resolved.Object.Pins...) {
+		location := pin.Location
+		if location == "" {
+			location = pin.RepositoryURL
+		}
+		name := swift.PackageName(location)
+		if pin.State.Version == "" {
+			detail := "package is pinned to a revision"
+			if pin.State.Branch != "" {
+				detail = "package is pinned to branch " + pin.State.Branch
+			}
+			skipped = append(skipped, domain.SkippedDependency{
+				Name: name, Ecosystem: "swift", Reason: domain.SkipReasonInvalidVersion, Detail: detail,
+			})
+			continue
+		}
+		deps = append(deps, domain.Dependency{
+			Name:           name,
+			CurrentVersion: pin.State.Version,
+			Type:           domain.DependencyTypeProd,
+			Ecosystem:      "swift",
+		})
+	}
+	return deps, skipped, nil
+}
+
+// podPattern matches pod declarations of a Podfile, e.g. pod 'Alamofire', '~> 5.6'
+var podPattern = regexp.MustCompile(`^\s*pod\s+['"]([^'"]+)['"]\s*(?:,\s*['"]([^'"]+)['"])?(.*)$`)
+
+// parsePodfile returns the pods a Podfile depends on, with the lower bound of their version
+// requirement. Pods without a version or taken from git or a local path are returned as skipped.
+// Subspecs (e.g. Firebase/Analytics) count as their pod.
+func parsePodfile(content string) ([]domain.Dependency, []domain.SkippedDependency) {
+	var deps []domain.Dependency
+	var skipped []domain.SkippedDependency
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(content, "\n") {
+		m := podPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name := podName(m[1])
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		version := podVersion(m[2])
+		switch {
+		case strings.Contains(m[3], ":git") || strings.Contains(m[3], ":path") || strings.Contains(m[3], ":podspec"):
+			skipped = append(skipped, domain.SkippedDependency{
+				Name: name, Ecosystem: "cocoapods", Reason: domain.SkipReasonInvalidVersion, Detail: "pod is taken from git or a local path",
+			})
+		case version == "":
+			skipped = append(skipped, domain.SkippedDependency{
+				Name: name, Ecosystem: "cocoapods", Reason: domain.SkipReasonInvalidVersion, Detail: "pod has no version requirement",
+			})
+		default:
+			deps = append(deps, domain.Dependency{
+				Name:           name,
+				CurrentVersion: version,
+				Type:           domain.DependencyTypeProd,
+				Ecosystem:      "cocoapods",
+			})
+		}
+	}
+
+	return deps, skipped
+}
+
+// parsePodfileLock returns the pods a Podfile.lock lists as dependencies of the Podfile, with
+// their installed version. Pods installed only as dependencies of other pods are left out.
+func parsePodfileLock(content []byte) ([]domain.Dependency, error) {
+	var lock struct {
+		Pods         []interface{} `yaml:"PODS"` // "Name (version)", or a map of it to its dependencies
+		Dependencies []string      `yaml:"DEPENDENCIES"`
+	}
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	installed := make(map[string]string)
+	for _, pod := range lock.Pods {
+		entry, ok := pod.(string)
+		if m, isMap := pod.(map[string]interface{}); isMap {
+			for key := range m {
+				entry, ok = key, true
+			}
+		}
+		if !ok {
+			continue
+		}
+		name, version, _ := strings.Cut(entry, " (")
+		name = podName(name)
+		if _, found := installed[name]; !found {
+			installed[name] = strings.TrimSuffix(version, ")")
+		}
+	}
+
+	var deps []domain.Dependency
+	seen := make(map[string]bool)
+	for _, dep := range lock.Dependencies {
+		name, _, _ := strings.Cut(dep, " (")
+		name = podName(name)
+		version, ok := installed[name]
+		if seen[name] || !ok || version == "" {
+			continue
+		}
+		seen[name] = true
+		deps = append(deps, domain.Dependency{
+			Name:           name,
+			CurrentVersion: version,
+			Type:           domain.DependencyTypeProd,
+			Ecosystem:      "cocoapods",
+		})
+	}
+	return deps, nil
+}
+
+// podName returns the pod of a pod or subspec name, e.g. Firebase for Firebase/Analytics
+func podName(name string) string {
+	name, _, _ = strings.Cut(strings.TrimSpace(name), "/")
+	return name
+}
+
+// podVersion returns the version of a pod version requirement without its operator,
+// e.g. 5.6 for ~> 5.6
+func podVersion(requirement string) string {
+	return strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(requirement), "~>=<"))
+}
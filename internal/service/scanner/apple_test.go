@@ -0,0 +1,141 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func versions(deps []domain.Dependency) map[string]string {
+	result := make(map[string]string, len(deps))
+	for _, d := range deps {
+		result[d.Name] = d.CurrentVersion
+	}
+	return result
+}
+
+func TestPreferLockfiles(t *testing.T) {
+	paths := []string{"Package.swift", "Package.resolved", "ios/Podfile", "ios/Podfile.lock", "app/Podfile", "package.json"}
+	want := []string{"Package.resolved", "ios/Podfile.lock", "app/Podfile", "package.json"}
+	if got := preferLockfiles(paths); !reflect.DeepEqual(got, want) {
+		t.Errorf("preferLockfiles() = %v, want %v", got, want)
+	}
+}
+
+func TestParsePackageSwift(t *testing.T) {
+	content := `// swift-tools-version:5.9
+import PackageDescription
+
+let package = Package(
+    name: "App",
+    dependencies: [
+        .package(url: "https://github.com/apple/swift-nio.git", from: "2.62.0"),
+        .package(url: "https://github.com/apple/swift-log", .upToNextMajor(from: "1.5.3")),
+        .package(url: "https://github.com/vapor/vapor.git", exact: "4.89.0"),
+        .package(url: "https://github.com/example/edge.git", branch: "main"),
+        .package(path: "../LocalKit"),
+    ]
+)`
+
+	deps, skipped := parsePackageSwift(content)
+	want := map[string]string{
+		"github.com/apple/swift-nio": "2.62.0",
+		"github.com/apple/swift-log": "1.5.3",
+		"github.com/vapor/vapor":     "4.89.0",
+	}
+	if got := versions(deps); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePackageSwift() = %v, want %v", got, want)
+	}
+	if len(skipped) != 1 || skipped[0].Name != "github.com/example/edge" || skipped[0].Ecosystem != "swift" {
+		t.Errorf("parsePackageSwift() skipped = %+v, want github.com/example/edge", skipped)
+	}
+}
+
+func TestParsePackageResolved(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "version 2",
+			content: `{"pins":[
+				{"identity":"swift-nio","kind":"remoteSourceControl","location":"https://github.com/apple/swift-nio.git","state":{"revision":"abc","version":"2.62.0"}},
+				{"identity":"edge","kind":"remoteSourceControl","location":"https://github.com/example/edge.git","state":{"branch":"main","revision":"def"}}
+			],"version":2}`,
+		},
+		{
+			name: "version 1",
+			content: `{"object":{"pins":[
+				{"package":"NIO","repositoryURL":"https://github.com/apple/swift-nio.git","state":{"branch":null,"revision":"abc","version":"2.62.0"}},
+				{"package":"Edge","repositoryURL":"https://github.com/example/edge.git","state":{"branch":"main","revision":"def","version":null}}
+			]},"version":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, skipped, err := parsePackageResolved([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("parsePackageResolved() error = %v", err)
+			}
+			if got := versions(deps); !reflect.DeepEqual(got, map[string]string{"github.com/apple/swift-nio": "2.62.0"}) {
+				t.Errorf("parsePackageResolved() = %v", got)
+			}
+			if len(skipped) != 1 || skipped[0].Detail != "package is pinned to branch main" {
+				t.Errorf("parsePackageResolved() skipped = %+v", skipped)
+			}
+		})
+	}
+}
+
+func TestParsePodfile(t *testing.T) {
+	content := `platform :ios, '15.0'
+
+target 'App' do
+  use_frameworks!
+  pod 'Alamofire', '~> 5.6'
+  pod 'Firebase/Analytics', '>= 10.0.0'
+  pod 'Firebase/Crashlytics'
+  pod 'SnapKit'
+  pod 'Internal', :git => 'https://github.com/example/internal.git'
+end`
+
+	deps, skipped := parsePodfile(content)
+	want := map[string]string{"Alamofire": "5.6", "Firebase": "10.0.0"}
+	if got := versions(deps); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePodfile() = %v, want %v", got, want)
+	}
+	skippedNames := map[string]bool{}
+	for _, sd := range skipped {
+		skippedNames[sd.Name] = true
+	}
+	if len(skipped) != 2 || !skippedNames["SnapKit"] || !skippedNames["Internal"] {
+		t.Errorf("parsePodfile() skipped = %+v, want SnapKit and Internal", skipped)
+	}
+}
+
+func TestParsePodfileLock(t *testing.T) {
+	content := `PODS:
+  - Alamofire (5.8.1)
+  - Firebase/Analytics (10.18.0):
+    - Firebase/Core
+  - Firebase/Core (10.18.0):
+    - FirebaseCore (= 10.18.0)
+  - FirebaseCore (10.18.0)
+
+DEPENDENCIES:
+  - Alamofire (~> 5.6)
+  - Firebase/Analytics (>= 10.0.0)
+
+COCOAPODS: 1.14.3
+`
+	deps, err := parsePodfileLock([]byte(content))
+	if err != nil {
+		t.Fatalf("parsePodfileLock() error = %v", err)
+	}
+	want := map[string]string{"Alamofire": "5.8.1", "Firebase": "10.18.0"}
+	if got := versions(deps); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePodfileLock() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,130 @@
+package scanner
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxArchiveManifestSize is the size above which manifests in an archive are skipped
+const maxArchiveManifestSize = 10 << 20
+
+// manifestArchive serves the manifests of a repository from its tarball instead of the
+// contents API: the archive of a repository and ref is downloaded once and its manifests are
+// extracted in memory. Only the most recent archive is kept since repositories are scanned
+// one after another. Other files (e.g. CODEOWNERS) are still fetched through the API.
+type manifestArchive struct {
+	download func(ctx context.Context, repoPath, ref string) (io.ReadCloser, error)
+
+	mu    sync.Mutex
+	key   string // repository and ref of the extracted manifests
+	files map[string][]byte
+	err   error // why the archive of key could not be loaded; files are then fetched with the API
+}
+
+// ListManifestFiles returns the manifest paths found in the archive. When the archive cannot be
+// downloaded the listing falls back to api, so an oversized or unavailable archive does not fail the scan.
+// Listing starts the scan of a repository, so an earlier failed download is retried.
+func (m *manifestArchive) ListManifestFiles(ctx context.Context, repoPath, ref string, api func() ([]string, error)) ([]string, error) {
+	files, err := m.load(ctx, repoPath, ref, true)
+	if err != nil {
+		log.Warn().Err(err).Str("repo", repoPath).Str("ref", ref).Msg("failed to fetch repository archive, falling back to API")
+		return api()
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// GetFileContent returns a manifest from the archive; other files are fetched with api
+func (m *manifestArchive) GetFileContent(ctx context.Context, repoPath, filePath, ref string, api func() ([]byte, error)) ([]byte, error) {
+	if !manifestKinds[manifestType(filePath)] {
+		return api()
+	}
+	files, err := m.load(ctx, repoPath, ref, false)
+	if err != nil {
+		return api()
+	}
+	content, ok := files[filePath]
+	if !ok {
+		return nil, fmt.Errorf("file not found: %s", filePath)
+	}
+	return content, nil
+}
+
+func (m *manifestArchive) load(ctx context.Context, repoPath, ref string, retryFailed bool) (map[string][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := repoPath + "@" + ref
+	if m.key == key && (m.files != nil || (m.err != nil && !retryFailed)) {
+		return m.files, m.err
+	}
+
+	// A failed download is remembered, so the following files of the repository fall back to the
+	// API at once instead of downloading the whole archive again
+	files, err := m.fetch(ctx, repoPath, ref)
+	m.key, m.files, m.err = key, files, err
+	return files, err
+}
+
+func (m *manifestArchive) fetch(ctx context.Context, repoPath, ref string) (map[string][]byte, error) {
+	body, err := m.download(ctx, repoPath, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	files, err := extractManifests(body)
+	if err != nil {
+		return nil, fmt.Errorf("read archive of %s: %w", repoPath, err)
+	}
+	return files, nil
+}
+
+// extractManifests reads the manifests from a gzipped tarball, keyed by their path within the
+// repository. The top-level directory that providers wrap the repository in is stripped.
+func extractManifests(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > maxArchiveManifestSize {
+			continue
+		}
+
+		_, path, ok := strings.Cut(hdr.Name, "/")
+		if !ok || !manifestKinds[manifestType(path)] {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = content
+	}
+	return files, nil
+}
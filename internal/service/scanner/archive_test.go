@@ -0,0 +1,125 @@
+package scanner
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// tarball builds a gzipped tarball with the files wrapped in a top-level directory, like provider archives
+func tarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "owner-repo-abc123/", Typeflag: tar.TypeDir, Mode: 0755})
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: "owner-repo-abc123/" + name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestExtractManifests(t *testing.T) {
+	data := tarball(t, map[string]string{
+		"package.json":          `{"name":"root"}`,
+		"services/api/go.mod":   "module api",
+		"services/api/main.go":  "package main",
+		"README.md":             "# repo",
+		"web/build.gradle.kts":  "plugins {}",
+		"docs/package.json.bak": "{}",
+	})
+
+	files, err := extractManifests(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("extractManifests() error = %v", err)
+	}
+
+	want := map[string][]byte{
+		"package.json":         []byte(`{"name":"root"}`),
+		"services/api/go.mod":  []byte("module api"),
+		"web/build.gradle.kts": []byte("plugins {}"),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("extractManifests() = %v, want %v", files, want)
+	}
+}
+
+func TestManifestArchive(t *testing.T) {
+	data := tarball(t, map[string]string{"package.json": "{}", "api/pom.xml": "<project/>"})
+	downloads := 0
+	archive := &manifestArchive{download: func(ctx context.Context, repoPath, ref string) (io.ReadCloser, error) {
+		downloads++
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}}
+	ctx := context.Background()
+	noAPI := func() ([]string, error) { t.Error("unexpected API listing"); return nil, nil }
+
+	paths, err := archive.ListManifestFiles(ctx, "owner/repo", "main", noAPI)
+	if err != nil || !reflect.DeepEqual(paths, []string{"api/pom.xml", "package.json"}) {
+		t.Fatalf("ListManifestFiles() = %v, %v", paths, err)
+	}
+
+	content, err := archive.GetFileContent(ctx, "owner/repo", "api/pom.xml", "main", nil)
+	if err != nil || string(content) != "<project/>" {
+		t.Errorf("GetFileContent() = %q, %v", content, err)
+	}
+	if downloads != 1 {
+		t.Errorf("downloads = %d, want 1", downloads)
+	}
+
+	// Files other than manifests are fetched through the API
+	content, _ = archive.GetFileContent(ctx, "owner/repo", "CODEOWNERS", "main", func() ([]byte, error) { return []byte("* @team"), nil })
+	if string(content) != "* @team" {
+		t.Errorf("GetFileContent(CODEOWNERS) = %q, want API content", content)
+	}
+
+	// Another ref downloads its own archive
+	archive.ListManifestFiles(ctx, "owner/repo", "release", noAPI)
+	if downloads != 2 {
+		t.Errorf("downloads = %d, want 2", downloads)
+	}
+}
+
+func TestManifestArchive_FallsBackToAPI(t *testing.T) {
+	downloads := 0
+	archive := &manifestArchive{download: func(ctx context.Context, repoPath, ref string) (io.ReadCloser, error) {
+		downloads++
+		return nil, errors.New("archive too large")
+	}}
+	ctx := context.Background()
+
+	paths, err := archive.ListManifestFiles(ctx, "owner/repo", "main", func() ([]string, error) {
+		return []string{"go.mod", "package.json"}, nil
+	})
+	if err != nil || !reflect.DeepEqual(paths, []string{"go.mod", "package.json"}) {
+		t.Errorf("ListManifestFiles() = %v, %v, want API listing", paths, err)
+	}
+
+	// The failure is remembered: the manifests are fetched with the API without downloading again
+	for _, path := range paths {
+		content, err := archive.GetFileContent(ctx, "owner/repo", path, "main", func() ([]byte, error) { return []byte("api"), nil })
+		if err != nil || string(content) != "api" {
+			t.Errorf("GetFileContent(%s) = %q, %v, want API content", path, content, err)
+		}
+	}
+	if downloads != 1 {
+		t.Errorf("archive downloaded %d times, want once", downloads)
+	}
+
+	// The next scan of the repository, or another repository, tries the archive again
+	archive.ListManifestFiles(ctx, "owner/repo", "main", func() ([]string, error) { return nil, nil })
+	archive.ListManifestFiles(ctx, "owner/other", "main", func() ([]string, error) { return nil, nil })
+	if downloads != 3 {
+		t.Errorf("archive downloaded %d times, want once per listing", downloads)
+	}
+}
@@ -0,0 +1,53 @@
+package scanner
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// registryEcosystems are the ecosystems whose registry lookups are cached. Gradle dependencies
+// are looked up in Maven Central and share the maven cache.
+var registryEcosystems = []string{"npm", "maven", "go", "swift", "cocoapods", "android"}
+
+// ParseCacheTTLOverrides parses comma-separated per-ecosystem registry cache lifetimes in
+// seconds, e.g. maven=7200,npm=300
+func ParseCacheTTLOverrides(value string) (map[string]time.Duration, error) {
+	overrides := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		ecosystem, seconds, ok := strings.Cut(entry, "=")
+		ecosystem = strings.ToLower(strings.TrimSpace(ecosystem))
+		if !ok || !slices.Contains(registryEcosystems, ecosystem) {
+			return nil, fmt.Errorf("%q is not ecosystem=seconds with an ecosystem of %s", entry, strings.Join(registryEcosystems, ", "))
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(seconds))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%q must give a number of seconds", entry)
+		}
+		overrides[ecosystem] = time.Duration(n) * time.Second
+	}
+	return overrides, nil
+}
+
+// SetRegistryCacheTTLs changes how long latest versions looked up in registries are cached, ttl
+// unless overridden for the ecosystem. Cached versions older than the new lifetime expire.
+func (s *Scanner) SetRegistryCacheTTLs(ttl time.Duration, overrides map[string]time.Duration) {
+	ttlOf := func(ecosystem string) time.Duration {
+		if t, ok := overrides[ecosystem]; ok {
+			return t
+		}
+		return ttl
+	}
+	s.npmClient.SetCacheTTL(ttlOf("npm"))
+	s.mavenClient.SetCacheTTL(ttlOf("maven"))
+	s.goClient.SetCacheTTL(ttlOf("go"))
+	s.swiftClient.SetCacheTTL(ttlOf("swift"))
+	s.podsClient.SetCacheTTL(ttlOf("cocoapods"))
+	s.sdkClient.SetCacheTTL(ttlOf("android"))
+}
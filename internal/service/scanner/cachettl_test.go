@@ -0,0 +1,22 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCacheTTLOverrides(t *testing.T) {
+	got, err := ParseCacheTTLOverrides(" Maven=7200, npm=0 ,")
+	if err != nil {
+		t.Fatalf("ParseCacheTTLOverrides() error = %v", err)
+	}
+	if len(got) != 2 || got["maven"] != 2*time.Hour || got["npm"] != 0 {
+		t.Errorf("ParseCacheTTLOverrides() = %v", got)
+	}
+
+	for _, value := range []string{"pypi=60", "maven", "npm=-1", "go=soon"} {
+		if _, err := ParseCacheTTLOverrides(value); err == nil {
+			t.Errorf("ParseCacheTTLOverrides(%q) error = nil", value)
+		}
+	}
+}
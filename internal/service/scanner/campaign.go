@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+// CampaignProgress derives the status of each repository using the campaign package from its
+// tracked dependencies. A repository is completed when every declaration of the package is at or
+// beyond the target version, pending when any declaration still needs an upgrade, and unknown
+// when its versions cannot be compared.
+func CampaignProgress(campaign domain.Campaign, deps []domain.DependencyWithRepo) *domain.CampaignProgress {
+	progress := &domain.CampaignProgress{Campaign: campaign, Repositories: []domain.CampaignRepository{}}
+
+	byRepo := make(map[int64]*domain.CampaignRepository)
+	for _, dep := range deps {
+		repo, ok := byRepo[dep.RepositoryID]
+		if !ok {
+			repo = &domain.CampaignRepository{RepositoryID: dep.RepositoryID, RepoFullName: dep.RepoFullName, Status: "completed"}
+			byRepo[dep.RepositoryID] = repo
+		}
+		if !slices.Contains(repo.Versions, dep.CurrentVersion) {
+			repo.Versions = append(repo.Versions, dep.CurrentVersion)
+		}
+
+		switch UpgradeJump(dep.CurrentVersion, campaign.TargetVersion) {
+		case "none":
+		case "unknown":
+			if repo.Status == "completed" {
+				repo.Status = "unknown"
+			}
+		default:
+			repo.Status = "pending"
+		}
+	}
+
+	for _, repo := range byRepo {
+		switch repo.Status {
+		case "completed":
+			progress.Completed++
+		case "pending":
+			progress.Pending++
+		default:
+			progress.Unknown++
+		}
+		progress.Repositories = append(progress.Repositories, *repo)
+	}
+	sort.Slice(progress.Repositories, func(i, j int) bool {
+		return progress.Repositories[i].RepoFullName < progress.Repositories[j].RepoFullName
+	})
+
+	progress.TotalRepositories = len(byRepo)
+	if progress.TotalRepositories > 0 {
+		percent := float64(progress.Completed) / float64(progress.TotalRepositories) * 100
+		progress.ProgressPercent = math.Round(percent*10) / 10
+	}
+	return progress
+}
@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestCampaignProgress(t *testing.T) {
+	campaign := domain.Campaign{ID: 1, Name: "lodash 4.17.21", Ecosystem: "npm", PackageName: "lodash", TargetVersion: "4.17.21"}
+	usage := func(repoID int64, repo, version string) domain.DependencyWithRepo {
+		return domain.DependencyWithRepo{
+			Dependency:   domain.Dependency{RepositoryID: repoID, Name: "lodash", Ecosystem: "npm", CurrentVersion: version},
+			RepoFullName: repo,
+		}
+	}
+
+	progress := CampaignProgress(campaign, []domain.DependencyWithRepo{
+		usage(1, "org/web", "^4.17.21"),
+		usage(1, "org/web", "^4.17.15"), // second manifest still behind
+		usage(2, "org/api", "4.17.21"),
+		usage(3, "org/cli", "^4.17.21"),
+		usage(4, "org/legacy", "latest"),
+	})
+
+	if progress.TotalRepositories != 4 || progress.Completed != 2 || progress.Pending != 1 || progress.Unknown != 1 {
+		t.Errorf("counts = total %d, completed %d, pending %d, unknown %d",
+			progress.TotalRepositories, progress.Completed, progress.Pending, progress.Unknown)
+	}
+	if progress.ProgressPercent != 50 {
+		t.Errorf("ProgressPercent = %v, want 50", progress.ProgressPercent)
+	}
+
+	if len(progress.Repositories) != 4 || progress.Repositories[3].RepoFullName != "org/web" {
+		t.Fatalf("Repositories = %+v", progress.Repositories)
+	}
+	web := progress.Repositories[3]
+	if web.Status != "pending" || len(web.Versions) != 2 {
+		t.Errorf("org/web = %+v, want pending with 2 versions", web)
+	}
+}
+
+func TestCampaignProgress_NoUsages(t *testing.T) {
+	progress := CampaignProgress(domain.Campaign{TargetVersion: "1.0.0"}, nil)
+	if progress.TotalRepositories != 0 || progress.ProgressPercent != 0 || progress.Repositories == nil {
+		t.Errorf("unexpected progress %+v", progress)
+	}
+}
@@ -0,0 +1,77 @@
+package scanner
+
+import (
+	"context"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// Collect lists the repositories of a source and fetches, parses and resolves the dependencies of
+// each without persisting anything. report is called with every repository that has manifests,
+// leaving the source of the list to the caller; its error stops the collection. Agents use it to
+// scan on behalf of a central server.
+func (s *Scanner) Collect(ctx context.Context, source domain.Source, report func(domain.IngestRequest) error) error {
+	provider := s.provider(source)
+	repos, err := provider.ListRepositories(ctx)
+	if err != nil {
+		return err
+	}
+	if source.Repositories != "" {
+		repos = filterRepositories(repos, source.Repositories)
+	}
+	log.Info().Int("total_repos", len(repos)).Str("source", source.Name).Msg("fetched repositories from source")
+
+	for _, repo := range repos {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		manifests, branch, err := s.fetchFirstManifests(ctx, provider, repo.FullName, scanBranches(source, repo.DefaultBranch, ""))
+		if err != nil {
+			log.Error().Err(err).Str("repo", repo.FullName).Msg("failed to fetch manifests")
+			continue
+		}
+		if len(manifests) == 0 {
+			log.Info().Str("repo", repo.FullName).Msg("no valid manifest content found")
+			continue
+		}
+
+		var deps []domain.Dependency
+		for _, manifest := range manifests {
+			parsed, _, err := parseManifest(manifest)
+			if err != nil {
+				log.Warn().Err(err).Str("repo", repo.FullName).Str("path", manifest.path).Msg("failed to parse manifest")
+				continue
+			}
+			deps = append(deps, parsed...)
+		}
+		if len(deps) == 0 {
+			continue
+		}
+		_ = s.resolveLatestVersions(ctx, deps)
+
+		req := domain.IngestRequest{
+			Repository:   repo.FullName,
+			HTMLURL:      repo.HTMLURL,
+			Branch:       branch,
+			Dependencies: make([]domain.IngestDependency, len(deps)),
+		}
+		for i, dep := range deps {
+			req.Dependencies[i] = domain.IngestDependency{
+				Name:          dep.Name,
+				Version:       dep.CurrentVersion,
+				LatestVersion: dep.LatestVersion,
+				Ecosystem:     dep.Ecosystem,
+				Type:          dep.Type,
+				Scope:         dep.Scope,
+				ManifestPath:  dep.ManifestPath,
+				ManifestType:  dep.ManifestType,
+			}
+		}
+		if err := report(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
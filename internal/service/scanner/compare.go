@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"sort"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+// CompareDependencies computes the dependency diff between the dependency sets of two refs.
+// Dependencies are matched by manifest path, ecosystem, name and type.
+func CompareDependencies(baseRef, headRef string, base, head []domain.Dependency) *domain.DependencyDiff {
+	diff := &domain.DependencyDiff{
+		Base:    baseRef,
+		Head:    headRef,
+		Added:   []domain.DependencyChange{},
+		Removed: []domain.DependencyChange{},
+		Changed: []domain.DependencyChange{},
+	}
+
+	baseByKey := make(map[string]domain.Dependency, len(base))
+	for _, dep := range base {
+		baseByKey[dependencyKey(dep)] = dep
+	}
+
+	seen := make(map[string]bool, len(head))
+	for _, dep := range head {
+		key := dependencyKey(dep)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		baseDep, ok := baseByKey[key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, dependencyChange(dep, "", dep.CurrentVersion))
+		case baseDep.CurrentVersion != dep.CurrentVersion:
+			diff.Changed = append(diff.Changed, dependencyChange(dep, baseDep.CurrentVersion, dep.CurrentVersion))
+		default:
+			diff.Unchanged++
+		}
+	}
+
+	for key, dep := range baseByKey {
+		if !seen[key] {
+			diff.Removed = append(diff.Removed, dependencyChange(dep, dep.CurrentVersion, ""))
+		}
+	}
+
+	sortChanges(diff.Added)
+	sortChanges(diff.Removed)
+	sortChanges(diff.Changed)
+
+	return diff
+}
+
+func dependencyKey(dep domain.Dependency) string {
+	return dep.ManifestPath + "|" + dep.Ecosystem + "|" + dep.Name + "|" + dep.Type
+}
+
+func dependencyChange(dep domain.Dependency, baseVersion, headVersion string) domain.DependencyChange {
+	return domain.DependencyChange{
+		Name:         dep.Name,
+		Type:         dep.Type,
+		Ecosystem:    dep.Ecosystem,
+		ManifestPath: dep.ManifestPath,
+		BaseVersion:  baseVersion,
+		HeadVersion:  headVersion,
+	}
+}
+
+func sortChanges(changes []domain.DependencyChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].ManifestPath != changes[j].ManifestPath {
+			return changes[i].ManifestPath < changes[j].ManifestPath
+		}
+		return changes[i].Name < changes[j].Name
+	})
+}
@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestCompareDependencies(t *testing.T) {
+	base := []domain.Dependency{
+		{Name: "react", CurrentVersion: "18.2.0", Type: "dependency", Ecosystem: "npm", ManifestPath: "package.json"},
+		{Name: "lodash", CurrentVersion: "4.17.21", Type: "dependency", Ecosystem: "npm", ManifestPath: "package.json"},
+		{Name: "moment", CurrentVersion: "2.29.4", Type: "dependency", Ecosystem: "npm", ManifestPath: "package.json"},
+		{Name: "github.com/pkg/errors", CurrentVersion: "v0.9.1", Type: "dependency", Ecosystem: "go", ManifestPath: "go.mod"},
+	}
+	head := []domain.Dependency{
+		{Name: "react", CurrentVersion: "18.3.1", Type: "dependency", Ecosystem: "npm", ManifestPath: "package.json"},
+		{Name: "lodash", CurrentVersion: "4.17.21", Type: "dependency", Ecosystem: "npm", ManifestPath: "package.json"},
+		{Name: "dayjs", CurrentVersion: "1.11.10", Type: "dependency", Ecosystem: "npm", ManifestPath: "package.json"},
+		{Name: "github.com/pkg/errors", CurrentVersion: "v0.9.1", Type: "dependency", Ecosystem: "go", ManifestPath: "go.mod"},
+		// Same package in another manifest is a separate dependency
+		{Name: "lodash", CurrentVersion: "4.17.21", Type: "dependency", Ecosystem: "npm", ManifestPath: "web/package.json"},
+	}
+
+	diff := CompareDependencies("main", "release/1.x", base, head)
+
+	if diff.Base != "main" || diff.Head != "release/1.x" {
+		t.Errorf("refs = %q..%q, want main..release/1.x", diff.Base, diff.Head)
+	}
+	if diff.Unchanged != 2 {
+		t.Errorf("Unchanged = %d, want 2", diff.Unchanged)
+	}
+
+	if len(diff.Added) != 2 || diff.Added[0].Name != "dayjs" || diff.Added[1].ManifestPath != "web/package.json" {
+		t.Fatalf("Added = %+v, want dayjs and web/package.json lodash", diff.Added)
+	}
+	if diff.Added[0].BaseVersion != "" || diff.Added[0].HeadVersion != "1.11.10" {
+		t.Errorf("Added[0] versions = %q -> %q", diff.Added[0].BaseVersion, diff.Added[0].HeadVersion)
+	}
+
+	if len(diff.Removed) != 1 || diff.Removed[0].Name != "moment" || diff.Removed[0].BaseVersion != "2.29.4" {
+		t.Errorf("Removed = %+v, want moment 2.29.4", diff.Removed)
+	}
+
+	if len(diff.Changed) != 1 {
+		t.Fatalf("Changed = %+v, want 1 change", diff.Changed)
+	}
+	if c := diff.Changed[0]; c.Name != "react" || c.BaseVersion != "18.2.0" || c.HeadVersion != "18.3.1" {
+		t.Errorf("Changed[0] = %+v, want react 18.2.0 -> 18.3.1", c)
+	}
+}
+
+func TestCompareDependencies_Empty(t *testing.T) {
+	diff := CompareDependencies("a", "b", nil, nil)
+	if diff.Added == nil || diff.Removed == nil || diff.Changed == nil {
+		t.Error("expected non-nil slices for JSON encoding")
+	}
+}
@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"math"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jiin/stale/internal/domain"
+)
+
+// Convergence reports the spread of versions of each package used by at least minRepos
+// repositories, least converged first
+func Convergence(deps []domain.DependencyWithRepo, minRepos int) []domain.PackageConvergence {
+	type packageKey struct{ ecosystem, name string }
+	type versionUsages struct {
+		usages int
+		repos  map[int64]bool
+	}
+
+	var keys []packageKey
+	packages := make(map[packageKey]map[string]*versionUsages)
+	for _, dep := range deps {
+		key := packageKey{dep.Ecosystem, dep.Name}
+		versions, ok := packages[key]
+		if !ok {
+			versions = make(map[string]*versionUsages)
+			packages[key] = versions
+			keys = append(keys, key)
+		}
+		version := cleanVersion(dep.CurrentVersion)
+		if versions[version] == nil {
+			versions[version] = &versionUsages{repos: make(map[int64]bool)}
+		}
+		versions[version].usages++
+		versions[version].repos[dep.RepositoryID] = true
+	}
+
+	report := []domain.PackageConvergence{}
+	for _, key := range keys {
+		repos := make(map[int64]bool)
+		pc := domain.PackageConvergence{Ecosystem: key.ecosystem, Name: key.name}
+		for version, vu := range packages[key] {
+			for id := range vu.repos {
+				repos[id] = true
+			}
+			pc.Usages += vu.usages
+			pc.Versions = append(pc.Versions, domain.VersionUsage{Version: version, Usages: vu.usages, Repositories: len(vu.repos)})
+		}
+		if len(repos) < minRepos {
+			continue
+		}
+		pc.Repositories = len(repos)
+		pc.DistinctVersions = len(pc.Versions)
+		sortVersionsNewestFirst(pc.Versions)
+
+		mode := pc.Versions[0]
+		for _, vu := range pc.Versions[1:] {
+			if vu.Usages > mode.Usages {
+				mode = vu
+			}
+		}
+		pc.ModeVersion = mode.Version
+		pc.Score = math.Round(float64(mode.Usages)/float64(pc.Usages)*1000) / 1000
+
+		for _, vu := range pc.Versions {
+			if _, err := semver.NewVersion(vu.Version); err != nil {
+				continue
+			}
+			if pc.MaxVersion == "" {
+				pc.MaxVersion = vu.Version
+			}
+			pc.MinVersion = vu.Version
+		}
+		report = append(report, pc)
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.Score != b.Score {
+			return a.Score < b.Score
+		}
+		if a.Repositories != b.Repositories {
+			return a.Repositories > b.Repositories
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Ecosystem < b.Ecosystem
+	})
+	return report
+}
+
+// sortVersionsNewestFirst orders version usages by descending version, with versions that cannot
+// be parsed last in alphabetical order
+func sortVersionsNewestFirst(versions []domain.VersionUsage) {
+	sort.Slice(versions, func(i, j int) bool {
+		a, errA := semver.NewVersion(versions[i].Version)
+		b, errB := semver.NewVersion(versions[j].Version)
+		switch {
+		case errA == nil && errB == nil:
+			if !a.Equal(b) {
+				return a.GreaterThan(b)
+			}
+		case errA == nil:
+			return true
+		case errB == nil:
+			return false
+		}
+		return versions[i].Version < versions[j].Version
+	})
+}
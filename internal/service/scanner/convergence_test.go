@@ -0,0 +1,53 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestConvergence(t *testing.T) {
+	usage := func(repoID int64, name, version string) domain.DependencyWithRepo {
+		return domain.DependencyWithRepo{Dependency: domain.Dependency{RepositoryID: repoID, Name: name, Ecosystem: "npm", CurrentVersion: version}}
+	}
+
+	report := Convergence([]domain.DependencyWithRepo{
+		usage(1, "react", "^18.2.0"),
+		usage(2, "react", "18.2.0"),
+		usage(3, "react", "17.0.2"),
+		usage(4, "react", "latest"),
+		usage(1, "lodash", "4.17.21"),
+		usage(2, "lodash", "4.17.21"),
+		usage(1, "left-pad", "1.3.0"), // used by one repository only
+	}, 2)
+
+	if len(report) != 2 {
+		t.Fatalf("len(report) = %d, want 2: %+v", len(report), report)
+	}
+
+	react := report[0]
+	if react.Name != "react" || react.Repositories != 4 || react.Usages != 4 || react.DistinctVersions != 3 {
+		t.Errorf("react = %+v", react)
+	}
+	if react.MinVersion != "17.0.2" || react.MaxVersion != "18.2.0" || react.ModeVersion != "18.2.0" || react.Score != 0.5 {
+		t.Errorf("react spread = min %q max %q mode %q score %v", react.MinVersion, react.MaxVersion, react.ModeVersion, react.Score)
+	}
+	if react.Versions[0].Version != "18.2.0" || react.Versions[0].Repositories != 2 || react.Versions[2].Version != "latest" {
+		t.Errorf("react versions = %+v", react.Versions)
+	}
+
+	if lodash := report[1]; lodash.Name != "lodash" || lodash.Score != 1 || lodash.DistinctVersions != 1 {
+		t.Errorf("lodash = %+v", lodash)
+	}
+}
+
+func TestConvergence_ModeTiePrefersNewest(t *testing.T) {
+	report := Convergence([]domain.DependencyWithRepo{
+		{Dependency: domain.Dependency{RepositoryID: 1, Name: "vue", Ecosystem: "npm", CurrentVersion: "2.7.0"}},
+		{Dependency: domain.Dependency{RepositoryID: 2, Name: "vue", Ecosystem: "npm", CurrentVersion: "3.4.0"}},
+	}, 2)
+
+	if len(report) != 1 || report[0].ModeVersion != "3.4.0" || report[0].Score != 0.5 {
+		t.Errorf("report = %+v", report)
+	}
+}
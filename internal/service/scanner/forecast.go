@@ -0,0 +1,188 @@
+package scanner
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jiin/stale/internal/domain"
+)
+
+// cadenceReleases is how many of the most recent releases of a package give its cadence
+const cadenceReleases = 6
+
+// Forecast estimates the next release window of each package from the times its releases were
+// first seen, and projects how many major versions each dependency will be behind in months
+// months from now assuming its package keeps releasing majors at the same pace. Dependencies
+// projected to be at least majors major versions behind are at risk.
+func Forecast(deps []domain.DependencyWithRepo, releases []domain.PackageRelease, now time.Time, months, majors int) domain.FreshnessForecast {
+	horizon := now.AddDate(0, months, 0)
+	forecast := domain.FreshnessForecast{
+		Months:   months,
+		Majors:   majors,
+		Horizon:  horizon,
+		Packages: []domain.PackageForecast{},
+		AtRisk:   []domain.DependencyForecast{},
+	}
+
+	type packageKey struct{ ecosystem, name string }
+	byPackage := make(map[packageKey][]domain.PackageRelease)
+	var keys []packageKey
+	for _, release := range releases {
+		key := packageKey{release.Ecosystem, release.Name}
+		if _, ok := byPackage[key]; !ok {
+			keys = append(keys, key)
+		}
+		byPackage[key] = append(byPackage[key], release)
+	}
+
+	packages := make(map[packageKey]*packageCadence)
+	for _, key := range keys {
+		cadence := cadenceOf(byPackage[key], now)
+		packages[key] = cadence
+		if cadence.forecast.CadenceDays > 0 {
+			forecast.Packages = append(forecast.Packages, cadence.forecast)
+		}
+	}
+
+	for _, dep := range deps {
+		current, err := semver.NewVersion(cleanVersion(dep.CurrentVersion))
+		if err != nil {
+			continue
+		}
+		latest, err := semver.NewVersion(cleanVersion(dep.LatestVersion))
+		if err != nil {
+			continue
+		}
+
+		df := domain.DependencyForecast{
+			RepositoryID:   dep.RepositoryID,
+			Repository:     dep.RepoFullName,
+			Name:           dep.Name,
+			Ecosystem:      dep.Ecosystem,
+			ManifestPath:   dep.ManifestPath,
+			CurrentVersion: dep.CurrentVersion,
+			LatestVersion:  dep.LatestVersion,
+		}
+		if latest.Major() > current.Major() {
+			df.MajorsBehind = int(latest.Major() - current.Major())
+		}
+		df.ProjectedMajorsBehind = df.MajorsBehind
+		if cadence, ok := packages[packageKey{dep.Ecosystem, dep.Name}]; ok {
+			df.NextRelease = cadence.forecast.NextRelease
+			df.ProjectedMajorsBehind += cadence.majorsBy(horizon)
+		}
+		if df.ProjectedMajorsBehind >= majors {
+			forecast.AtRisk = append(forecast.AtRisk, df)
+		}
+	}
+
+	sort.Slice(forecast.Packages, func(i, j int) bool {
+		a, b := forecast.Packages[i], forecast.Packages[j]
+		if a.CadenceDays != b.CadenceDays {
+			return a.CadenceDays < b.CadenceDays
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Ecosystem < b.Ecosystem
+	})
+	sort.SliceStable(forecast.AtRisk, func(i, j int) bool {
+		a, b := forecast.AtRisk[i], forecast.AtRisk[j]
+		if a.ProjectedMajorsBehind != b.ProjectedMajorsBehind {
+			return a.ProjectedMajorsBehind > b.ProjectedMajorsBehind
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Repository < b.Repository
+	})
+	return forecast
+}
+
+// packageCadence is the release cadence of a package with the pace of its major releases
+type packageCadence struct {
+	forecast      domain.PackageForecast
+	majorInterval time.Duration // median time between major releases, 0 when fewer than two were seen
+	lastMajorAt   time.Time
+}
+
+// cadenceOf derives the cadence of a package from its releases, oldest first
+func cadenceOf(releases []domain.PackageRelease, now time.Time) *packageCadence {
+	last := releases[len(releases)-1]
+	c := &packageCadence{forecast: domain.PackageForecast{
+		Ecosystem:        last.Ecosystem,
+		Name:             last.Name,
+		ReleasesObserved: len(releases),
+		LastReleaseAt:    last.FirstSeenAt,
+	}}
+
+	recent := releases[max(len(releases)-cadenceReleases, 0):]
+	if gaps := intervals(recent); len(gaps) > 0 {
+		median := medianDuration(gaps)
+		c.forecast.CadenceDays = days(median)
+		window := &domain.ReleaseWindow{
+			From:     last.FirstSeenAt.Add(slices.Min(gaps)),
+			Expected: last.FirstSeenAt.Add(median),
+			To:       last.FirstSeenAt.Add(slices.Max(gaps)),
+		}
+		window.Overdue = window.To.Before(now)
+		c.forecast.NextRelease = window
+	}
+
+	// The first release seen of each major version, skipping prereleases
+	var majorReleases []domain.PackageRelease
+	var lastMajor uint64
+	for _, release := range releases {
+		v, err := semver.NewVersion(cleanVersion(release.Version))
+		if err != nil || v.Prerelease() != "" {
+			continue
+		}
+		if len(majorReleases) == 0 || v.Major() > lastMajor {
+			majorReleases = append(majorReleases, release)
+			lastMajor = v.Major()
+		}
+	}
+	if gaps := intervals(majorReleases); len(gaps) > 0 {
+		c.majorInterval = medianDuration(gaps)
+		c.lastMajorAt = majorReleases[len(majorReleases)-1].FirstSeenAt
+		c.forecast.MajorCadenceDays = days(c.majorInterval)
+	}
+	return c
+}
+
+// majorsBy returns how many major versions are likely to be released by the given time
+func (c *packageCadence) majorsBy(t time.Time) int {
+	if c.majorInterval <= 0 || !t.After(c.lastMajorAt) {
+		return 0
+	}
+	return int(t.Sub(c.lastMajorAt) / c.majorInterval)
+}
+
+// intervals returns the positive gaps between consecutive releases
+func intervals(releases []domain.PackageRelease) []time.Duration {
+	var gaps []time.Duration
+	for i := 1; i < len(releases); i++ {
+		if gap := releases[i].FirstSeenAt.Sub(releases[i-1].FirstSeenAt); gap > 0 {
+			gaps = append(gaps, gap)
+		}
+	}
+	return gaps
+}
+
+func medianDuration(durations []time.Duration) time.Duration {
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// days returns a duration in days rounded to one decimal
+func days(d time.Duration) float64 {
+	return math.Round(d.Hours()/24*10) / 10
+}
@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestForecast(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(days int) time.Time { return start.AddDate(0, 0, days) }
+	release := func(name, version string, days int) domain.PackageRelease {
+		return domain.PackageRelease{Ecosystem: "npm", Name: name, Version: version, FirstSeenAt: at(days)}
+	}
+	usage := func(repo, name, current, latest string) domain.DependencyWithRepo {
+		return domain.DependencyWithRepo{
+			Dependency:   domain.Dependency{Name: name, Ecosystem: "npm", CurrentVersion: current, LatestVersion: latest},
+			RepoFullName: repo,
+		}
+	}
+
+	releases := []domain.PackageRelease{
+		release("lodash", "4.17.21", 0), // a single release gives no cadence
+		release("react", "17.0.0", 0),
+		release("react", "18.0.0", 180),
+		release("react", "18.1.0", 210),
+		release("react", "18.2.0", 240),
+		release("react", "19.0.0", 360),
+	}
+	deps := []domain.DependencyWithRepo{
+		usage("acme/web", "react", "^17.0.2", "19.0.0"),
+		usage("acme/admin", "react", "18.2.0", "19.0.0"),
+		usage("acme/site", "react", "19.0.0", "19.0.0"),
+		usage("acme/web", "lodash", "3.10.1", "4.17.21"),
+	}
+
+	forecast := Forecast(deps, releases, at(370), 6, 2)
+
+	if len(forecast.Packages) != 1 {
+		t.Fatalf("packages = %+v, want react only", forecast.Packages)
+	}
+	react := forecast.Packages[0]
+	if react.Name != "react" || react.ReleasesObserved != 5 || react.CadenceDays != 75 || react.MajorCadenceDays != 180 {
+		t.Errorf("react = %+v, want 5 releases every 75 days, majors every 180", react)
+	}
+	if w := react.NextRelease; w == nil || !w.From.Equal(at(390)) || !w.Expected.Equal(at(435)) || !w.To.Equal(at(540)) || w.Overdue {
+		t.Errorf("react next release = %+v, want days 390 to 540, expected 435", w)
+	}
+
+	// One more react major is due within six months
+	if len(forecast.AtRisk) != 2 {
+		t.Fatalf("at risk = %+v, want the react 17 and 18 usages", forecast.AtRisk)
+	}
+	if d := forecast.AtRisk[0]; d.Repository != "acme/web" || d.MajorsBehind != 2 || d.ProjectedMajorsBehind != 3 || d.NextRelease == nil {
+		t.Errorf("at risk[0] = %+v, want acme/web 2 majors behind, 3 projected", d)
+	}
+	if d := forecast.AtRisk[1]; d.Repository != "acme/admin" || d.MajorsBehind != 1 || d.ProjectedMajorsBehind != 2 {
+		t.Errorf("at risk[1] = %+v, want acme/admin 1 major behind, 2 projected", d)
+	}
+
+	if overdue := Forecast(deps, releases, at(600), 6, 2).Packages[0].NextRelease; !overdue.Overdue {
+		t.Errorf("next release = %+v, want overdue after its window", overdue)
+	}
+}
@@ -0,0 +1,176 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// renovateConfigPaths are the Renovate config locations, in the order Renovate reads them.
+// JSON5 configs are not supported.
+var renovateConfigPaths = []string{"renovate.json", ".github/renovate.json", ".gitlab/renovate.json", ".renovaterc", ".renovaterc.json"}
+
+// dependabotConfigPaths are the Dependabot config locations
+var dependabotConfigPaths = []string{".github/dependabot.yml", ".github/dependabot.yaml"}
+
+// updateToolEcosystems maps Renovate managers and Dependabot package ecosystems to the
+// ecosystems of scanned dependencies; others are not scanned and are left out
+var updateToolEcosystems = map[string]string{
+	"npm":       "npm",
+	"maven":     "maven",
+	"gradle":    "gradle",
+	"gomod":     "go",
+	"swift":     "swift",
+	"cocoapods": "cocoapods",
+}
+
+// IgnoreRule is a dependency excluded from updates by a Renovate or Dependabot config
+type IgnoreRule struct {
+	Name      string
+	Ecosystem string // empty when the rule applies to every ecosystem
+	Detail    string // the setting that excludes the dependency, e.g. ignoreDeps
+}
+
+// recordIgnoreSuggestions stores the dependencies excluded by the Renovate and Dependabot configs
+// of a repository as ignore suggestions. Failures are logged and do not fail the scan.
+func (s *Scanner) recordIgnoreSuggestions(ctx context.Context, provider GitProvider, repoID int64, repoFullName, ref string) {
+	suggestions := s.ignoreSuggestions(ctx, provider, repoFullName, ref)
+	if err := s.ignoredRepo.ReplaceSuggestions(ctx, repoID, suggestions); err != nil {
+		log.Warn().Err(err).Str("repo", repoFullName).Msg("failed to save ignore suggestions")
+	}
+}
+
+// ignoreSuggestions reads the ignore rules of the first Renovate config and the first Dependabot
+// config found in a repository; missing or invalid configs are skipped
+func (s *Scanner) ignoreSuggestions(ctx context.Context, provider GitProvider, repoFullName, ref string) []domain.IgnoreSuggestion {
+	var suggestions []domain.IgnoreSuggestion
+	seen := make(map[string]bool)
+
+	read := func(paths []string, parse func([]byte) ([]IgnoreRule, error)) {
+		for _, p := range paths {
+			content, err := provider.GetFileContent(ctx, repoFullName, p, ref)
+			if err != nil {
+				continue
+			}
+			rules, err := parse(content)
+			if err != nil {
+				log.Warn().Err(err).Str("repo", repoFullName).Str("path", p).Msg("failed to parse update tool config")
+				return
+			}
+			for _, rule := range rules {
+				key := rule.Name + ":" + rule.Ecosystem
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				suggestions = append(suggestions, domain.IgnoreSuggestion{
+					Name:       rule.Name,
+					Ecosystem:  rule.Ecosystem,
+					ConfigPath: p,
+					Detail:     rule.Detail,
+				})
+			}
+			return
+		}
+	}
+	read(renovateConfigPaths, ParseRenovateIgnores)
+	read(dependabotConfigPaths, ParseDependabotIgnores)
+
+	return suggestions
+}
+
+// ParseRenovateIgnores returns the dependencies listed in ignoreDeps and those disabled by package
+// rules (enabled: false) of a Renovate config. Package name patterns (globs and regular expressions)
+// are left out since ignore rules match exact names.
+func ParseRenovateIgnores(content []byte) ([]IgnoreRule, error) {
+	var config struct {
+		IgnoreDeps   []string `json:"ignoreDeps"`
+		PackageRules []struct {
+			MatchPackageNames []string `json:"matchPackageNames"`
+			PackageNames      []string `json:"packageNames"` // deprecated name of matchPackageNames
+			MatchManagers     []string `json:"matchManagers"`
+			Enabled           *bool    `json:"enabled"`
+		} `json:"packageRules"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+
+	var rules []IgnoreRule
+	for _, name := range config.IgnoreDeps {
+		if isExactPackageName(name) {
+			rules = append(rules, IgnoreRule{Name: name, Detail: "ignoreDeps"})
+		}
+	}
+
+	for _, pr := range config.PackageRules {
+		if pr.Enabled == nil || *pr.Enabled {
+			continue
+		}
+
+		ecosystems := []string{""}
+		if len(pr.MatchManagers) > 0 {
+			ecosystems = nil
+			for _, manager := range pr.MatchManagers {
+				if eco, ok := updateToolEcosystems[manager]; ok {
+					ecosystems = append(ecosystems, eco)
+				}
+			}
+		}
+
+		for _, name := range append(pr.MatchPackageNames, pr.PackageNames...) {
+			if !isExactPackageName(name) {
+				continue
+			}
+			for _, eco := range ecosystems {
+				rules = append(rules, IgnoreRule{Name: name, Ecosystem: eco, Detail: "packageRules"})
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// ParseDependabotIgnores returns the dependencies ignored entirely by a Dependabot config. Ignore
+// entries limited to some versions or update types still allow updates and are left out.
+func ParseDependabotIgnores(content []byte) ([]IgnoreRule, error) {
+	var config struct {
+		Updates []struct {
+			PackageEcosystem string `yaml:"package-ecosystem"`
+			Ignore           []struct {
+				DependencyName string   `yaml:"dependency-name"`
+				Versions       []string `yaml:"versions"`
+				UpdateTypes    []string `yaml:"update-types"`
+			} `yaml:"ignore"`
+		} `yaml:"updates"`
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+
+	var rules []IgnoreRule
+	for _, update := range config.Updates {
+		eco, ok := updateToolEcosystems[update.PackageEcosystem]
+		if !ok {
+			continue
+		}
+		for _, ig := range update.Ignore {
+			if len(ig.Versions) > 0 || len(ig.UpdateTypes) > 0 || !isExactPackageName(ig.DependencyName) {
+				continue
+			}
+			rules = append(rules, IgnoreRule{Name: ig.DependencyName, Ecosystem: eco, Detail: "ignore"})
+		}
+	}
+
+	return rules, nil
+}
+
+// isExactPackageName reports whether a package name in an update tool config is a literal name
+// rather than a glob, regular expression or negation
+func isExactPackageName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "*?") && !strings.HasPrefix(name, "/") && !strings.HasPrefix(name, "!")
+}
@@ -0,0 +1,97 @@
+package scanner
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseRenovateIgnores(t *testing.T) {
+	content := []byte(`{
+		"extends": ["config:recommended"],
+		"ignoreDeps": ["lodash", "@types/*"],
+		"packageRules": [
+			{"matchPackageNames": ["react"], "matchManagers": ["npm", "dockerfile"], "enabled": false},
+			{"matchPackageNames": ["/^@aws-sdk/"], "enabled": false},
+			{"packageNames": ["com.google.guava:guava"], "enabled": false},
+			{"matchPackageNames": ["typescript"], "automerge": true},
+			{"matchPackageNames": ["nginx"], "matchManagers": ["dockerfile"], "enabled": false}
+		]
+	}`)
+
+	rules, err := ParseRenovateIgnores(content)
+	if err != nil {
+		t.Fatalf("ParseRenovateIgnores() error = %v", err)
+	}
+	want := []IgnoreRule{
+		{Name: "lodash", Detail: "ignoreDeps"},
+		{Name: "react", Ecosystem: "npm", Detail: "packageRules"},
+		{Name: "com.google.guava:guava", Detail: "packageRules"},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("ParseRenovateIgnores() = %+v, want %+v", rules, want)
+	}
+
+	if _, err := ParseRenovateIgnores([]byte("{ // json5\n}")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestParseDependabotIgnores(t *testing.T) {
+	content := []byte(`version: 2
+updates:
+  - package-ecosystem: npm
+    directory: /
+    ignore:
+      - dependency-name: lodash
+      - dependency-name: react
+        update-types: ["version-update:semver-major"]
+      - dependency-name: "@types/*"
+  - package-ecosystem: gomod
+    directory: /
+    ignore:
+      - dependency-name: github.com/pkg/errors
+      - dependency-name: golang.org/x/net
+        versions: ["0.x"]
+  - package-ecosystem: docker
+    directory: /
+    ignore:
+      - dependency-name: nginx
+`)
+
+	rules, err := ParseDependabotIgnores(content)
+	if err != nil {
+		t.Fatalf("ParseDependabotIgnores() error = %v", err)
+	}
+	want := []IgnoreRule{
+		{Name: "lodash", Ecosystem: "npm", Detail: "ignore"},
+		{Name: "github.com/pkg/errors", Ecosystem: "go", Detail: "ignore"},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("ParseDependabotIgnores() = %+v, want %+v", rules, want)
+	}
+}
+
+func TestIgnoreSuggestions(t *testing.T) {
+	s := &Scanner{}
+	provider := &fileProvider{files: map[string]string{
+		".github/renovate.json":  `{"ignoreDeps": ["lodash"]}`,
+		".renovaterc.json":       `{"ignoreDeps": ["shadowed"]}`,
+		".github/dependabot.yml": "updates:\n  - package-ecosystem: npm\n    ignore:\n      - dependency-name: lodash\n",
+	}}
+
+	suggestions := s.ignoreSuggestions(context.Background(), provider, "org/app", "main")
+	if len(suggestions) != 2 {
+		t.Fatalf("ignoreSuggestions() = %+v, want 2 suggestions", suggestions)
+	}
+	if sg := suggestions[0]; sg.Name != "lodash" || sg.Ecosystem != "" || sg.ConfigPath != ".github/renovate.json" {
+		t.Errorf("renovate suggestion = %+v", sg)
+	}
+	if sg := suggestions[1]; sg.Name != "lodash" || sg.Ecosystem != "npm" || sg.ConfigPath != ".github/dependabot.yml" {
+		t.Errorf("dependabot suggestion = %+v", sg)
+	}
+
+	if got := s.ignoreSuggestions(context.Background(), &fileProvider{}, "org/app", "main"); got != nil {
+		t.Errorf("ignoreSuggestions() without configs = %+v, want none", got)
+	}
+}
@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/jiin/stale/internal/domain"
+)
+
+// UpgradeJump classifies the upgrade from current to target as "major", "minor" or "patch".
+// It returns "none" when current is already at or beyond target, and "unknown" when either
+// version cannot be parsed. Version ranges (^1.2.3, ~1.2.3, >=1.2.3) use their lower bound.
+func UpgradeJump(current, target string) string {
+	currentVer, err := semver.NewVersion(cleanVersion(current))
+	if err != nil {
+		return "unknown"
+	}
+	targetVer, err := semver.NewVersion(cleanVersion(target))
+	if err != nil {
+		return "unknown"
+	}
+
+	switch {
+	case !currentVer.LessThan(targetVer):
+		return "none"
+	case currentVer.Major() != targetVer.Major():
+		return "major"
+	case currentVer.Minor() != targetVer.Minor():
+		return "minor"
+	default:
+		return "patch"
+	}
+}
+
+// PackageImpact groups the usages of a package by the version jump needed to reach target
+func PackageImpact(ecosystem, name, target string, deps []domain.DependencyWithRepo) *domain.PackageImpact {
+	impact := &domain.PackageImpact{
+		Ecosystem: ecosystem,
+		Name:      name,
+		Target:    target,
+		Major:     []domain.PackageUsage{},
+		Minor:     []domain.PackageUsage{},
+		Patch:     []domain.PackageUsage{},
+		UpToDate:  []domain.PackageUsage{},
+		Unknown:   []domain.PackageUsage{},
+	}
+
+	affected := make(map[int64]bool)
+	for _, dep := range deps {
+		usage := domain.PackageUsage{
+			RepositoryID:   dep.RepositoryID,
+			RepoFullName:   dep.RepoFullName,
+			ManifestPath:   dep.ManifestPath,
+			Type:           dep.Type,
+			CurrentVersion: dep.CurrentVersion,
+		}
+
+		switch UpgradeJump(dep.CurrentVersion, target) {
+		case "major":
+			impact.Major = append(impact.Major, usage)
+		case "minor":
+			impact.Minor = append(impact.Minor, usage)
+		case "patch":
+			impact.Patch = append(impact.Patch, usage)
+		case "none":
+			impact.UpToDate = append(impact.UpToDate, usage)
+			continue
+		default:
+			impact.Unknown = append(impact.Unknown, usage)
+			continue
+		}
+		affected[dep.RepositoryID] = true
+	}
+
+	impact.TotalRepositories = len(affected)
+	return impact
+}
@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestUpgradeJump(t *testing.T) {
+	tests := []struct {
+		current  string
+		target   string
+		expected string
+	}{
+		{"1.2.3", "2.0.0", "major"},
+		{"^1.2.3", "1.4.0", "minor"},
+		{"~1.2.3", "1.2.9", "patch"},
+		{"2.0.0", "2.0.0", "none"},
+		{">=3.0.0", "2.0.0", "none"},
+		{"latest", "2.0.0", "unknown"},
+		{"1.0.0", "not-a-version", "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+"->"+tt.target, func(t *testing.T) {
+			if got := UpgradeJump(tt.current, tt.target); got != tt.expected {
+				t.Errorf("UpgradeJump(%q, %q) = %q, want %q", tt.current, tt.target, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPackageImpact(t *testing.T) {
+	usage := func(repoID int64, repo, manifest, version string) domain.DependencyWithRepo {
+		return domain.DependencyWithRepo{
+			Dependency:   domain.Dependency{RepositoryID: repoID, Name: "react", Ecosystem: "npm", CurrentVersion: version, ManifestPath: manifest},
+			RepoFullName: repo,
+		}
+	}
+
+	impact := PackageImpact("npm", "react", "18.2.0", []domain.DependencyWithRepo{
+		usage(1, "org/web", "package.json", "^16.14.0"),
+		usage(1, "org/web", "admin/package.json", "^18.0.0"),
+		usage(2, "org/docs", "package.json", "18.2.0"),
+		usage(3, "org/app", "package.json", "18.1.5"),
+		usage(4, "org/legacy", "package.json", "*"),
+	})
+
+	if len(impact.Major) != 1 || impact.Major[0].ManifestPath != "package.json" {
+		t.Errorf("Major = %+v", impact.Major)
+	}
+	if len(impact.Minor) != 2 || len(impact.Patch) != 0 {
+		t.Errorf("Minor = %+v, Patch = %+v", impact.Minor, impact.Patch)
+	}
+	if len(impact.UpToDate) != 1 || impact.UpToDate[0].RepoFullName != "org/docs" {
+		t.Errorf("UpToDate = %+v", impact.UpToDate)
+	}
+	if len(impact.Unknown) != 1 || impact.Unknown[0].RepoFullName != "org/legacy" {
+		t.Errorf("Unknown = %+v", impact.Unknown)
+	}
+	if impact.TotalRepositories != 2 {
+		t.Errorf("TotalRepositories = %d, want 2", impact.TotalRepositories)
+	}
+}
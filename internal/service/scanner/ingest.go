@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/service/tracing"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrNotExternal is returned when ingesting dependencies of a repository tracked by a scanned source
+var ErrNotExternal = errors.New("repository is tracked by a source that is scanned")
+
+// Ingest records the dependencies of a repository pushed by an external scanner under an
+// external source. Latest versions are looked up as during scans, and dependencies missing from
+// the list are removed.
+func (s *Scanner) Ingest(ctx context.Context, source domain.Source, repo domain.Repository, deps []domain.Dependency) (_ *domain.IngestResult, err error) {
+	ctx, span := tracing.Start(ctx, "scan.ingest",
+		attribute.String("source.name", source.Name),
+		attribute.String("repository", repo.FullName),
+		attribute.Int("dependencies", len(deps)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	existing, err := s.repoRepo.GetByFullName(ctx, repo.FullName)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if existing != nil && existing.SourceID != source.ID {
+		owner, err := s.sourceRepo.GetByID(ctx, existing.SourceID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if owner != nil && !owner.External() {
+			return nil, ErrNotExternal
+		}
+	}
+
+	repo.SourceID = source.ID
+	for _, dep := range deps {
+		switch dep.Ecosystem {
+		case "npm":
+			repo.HasPackageJSON = true
+		case "maven":
+			repo.HasPomXML = true
+		case "gradle":
+			repo.HasBuildGradle = true
+		case "go":
+			repo.HasGoMod = true
+		case "swift":
+			repo.HasSwiftPM = true
+		case "cocoapods":
+			repo.HasPodfile = true
+		}
+	}
+
+	// Latest versions looked up by the reporter, e.g. an agent with registry access, are kept
+	comparison := s.Comparison()
+	recommended := s.recommendedVersions(ctx)
+	var lookup []domain.Dependency
+	var lookupIndex []int
+	for i := range deps {
+		if deps[i].LatestVersion == "" {
+			lookup = append(lookup, deps[i])
+			lookupIndex = append(lookupIndex, i)
+			continue
+		}
+		deps[i].RecommendedVersion = recommended[deps[i].Ecosystem+":"+deps[i].Name]
+		deps[i].IsOutdated = comparison.IsOutdated(deps[i].CurrentVersion, cmp.Or(deps[i].RecommendedVersion, deps[i].LatestVersion))
+	}
+	_ = s.resolveLatestVersions(ctx, lookup)
+	for j, i := range lookupIndex {
+		deps[i] = lookup[j]
+	}
+
+	result := &domain.IngestResult{Source: source, Dependencies: len(deps)}
+	for _, dep := range deps {
+		if dep.IsOutdated {
+			result.OutdatedCount++
+		}
+	}
+
+	ingestStart := time.Now()
+	repoID, err := s.repoRepo.Upsert(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deps {
+		deps[i].RepositoryID = repoID
+		if err := s.depRepo.Upsert(ctx, deps[i]); err != nil {
+			return nil, err
+		}
+	}
+	removed, err := s.depRepo.DeleteStaleByRepoID(ctx, repoID, ingestStart)
+	if err != nil {
+		log.Warn().Err(err).Str("repo", repo.FullName).Msg("failed to delete stale dependencies")
+	}
+	result.Removed = removed
+	_ = s.sourceRepo.UpdateLastScan(ctx, source.ID)
+
+	stored, err := s.repoRepo.GetByID(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	result.Repository = *stored
+	result.Repository.DependencyCount = result.Dependencies
+	result.Repository.OutdatedCount = result.OutdatedCount
+
+	log.Info().Str("source", source.Name).Str("repo", repo.FullName).Int("dependencies", len(deps)).Msg("ingested dependencies")
+	return result, nil
+}
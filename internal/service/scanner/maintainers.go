@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jiin/stale/internal/service/feature"
+	"github.com/rs/zerolog/log"
+)
+
+// maintainerEcosystems are the ecosystems whose registries list the maintainers of packages
+var maintainerEcosystems = []string{"npm", "maven", "gradle", "cocoapods"}
+
+const (
+	// maintainersTTL is how long fetched maintainers are kept before they are fetched again
+	maintainersTTL = 7 * 24 * time.Hour
+	// maintainersBatch bounds the registry lookups of a single refresh
+	maintainersBatch = 500
+)
+
+// RefreshMaintainers fetches the maintainers of packages in use that were never fetched or were
+// fetched more than maintainersTTL ago, up to maintainersBatch of them, and returns the number
+// stored. Failed lookups are logged and tried again on the next refresh.
+func (s *Scanner) RefreshMaintainers(ctx context.Context) (int, error) {
+	if !s.flags.Enabled(ctx, feature.PackageMaintainers) {
+		return 0, nil
+	}
+
+	packages, err := s.depRepo.GetStaleMaintainers(ctx, maintainerEcosystems, time.Now().Add(-maintainersTTL), maintainersBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	for _, p := range packages {
+		if ctx.Err() != nil {
+			return refreshed, ctx.Err()
+		}
+
+		maintainers, err := s.maintainers(ctx, p.Ecosystem, p.Name)
+		if err != nil {
+			log.Debug().Err(err).Str("ecosystem", p.Ecosystem).Str("package", p.Name).Msg("failed to fetch package maintainers")
+			continue
+		}
+		if err := s.depRepo.SetMaintainers(ctx, p.Ecosystem, p.Name, maintainers); err != nil {
+			return refreshed, err
+		}
+		refreshed++
+	}
+	return refreshed, nil
+}
+
+// maintainers looks up the maintainers of a package in the registry of its ecosystem
+func (s *Scanner) maintainers(ctx context.Context, ecosystem, name string) ([]string, error) {
+	switch ecosystem {
+	case "npm":
+		return s.npmClient.GetMaintainers(ctx, name)
+	case "maven", "gradle":
+		groupID, artifactID, _ := strings.Cut(name, ":")
+		return s.mavenClient.GetMaintainers(ctx, groupID, artifactID)
+	case "cocoapods":
+		return s.podsClient.GetMaintainers(ctx, name)
+	}
+	return nil, nil
+}
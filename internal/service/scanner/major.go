@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jiin/stale/internal/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// latestInMajor looks up the newest version of a dependency within its current major version, for
+// ecosystems whose registries list every version. An empty string is returned otherwise, or when
+// the lookup fails.
+func (s *Scanner) latestInMajor(ctx context.Context, dep domain.Dependency) string {
+	var versions []string
+	var err error
+	switch dep.Ecosystem {
+	case "npm":
+		versions, err = s.npmClient.GetVersions(ctx, dep.Name)
+	case "maven", "gradle":
+		groupID, artifactID, _ := strings.Cut(dep.Name, ":")
+		versions, err = s.mavenClient.GetVersions(ctx, groupID, artifactID)
+	default:
+		return ""
+	}
+	if err != nil {
+		log.Debug().Err(err).Str("ecosystem", dep.Ecosystem).Str("package", dep.Name).Msg("failed to fetch package versions")
+		return ""
+	}
+	return newestInMajor(dep.CurrentVersion, versions)
+}
+
+// newestInMajor returns the newest stable version among versions that keeps the major version of
+// current, so that upgrading to it should bring no breaking changes. Below 1.0.0 the minor version
+// has to match too, as npm caret ranges do. An empty string is returned when current is not a
+// semantic version or no such version is at least current.
+func newestInMajor(current string, versions []string) string {
+	cur, err := semver.NewVersion(current)
+	if err != nil {
+		return ""
+	}
+
+	var newest *semver.Version
+	for _, version := range versions {
+		v, err := semver.NewVersion(version)
+		if err != nil || v.Prerelease() != "" || v.Major() != cur.Major() || v.LessThan(cur) {
+			continue
+		}
+		if cur.Major() == 0 && v.Minor() != cur.Minor() {
+			continue
+		}
+		if newest == nil || v.GreaterThan(newest) {
+			newest = v
+		}
+	}
+
+	if newest == nil {
+		return ""
+	}
+	return newest.Original()
+}
@@ -0,0 +1,25 @@
+package scanner
+
+import "testing"
+
+func TestNewestInMajor(t *testing.T) {
+	versions := []string{"1.0.0", "1.2.0", "1.10.3", "1.11.0-beta.1", "2.0.0", "2.3.1", "0.3.0", "0.3.7", "0.4.0", "not-a-version"}
+	tests := []struct {
+		current string
+		want    string
+	}{
+		{"1.2.0", "1.10.3"},
+		{"1.10.3", "1.10.3"},
+		{"2.0.0", "2.3.1"},
+		{"0.3.1", "0.3.7"}, // below 1.0.0 the minor version is the breaking one
+		{"3.0.0", ""},
+		{"1.11.0", ""}, // nothing stable at or above the current version
+		{"latest", ""},
+	}
+
+	for _, tt := range tests {
+		if got := newestInMajor(tt.current, versions); got != tt.want {
+			t.Errorf("newestInMajor(%q) = %q, want %q", tt.current, got, tt.want)
+		}
+	}
+}
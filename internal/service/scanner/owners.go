@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// codeownersPaths are the locations GitHub and GitLab read CODEOWNERS from, in order of precedence
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// repositoryOwners reads the owners of a repository from the metadata file (e.g. a Backstage
+// catalog-info.yaml) or, when it names no owner, from CODEOWNERS. It returns the owners and the
+// path of the file they were read from; missing files are not an error.
+func (s *Scanner) repositoryOwners(ctx context.Context, provider GitProvider, repoFullName, ref string) ([]string, string) {
+	if s.ownershipFile != "" {
+		if content, err := provider.GetFileContent(ctx, repoFullName, s.ownershipFile, ref); err == nil {
+			if owners := ParseCatalogOwners(content); len(owners) > 0 {
+				return owners, s.ownershipFile
+			}
+		}
+	}
+
+	for _, p := range codeownersPaths {
+		content, err := provider.GetFileContent(ctx, repoFullName, p, ref)
+		if err != nil {
+			continue
+		}
+		if owners := ParseCodeowners(content); len(owners) > 0 {
+			return owners, p
+		}
+	}
+	return nil, ""
+}
+
+// ParseCodeowners returns the default owners of a CODEOWNERS file: the owners of the last rule
+// matching every file (* or /*), or otherwise all owners named in the file, in order of appearance.
+// GitLab section headers ([Section] @owner) contribute their default owners.
+func ParseCodeowners(content []byte) []string {
+	var defaultOwners, allOwners []string
+	seen := make(map[string]bool)
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+
+		var pattern string
+		var owners []string
+		if strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			// Section header; default owners follow the closing bracket (and optional approval count)
+			rest := line[strings.Index(line, "]")+1:]
+			if strings.HasPrefix(rest, "[") {
+				rest = rest[strings.Index(rest, "]")+1:]
+			}
+			owners = strings.Fields(rest)
+		} else {
+			fields := strings.Fields(line)
+			pattern, owners = fields[0], fields[1:]
+		}
+
+		for _, owner := range owners {
+			if !seen[owner] {
+				seen[owner] = true
+				allOwners = append(allOwners, owner)
+			}
+		}
+		if (pattern == "*" || pattern == "/*" || pattern == "/**") && len(owners) > 0 {
+			defaultOwners = owners
+		}
+	}
+
+	if len(defaultOwners) > 0 {
+		return defaultOwners
+	}
+	return allOwners
+}
+
+// ParseCatalogOwners returns the spec.owner of every entity of a Backstage catalog-info.yaml
+// (which may hold several YAML documents), e.g. group:platform-team
+func ParseCatalogOwners(content []byte) []string {
+	var owners []string
+	seen := make(map[string]bool)
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var entity struct {
+			Spec struct {
+				Owner string `yaml:"owner"`
+			} `yaml:"spec"`
+		}
+		err := decoder.Decode(&entity)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return owners
+		}
+
+		owner := strings.TrimSpace(entity.Spec.Owner)
+		if owner != "" && !seen[owner] {
+			seen[owner] = true
+			owners = append(owners, owner)
+		}
+	}
+	return owners
+}
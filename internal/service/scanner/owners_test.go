@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseCodeowners(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name: "last catch-all rule wins",
+			content: `# Default owners
+* @org/legacy
+*.go @org/backend
+* @org/platform alice@example.com
+`,
+			expected: []string{"@org/platform", "alice@example.com"},
+		},
+		{
+			name: "all owners without catch-all rule",
+			content: `/web/ @org/frontend # UI
+/api/ @org/backend @org/frontend
+`,
+			expected: []string{"@org/frontend", "@org/backend"},
+		},
+		{
+			name: "gitlab section default owners",
+			content: `[Documentation][2] @docs-team
+docs/
+^[Database] @dba
+`,
+			expected: []string{"@docs-team", "@dba"},
+		},
+		{
+			name:     "no owners",
+			content:  "# nothing here\n",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseCodeowners([]byte(tt.content)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseCodeowners() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseCatalogOwners(t *testing.T) {
+	content := `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: api
+spec:
+  owner: group:platform-team
+---
+kind: Component
+spec:
+  owner: group:platform-team
+---
+kind: API
+spec:
+  owner: user:alice
+`
+	got := ParseCatalogOwners([]byte(content))
+	want := []string{"group:platform-team", "user:alice"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCatalogOwners() = %v, want %v", got, want)
+	}
+
+	if got := ParseCatalogOwners([]byte("not: [valid")); len(got) != 0 {
+		t.Errorf("ParseCatalogOwners(invalid) = %v, want none", got)
+	}
+}
+
+// fileProvider serves files from a map, failing for everything else
+type fileProvider struct {
+	GitProvider
+	files map[string]string
+}
+
+func (p *fileProvider) GetFileContent(ctx context.Context, repoPath, filePath, ref string) ([]byte, error) {
+	if content, ok := p.files[filePath]; ok {
+		return []byte(content), nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestRepositoryOwners(t *testing.T) {
+	s := &Scanner{ownershipFile: "catalog-info.yaml"}
+	ctx := context.Background()
+
+	provider := &fileProvider{files: map[string]string{
+		"catalog-info.yaml": "spec:\n  owner: group:payments\n",
+		"CODEOWNERS":        "* @org/payments",
+	}}
+	owners, source := s.repositoryOwners(ctx, provider, "org/app", "main")
+	if !reflect.DeepEqual(owners, []string{"group:payments"}) || source != "catalog-info.yaml" {
+		t.Errorf("repositoryOwners() = %v, %q, want metadata file owners", owners, source)
+	}
+
+	provider.files["catalog-info.yaml"] = "spec: {}\n"
+	owners, source = s.repositoryOwners(ctx, provider, "org/app", "main")
+	if !reflect.DeepEqual(owners, []string{"@org/payments"}) || source != "CODEOWNERS" {
+		t.Errorf("repositoryOwners() = %v, %q, want CODEOWNERS owners", owners, source)
+	}
+
+	owners, source = s.repositoryOwners(ctx, &fileProvider{}, "org/app", "main")
+	if owners != nil || source != "" {
+		t.Errorf("repositoryOwners() = %v, %q, want none", owners, source)
+	}
+}
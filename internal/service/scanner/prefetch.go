@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+// prefetchConcurrency bounds the concurrent registry lookups of a prefetch
+const prefetchConcurrency = 10
+
+// Prefetch looks up the latest versions of the limit packages used by the most repositories, so
+// the registry caches are warm when a scan starts and the lookups shared by many repositories do
+// not pile up at its beginning. It returns the number of packages looked up; failed lookups are
+// left to the scan.
+func (s *Scanner) Prefetch(ctx context.Context, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+	packages, err := s.depRepo.GetPopularPackages(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	var fetched atomic.Int32
+	sem := make(chan struct{}, prefetchConcurrency)
+	for _, p := range packages {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if _, err := s.latestVersion(ctx, p); err != nil {
+				log.Debug().Err(err).Str("ecosystem", p.Ecosystem).Str("package", p.Name).Msg("failed to prefetch latest version")
+				return
+			}
+			fetched.Add(1)
+		}()
+	}
+	wg.Wait()
+	return int(fetched.Load()), ctx.Err()
+}
@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/service/httputil"
+	"github.com/jiin/stale/internal/service/tracing"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ScanRepositories scans the given tracked repositories only, without listing their sources, so
+// repositories are neither discovered nor marked as removed. Repositories that are unknown,
+// removed from their source or belong to a disabled or external source are logged and skipped.
+func (s *Scanner) ScanRepositories(ctx context.Context, scanID int64, repoIDs []int64) error {
+	var totalRepos, totalDeps int32
+
+	bySource := make(map[int64][]domain.Repository)
+	var sourceIDs []int64
+	for _, id := range repoIDs {
+		repo, err := s.repoRepo.GetByID(ctx, id)
+		if err != nil {
+			log.Warn().Err(err).Int64("repo_id", id).Msg("skipping repository of targeted rescan")
+			continue
+		}
+		if repo.RemovedAt != nil {
+			log.Info().Str("repo", repo.FullName).Msg("skipping repository no longer found in source")
+			continue
+		}
+		if _, ok := bySource[repo.SourceID]; !ok {
+			sourceIDs = append(sourceIDs, repo.SourceID)
+		}
+		bySource[repo.SourceID] = append(bySource[repo.SourceID], *repo)
+	}
+
+	for _, sourceID := range sourceIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		source, err := s.sourceRepo.GetByID(ctx, sourceID)
+		if err != nil {
+			log.Error().Err(err).Int64("source_id", sourceID).Msg("failed to load source of targeted rescan")
+			continue
+		}
+		if source.Disabled() {
+			log.Info().Str("source", source.Name).Msg("skipping source disabled after repeated scan failures")
+			continue
+		}
+		if source.External() {
+			log.Info().Str("source", source.Name).Msg("skipping external source - its dependencies are ingested")
+			continue
+		}
+		s.rescanSourceRepos(ctx, *source, bySource[sourceID], scanID, &totalRepos, &totalDeps)
+	}
+	return ctx.Err()
+}
+
+// rescanSourceRepos scans tracked repositories of a source, looking each up again so a changed
+// default branch is picked up
+func (s *Scanner) rescanSourceRepos(ctx context.Context, source domain.Source, repos []domain.Repository, scanID int64, totalRepos, totalDeps *int32) {
+	ctx, span := tracing.Start(ctx, "scan.source",
+		attribute.Int64("source.id", source.ID),
+		attribute.String("source.name", source.Name),
+		attribute.String("source.type", source.Type),
+		attribute.Int("source.repositories", len(repos)),
+	)
+	st := &sourceScan{
+		source:             source,
+		provider:           s.provider(source),
+		knownRepos:         make(map[string]bool, len(repos)),
+		usage:              &httputil.Usage{},
+		span:               span,
+		additionalBranches: parseBranchList(source.AdditionalBranches),
+	}
+	defer s.finishSourceScan(ctx, st, scanID)
+	s.addReposTotal(ctx, scanID, len(repos))
+
+	var err error
+	st.branchOverrides, err = s.repoRepo.GetBranchOverrides(ctx, source.ID)
+	if err != nil {
+		log.Warn().Err(err).Str("source", source.Name).Msg("failed to load branch overrides")
+	}
+
+	for _, repo := range repos {
+		if ctx.Err() != nil {
+			return
+		}
+		st.knownRepos[strings.ToLower(repo.FullName)] = true
+
+		info, err := st.provider.GetRepository(httputil.WithUsage(ctx, st.usage), repo.FullName)
+		if err != nil {
+			log.Error().Err(err).Str("repo", repo.FullName).Msg("failed to fetch repository")
+			s.recordRepoScanError(ctx, repo, "failed to fetch repository from "+err.Error())
+			continue
+		}
+		s.scanSourceRepo(ctx, st, *info, scanID, totalRepos, totalDeps)
+	}
+}
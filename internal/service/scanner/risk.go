@@ -0,0 +1,122 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+// popularPackages are widely used npm packages that typosquatting packages imitate. Packages in
+// this list are never reported as typosquats themselves, so legitimate packages with similar
+// names (e.g. react and preact) are listed together.
+var popularPackages = []string{
+	"acorn", "ansi-styles", "apollo-server", "autoprefixer", "axios", "babel-core", "bcrypt",
+	"bcryptjs", "bluebird", "body-parser", "boxen", "braces", "browserify", "bunyan", "chai",
+	"chalk", "chart.js", "cheerio", "chokidar", "classnames", "commander", "concurrently",
+	"core-js", "cors", "cross-env", "cross-spawn", "date-fns", "dayjs", "debug", "dotenv",
+	"dotenv-expand", "dotenvx", "electron", "esbuild", "eslint", "esprima", "event-stream",
+	"execa", "express", "figlet", "formik", "fs-extra", "graphql", "gulp", "grunt", "handlebars",
+	"helmet", "highlight.js", "husky", "immutable", "inherits", "inquirer", "jquery", "js-yaml",
+	"jsonwebtoken", "karma", "kleur", "knex", "left-pad", "lint-staged", "lodash", "marked",
+	"micromatch", "minimist", "mkdirp", "mocha", "moment", "mongoose", "morgan", "mysql", "mysql2",
+	"nanoid", "next", "nock", "node-fetch", "node-gyp", "node-sass", "nodemon", "nuxt",
+	"object-assign", "passport", "picocolors", "picomatch", "pino", "postcss", "preact",
+	"prettier", "prop-types", "puppeteer", "rambda", "ramda", "react", "react-dom", "react-redux",
+	"react-router", "react-router-dom", "readable-stream", "redis", "redux", "request",
+	"request-promise", "rimraf", "rollup", "rxjs", "safe-buffer", "sass", "semver", "sequelize",
+	"shelljs", "sinon", "socket.io", "source-map", "string-width", "strip-ansi",
+	"styled-components", "superagent", "supertest", "supports-color", "svelte", "tailwindcss",
+	"three", "tslib", "tslint", "typescript", "ua-parser-js", "underscore", "uuid", "validator",
+	"vite", "webpack", "winston", "yargs", "yaml",
+}
+
+// isPopularPackage is popularPackages as a set
+var isPopularPackage = func() map[string]bool {
+	set := make(map[string]bool, len(popularPackages))
+	for _, name := range popularPackages {
+		set[name] = true
+	}
+	return set
+}()
+
+// typosquatTarget returns the popular package an npm package name imitates, or an empty string.
+// Names within one edit of a popular name of 5 characters or more, or within two edits of one of
+// 10 characters or more, are suspicious. Scoped packages are left out since their scope is owned.
+func typosquatTarget(name string) string {
+	name = strings.ToLower(name)
+	if strings.HasPrefix(name, "@") || isPopularPackage[name] {
+		return ""
+	}
+
+	for _, popular := range popularPackages {
+		maxDistance := 0
+		switch {
+		case len(popular) >= 10:
+			maxDistance = 2
+		case len(popular) >= 5:
+			maxDistance = 1
+		}
+		if maxDistance == 0 || abs(len(name)-len(popular)) > maxDistance {
+			continue
+		}
+		if editDistance(name, popular) <= maxDistance {
+			return popular
+		}
+	}
+	return ""
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// assessRisks sets the supply-chain risks of an npm dependency: install scripts run by its
+// current version and a name imitating a popular package. A failed install script lookup leaves
+// that risk out.
+func (s *Scanner) assessRisks(ctx context.Context, dep *domain.Dependency) {
+	if dep.Ecosystem != "npm" {
+		return
+	}
+
+	var risks, details []string
+	if isSemver(dep.CurrentVersion) {
+		scripts, err := s.npmClient.GetInstallScripts(ctx, dep.Name, dep.CurrentVersion)
+		if err == nil && len(scripts) > 0 {
+			risks = append(risks, domain.RiskInstallScript)
+			details = append(details, "runs "+strings.Join(scripts, "; "))
+		}
+	}
+	if target := typosquatTarget(dep.Name); target != "" {
+		risks = append(risks, domain.RiskTyposquat)
+		details = append(details, "name is similar to "+target)
+	}
+
+	dep.Risks = strings.Join(risks, ",")
+	dep.RiskDetail = strings.Join(details, "; ")
+}
@@ -0,0 +1,47 @@
+package scanner
+
+import "testing"
+
+func TestTyposquatTarget(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"lodash", ""},
+		{"lodahs", ""}, // a transposition is two edits
+		{"lodashs", "lodash"},
+		{"crossenv", "cross-env"},
+		{"Expres", "express"},
+		{"preact", ""},
+		{"tailwind-css", "tailwindcss"},
+		{"jsonwebtokn", "jsonwebtoken"},
+		{"jsonwebtkn", "jsonwebtoken"},
+		{"next", ""},
+		{"nest", ""}, // popular names shorter than 5 characters are not compared
+		{"@evil/lodash", ""},
+		{"left-pad-utils", ""},
+	}
+
+	for _, tt := range tests {
+		if got := typosquatTarget(tt.name); got != tt.want {
+			t.Errorf("typosquatTarget(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"react", "preact", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+// SBOM formats, recorded as the manifest type of the dependencies of an SBOM
+const (
+	SBOMFormatCycloneDX = "cyclonedx"
+	SBOMFormatSPDX      = "spdx"
+)
+
+// ErrUnknownSBOM is returned for documents that are neither CycloneDX nor SPDX JSON
+var ErrUnknownSBOM = errors.New("document is not a CycloneDX or SPDX JSON SBOM")
+
+// cycloneDXDocument is the part of a CycloneDX JSON SBOM that lists components
+type cycloneDXDocument struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	PURL       string               `json:"purl"`
+	Scope      string               `json:"scope"` // required (default), optional or excluded
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// spdxDocument is the part of an SPDX JSON SBOM that lists packages
+type spdxDocument struct {
+	DocumentDescribes []string      `json:"documentDescribes"`
+	Packages          []spdxPackage `json:"packages"`
+}
+
+type spdxPackage struct {
+	SPDXID       string `json:"SPDXID"`
+	ExternalRefs []struct {
+		ReferenceType    string `json:"referenceType"`
+		ReferenceLocator string `json:"referenceLocator"`
+	} `json:"externalRefs"`
+}
+
+// ParseSBOM translates the components of a CycloneDX or SPDX JSON SBOM, as produced by e.g. Syft,
+// into dependencies. Components are identified by their package URL; those without one, of an
+// unsupported ecosystem or listed again are counted as skipped.
+func ParseSBOM(content []byte) (deps []domain.IngestDependency, skipped int, err error) {
+	var probe struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(content, &probe); err != nil {
+		return nil, 0, ErrUnknownSBOM
+	}
+
+	seen := make(map[string]bool)
+	add := func(purl, format, depType string) {
+		dep, ok := purlDependency(purl)
+		if !ok || seen[dep.Ecosystem+":"+dep.Name] {
+			skipped++
+			return
+		}
+		seen[dep.Ecosystem+":"+dep.Name] = true
+		dep.Type = depType
+		dep.ManifestType = format
+		deps = append(deps, dep)
+	}
+
+	switch {
+	case strings.EqualFold(probe.BOMFormat, "CycloneDX"):
+		var doc cycloneDXDocument
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return nil, 0, err
+		}
+		var walk func(components []cycloneDXComponent)
+		walk = func(components []cycloneDXComponent) {
+			for _, c := range components {
+				add(c.PURL, SBOMFormatCycloneDX, cycloneDXType(c.Scope))
+				walk(c.Components)
+			}
+		}
+		walk(doc.Components)
+
+	case strings.HasPrefix(probe.SPDXVersion, "SPDX-"):
+		var doc spdxDocument
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return nil, 0, err
+		}
+		described := make(map[string]bool, len(doc.DocumentDescribes))
+		for _, id := range doc.DocumentDescribes {
+			described[id] = true
+		}
+		for _, p := range doc.Packages {
+			// The described package is the scanned artifact itself
+			if described[p.SPDXID] {
+				continue
+			}
+			purl := ""
+			for _, ref := range p.ExternalRefs {
+				if ref.ReferenceType == "purl" {
+					purl = ref.ReferenceLocator
+					break
+				}
+			}
+			add(purl, SBOMFormatSPDX, domain.DependencyTypeProd)
+		}
+
+	default:
+		return nil, 0, ErrUnknownSBOM
+	}
+
+	return deps, skipped, nil
+}
+
+// cycloneDXType returns the dependency type of a CycloneDX component scope
+func cycloneDXType(scope string) string {
+	switch scope {
+	case "optional":
+		return domain.DependencyTypeOptional
+	case "excluded": // not part of the runtime, e.g. build or test tooling
+		return domain.DependencyTypeDev
+	}
+	return domain.DependencyTypeProd
+}
+
+// purlDependency returns the dependency identified by a package URL, e.g.
+// pkg:npm/%40babel/core@7.24.0 or pkg:maven/org.slf4j/slf4j-api@2.0.9, named as scans name it
+func purlDependency(purl string) (domain.IngestDependency, bool) {
+	rest, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return domain.IngestDependency{}, false
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+
+	purlType, path, ok := strings.Cut(strings.TrimLeft(rest, "/"), "/")
+	if !ok {
+		return domain.IngestDependency{}, false
+	}
+	version := ""
+	if i := strings.LastIndex(path, "@"); i > strings.LastIndex(path, "/") {
+		path, version = path[:i], path[i+1:]
+	}
+	version, err := url.PathUnescape(version)
+	if err != nil || version == "" {
+		return domain.IngestDependency{}, false
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		if segments[i], err = url.PathUnescape(s); err != nil || segments[i] == "" {
+			return domain.IngestDependency{}, false
+		}
+	}
+	name := strings.Join(segments, "/")
+
+	dep := domain.IngestDependency{Name: name, Version: version}
+	switch strings.ToLower(purlType) {
+	case "npm":
+		dep.Ecosystem = "npm"
+	case "maven":
+		if len(segments) != 2 {
+			return domain.IngestDependency{}, false
+		}
+		dep.Ecosystem, dep.Name = "maven", segments[0]+":"+segments[1]
+	case "golang":
+		dep.Ecosystem = "go"
+	case "swift":
+		dep.Ecosystem, dep.Name = "swift", strings.ToLower(name)
+	case "cocoapods":
+		dep.Ecosystem, dep.Name = "cocoapods", podName(name)
+	default:
+		return domain.IngestDependency{}, false
+	}
+	return dep, true
+}
@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestParseSBOM_CycloneDX(t *testing.T) {
+	content := `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"components": [
+			{"type": "library", "name": "core", "version": "7.24.0", "purl": "pkg:npm/%40babel/core@7.24.0"},
+			{"type": "library", "name": "slf4j-api", "version": "2.0.9", "purl": "pkg:maven/org.slf4j/slf4j-api@2.0.9?type=jar", "scope": "optional",
+				"components": [{"type": "library", "name": "gin", "purl": "pkg:golang/github.com/gin-gonic/gin@v1.9.1"}]},
+			{"type": "library", "name": "jest", "purl": "pkg:npm/jest@29.7.0", "scope": "excluded"},
+			{"type": "library", "name": "requests", "purl": "pkg:pypi/requests@2.31.0"},
+			{"type": "operating-system", "name": "alpine"},
+			{"type": "library", "name": "core", "purl": "pkg:npm/%40babel/core@7.23.0"}
+		]
+	}`
+
+	deps, skipped, err := ParseSBOM([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseSBOM() error = %v", err)
+	}
+	if skipped != 3 {
+		t.Errorf("skipped = %d, want 3", skipped)
+	}
+
+	want := []domain.IngestDependency{
+		{Name: "@babel/core", Version: "7.24.0", Ecosystem: "npm", Type: domain.DependencyTypeProd, ManifestType: "cyclonedx"},
+		{Name: "org.slf4j:slf4j-api", Version: "2.0.9", Ecosystem: "maven", Type: domain.DependencyTypeOptional, ManifestType: "cyclonedx"},
+		{Name: "github.com/gin-gonic/gin", Version: "v1.9.1", Ecosystem: "go", Type: domain.DependencyTypeProd, ManifestType: "cyclonedx"},
+		{Name: "jest", Version: "29.7.0", Ecosystem: "npm", Type: domain.DependencyTypeDev, ManifestType: "cyclonedx"},
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("got %d dependencies, want %d: %+v", len(deps), len(want), deps)
+	}
+	for i := range want {
+		if deps[i] != want[i] {
+			t.Errorf("deps[%d] = %+v, want %+v", i, deps[i], want[i])
+		}
+	}
+}
+
+func TestParseSBOM_SPDX(t *testing.T) {
+	content := `{
+		"spdxVersion": "SPDX-2.3",
+		"documentDescribes": ["SPDXRef-image"],
+		"packages": [
+			{"SPDXID": "SPDXRef-image", "name": "app", "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:oci/app@sha256%3Aabc"}]},
+			{"SPDXID": "SPDXRef-1", "name": "Alamofire", "externalRefs": [
+				{"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:alamofire:alamofire:5.8.1"},
+				{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:cocoapods/Alamofire@5.8.1"}
+			]},
+			{"SPDXID": "SPDXRef-2", "name": "swift-nio", "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:swift/github.com/Apple/swift-nio@2.62.0"}]},
+			{"SPDXID": "SPDXRef-3", "name": "libc", "versionInfo": "1.0"}
+		]
+	}`
+
+	deps, skipped, err := ParseSBOM([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseSBOM() error = %v", err)
+	}
+	if skipped != 1 {
+		t.Errorf("skipped = %d, want 1", skipped)
+	}
+	if len(deps) != 2 {
+		t.Fatalf("got %d dependencies, want 2: %+v", len(deps), deps)
+	}
+	if deps[0].Name != "Alamofire" || deps[0].Ecosystem != "cocoapods" || deps[0].Version != "5.8.1" || deps[0].ManifestType != "spdx" {
+		t.Errorf("deps[0] = %+v, want Alamofire 5.8.1 of cocoapods", deps[0])
+	}
+	if deps[1].Name != "github.com/apple/swift-nio" || deps[1].Ecosystem != "swift" {
+		t.Errorf("deps[1] = %+v, want github.com/apple/swift-nio of swift", deps[1])
+	}
+}
+
+func TestParseSBOM_Unknown(t *testing.T) {
+	for _, content := range []string{`not json`, `{"name": "package.json"}`} {
+		if _, _, err := ParseSBOM([]byte(content)); !errors.Is(err, ErrUnknownSBOM) {
+			t.Errorf("ParseSBOM(%q) error = %v, want ErrUnknownSBOM", content, err)
+		}
+	}
+}
+
+func TestPurlDependency(t *testing.T) {
+	tests := []struct {
+		purl      string
+		name      string
+		ecosystem string
+		version   string
+		ok        bool
+	}{
+		{"pkg:npm/lodash@4.17.21", "lodash", "npm", "4.17.21", true},
+		{"pkg:npm/%40types/node@20.1.0#lib", "@types/node", "npm", "20.1.0", true},
+		{"pkg:maven/com.google.guava/guava@32.1.2-jre?type=jar", "com.google.guava:guava", "maven", "32.1.2-jre", true},
+		{"pkg:golang/golang.org/x/net@v0.17.0", "golang.org/x/net", "go", "v0.17.0", true},
+		{"pkg:cocoapods/Firebase/Analytics@10.0.0", "Firebase", "cocoapods", "10.0.0", true},
+		{"pkg:npm/lodash", "", "", "", false},
+		{"pkg:maven/guava@32.1.2", "", "", "", false},
+		{"pkg:deb/debian/curl@7.88.1", "", "", "", false},
+		{"npm/lodash@4.17.21", "", "", "", false},
+	}
+
+	for _, tt := range tests {
+		dep, ok := purlDependency(tt.purl)
+		if ok != tt.ok {
+			t.Errorf("purlDependency(%q) ok = %v, want %v", tt.purl, ok, tt.ok)
+			continue
+		}
+		if ok && (dep.Name != tt.name || dep.Ecosystem != tt.ecosystem || dep.Version != tt.version) {
+			t.Errorf("purlDependency(%q) = %s %s@%s, want %s %s@%s", tt.purl, dep.Ecosystem, dep.Name, dep.Version, tt.ecosystem, tt.name, tt.version)
+		}
+	}
+}
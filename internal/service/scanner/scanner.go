@@ -1,10 +1,17 @@
 package scanner
 
 import (
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
+	"fmt"
+	"path"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -13,19 +20,30 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/jiin/stale/internal/domain"
 	"github.com/jiin/stale/internal/repository"
+	"github.com/jiin/stale/internal/service/android"
+	"github.com/jiin/stale/internal/service/cocoapods"
+	"github.com/jiin/stale/internal/service/feature"
 	"github.com/jiin/stale/internal/service/github"
 	"github.com/jiin/stale/internal/service/gitlab"
+	"github.com/jiin/stale/internal/service/gitrepo"
 	"github.com/jiin/stale/internal/service/golang"
+	"github.com/jiin/stale/internal/service/httputil"
 	"github.com/jiin/stale/internal/service/maven"
 	"github.com/jiin/stale/internal/service/npm"
+	"github.com/jiin/stale/internal/service/swift"
+	"github.com/jiin/stale/internal/service/tracing"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// GitProvider is an interface for Git hosting providers (GitHub, GitLab)
+// GitProvider is an interface for Git hosting providers (GitHub, GitLab, plain git servers)
 type GitProvider interface {
 	ListRepositories(ctx context.Context) ([]RepoInfo, error)
+	GetRepository(ctx context.Context, repoPath string) (*RepoInfo, error)
 	GetFileContent(ctx context.Context, repoPath, filePath, ref string) ([]byte, error)
 	ListManifestFiles(ctx context.Context, repoPath, ref string) ([]string, error)
+	ListBranches(ctx context.Context, repoPath string) ([]string, error)
 }
 
 // RepoInfo contains common repository information
@@ -36,9 +54,26 @@ type RepoInfo struct {
 	HTMLURL       string
 }
 
-// GitHubAdapter adapts github.Client to GitProvider
+// Registry looks up the latest version of a dependency in the registry of its ecosystem. Scanners
+// use the public registries unless one is set with SetRegistry, e.g. a fake in tests. The latest
+// Go release is looked up as the go package of the go ecosystem, which no module is named.
+type Registry interface {
+	LatestVersion(ctx context.Context, dep domain.Dependency) (string, error)
+}
+
+// AlertProvider is implemented by providers that can report known vulnerabilities of a repository
+type AlertProvider interface {
+	ListAlerts(ctx context.Context, repoPath string) ([]domain.VulnerabilityAlert, error)
+}
+
+// GitHubAdapter adapts github.Client to GitProvider and AlertProvider
 type GitHubAdapter struct {
-	client *github.Client
+	client  *github.Client
+	org     bool             // alerts can be fetched for the whole organization at once
+	archive *manifestArchive // set when manifests are read from repository archives
+
+	orgAlerts    map[string][]domain.VulnerabilityAlert // by lowercased repository full name
+	orgAlertsErr error
 }
 
 func (a *GitHubAdapter) ListRepositories(ctx context.Context) ([]RepoInfo, error) {
@@ -58,17 +93,94 @@ func (a *GitHubAdapter) ListRepositories(ctx context.Context) ([]RepoInfo, error
 	return result, nil
 }
 
+func (a *GitHubAdapter) GetRepository(ctx context.Context, repoPath string) (*RepoInfo, error) {
+	r, err := a.client.GetRepository(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return &RepoInfo{
+		Name:          r.Name,
+		FullName:      r.FullName,
+		DefaultBranch: r.DefaultBranch,
+		HTMLURL:       r.HTMLURL,
+	}, nil
+}
+
 func (a *GitHubAdapter) GetFileContent(ctx context.Context, repoPath, filePath, ref string) ([]byte, error) {
+	if a.archive != nil {
+		return a.archive.GetFileContent(ctx, repoPath, filePath, ref, func() ([]byte, error) {
+			return a.client.GetFileContent(ctx, repoPath, filePath, ref)
+		})
+	}
 	return a.client.GetFileContent(ctx, repoPath, filePath, ref)
 }
 
 func (a *GitHubAdapter) ListManifestFiles(ctx context.Context, repoPath, ref string) ([]string, error) {
+	if a.archive != nil {
+		return a.archive.ListManifestFiles(ctx, repoPath, ref, func() ([]string, error) {
+			return a.client.ListManifestFiles(ctx, repoPath, ref)
+		})
+	}
 	return a.client.ListManifestFiles(ctx, repoPath, ref)
 }
 
+func (a *GitHubAdapter) ListBranches(ctx context.Context, repoPath string) ([]string, error) {
+	return a.client.ListBranches(ctx, repoPath)
+}
+
+// ListAlerts returns the open Dependabot alerts of a repository. For organization sources
+// the alerts of all repositories are fetched once and served from memory afterwards.
+func (a *GitHubAdapter) ListAlerts(ctx context.Context, repoPath string) ([]domain.VulnerabilityAlert, error) {
+	if !a.org {
+		alerts, err := a.client.ListRepoDependabotAlerts(ctx, repoPath)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]domain.VulnerabilityAlert, len(alerts))
+		for i, alert := range alerts {
+			result[i] = toVulnerabilityAlert(alert)
+		}
+		return result, nil
+	}
+
+	if a.orgAlerts == nil && a.orgAlertsErr == nil {
+		alerts, err := a.client.ListOrgDependabotAlerts(ctx)
+		if err != nil {
+			a.orgAlertsErr = err
+		} else {
+			a.orgAlerts = make(map[string][]domain.VulnerabilityAlert)
+			for _, alert := range alerts {
+				key := strings.ToLower(alert.RepoFullName)
+				a.orgAlerts[key] = append(a.orgAlerts[key], toVulnerabilityAlert(alert))
+			}
+		}
+	}
+	if a.orgAlertsErr != nil {
+		return nil, a.orgAlertsErr
+	}
+	return a.orgAlerts[strings.ToLower(repoPath)], nil
+}
+
+func toVulnerabilityAlert(a github.DependabotAlert) domain.VulnerabilityAlert {
+	return domain.VulnerabilityAlert{
+		AlertNumber:     a.Number,
+		Ecosystem:       strings.ToLower(a.Ecosystem),
+		PackageName:     a.PackageName,
+		ManifestPath:    a.ManifestPath,
+		Severity:        strings.ToLower(a.Severity),
+		GHSAID:          a.GHSAID,
+		CVEID:           a.CVEID,
+		Summary:         a.Summary,
+		VulnerableRange: a.VulnerableRange,
+		PatchedVersion:  a.PatchedVersion,
+		HTMLURL:         a.HTMLURL,
+	}
+}
+
 // GitLabAdapter adapts gitlab.Client to GitProvider
 type GitLabAdapter struct {
-	client *gitlab.Client
+	client  *gitlab.Client
+	archive *manifestArchive // set when manifests are read from repository archives
 }
 
 func (a *GitLabAdapter) ListRepositories(ctx context.Context) ([]RepoInfo, error) {
@@ -88,51 +200,245 @@ func (a *GitLabAdapter) ListRepositories(ctx context.Context) ([]RepoInfo, error
 	return result, nil
 }
 
+func (a *GitLabAdapter) GetRepository(ctx context.Context, repoPath string) (*RepoInfo, error) {
+	r, err := a.client.GetRepository(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return &RepoInfo{
+		Name:          r.Name,
+		FullName:      r.FullName,
+		DefaultBranch: r.DefaultBranch,
+		HTMLURL:       r.WebURL,
+	}, nil
+}
+
 func (a *GitLabAdapter) GetFileContent(ctx context.Context, repoPath, filePath, ref string) ([]byte, error) {
+	if a.archive != nil {
+		return a.archive.GetFileContent(ctx, repoPath, filePath, ref, func() ([]byte, error) {
+			return a.client.GetFileContent(ctx, repoPath, filePath, ref)
+		})
+	}
 	return a.client.GetFileContent(ctx, repoPath, filePath, ref)
 }
 
 func (a *GitLabAdapter) ListManifestFiles(ctx context.Context, repoPath, ref string) ([]string, error) {
+	if a.archive != nil {
+		return a.archive.ListManifestFiles(ctx, repoPath, ref, func() ([]string, error) {
+			return a.client.ListManifestFiles(ctx, repoPath, ref)
+		})
+	}
+	return a.client.ListManifestFiles(ctx, repoPath, ref)
+}
+
+func (a *GitLabAdapter) ListBranches(ctx context.Context, repoPath string) ([]string, error) {
+	return a.client.ListBranches(ctx, repoPath)
+}
+
+// GitAdapter adapts gitrepo.Client (plain git servers) to GitProvider
+type GitAdapter struct {
+	client *gitrepo.Client
+}
+
+func (a *GitAdapter) ListRepositories(ctx context.Context) ([]RepoInfo, error) {
+	repos, err := a.client.ListRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]RepoInfo, len(repos))
+	for i, r := range repos {
+		result[i] = RepoInfo{
+			Name:          r.Name,
+			FullName:      r.FullName,
+			DefaultBranch: r.DefaultBranch,
+			HTMLURL:       r.CloneURL,
+		}
+	}
+	return result, nil
+}
+
+func (a *GitAdapter) GetRepository(ctx context.Context, repoPath string) (*RepoInfo, error) {
+	r, err := a.client.GetRepository(ctx, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return &RepoInfo{
+		Name:          r.Name,
+		FullName:      r.FullName,
+		DefaultBranch: r.DefaultBranch,
+		HTMLURL:       r.CloneURL,
+	}, nil
+}
+
+func (a *GitAdapter) GetFileContent(ctx context.Context, repoPath, filePath, ref string) ([]byte, error) {
+	return a.client.GetFileContent(ctx, repoPath, filePath, ref)
+}
+
+func (a *GitAdapter) ListManifestFiles(ctx context.Context, repoPath, ref string) ([]string, error) {
 	return a.client.ListManifestFiles(ctx, repoPath, ref)
 }
 
+func (a *GitAdapter) ListBranches(ctx context.Context, repoPath string) ([]string, error) {
+	return a.client.ListBranches(ctx, repoPath)
+}
+
 type Scanner struct {
 	sourceRepo  *repository.SourceRepository
 	repoRepo    *repository.RepoRepository
 	depRepo     *repository.DependencyRepository
 	scanRepo    *repository.ScanRepository
+	alertRepo   *repository.AlertRepository
+	ignoredRepo *repository.IgnoredRepository
 	npmClient   *npm.Client
 	mavenClient *maven.Client
 	goClient    *golang.Client
+	swiftClient *swift.Client
+	podsClient  *cocoapods.Client
+	sdkClient   *android.Client
+	flags       *feature.Flags
+
+	providers func(domain.Source) GitProvider // replaces newProvider when set
+	registry  Registry                        // replaces the registry clients when set
+
+	deterministic bool // process sources, repositories, manifests and dependencies in name order
+
+	snapshots         bool // store the content of processed manifests of the primary branch
+	compressSnapshots bool
+
+	mu              sync.RWMutex
+	comparison      Comparison
+	fallback        bool          // compare with the last known latest version when a registry lookup fails
+	manifestRefresh time.Duration // see SetManifestRefreshInterval
+
+	ownershipFile string // repository metadata file owners are read from, besides CODEOWNERS
+
+	outbox *repository.OutboxRepository // receives vulnerability.found events when set
 }
 
 type PackageJSON struct {
-	Dependencies    map[string]string `json:"dependencies"`
-	DevDependencies map[string]string `json:"devDependencies"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	BundledDependencies  json.RawMessage   `json:"bundledDependencies"`
+	BundleDependencies   json.RawMessage   `json:"bundleDependencies"` // alias of bundledDependencies
+}
+
+// bundled returns the names of the bundled dependencies. The field is either a list of names or
+// true, which bundles every dependency.
+func (p PackageJSON) bundled() map[string]bool {
+	raw := p.BundledDependencies
+	if len(raw) == 0 {
+		raw = p.BundleDependencies
+	}
+
+	names := make(map[string]bool)
+	var all bool
+	if err := json.Unmarshal(raw, &all); err == nil {
+		if all {
+			for name := range p.Dependencies {
+				names[name] = true
+			}
+		}
+		return names
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		for _, name := range list {
+			names[name] = true
+		}
+	}
+	return names
 }
 
 // PomXML represents a Maven pom.xml file
 type PomXML struct {
 	XMLName      xml.Name `xml:"project"`
 	Dependencies struct {
-		Dependency []struct {
-			GroupID    string `xml:"groupId"`
-			ArtifactID string `xml:"artifactId"`
-			Version    string `xml:"version"`
-			Scope      string `xml:"scope"`
-		} `xml:"dependency"`
+		Dependency []PomDependency `xml:"dependency"`
 	} `xml:"dependencies"`
 	DependencyManagement struct {
 		Dependencies struct {
-			Dependency []struct {
-				GroupID    string `xml:"groupId"`
-				ArtifactID string `xml:"artifactId"`
-				Version    string `xml:"version"`
-			} `xml:"dependency"`
+			Dependency []PomDependency `xml:"dependency"`
 		} `xml:"dependencies"`
 	} `xml:"dependencyManagement"`
 }
 
+// PomDependency is a dependency declared in a pom.xml
+type PomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+// manifestFile is a manifest fetched from a repository
+type manifestFile struct {
+	path    string // path within the repository, e.g. services/api/package.json
+	kind    string // manifest type, see manifestType
+	branch  string // additional branch the manifest was fetched from (empty for the primary scan branch)
+	content []byte
+}
+
+// manifestKinds are the supported manifest types
+var manifestKinds = map[string]bool{
+	"package.json":     true,
+	"pom.xml":          true,
+	"build.gradle":     true,
+	"build.gradle.kts": true,
+	"go.mod":           true,
+	"Package.swift":    true,
+	"Package.resolved": true,
+	"Podfile":          true,
+	"Podfile.lock":     true,
+}
+
+// manifestType returns the manifest type for a manifest path (its file name)
+func manifestType(path string) string {
+	if idx := strings.LastIndex(path, "/"); idx != -1 {
+		return path[idx+1:]
+	}
+	return path
+}
+
+// IsManifest reports whether a path is a manifest scans read, e.g. web/package.json
+func IsManifest(path string) bool {
+	return manifestKinds[manifestType(path)]
+}
+
+// manifestEcosystem returns the ecosystem of the dependencies declared in a manifest type
+func manifestEcosystem(kind string) string {
+	switch kind {
+	case "package.json":
+		return "npm"
+	case "pom.xml":
+		return "maven"
+	case "build.gradle", "build.gradle.kts":
+		return "gradle"
+	case "go.mod":
+		return "go"
+	case "Package.swift", "Package.resolved":
+		return "swift"
+	case "Podfile", "Podfile.lock":
+		return "cocoapods"
+	}
+	return ""
+}
+
+// Ecosystems returns the ecosystems of the supported manifest types, sorted
+func (s *Scanner) Ecosystems() []string {
+	seen := make(map[string]bool)
+	var ecosystems []string
+	for kind := range manifestKinds {
+		if eco := manifestEcosystem(kind); eco != "" && !seen[eco] {
+			seen[eco] = true
+			ecosystems = append(ecosystems, eco)
+		}
+	}
+	sort.Strings(ecosystems)
+	return ecosystems
+}
+
 // GradleDependency represents a parsed Gradle dependency
 type GradleDependency struct {
 	Group    string
@@ -146,15 +452,29 @@ func New(
 	repoRepo *repository.RepoRepository,
 	depRepo *repository.DependencyRepository,
 	scanRepo *repository.ScanRepository,
+	alertRepo *repository.AlertRepository,
+	ignoredRepo *repository.IgnoredRepository,
+	flags *feature.Flags,
+	ownershipFile string,
+	spiToken string,
 ) *Scanner {
 	return &Scanner{
-		sourceRepo:  sourceRepo,
-		repoRepo:    repoRepo,
-		depRepo:     depRepo,
-		scanRepo:    scanRepo,
-		npmClient:   npm.New(),
-		mavenClient: maven.New(),
-		goClient:    golang.New(),
+		sourceRepo:    sourceRepo,
+		repoRepo:      repoRepo,
+		depRepo:       depRepo,
+		scanRepo:      scanRepo,
+		alertRepo:     alertRepo,
+		ignoredRepo:   ignoredRepo,
+		npmClient:     npm.New(),
+		mavenClient:   maven.New(),
+		goClient:      golang.New(),
+		swiftClient:   swift.New(spiToken),
+		podsClient:    cocoapods.New(),
+		sdkClient:     android.New(),
+		flags:         flags,
+		ownershipFile: ownershipFile,
+
+		manifestRefresh: DefaultManifestRefreshInterval,
 	}
 }
 
@@ -163,30 +483,105 @@ func (s *Scanner) ScanAll(ctx context.Context, scanID int64) error {
 	if err != nil {
 		return err
 	}
+	return s.ScanSources(ctx, scanID, sources)
+}
 
+// maxParallelSources bounds the number of sources scanned at the same time. Sources are on
+// different providers or use different tokens, so their rate limits are independent.
+const maxParallelSources = 4
+
+// ScanSources scans the given sources. Sources with a higher priority are scanned first; sources
+// of the same priority are scanned concurrently, up to maxParallelSources at a time, or one at a
+// time in name order by deterministic scanners. Failing sources are logged and skipped, and
+// disabled and external sources are not scanned.
+func (s *Scanner) ScanSources(ctx context.Context, scanID int64, sources []domain.Source) error {
 	var totalRepos, totalDeps int32
 
+	active := make([]domain.Source, 0, len(sources))
 	for _, source := range sources {
-		err := s.scanSource(ctx, source, scanID, &totalRepos, &totalDeps)
-		if err != nil {
-			log.Error().Err(err).Str("source", source.Name).Msg("failed to scan source")
+		if source.Disabled() {
+			log.Info().Str("source", source.Name).Msg("skipping source disabled after repeated scan failures")
 			continue
 		}
-		_ = s.sourceRepo.UpdateLastScan(ctx, source.ID)
+		if source.External() {
+			continue
+		}
+		active = append(active, source)
+	}
+	if s.deterministic {
+		sort.SliceStable(active, func(i, j int) bool { return active[i].Name < active[j].Name })
+	}
+
+	for _, tier := range priorityTiers(active) {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, maxParallelSources)
+
+		for _, source := range tier {
+			wg.Add(1)
+			go func(source domain.Source) {
+				defer wg.Done()
+				defer func() {
+					if r := recover(); r != nil {
+						log.Error().Interface("panic", r).Str("source", source.Name).Msg("panic while scanning source")
+					}
+				}()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				if ctx.Err() != nil {
+					return
+				}
+				if err := s.scanSource(ctx, source, scanID, &totalRepos, &totalDeps); err != nil {
+					log.Error().Err(err).Str("source", source.Name).Msg("failed to scan source")
+					s.recordScanError(ctx, source, err)
+					return
+				}
+				_ = s.sourceRepo.UpdateLastScan(ctx, source.ID)
+			}(source)
+			if s.deterministic {
+				wg.Wait()
+			}
+		}
+
+		wg.Wait()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 	}
 
 	return nil
 }
 
+// priorityTiers groups sources by priority, highest first, keeping their order within a tier
+func priorityTiers(sources []domain.Source) [][]domain.Source {
+	sorted := make([]domain.Source, len(sources))
+	copy(sorted, sources)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Priority > sorted[j].Priority })
+
+	var tiers [][]domain.Source
+	for i, source := range sorted {
+		if i == 0 || source.Priority != sorted[i-1].Priority {
+			tiers = append(tiers, nil)
+		}
+		tiers[len(tiers)-1] = append(tiers[len(tiers)-1], source)
+	}
+	return tiers
+}
+
 func (s *Scanner) ScanSource(ctx context.Context, sourceID, scanID int64) error {
 	source, err := s.sourceRepo.GetByID(ctx, sourceID)
 	if err != nil {
 		return err
 	}
+	if source.External() {
+		log.Info().Str("source", source.Name).Msg("skipping external source - its dependencies are ingested")
+		return nil
+	}
 
 	var totalRepos, totalDeps int32
 	err = s.scanSource(ctx, *source, scanID, &totalRepos, &totalDeps)
 	if err != nil {
+		s.recordScanError(ctx, *source, err)
 		return err
 	}
 
@@ -194,25 +589,344 @@ func (s *Scanner) ScanSource(ctx context.Context, sourceID, scanID int64) error
 	return nil
 }
 
-func (s *Scanner) scanSource(ctx context.Context, source domain.Source, scanID int64, totalRepos, totalDeps *int32) error {
-	var provider GitProvider
+// recordScanError stores the error of a failed source scan on the source so it can be reported.
+// Canceled scans are not failures of the source.
+func (s *Scanner) recordScanError(ctx context.Context, source domain.Source, scanErr error) {
+	if ctx.Err() != nil {
+		return
+	}
+	if err := s.sourceRepo.SetScanError(ctx, source.ID, scanErr.Error()); err != nil {
+		log.Warn().Err(err).Str("source", source.Name).Msg("failed to record source scan error")
+	}
+}
 
+// DependenciesAtRef fetches and parses the manifests of a repository at ref without
+// looking up latest versions or persisting anything
+func (s *Scanner) DependenciesAtRef(ctx context.Context, source domain.Source, repoFullName, ref string) ([]domain.Dependency, error) {
+	manifests, err := s.fetchManifests(ctx, s.provider(source), repoFullName, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []domain.Dependency
+	for _, manifest := range manifests {
+		parsed, _, err := parseManifest(manifest)
+		if err != nil {
+			log.Warn().Err(err).Str("repo", repoFullName).Str("ref", ref).Str("path", manifest.path).Msg("failed to parse manifest")
+			continue
+		}
+		deps = append(deps, parsed...)
+	}
+
+	return deps, nil
+}
+
+// ScanRepository scans a single repository of a source immediately and returns its dependencies.
+// An empty branch scans the repository's default branch. Nothing is stored; see PersistRepositoryScan.
+func (s *Scanner) ScanRepository(ctx context.Context, source domain.Source, repoFullName, branch string) (_ *domain.AdhocScanResult, err error) {
+	ctx, span := tracing.Start(ctx, "scan.adhoc", attribute.String("source.name", source.Name), attribute.String("repository", repoFullName))
+	defer func() { tracing.End(span, err) }()
+
+	provider := s.provider(source)
+
+	info, err := provider.GetRepository(ctx, repoFullName)
+	if err != nil {
+		return nil, err
+	}
+	if branch == "" {
+		branch = info.DefaultBranch
+	}
+
+	manifests, err := s.fetchManifests(ctx, provider, info.FullName, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.AdhocScanResult{
+		Repository: domain.Repository{
+			SourceID:      source.ID,
+			Name:          info.Name,
+			FullName:      info.FullName,
+			DefaultBranch: branch,
+			HTMLURL:       info.HTMLURL,
+		},
+		Manifests:    []string{},
+		Dependencies: []domain.Dependency{},
+	}
+	setManifestFlags(&result.Repository, manifests)
+	s.recordGoRuntime(ctx, provider, &result.Repository, branch, manifests)
+	owners, ownersSource := s.repositoryOwners(ctx, provider, info.FullName, branch)
+	result.Repository.Owners, result.Repository.OwnersSource = strings.Join(owners, ","), ownersSource
+
+	for _, manifest := range manifests {
+		deps, _, err := parseManifest(manifest)
+		if err != nil {
+			log.Warn().Err(err).Str("repo", info.FullName).Str("path", manifest.path).Msg("failed to parse manifest")
+			continue
+		}
+		result.Manifests = append(result.Manifests, manifest.path)
+		result.Dependencies = append(result.Dependencies, deps...)
+	}
+
+	_ = s.resolveLatestVersions(ctx, result.Dependencies)
+	for _, dep := range result.Dependencies {
+		if dep.IsOutdated {
+			result.OutdatedCount++
+		}
+	}
+	result.Repository.DependencyCount = len(result.Dependencies)
+	result.Repository.OutdatedCount = result.OutdatedCount
+
+	return result, nil
+}
+
+// PersistRepositoryScan stores the result of an ad-hoc scan under the given source
+func (s *Scanner) PersistRepositoryScan(ctx context.Context, sourceID int64, result *domain.AdhocScanResult) error {
+	repoScanStart := time.Now()
+	result.Repository.SourceID = sourceID
+	repoID, err := s.repoRepo.Upsert(ctx, result.Repository)
+	if err != nil {
+		return err
+	}
+	result.Repository.ID = repoID
+
+	for i := range result.Dependencies {
+		result.Dependencies[i].RepositoryID = repoID
+		if err := s.depRepo.Upsert(ctx, result.Dependencies[i]); err != nil {
+			return err
+		}
+	}
+	if _, err := s.depRepo.DeleteStaleByRepoID(ctx, repoID, repoScanStart); err != nil {
+		log.Warn().Err(err).Str("repo", result.Repository.FullName).Msg("failed to delete stale dependencies")
+	}
+	result.Persisted = true
+	return nil
+}
+
+// PreviewRepository inspects a repository of a source without persisting anything, returning
+// its manifests and the number of dependencies a scan would record. Latest versions are not looked up.
+func (s *Scanner) PreviewRepository(ctx context.Context, source domain.Source, repoFullName string) (*domain.RepositoryPreview, error) {
+	provider := s.provider(source)
+
+	info, err := provider.GetRepository(ctx, repoFullName)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &domain.RepositoryPreview{
+		Repository: domain.Repository{
+			SourceID:      source.ID,
+			Name:          info.Name,
+			FullName:      info.FullName,
+			DefaultBranch: info.DefaultBranch,
+			HTMLURL:       info.HTMLURL,
+		},
+		Manifests: []domain.ManifestSummary{},
+		Settings: domain.RepositorySettings{
+			ScanBranch:         info.DefaultBranch,
+			AdditionalBranches: source.AdditionalBranches,
+		},
+	}
+
+	// Apply the override of an already tracked repository
+	if overrides, err := s.repoRepo.GetBranchOverrides(ctx, source.ID); err == nil {
+		preview.Settings.BranchOverride = overrides[info.FullName]
+	}
+
+	// A scan uses the first of the fallback branches that has manifests
+	branches := scanBranches(source, info.DefaultBranch, preview.Settings.BranchOverride)
+	manifests, branch, err := s.fetchFirstManifests(ctx, provider, info.FullName, branches)
+	if err != nil {
+		return nil, err
+	}
+	preview.Settings.ScanBranch = branch
+	setManifestFlags(&preview.Repository, manifests)
+	preview.Repository.DefaultBranch = preview.Settings.ScanBranch
+
+	for _, manifest := range manifests {
+		deps, _, err := parseManifest(manifest)
+		if err != nil {
+			log.Warn().Err(err).Str("repo", info.FullName).Str("path", manifest.path).Msg("failed to parse manifest")
+			continue
+		}
+		preview.Manifests = append(preview.Manifests, domain.ManifestSummary{
+			Path:            manifest.path,
+			Type:            manifest.kind,
+			Ecosystem:       manifestEcosystem(manifest.kind),
+			DependencyCount: len(deps),
+		})
+		preview.EstimatedDependencies += len(deps)
+	}
+	preview.Repository.DependencyCount = preview.EstimatedDependencies
+
+	sort.Slice(preview.Manifests, func(i, j int) bool {
+		return preview.Manifests[i].Path < preview.Manifests[j].Path
+	})
+
+	return preview, nil
+}
+
+// newProvider creates the Git provider client for a source
+func newProvider(source domain.Source) GitProvider {
 	switch source.Type {
 	case "gitlab":
 		glClient := gitlab.New(source.Token, source.URL, source.Organization, source.InsecureSkipVerify, source.MembershipOnly)
-		provider = &GitLabAdapter{client: glClient}
+		adapter := &GitLabAdapter{client: glClient}
+		if source.FetchMode == domain.FetchModeArchive {
+			adapter.archive = &manifestArchive{download: glClient.DownloadArchive}
+		}
+		return adapter
+	case "git":
+		// Git sources always clone the repository, so the fetch mode does not apply
+		return &GitAdapter{client: gitrepo.New(source.Token, source.URL, source.Repositories, source.InsecureSkipVerify)}
 	default: // github
 		ghClient := github.New(source.Token, source.Organization, source.OwnerOnly)
-		provider = &GitHubAdapter{client: ghClient}
+		adapter := &GitHubAdapter{client: ghClient, org: source.Organization != ""}
+		if source.FetchMode == domain.FetchModeArchive {
+			adapter.archive = &manifestArchive{download: ghClient.DownloadArchive}
+		}
+		return adapter
 	}
+}
+
+// provider returns the Git provider of a source
+func (s *Scanner) provider(source domain.Source) GitProvider {
+	if s.providers != nil {
+		return s.providers(source)
+	}
+	return newProvider(source)
+}
+
+// SetProviders makes the scanner reach sources through the providers returned by newProvider
+// instead of their hosts, e.g. in-memory providers in tests
+func (s *Scanner) SetProviders(newProvider func(domain.Source) GitProvider) {
+	s.providers = newProvider
+}
+
+// SetDeterministic makes scans process sources one at a time and sources, repositories, manifests
+// and dependencies in name order, so scans of unchanged repositories record the same rows in the
+// same order. Sources are no longer scanned concurrently.
+func (s *Scanner) SetDeterministic(deterministic bool) {
+	s.deterministic = deterministic
+}
+
+// SetManifestSnapshots sets whether scans store the content of the manifests of the primary branch
+// they process, gzipped when compress is set, so what was parsed can be inspected later
+func (s *Scanner) SetManifestSnapshots(enabled, compress bool) {
+	s.snapshots = enabled
+	s.compressSnapshots = compress
+}
 
-	repos, err := provider.ListRepositories(ctx)
+// SetOutbox makes scans append a vulnerability.found event to outbox for each alert imported for
+// the first time
+func (s *Scanner) SetOutbox(outbox *repository.OutboxRepository) {
+	s.outbox = outbox
+}
+
+// SetRegistry makes the scanner look up latest versions in registry instead of the public registries
+func (s *Scanner) SetRegistry(registry Registry) {
+	s.registry = registry
+}
+
+// sourceScan is the state of a source being scanned
+type sourceScan struct {
+	source             domain.Source
+	provider           GitProvider
+	repos              []RepoInfo
+	branchOverrides    map[string]string
+	additionalBranches []string
+	usage              *httputil.Usage // provider API usage of the source during the scan
+	span               trace.Span      // parent of the spans of the source's repositories
+
+	knownRepos      map[string]bool
+	reportDiscovery bool
+	newRepos        int
+	removedRepos    []string
+}
+
+func (s *Scanner) scanSource(ctx context.Context, source domain.Source, scanID int64, totalRepos, totalDeps *int32) error {
+	usage := &httputil.Usage{}
+	st, err := s.prepareSourceScan(ctx, source, scanID, usage)
 	if err != nil {
+		s.recordUsage(ctx, scanID, source, usage)
 		return err
 	}
+	defer s.finishSourceScan(ctx, st, scanID)
+	s.addReposTotal(ctx, scanID, len(st.repos))
+
+	for _, repo := range st.repos {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.scanSourceRepo(ctx, st, repo, scanID, totalRepos, totalDeps)
+	}
+	return nil
+}
+
+// addReposTotal adds the repositories listed for a source to the repositories a scan covers, the
+// denominator of its progress
+func (s *Scanner) addReposTotal(ctx context.Context, scanID int64, repos int) {
+	if repos == 0 {
+		return
+	}
+	if err := s.scanRepo.AddReposTotal(ctx, scanID, repos); err != nil {
+		log.Warn().Err(err).Int64("scan_id", scanID).Msg("failed to record repositories of scan")
+	}
+}
+
+// prepareSourceScan lists the repositories of a source and marks the ones that disappeared as removed.
+// Provider API calls of the source are counted in usage.
+func (s *Scanner) prepareSourceScan(ctx context.Context, source domain.Source, scanID int64, usage *httputil.Usage) (*sourceScan, error) {
+	provider := s.provider(source)
+
+	ctx, span := tracing.Start(ctx, "scan.source",
+		attribute.Int64("source.id", source.ID),
+		attribute.String("source.name", source.Name),
+		attribute.String("source.type", source.Type),
+	)
+	repos, err := provider.ListRepositories(httputil.WithUsage(ctx, usage))
+	if err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("source.repositories", len(repos)))
 
 	log.Info().Int("total_repos", len(repos)).Str("source", source.Name).Msg("fetched repositories from source")
 
+	st := &sourceScan{
+		source:     source,
+		provider:   provider,
+		knownRepos: make(map[string]bool),
+		usage:      usage,
+		span:       span,
+	}
+
+	// Compare with the repositories of the previous scan to detect new and removed ones.
+	// The first scan of a source imports everything, so discoveries are only reported afterwards.
+	st.reportDiscovery = source.LastScanAt != nil
+	existing, err := s.repoRepo.GetBySourceID(ctx, source.ID)
+	if err != nil {
+		log.Warn().Err(err).Str("source", source.Name).Msg("failed to load known repositories")
+		st.reportDiscovery = false
+	}
+	for _, r := range existing {
+		if r.RemovedAt == nil {
+			st.knownRepos[strings.ToLower(r.FullName)] = true
+		}
+	}
+
+	listed := make([]string, len(repos))
+	for i, repo := range repos {
+		listed[i] = repo.FullName
+	}
+	st.removedRepos, err = s.repoRepo.MarkRemoved(ctx, source.ID, listed)
+	if err != nil {
+		log.Warn().Err(err).Str("source", source.Name).Msg("failed to mark removed repositories")
+	}
+	for _, name := range st.removedRepos {
+		log.Info().Str("repo", name).Str("source", source.Name).Msg("repository no longer found in source")
+	}
+
 	// Filter repos if specific repositories are configured
 	if source.Repositories != "" {
 		beforeFilter := len(repos)
@@ -222,346 +936,864 @@ func (s *Scanner) scanSource(ctx context.Context, source domain.Source, scanID i
 
 	if len(repos) == 0 {
 		log.Warn().Str("source", source.Name).Msg("no repositories to scan")
-		return nil
+		return st, nil
 	}
+	if s.deterministic {
+		sort.Slice(repos, func(i, j int) bool { return repos[i].FullName < repos[j].FullName })
+	}
+	st.repos = repos
 
-	for _, repo := range repos {
-		// Use source.ScanBranch if set, otherwise use repo's default branch
-		scanBranch := repo.DefaultBranch
-		if source.ScanBranch != "" {
-			scanBranch = source.ScanBranch
+	// Per-repository branch overrides take precedence over the source scan branch
+	st.branchOverrides, err = s.repoRepo.GetBranchOverrides(ctx, source.ID)
+	if err != nil {
+		log.Warn().Err(err).Str("source", source.Name).Msg("failed to load branch overrides")
+	}
+
+	st.additionalBranches = parseBranchList(source.AdditionalBranches)
+	return st, nil
+}
+
+// finishSourceScan records the repositories discovered and removed and the API usage of a source
+func (s *Scanner) finishSourceScan(ctx context.Context, st *sourceScan, scanID int64) {
+	defer st.span.End()
+	st.span.SetAttributes(attribute.Int64("source.api_calls", st.usage.Calls()))
+	s.recordUsage(ctx, scanID, st.source, st.usage)
+
+	newRepos := st.newRepos
+	if !st.reportDiscovery {
+		newRepos = 0
+	}
+	if newRepos > 0 || len(st.removedRepos) > 0 {
+		if err := s.scanRepo.AddDiscovery(ctx, scanID, newRepos, len(st.removedRepos)); err != nil {
+			log.Warn().Err(err).Int64("scan_id", scanID).Msg("failed to record repository discovery")
 		}
+	}
+}
 
-		log.Info().Str("repo", repo.FullName).Str("branch", scanBranch).Msg("scanning repository")
-		repoEntity := domain.Repository{
-			SourceID:      source.ID,
-			Name:          repo.Name,
-			FullName:      repo.FullName,
-			DefaultBranch: scanBranch,
-			HTMLURL:       repo.HTMLURL,
+// recordUsage stores the provider API usage of a source on the scan
+func (s *Scanner) recordUsage(ctx context.Context, scanID int64, source domain.Source, usage *httputil.Usage) {
+	if usage.Calls() == 0 {
+		return
+	}
+	err := s.scanRepo.AddSourceUsage(ctx, domain.ScanSourceUsage{
+		ScanID:       scanID,
+		SourceID:     source.ID,
+		SourceName:   source.Name,
+		APICalls:     usage.Calls(),
+		BytesFetched: usage.Bytes(),
+	})
+	if err != nil {
+		log.Warn().Err(err).Int64("scan_id", scanID).Str("source", source.Name).Msg("failed to record API usage")
+	}
+	log.Info().Str("source", source.Name).Int64("api_calls", usage.Calls()).Int64("bytes", usage.Bytes()).Msg("provider API usage")
+}
+
+// scanSourceRepo scans a single repository of a source and stores its dependencies
+func (s *Scanner) scanSourceRepo(ctx context.Context, st *sourceScan, repo RepoInfo, scanID int64, totalRepos, totalDeps *int32) {
+	source := st.source
+
+	// The source span is kept on the scan state rather than in the caller's ctx
+	ctx, span := tracing.Start(trace.ContextWithSpan(ctx, st.span), "scan.repository",
+		attribute.String("repository", repo.FullName),
+	)
+	defer span.End()
+	providerCtx := httputil.WithUsage(ctx, st.usage)
+
+	branches := scanBranches(source, repo.DefaultBranch, st.branchOverrides[repo.FullName])
+	log.Info().Str("repo", repo.FullName).Strs("branches", branches).Msg("scanning repository")
+	repoEntity := domain.Repository{
+		SourceID:      source.ID,
+		Name:          repo.Name,
+		FullName:      repo.FullName,
+		DefaultBranch: branches[0],
+		HTMLURL:       repo.HTMLURL,
+	}
+
+	manifests, scanBranch, err := s.fetchFirstManifests(providerCtx, st.provider, repo.FullName, branches)
+	span.SetAttributes(attribute.String("branch", scanBranch))
+	if err != nil {
+		tracing.RecordError(span, err)
+		log.Error().Err(err).Str("repo", repo.FullName).Msg("failed to fetch manifests")
+		s.recordRepoScanError(ctx, repoEntity, "failed to fetch manifests from "+err.Error())
+		return
+	}
+	// The branch the manifests were found on is recorded as the scanned branch
+	repoEntity.DefaultBranch = scanBranch
+	if scanBranch != branches[0] {
+		log.Info().Str("repo", repo.FullName).Str("branch", scanBranch).Msg("scanning fallback branch")
+	}
+
+	// Skip if no manifest found
+	if len(manifests) == 0 {
+		log.Info().Str("repo", repo.FullName).Msg("no valid manifest content found")
+		if err := s.repoRepo.ClearScanError(ctx, repo.FullName); err != nil {
+			log.Warn().Err(err).Str("repo", repo.FullName).Msg("failed to clear repository scan error")
 		}
+		return
+	}
 
-		var repoDeps int32
+	setManifestFlags(&repoEntity, manifests)
+	s.recordGoRuntime(providerCtx, st.provider, &repoEntity, scanBranch, manifests)
+	owners, ownersSource := s.repositoryOwners(providerCtx, st.provider, repo.FullName, scanBranch)
+	repoEntity.Owners, repoEntity.OwnersSource = strings.Join(owners, ","), ownersSource
 
-		// List all manifest files in the repository (supports multi-module projects)
-		manifestPaths, err := provider.ListManifestFiles(ctx, repo.FullName, scanBranch)
-		if err != nil {
-			log.Warn().Err(err).Str("repo", repo.FullName).Msg("failed to list manifest files, falling back to root scan")
-			// Fallback to root-level scan if tree listing fails
-			manifestPaths = []string{"package.json", "pom.xml", "build.gradle", "build.gradle.kts", "go.mod"}
+	// Record scan start time for this repo (used to detect stale dependencies)
+	repoScanStart := time.Now()
+
+	// Upsert repository
+	repoID, err := s.repoRepo.Upsert(ctx, repoEntity)
+	if err != nil {
+		log.Error().Err(err).Str("repo", repo.FullName).Msg("failed to upsert repository")
+		return
+	}
+
+	if !st.knownRepos[strings.ToLower(repo.FullName)] {
+		st.newRepos++
+		log.Info().Str("repo", repo.FullName).Str("source", source.Name).Msg("discovered new repository")
+	}
+
+	// Process all manifest files (supports multi-module projects)
+	repoDeps, parseErr := s.processManifests(ctx, repoID, repo.FullName, manifests)
+	if parseErr != nil {
+		s.recordRepoScanError(ctx, repoEntity, parseErr.Error())
+	}
+
+	// Delete stale dependencies (those not updated in this scan)
+	// This removes dependencies that were removed from the manifest
+	if deleted, err := s.depRepo.DeleteStaleByRepoID(ctx, repoID, repoScanStart); err != nil {
+		log.Warn().Err(err).Str("repo", repo.FullName).Msg("failed to delete stale dependencies")
+	} else if deleted > 0 {
+		log.Info().Str("repo", repo.FullName).Int64("deleted", deleted).Msg("removed stale dependencies")
+	}
+
+	// Dependency sets of additional branches are tracked separately from the primary branch
+	if s.flags.Enabled(ctx, feature.AdditionalBranches) {
+		s.scanAdditionalBranches(providerCtx, st.provider, repoID, repo.FullName, scanBranch, st.additionalBranches)
+	}
+
+	if alertProvider, ok := st.provider.(AlertProvider); ok && source.ImportDependabotAlerts && s.flags.Enabled(ctx, feature.VulnerabilityAlerts) {
+		s.importAlerts(providerCtx, alertProvider, repoID, repo.FullName)
+	}
+
+	if s.flags.Enabled(ctx, feature.IgnoreSuggestions) {
+		s.recordIgnoreSuggestions(providerCtx, st.provider, repoID, repo.FullName, scanBranch)
+	}
+
+	span.SetAttributes(attribute.Int("dependencies", int(repoDeps)))
+	atomic.AddInt32(totalRepos, 1)
+	atomic.AddInt32(totalDeps, repoDeps)
+	log.Info().Str("repo", repo.FullName).Int32("deps", repoDeps).Msg("repository scanned successfully")
+
+	// Update stats in real-time after each repository
+	_ = s.scanRepo.UpdateStats(ctx, scanID, int(atomic.LoadInt32(totalRepos)), int(atomic.LoadInt32(totalDeps)))
+}
+
+// importAlerts replaces the stored vulnerability alerts of a repository with those reported by the provider.
+// Failures (e.g. Dependabot alerts disabled or missing token permissions) are logged and do not fail the scan.
+func (s *Scanner) importAlerts(ctx context.Context, provider AlertProvider, repoID int64, repoFullName string) {
+	alerts, err := provider.ListAlerts(ctx, repoFullName)
+	if err != nil {
+		log.Warn().Err(err).Str("repo", repoFullName).Msg("failed to fetch vulnerability alerts")
+		return
+	}
+	added, err := s.alertRepo.ReplaceForRepo(ctx, repoID, alerts)
+	if err != nil {
+		log.Warn().Err(err).Str("repo", repoFullName).Msg("failed to save vulnerability alerts")
+		return
+	}
+	if len(alerts) > 0 {
+		log.Info().Str("repo", repoFullName).Int("alerts", len(alerts)).Msg("imported vulnerability alerts")
+	}
+
+	if s.outbox == nil {
+		return
+	}
+	events := make([]domain.OutboxEvent, 0, len(added))
+	for _, a := range added {
+		a.RepositoryID = repoID
+		if event, err := domain.NewOutboxEvent(domain.OutboxVulnerabilityFound, domain.FoundVulnerability{VulnerabilityAlert: a, Repository: repoFullName}); err == nil {
+			events = append(events, event)
+		}
+	}
+	if err := s.outbox.Append(ctx, events); err != nil {
+		log.Warn().Err(err).Str("repo", repoFullName).Msg("failed to append vulnerability events to the outbox")
+	}
+}
+
+// setManifestFlags marks which kinds of manifests were found in a repository
+func setManifestFlags(repo *domain.Repository, manifests []manifestFile) {
+	for _, manifest := range manifests {
+		switch manifest.kind {
+		case "package.json":
+			repo.HasPackageJSON = true
+		case "pom.xml":
+			repo.HasPomXML = true
+		case "build.gradle", "build.gradle.kts":
+			repo.HasBuildGradle = true
+		case "go.mod":
+			repo.HasGoMod = true
+		case "Package.swift", "Package.resolved":
+			repo.HasSwiftPM = true
+		case "Podfile", "Podfile.lock":
+			repo.HasPodfile = true
+		}
+	}
+}
+
+// fetchManifests lists and downloads all supported manifest files of a repository at ref.
+// Only manifests whose content could be fetched are returned. An error is returned when the
+// manifest files could not be listed and the root-level fallback found nothing either
+// (e.g. the ref does not exist).
+func (s *Scanner) fetchManifests(ctx context.Context, provider GitProvider, repoFullName, ref string) (manifests []manifestFile, err error) {
+	ctx, span := tracing.Start(ctx, "scan.fetch_manifests", attribute.String("repository", repoFullName), attribute.String("ref", ref))
+	defer func() {
+		span.SetAttributes(attribute.Int("manifests", len(manifests)))
+		tracing.End(span, err)
+	}()
+
+	// List all manifest files in the repository (supports multi-module projects)
+	manifestPaths, listErr := provider.ListManifestFiles(ctx, repoFullName, ref)
+	if listErr != nil {
+		log.Warn().Err(listErr).Str("repo", repoFullName).Str("ref", ref).Msg("failed to list manifest files, falling back to root scan")
+		// Fallback to root-level scan if tree listing fails
+		manifestPaths = []string{"package.json", "pom.xml", "build.gradle", "build.gradle.kts", "go.mod", "Package.resolved", "Podfile.lock"}
+	}
+
+	if len(manifestPaths) == 0 {
+		log.Info().Str("repo", repoFullName).Str("ref", ref).Msg("no supported manifest file found (package.json, pom.xml, build.gradle, go.mod, Package.swift, Podfile)")
+		return nil, nil
+	}
+	manifestPaths = preferLockfiles(manifestPaths)
+
+	log.Info().Str("repo", repoFullName).Str("ref", ref).Int("count", len(manifestPaths)).Strs("files", manifestPaths).Msg("found manifest files")
+
+	// Fetch all manifest files in parallel
+	results := make(chan manifestFile, len(manifestPaths))
+	for _, path := range manifestPaths {
+		go func(p string) {
+			// Recover from panics to prevent server crash
+			defer func() {
+				if r := recover(); r != nil {
+					log.Error().Interface("panic", r).Str("repo", repoFullName).Str("path", p).Msg("panic in manifest fetch goroutine")
+					results <- manifestFile{path: p}
+				}
+			}()
+			content, err := provider.GetFileContent(ctx, repoFullName, p, ref)
+			if err != nil {
+				log.Debug().Err(err).Str("repo", repoFullName).Str("path", p).Msg("failed to fetch manifest")
+				results <- manifestFile{path: p}
+			} else {
+				results <- manifestFile{path: p, content: content}
+			}
+		}(path)
+	}
+
+	for i := 0; i < len(manifestPaths); i++ {
+		result := <-results
+		if result.content == nil {
+			continue
+		}
+
+		// Determine manifest type from filename
+		result.kind = manifestType(result.path)
+
+		if manifestKinds[result.kind] {
+			manifests = append(manifests, result)
+		}
+	}
+
+	if listErr != nil && len(manifests) == 0 {
+		return nil, listErr
+	}
+	if s.deterministic {
+		sort.Slice(manifests, func(i, j int) bool { return manifests[i].path < manifests[j].path })
+	}
+
+	return manifests, nil
+}
+
+// processManifests parses the manifests and records their dependencies, returning the number recorded
+// and the errors of manifests that failed to parse. Manifests of the primary branch whose content is unchanged since they were last parsed reuse
+// their parsed dependencies, whose latest versions are still looked up; see SetManifestRefreshInterval.
+// The dependencies of the primary branch that were skipped or could not be checked are recorded
+// with their reason.
+func (s *Scanner) processManifests(ctx context.Context, repoID int64, repoFullName string, manifests []manifestFile) (int32, error) {
+	var repoDeps int32
+	var parseErrs []error
+
+	primary := len(manifests) > 0 && manifests[0].branch == ""
+	var hashes map[string]domain.ManifestHash
+	var previousSkipped []domain.SkippedDependency
+	if primary {
+		var err error
+		if hashes, err = s.depRepo.GetManifestHashes(ctx, repoID); err != nil {
+			log.Warn().Err(err).Str("repo", repoFullName).Msg("failed to load manifest hashes")
+		}
+		if len(hashes) > 0 {
+			if previousSkipped, err = s.depRepo.GetSkippedByRepoID(ctx, repoID); err != nil {
+				log.Warn().Err(err).Str("repo", repoFullName).Msg("failed to load skipped dependencies")
+				hashes = nil // without them, reused manifests would lose their skipped dependencies
+			}
+		}
+	}
+
+	var paths []string
+	for _, manifest := range manifests {
+		hash := contentHash(manifest.content)
+		paths = append(paths, manifest.path)
+
+		var deps []domain.Dependency
+		var skipped []domain.SkippedDependency
+		reused := false
+		if primary {
+			if deps = s.carryForward(ctx, repoID, manifest, hash, hashes); len(deps) > 0 {
+				log.Debug().Str("repo", repoFullName).Str("path", manifest.path).Int("deps", len(deps)).Msg("manifest unchanged, reusing its parsed dependencies")
+				skipped = parseSkipped(previousSkipped, manifest.path, deps)
+				reused = true
+			}
+		}
+
+		if !reused {
+			log.Debug().Str("repo", repoFullName).Str("path", manifest.path).Msg("processing " + manifest.kind)
+			if primary && s.snapshots {
+				if err := s.depRepo.SetManifestContent(ctx, repoID, manifest.path, manifest.content, s.compressSnapshots); err != nil {
+					log.Warn().Err(err).Str("repo", repoFullName).Str("path", manifest.path).Msg("failed to store manifest content")
+				}
+			}
+			var err error
+			deps, skipped, err = parseManifest(manifest)
+			if err != nil {
+				log.Warn().Err(err).Str("repo", repoFullName).Str("path", manifest.path).Msg("failed to parse manifest")
+				parseErrs = append(parseErrs, fmt.Errorf("failed to parse %s: %w", manifest.path, err))
+				continue
+			}
+			for i := range deps {
+				deps[i].RepositoryID = repoID
+			}
+		}
+		if s.deterministic {
+			sortDependencies(deps)
+		}
+		n, unchecked := s.recordDependencies(ctx, deps)
+		repoDeps += n
+
+		if primary {
+			if len(skipped)+len(unchecked) > 0 {
+				log.Info().Str("repo", repoFullName).Str("path", manifest.path).Int("skipped", len(skipped)).Int("unchecked", len(unchecked)).Msg("some dependencies were skipped or could not be checked")
+			}
+			if err := s.depRepo.ReplaceSkipped(ctx, repoID, manifest.path, append(skipped, unchecked...)); err != nil {
+				log.Warn().Err(err).Str("repo", repoFullName).Str("path", manifest.path).Msg("failed to record skipped dependencies")
+			}
+			// The hash keeps the time the manifest was last parsed, which the refresh interval counts from
+			if !reused {
+				if err := s.depRepo.SetManifestHash(ctx, repoID, manifest.path, hash); err != nil {
+					log.Warn().Err(err).Str("repo", repoFullName).Str("path", manifest.path).Msg("failed to record manifest hash")
+				}
+			}
 		}
+	}
 
-		if len(manifestPaths) == 0 {
-			log.Info().Str("repo", repo.FullName).Msg("no supported manifest file found (package.json, pom.xml, build.gradle, go.mod)")
-			continue
+	if primary {
+		if err := s.depRepo.DeleteManifestHashesExcept(ctx, repoID, paths); err != nil {
+			log.Warn().Err(err).Str("repo", repoFullName).Msg("failed to remove hashes of deleted manifests")
+		}
+		if err := s.depRepo.DeleteSkippedExcept(ctx, repoID, paths); err != nil {
+			log.Warn().Err(err).Str("repo", repoFullName).Msg("failed to remove skipped dependencies of deleted manifests")
+		}
+		if err := s.depRepo.DeleteManifestContentsExcept(ctx, repoID, paths); err != nil {
+			log.Warn().Err(err).Str("repo", repoFullName).Msg("failed to remove content of deleted manifests")
 		}
+	}
 
-		log.Info().Str("repo", repo.FullName).Int("count", len(manifestPaths)).Strs("files", manifestPaths).Msg("found manifest files")
+	return repoDeps, errors.Join(parseErrs...)
+}
 
-		// Fetch all manifest files in parallel
-		type manifestResult struct {
-			path    string
-			content []byte
-		}
+// recordRepoScanError stores the error of the last scan of a repository so it is listed with the
+// repository. Canceled scans are not recorded.
+func (s *Scanner) recordRepoScanError(ctx context.Context, repo domain.Repository, scanErr string) {
+	if ctx.Err() != nil {
+		return
+	}
+	if err := s.repoRepo.SetScanError(ctx, repo, scanErr); err != nil {
+		log.Warn().Err(err).Str("repo", repo.FullName).Msg("failed to record repository scan error")
+	}
+}
 
-		results := make(chan manifestResult, len(manifestPaths))
-		for _, path := range manifestPaths {
-			go func(p string) {
-				// Recover from panics to prevent server crash
-				defer func() {
-					if r := recover(); r != nil {
-						log.Error().Interface("panic", r).Str("repo", repo.FullName).Str("path", p).Msg("panic in manifest fetch goroutine")
-						results <- manifestResult{p, nil}
-					}
-				}()
-				content, err := provider.GetFileContent(ctx, repo.FullName, p, scanBranch)
-				if err != nil {
-					log.Debug().Err(err).Str("repo", repo.FullName).Str("path", p).Msg("failed to fetch manifest")
-					results <- manifestResult{p, nil}
-				} else {
-					results <- manifestResult{p, content}
-				}
-			}(path)
-		}
+// carryForward returns the dependencies parsed from a manifest whose content matches the recorded
+// hash, without the results of their registry lookups, so the manifest is not parsed again. None
+// means the manifest must be parsed.
+func (s *Scanner) carryForward(ctx context.Context, repoID int64, manifest manifestFile, hash string, hashes map[string]domain.ManifestHash) []domain.Dependency {
+	previous, ok := hashes[manifest.path]
+	if !ok || previous.ContentHash != hash || time.Since(previous.HashedAt) > s.ManifestRefreshInterval() {
+		return nil
+	}
 
-		// Collect results and categorize by manifest type
-		var packageJSONFiles, pomXMLFiles, gradleFiles, goModFiles []manifestResult
-		for i := 0; i < len(manifestPaths); i++ {
-			result := <-results
-			if result.content == nil {
-				continue
-			}
+	deps, err := s.depRepo.GetByManifest(ctx, repoID, manifest.path)
+	if err != nil {
+		log.Warn().Err(err).Str("path", manifest.path).Msg("failed to load the dependencies of an unchanged manifest")
+		return nil
+	}
+	for i := range deps {
+		d := &deps[i]
+		d.LatestVersion, d.LatestInMajor, d.RecommendedVersion = "", "", ""
+		d.Risks, d.RiskDetail = "", ""
+		d.IsOutdated, d.StaleData, d.FirstOutdatedAt = false, false, nil
+	}
+	return deps
+}
 
-			// Determine manifest type from filename
-			filename := result.path
-			if idx := strings.LastIndex(result.path, "/"); idx != -1 {
-				filename = result.path[idx+1:]
-			}
+// parseSkipped returns the skipped dependencies recorded for a manifest when it was parsed. Those
+// of the recorded dependencies, which could not be checked, are left out as they are checked again.
+func parseSkipped(skipped []domain.SkippedDependency, manifestPath string, deps []domain.Dependency) []domain.SkippedDependency {
+	recorded := make(map[string]bool, len(deps))
+	for _, d := range deps {
+		recorded[d.Ecosystem+":"+d.Name+"@"+d.CurrentVersion] = true
+	}
 
-			switch filename {
-			case "package.json":
-				packageJSONFiles = append(packageJSONFiles, result)
-				repoEntity.HasPackageJSON = true
-			case "pom.xml":
-				pomXMLFiles = append(pomXMLFiles, result)
-				repoEntity.HasPomXML = true
-			case "build.gradle", "build.gradle.kts":
-				gradleFiles = append(gradleFiles, result)
-				repoEntity.HasBuildGradle = true
-			case "go.mod":
-				goModFiles = append(goModFiles, result)
-				repoEntity.HasGoMod = true
-			}
+	var parsed []domain.SkippedDependency
+	for _, d := range skipped {
+		if d.ManifestPath == manifestPath && !recorded[d.Ecosystem+":"+d.Name+"@"+d.Version] {
+			parsed = append(parsed, d)
 		}
+	}
+	return parsed
+}
 
-		// Skip if no manifest found
-		totalManifests := len(packageJSONFiles) + len(pomXMLFiles) + len(gradleFiles) + len(goModFiles)
-		if totalManifests == 0 {
-			log.Info().Str("repo", repo.FullName).Msg("no valid manifest content found")
-			continue
+// sortDependencies orders dependencies by name, ecosystem and type
+func sortDependencies(deps []domain.Dependency) {
+	sort.Slice(deps, func(i, j int) bool {
+		a, b := deps[i], deps[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
 		}
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		return a.Type < b.Type
+	})
+}
 
-		// Record scan start time for this repo (used to detect stale dependencies)
-		repoScanStart := time.Now()
+// contentHash returns the hex-encoded SHA-256 of a manifest's content
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
 
-		// Upsert repository
-		repoID, err := s.repoRepo.Upsert(ctx, repoEntity)
-		if err != nil {
-			log.Error().Err(err).Str("repo", repo.FullName).Msg("failed to upsert repository")
-			continue
-		}
+// ParseManifest parses the content of a manifest the way scans do, without looking up latest
+// versions. The manifest type is the file name of path, e.g. app/build.gradle.
+func ParseManifest(path string, content []byte) ([]domain.Dependency, []domain.SkippedDependency, error) {
+	kind := manifestType(path)
+	if !manifestKinds[kind] {
+		return nil, nil, fmt.Errorf("unsupported manifest type %q", kind)
+	}
+	return parseManifest(manifestFile{path: path, kind: kind, content: content})
+}
 
-		// Process all manifest files (supports multi-module projects)
-		for _, manifest := range packageJSONFiles {
-			var pkg PackageJSON
-			if err := json.Unmarshal(manifest.content, &pkg); err == nil {
-				log.Debug().Str("repo", repo.FullName).Str("path", manifest.path).Msg("processing package.json")
-				deps := s.processNpmDependencies(ctx, repoID, pkg.Dependencies, "dependency")
-				deps += s.processNpmDependencies(ctx, repoID, pkg.DevDependencies, "devDependency")
-				atomic.AddInt32(&repoDeps, int32(deps))
-			}
+// parseManifest extracts the dependencies declared in a manifest without looking up
+// their latest versions. Dependencies whose version cannot be determined are returned as skipped.
+func parseManifest(manifest manifestFile) ([]domain.Dependency, []domain.SkippedDependency, error) {
+	var deps []domain.Dependency
+	var skipped []domain.SkippedDependency
+
+	switch manifest.kind {
+	case "package.json":
+		var pkg PackageJSON
+		if err := json.Unmarshal(manifest.content, &pkg); err != nil {
+			return nil, nil, err
 		}
-
-		for _, manifest := range pomXMLFiles {
-			var pom PomXML
-			if err := xml.Unmarshal(manifest.content, &pom); err == nil {
-				log.Debug().Str("repo", repo.FullName).Str("path", manifest.path).Msg("processing pom.xml")
-				deps := s.processMavenDependencies(ctx, repoID, pom)
-				atomic.AddInt32(&repoDeps, int32(deps))
+		deps = append(deps, npmDependencies(pkg.Dependencies, domain.DependencyTypeProd)...)
+		deps = append(deps, npmDependencies(pkg.DevDependencies, domain.DependencyTypeDev)...)
+		deps = append(deps, npmDependencies(pkg.PeerDependencies, domain.DependencyTypePeer)...)
+		deps = append(deps, npmDependencies(pkg.OptionalDependencies, domain.DependencyTypeOptional)...)
+
+		// Bundled dependencies take their version from dependencies
+		bundled := pkg.bundled()
+		for i := range deps {
+			if deps[i].Type == domain.DependencyTypeProd && bundled[deps[i].Name] {
+				deps[i].Type = domain.DependencyTypeBundled
 			}
 		}
-
-		for _, manifest := range gradleFiles {
-			log.Debug().Str("repo", repo.FullName).Str("path", manifest.path).Msg("processing build.gradle")
-			deps := s.processGradleDependencies(ctx, repoID, string(manifest.content))
-			atomic.AddInt32(&repoDeps, int32(deps))
+	case "pom.xml":
+		var pom PomXML
+		if err := xml.Unmarshal(manifest.content, &pom); err != nil {
+			return nil, nil, err
 		}
-
-		for _, manifest := range goModFiles {
-			log.Debug().Str("repo", repo.FullName).Str("path", manifest.path).Msg("processing go.mod")
-			deps := s.processGoDependencies(ctx, repoID, string(manifest.content))
-			atomic.AddInt32(&repoDeps, int32(deps))
+		deps, skipped = mavenDependencies(pom)
+	case "build.gradle", "build.gradle.kts":
+		deps, skipped = gradleDependencies(string(manifest.content))
+		androidDeps, androidSkipped := androidDependencies(string(manifest.content))
+		deps = append(deps, androidDeps...)
+		skipped = append(skipped, androidSkipped...)
+	case "go.mod":
+		for _, d := range parseGoMod(string(manifest.content)) {
+			deps = append(deps, domain.Dependency{
+				Name:           d.Path,
+				CurrentVersion: d.Version,
+				Type:           "dependency",
+				Ecosystem:      "go",
+			})
 		}
-
-		// Delete stale dependencies (those not updated in this scan)
-		// This removes dependencies that were removed from the manifest
-		if deleted, err := s.depRepo.DeleteStaleByRepoID(ctx, repoID, repoScanStart); err != nil {
-			log.Warn().Err(err).Str("repo", repo.FullName).Msg("failed to delete stale dependencies")
-		} else if deleted > 0 {
-			log.Info().Str("repo", repo.FullName).Int64("deleted", deleted).Msg("removed stale dependencies")
+	case "Package.swift":
+		deps, skipped = parsePackageSwift(string(manifest.content))
+	case "Package.resolved":
+		var err error
+		if deps, skipped, err = parsePackageResolved(manifest.content); err != nil {
+			return nil, nil, err
 		}
+	case "Podfile":
+		deps, skipped = parsePodfile(string(manifest.content))
+	case "Podfile.lock":
+		var err error
+		if deps, err = parsePodfileLock(manifest.content); err != nil {
+			return nil, nil, err
+		}
+	}
 
-		atomic.AddInt32(totalRepos, 1)
-		atomic.AddInt32(totalDeps, repoDeps)
-		log.Info().Str("repo", repo.FullName).Int32("deps", repoDeps).Msg("repository scanned successfully")
-
-		// Update stats in real-time after each repository
-		_ = s.scanRepo.UpdateStats(ctx, scanID, int(atomic.LoadInt32(totalRepos)), int(atomic.LoadInt32(totalDeps)))
+	for i := range deps {
+		deps[i].ManifestPath = manifest.path
+		deps[i].ManifestType = manifest.kind
+		deps[i].Branch = manifest.branch
+	}
+	for i := range skipped {
+		skipped[i].ManifestPath = manifest.path
 	}
 
-	return nil
+	return deps, skipped, nil
 }
 
-func (s *Scanner) processNpmDependencies(ctx context.Context, repoID int64, deps map[string]string, depType string) int {
-	if len(deps) == 0 {
-		return 0
-	}
+// scanAdditionalBranches records per-branch dependency sets for the additional branches
+// configured on the source and removes sets of branches that are no longer scanned
+func (s *Scanner) scanAdditionalBranches(ctx context.Context, provider GitProvider, repoID int64, repoFullName, primaryBranch string, patterns []string) {
+	var keep []string
 
-	var wg sync.WaitGroup
-	sem := make(chan struct{}, 10) // Limit concurrent npm requests
-	var count int32
+	if len(patterns) > 0 {
+		branches, err := resolveBranches(ctx, provider, repoFullName, patterns)
+		if err != nil {
+			log.Warn().Err(err).Str("repo", repoFullName).Msg("failed to list branches, skipping additional branches")
+			return
+		}
 
-	for name, version := range deps {
-		wg.Add(1)
-		go func(name, version string) {
-			defer wg.Done()
-			defer func() {
-				if r := recover(); r != nil {
-					log.Error().Interface("panic", r).Str("dep", name).Msg("panic in npm dependency processing")
-				}
-			}()
-			sem <- struct{}{}
-			defer func() { <-sem }()
+		for _, branch := range branches {
+			if branch == primaryBranch {
+				continue
+			}
+			keep = append(keep, branch)
 
-			cleanedVersion := cleanVersion(version)
-			latest, err := s.npmClient.GetLatestVersion(ctx, name)
+			branchScanStart := time.Now()
+			manifests, err := s.fetchManifests(ctx, provider, repoFullName, branch)
 			if err != nil {
-				latest = ""
+				log.Warn().Err(err).Str("repo", repoFullName).Str("branch", branch).Msg("failed to fetch branch manifests")
+				continue
 			}
-
-			dep := domain.Dependency{
-				RepositoryID:   repoID,
-				Name:           name,
-				CurrentVersion: cleanedVersion,
-				LatestVersion:  latest,
-				Type:           depType,
-				Ecosystem:      "npm",
-				IsOutdated:     isOutdated(cleanedVersion, latest),
+			if len(manifests) == 0 {
+				continue
+			}
+			for i := range manifests {
+				manifests[i].branch = branch
 			}
 
-			if err := s.depRepo.Upsert(ctx, dep); err != nil {
-				log.Error().Err(err).Str("dep", name).Msg("failed to upsert dependency")
-				return
+			deps, _ := s.processManifests(ctx, repoID, repoFullName, manifests)
+			if _, err := s.depRepo.DeleteStaleBranchDependencies(ctx, repoID, branch, branchScanStart); err != nil {
+				log.Warn().Err(err).Str("repo", repoFullName).Str("branch", branch).Msg("failed to delete stale branch dependencies")
 			}
+			log.Info().Str("repo", repoFullName).Str("branch", branch).Int32("deps", deps).Msg("branch scanned successfully")
+		}
+	}
 
-			atomic.AddInt32(&count, 1)
-		}(name, version)
+	if err := s.depRepo.DeleteBranchesExcept(ctx, repoID, keep); err != nil {
+		log.Warn().Err(err).Str("repo", repoFullName).Msg("failed to remove dependencies of unscanned branches")
 	}
+}
 
-	wg.Wait()
-	return int(count)
+// saveDependency stores a dependency of the primary scan branch or, when Branch is set,
+// of an additional branch
+func (s *Scanner) saveDependency(ctx context.Context, dep domain.Dependency) error {
+	if dep.Branch != "" {
+		return s.depRepo.UpsertBranch(ctx, dep)
+	}
+	return s.depRepo.Upsert(ctx, dep)
 }
 
-func (s *Scanner) processMavenDependencies(ctx context.Context, repoID int64, pom PomXML) int {
-	var wg sync.WaitGroup
-	sem := make(chan struct{}, 10)
-	var count int32
-	var skipped int32
+// recordDependencies looks up the latest version of each dependency and stores it, returning
+// the number of dependencies stored and those whose outdated status could not be determined
+func (s *Scanner) recordDependencies(ctx context.Context, deps []domain.Dependency) (int32, []domain.SkippedDependency) {
+	unchecked := s.resolveLatestVersions(ctx, deps)
 
-	// Process regular dependencies
-	for _, dep := range pom.Dependencies.Dependency {
-		if dep.Version == "" || strings.HasPrefix(dep.Version, "${") {
-			atomic.AddInt32(&skipped, 1)
-			log.Debug().
-				Str("groupId", dep.GroupID).
-				Str("artifactId", dep.ArtifactID).
-				Str("version", dep.Version).
-				Msg("skipping Maven dependency with property reference or empty version")
+	var count int32
+	for _, dep := range deps {
+		if err := s.saveDependency(ctx, dep); err != nil {
+			log.Error().Err(err).Str("dep", dep.Name).Str("ecosystem", dep.Ecosystem).Msg("failed to upsert dependency")
 			continue
 		}
+		count++
+	}
+	return count, unchecked
+}
+
+// resolveLatestVersions looks up the latest version of each dependency concurrently and
+// sets LatestVersion and IsOutdated in place. Dependencies of packages with a recommended version
+// are outdated compared to it instead of the latest version. Dependencies whose lookup failed or
+// whose current version is not a semantic version are returned as unchecked; they are still
+// reported as up to date.
+func (s *Scanner) resolveLatestVersions(ctx context.Context, deps []domain.Dependency) []domain.SkippedDependency {
+	ctx, span := tracing.Start(ctx, "scan.resolve_versions", attribute.Int("dependencies", len(deps)))
+	defer span.End()
+
+	comparison := s.Comparison()
+	fallback := s.RegistryFallback()
+	recommended := s.recommendedVersions(ctx)
+	assessRisks := s.flags.Enabled(ctx, feature.SupplyChainRisks)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var unchecked []domain.SkippedDependency
+	sem := make(chan struct{}, 10) // Limit concurrent registry requests
 
+	for i := range deps {
 		wg.Add(1)
-		go func(groupID, artifactID, version, scope string) {
+		go func(d *domain.Dependency) {
 			defer wg.Done()
 			defer func() {
 				if r := recover(); r != nil {
-					log.Error().Interface("panic", r).Str("groupId", groupID).Str("artifactId", artifactID).Msg("panic in maven dependency processing")
+					log.Error().Interface("panic", r).Str("dep", d.Name).Str("ecosystem", d.Ecosystem).Msg("panic in dependency processing")
 				}
 			}()
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			latest, err := s.mavenClient.GetLatestVersion(ctx, groupID, artifactID)
-			if err != nil {
-				latest = ""
+			latest, err := s.latestVersion(ctx, *d)
+			if err != nil && fallback {
+				latest = s.lastKnownLatestVersion(ctx, *d)
+				d.StaleData = latest != ""
 			}
-
-			depType := "dependency"
-			if scope == "test" {
-				depType = "devDependency"
+			d.LatestVersion = latest
+			d.RecommendedVersion = recommended[d.Ecosystem+":"+d.Name]
+			d.IsOutdated = comparison.IsOutdated(d.CurrentVersion, cmp.Or(d.RecommendedVersion, d.LatestVersion))
+			if err == nil {
+				d.LatestInMajor = s.latestInMajor(ctx, *d)
 			}
-
-			d := domain.Dependency{
-				RepositoryID:   repoID,
-				Name:           groupID + ":" + artifactID,
-				CurrentVersion: version,
-				LatestVersion:  latest,
-				Type:           depType,
-				Ecosystem:      "maven",
-				IsOutdated:     isOutdated(version, latest),
+			if assessRisks {
+				s.assessRisks(ctx, d)
 			}
 
-			if err := s.depRepo.Upsert(ctx, d); err != nil {
-				log.Error().Err(err).Str("dep", d.Name).Msg("failed to upsert maven dependency")
-				return
+			reason, detail := "", ""
+			switch {
+			case err != nil && d.StaleData:
+				reason, detail = domain.SkipReasonRegistryError, err.Error()+" (compared with the last known latest version)"
+			case err != nil:
+				reason, detail = domain.SkipReasonRegistryError, err.Error()
+			case !isSemver(d.CurrentVersion):
+				reason, detail = domain.SkipReasonInvalidVersion, "version is not a semantic version"
 			}
-
-			atomic.AddInt32(&count, 1)
-		}(dep.GroupID, dep.ArtifactID, dep.Version, dep.Scope)
+			if reason != "" {
+				mu.Lock()
+				unchecked = append(unchecked, skippedDependency(*d, reason, detail))
+				mu.Unlock()
+			}
+		}(&deps[i])
 	}
 
 	wg.Wait()
+	return unchecked
+}
+
+// lastKnownLatestVersion returns the latest version recorded for the package of a dependency by
+// earlier scans, or an empty string when there is none. Failures are logged.
+func (s *Scanner) lastKnownLatestVersion(ctx context.Context, dep domain.Dependency) string {
+	if s.depRepo == nil { // agents scan without a database
+		return ""
+	}
+	latest, err := s.depRepo.GetLastKnownLatestVersion(ctx, dep.Ecosystem, dep.Name)
+	if err != nil {
+		log.Warn().Err(err).Str("dep", dep.Name).Str("ecosystem", dep.Ecosystem).Msg("failed to load last known latest version")
+	}
+	return latest
+}
 
-	if skipped > 0 {
-		log.Info().Int32("skipped", skipped).Int32("processed", count).Msg("Maven dependencies with property references were skipped")
+// recommendedVersions returns the versions pinned for packages by "ecosystem:name". Failures are
+// logged and leave dependencies compared with their latest version.
+func (s *Scanner) recommendedVersions(ctx context.Context) map[string]string {
+	if s.depRepo == nil { // agents scan without a database
+		return nil
+	}
+	pinned, err := s.depRepo.GetRecommendedVersions(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load recommended versions")
+		return nil
 	}
 
-	return int(count)
+	versions := make(map[string]string, len(pinned))
+	for _, rv := range pinned {
+		versions[rv.Ecosystem+":"+rv.Name] = rv.Version
+	}
+	return versions
 }
 
-func (s *Scanner) processGradleDependencies(ctx context.Context, repoID int64, content string) int {
-	deps, skipped := parseGradleDependencies(content)
+// skippedDependency records a dependency that was skipped or could not be checked
+func skippedDependency(dep domain.Dependency, reason, detail string) domain.SkippedDependency {
+	return domain.SkippedDependency{
+		Name:         dep.Name,
+		Ecosystem:    dep.Ecosystem,
+		Version:      dep.CurrentVersion,
+		ManifestPath: dep.ManifestPath,
+		Reason:       reason,
+		Detail:       detail,
+	}
+}
 
-	if len(skipped) > 0 {
-		for _, dep := range skipped {
-			log.Debug().Str("dependency", dep).Msg("skipping Gradle dependency with property reference")
-		}
-		log.Info().Int("skipped", len(skipped)).Msg("Gradle dependencies with property references were skipped")
+// isSemver reports whether a version can be compared with semantic versioning
+func isSemver(version string) bool {
+	_, err := semver.NewVersion(version)
+	return err == nil
+}
+
+// latestVersion looks up the latest version of a dependency in the registry of its ecosystem
+func (s *Scanner) latestVersion(ctx context.Context, dep domain.Dependency) (string, error) {
+	ctx, span := tracing.Start(ctx, "registry.latest_version",
+		attribute.String("ecosystem", dep.Ecosystem),
+		attribute.String("package", dep.Name),
+	)
+	var latest string
+	var err error
+	defer func() { tracing.End(span, err) }()
+
+	if s.registry != nil {
+		latest, err = s.registry.LatestVersion(ctx, dep)
+		return latest, err
 	}
 
-	if len(deps) == 0 {
-		return 0
+	switch dep.Ecosystem {
+	case "npm":
+		latest, err = s.npmClient.GetLatestVersion(ctx, dep.Name)
+	case "maven", "gradle":
+		groupID, artifactID, _ := strings.Cut(dep.Name, ":")
+		latest, err = s.mavenClient.GetLatestVersion(ctx, groupID, artifactID)
+	case "go":
+		latest, err = s.goClient.GetLatestVersion(ctx, dep.Name)
+	case "swift":
+		latest, err = s.swiftClient.GetLatestVersion(ctx, dep.Name)
+	case "cocoapods":
+		latest, err = s.podsClient.GetLatestVersion(ctx, dep.Name)
+	case "android":
+		latest, err = s.sdkClient.GetLatestVersion(ctx, dep.Name)
 	}
 
-	var wg sync.WaitGroup
-	sem := make(chan struct{}, 10)
-	var count int32
+	if err != nil {
+		return "", err
+	}
+	return latest, nil
+}
 
-	for _, dep := range deps {
-		wg.Add(1)
-		go func(d GradleDependency) {
-			defer wg.Done()
-			defer func() {
-				if r := recover(); r != nil {
-					log.Error().Interface("panic", r).Str("dep", d.Group+":"+d.Name).Msg("panic in gradle dependency processing")
-				}
-			}()
-			sem <- struct{}{}
-			defer func() { <-sem }()
+func npmDependencies(deps map[string]string, depType string) []domain.Dependency {
+	result := make([]domain.Dependency, 0, len(deps))
+	for name, version := range deps {
+		result = append(result, domain.Dependency{
+			Name:           name,
+			CurrentVersion: cleanVersion(version),
+			Type:           depType,
+			Ecosystem:      "npm",
+		})
+	}
+	return result
+}
 
-			latest, err := s.mavenClient.GetLatestVersion(ctx, d.Group, d.Name)
-			if err != nil {
-				latest = ""
-			}
+// mavenDependencies returns the dependencies of a POM along with the BOMs it imports in its
+// dependency management. Dependencies whose version is a property reference or is left to a
+// parent POM or BOM are returned as skipped.
+func mavenDependencies(pom PomXML) ([]domain.Dependency, []domain.SkippedDependency) {
+	var deps []domain.Dependency
+	var skipped []domain.SkippedDependency
+
+	declared := pom.Dependencies.Dependency
+	for _, dep := range pom.DependencyManagement.Dependencies.Dependency {
+		if dep.Scope == "import" {
+			declared = append(declared, dep)
+		}
+	}
 
-			depEntity := domain.Dependency{
-				RepositoryID:   repoID,
-				Name:           d.Group + ":" + d.Name,
-				CurrentVersion: d.Version,
-				LatestVersion:  latest,
-				Type:           "dependency",
-				Ecosystem:      "gradle",
-				IsOutdated:     isOutdated(d.Version, latest),
+	for _, dep := range declared {
+		if dep.Version == "" || strings.HasPrefix(dep.Version, "${") {
+			log.Debug().
+				Str("groupId", dep.GroupID).
+				Str("artifactId", dep.ArtifactID).
+				Str("version", dep.Version).
+				Msg("skipping Maven dependency with property reference or empty version")
+			sd := domain.SkippedDependency{
+				Name:      dep.GroupID + ":" + dep.ArtifactID,
+				Ecosystem: "maven",
+				Version:   dep.Version,
+				Reason:    domain.SkipReasonUnresolvedProperty,
+				Detail:    "property " + dep.Version + " is not resolved",
 			}
-
-			if err := s.depRepo.Upsert(ctx, depEntity); err != nil {
-				log.Error().Err(err).Str("dep", depEntity.Name).Msg("failed to upsert gradle dependency")
-				return
+			if dep.Version == "" {
+				sd.Reason, sd.Detail = domain.SkipReasonInvalidVersion, "version is managed by a parent POM or BOM"
 			}
+			skipped = append(skipped, sd)
+			continue
+		}
 
-			atomic.AddInt32(&count, 1)
-		}(dep)
+		// compile is the scope of dependencies declared without one
+		scope := strings.TrimSpace(dep.Scope)
+		if scope == "" {
+			scope = "compile"
+		}
+		depType := domain.DependencyTypeProd
+		if scope == "test" {
+			depType = domain.DependencyTypeDev
+		}
+
+		deps = append(deps, domain.Dependency{
+			Name:           dep.GroupID + ":" + dep.ArtifactID,
+			CurrentVersion: dep.Version,
+			Type:           depType,
+			Scope:          scope,
+			Ecosystem:      "maven",
+		})
 	}
 
-	wg.Wait()
-	return int(count)
+	if len(skipped) > 0 {
+		log.Info().Int("skipped", len(skipped)).Int("processed", len(deps)).Msg("Maven dependencies with property references were skipped")
+	}
+
+	return deps, skipped
+}
+
+// gradleDependencies returns the dependencies of a Gradle build script. Dependencies whose version
+// is a property reference are returned as skipped.
+func gradleDependencies(content string) ([]domain.Dependency, []domain.SkippedDependency) {
+	parsed, unresolved := parseGradleDependencies(content)
+
+	var skipped []domain.SkippedDependency
+	for _, d := range unresolved {
+		log.Debug().Str("dependency", d.Group+":"+d.Name).Msg("skipping Gradle dependency with property reference")
+		skipped = append(skipped, domain.SkippedDependency{
+			Name:      d.Group + ":" + d.Name,
+			Ecosystem: "gradle",
+			Version:   d.Version,
+			Reason:    domain.SkipReasonUnresolvedProperty,
+			Detail:    "property " + d.Version + " is not resolved",
+		})
+	}
+	if len(skipped) > 0 {
+		log.Info().Int("skipped", len(skipped)).Msg("Gradle dependencies with property references were skipped")
+	}
+
+	deps := make([]domain.Dependency, 0, len(parsed))
+	for _, d := range parsed {
+		deps = append(deps, domain.Dependency{
+			Name:           d.Group + ":" + d.Name,
+			CurrentVersion: d.Version,
+			Type:           "dependency",
+			Ecosystem:      "gradle",
+		})
+	}
+	return deps, skipped
 }
 
 // parseGradleDependencies extracts dependencies from build.gradle content
-// Returns parsed dependencies and a list of skipped dependency names (those with property references)
-func parseGradleDependencies(content string) ([]GradleDependency, []string) {
+// Returns parsed dependencies and the skipped ones (those with property references)
+func parseGradleDependencies(content string) ([]GradleDependency, []GradleDependency) {
 	var deps []GradleDependency
-	var skipped []string
+	var skipped []GradleDependency
 
 	// Match patterns like: implementation 'group:name:version'
 	// or implementation "group:name:version"
@@ -576,15 +1808,16 @@ func parseGradleDependencies(content string) ([]GradleDependency, []string) {
 		for _, match := range matches {
 			if len(match) >= 4 {
 				// Skip property references like $version
-				if strings.Contains(match[3], "$") {
-					skipped = append(skipped, match[1]+":"+match[2])
-					continue
-				}
-				deps = append(deps, GradleDependency{
+				dep := GradleDependency{
 					Group:   match[1],
 					Name:    match[2],
 					Version: match[3],
-				})
+				}
+				if strings.Contains(dep.Version, "$") {
+					skipped = append(skipped, dep)
+					continue
+				}
+				deps = append(deps, dep)
 			}
 		}
 	}
@@ -598,56 +1831,6 @@ type GoModDependency struct {
 	Version string
 }
 
-func (s *Scanner) processGoDependencies(ctx context.Context, repoID int64, content string) int {
-	deps := parseGoMod(content)
-	if len(deps) == 0 {
-		return 0
-	}
-
-	var wg sync.WaitGroup
-	sem := make(chan struct{}, 10)
-	var count int32
-
-	for _, dep := range deps {
-		wg.Add(1)
-		go func(d GoModDependency) {
-			defer wg.Done()
-			defer func() {
-				if r := recover(); r != nil {
-					log.Error().Interface("panic", r).Str("dep", d.Path).Msg("panic in go dependency processing")
-				}
-			}()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			latest, err := s.goClient.GetLatestVersion(ctx, d.Path)
-			if err != nil {
-				latest = ""
-			}
-
-			depEntity := domain.Dependency{
-				RepositoryID:   repoID,
-				Name:           d.Path,
-				CurrentVersion: d.Version,
-				LatestVersion:  latest,
-				Type:           "dependency",
-				Ecosystem:      "go",
-				IsOutdated:     isOutdated(d.Version, latest),
-			}
-
-			if err := s.depRepo.Upsert(ctx, depEntity); err != nil {
-				log.Error().Err(err).Str("dep", depEntity.Name).Msg("failed to upsert go dependency")
-				return
-			}
-
-			atomic.AddInt32(&count, 1)
-		}(dep)
-	}
-
-	wg.Wait()
-	return int(count)
-}
-
 // parseGoMod parses go.mod content and extracts dependencies
 func parseGoMod(content string) []GoModDependency {
 	var deps []GoModDependency
@@ -707,6 +1890,97 @@ func parseGoMod(content string) []GoModDependency {
 	return deps
 }
 
+// scanBranches returns the branches to scan a repository on, in order: the repository override if
+// set, then the branches of the source scan branch, otherwise the repository's default branch.
+// The source scan branch may list fallbacks, e.g. "develop,main,master".
+func scanBranches(source domain.Source, defaultBranch, override string) []string {
+	if override != "" {
+		return []string{override}
+	}
+	if branches := parseBranchList(source.ScanBranch); len(branches) > 0 {
+		return branches
+	}
+	return []string{defaultBranch}
+}
+
+// fetchFirstManifests fetches the manifests of the first branch that has any, returning them with
+// the branch. Branches that fail to fetch are skipped; an error is returned only when every branch
+// failed. When no branch has manifests, the first branch that was fetched is returned.
+func (s *Scanner) fetchFirstManifests(ctx context.Context, provider GitProvider, repoFullName string, branches []string) ([]manifestFile, string, error) {
+	fetched := ""
+	var failures []string
+	for _, branch := range branches {
+		manifests, err := s.fetchManifests(ctx, provider, repoFullName, branch)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, branch, err
+			}
+			log.Debug().Err(err).Str("repo", repoFullName).Str("branch", branch).Msg("failed to fetch branch manifests")
+			failures = append(failures, fmt.Sprintf("branch %s: %v", branch, err))
+			continue
+		}
+		if len(manifests) > 0 {
+			return manifests, branch, nil
+		}
+		if fetched == "" {
+			fetched = branch
+		}
+	}
+
+	if fetched == "" {
+		return nil, branches[0], errors.New(strings.Join(failures, "; "))
+	}
+	return nil, fetched, nil
+}
+
+// parseBranchList splits a comma-separated list of branch names or glob patterns
+func parseBranchList(list string) []string {
+	var branches []string
+	for _, b := range strings.Split(list, ",") {
+		name := strings.TrimSpace(b)
+		if name != "" {
+			branches = append(branches, name)
+		}
+	}
+	return branches
+}
+
+// resolveBranches expands branch patterns (e.g. "release/*") against the branches of a repository.
+// Branches are only listed from the provider when a pattern contains glob characters.
+func resolveBranches(ctx context.Context, provider GitProvider, repoFullName string, patterns []string) ([]string, error) {
+	hasGlob := false
+	for _, p := range patterns {
+		if strings.ContainsAny(p, "*?[") {
+			hasGlob = true
+			break
+		}
+	}
+	if !hasGlob {
+		return patterns, nil
+	}
+
+	branches, err := provider.ListBranches(ctx, repoFullName)
+	if err != nil {
+		return nil, err
+	}
+	return matchBranches(branches, patterns), nil
+}
+
+// matchBranches returns the branches matching any of the patterns, without duplicates
+func matchBranches(branches, patterns []string) []string {
+	var matched []string
+	seen := make(map[string]bool)
+	for _, branch := range branches {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, branch); ok && !seen[branch] {
+				seen[branch] = true
+				matched = append(matched, branch)
+			}
+		}
+	}
+	return matched
+}
+
 // filterRepositories filters repos based on comma-separated list of repo names
 func filterRepositories(repos []RepoInfo, filter string) []RepoInfo {
 	if filter == "" {
@@ -753,7 +2027,79 @@ func cleanVersion(version string) string {
 	return version
 }
 
-func isOutdated(current, latest string) bool {
+// Comparison configures how versions are compared to decide whether a dependency is outdated
+type Comparison struct {
+	// A prerelease latest version (e.g. 2.0.0-rc.1) does not make a dependency outdated. Since
+	// only the latest version is known, a newer stable release is then not reported either.
+	IgnorePrereleases bool
+}
+
+// IsOutdated reports whether latest is newer than current under the comparison settings
+func (c Comparison) IsOutdated(current, latest string) bool {
+	if !IsOutdated(current, latest) {
+		return false
+	}
+	if c.IgnorePrereleases {
+		if v, err := semver.NewVersion(latest); err == nil && v.Prerelease() != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// SetComparison sets how the versions of dependencies are compared by later scans
+func (s *Scanner) SetComparison(c Comparison) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.comparison = c
+}
+
+// Comparison returns how the versions of dependencies are compared
+func (s *Scanner) Comparison() Comparison {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.comparison
+}
+
+// SetRegistryFallback sets whether later scans compare dependencies whose registry lookup fails
+// with the last known latest version of their package, marking them stale data, instead of
+// leaving their latest version empty
+func (s *Scanner) SetRegistryFallback(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = enabled
+}
+
+// RegistryFallback reports whether scans fall back to last known latest versions
+func (s *Scanner) RegistryFallback() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fallback
+}
+
+// DefaultManifestRefreshInterval is how long the parsed dependencies of unchanged manifests are
+// reused unless configured otherwise, long enough for daily scans to skip parsing them
+const DefaultManifestRefreshInterval = 7 * 24 * time.Hour
+
+// SetManifestRefreshInterval sets how long later scans reuse the dependencies parsed from a
+// manifest whose content is unchanged before parsing it again, so parser improvements are still
+// picked up. Their latest versions are looked up on every scan. 0 parses manifests on every scan.
+func (s *Scanner) SetManifestRefreshInterval(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.manifestRefresh = interval
+}
+
+// ManifestRefreshInterval returns how long the parsed dependencies of unchanged manifests are reused
+func (s *Scanner) ManifestRefreshInterval() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.manifestRefresh
+}
+
+// IsOutdated reports whether latest is a newer semantic version than current. Versions that
+// cannot be compared are not outdated.
+func IsOutdated(current, latest string) bool {
 	if current == "" || latest == "" {
 		return false
 	}
@@ -1,7 +1,12 @@
 package scanner
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/jiin/stale/internal/domain"
 )
 
 func TestCleanVersion(t *testing.T) {
@@ -59,9 +64,33 @@ func TestIsOutdated(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isOutdated(tt.current, tt.latest)
+			result := IsOutdated(tt.current, tt.latest)
 			if result != tt.expected {
-				t.Errorf("isOutdated(%q, %q) = %v, want %v", tt.current, tt.latest, result, tt.expected)
+				t.Errorf("IsOutdated(%q, %q) = %v, want %v", tt.current, tt.latest, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestComparison_IgnorePrereleases(t *testing.T) {
+	c := Comparison{IgnorePrereleases: true}
+
+	tests := []struct {
+		name     string
+		current  string
+		latest   string
+		expected bool
+	}{
+		{"stable update", "1.0.0", "1.1.0", true},
+		{"prerelease latest", "1.0.0", "2.0.0-rc.1", false},
+		{"prerelease current", "1.0.0-beta.1", "1.0.0", true},
+		{"same version", "1.0.0", "1.0.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.IsOutdated(tt.current, tt.latest); got != tt.expected {
+				t.Errorf("IsOutdated(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.expected)
 			}
 		})
 	}
@@ -342,3 +371,393 @@ func TestFilterRepositories(t *testing.T) {
 		})
 	}
 }
+
+func TestManifestType(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"package.json", "package.json"},
+		{"services/api/pom.xml", "pom.xml"},
+		{"app/build.gradle.kts", "build.gradle.kts"},
+		{"tools/go.mod", "go.mod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := manifestType(tt.path); got != tt.expected {
+				t.Errorf("manifestType(%q) = %q, want %q", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseBranchList(t *testing.T) {
+	result := parseBranchList(" main, release/*, ,develop ")
+	expected := []string{"main", "release/*", "develop"}
+
+	if len(result) != len(expected) {
+		t.Fatalf("parseBranchList() returned %d branches, want %d", len(result), len(expected))
+	}
+	for i, b := range result {
+		if b != expected[i] {
+			t.Errorf("parseBranchList()[%d] = %q, want %q", i, b, expected[i])
+		}
+	}
+
+	if got := parseBranchList(""); got != nil {
+		t.Errorf("parseBranchList(\"\") = %v, want nil", got)
+	}
+}
+
+func TestScanBranches(t *testing.T) {
+	tests := []struct {
+		name       string
+		scanBranch string
+		override   string
+		expected   []string
+	}{
+		{"default branch", "", "", []string{"main"}},
+		{"scan branch", "develop", "", []string{"develop"}},
+		{"fallback list", "develop, main ,master", "", []string{"develop", "main", "master"}},
+		{"override", "develop,main", "release/2.x", []string{"release/2.x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := scanBranches(domain.Source{ScanBranch: tt.scanBranch}, "main", tt.override)
+			if strings.Join(result, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("scanBranches() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// branchProvider serves the files of each branch; unknown branches fail like a missing ref
+type branchProvider struct {
+	GitProvider
+	branches map[string]map[string]string
+}
+
+func (p *branchProvider) ListManifestFiles(ctx context.Context, repoPath, ref string) ([]string, error) {
+	files, ok := p.branches[ref]
+	if !ok {
+		return nil, errors.New("404 Not Found")
+	}
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func (p *branchProvider) GetFileContent(ctx context.Context, repoPath, filePath, ref string) ([]byte, error) {
+	if content, ok := p.branches[ref][filePath]; ok {
+		return []byte(content), nil
+	}
+	return nil, errors.New("404 Not Found")
+}
+
+func TestFetchFirstManifests(t *testing.T) {
+	provider := &branchProvider{branches: map[string]map[string]string{
+		"main":   {"package.json": `{"dependencies": {"react": "^18.2.0"}}`},
+		"master": {"README.md": "# docs"},
+	}}
+	s := &Scanner{}
+	ctx := context.Background()
+
+	manifests, branch, err := s.fetchFirstManifests(ctx, provider, "owner/repo", []string{"develop", "master", "main"})
+	if err != nil || branch != "main" || len(manifests) != 1 || manifests[0].path != "package.json" {
+		t.Errorf("fetchFirstManifests() = %d manifests, %q, %v, want package.json of main", len(manifests), branch, err)
+	}
+
+	// A branch without manifests is used when no branch has any
+	manifests, branch, err = s.fetchFirstManifests(ctx, provider, "owner/repo", []string{"develop", "master"})
+	if err != nil || branch != "master" || len(manifests) != 0 {
+		t.Errorf("fetchFirstManifests() = %d manifests, %q, %v, want master without manifests", len(manifests), branch, err)
+	}
+
+	_, branch, err = s.fetchFirstManifests(ctx, provider, "owner/repo", []string{"develop", "release"})
+	if err == nil || branch != "develop" || !strings.Contains(err.Error(), "branch develop: 404 Not Found; branch release: 404 Not Found") {
+		t.Errorf("fetchFirstManifests() = %q, %v, want the errors of every branch", branch, err)
+	}
+}
+
+func TestMatchBranches(t *testing.T) {
+	branches := []string{"main", "develop", "release/1.x", "release/2.x", "release/2.x/hotfix"}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		expected []string
+	}{
+		{"glob", []string{"release/*"}, []string{"release/1.x", "release/2.x"}},
+		{"literal", []string{"develop"}, []string{"develop"}},
+		{"overlapping patterns", []string{"release/*", "release/2.*"}, []string{"release/1.x", "release/2.x"}},
+		{"no match", []string{"hotfix/*"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := matchBranches(branches, tt.patterns)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("matchBranches() = %v, want %v", result, tt.expected)
+			}
+			for i, b := range result {
+				if b != tt.expected[i] {
+					t.Errorf("matchBranches()[%d] = %q, want %q", i, b, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	manifest := manifestFile{
+		path:    "web/package.json",
+		kind:    "package.json",
+		branch:  "develop",
+		content: []byte(`{"dependencies":{"react":"^18.2.0"},"devDependencies":{"jest":"~29.7.0"}}`),
+	}
+
+	deps, _, err := parseManifest(manifest)
+	if err != nil {
+		t.Fatalf("parseManifest() error = %v", err)
+	}
+	if len(deps) != 2 {
+		t.Fatalf("parseManifest() returned %d dependencies, want 2", len(deps))
+	}
+
+	for _, d := range deps {
+		if d.ManifestPath != "web/package.json" || d.ManifestType != "package.json" || d.Branch != "develop" {
+			t.Errorf("dependency %s has manifest %q/%q on branch %q", d.Name, d.ManifestPath, d.ManifestType, d.Branch)
+		}
+		switch d.Name {
+		case "react":
+			if d.CurrentVersion != "18.2.0" || d.Type != "dependency" {
+				t.Errorf("react = %q (%s), want 18.2.0 (dependency)", d.CurrentVersion, d.Type)
+			}
+		case "jest":
+			if d.CurrentVersion != "29.7.0" || d.Type != "devDependency" {
+				t.Errorf("jest = %q (%s), want 29.7.0 (devDependency)", d.CurrentVersion, d.Type)
+			}
+		default:
+			t.Errorf("unexpected dependency %s", d.Name)
+		}
+	}
+
+	if _, _, err := parseManifest(manifestFile{path: "pom.xml", kind: "pom.xml", content: []byte("<project")}); err == nil {
+		t.Error("parseManifest() expected error for malformed pom.xml")
+	}
+}
+
+func TestParseManifestNpmTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string // name -> type
+	}{
+		{
+			name: "peer and optional",
+			content: `{"dependencies":{"react-dom":"^18.2.0"},"peerDependencies":{"react":">=17"},
+				"optionalDependencies":{"fsevents":"^2.3.3"}}`,
+			want: map[string]string{"react-dom": "dependency", "react": "peerDependency", "fsevents": "optionalDependency"},
+		},
+		{
+			name:    "bundled names",
+			content: `{"dependencies":{"lodash":"^4.17.21","chalk":"^5.3.0"},"bundledDependencies":["lodash"]}`,
+			want:    map[string]string{"lodash": "bundledDependency", "chalk": "dependency"},
+		},
+		{
+			name:    "bundle all",
+			content: `{"dependencies":{"lodash":"^4.17.21"},"devDependencies":{"jest":"^29.7.0"},"bundleDependencies":true}`,
+			want:    map[string]string{"lodash": "bundledDependency", "jest": "devDependency"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _, err := parseManifest(manifestFile{path: "package.json", kind: "package.json", content: []byte(tt.content)})
+			if err != nil {
+				t.Fatalf("parseManifest() error = %v", err)
+			}
+			if len(deps) != len(tt.want) {
+				t.Fatalf("parseManifest() returned %d dependencies, want %d", len(deps), len(tt.want))
+			}
+			for _, d := range deps {
+				if d.Type != tt.want[d.Name] {
+					t.Errorf("%s type = %q, want %q", d.Name, d.Type, tt.want[d.Name])
+				}
+			}
+		})
+	}
+}
+
+func TestParseManifestMavenScopes(t *testing.T) {
+	pom := manifestFile{
+		path: "pom.xml",
+		kind: "pom.xml",
+		content: []byte(`<project>
+			<dependencyManagement><dependencies>
+				<dependency><groupId>org.springframework.boot</groupId><artifactId>spring-boot-dependencies</artifactId><version>3.2.0</version><type>pom</type><scope>import</scope></dependency>
+				<dependency><groupId>com.fasterxml.jackson.core</groupId><artifactId>jackson-databind</artifactId><version>2.16.0</version></dependency>
+			</dependencies></dependencyManagement>
+			<dependencies>
+				<dependency><groupId>org.example</groupId><artifactId>core</artifactId><version>1.2.0</version></dependency>
+				<dependency><groupId>jakarta.servlet</groupId><artifactId>jakarta.servlet-api</artifactId><version>6.0.0</version><scope>provided</scope></dependency>
+				<dependency><groupId>org.postgresql</groupId><artifactId>postgresql</artifactId><version>42.7.0</version><scope>runtime</scope></dependency>
+				<dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>4.13.2</version><scope>test</scope></dependency>
+			</dependencies></project>`),
+	}
+	deps, _, err := parseManifest(pom)
+	if err != nil {
+		t.Fatalf("parseManifest() error = %v", err)
+	}
+
+	want := map[string][2]string{ // name -> type, scope
+		"org.springframework.boot:spring-boot-dependencies": {"dependency", "import"},
+		"org.example:core":                    {"dependency", "compile"},
+		"jakarta.servlet:jakarta.servlet-api": {"dependency", "provided"},
+		"org.postgresql:postgresql":           {"dependency", "runtime"},
+		"junit:junit":                         {"devDependency", "test"},
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("parseManifest() returned %d dependencies, want %d", len(deps), len(want))
+	}
+	for _, d := range deps {
+		if got := [2]string{d.Type, d.Scope}; got != want[d.Name] {
+			t.Errorf("%s = %v, want %v", d.Name, got, want[d.Name])
+		}
+	}
+}
+
+func TestParseManifestSkipped(t *testing.T) {
+	pom := manifestFile{
+		path: "api/pom.xml",
+		kind: "pom.xml",
+		content: []byte(`<project><dependencies>
+			<dependency><groupId>org.example</groupId><artifactId>core</artifactId><version>1.2.0</version></dependency>
+			<dependency><groupId>org.springframework</groupId><artifactId>spring-core</artifactId><version>${spring.version}</version></dependency>
+			<dependency><groupId>org.slf4j</groupId><artifactId>slf4j-api</artifactId></dependency>
+		</dependencies></project>`),
+	}
+	deps, skipped, err := parseManifest(pom)
+	if err != nil {
+		t.Fatalf("parseManifest() error = %v", err)
+	}
+	if len(deps) != 1 || len(skipped) != 2 {
+		t.Fatalf("parseManifest() returned %d dependencies and %d skipped, want 1 and 2", len(deps), len(skipped))
+	}
+	want := map[string]string{
+		"org.springframework:spring-core": domain.SkipReasonUnresolvedProperty,
+		"org.slf4j:slf4j-api":             domain.SkipReasonInvalidVersion,
+	}
+	for _, sd := range skipped {
+		if sd.Reason != want[sd.Name] || sd.Ecosystem != "maven" || sd.ManifestPath != "api/pom.xml" {
+			t.Errorf("skipped %s = %s (%s, %s), want %s (maven, api/pom.xml)", sd.Name, sd.Reason, sd.Ecosystem, sd.ManifestPath, want[sd.Name])
+		}
+	}
+
+	gradle := manifestFile{
+		path:    "build.gradle",
+		kind:    "build.gradle",
+		content: []byte(`implementation "com.google.guava:guava:$guavaVersion"`),
+	}
+	_, skipped, err = parseManifest(gradle)
+	if err != nil {
+		t.Fatalf("parseManifest() error = %v", err)
+	}
+	if len(skipped) != 1 || skipped[0].Reason != domain.SkipReasonUnresolvedProperty || skipped[0].Version != "$guavaVersion" {
+		t.Errorf("parseManifest() skipped = %+v, want guava with an unresolved property", skipped)
+	}
+}
+
+func TestManifestEcosystem(t *testing.T) {
+	tests := map[string]string{
+		"package.json":     "npm",
+		"pom.xml":          "maven",
+		"build.gradle":     "gradle",
+		"build.gradle.kts": "gradle",
+		"go.mod":           "go",
+		"Package.swift":    "swift",
+		"Package.resolved": "swift",
+		"Podfile":          "cocoapods",
+		"Podfile.lock":     "cocoapods",
+		"Cargo.toml":       "",
+	}
+
+	for kind, expected := range tests {
+		if got := manifestEcosystem(kind); got != expected {
+			t.Errorf("manifestEcosystem(%q) = %q, want %q", kind, got, expected)
+		}
+	}
+}
+
+func TestPriorityTiers(t *testing.T) {
+	sources := []domain.Source{
+		{Name: "large", Priority: 0},
+		{Name: "urgent", Priority: 10},
+		{Name: "small", Priority: 0},
+		{Name: "night", Priority: -5},
+	}
+
+	tiers := priorityTiers(sources)
+
+	var got []string
+	for _, tier := range tiers {
+		var names []string
+		for _, s := range tier {
+			names = append(names, s.Name)
+		}
+		got = append(got, strings.Join(names, ","))
+	}
+	want := []string{"urgent", "large,small", "night"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("priorityTiers() = %v, want %v", got, want)
+	}
+	if sources[0].Name != "large" {
+		t.Error("priorityTiers() should not reorder its input")
+	}
+}
+
+func TestParseGoDirectives(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantGo        string
+		wantToolchain string
+		wantVersion   string
+	}{
+		{
+			name:          "go and toolchain",
+			content:       "module example.com/app\n\ngo 1.21\n\ntoolchain go1.22.3 // pinned\n\nrequire github.com/go-chi/chi/v5 v5.0.12\n",
+			wantGo:        "1.21",
+			wantToolchain: "go1.22.3",
+			wantVersion:   "1.22.3",
+		},
+		{
+			name:        "go only",
+			content:     "module example.com/app\n\ngo 1.22.1\n",
+			wantGo:      "1.22.1",
+			wantVersion: "1.22.1",
+		},
+		{
+			name:        "default toolchain",
+			content:     "module example.com/app\ngo 1.22\ntoolchain default\n",
+			wantGo:      "1.22",
+			wantVersion: "1.22",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goVersion, toolchain := parseGoDirectives(tt.content)
+			if goVersion != tt.wantGo || toolchain != tt.wantToolchain {
+				t.Errorf("parseGoDirectives() = %q, %q, want %q, %q", goVersion, toolchain, tt.wantGo, tt.wantToolchain)
+			}
+			if got := goToolchainVersion(goVersion, toolchain); got != tt.wantVersion {
+				t.Errorf("goToolchainVersion() = %q, want %q", got, tt.wantVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package scanner
+
+import (
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+// OutdatedDays returns the number of full days the dependency has been outdated, or 0 when
+// it is up to date or the start of its outdated period is unknown
+func OutdatedDays(dep domain.Dependency, now time.Time) int {
+	if !dep.IsOutdated || dep.FirstOutdatedAt == nil || now.Before(*dep.FirstOutdatedAt) {
+		return 0
+	}
+	return int(now.Sub(*dep.FirstOutdatedAt).Hours() / 24)
+}
+
+// SLABreaches counts the outdated dependencies that have been outdated for longer than the SLA
+// configured for their upgrade type. An SLA of 0 days disables the check for that type, and
+// dependencies whose versions cannot be compared are never counted.
+func SLABreaches(deps []domain.DependencyWithRepo, settings *domain.Settings, now time.Time) domain.SLABreachStats {
+	var stats domain.SLABreachStats
+	for _, dep := range deps {
+		days := OutdatedDays(dep.Dependency, now)
+		if days == 0 {
+			continue
+		}
+
+		switch UpgradeJump(dep.CurrentVersion, dep.LatestVersion) {
+		case "major":
+			if settings.SLAMajorDays > 0 && days > settings.SLAMajorDays {
+				stats.Major++
+			}
+		case "minor":
+			if settings.SLAMinorDays > 0 && days > settings.SLAMinorDays {
+				stats.Minor++
+			}
+		case "patch":
+			if settings.SLAPatchDays > 0 && days > settings.SLAPatchDays {
+				stats.Patch++
+			}
+		}
+	}
+	stats.Total = stats.Major + stats.Minor + stats.Patch
+	return stats
+}
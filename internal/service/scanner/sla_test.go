@@ -0,0 +1,51 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestOutdatedDays(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-10*24*time.Hour - time.Hour)
+
+	if got := OutdatedDays(domain.Dependency{IsOutdated: true, FirstOutdatedAt: &since}, now); got != 10 {
+		t.Errorf("OutdatedDays() = %d, want 10", got)
+	}
+	if got := OutdatedDays(domain.Dependency{IsOutdated: false, FirstOutdatedAt: &since}, now); got != 0 {
+		t.Errorf("OutdatedDays(up to date) = %d, want 0", got)
+	}
+	if got := OutdatedDays(domain.Dependency{IsOutdated: true}, now); got != 0 {
+		t.Errorf("OutdatedDays(unknown start) = %d, want 0", got)
+	}
+}
+
+func TestSLABreaches(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	dep := func(current, latest string, daysOutdated int) domain.DependencyWithRepo {
+		since := now.AddDate(0, 0, -daysOutdated)
+		return domain.DependencyWithRepo{Dependency: domain.Dependency{
+			CurrentVersion:  current,
+			LatestVersion:   latest,
+			IsOutdated:      true,
+			FirstOutdatedAt: &since,
+		}}
+	}
+
+	deps := []domain.DependencyWithRepo{
+		dep("1.0.0", "2.0.0", 100), // major breach
+		dep("1.0.0", "2.0.0", 80),  // major within SLA
+		dep("1.0.0", "1.1.0", 61),  // minor breach
+		dep("1.0.0", "1.0.1", 31),  // patch, SLA disabled
+		dep("latest", "2.0.0", 500),
+	}
+	settings := &domain.Settings{SLAMajorDays: 90, SLAMinorDays: 60, SLAPatchDays: 0}
+
+	got := SLABreaches(deps, settings, now)
+	want := domain.SLABreachStats{Major: 1, Minor: 1, Patch: 0, Total: 2}
+	if got != want {
+		t.Errorf("SLABreaches() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/service/httputil"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrTokenCheckFailed is returned when the provider could not be asked about a token, e.g. on a
+// timeout or a server error. The last recorded status of the token is kept.
+var ErrTokenCheckFailed = errors.New("failed to check token")
+
+// TokenChecker is implemented by providers that can validate their token and report its
+// expiration. Errors of tokens the provider refuses wrap httputil.ErrCredentialsRejected.
+type TokenChecker interface {
+	CheckToken(ctx context.Context) (*time.Time, error)
+}
+
+func (a *GitHubAdapter) CheckToken(ctx context.Context) (*time.Time, error) {
+	return a.client.TokenExpiration(ctx)
+}
+
+func (a *GitLabAdapter) CheckToken(ctx context.Context) (*time.Time, error) {
+	return a.client.TokenExpiration(ctx)
+}
+
+// CheckToken validates access to the configured repositories. Git credentials do not expire.
+func (a *GitAdapter) CheckToken(ctx context.Context) (*time.Time, error) {
+	return nil, a.client.ValidateToken(ctx)
+}
+
+// CheckSourceToken validates the token of a source and stores its status and expiration.
+// Only a token the provider refuses is marked invalid; other failures return ErrTokenCheckFailed.
+// External sources have no token.
+func (s *Scanner) CheckSourceToken(ctx context.Context, source domain.Source) (*domain.Source, error) {
+	if source.External() {
+		return &source, nil
+	}
+	checker, ok := s.provider(source).(TokenChecker)
+	if !ok {
+		return &source, nil
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	status, errMsg := domain.TokenStatusValid, ""
+	expiresAt, err := checker.CheckToken(checkCtx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !errors.Is(err, httputil.ErrCredentialsRejected) {
+			return nil, fmt.Errorf("%w: %w", ErrTokenCheckFailed, err)
+		}
+		status, errMsg = domain.TokenStatusInvalid, err.Error()
+	}
+
+	if err := s.sourceRepo.UpdateTokenStatus(ctx, source.ID, status, errMsg, expiresAt); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	source.TokenStatus = status
+	source.TokenError = errMsg
+	source.TokenExpiresAt = expiresAt
+	source.TokenCheckedAt = &now
+	return &source, nil
+}
+
+// CheckTokens validates the tokens of all sources and returns the sources whose token
+// is invalid or about to expire
+func (s *Scanner) CheckTokens(ctx context.Context) ([]domain.SourceTokenWarning, error) {
+	sources, err := s.sourceRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []domain.SourceTokenWarning
+	for _, source := range sources {
+		checked, err := s.CheckSourceToken(ctx, source)
+		if err != nil {
+			log.Error().Err(err).Str("source", source.Name).Msg("failed to check source token")
+			continue
+		}
+		if warning := TokenWarning(*checked, time.Now()); warning != nil {
+			warnings = append(warnings, *warning)
+		}
+	}
+	return warnings, nil
+}
+
+// TokenWarning returns a warning if the last check found the token of the source invalid,
+// or if it expires within domain.TokenExpiryWarningDays
+func TokenWarning(source domain.Source, now time.Time) *domain.SourceTokenWarning {
+	warning := &domain.SourceTokenWarning{
+		SourceID:   source.ID,
+		SourceName: source.Name,
+		ExpiresAt:  source.TokenExpiresAt,
+		Error:      source.TokenError,
+	}
+
+	if source.TokenExpiresAt != nil {
+		days := int(math.Floor(source.TokenExpiresAt.Sub(now).Hours() / 24))
+		warning.DaysLeft = &days
+	}
+
+	switch {
+	case source.TokenStatus == domain.TokenStatusInvalid:
+		warning.Status = "invalid"
+	case source.TokenExpiresAt == nil:
+		return nil
+	case !source.TokenExpiresAt.After(now):
+		warning.Status = "expired"
+	case *warning.DaysLeft < domain.TokenExpiryWarningDays:
+		warning.Status = "expiring"
+	default:
+		return nil
+	}
+	return warning
+}
@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestTokenWarning(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name     string
+		source   domain.Source
+		want     string
+		wantDays int
+	}{
+		{"valid without expiration", domain.Source{TokenStatus: domain.TokenStatusValid}, "", 0},
+		{"not checked", domain.Source{}, "", 0},
+		{"invalid", domain.Source{TokenStatus: domain.TokenStatusInvalid, TokenError: "401"}, "invalid", 0},
+		{"expires later", domain.Source{TokenStatus: domain.TokenStatusValid, TokenExpiresAt: at(60 * 24 * time.Hour)}, "", 0},
+		{"expiring", domain.Source{TokenStatus: domain.TokenStatusValid, TokenExpiresAt: at(5*24*time.Hour + time.Hour)}, "expiring", 5},
+		{"expired", domain.Source{TokenStatus: domain.TokenStatusValid, TokenExpiresAt: at(-time.Hour)}, "expired", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warning := TokenWarning(tt.source, now)
+			if tt.want == "" {
+				if warning != nil {
+					t.Errorf("TokenWarning() = %+v, want nil", warning)
+				}
+				return
+			}
+			if warning == nil || warning.Status != tt.want {
+				t.Fatalf("TokenWarning() = %+v, want status %q", warning, tt.want)
+			}
+			if tt.source.TokenExpiresAt != nil && (warning.DaysLeft == nil || *warning.DaysLeft != tt.wantDays) {
+				t.Errorf("DaysLeft = %v, want %d", warning.DaysLeft, tt.wantDays)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// recordGoRuntime sets the Go version, toolchain and vendoring of the root-most go.mod of a
+// repository and compares the toolchain against the latest Go release. A failed release lookup
+// is logged and leaves the comparison out.
+func (s *Scanner) recordGoRuntime(ctx context.Context, provider GitProvider, repo *domain.Repository, ref string, manifests []manifestFile) {
+	var goMod *manifestFile
+	for i, m := range manifests {
+		if m.kind != "go.mod" || m.branch != "" {
+			continue
+		}
+		if goMod == nil || strings.Count(m.path, "/") < strings.Count(goMod.path, "/") {
+			goMod = &manifests[i]
+		}
+	}
+	if goMod == nil {
+		return
+	}
+
+	repo.GoVersion, repo.GoToolchain = parseGoDirectives(string(goMod.content))
+
+	// go mod vendor writes vendor/modules.txt next to go.mod
+	modulesTxt := path.Join(path.Dir(goMod.path), "vendor", "modules.txt")
+	if _, err := provider.GetFileContent(ctx, repo.FullName, modulesTxt, ref); err == nil {
+		repo.GoVendored = true
+	}
+
+	current := goToolchainVersion(repo.GoVersion, repo.GoToolchain)
+	if current == "" {
+		return
+	}
+	var latest string
+	var err error
+	if s.registry != nil {
+		latest, err = s.registry.LatestVersion(ctx, domain.Dependency{Name: "go", Ecosystem: "go"})
+	} else {
+		latest, err = s.goClient.GetLatestRelease(ctx)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("repo", repo.FullName).Msg("failed to fetch the latest Go release")
+		return
+	}
+	repo.GoLatestVersion = strings.TrimPrefix(latest, "go")
+	repo.GoToolchainOutdated = IsOutdated(current, repo.GoLatestVersion)
+}
+
+// parseGoDirectives returns the go and toolchain directives of a go.mod, e.g. 1.22 and go1.22.3.
+// A toolchain of "default" is left out.
+func parseGoDirectives(content string) (goVersion, toolchain string) {
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "go":
+			goVersion = fields[1]
+		case "toolchain":
+			if fields[1] != "default" {
+				toolchain = fields[1]
+			}
+		}
+	}
+	return goVersion, toolchain
+}
+
+// goToolchainVersion returns the Go version a module builds with: its toolchain when set,
+// otherwise its go version
+func goToolchainVersion(goVersion, toolchain string) string {
+	if toolchain != "" {
+		return strings.TrimPrefix(toolchain, "go")
+	}
+	return goVersion
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// handleScanFailures disables the sources that failed SourceDisableAfter scans in a row, then
+// emails the sources that failed during a scan and posts them to the failure webhook. A source is
+// notified at most once every FailureNotifyHours, so a flapping token does not alert on every
+// scan; disabling a source is always notified.
+func (s *Scheduler) handleScanFailures(ctx context.Context, scanID int64, scanErr error) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load settings for scan failure notification")
+		return
+	}
+
+	scan, err := s.scanRepo.GetByID(ctx, scanID)
+	if err != nil {
+		log.Error().Err(err).Int64("scan_id", scanID).Msg("failed to load scan for failure notification")
+		return
+	}
+	sources, err := s.sourceRepo.GetAll(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load sources for failure notification")
+		return
+	}
+
+	failures := scanFailures(scan, sources, scanErr)
+	if len(failures) == 0 {
+		return
+	}
+	s.disableFailingSources(ctx, failures, settings.SourceDisableAfter)
+
+	emailEnabled := settings.EmailEnabled && settings.FailureNotifyEmail
+	if !emailEnabled && settings.FailureWebhookURL == "" {
+		return
+	}
+
+	now := time.Now()
+	if settings.FailureNotifyHours > 0 {
+		var disabled, throttled []domain.SourceScanFailure
+		for _, f := range failures {
+			if f.Disabled {
+				disabled = append(disabled, f)
+			} else {
+				throttled = append(throttled, f)
+			}
+		}
+
+		since := now.Add(-time.Duration(settings.FailureNotifyHours) * time.Hour)
+		filtered, err := s.notificationRepo.FilterFailuresNotified(ctx, throttled, since)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to filter recently notified scan failures")
+		} else {
+			if suppressed := len(throttled) - len(filtered); suppressed > 0 {
+				log.Info().Int("suppressed", suppressed).Int("notify_hours", settings.FailureNotifyHours).Msg("skipping recently notified scan failures")
+			}
+			failures = append(disabled, filtered...)
+		}
+	}
+	if len(failures) == 0 {
+		return
+	}
+
+	notified := false
+	if emailEnabled {
+		if err := s.emailService.SendScanFailures(settings, failures); err != nil {
+			log.Error().Err(err).Msg("failed to send scan failure email")
+		} else {
+			notified = true
+		}
+	}
+	if settings.FailureWebhookURL != "" {
+		if err := s.observability.SendScanFailures(ctx, settings, failures); err != nil {
+			log.Error().Err(err).Msg("failed to send scan failure webhook")
+		} else {
+			notified = true
+		}
+	}
+
+	if notified {
+		if err := s.notificationRepo.MarkFailuresNotified(ctx, failures, now); err != nil {
+			log.Warn().Err(err).Msg("failed to record notified scan failures")
+		}
+	}
+}
+
+// disableFailingSources puts the sources that failed at least threshold scans in a row in the
+// error state and marks their failures as disabling them. A threshold of 0 never disables sources.
+func (s *Scheduler) disableFailingSources(ctx context.Context, failures []domain.SourceScanFailure, threshold int) {
+	if threshold <= 0 {
+		return
+	}
+	for i, f := range failures {
+		if f.SourceID == 0 || f.ConsecutiveFailures < threshold {
+			continue
+		}
+		disabled, err := s.sourceRepo.Disable(ctx, f.SourceID)
+		if err != nil {
+			log.Error().Err(err).Str("source", f.SourceName).Msg("failed to disable failing source")
+			continue
+		}
+		if disabled {
+			log.Warn().Str("source", f.SourceName).Int("consecutive_failures", f.ConsecutiveFailures).Msg("disabled source after repeated scan failures")
+			failures[i].Disabled = true
+		}
+	}
+}
+
+// scanFailures returns the sources whose scan failed since the scan started. A failed scan
+// without a failing source, e.g. one that could not load its sources, is returned as a failure
+// without source; canceled scans are not failures.
+func scanFailures(scan *domain.ScanJob, sources []domain.Source, scanErr error) []domain.SourceScanFailure {
+	var failures []domain.SourceScanFailure
+	for _, source := range sources {
+		if source.LastScanError == "" || source.LastScanErrorAt == nil {
+			continue
+		}
+		if scan.StartedAt != nil && source.LastScanErrorAt.Before(*scan.StartedAt) {
+			continue
+		}
+		failures = append(failures, domain.SourceScanFailure{
+			ScanID:              scan.ID,
+			SourceID:            source.ID,
+			SourceName:          source.Name,
+			Error:               source.LastScanError,
+			FailedAt:            *source.LastScanErrorAt,
+			ConsecutiveFailures: source.ConsecutiveFailures,
+		})
+	}
+
+	if scanErr != nil && len(failures) == 0 && !errors.Is(scanErr, context.Canceled) {
+		failures = append(failures, domain.SourceScanFailure{ScanID: scan.ID, Error: scanErr.Error(), FailedAt: time.Now()})
+	}
+	return failures
+}
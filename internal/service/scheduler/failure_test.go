@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestScanFailures(t *testing.T) {
+	started := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		v := started.Add(d)
+		return &v
+	}
+	scan := &domain.ScanJob{ID: 7, StartedAt: &started}
+	sources := []domain.Source{
+		{ID: 1, Name: "github", LastScanError: "401 Bad credentials", LastScanErrorAt: at(time.Minute), ConsecutiveFailures: 3},
+		{ID: 2, Name: "gitlab", LastScanError: "connection refused", LastScanErrorAt: at(-time.Hour)}, // failed in an earlier scan
+		{ID: 3, Name: "healthy"},
+	}
+
+	failures := scanFailures(scan, sources, nil)
+	if len(failures) != 1 || failures[0].SourceID != 1 || failures[0].ScanID != 7 || failures[0].Error != "401 Bad credentials" || failures[0].ConsecutiveFailures != 3 {
+		t.Errorf("scanFailures() = %+v, want the source that failed during the scan", failures)
+	}
+
+	failures = scanFailures(scan, sources[1:], errors.New("database is locked"))
+	if len(failures) != 1 || failures[0].SourceID != 0 || failures[0].Error != "database is locked" {
+		t.Errorf("scanFailures() = %+v, want the scan error", failures)
+	}
+
+	if failures := scanFailures(scan, sources[1:], context.Canceled); len(failures) != 0 {
+		t.Errorf("scanFailures() = %+v, want canceled scans left out", failures)
+	}
+}
@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// outboxRetention is how long integration events stay in the outbox
+const outboxRetention = 30 * 24 * time.Hour
+
+// SetOutbox makes finished scans append dependency.outdated and scan.completed events to outbox.
+// Call it before Start.
+func (s *Scheduler) SetOutbox(outbox *repository.OutboxRepository) {
+	s.outbox = outbox
+}
+
+// recordScanEvents appends an event for each dependency that became outdated in a successful
+// scan, followed by one for the finished scan
+func (s *Scheduler) recordScanEvents(ctx context.Context, scanID int64, scanErr error) {
+	if s.outbox == nil {
+		return
+	}
+
+	var events []domain.OutboxEvent
+	if scanErr == nil {
+		deps, err := s.depRepo.GetNewlyOutdated(ctx)
+		if err != nil {
+			log.Warn().Err(err).Int64("scan_id", scanID).Msg("failed to get newly outdated dependencies for the outbox")
+		}
+		for _, dep := range deps {
+			if event, err := domain.NewOutboxEvent(domain.OutboxDependencyOutdated, dep); err == nil {
+				events = append(events, event)
+			}
+		}
+	}
+
+	scan, err := s.scanRepo.GetByID(ctx, scanID)
+	if err != nil {
+		log.Warn().Err(err).Int64("scan_id", scanID).Msg("failed to get scan for the outbox")
+	} else if event, err := domain.NewOutboxEvent(domain.OutboxScanCompleted, scan); err == nil {
+		events = append(events, event)
+	}
+
+	if err := s.outbox.Append(ctx, events); err != nil {
+		log.Error().Err(err).Int64("scan_id", scanID).Msg("failed to append scan events to the outbox")
+	}
+}
+
+// pruneOutbox removes integration events older than outboxRetention
+func (s *Scheduler) pruneOutbox() {
+	removed, err := s.outbox.DeleteBefore(context.Background(), time.Now().Add(-outboxRetention))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to prune the outbox")
+		return
+	}
+	if removed > 0 {
+		log.Info().Int64("events", removed).Msg("pruned old outbox events")
+	}
+}
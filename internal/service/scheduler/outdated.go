@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/service/scanner"
+	"github.com/rs/zerolog/log"
+)
+
+// comparison returns the version comparison configured in the settings
+func comparison(settings *domain.Settings) scanner.Comparison {
+	return scanner.Comparison{IgnorePrereleases: settings.IgnorePrereleases}
+}
+
+// applyComparison makes later scans compare versions as configured in the settings
+func (s *Scheduler) applyComparison(ctx context.Context) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load version comparison settings")
+		return
+	}
+	s.scanner.SetComparison(comparison(settings))
+}
+
+// ApplyRegistryFallback makes later scans fall back to last known latest versions when a
+// registry cannot be reached, as configured in the settings
+func (s *Scheduler) ApplyRegistryFallback(ctx context.Context) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load registry fallback setting")
+		return
+	}
+	s.scanner.SetRegistryFallback(settings.RegistryFallback)
+}
+
+// OnCacheSettings registers a callback applying the cache settings, called by ApplyCacheSettings
+func (s *Scheduler) OnCacheSettings(callback func(*domain.Settings)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onCacheSettings = append(s.onCacheSettings, callback)
+}
+
+// ApplyCacheSettings changes the lifetimes of the registry caches and of the results of unchanged
+// manifests of later scans, and of the caches registered with OnCacheSettings, to the ones
+// configured in the settings
+func (s *Scheduler) ApplyCacheSettings(ctx context.Context) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load cache settings")
+		return
+	}
+	overrides, err := scanner.ParseCacheTTLOverrides(settings.RegistryCacheOverrides)
+	if err != nil {
+		log.Warn().Err(err).Msg("ignoring invalid registry cache overrides")
+	}
+	s.scanner.SetRegistryCacheTTLs(time.Duration(settings.RegistryCacheSeconds)*time.Second, overrides)
+	s.scanner.SetManifestRefreshInterval(time.Duration(settings.ManifestRefreshHours) * time.Hour)
+
+	s.mu.Lock()
+	callbacks := slices.Clone(s.onCacheSettings)
+	s.mu.Unlock()
+	for _, callback := range callbacks {
+		callback(settings)
+	}
+}
+
+// RecomputeOutdated re-evaluates whether each stored dependency is outdated with the version
+// comparison of the current settings, from the stored versions and without scanning providers.
+// Later scans use the same comparison. Returns the number of dependencies changed.
+func (s *Scheduler) RecomputeOutdated(ctx context.Context) (int64, error) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	cmp := comparison(settings)
+	s.scanner.SetComparison(cmp)
+
+	n, err := s.depRepo.RecomputeOutdated(ctx, cmp.IsOutdated)
+	if err != nil {
+		return 0, err
+	}
+	log.Info().Int64("changed", n).Bool("ignore_prereleases", cmp.IgnorePrereleases).Msg("recomputed outdated dependencies")
+
+	// Cached results (e.g. dependency stats) are stale like after a scan
+	if n > 0 {
+		s.notifyScanComplete()
+	}
+	return n, nil
+}
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// State returns whether scheduled scans are paused, whether maintenance mode is enabled and how
+// many scheduled runs are queued
+func (s *Scheduler) State(ctx context.Context) (*domain.SchedulerState, error) {
+	state, err := s.settingsRepo.GetSchedulerState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state.QueuedRuns = s.QueuedRuns()
+	return state, nil
+}
+
+// Pause stops scheduled scans until Resume is called or until passes, keeping the cron
+// configuration. Maintenance mode rejects manual scans as well.
+func (s *Scheduler) Pause(ctx context.Context, reason string, until *time.Time, maintenance bool) (*domain.SchedulerState, error) {
+	now := time.Now().UTC()
+	state := &domain.SchedulerState{
+		Paused:          true,
+		PausedAt:        &now,
+		PausedUntil:     until,
+		PauseReason:     reason,
+		MaintenanceMode: maintenance,
+	}
+	if err := s.settingsRepo.SetSchedulerState(ctx, state); err != nil {
+		return nil, err
+	}
+
+	event := log.Info().Str("reason", reason).Bool("maintenance_mode", maintenance)
+	if until != nil {
+		event = event.Time("until", *until)
+	}
+	event.Msg("scheduled scans paused")
+	return state, nil
+}
+
+// CheckWritable returns ErrMaintenanceMode while maintenance mode is enabled. Results recorded
+// outside of scans, by ad-hoc scans and ingestion, check it before writing.
+func (s *Scheduler) CheckWritable(ctx context.Context) error {
+	state, err := s.State(ctx)
+	if err != nil {
+		return err
+	}
+	if state.InMaintenance(time.Now()) {
+		return ErrMaintenanceMode
+	}
+	return nil
+}
+
+// Resume resumes scheduled scans and leaves maintenance mode
+func (s *Scheduler) Resume(ctx context.Context) (*domain.SchedulerState, error) {
+	state := &domain.SchedulerState{}
+	if err := s.settingsRepo.SetSchedulerState(ctx, state); err != nil {
+		return nil, err
+	}
+	log.Info().Msg("scheduled scans resumed")
+	return state, nil
+}
+
+// scheduledScansPaused reports whether scheduled scans are paused. Scans run when the state
+// cannot be loaded, so a database hiccup does not silently stop them.
+func (s *Scheduler) scheduledScansPaused(ctx context.Context) bool {
+	state, err := s.State(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load scheduler state")
+		return false
+	}
+	return state.ScansPaused(time.Now())
+}
@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jiin/stale/internal/repository"
+	"github.com/jiin/stale/internal/testutil"
+)
+
+func TestCheckWritable(t *testing.T) {
+	s := &Scheduler{settingsRepo: repository.NewSettingsRepository(testutil.NewDB(t))}
+	ctx := context.Background()
+
+	if err := s.CheckWritable(ctx); err != nil {
+		t.Fatalf("CheckWritable() = %v, want nil", err)
+	}
+
+	// Pausing scheduled scans alone still lets results be recorded
+	if _, err := s.Pause(ctx, "release freeze", nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CheckWritable(ctx); err != nil {
+		t.Errorf("CheckWritable() while paused = %v, want nil", err)
+	}
+
+	if _, err := s.Pause(ctx, "database migration", nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CheckWritable(ctx); !errors.Is(err, ErrMaintenanceMode) {
+		t.Errorf("CheckWritable() in maintenance = %v, want ErrMaintenanceMode", err)
+	}
+
+	if _, err := s.Resume(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CheckWritable(ctx); err != nil {
+		t.Errorf("CheckWritable() after resume = %v, want nil", err)
+	}
+}
@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+// minReposForRate is how many repositories a scan must have scanned before its own pace replaces
+// the pace of previous scans, which is noisy over the first few
+const minReposForRate = 5
+
+// EstimateProgress estimates the progress of a scan from the repositories it scanned so far
+// and previous completed scans of the same sources (history, newest first). The expected number of
+// repositories is the largest of those listed so far and those the last scan found.
+func EstimateProgress(scan domain.ScanJob, history []domain.ScanJob, now time.Time) domain.ScanProgress {
+	p := domain.ScanProgress{ReposDone: scan.ReposFound, ReposTotal: max(scan.ReposTotal, scan.ReposFound)}
+	if scan.StartedAt != nil {
+		p.ElapsedSeconds = max(now.Sub(*scan.StartedAt).Seconds(), 0)
+	}
+
+	var histRepos int
+	var histSeconds float64
+	for _, h := range history {
+		if h.StartedAt == nil || h.FinishedAt == nil || h.ReposFound == 0 {
+			continue
+		}
+		histRepos += h.ReposFound
+		histSeconds += h.FinishedAt.Sub(*h.StartedAt).Seconds()
+	}
+	if len(history) > 0 {
+		p.ReposTotal = max(p.ReposTotal, history[0].ReposFound)
+	}
+	if histRepos > 0 {
+		p.SecondsPerRepo = histSeconds / float64(histRepos)
+	}
+	if p.ReposDone > 0 && (p.ReposDone >= minReposForRate || p.SecondsPerRepo == 0) {
+		p.SecondsPerRepo = p.ElapsedSeconds / float64(p.ReposDone)
+	}
+
+	if p.ReposTotal > 0 {
+		p.Percent = float64(p.ReposDone) / float64(p.ReposTotal) * 100
+	}
+	if p.SecondsPerRepo > 0 {
+		p.RemainingSeconds = float64(p.ReposTotal-p.ReposDone) * p.SecondsPerRepo
+		p.EstimatedSeconds = p.ElapsedSeconds + p.RemainingSeconds
+		finish := now.Add(time.Duration(p.RemainingSeconds * float64(time.Second)))
+		p.EstimatedFinishAt = &finish
+	}
+	return p
+}
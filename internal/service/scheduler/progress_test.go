@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestEstimateProgress(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+	// Previous scans took 2s per repository
+	history := []domain.ScanJob{
+		{ReposFound: 100, StartedAt: at(-26 * time.Hour), FinishedAt: at(-26*time.Hour + 200*time.Second)},
+		{ReposFound: 50, StartedAt: at(-50 * time.Hour), FinishedAt: at(-50*time.Hour + 100*time.Second)},
+	}
+
+	tests := []struct {
+		name          string
+		scan          domain.ScanJob
+		history       []domain.ScanJob
+		wantTotal     int
+		wantPercent   float64
+		wantEstimated float64
+		wantRemaining float64
+	}{
+		{"starting", domain.ScanJob{StartedAt: at(0)}, history, 100, 0, 200, 200},
+		{"few repos use history pace", domain.ScanJob{ReposFound: 2, ReposTotal: 40, StartedAt: at(-10 * time.Second)}, history, 100, 2, 206, 196},
+		{"own pace", domain.ScanJob{ReposFound: 60, ReposTotal: 120, StartedAt: at(-60 * time.Second)}, history, 120, 50, 120, 60},
+		{"without history", domain.ScanJob{ReposFound: 1, ReposTotal: 4, StartedAt: at(-3 * time.Second)}, nil, 4, 25, 12, 9},
+		{"nothing to go by", domain.ScanJob{StartedAt: at(0)}, nil, 0, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := EstimateProgress(tt.scan, tt.history, now)
+			if p.ReposTotal != tt.wantTotal || p.Percent != tt.wantPercent || p.EstimatedSeconds != tt.wantEstimated || p.RemainingSeconds != tt.wantRemaining {
+				t.Errorf("EstimateProgress() = total %d, %.1f%%, estimated %.0fs, remaining %.0fs, want %d, %.1f%%, %.0fs, %.0fs",
+					p.ReposTotal, p.Percent, p.EstimatedSeconds, p.RemainingSeconds, tt.wantTotal, tt.wantPercent, tt.wantEstimated, tt.wantRemaining)
+			}
+			if (p.EstimatedFinishAt != nil) != (tt.wantEstimated > 0) {
+				t.Errorf("EstimatedFinishAt = %v, want it set only with an estimate", p.EstimatedFinishAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// queuedRun is a run postponed because another scan was running: the run of a source schedule,
+// of the global schedule when sourceID is nil, or a targeted rescan of repoIDs
+type queuedRun struct {
+	sourceID *int64
+	repoIDs  []int64
+}
+
+// queueWhenBusy reports whether scheduled runs firing during another scan are queued rather
+// than skipped
+func (s *Scheduler) queueWhenBusy(ctx context.Context) bool {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load settings for scheduled scan queueing")
+		return true
+	}
+	return settings.ScheduleQueueWhenBusy
+}
+
+// queueScheduledRun queues a scheduled run until the running scan completes, reporting false when
+// a run of the same schedule is already queued. Must be called with s.mu held.
+func (s *Scheduler) queueScheduledRun(sourceID *int64) bool {
+	for _, run := range s.queuedRuns {
+		if run.repoIDs == nil && sameSchedule(run.sourceID, sourceID) {
+			return false
+		}
+	}
+	s.queuedRuns = append(s.queuedRuns, queuedRun{sourceID: sourceID})
+	return true
+}
+
+// queueRescan queues a targeted rescan until the running scan completes. Repositories are added to
+// an already queued rescan, so they are all scanned in one run. Must be called with s.mu held.
+func (s *Scheduler) queueRescan(repoIDs []int64) {
+	for i, run := range s.queuedRuns {
+		if run.repoIDs != nil {
+			s.queuedRuns[i].repoIDs = mergeIDs(run.repoIDs, repoIDs)
+			return
+		}
+	}
+	s.queuedRuns = append(s.queuedRuns, queuedRun{repoIDs: mergeIDs(nil, repoIDs)})
+}
+
+// mergeIDs appends the ids not yet in list
+func mergeIDs(list, ids []int64) []int64 {
+	seen := make(map[int64]bool, len(list))
+	for _, id := range list {
+		seen[id] = true
+	}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			list = append(list, id)
+		}
+	}
+	return list
+}
+
+// releaseRunningJob marks a scan as no longer running and starts the oldest queued run.
+// It does nothing when another scan holds the slot, so a cancelled scan finishing late does not
+// release the scan started after it.
+func (s *Scheduler) releaseRunningJob(scanID int64) {
+	s.mu.Lock()
+	if s.runningJobID == nil || *s.runningJobID != scanID {
+		s.mu.Unlock()
+		return
+	}
+	s.runningJobID = nil
+
+	var next *queuedRun
+	if len(s.queuedRuns) > 0 {
+		next = &s.queuedRuns[0]
+		s.queuedRuns = s.queuedRuns[1:]
+	}
+	s.mu.Unlock()
+
+	switch {
+	case next == nil:
+	case next.repoIDs != nil:
+		log.Info().Int("repositories", len(next.repoIDs)).Msg("starting queued repository rescan")
+		go func() {
+			if _, _, err := s.startRescan(context.Background(), next.repoIDs); err != nil {
+				log.Error().Err(err).Msg("failed to start queued repository rescan")
+			}
+		}()
+	default:
+		log.Info().Msg("starting queued scheduled scan")
+		go s.runScheduledScan(next.sourceID)
+	}
+}
+
+// QueuedRuns returns the number of runs waiting for the running scan to complete
+func (s *Scheduler) QueuedRuns() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queuedRuns)
+}
+
+func sameSchedule(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
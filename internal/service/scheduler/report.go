@@ -0,0 +1,239 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// snapshotRetentionDays is how long archived state is kept, beyond the longest comparison period
+const snapshotRetentionDays = 100
+
+// archiveState archives the outdated dependencies after a completed scan, replacing the snapshot
+// of an earlier scan of the same day. Ignored dependencies are left out, so ignoring one counts as
+// fixed in later comparisons.
+func (s *Scheduler) archiveState(ctx context.Context, scanErr error) {
+	if scanErr != nil {
+		return
+	}
+	deps, err := s.reportedDependencies(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load dependencies to archive")
+		return
+	}
+
+	now := time.Now()
+	snapshot := domain.StateSnapshot{Day: domain.SnapshotDay(now), Dependencies: len(deps), TakenAt: now, Entries: outdatedEntries(deps)}
+	if err := s.snapshotRepo.Save(ctx, snapshot); err != nil {
+		log.Warn().Err(err).Msg("failed to archive the dependency state")
+	}
+}
+
+// pruneSnapshots removes archived state older than snapshotRetentionDays
+func (s *Scheduler) pruneSnapshots() {
+	removed, err := s.snapshotRepo.DeleteBefore(context.Background(), domain.SnapshotDay(time.Now().AddDate(0, 0, -snapshotRetentionDays)))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to prune archived dependency state")
+		return
+	}
+	if removed > 0 {
+		log.Info().Int64("snapshots", removed).Msg("pruned archived dependency state")
+	}
+}
+
+// ComparisonReport compares the outdated dependencies now with the state archived the given
+// numbers of days ago, or the latest state archived before then
+func (s *Scheduler) ComparisonReport(ctx context.Context, days []int) (*domain.ComparisonReport, error) {
+	deps, err := s.reportedDependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	current := outdatedEntries(deps)
+
+	report := &domain.ComparisonReport{GeneratedAt: now, Outdated: len(current)}
+	for _, d := range days {
+		baseline, err := s.snapshotRepo.GetOnOrBefore(ctx, domain.SnapshotDay(now.AddDate(0, 0, -d)))
+		if err != nil {
+			return nil, err
+		}
+		report.Periods = append(report.Periods, comparePeriod(d, current, baseline))
+	}
+	return report, nil
+}
+
+// sendMonthlyReport emails the comparison report when enabled in the settings
+func (s *Scheduler) sendMonthlyReport() {
+	ctx := context.Background()
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load settings for the monthly report")
+		return
+	}
+	if !settings.EmailEnabled || !settings.EmailMonthlyReport {
+		return
+	}
+
+	report, err := s.ComparisonReport(ctx, domain.ComparisonReportDays)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to build the monthly report")
+		return
+	}
+	if err := s.emailService.SendComparisonReport(settings, report); err != nil {
+		log.Error().Err(err).Msg("failed to send the monthly report")
+		return
+	}
+	log.Info().Int("outdated", report.Outdated).Msg("sent the monthly report")
+}
+
+// reportedDependencies returns the dependencies not excluded by an unexpired ignore rule
+func (s *Scheduler) reportedDependencies(ctx context.Context) ([]domain.DependencyWithRepo, error) {
+	deps, err := s.depRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.ignoredRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	reported := deps[:0]
+	for _, dep := range deps {
+		if !ignored(rules, dep, now) {
+			reported = append(reported, dep)
+		}
+	}
+	return reported, nil
+}
+
+// ignored reports whether an unexpired rule ignores the dependency: one of its package in any
+// ecosystem or its own, for every repository or its own
+func ignored(rules []domain.IgnoredDependency, dep domain.DependencyWithRepo, now time.Time) bool {
+	for _, rule := range rules {
+		if rule.Name != dep.Name || (rule.Ecosystem != "" && rule.Ecosystem != dep.Ecosystem) {
+			continue
+		}
+		if rule.Repository != "" && rule.Repository != dep.RepoFullName {
+			continue
+		}
+		if rule.ExpiresAt == nil || rule.ExpiresAt.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// outdatedEntries returns the outdated dependencies as archived
+func outdatedEntries(deps []domain.DependencyWithRepo) []domain.OutdatedEntry {
+	var entries []domain.OutdatedEntry
+	for _, dep := range deps {
+		if !dep.IsOutdated {
+			continue
+		}
+		entries = append(entries, domain.OutdatedEntry{
+			Repository:     dep.RepoFullName,
+			Source:         dep.SourceName,
+			Owners:         dep.RepoOwners,
+			ManifestPath:   dep.ManifestPath,
+			Name:           dep.Name,
+			Type:           dep.Type,
+			Ecosystem:      dep.Ecosystem,
+			CurrentVersion: dep.CurrentVersion,
+			LatestVersion:  dep.LatestVersion,
+		})
+	}
+	return entries
+}
+
+// comparePeriod compares the current outdated dependencies with an archived snapshot, which is
+// nil when no state that old is archived
+func comparePeriod(days int, current []domain.OutdatedEntry, baseline *domain.StateSnapshot) domain.ComparisonPeriod {
+	period := domain.ComparisonPeriod{Days: days, After: len(current), BySource: []domain.ComparisonGroup{}, ByTeam: []domain.ComparisonGroup{}}
+	if baseline == nil {
+		return period
+	}
+	period.SnapshotDay = baseline.Day
+	period.Before = len(baseline.Entries)
+
+	before := make(map[string]bool, len(baseline.Entries))
+	for _, e := range baseline.Entries {
+		before[e.Key()] = true
+	}
+	after := make(map[string]bool, len(current))
+	for _, e := range current {
+		after[e.Key()] = true
+	}
+
+	sources := make(map[string]*domain.ComparisonGroup)
+	teams := make(map[string]*domain.ComparisonGroup)
+	count := func(e domain.OutdatedEntry, update func(*domain.ComparisonGroup)) {
+		update(group(sources, e.Source))
+		for _, team := range teamsOf(e.Owners) {
+			update(group(teams, team))
+		}
+	}
+
+	for _, e := range baseline.Entries {
+		count(e, func(g *domain.ComparisonGroup) { g.Before++ })
+		if !after[e.Key()] {
+			period.Fixed++
+			count(e, func(g *domain.ComparisonGroup) { g.Fixed++ })
+		}
+	}
+	for _, e := range current {
+		count(e, func(g *domain.ComparisonGroup) { g.After++ })
+		if !before[e.Key()] {
+			period.NewlyOutdated++
+			count(e, func(g *domain.ComparisonGroup) { g.NewlyOutdated++ })
+		}
+	}
+	period.Net = period.After - period.Before
+
+	period.BySource = sortedGroups(sources)
+	period.ByTeam = sortedGroups(teams)
+	return period
+}
+
+func group(groups map[string]*domain.ComparisonGroup, name string) *domain.ComparisonGroup {
+	g, ok := groups[name]
+	if !ok {
+		g = &domain.ComparisonGroup{Name: name}
+		groups[name] = g
+	}
+	return g
+}
+
+// teamsOf returns the owners of a repository, or UnownedTeam when it has none
+func teamsOf(owners string) []string {
+	var teams []string
+	for _, owner := range strings.Split(owners, ",") {
+		if owner = strings.TrimSpace(owner); owner != "" {
+			teams = append(teams, owner)
+		}
+	}
+	if len(teams) == 0 {
+		return []string{domain.UnownedTeam}
+	}
+	return teams
+}
+
+// sortedGroups returns the groups with their net change, the most worsened first
+func sortedGroups(groups map[string]*domain.ComparisonGroup) []domain.ComparisonGroup {
+	sorted := make([]domain.ComparisonGroup, 0, len(groups))
+	for _, g := range groups {
+		g.Net = g.After - g.Before
+		sorted = append(sorted, *g)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Net != sorted[j].Net {
+			return sorted[i].Net > sorted[j].Net
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestComparePeriod(t *testing.T) {
+	lodash := domain.OutdatedEntry{Repository: "org/web", Source: "github", Owners: "frontend", Name: "lodash", CurrentVersion: "4.0.0"}
+	react := domain.OutdatedEntry{Repository: "org/web", Source: "github", Owners: "frontend", Name: "react", CurrentVersion: "17.0.0"}
+	gin := domain.OutdatedEntry{Repository: "group/api", Source: "gitlab", Name: "gin", CurrentVersion: "1.8.0"}
+	echo := domain.OutdatedEntry{Repository: "group/api", Source: "gitlab", Name: "echo", CurrentVersion: "4.0.0"}
+
+	baseline := &domain.StateSnapshot{Day: "2026-01-01", Entries: []domain.OutdatedEntry{lodash, gin}}
+	current := []domain.OutdatedEntry{react, gin, echo}
+
+	got := comparePeriod(30, current, baseline)
+	if got.SnapshotDay != "2026-01-01" || got.Before != 2 || got.After != 3 || got.NewlyOutdated != 2 || got.Fixed != 1 || got.Net != 1 {
+		t.Errorf("comparePeriod() = %+v, want 2 newly outdated, 1 fixed and a net change of 1", got)
+	}
+
+	wantSources := []domain.ComparisonGroup{
+		{Name: "gitlab", Before: 1, After: 2, NewlyOutdated: 1, Net: 1},
+		{Name: "github", Before: 1, After: 1, NewlyOutdated: 1, Fixed: 1, Net: 0},
+	}
+	if !reflect.DeepEqual(got.BySource, wantSources) {
+		t.Errorf("BySource = %+v, want %+v", got.BySource, wantSources)
+	}
+	wantTeams := []domain.ComparisonGroup{
+		{Name: domain.UnownedTeam, Before: 1, After: 2, NewlyOutdated: 1, Net: 1},
+		{Name: "frontend", Before: 1, After: 1, NewlyOutdated: 1, Fixed: 1, Net: 0},
+	}
+	if !reflect.DeepEqual(got.ByTeam, wantTeams) {
+		t.Errorf("ByTeam = %+v, want %+v", got.ByTeam, wantTeams)
+	}
+}
+
+func TestComparePeriod_UpgradedButStillOutdated(t *testing.T) {
+	old := domain.OutdatedEntry{Repository: "org/web", Name: "react", CurrentVersion: "16.0.0"}
+	upgraded := old
+	upgraded.CurrentVersion = "17.0.0"
+
+	got := comparePeriod(30, []domain.OutdatedEntry{upgraded}, &domain.StateSnapshot{Entries: []domain.OutdatedEntry{old}})
+	if got.NewlyOutdated != 0 || got.Fixed != 0 || got.Net != 0 {
+		t.Errorf("comparePeriod() = %+v, want no change for a dependency still outdated", got)
+	}
+}
+
+func TestComparePeriod_NoBaseline(t *testing.T) {
+	current := []domain.OutdatedEntry{{Repository: "org/web", Name: "react"}}
+
+	got := comparePeriod(90, current, nil)
+	if got.SnapshotDay != "" || got.After != 1 || got.NewlyOutdated != 0 || got.Net != 0 || len(got.BySource) != 0 {
+		t.Errorf("comparePeriod() = %+v, want only the current count without a baseline", got)
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	dep := domain.DependencyWithRepo{Dependency: domain.Dependency{Name: "lodash", Ecosystem: "npm"}, RepoFullName: "org/web"}
+
+	tests := []struct {
+		name string
+		rule domain.IgnoredDependency
+		want bool
+	}{
+		{"everywhere", domain.IgnoredDependency{Name: "lodash"}, true},
+		{"same ecosystem", domain.IgnoredDependency{Name: "lodash", Ecosystem: "npm"}, true},
+		{"other ecosystem", domain.IgnoredDependency{Name: "lodash", Ecosystem: "pypi"}, false},
+		{"same repository", domain.IgnoredDependency{Name: "lodash", Repository: "org/web"}, true},
+		{"other repository", domain.IgnoredDependency{Name: "lodash", Repository: "org/api"}, false},
+		{"other package", domain.IgnoredDependency{Name: "react"}, false},
+		{"expires later", domain.IgnoredDependency{Name: "lodash", ExpiresAt: &future}, true},
+		{"expired", domain.IgnoredDependency{Name: "lodash", ExpiresAt: &past}, false},
+	}
+	for _, tt := range tests {
+		if got := ignored([]domain.IgnoredDependency{tt.rule}, dep, now); got != tt.want {
+			t.Errorf("%s: ignored() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTeamsOf(t *testing.T) {
+	tests := []struct {
+		owners string
+		want   []string
+	}{
+		{"", []string{domain.UnownedTeam}},
+		{" , ", []string{domain.UnownedTeam}},
+		{"platform", []string{"platform"}},
+		{"platform, @org/web", []string{"platform", "@org/web"}},
+	}
+	for _, tt := range tests {
+		if got := teamsOf(tt.owners); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("teamsOf(%q) = %v, want %v", tt.owners, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// RescanStatus tells how a requested rescan of repositories is carried out
+type RescanStatus struct {
+	Scan     *domain.ScanJob     // scan started for the repositories due, nil when queued or none is due
+	Queued   bool                // the repositories due are scanned once the running scan completes
+	Deferred map[int64]time.Time // repositories rescanned too recently, by when their pending rescan runs
+}
+
+// RescanRepositories starts a scan of the given repositories only. When another scan is running
+// the rescan is queued until it completes; repositories queued meanwhile are scanned in the same
+// run. Repositories whose last requested rescan is more recent than the rescan interval setting
+// are deferred: rapid triggers are coalesced into one pending rescan that runs once the interval
+// has passed.
+func (s *Scheduler) RescanRepositories(ctx context.Context, repoIDs []int64) (*RescanStatus, error) {
+	state, err := s.State(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if state.InMaintenance(time.Now()) {
+		return nil, ErrMaintenanceMode
+	}
+	interval := s.rescanInterval(ctx)
+
+	s.mu.Lock()
+	due, deferred := s.throttleRescans(repoIDs, interval, time.Now())
+	status := &RescanStatus{Deferred: deferred}
+	if len(deferred) > 0 {
+		s.deferRescans(deferred)
+		log.Info().Int("repositories", len(deferred)).Msg("deferred rescan of recently rescanned repositories")
+	}
+	s.mu.Unlock()
+
+	if len(due) > 0 {
+		status.Scan, status.Queued, err = s.startRescan(ctx, due)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// startRescan starts a scan of the given repositories, or queues it while another scan is running
+func (s *Scheduler) startRescan(ctx context.Context, repoIDs []int64) (_ *domain.ScanJob, queued bool, _ error) {
+	state, err := s.State(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if state.InMaintenance(time.Now()) {
+		return nil, false, ErrMaintenanceMode
+	}
+
+	s.mu.Lock()
+	if s.runningJobID != nil {
+		s.queueRescan(repoIDs)
+		s.mu.Unlock()
+		log.Info().Int("repositories", len(repoIDs)).Msg("queued repository rescan - it runs once the running scan completes")
+		return nil, true, nil
+	}
+
+	scan, err := s.scanRepo.Create(ctx, nil)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, false, err
+	}
+
+	s.runningJobID = &scan.ID
+	s.mu.Unlock()
+
+	s.startScan(scan.ID, "rescan", func(ctx context.Context) error {
+		return s.scanner.ScanRepositories(ctx, scan.ID, repoIDs)
+	})
+	return scan, false, nil
+}
+
+// rescanInterval returns the minimum time between requested rescans of a repository
+func (s *Scheduler) rescanInterval(ctx context.Context) time.Duration {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load settings for repository rescan interval")
+		return 0
+	}
+	return time.Duration(settings.RescanIntervalMinutes) * time.Minute
+}
+
+// throttleRescans splits the repositories of a rescan request into those due, whose rescan is
+// recorded at now, and those rescanned within interval, with the time they are due again. Repositories
+// already waiting for a deferred rescan are deferred too. Must be called with s.mu held.
+func (s *Scheduler) throttleRescans(repoIDs []int64, interval time.Duration, now time.Time) (due []int64, deferred map[int64]time.Time) {
+	if s.rescannedAt == nil {
+		s.rescannedAt = make(map[int64]time.Time)
+	}
+	for id, at := range s.rescannedAt {
+		if now.Sub(at) >= interval && !s.rescanDeferred(id) {
+			delete(s.rescannedAt, id)
+		}
+	}
+
+	deferred = make(map[int64]time.Time)
+	seen := make(map[int64]bool, len(repoIDs))
+	for _, id := range repoIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if at, ok := s.rescannedAt[id]; ok && now.Sub(at) < interval {
+			deferred[id] = at.Add(interval)
+			continue
+		}
+		if s.rescanDeferred(id) {
+			deferred[id] = s.rescanDue
+			continue
+		}
+		s.rescannedAt[id] = now
+		due = append(due, id)
+	}
+	return due, deferred
+}
+
+func (s *Scheduler) rescanDeferred(id int64) bool {
+	for _, deferredID := range s.deferredRescans {
+		if deferredID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// deferRescans adds repositories to the pending deferred rescan, which runs when the earliest of
+// them is due. Repositories still rescanned too recently by then are deferred again. Must be
+// called with s.mu held.
+func (s *Scheduler) deferRescans(deferred map[int64]time.Time) {
+	due := time.Time{}
+	for id, at := range deferred {
+		s.deferredRescans = mergeIDs(s.deferredRescans, []int64{id})
+		if due.IsZero() || at.Before(due) {
+			due = at
+		}
+	}
+
+	if s.rescanTimer != nil && !due.Before(s.rescanDue) {
+		return
+	}
+	if s.rescanTimer != nil {
+		s.rescanTimer.Stop()
+	}
+	s.rescanDue = due
+	s.rescanTimer = time.AfterFunc(time.Until(due), s.runDeferredRescans)
+}
+
+// runDeferredRescans rescans the repositories of the pending deferred rescan
+func (s *Scheduler) runDeferredRescans() {
+	s.mu.Lock()
+	repoIDs := s.deferredRescans
+	s.deferredRescans, s.rescanTimer = nil, nil
+	s.mu.Unlock()
+
+	if len(repoIDs) == 0 {
+		return
+	}
+	log.Info().Int("repositories", len(repoIDs)).Msg("starting deferred repository rescan")
+	if _, err := s.RescanRepositories(context.Background(), repoIDs); err != nil {
+		log.Error().Err(err).Msg("failed to start deferred repository rescan")
+	}
+}
@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestThrottleRescans(t *testing.T) {
+	s := &Scheduler{}
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	interval := 5 * time.Minute
+
+	due, deferred := s.throttleRescans([]int64{1, 2, 2}, interval, now)
+	if !reflect.DeepEqual(due, []int64{1, 2}) || len(deferred) != 0 {
+		t.Fatalf("first request: due = %v, deferred = %v", due, deferred)
+	}
+
+	// Repositories rescanned within the interval are deferred until it has passed
+	due, deferred = s.throttleRescans([]int64{2, 3}, interval, now.Add(time.Minute))
+	if !reflect.DeepEqual(due, []int64{3}) {
+		t.Errorf("second request: due = %v, want [3]", due)
+	}
+	if got := deferred[2]; !got.Equal(now.Add(interval)) {
+		t.Errorf("repository 2 deferred until %v, want %v", got, now.Add(interval))
+	}
+
+	due, deferred = s.throttleRescans([]int64{1}, interval, now.Add(interval))
+	if !reflect.DeepEqual(due, []int64{1}) || len(deferred) != 0 {
+		t.Errorf("after the interval: due = %v, deferred = %v", due, deferred)
+	}
+}
+
+func TestThrottleRescans_PendingDeferred(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := &Scheduler{deferredRescans: []int64{4}, rescanDue: now.Add(time.Minute)}
+
+	// A repository waiting for the deferred rescan is not scanned twice
+	due, deferred := s.throttleRescans([]int64{4}, 5*time.Minute, now)
+	if len(due) != 0 {
+		t.Errorf("due = %v, want none", due)
+	}
+	if got := deferred[4]; !got.Equal(s.rescanDue) {
+		t.Errorf("deferred until %v, want %v", got, s.rescanDue)
+	}
+}
+
+func TestThrottleRescans_NoInterval(t *testing.T) {
+	s := &Scheduler{}
+	now := time.Now()
+
+	s.throttleRescans([]int64{1}, 0, now)
+	due, deferred := s.throttleRescans([]int64{1}, 0, now)
+	if !reflect.DeepEqual(due, []int64{1}) || len(deferred) != 0 {
+		t.Errorf("due = %v, deferred = %v, want every repository due", due, deferred)
+	}
+}
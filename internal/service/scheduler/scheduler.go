@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"sync"
 	"time"
@@ -9,42 +10,79 @@ import (
 	"github.com/jiin/stale/internal/domain"
 	"github.com/jiin/stale/internal/repository"
 	"github.com/jiin/stale/internal/service/email"
+	"github.com/jiin/stale/internal/service/observability"
 	"github.com/jiin/stale/internal/service/scanner"
+	"github.com/jiin/stale/internal/service/tracing"
 	"github.com/robfig/cron/v3"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-var ErrScanAlreadyRunning = errors.New("a scan is already running")
+var (
+	ErrScanAlreadyRunning = errors.New("a scan is already running")
+	ErrMaintenanceMode    = errors.New("maintenance mode is enabled")
+	ErrSourceDisabled     = errors.New("source is disabled after repeated scan failures")
+)
 
 type Scheduler struct {
 	scanner          *scanner.Scanner
 	scanRepo         *repository.ScanRepository
+	sourceRepo       *repository.SourceRepository
 	depRepo          *repository.DependencyRepository
+	ignoredRepo      *repository.IgnoredRepository
 	settingsRepo     *repository.SettingsRepository
+	notificationRepo *repository.NotificationRepository
+	viewRepo         *repository.ViewRepository
+	snapshotRepo     *repository.SnapshotRepository
 	emailService     *email.Service
+	observability    *observability.Service
 	cron             *cron.Cron
 	cronEntryID      cron.EntryID
+	sourceEntries    map[int64]cron.EntryID // cron entries of sources with their own schedule
 	stopCh           chan struct{}
 	mu               sync.Mutex
 	runningJobID     *int64
+	queuedRuns       []queuedRun // runs waiting for the running scan to complete
+	rescannedAt      map[int64]time.Time // when requested rescans of repositories were last started
+	deferredRescans  []int64             // repositories rescanned too recently, rescanned by rescanTimer
+	rescanTimer      *time.Timer
+	rescanDue        time.Time
 	onScanComplete   []func() // Callbacks to run after scan completes
+
+	onCacheSettings []func(*domain.Settings) // Callbacks applying the cache settings, see ApplyCacheSettings
+
+	prefetchPackages int // popular packages whose latest versions are prefetched, see SetPrefetch
+
+	outbox *repository.OutboxRepository // receives integration events when set, see SetOutbox
 }
 
 func New(
 	scanner *scanner.Scanner,
 	scanRepo *repository.ScanRepository,
+	sourceRepo *repository.SourceRepository,
 	depRepo *repository.DependencyRepository,
+	ignoredRepo *repository.IgnoredRepository,
 	settingsRepo *repository.SettingsRepository,
+	notificationRepo *repository.NotificationRepository,
+	viewRepo *repository.ViewRepository,
+	snapshotRepo *repository.SnapshotRepository,
 	emailService *email.Service,
+	observabilityService *observability.Service,
 ) *Scheduler {
 	return &Scheduler{
-		scanner:      scanner,
-		scanRepo:     scanRepo,
-		depRepo:      depRepo,
-		settingsRepo: settingsRepo,
-		emailService: emailService,
-		cron:         cron.New(cron.WithLocation(time.Local)),
-		stopCh:       make(chan struct{}),
+		scanner:          scanner,
+		scanRepo:         scanRepo,
+		sourceRepo:       sourceRepo,
+		depRepo:          depRepo,
+		ignoredRepo:      ignoredRepo,
+		settingsRepo:     settingsRepo,
+		notificationRepo: notificationRepo,
+		viewRepo:         viewRepo,
+		snapshotRepo:     snapshotRepo,
+		emailService:     emailService,
+		observability:    observabilityService,
+		cron:             cron.New(cron.WithLocation(time.Local)),
+		stopCh:           make(chan struct{}),
 	}
 }
 
@@ -59,6 +97,51 @@ func (s *Scheduler) Start() {
 
 	// Load settings and configure cron
 	s.ReloadSchedule()
+	s.ReloadSourceSchedules()
+	s.applyComparison(ctx)
+	s.ApplyRegistryFallback(ctx)
+	s.ApplyCacheSettings(ctx)
+
+	// Check source tokens daily (and once on startup) to warn before scans start failing
+	if _, err := s.cron.AddFunc("@daily", s.checkSourceTokens); err != nil {
+		log.Error().Err(err).Msg("failed to schedule source token checks")
+	}
+	go s.checkSourceTokens()
+
+	// Remove expired ignore rules daily (and once on startup), and remind about the ones
+	// expiring in the coming week every Monday morning
+	if _, err := s.cron.AddFunc("@daily", s.expireIgnores); err != nil {
+		log.Error().Err(err).Msg("failed to schedule ignore rule expiration")
+	}
+	if _, err := s.cron.AddFunc("0 9 * * 1", s.remindExpiringIgnores); err != nil {
+		log.Error().Err(err).Msg("failed to schedule ignore rule expiry reminders")
+	}
+	go s.expireIgnores()
+
+	// Warm the registry caches with the most used packages, as before every scheduled scan
+	go s.prefetch(ctx)
+
+	// Refresh the maintainers of the packages in use daily (and once on startup)
+	if _, err := s.cron.AddFunc("@daily", s.refreshMaintainers); err != nil {
+		log.Error().Err(err).Msg("failed to schedule package maintainer refresh")
+	}
+	go s.refreshMaintainers()
+
+	// Archive the state of the dependencies after scans, keeping it for snapshotRetentionDays, and
+	// email how it changed on the first day of each month
+	if _, err := s.cron.AddFunc("@daily", s.pruneSnapshots); err != nil {
+		log.Error().Err(err).Msg("failed to schedule archived state pruning")
+	}
+	if _, err := s.cron.AddFunc("0 8 1 * *", s.sendMonthlyReport); err != nil {
+		log.Error().Err(err).Msg("failed to schedule the monthly report")
+	}
+
+	// Remove old integration events daily
+	if s.outbox != nil {
+		if _, err := s.cron.AddFunc("@daily", s.pruneOutbox); err != nil {
+			log.Error().Err(err).Msg("failed to schedule outbox pruning")
+		}
+	}
 
 	// Start cron scheduler
 	s.cron.Start()
@@ -93,27 +176,63 @@ func (s *Scheduler) ReloadSchedule() {
 	}
 
 	// Add new cron job
-	entryID, err := s.cron.AddFunc(settings.ScheduleCron, s.runScheduledScan)
+	entryID, err := s.cron.AddFunc(cronSpec(settings.ScheduleCron, settings.ScheduleTimezone), func() { s.runScheduledScan(nil) })
 	if err != nil {
-		log.Error().Err(err).Str("cron", settings.ScheduleCron).Msg("invalid cron expression")
+		log.Error().Err(err).Str("cron", settings.ScheduleCron).Str("timezone", settings.ScheduleTimezone).Msg("invalid cron expression")
 		return
 	}
 
 	s.cronEntryID = entryID
-	log.Info().Str("cron", settings.ScheduleCron).Msg("scheduled scan configured")
+	log.Info().Str("cron", settings.ScheduleCron).Str("timezone", settings.ScheduleTimezone).Msg("scheduled scan configured")
+}
+
+// ReloadSourceSchedules replaces the cron entries of sources that define their own schedule.
+// Call it after sources are created, updated or deleted.
+func (s *Scheduler) ReloadSourceSchedules() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	sources, err := s.sourceRepo.GetAll(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load sources for scheduler")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entryID := range s.sourceEntries {
+		s.cron.Remove(entryID)
+	}
+	s.sourceEntries = make(map[int64]cron.EntryID)
+
+	for _, source := range sources {
+		if source.ScheduleCron == "" {
+			continue
+		}
+		sourceID := source.ID
+		entryID, err := s.cron.AddFunc(cronSpec(source.ScheduleCron, source.ScheduleTimezone), func() { s.runScheduledScan(&sourceID) })
+		if err != nil {
+			log.Error().Err(err).Str("source", source.Name).Str("cron", source.ScheduleCron).Str("timezone", source.ScheduleTimezone).Msg("invalid source cron expression")
+			continue
+		}
+		s.sourceEntries[sourceID] = entryID
+		log.Info().Str("source", source.Name).Str("cron", source.ScheduleCron).Str("timezone", source.ScheduleTimezone).Msg("source scan schedule configured")
+	}
 }
 
 func (s *Scheduler) Stop() {
 	s.cron.Stop()
 	close(s.stopCh)
-}
 
-func (s *Scheduler) ClearRunningJob(scanID int64) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	if s.runningJobID != nil && *s.runningJobID == scanID {
-		s.runningJobID = nil
+	if s.rescanTimer != nil {
+		s.rescanTimer.Stop()
 	}
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) ClearRunningJob(scanID int64) {
+	s.releaseRunningJob(scanID)
 }
 
 // OnScanComplete registers a callback to run after scan completes
@@ -134,17 +253,45 @@ func (s *Scheduler) notifyScanComplete() {
 	}
 }
 
-func (s *Scheduler) runScheduledScan() {
+// runScheduledScan runs a scheduled scan of the given source, or of all sources without their
+// own schedule when sourceID is nil. Sources outside their scan window are skipped, and nothing
+// runs while the scheduler is paused.
+func (s *Scheduler) runScheduledScan(sourceID *int64) {
+	ctx := context.Background()
+
+	if s.scheduledScansPaused(ctx) {
+		log.Info().Msg("skipping scheduled scan - scheduler is paused")
+		return
+	}
+
+	all, err := s.sourceRepo.GetAll(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load sources for scheduled scan")
+		return
+	}
+	sources := scheduledSources(all, sourceID, time.Now())
+	if len(sources) == 0 {
+		log.Info().Msg("skipping scheduled scan - no sources to scan within their scan window")
+		return
+	}
+	queueWhenBusy := s.queueWhenBusy(ctx)
+
 	s.mu.Lock()
 	if s.runningJobID != nil {
+		if queueWhenBusy {
+			queued := s.queueScheduledRun(sourceID)
+			s.mu.Unlock()
+			if queued {
+				log.Info().Msg("queued scheduled scan - it runs once the running scan completes")
+			}
+			return
+		}
 		s.mu.Unlock()
 		log.Info().Msg("skipping scheduled scan - another scan is already running")
 		return
 	}
 
-	ctx := context.Background()
-
-	scan, err := s.scanRepo.Create(ctx, nil)
+	scan, err := s.scanRepo.Create(ctx, sourceID)
 	if err != nil {
 		s.mu.Unlock()
 		log.Error().Err(err).Msg("failed to create scheduled scan job")
@@ -155,14 +302,13 @@ func (s *Scheduler) runScheduledScan() {
 	s.mu.Unlock()
 
 	// Clear running job ID when done
-	defer func() {
-		s.mu.Lock()
-		s.runningJobID = nil
-		s.mu.Unlock()
-	}()
+	defer s.releaseRunningJob(scan.ID)
 
 	log.Info().Int64("scan_id", scan.ID).Msg("starting scheduled scan")
 
+	ctx, span := tracing.Start(ctx, "scan", attribute.Int64("scan.id", scan.ID), attribute.String("scan.trigger", "scheduled"))
+	defer span.End()
+
 	if err := s.scanRepo.UpdateStatus(ctx, scan.ID, domain.ScanStatusRunning, nil); err != nil {
 		log.Error().Err(err).Msg("failed to update scan status to running")
 		return
@@ -173,7 +319,10 @@ func (s *Scheduler) runScheduledScan() {
 		log.Warn().Err(err).Msg("failed to mark previously outdated dependencies")
 	}
 
-	scanErr := s.scanner.ScanAll(ctx, scan.ID)
+	s.prefetch(ctx)
+
+	scanErr := s.scanner.ScanSources(ctx, scan.ID, sources)
+	tracing.RecordError(span, scanErr)
 
 	status := domain.ScanStatusCompleted
 	if scanErr != nil {
@@ -188,6 +337,10 @@ func (s *Scheduler) runScheduledScan() {
 	if err := s.scanRepo.UpdateStatus(ctx, scan.ID, status, scanErr); err != nil {
 		log.Error().Err(err).Msg("failed to update scan status")
 	}
+	s.sendScanSummary(ctx, scan.ID)
+	s.recordScanEvents(ctx, scan.ID, scanErr)
+	s.archiveState(ctx, scanErr)
+	s.handleScanFailures(ctx, scan.ID, scanErr)
 
 	// Notify scan complete callbacks (cache invalidation, etc.)
 	s.notifyScanComplete()
@@ -204,29 +357,365 @@ func (s *Scheduler) sendNewOutdatedNotification(ctx context.Context, scanID int6
 		return
 	}
 
-	// Get newly outdated dependencies
-	newOutdated, err := s.depRepo.GetNewlyOutdated(ctx)
+	now := time.Now()
+	report, err := s.newOutdatedNotification(ctx, scanID, settings, now)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get newly outdated dependencies")
 		return
 	}
 
-	if len(newOutdated) == 0 {
-		log.Debug().Msg("no new outdated dependencies to report")
+	if len(report.NewOutdated) == 0 && report.NewRepos == 0 && report.RemovedRepos == 0 {
+		log.Debug().Msg("no new outdated dependencies or repository changes to report")
+		return
+	}
+
+	thread, started := s.notificationThread(ctx, settings, domain.NotificationThreadNewOutdated, now)
+	if err := s.emailService.SendNewOutdatedReport(settings, report, thread); err != nil {
+		log.Error().Err(err).Msg("failed to send email notification")
+		return
+	}
+
+	if err := s.notificationRepo.MarkNotified(ctx, report.NewOutdated, now); err != nil {
+		log.Warn().Err(err).Msg("failed to record notified dependencies")
+	}
+	if started {
+		if err := s.notificationRepo.SetThread(ctx, domain.NotificationThread{Thread: domain.NotificationThreadNewOutdated, MessageID: thread.MessageID, StartedAt: now}); err != nil {
+			log.Warn().Err(err).Msg("failed to save notification thread")
+		}
+	}
+}
+
+// notificationThreadDays is how long notifications reply to the email that started their thread
+// before a new thread is started
+const notificationThreadDays = 30
+
+// notificationThread returns the threading of the next notification email of a thread, and
+// whether the email starts a new thread
+func (s *Scheduler) notificationThread(ctx context.Context, settings *domain.Settings, name string, now time.Time) (email.Thread, bool) {
+	thread := email.Thread{MessageID: email.NewMessageID(settings.EmailFrom)}
+
+	current, err := s.notificationRepo.GetThread(ctx, name)
+	if err != nil {
+		log.Warn().Err(err).Str("thread", name).Msg("failed to load notification thread")
+		return thread, true
+	}
+	if current == nil || now.Sub(current.StartedAt) > notificationThreadDays*24*time.Hour {
+		return thread, true
+	}
+
+	thread.InReplyTo = current.MessageID
+	return thread, false
+}
+
+// tokenNotifyInterval is the minimum time between token warnings of a source. It is shorter than a
+// day so the daily check still warns every day, while the check on startup does not repeat the
+// warning of the last daily check.
+const tokenNotifyInterval = 20 * time.Hour
+
+// checkSourceTokens validates the tokens of all sources and emails a warning for
+// tokens that are invalid or about to expire
+func (s *Scheduler) checkSourceTokens() {
+	ctx := context.Background()
+
+	warnings, err := s.scanner.CheckTokens(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check source tokens")
+		return
+	}
+	if len(warnings) == 0 {
+		return
+	}
+
+	for _, w := range warnings {
+		log.Warn().Str("source", w.SourceName).Str("status", w.Status).Msg("source token needs attention")
+	}
+
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load settings for token warning notification")
+		return
+	}
+	if !settings.EmailEnabled {
+		return
+	}
+
+	now := time.Now()
+	filtered, err := s.notificationRepo.FilterTokenWarningsNotified(ctx, warnings, now.Add(-tokenNotifyInterval))
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to filter recently notified token warnings")
+	} else {
+		if suppressed := len(warnings) - len(filtered); suppressed > 0 {
+			log.Info().Int("suppressed", suppressed).Msg("skipping recently notified token warnings")
+		}
+		warnings = filtered
+	}
+	if len(warnings) == 0 {
+		return
+	}
+
+	if err := s.emailService.SendTokenWarnings(settings, warnings); err != nil {
+		log.Error().Err(err).Msg("failed to send token warning notification")
+		return
+	}
+	if err := s.notificationRepo.MarkTokenWarningsNotified(ctx, warnings, now); err != nil {
+		log.Warn().Err(err).Msg("failed to record token warning notification")
+	}
+}
+
+// SetPrefetch sets how many of the most used packages have their latest versions prefetched on
+// startup and before scheduled scans; 0 disables prefetching. Call it before Start.
+func (s *Scheduler) SetPrefetch(packages int) {
+	s.prefetchPackages = packages
+}
+
+// prefetch warms the registry caches with the latest versions of the most used packages
+func (s *Scheduler) prefetch(ctx context.Context) {
+	if s.prefetchPackages <= 0 {
+		return
+	}
+	start := time.Now()
+	fetched, err := s.scanner.Prefetch(ctx, s.prefetchPackages)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to prefetch latest versions")
+		return
+	}
+	log.Info().Int("packages", fetched).Dur("duration", time.Since(start)).Msg("prefetched latest versions of popular packages")
+}
+
+// refreshMaintainers fetches the maintainers of packages in use that are unknown or outdated
+func (s *Scheduler) refreshMaintainers() {
+	refreshed, err := s.scanner.RefreshMaintainers(context.Background())
+	if err != nil {
+		log.Error().Err(err).Msg("failed to refresh package maintainers")
+	}
+	if refreshed > 0 {
+		log.Info().Int("packages", refreshed).Msg("refreshed package maintainers")
+	}
+}
+
+// sendScanSummary reports the outcome of a finished scan to the configured observability provider
+func (s *Scheduler) sendScanSummary(ctx context.Context, scanID int64) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load settings for observability event")
+		return
+	}
+	if settings.ObservabilityProvider == "" {
+		return
+	}
+
+	scan, err := s.scanRepo.GetByID(ctx, scanID)
+	if err != nil {
+		log.Error().Err(err).Int64("scan_id", scanID).Msg("failed to load scan for observability event")
+		return
+	}
+	summary := domain.ScanSummary{
+		ScanID:       scan.ID,
+		Status:       scan.Status,
+		Repositories: scan.ReposFound,
+		Dependencies: scan.DepsFound,
+		APICalls:     scan.APICalls,
+		FinishedAt:   time.Now(),
+	}
+	if scan.FinishedAt != nil {
+		summary.FinishedAt = *scan.FinishedAt
+	}
+	if scan.StartedAt != nil {
+		summary.DurationSeconds = summary.FinishedAt.Sub(*scan.StartedAt).Seconds()
+	}
+	if stats, err := s.depRepo.GetStats(ctx, settings.StatsExclusions()); err != nil {
+		log.Warn().Err(err).Msg("failed to load dependency stats for observability event")
+	} else {
+		summary.Outdated = stats.OutdatedCount
+	}
+	if breaches, err := s.slaBreaches(ctx); err != nil {
+		log.Warn().Err(err).Msg("failed to count SLA breaches")
+	} else {
+		summary.SLABreaches = breaches.Total
+	}
+
+	if err := s.observability.SendScanSummary(ctx, settings, summary); err != nil {
+		log.Error().Err(err).Str("provider", settings.ObservabilityProvider).Msg("failed to send observability event")
+	}
+}
+
+// expireIgnores removes the ignore rules that expired so their dependencies are reported again
+func (s *Scheduler) expireIgnores() {
+	ctx := context.Background()
+
+	expired, err := s.ignoredRepo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		log.Error().Err(err).Msg("failed to remove expired ignore rules")
+		return
+	}
+	if len(expired) == 0 {
 		return
 	}
 
+	for _, ig := range expired {
+		log.Info().Str("name", ig.Name).Str("ecosystem", ig.Ecosystem).Msg("ignore rule expired, dependency reactivated")
+	}
+	s.sendIgnoreExpiryReminder(ctx, nil, expired)
+}
+
+// remindExpiringIgnores emails the ignore rules expiring within the reminder window
+func (s *Scheduler) remindExpiringIgnores() {
+	ctx := context.Background()
+
+	expiring, err := s.ignoredRepo.GetExpiring(ctx, time.Now().AddDate(0, 0, domain.IgnoreExpiryReminderDays))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load expiring ignore rules")
+		return
+	}
+	if len(expiring) == 0 {
+		return
+	}
+	s.sendIgnoreExpiryReminder(ctx, expiring, nil)
+}
+
+func (s *Scheduler) sendIgnoreExpiryReminder(ctx context.Context, expiring, expired []domain.IgnoredDependency) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load settings for ignore expiry notification")
+		return
+	}
+	if err := s.emailService.SendIgnoreExpiryReminder(settings, expiring, expired); err != nil {
+		log.Error().Err(err).Msg("failed to send ignore expiry notification")
+	}
+}
+
+// newOutdatedReport collects the dependencies that became outdated and the repositories
+// discovered or removed during a scan
+func (s *Scheduler) newOutdatedReport(ctx context.Context, scanID int64) (*domain.NewOutdatedReport, error) {
+	newOutdated, err := s.depRepo.GetNewlyOutdated(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if newOutdated, err = s.matchNotifyView(ctx, newOutdated); err != nil {
+		return nil, err
+	}
+
 	report := &domain.NewOutdatedReport{
 		ScanID:      scanID,
 		NewOutdated: newOutdated,
 	}
 
-	if err := s.emailService.SendNewOutdatedReport(settings, report); err != nil {
-		log.Error().Err(err).Msg("failed to send email notification")
+	// Include repositories discovered or removed during the scan
+	if scan, err := s.scanRepo.GetByID(ctx, scanID); err != nil {
+		log.Warn().Err(err).Int64("scan_id", scanID).Msg("failed to load scan for repository discovery")
+	} else {
+		report.NewRepos = scan.ReposNew
+		report.RemovedRepos = scan.ReposRemoved
 	}
+
+	// Include dependencies that have been outdated for longer than their upgrade SLA
+	if breaches, err := s.slaBreaches(ctx); err != nil {
+		log.Warn().Err(err).Msg("failed to count SLA breaches")
+	} else {
+		report.SLABreaches = breaches.Total
+	}
+	return report, nil
+}
+
+// newOutdatedNotification returns the report the new outdated notification sends for a scan at
+// now. Dependencies reported within the reminder window are left out so each scan does not repeat
+// them.
+func (s *Scheduler) newOutdatedNotification(ctx context.Context, scanID int64, settings *domain.Settings, now time.Time) (*domain.NewOutdatedReport, error) {
+	report, err := s.newOutdatedReport(ctx, scanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.EmailRemindDays > 0 && len(report.NewOutdated) > 0 {
+		filtered, err := s.notificationRepo.FilterNotified(ctx, report.NewOutdated, now.AddDate(0, 0, -settings.EmailRemindDays))
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to filter recently notified dependencies")
+		} else {
+			if suppressed := len(report.NewOutdated) - len(filtered); suppressed > 0 {
+				log.Info().Int("suppressed", suppressed).Int("remind_days", settings.EmailRemindDays).Msg("skipping recently notified dependencies")
+			}
+			report.NewOutdated = filtered
+		}
+	}
+	return report, nil
+}
+
+// matchNotifyView keeps the dependencies matching the saved view the new outdated notification is
+// limited to. A view that no longer exists limits nothing.
+func (s *Scheduler) matchNotifyView(ctx context.Context, deps []domain.DependencyWithRepo) ([]domain.DependencyWithRepo, error) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil || settings.EmailNotifyViewID == 0 || len(deps) == 0 {
+		return deps, err
+	}
+
+	view, err := s.viewRepo.GetByID(ctx, settings.EmailNotifyViewID)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Warn().Int64("view_id", settings.EmailNotifyViewID).Msg("notification view not found, reporting every dependency")
+		return deps, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	matching, err := s.depRepo.GetFilteredWithAll(ctx, view.Status, view.Repo, "", view.Ecosystem, view.Search)
+	if err != nil {
+		return nil, err
+	}
+	inView := make(map[int64]bool, len(matching))
+	for _, dep := range matching {
+		inView[dep.ID] = true
+	}
+
+	var filtered []domain.DependencyWithRepo
+	for _, dep := range deps {
+		if inView[dep.ID] {
+			filtered = append(filtered, dep)
+		}
+	}
+	return filtered, nil
+}
+
+func (s *Scheduler) slaBreaches(ctx context.Context) (domain.SLABreachStats, error) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		return domain.SLABreachStats{}, err
+	}
+	outdated, err := s.depRepo.GetUpgradable(ctx)
+	if err != nil {
+		return domain.SLABreachStats{}, err
+	}
+	return scanner.SLABreaches(outdated, settings, time.Now()), nil
+}
+
+// LatestNewOutdatedReport returns the report the new outdated dependencies notification would send
+// now for the latest completed scan, leaving out the dependencies reported within the reminder
+// window like the notification does
+func (s *Scheduler) LatestNewOutdatedReport(ctx context.Context) (*domain.NewOutdatedReport, error) {
+	scan, err := s.scanRepo.GetLatestCompleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.newOutdatedNotification(ctx, scan.ID, settings, time.Now())
 }
 
 func (s *Scheduler) TriggerScan(ctx context.Context, sourceID *int64) (*domain.ScanJob, error) {
+	state, err := s.State(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if state.InMaintenance(time.Now()) {
+		return nil, ErrMaintenanceMode
+	}
+	if sourceID != nil {
+		if source, err := s.sourceRepo.GetByID(ctx, *sourceID); err == nil && source.Disabled() {
+			return nil, ErrSourceDisabled
+		}
+	}
+
 	s.mu.Lock()
 	if s.runningJobID != nil {
 		s.mu.Unlock()
@@ -242,33 +731,38 @@ func (s *Scheduler) TriggerScan(ctx context.Context, sourceID *int64) (*domain.S
 	s.runningJobID = &scan.ID
 	s.mu.Unlock()
 
+	s.startScan(scan.ID, "manual", func(ctx context.Context) error {
+		if sourceID != nil {
+			return s.scanner.ScanSource(ctx, *sourceID, scan.ID)
+		}
+		return s.scanner.ScanAll(ctx, scan.ID)
+	})
+
+	return scan, nil
+}
+
+// startScan runs a scan job holding the running slot in the background
+func (s *Scheduler) startScan(scanID int64, trigger string, scan func(ctx context.Context) error) {
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Error().Interface("panic", r).Int64("scan_id", scan.ID).Msg("panic in scan goroutine")
-				s.mu.Lock()
-				s.runningJobID = nil
-				s.mu.Unlock()
-				_ = s.scanRepo.UpdateStatus(context.Background(), scan.ID, domain.ScanStatusFailed, errors.New("scan panicked"))
+				log.Error().Interface("panic", r).Int64("scan_id", scanID).Msg("panic in scan goroutine")
+				s.releaseRunningJob(scanID)
+				_ = s.scanRepo.UpdateStatus(context.Background(), scanID, domain.ScanStatusFailed, errors.New("scan panicked"))
 				// Still notify callbacks for cache invalidation etc.
 				s.notifyScanComplete()
 			}
 		}()
-		s.runScan(scan.ID, sourceID)
+		s.runScan(scanID, trigger, scan)
 	}()
-
-	return scan, nil
 }
 
-func (s *Scheduler) runScan(scanID int64, sourceID *int64) {
-	ctx := context.Background()
+func (s *Scheduler) runScan(scanID int64, trigger string, scan func(ctx context.Context) error) {
+	ctx, span := tracing.Start(context.Background(), "scan", attribute.Int64("scan.id", scanID), attribute.String("scan.trigger", trigger))
+	defer span.End()
 
 	// Clear running job ID when done
-	defer func() {
-		s.mu.Lock()
-		s.runningJobID = nil
-		s.mu.Unlock()
-	}()
+	defer s.releaseRunningJob(scanID)
 
 	if err := s.scanRepo.UpdateStatus(ctx, scanID, domain.ScanStatusRunning, nil); err != nil {
 		log.Error().Err(err).Msg("failed to update scan status to running")
@@ -280,12 +774,8 @@ func (s *Scheduler) runScan(scanID int64, sourceID *int64) {
 		log.Warn().Err(err).Msg("failed to mark previously outdated dependencies")
 	}
 
-	var scanErr error
-	if sourceID != nil {
-		scanErr = s.scanner.ScanSource(ctx, *sourceID, scanID)
-	} else {
-		scanErr = s.scanner.ScanAll(ctx, scanID)
-	}
+	scanErr := scan(ctx)
+	tracing.RecordError(span, scanErr)
 
 	status := domain.ScanStatusCompleted
 	if scanErr != nil {
@@ -300,6 +790,10 @@ func (s *Scheduler) runScan(scanID int64, sourceID *int64) {
 	if err := s.scanRepo.UpdateStatus(ctx, scanID, status, scanErr); err != nil {
 		log.Error().Err(err).Msg("failed to update scan status")
 	}
+	s.sendScanSummary(ctx, scanID)
+	s.recordScanEvents(ctx, scanID, scanErr)
+	s.archiveState(ctx, scanErr)
+	s.handleScanFailures(ctx, scanID, scanErr)
 
 	// Notify scan complete callbacks (cache invalidation, etc.)
 	s.notifyScanComplete()
@@ -1,10 +1,15 @@
 package scheduler
 
 import (
+	"context"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/jiin/stale/internal/testutil"
 	"github.com/robfig/cron/v3"
 )
 
@@ -176,3 +181,97 @@ func TestErrScanAlreadyRunning(t *testing.T) {
 func ptr(v int64) *int64 {
 	return &v
 }
+
+func TestQueueScheduledRun(t *testing.T) {
+	s := &Scheduler{}
+
+	if !s.queueScheduledRun(nil) {
+		t.Error("global run should be queued")
+	}
+	if s.queueScheduledRun(nil) {
+		t.Error("global run should not be queued twice")
+	}
+	if !s.queueScheduledRun(ptr(int64(1))) {
+		t.Error("source run should be queued")
+	}
+	if s.queueScheduledRun(ptr(int64(1))) {
+		t.Error("source run should not be queued twice")
+	}
+	if !s.queueScheduledRun(ptr(int64(2))) {
+		t.Error("run of another source should be queued")
+	}
+	if got := s.QueuedRuns(); got != 3 {
+		t.Errorf("QueuedRuns() = %d, want 3", got)
+	}
+}
+
+func TestQueueRescan(t *testing.T) {
+	s := &Scheduler{}
+
+	s.queueRescan([]int64{1, 2})
+	if !s.queueScheduledRun(nil) {
+		t.Error("global run should be queued next to a rescan")
+	}
+	s.queueRescan([]int64{2, 3})
+
+	if got := s.QueuedRuns(); got != 2 {
+		t.Fatalf("QueuedRuns() = %d, want 2", got)
+	}
+	if got := s.queuedRuns[0].repoIDs; !reflect.DeepEqual(got, []int64{1, 2, 3}) {
+		t.Errorf("queued repoIDs = %v, want [1 2 3]", got)
+	}
+}
+
+func TestReleaseRunningJob(t *testing.T) {
+	s := &Scheduler{runningJobID: ptr(int64(2))}
+
+	// A scan that no longer holds the slot does not release it
+	s.releaseRunningJob(1)
+	if s.runningJobID == nil || *s.runningJobID != 2 {
+		t.Errorf("runningJobID = %v, want 2", s.runningJobID)
+	}
+
+	s.releaseRunningJob(2)
+	if s.runningJobID != nil {
+		t.Errorf("runningJobID = %v, want nil", *s.runningJobID)
+	}
+}
+
+func TestLatestNewOutdatedReport_LeavesOutReminded(t *testing.T) {
+	h := testutil.NewHarness(t)
+	h.Provider.AddRepository("acme/web", map[string]string{
+		"package.json": `{"dependencies": {"lodash": "4.17.20", "react": "18.2.0"}}`,
+	})
+	h.Registry.SetLatest("npm", "lodash", "4.17.21")
+	h.Registry.SetLatest("npm", "react", "18.3.1")
+	h.AddSource(domain.SourceInput{})
+	h.Scan()
+
+	ctx := context.Background()
+	settingsRepo := repository.NewSettingsRepository(h.DB)
+	remindDays := 7
+	if err := settingsRepo.Update(ctx, &domain.SettingsInput{EmailRemindDays: &remindDays}); err != nil {
+		t.Fatal(err)
+	}
+	s := &Scheduler{
+		scanRepo:         h.Scans,
+		depRepo:          h.Deps,
+		settingsRepo:     settingsRepo,
+		notificationRepo: repository.NewNotificationRepository(h.DB),
+		viewRepo:         repository.NewViewRepository(h.DB),
+	}
+
+	report, err := s.LatestNewOutdatedReport(ctx)
+	if err != nil || len(report.NewOutdated) != 2 {
+		t.Fatalf("LatestNewOutdatedReport() = %+v, %v, want both dependencies", report, err)
+	}
+
+	// The preview leaves out what the notification would not repeat
+	if err := s.notificationRepo.MarkNotified(ctx, report.NewOutdated[:1], time.Now().AddDate(0, 0, -1)); err != nil {
+		t.Fatal(err)
+	}
+	report, err = s.LatestNewOutdatedReport(ctx)
+	if err != nil || len(report.NewOutdated) != 1 {
+		t.Errorf("LatestNewOutdatedReport() after a reminder = %+v, %v, want one dependency", report, err)
+	}
+}
@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// LoadTimezone returns the location of a schedule timezone such as "Asia/Seoul". An empty
+// timezone is the server timezone.
+func LoadTimezone(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", timezone)
+	}
+	return loc, nil
+}
+
+// cronSpec returns the spec that runs a cron expression in a timezone; the cron parser reads the
+// timezone from a CRON_TZ prefix. An empty timezone runs the expression in the server timezone.
+func cronSpec(expr, timezone string) string {
+	if timezone == "" {
+		return expr
+	}
+	return "CRON_TZ=" + timezone + " " + expr
+}
+
+// NextRun returns the next time a cron expression runs after now, in the schedule timezone
+func NextRun(expr, timezone string, now time.Time) (time.Time, error) {
+	loc, err := LoadTimezone(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(now.In(loc)), nil
+}
@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestLoadTimezone(t *testing.T) {
+	if loc, err := LoadTimezone(""); err != nil || loc != time.Local {
+		t.Errorf("LoadTimezone(\"\") = %v, %v, want Local", loc, err)
+	}
+	if loc, err := LoadTimezone("Asia/Seoul"); err != nil || loc.String() != "Asia/Seoul" {
+		t.Errorf("LoadTimezone(Asia/Seoul) = %v, %v", loc, err)
+	}
+	if _, err := LoadTimezone("Mars/Olympus"); err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+}
+
+func TestCronSpec(t *testing.T) {
+	if got := cronSpec("0 9 * * *", ""); got != "0 9 * * *" {
+		t.Errorf("cronSpec() = %q", got)
+	}
+	if got := cronSpec("0 9 * * *", "Asia/Seoul"); got != "CRON_TZ=Asia/Seoul 0 9 * * *" {
+		t.Errorf("cronSpec() = %q", got)
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	// 01:00 UTC is 10:00 in Seoul, past the 09:00 run of the day
+	now := time.Date(2025, 3, 1, 1, 0, 0, 0, time.UTC)
+
+	next, err := NextRun("0 9 * * *", "Asia/Seoul", now)
+	if err != nil {
+		t.Fatalf("NextRun() error = %v", err)
+	}
+	if got := next.Format("2006-01-02 15:04 MST"); got != "2025-03-02 09:00 KST" {
+		t.Errorf("NextRun() = %s, want 2025-03-02 09:00 KST", got)
+	}
+
+	if _, err := NextRun("0 9 * * *", "Mars/Olympus", now); err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+	if _, err := NextRun("not a cron", "", now); err == nil {
+		t.Error("expected error for invalid cron expression")
+	}
+}
+
+func TestScheduledSources_Timezone(t *testing.T) {
+	sources := []domain.Source{
+		{ID: 1, Name: "seoul-nights", ScanWindow: "22:00-06:00", ScheduleTimezone: "Asia/Seoul"},
+	}
+
+	// 14:00 UTC is 23:00 in Seoul
+	if got := scheduledSources(sources, nil, time.Date(2025, 3, 1, 14, 0, 0, 0, time.UTC)); len(got) != 1 {
+		t.Errorf("scheduledSources() at 23:00 KST = %v, want the source", got)
+	}
+	// 03:00 UTC is 12:00 in Seoul
+	if got := scheduledSources(sources, nil, time.Date(2025, 3, 1, 3, 0, 0, 0, time.UTC)); len(got) != 0 {
+		t.Errorf("scheduledSources() at 12:00 KST = %v, want none", got)
+	}
+}
@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+// ParseScanWindow parses a scan window such as "22:00-06:00" and returns its start and end
+// as minutes after midnight. Windows may wrap around midnight.
+func ParseScanWindow(window string) (int, int, error) {
+	from, to, ok := strings.Cut(window, "-")
+	if !ok {
+		return 0, 0, errors.New("scan window must look like HH:MM-HH:MM")
+	}
+
+	start, err := parseClock(strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseClock(strings.TrimSpace(to))
+	if err != nil {
+		return 0, 0, err
+	}
+	if start == end {
+		return 0, 0, errors.New("scan window must not be empty")
+	}
+	return start, end, nil
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q in scan window", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// InScanWindow reports whether t falls within the scan window. An empty or invalid window allows any time.
+func InScanWindow(window string, t time.Time) bool {
+	if window == "" {
+		return true
+	}
+	start, end, err := ParseScanWindow(window)
+	if err != nil {
+		return true
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end // wraps around midnight
+}
+
+// scheduledSources returns the sources a scheduled run scans: the given source for a source
+// schedule, otherwise all sources without their own schedule. Sources outside their scan
+// window, read in the source's schedule timezone, are skipped.
+func scheduledSources(sources []domain.Source, sourceID *int64, now time.Time) []domain.Source {
+	var selected []domain.Source
+	for _, source := range sources {
+		if sourceID != nil && source.ID != *sourceID {
+			continue
+		}
+		if sourceID == nil && source.ScheduleCron != "" {
+			continue
+		}
+		if !InScanWindow(source.ScanWindow, inTimezone(now, source.ScheduleTimezone)) {
+			continue
+		}
+		selected = append(selected, source)
+	}
+	return selected
+}
+
+// inTimezone returns t in a schedule timezone, or unchanged when the timezone is empty or unknown
+func inTimezone(t time.Time, timezone string) time.Time {
+	if timezone == "" {
+		return t
+	}
+	loc, err := LoadTimezone(timezone)
+	if err != nil {
+		return t
+	}
+	return t.In(loc)
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+func TestParseScanWindow(t *testing.T) {
+	tests := []struct {
+		window    string
+		wantStart int
+		wantEnd   int
+		wantErr   bool
+	}{
+		{"22:00-06:00", 22 * 60, 6 * 60, false},
+		{"09:30 - 17:00", 9*60 + 30, 17 * 60, false},
+		{"22:00", 0, 0, true},
+		{"25:00-06:00", 0, 0, true},
+		{"06:00-06:00", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.window, func(t *testing.T) {
+			start, end, err := ParseScanWindow(tt.window)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseScanWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("ParseScanWindow() = %d, %d, want %d, %d", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestInScanWindow(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 3, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		window string
+		t      time.Time
+		want   bool
+	}{
+		{"", at(12, 0), true},
+		{"22:00-06:00", at(23, 0), true},
+		{"22:00-06:00", at(3, 0), true},
+		{"22:00-06:00", at(6, 0), false},
+		{"22:00-06:00", at(12, 0), false},
+		{"09:00-17:00", at(9, 0), true},
+		{"09:00-17:00", at(17, 30), false},
+	}
+
+	for _, tt := range tests {
+		if got := InScanWindow(tt.window, tt.t); got != tt.want {
+			t.Errorf("InScanWindow(%q, %s) = %v, want %v", tt.window, tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestScheduledSources(t *testing.T) {
+	sources := []domain.Source{
+		{ID: 1, Name: "global"},
+		{ID: 2, Name: "own-schedule", ScheduleCron: "0 2 * * *"},
+		{ID: 3, Name: "night-only", ScanWindow: "22:00-06:00"},
+	}
+	noon := time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local)
+	night := time.Date(2025, 3, 1, 23, 0, 0, 0, time.Local)
+
+	names := func(sources []domain.Source) []string {
+		var result []string
+		for _, s := range sources {
+			result = append(result, s.Name)
+		}
+		return result
+	}
+
+	if got := names(scheduledSources(sources, nil, noon)); len(got) != 1 || got[0] != "global" {
+		t.Errorf("global run at noon = %v, want [global]", got)
+	}
+	if got := names(scheduledSources(sources, nil, night)); len(got) != 2 || got[1] != "night-only" {
+		t.Errorf("global run at night = %v, want [global night-only]", got)
+	}
+	id := int64(2)
+	if got := names(scheduledSources(sources, &id, noon)); len(got) != 1 || got[0] != "own-schedule" {
+		t.Errorf("source run = %v, want [own-schedule]", got)
+	}
+}
@@ -0,0 +1,136 @@
+// Package swift looks up Swift packages in the Swift Package Index. Packages are named by the
+// repository they are hosted in, e.g. github.com/apple/swift-nio.
+package swift
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jiin/stale/internal/service/cache"
+	"github.com/jiin/stale/internal/service/httputil"
+)
+
+const indexURL = "https://swiftpackageindex.com/api"
+
+// Cache TTL: 1 hour - package releases don't change that frequently.
+// Expired entries are refreshed with conditional requests, see httputil.ConditionalCacheTransport
+const cacheTTL = 1 * time.Hour
+
+// ErrNoToken is returned when the Swift Package Index API token is not configured
+var ErrNoToken = errors.New("swift package index API token is not configured")
+
+type Client struct {
+	httpClient  *http.Client
+	retryConfig httputil.RetryConfig
+	cache       *cache.Cache[string]
+	baseURL     string
+	token       string // Swift Package Index API token
+}
+
+// PackageInfo is the part of a Swift Package Index package used to find its latest release
+type PackageInfo struct {
+	Releases struct {
+		Stable *struct {
+			Link struct {
+				Label string `json:"label"` // tag of the release, e.g. 2.62.0
+			} `json:"link"`
+		} `json:"stable"`
+	} `json:"releases"`
+}
+
+func New(token string) *Client {
+	return &Client{
+		httpClient:  httputil.NewCachingClient(10 * time.Second),
+		retryConfig: httputil.DefaultRetryConfig(),
+		cache:       cache.New[string](cacheTTL),
+		baseURL:     indexURL,
+		token:       token,
+	}
+}
+
+// SetCacheTTL changes how long latest versions are cached, 0 disables the cache
+func (c *Client) SetCacheTTL(ttl time.Duration) {
+	c.cache.SetTTL(ttl)
+}
+
+// GetLatestVersion returns the latest stable release of a package. Only packages hosted on
+// GitHub are indexed.
+func (c *Client) GetLatestVersion(ctx context.Context, name string) (string, error) {
+	if version, found := c.cache.Get(name); found {
+		return version, nil
+	}
+
+	owner, repo, ok := githubRepository(name)
+	if !ok {
+		return "", fmt.Errorf("package %s is not hosted on GitHub", name)
+	}
+	if c.token == "" {
+		return "", ErrNoToken
+	}
+
+	reqURL := fmt.Sprintf("%s/packages/%s/%s", c.baseURL, url.PathEscape(owner), url.PathEscape(repo))
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httputil.DoWithRetry(ctx, c.httpClient, req, c.retryConfig)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("package %s not found", name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("swift package index returned %d for %s", resp.StatusCode, name)
+	}
+
+	var info PackageInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", err
+	}
+	if info.Releases.Stable == nil || info.Releases.Stable.Link.Label == "" {
+		return "", fmt.Errorf("no stable release found for %s", name)
+	}
+
+	version := strings.TrimPrefix(info.Releases.Stable.Link.Label, "v")
+	c.cache.Set(name, version)
+	return version, nil
+}
+
+// PackageName returns the name of a package from its repository URL, e.g.
+// https://github.com/apple/swift-nio.git and git@github.com:apple/swift-nio.git are both
+// github.com/apple/swift-nio
+func PackageName(repositoryURL string) string {
+	name := strings.TrimSpace(repositoryURL)
+	if rest, ok := strings.CutPrefix(name, "git@"); ok {
+		name = strings.Replace(rest, ":", "/", 1)
+	}
+	if _, rest, ok := strings.Cut(name, "://"); ok {
+		name = rest
+	}
+	if at, slash := strings.Index(name, "@"), strings.Index(name, "/"); at >= 0 && at < slash {
+		name = name[at+1:] // user info, e.g. git@github.com/...
+	}
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "/"), ".git")
+	return strings.ToLower(name)
+}
+
+// githubRepository returns the owner and repository of a package hosted on GitHub
+func githubRepository(name string) (owner, repo string, ok bool) {
+	parts := strings.Split(name, "/")
+	if len(parts) != 3 || parts[0] != "github.com" || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
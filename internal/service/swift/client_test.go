@@ -0,0 +1,90 @@
+package swift
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/service/cache"
+	"github.com/jiin/stale/internal/service/httputil"
+)
+
+func newTestClient(baseURL, token string) *Client {
+	return &Client{
+		httpClient:  &http.Client{Timeout: 5 * time.Second},
+		retryConfig: httputil.RetryConfig{MaxRetries: 1, BaseDelay: 10 * time.Millisecond},
+		cache:       cache.New[string](time.Minute),
+		baseURL:     baseURL,
+		token:       token,
+	}
+}
+
+func TestGetLatestVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/packages/apple/swift-nio" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer spi-token" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"repositoryName":"swift-nio","releases":{"stable":{"date":"2024-01-10T00:00:00Z","link":{"label":"2.62.0","url":"https://github.com/apple/swift-nio/releases/tag/2.62.0"}}}}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "spi-token")
+	version, err := client.GetLatestVersion(context.Background(), "github.com/apple/swift-nio")
+	if err != nil {
+		t.Fatalf("GetLatestVersion() error = %v", err)
+	}
+	if version != "2.62.0" {
+		t.Errorf("GetLatestVersion() = %q, want 2.62.0", version)
+	}
+}
+
+func TestGetLatestVersion_NoStableRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"releases":{"beta":{"link":{"label":"1.0.0-beta.1"}}}}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "spi-token")
+	if _, err := client.GetLatestVersion(context.Background(), "github.com/example/pkg"); err == nil {
+		t.Error("GetLatestVersion() expected error without a stable release")
+	}
+}
+
+func TestGetLatestVersion_Unsupported(t *testing.T) {
+	client := newTestClient("http://127.0.0.1:0", "")
+	if _, err := client.GetLatestVersion(context.Background(), "github.com/apple/swift-nio"); !errors.Is(err, ErrNoToken) {
+		t.Errorf("GetLatestVersion() error = %v, want ErrNoToken", err)
+	}
+	client.token = "spi-token"
+	if _, err := client.GetLatestVersion(context.Background(), "gitlab.com/example/pkg"); err == nil {
+		t.Error("GetLatestVersion() expected error for a package not hosted on GitHub")
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/apple/swift-nio.git", "github.com/apple/swift-nio"},
+		{"https://github.com/Alamofire/Alamofire", "github.com/alamofire/alamofire"},
+		{"git@github.com:apple/swift-log.git", "github.com/apple/swift-log"},
+		{"ssh://git@github.com/apple/swift-log.git", "github.com/apple/swift-log"},
+		{"https://gitlab.com/example/pkg/", "gitlab.com/example/pkg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := PackageName(tt.url); got != tt.want {
+				t.Errorf("PackageName(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package synthetic generates fake repositories and dependencies for load testing.
+package synthetic
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+// Spec sizes the generated data. The same spec and seed always generate the same data.
+type Spec struct {
+	Repositories        int   `json:"repositories"`
+	DependenciesPerRepo int   `json:"dependencies_per_repo"`
+	OutdatedPercent     int   `json:"outdated_percent"` // share of outdated dependencies, 0 to 100
+	Seed                int64 `json:"seed"`
+}
+
+// Repository is a generated repository with its dependencies
+type Repository struct {
+	Repository   domain.Repository
+	Dependencies []domain.Dependency
+}
+
+// ecosystem describes how the packages of an ecosystem are named and declared
+type ecosystem struct {
+	name         string
+	manifestPath string
+	manifestType string
+	packageName  func(i int) string
+}
+
+var ecosystems = []ecosystem{
+	{"npm", "package.json", "package.json", func(i int) string { return fmt.Sprintf("synthetic-pkg-%04d", i) }},
+	{"maven", "pom.xml", "pom.xml", func(i int) string { return fmt.Sprintf("com.example.synthetic:lib-%04d", i) }},
+	{"go", "go.mod", "go.mod", func(i int) string { return fmt.Sprintf("example.com/synthetic/mod%04d", i) }},
+}
+
+// Generate returns spec.Repositories repositories named synthetic/repo-NNNN, each depending on
+// spec.DependenciesPerRepo packages. Packages come from a pool twice as large as a repository's
+// dependencies, so repositories share packages like real ones do.
+func Generate(spec Spec) []Repository {
+	rng := rand.New(rand.NewSource(spec.Seed))
+	pool := max(2*spec.DependenciesPerRepo, 50)
+
+	latest := make([][3]int, pool)
+	for i := range latest {
+		latest[i] = [3]int{1 + rng.Intn(9), rng.Intn(20), rng.Intn(30)}
+	}
+
+	repos := make([]Repository, spec.Repositories)
+	for r := range repos {
+		name := fmt.Sprintf("repo-%04d", r+1)
+		eco := ecosystems[r%len(ecosystems)]
+		repo := Repository{Repository: domain.Repository{
+			Name:          name,
+			FullName:      "synthetic/" + name,
+			DefaultBranch: "main",
+		}}
+
+		for _, p := range rng.Perm(pool)[:min(spec.DependenciesPerRepo, pool)] {
+			current := latest[p]
+			outdated := rng.Intn(100) < spec.OutdatedPercent
+			if outdated {
+				current = behind(rng, current)
+			}
+			repo.Dependencies = append(repo.Dependencies, domain.Dependency{
+				Name:           eco.packageName(p),
+				CurrentVersion: version(current),
+				LatestVersion:  version(latest[p]),
+				Ecosystem:      eco.name,
+				Type:           domain.DependencyTypeProd,
+				ManifestPath:   eco.manifestPath,
+				ManifestType:   eco.manifestType,
+			})
+		}
+		repos[r] = repo
+	}
+	return repos
+}
+
+// behind returns a version older than latest by a major, minor or patch release
+func behind(rng *rand.Rand, latest [3]int) [3]int {
+	v := latest
+	switch {
+	case v[0] > 1 && rng.Intn(4) == 0:
+		v[0] -= 1 + rng.Intn(v[0]-1)
+	case v[1] > 0 && rng.Intn(2) == 0:
+		v[1] -= 1 + rng.Intn(v[1])
+	case v[2] > 0:
+		v[2] -= 1 + rng.Intn(v[2])
+	default:
+		v[0], v[1], v[2] = v[0]-1, 9, 9
+	}
+	return v
+}
+
+func version(v [3]int) string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
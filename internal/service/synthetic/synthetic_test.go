@@ -0,0 +1,56 @@
+package synthetic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jiin/stale/internal/service/scanner"
+)
+
+func TestGenerate(t *testing.T) {
+	spec := Spec{Repositories: 7, DependenciesPerRepo: 30, OutdatedPercent: 40, Seed: 3}
+	repos := Generate(spec)
+
+	if len(repos) != 7 {
+		t.Fatalf("len(repos) = %d, want 7", len(repos))
+	}
+	if repos[0].Repository.FullName != "synthetic/repo-0001" {
+		t.Errorf("FullName = %q", repos[0].Repository.FullName)
+	}
+	outdated := 0
+	for _, repo := range repos {
+		if len(repo.Dependencies) != 30 {
+			t.Fatalf("%s has %d dependencies, want 30", repo.Repository.FullName, len(repo.Dependencies))
+		}
+		seen := make(map[string]bool)
+		for _, dep := range repo.Dependencies {
+			if seen[dep.Name] {
+				t.Errorf("%s depends on %s twice", repo.Repository.FullName, dep.Name)
+			}
+			seen[dep.Name] = true
+			if dep.CurrentVersion != dep.LatestVersion {
+				outdated++
+				if !scanner.IsOutdated(dep.CurrentVersion, dep.LatestVersion) {
+					t.Errorf("%s %s is not older than %s", dep.Name, dep.CurrentVersion, dep.LatestVersion)
+				}
+			}
+		}
+	}
+	if outdated == 0 || outdated == 7*30 {
+		t.Errorf("outdated = %d, want some but not all of 210", outdated)
+	}
+
+	if !reflect.DeepEqual(Generate(spec), repos) {
+		t.Error("Generate() is not deterministic for the same seed")
+	}
+}
+
+func TestGenerate_NoneOutdated(t *testing.T) {
+	for _, repo := range Generate(Spec{Repositories: 3, DependenciesPerRepo: 10}) {
+		for _, dep := range repo.Dependencies {
+			if dep.CurrentVersion != dep.LatestVersion {
+				t.Errorf("%s %s is outdated with outdated_percent 0", dep.Name, dep.CurrentVersion)
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Package update checks the GitHub releases of stale for a version newer than the running one
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jiin/stale/internal/service/httputil"
+	"github.com/rs/zerolog/log"
+)
+
+// releasesURL is the latest release of stale on GitHub
+const releasesURL = "https://api.github.com/repos/amazingkj/stale/releases/latest"
+
+// Status is the result of the last update check
+type Status struct {
+	CurrentVersion  string     `json:"current_version"`
+	LatestVersion   string     `json:"latest_version,omitempty"`
+	UpdateAvailable bool       `json:"update_available"`
+	ReleaseURL      string     `json:"release_url,omitempty"`
+	CheckedAt       *time.Time `json:"checked_at,omitempty"`
+	Error           string     `json:"error,omitempty"`
+}
+
+// Checker periodically looks up the latest release of stale
+type Checker struct {
+	current    string
+	url        string
+	interval   time.Duration
+	httpClient *http.Client
+
+	mu     sync.RWMutex
+	status Status
+	stop   chan struct{}
+	once   sync.Once
+}
+
+// New creates a checker for the running version that checks every interval once started
+func New(current string, interval time.Duration) *Checker {
+	return &Checker{
+		current:    current,
+		url:        releasesURL,
+		interval:   interval,
+		httpClient: httputil.NewClient(10 * time.Second),
+		status:     Status{CurrentVersion: current},
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start checks for an update right away and then every interval until Stop is called
+func (c *Checker) Start() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := c.Check(ctx); err != nil {
+			log.Debug().Err(err).Msg("failed to check for a stale update")
+		}
+		cancel()
+
+		select {
+		case <-ticker.C:
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// Stop ends the periodic checks
+func (c *Checker) Stop() {
+	c.once.Do(func() { close(c.stop) })
+}
+
+// Status returns the result of the last check
+func (c *Checker) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status
+}
+
+// Check looks up the latest release and records whether it is newer than the running version.
+// A failed check keeps the previously found release.
+func (c *Checker) Check(ctx context.Context) error {
+	latest, releaseURL, err := c.latestRelease(ctx)
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.CheckedAt = &now
+	if err != nil {
+		c.status.Error = err.Error()
+		return err
+	}
+
+	c.status.Error = ""
+	c.status.LatestVersion = latest
+	c.status.ReleaseURL = releaseURL
+	c.status.UpdateAvailable = isNewer(latest, c.current)
+	if c.status.UpdateAvailable {
+		log.Info().Str("current", c.current).Str("latest", latest).Str("url", releaseURL).Msg("a newer version of stale is available")
+	}
+	return nil
+}
+
+// latestRelease returns the version and page URL of the latest release
+func (c *Checker) latestRelease(ctx context.Context) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("GitHub releases returned %d", resp.StatusCode)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", "", err
+	}
+	if release.TagName == "" {
+		return "", "", fmt.Errorf("latest release has no tag")
+	}
+	return strings.TrimPrefix(release.TagName, "v"), release.HTMLURL, nil
+}
+
+// isNewer reports whether latest is a newer version than current. Development builds whose
+// version is not a semantic version are never reported as outdated.
+func isNewer(latest, current string) bool {
+	currentVer, err := semver.NewVersion(current)
+	if err != nil {
+		return false
+	}
+	latestVer, err := semver.NewVersion(latest)
+	if err != nil {
+		return false
+	}
+	return latestVer.GreaterThan(currentVer)
+}
@@ -0,0 +1,60 @@
+package update
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"0.3.0", "0.2.0", true},
+		{"0.2.1", "0.2.0", true},
+		{"0.2.0", "0.2.0", false},
+		{"0.1.9", "0.2.0", false},
+		{"0.3.0", "dev", false},
+		{"nightly", "0.2.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := isNewer(tt.latest, tt.current); got != tt.want {
+			t.Errorf("isNewer(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestChecker_Check(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"tag_name": "v0.3.0", "html_url": "https://github.com/amazingkj/stale/releases/tag/v0.3.0"}`))
+	}))
+	defer server.Close()
+
+	c := New("0.2.0", time.Hour)
+	c.url = server.URL
+	c.httpClient = server.Client()
+
+	if err := c.Check(context.Background()); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	got := c.Status()
+	if !got.UpdateAvailable || got.LatestVersion != "0.3.0" || got.CurrentVersion != "0.2.0" || got.CheckedAt == nil {
+		t.Errorf("Status() = %+v, want update to 0.3.0", got)
+	}
+
+	// A failed check keeps the release found before
+	status = http.StatusForbidden
+	if err := c.Check(context.Background()); err == nil {
+		t.Fatal("Check() expected error for rate limited request")
+	}
+	got = c.Status()
+	if !got.UpdateAvailable || got.LatestVersion != "0.3.0" || got.Error == "" {
+		t.Errorf("Status() after failure = %+v, want previous release with error", got)
+	}
+}
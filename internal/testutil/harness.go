@@ -0,0 +1,131 @@
+package testutil
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jiin/stale/internal/database"
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/jiin/stale/internal/service/scanner"
+	"github.com/jmoiron/sqlx"
+)
+
+// NewDB returns a migrated SQLite database in a temporary directory, closed when the test ends
+func NewDB(t testing.TB) *sqlx.DB {
+	t.Helper()
+	db, err := database.New(filepath.Join(t.TempDir(), "stale.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return db
+}
+
+// Harness is a scanner scanning every source through Provider and looking up latest versions in
+// Registry, recording into a temporary database. Seed the fakes, add sources and scan, then check
+// what was recorded through the repositories.
+type Harness struct {
+	t        testing.TB
+	DB       *sqlx.DB
+	Sources  *repository.SourceRepository
+	Repos    *repository.RepoRepository
+	Deps     *repository.DependencyRepository
+	Scans    *repository.ScanRepository
+	Scanner  *scanner.Scanner
+	Provider *FakeProvider
+	Registry *FakeRegistry
+}
+
+func NewHarness(t testing.TB) *Harness {
+	t.Helper()
+	db := NewDB(t)
+	h := &Harness{
+		t:        t,
+		DB:       db,
+		Sources:  repository.NewSourceRepository(db),
+		Repos:    repository.NewRepoRepository(db),
+		Deps:     repository.NewDependencyRepository(db),
+		Scans:    repository.NewScanRepository(db),
+		Provider: NewFakeProvider(),
+		Registry: NewFakeRegistry(),
+	}
+	h.Scanner = scanner.New(h.Sources, h.Repos, h.Deps, h.Scans,
+		repository.NewAlertRepository(db), repository.NewIgnoredRepository(db), nil, "", "")
+	h.Scanner.SetProviders(func(domain.Source) scanner.GitProvider { return h.Provider })
+	h.Scanner.SetRegistry(h.Registry)
+	return h
+}
+
+// AddSource creates a GitHub source served by the fake provider. Fields of input left empty
+// default to a source named fake.
+func (h *Harness) AddSource(input domain.SourceInput) *domain.Source {
+	h.t.Helper()
+	if input.Name == "" {
+		input.Name = "fake"
+	}
+	if input.Type == "" {
+		input.Type = "github"
+	}
+	source, err := h.Sources.Create(context.Background(), input)
+	if err != nil {
+		h.t.Fatalf("failed to create source: %v", err)
+	}
+	return source
+}
+
+// Scan scans every source the way the scheduler does and returns the finished scan job
+func (h *Harness) Scan() *domain.ScanJob {
+	h.t.Helper()
+	ctx := context.Background()
+	scan, err := h.Scans.Create(ctx, nil)
+	if err != nil {
+		h.t.Fatalf("failed to create scan: %v", err)
+	}
+	if err := h.Scans.UpdateStatus(ctx, scan.ID, domain.ScanStatusRunning, nil); err != nil {
+		h.t.Fatalf("failed to start scan: %v", err)
+	}
+	if err := h.Deps.MarkPreviouslyOutdated(ctx); err != nil {
+		h.t.Fatalf("failed to mark previously outdated dependencies: %v", err)
+	}
+
+	scanErr := h.Scanner.ScanAll(ctx, scan.ID)
+	status := domain.ScanStatusCompleted
+	if scanErr != nil {
+		status = domain.ScanStatusFailed
+	}
+	if err := h.Scans.UpdateStatus(ctx, scan.ID, status, scanErr); err != nil {
+		h.t.Fatalf("failed to finish scan: %v", err)
+	}
+
+	scan, err = h.Scans.GetByID(ctx, scan.ID)
+	if err != nil {
+		h.t.Fatalf("failed to load scan: %v", err)
+	}
+	return scan
+}
+
+// Repository returns the recorded repository with the given full name, failing the test when
+// none was recorded
+func (h *Harness) Repository(fullName string) *domain.Repository {
+	h.t.Helper()
+	repo, err := h.Repos.GetByFullName(context.Background(), fullName)
+	if err != nil {
+		h.t.Fatalf("repository %s was not recorded: %v", fullName, err)
+	}
+	return repo
+}
+
+// Dependencies returns the recorded dependencies of a repository
+func (h *Harness) Dependencies(fullName string) []domain.Dependency {
+	h.t.Helper()
+	deps, err := h.Deps.GetByRepoID(context.Background(), h.Repository(fullName).ID)
+	if err != nil {
+		h.t.Fatalf("failed to load dependencies of %s: %v", fullName, err)
+	}
+	return deps
+}
@@ -0,0 +1,470 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jiin/stale/internal/domain"
+	"github.com/jiin/stale/internal/repository"
+	"github.com/jiin/stale/internal/service/httputil"
+	"github.com/jiin/stale/internal/service/scanner"
+)
+
+func TestHarness_ScanMonorepo(t *testing.T) {
+	h := NewHarness(t)
+	h.Provider.AddRepository("acme/platform", map[string]string{
+		"package.json":              `{"devDependencies": {"typescript": "^5.3.0"}}`,
+		"packages/web/package.json": `{"dependencies": {"react": "^18.2.0"}}`,
+		"packages/api/package.json": `{"dependencies": {"express": "4.19.2"}}`,
+		"services/billing/go.mod":   "module acme.dev/billing\n\ngo 1.22\n\nrequire github.com/google/uuid v1.5.0\n",
+		"README.md":                 "# platform",
+	})
+	h.Registry.SetLatest("npm", "typescript", "5.3.3")
+	h.Registry.SetLatest("npm", "react", "18.3.1")
+	h.Registry.SetLatest("npm", "express", "4.19.2")
+	h.Registry.SetLatest("go", "github.com/google/uuid", "1.6.0")
+	h.Registry.SetLatest("go", "go", "go1.23.2")
+	h.AddSource(domain.SourceInput{})
+
+	scan := h.Scan()
+	if scan.Status != domain.ScanStatusCompleted {
+		t.Fatalf("scan status = %s, want completed", scan.Status)
+	}
+	if scan.ReposFound != 1 || scan.ReposTotal != 1 {
+		t.Errorf("scan repositories = %d of %d, want 1 of 1", scan.ReposFound, scan.ReposTotal)
+	}
+
+	repo := h.Repository("acme/platform")
+	if !repo.HasPackageJSON || !repo.HasGoMod {
+		t.Errorf("repository manifests = package.json %v, go.mod %v, want both", repo.HasPackageJSON, repo.HasGoMod)
+	}
+	if repo.GoLatestVersion != "1.23.2" || !repo.GoToolchainOutdated {
+		t.Errorf("Go toolchain = latest %q, outdated %v, want 1.23.2 outdated", repo.GoLatestVersion, repo.GoToolchainOutdated)
+	}
+
+	want := map[string]struct {
+		manifestPath string
+		outdated     bool
+	}{
+		"typescript":             {"package.json", true},
+		"react":                  {"packages/web/package.json", true},
+		"express":                {"packages/api/package.json", false},
+		"github.com/google/uuid": {"services/billing/go.mod", true},
+	}
+	deps := h.Dependencies("acme/platform")
+	if len(deps) != len(want) {
+		t.Fatalf("recorded %d dependencies, want %d: %+v", len(deps), len(want), deps)
+	}
+	for _, dep := range deps {
+		w, ok := want[dep.Name]
+		if !ok {
+			t.Errorf("unexpected dependency %s", dep.Name)
+			continue
+		}
+		if dep.ManifestPath != w.manifestPath || dep.IsOutdated != w.outdated {
+			t.Errorf("%s = %s, outdated %v, want %s, outdated %v", dep.Name, dep.ManifestPath, dep.IsOutdated, w.manifestPath, w.outdated)
+		}
+	}
+
+	// Dropping a workspace removes its dependencies on the next scan
+	h.Provider.AddRepository("acme/platform", map[string]string{
+		"package.json":              `{"devDependencies": {"typescript": "^5.3.0"}}`,
+		"packages/web/package.json": `{"dependencies": {"react": "^18.2.0"}}`,
+	})
+	h.Scan()
+	for _, dep := range h.Dependencies("acme/platform") {
+		if dep.Name == "express" || dep.Name == "github.com/google/uuid" {
+			t.Errorf("dependency %s of a removed manifest is still recorded", dep.Name)
+		}
+	}
+	if h.Provider.Calls("ListManifestFiles") != 2 {
+		t.Errorf("ListManifestFiles called %d times, want once per scan", h.Provider.Calls("ListManifestFiles"))
+	}
+}
+
+func TestHarness_DeterministicScan(t *testing.T) {
+	h := NewHarness(t)
+	h.Scanner.SetDeterministic(true)
+	h.Provider.AddRepository("acme/web", map[string]string{
+		"package.json": `{"dependencies": {"zod": "3.23.8", "axios": "1.7.2", "react": "18.3.1", "lodash": "4.17.21", "dayjs": "1.11.11"}}`,
+	})
+	h.AddSource(domain.SourceInput{Name: "b"})
+	h.AddSource(domain.SourceInput{Name: "a"})
+	h.Scan()
+
+	// Dependencies are recorded in name order, so their ids follow it
+	deps := h.Dependencies("acme/web")
+	for i := 1; i < len(deps); i++ {
+		if deps[i].ID < deps[i-1].ID {
+			t.Errorf("%s (id %d) was recorded before %s (id %d)", deps[i].Name, deps[i].ID, deps[i-1].Name, deps[i-1].ID)
+		}
+	}
+}
+
+func TestHarness_PackageReleases(t *testing.T) {
+	h := NewHarness(t)
+	h.Provider.AddRepository("acme/web", map[string]string{
+		"package.json": `{"dependencies": {"react": "^18.2.0"}}`,
+	})
+	h.Registry.SetLatest("npm", "react", "18.3.1")
+	h.AddSource(domain.SourceInput{})
+	h.Scan()
+
+	// Changed manifests are looked up again, finding the new release once
+	h.Registry.SetLatest("npm", "react", "19.0.0")
+	h.Provider.AddRepository("acme/web", map[string]string{
+		"package.json": `{"dependencies": {"react": "^18.3.0"}}`,
+	})
+	h.Scan()
+	h.Provider.AddRepository("acme/web", map[string]string{
+		"package.json": `{"dependencies": {"react": "^18.3.1"}}`,
+	})
+	h.Scan()
+
+	releases, err := h.Deps.GetPackageReleases(context.Background(), "npm")
+	if err != nil {
+		t.Fatalf("GetPackageReleases() error = %v", err)
+	}
+	if len(releases) != 2 || releases[0].Version != "18.3.1" || releases[1].Version != "19.0.0" {
+		t.Errorf("releases = %+v, want 18.3.1 then 19.0.0 recorded once each", releases)
+	}
+}
+
+func TestHarness_AdhocScanPersistsSeparately(t *testing.T) {
+	h := NewHarness(t)
+	h.Provider.AddRepository("acme/web", map[string]string{"package.json": `{"dependencies": {"react": "18.2.0"}}`})
+	h.Registry.SetLatest("npm", "react", "18.3.1")
+	ctx := context.Background()
+
+	result, err := h.Scanner.ScanRepository(ctx, domain.Source{Name: "adhoc", Type: "github"}, "acme/web", "")
+	if err != nil {
+		t.Fatalf("ScanRepository() error = %v", err)
+	}
+	if result.Persisted || result.OutdatedCount != 1 {
+		t.Fatalf("ScanRepository() = %+v, want one outdated dependency, not persisted", result)
+	}
+	if _, err := h.Repos.GetByFullName(ctx, "acme/web"); err == nil {
+		t.Fatal("ScanRepository() should not record the repository")
+	}
+
+	source := h.AddSource(domain.SourceInput{})
+	if err := h.Scanner.PersistRepositoryScan(ctx, source.ID, result); err != nil {
+		t.Fatalf("PersistRepositoryScan() error = %v", err)
+	}
+	if repo := h.Repository("acme/web"); repo.SourceID != source.ID || !result.Persisted {
+		t.Errorf("repository source = %d, want %d", repo.SourceID, source.ID)
+	}
+	if deps := h.Dependencies("acme/web"); len(deps) != 1 || !deps[0].IsOutdated {
+		t.Errorf("dependencies = %+v, want outdated react", deps)
+	}
+}
+
+func TestHarness_LegacyDependenciesKeepOutdatedState(t *testing.T) {
+	h := NewHarness(t)
+	h.Provider.AddRepository("acme/web", map[string]string{"package.json": `{"dependencies": {"react": "18.2.0"}}`})
+	h.Registry.SetLatest("npm", "react", "18.3.1")
+	h.AddSource(domain.SourceInput{})
+	h.Scan()
+
+	// Dependencies recorded before manifest paths were tracked
+	if _, err := h.DB.Exec("UPDATE dependencies SET manifest_path = ''"); err != nil {
+		t.Fatal(err)
+	}
+	firstOutdatedAt := h.Dependencies("acme/web")[0].FirstOutdatedAt
+
+	h.Provider.AddRepository("acme/web", map[string]string{"package.json": `{"dependencies": {"react": "18.2.0", "zod": "3.23.8"}}`})
+	h.Scan()
+
+	deps := h.Dependencies("acme/web")
+	if len(deps) != 2 || deps[0].Name != "react" || deps[0].ManifestPath != "package.json" {
+		t.Fatalf("dependencies = %+v, want react moved to package.json", deps)
+	}
+	if !deps[0].PreviouslyOutdated || deps[0].FirstOutdatedAt == nil || !deps[0].FirstOutdatedAt.Equal(*firstOutdatedAt) {
+		t.Errorf("react = previously outdated %v since %v, want kept since %v", deps[0].PreviouslyOutdated, deps[0].FirstOutdatedAt, firstOutdatedAt)
+	}
+	newly, err := h.Deps.GetNewlyOutdated(context.Background())
+	if err != nil || len(newly) != 0 {
+		t.Errorf("GetNewlyOutdated() = %+v, %v, want none", newly, err)
+	}
+}
+
+// tokenProvider is the fake provider answering token checks with err
+type tokenProvider struct {
+	*FakeProvider
+	err error
+}
+
+func (p *tokenProvider) CheckToken(ctx context.Context) (*time.Time, error) {
+	return nil, p.err
+}
+
+func TestHarness_TokenCheckKeepsStatusOnTransientErrors(t *testing.T) {
+	h := NewHarness(t)
+	provider := &tokenProvider{FakeProvider: h.Provider}
+	h.Scanner.SetProviders(func(domain.Source) scanner.GitProvider { return provider })
+	source := h.AddSource(domain.SourceInput{Token: "ghp_test"})
+	ctx := context.Background()
+
+	checked, err := h.Scanner.CheckSourceToken(ctx, *source)
+	if err != nil || checked.TokenStatus != domain.TokenStatusValid {
+		t.Fatalf("CheckSourceToken() = %+v, %v, want valid", checked, err)
+	}
+
+	// Timeouts and server errors say nothing about the token
+	provider.err = errors.New("https://api.github.com/user: 502 Bad Gateway")
+	if _, err := h.Scanner.CheckSourceToken(ctx, *source); !errors.Is(err, scanner.ErrTokenCheckFailed) {
+		t.Fatalf("CheckSourceToken() error = %v, want ErrTokenCheckFailed", err)
+	}
+	stored, _ := h.Sources.GetByID(ctx, source.ID)
+	if stored.TokenStatus != domain.TokenStatusValid {
+		t.Errorf("token status after a server error = %q, want valid", stored.TokenStatus)
+	}
+
+	provider.err = fmt.Errorf("%w: 401 Bad credentials", httputil.ErrCredentialsRejected)
+	checked, err = h.Scanner.CheckSourceToken(ctx, *source)
+	if err != nil || checked.TokenStatus != domain.TokenStatusInvalid {
+		t.Errorf("CheckSourceToken() = %+v, %v, want invalid", checked, err)
+	}
+}
+
+func TestHarness_ConfigApplyIsAtomic(t *testing.T) {
+	h := NewHarness(t)
+	ctx := context.Background()
+	existing := h.AddSource(domain.SourceInput{Name: "old"})
+	ignoredRepo := repository.NewIgnoredRepository(h.DB)
+	if _, err := ignoredRepo.Create(ctx, &domain.IgnoredDependencyInput{Name: "lodash"}); err != nil {
+		t.Fatal(err)
+	}
+	settingsRepo := repository.NewSettingsRepository(h.DB)
+	configRepo := repository.NewConfigRepository(h.DB)
+	enabled, cron := true, "0 9 * * 1"
+
+	// The duplicate ignore rule fails the last statement, which undoes the others
+	changes := repository.ConfigChangeSet{
+		CreateSources: []domain.SourceInput{{Name: "new", Type: "github", Token: "ghp_test"}},
+		DeleteSources: []int64{existing.ID},
+		CreateIgnores: []domain.IgnoredDependencyInput{{Name: "react"}, {Name: "lodash"}},
+		Settings:      &domain.SettingsInput{ScheduleEnabled: &enabled, ScheduleCron: &cron},
+	}
+	if _, _, err := configRepo.Apply(ctx, changes); err == nil {
+		t.Fatal("Apply() with a duplicate ignore rule succeeded")
+	}
+	sources, _ := h.Sources.GetAll(ctx)
+	ignored, _ := ignoredRepo.GetAll(ctx)
+	settings, _ := settingsRepo.Get(ctx)
+	if len(sources) != 1 || sources[0].Name != "old" || len(ignored) != 1 || settings.ScheduleCron == cron {
+		t.Errorf("after failed Apply(): sources %+v, %d ignore rules, cron %q, want nothing changed", sources, len(ignored), settings.ScheduleCron)
+	}
+
+	changes.CreateIgnores = changes.CreateIgnores[:1]
+	changes.UpdateIgnores = []repository.IgnoreUpdate{{ID: ignored[0].ID, Input: domain.IgnoredDependencyInput{Name: "lodash", Reason: "pinned"}}}
+	created, createdIgnores, err := configRepo.Apply(ctx, changes)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(created) != 1 || created[0].Name != "new" || created[0].Token != "ghp_test" || len(createdIgnores) != 1 {
+		t.Errorf("Apply() = %+v, %+v, want the new source and ignore rule", created, createdIgnores)
+	}
+	sources, _ = h.Sources.GetAll(ctx)
+	settings, _ = settingsRepo.Get(ctx)
+	if len(sources) != 1 || sources[0].Name != "new" || settings.ScheduleCron != cron {
+		t.Errorf("after Apply(): sources %+v, cron %q", sources, settings.ScheduleCron)
+	}
+	if ig, _ := ignoredRepo.GetByID(ctx, ignored[0].ID); ig == nil || ig.Reason != "pinned" {
+		t.Errorf("after Apply(): ignore rule %+v, want the reason updated", ig)
+	}
+}
+
+func TestHarness_UnchangedManifests(t *testing.T) {
+	h := NewHarness(t)
+	ctx := context.Background()
+	h.Provider.AddRepository("acme/api", map[string]string{
+		"pom.xml": `<project><dependencies>
+			<dependency><groupId>com.google.guava</groupId><artifactId>guava</artifactId><version>32.0.0</version></dependency>
+			<dependency><groupId>org.springframework</groupId><artifactId>spring-core</artifactId><version>${spring.version}</version></dependency>
+		</dependencies></project>`,
+	})
+	h.Registry.SetLatest("maven", "com.google.guava:guava", "32.1.0")
+	h.AddSource(domain.SourceInput{})
+	h.Scan()
+	repo := h.Repository("acme/api")
+	parsed, _ := h.Deps.GetManifestHashes(ctx, repo.ID)
+
+	// Within the refresh interval the unchanged manifest is not parsed again, but a new release is
+	// still picked up and the dependency skipped when parsing stays recorded
+	h.Registry.SetLatest("maven", "com.google.guava:guava", "33.0.0")
+	h.Scan()
+	if deps := h.Dependencies("acme/api"); len(deps) != 1 || deps[0].LatestVersion != "33.0.0" || !deps[0].IsOutdated {
+		t.Errorf("dependencies after unchanged scan = %+v, want guava outdated with latest 33.0.0", deps)
+	}
+	if hashes, _ := h.Deps.GetManifestHashes(ctx, repo.ID); !hashes["pom.xml"].HashedAt.Equal(parsed["pom.xml"].HashedAt) {
+		t.Error("the unchanged manifest was parsed again within the refresh interval")
+	}
+	if skipped, _ := h.Deps.GetSkippedByRepoID(ctx, repo.ID); len(skipped) != 1 || skipped[0].Name != "org.springframework:spring-core" {
+		t.Errorf("skipped dependencies after unchanged scan = %+v, want spring-core", skipped)
+	}
+
+	// Past the interval the manifest is parsed again
+	h.Scanner.SetManifestRefreshInterval(0)
+	h.Scan()
+	if hashes, _ := h.Deps.GetManifestHashes(ctx, repo.ID); !hashes["pom.xml"].HashedAt.After(parsed["pom.xml"].HashedAt) {
+		t.Error("the manifest was not parsed again past the refresh interval")
+	}
+}
+
+func TestHarness_Prefetch(t *testing.T) {
+	h := NewHarness(t)
+	h.Provider.AddRepository("acme/web", map[string]string{"package.json": `{"dependencies": {"react": "18.2.0", "zod": "3.23.8"}}`})
+	h.Provider.AddRepository("acme/admin", map[string]string{"package.json": `{"dependencies": {"react": "18.2.0"}}`})
+	h.Registry.SetLatest("npm", "react", "18.3.1")
+	h.AddSource(domain.SourceInput{})
+	h.Scan()
+
+	reactLookups, zodLookups := h.Registry.Lookups("npm", "react"), h.Registry.Lookups("npm", "zod")
+	fetched, err := h.Scanner.Prefetch(context.Background(), 1)
+	if err != nil || fetched != 1 {
+		t.Fatalf("Prefetch() = %d, %v, want 1", fetched, err)
+	}
+	if h.Registry.Lookups("npm", "react") != reactLookups+1 || h.Registry.Lookups("npm", "zod") != zodLookups {
+		t.Error("Prefetch() should look up the package used by the most repositories only")
+	}
+
+	// Packages missing from their registry are not counted
+	if fetched, _ := h.Scanner.Prefetch(context.Background(), 10); fetched != 1 {
+		t.Errorf("Prefetch() = %d, want 1 as zod is not in the registry", fetched)
+	}
+}
+
+func TestHarness_RegistryFallback(t *testing.T) {
+	h := NewHarness(t)
+	h.Scanner.SetRegistryFallback(true)
+	setReact := func(version string) {
+		h.Provider.AddRepository("acme/web", map[string]string{"package.json": `{"dependencies": {"react": "` + version + `"}}`})
+	}
+	setReact("18.2.0")
+	h.Registry.SetLatest("npm", "react", "18.3.1")
+	h.AddSource(domain.SourceInput{})
+	h.Scan()
+
+	// A manifest changed during an outage is compared with the last known latest version
+	h.Registry.SetDown("npm", true)
+	setReact("18.2.1")
+	h.Scan()
+	if dep := h.Dependencies("acme/web")[0]; dep.LatestVersion != "18.3.1" || !dep.IsOutdated || !dep.StaleData {
+		t.Errorf("react = latest %q, outdated %v, stale %v, want 18.3.1 outdated from stale data", dep.LatestVersion, dep.IsOutdated, dep.StaleData)
+	}
+
+	// Failed lookups are retried by the next scan, which clears the mark once they succeed
+	lookups := h.Registry.Lookups("npm", "react")
+	h.Registry.SetDown("npm", false)
+	h.Scan()
+	if h.Registry.Lookups("npm", "react") != lookups+1 {
+		t.Error("the lookup that failed was not retried")
+	}
+	if dep := h.Dependencies("acme/web")[0]; dep.StaleData {
+		t.Error("react is still marked stale data after a successful lookup")
+	}
+
+	// Without the fallback an outage leaves the latest version unknown
+	h.Scanner.SetRegistryFallback(false)
+	h.Registry.SetDown("npm", true)
+	setReact("18.3.0")
+	h.Scan()
+	if dep := h.Dependencies("acme/web")[0]; dep.LatestVersion != "" || dep.IsOutdated || dep.StaleData {
+		t.Errorf("react = latest %q, outdated %v, stale %v, want unknown", dep.LatestVersion, dep.IsOutdated, dep.StaleData)
+	}
+}
+
+func TestHarness_ManifestSnapshots(t *testing.T) {
+	h := NewHarness(t)
+	h.Scanner.SetManifestSnapshots(true, true)
+	web := `{"dependencies": {"react": "18.2.0"}}`
+	h.Provider.AddRepository("acme/platform", map[string]string{
+		"package.json":              `{"private": true}`,
+		"packages/web/package.json": web,
+	})
+	h.AddSource(domain.SourceInput{})
+	h.Scan()
+
+	ctx := context.Background()
+	repoID := h.Repository("acme/platform").ID
+	mc, err := h.Deps.GetManifestContent(ctx, repoID, "packages/web/package.json")
+	if err != nil {
+		t.Fatalf("GetManifestContent() error = %v", err)
+	}
+	if string(mc.Content) != web || !mc.Compressed || mc.Size != int64(len(web)) {
+		t.Errorf("GetManifestContent() = %q, compressed %v, size %d, want %q compressed", mc.Content, mc.Compressed, mc.Size, web)
+	}
+
+	// The content of deleted manifests is removed
+	h.Provider.AddRepository("acme/platform", map[string]string{"package.json": `{"private": true}`})
+	h.Scan()
+	if _, err := h.Deps.GetManifestContent(ctx, repoID, "packages/web/package.json"); err == nil {
+		t.Error("content of a deleted manifest is still stored")
+	}
+}
+
+func TestHarness_RemovedRepositoriesLeftOutOfStats(t *testing.T) {
+	h := NewHarness(t)
+	h.Provider.AddRepository("acme/web", map[string]string{
+		"package.json": `{"dependencies": {"react": "18.2.0"}}`,
+	})
+	h.Provider.AddRepository("acme/legacy", map[string]string{
+		"package.json": `{"dependencies": {"react": "16.14.0", "jquery": "1.12.4"}}`,
+	})
+	h.Registry.SetLatest("npm", "react", "18.3.1")
+	h.Registry.SetLatest("npm", "jquery", "3.7.1")
+	h.AddSource(domain.SourceInput{})
+	h.Scan()
+
+	h.Provider.RemoveRepository("acme/legacy")
+	h.Scan()
+	if h.Repository("acme/legacy").RemovedAt == nil {
+		t.Fatal("acme/legacy not marked removed")
+	}
+
+	ctx := context.Background()
+	stats, err := h.Deps.GetStats(ctx, domain.StatsExclusions{})
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.TotalDependencies != 1 || stats.OutdatedCount != 1 || stats.DistinctPackages != 1 || stats.ByType["dependency"] != 1 {
+		t.Errorf("stats = %d total, %d outdated, %d packages, by type %v, want only the dependency of acme/web",
+			stats.TotalDependencies, stats.OutdatedCount, stats.DistinctPackages, stats.ByType)
+	}
+	if len(stats.TopRepositories) != 1 || stats.TopRepositories[0].Name != "acme/web" {
+		t.Errorf("top repositories = %+v, want acme/web only", stats.TopRepositories)
+	}
+	if repos, _ := h.Repos.Count(ctx); repos != 1 {
+		t.Errorf("repository count = %d, want 1", repos)
+	}
+	upgradable, _ := h.Deps.GetUpgradable(ctx)
+	if len(upgradable) != 1 || upgradable[0].RepoFullName != "acme/web" {
+		t.Errorf("upgradable = %+v, want react of acme/web", upgradable)
+	}
+}
+
+func TestFakeProvider_Branches(t *testing.T) {
+	p := NewFakeProvider()
+	p.AddRepository("acme/app", map[string]string{"package.json": "{}"})
+	p.SetBranch("acme/app", "release/1.x", map[string]string{"pom.xml": "<project/>", "docs/index.md": ""})
+
+	ctx := context.Background()
+	branches, err := p.ListBranches(ctx, "acme/app")
+	if err != nil || len(branches) != 2 || branches[0] != "main" || branches[1] != "release/1.x" {
+		t.Errorf("ListBranches() = %v, %v, want main and release/1.x", branches, err)
+	}
+	paths, err := p.ListManifestFiles(ctx, "acme/app", "release/1.x")
+	if err != nil || len(paths) != 1 || paths[0] != "pom.xml" {
+		t.Errorf("ListManifestFiles() = %v, %v, want pom.xml only", paths, err)
+	}
+	if _, err := p.GetFileContent(ctx, "acme/app", "package.json", "develop"); err == nil {
+		t.Error("GetFileContent() of an unknown branch succeeded")
+	}
+	if _, err := p.GetRepository(ctx, "acme/missing"); err == nil {
+		t.Error("GetRepository() of an unknown repository succeeded")
+	}
+}
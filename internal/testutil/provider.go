@@ -0,0 +1,194 @@
+// Package testutil provides in-memory fakes of the Git providers and package registries the
+// scanner talks to, and a harness scanning them into a temporary SQLite database, so scanner
+// features can be tested end to end without reaching live APIs
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jiin/stale/internal/service/scanner"
+)
+
+// DefaultBranch is the default branch of repositories added to a FakeProvider
+const DefaultBranch = "main"
+
+// FakeProvider is an in-memory scanner.GitProvider. Unknown repositories, branches and files fail
+// like a missing resource of a real provider.
+type FakeProvider struct {
+	mu    sync.Mutex
+	repos map[string]*fakeRepo // by full name
+	calls map[string]int       // by method name
+}
+
+type fakeRepo struct {
+	info     scanner.RepoInfo
+	branches map[string]map[string]string // file contents by path, by branch
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		repos: make(map[string]*fakeRepo),
+		calls: make(map[string]int),
+	}
+}
+
+// AddRepository adds a repository, e.g. owner/repo, whose default branch holds files (contents by
+// path, e.g. packages/api/package.json). Adding a repository again replaces it.
+func (p *FakeProvider) AddRepository(fullName string, files map[string]string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.repos[fullName] = &fakeRepo{
+		info: scanner.RepoInfo{
+			Name:          fullName[strings.LastIndex(fullName, "/")+1:],
+			FullName:      fullName,
+			DefaultBranch: DefaultBranch,
+			HTMLURL:       "https://git.example.com/" + fullName,
+		},
+		branches: map[string]map[string]string{DefaultBranch: copyFiles(files)},
+	}
+}
+
+// SetBranch sets the files of a branch of a repository added before, creating the branch
+func (p *FakeProvider) SetBranch(fullName, branch string, files map[string]string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	repo, ok := p.repos[fullName]
+	if !ok {
+		panic("testutil: SetBranch of unknown repository " + fullName)
+	}
+	repo.branches[branch] = copyFiles(files)
+}
+
+// SetDefaultBranch changes the default branch of a repository added before
+func (p *FakeProvider) SetDefaultBranch(fullName, branch string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	repo, ok := p.repos[fullName]
+	if !ok {
+		panic("testutil: SetDefaultBranch of unknown repository " + fullName)
+	}
+	repo.info.DefaultBranch = branch
+}
+
+// RemoveRepository removes a repository, as if it was deleted or access to it was revoked
+func (p *FakeProvider) RemoveRepository(fullName string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.repos, fullName)
+}
+
+// Calls returns how often a method of the provider was called, e.g. Calls("GetFileContent")
+func (p *FakeProvider) Calls(method string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls[method]
+}
+
+func (p *FakeProvider) ListRepositories(ctx context.Context) ([]scanner.RepoInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls["ListRepositories"]++
+
+	repos := make([]scanner.RepoInfo, 0, len(p.repos))
+	for _, repo := range p.repos {
+		repos = append(repos, repo.info)
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].FullName < repos[j].FullName })
+	return repos, nil
+}
+
+func (p *FakeProvider) GetRepository(ctx context.Context, repoPath string) (*scanner.RepoInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls["GetRepository"]++
+
+	repo, ok := p.repos[repoPath]
+	if !ok {
+		return nil, notFound(repoPath)
+	}
+	info := repo.info
+	return &info, nil
+}
+
+func (p *FakeProvider) GetFileContent(ctx context.Context, repoPath, filePath, ref string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls["GetFileContent"]++
+
+	files, err := p.branch(repoPath, ref)
+	if err != nil {
+		return nil, err
+	}
+	content, ok := files[filePath]
+	if !ok {
+		return nil, notFound(repoPath + "/" + filePath)
+	}
+	return []byte(content), nil
+}
+
+// ListManifestFiles returns the manifests of a branch, sorted by path
+func (p *FakeProvider) ListManifestFiles(ctx context.Context, repoPath, ref string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls["ListManifestFiles"]++
+
+	files, err := p.branch(repoPath, ref)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for path := range files {
+		if scanner.IsManifest(path) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (p *FakeProvider) ListBranches(ctx context.Context, repoPath string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls["ListBranches"]++
+
+	repo, ok := p.repos[repoPath]
+	if !ok {
+		return nil, notFound(repoPath)
+	}
+	branches := make([]string, 0, len(repo.branches))
+	for branch := range repo.branches {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+	return branches, nil
+}
+
+// branch returns the files of a branch of a repository. The caller holds p.mu.
+func (p *FakeProvider) branch(repoPath, ref string) (map[string]string, error) {
+	repo, ok := p.repos[repoPath]
+	if !ok {
+		return nil, notFound(repoPath)
+	}
+	files, ok := repo.branches[ref]
+	if !ok {
+		return nil, notFound(repoPath + "@" + ref)
+	}
+	return files, nil
+}
+
+// notFound returns the error of a missing resource, worded like the API errors of providers
+func notFound(resource string) error {
+	return fmt.Errorf("404 Not Found: %s", resource)
+}
+
+func copyFiles(files map[string]string) map[string]string {
+	copied := make(map[string]string, len(files))
+	for path, content := range files {
+		copied[path] = content
+	}
+	return copied
+}
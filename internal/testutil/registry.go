@@ -0,0 +1,73 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jiin/stale/internal/domain"
+)
+
+// FakeRegistry is an in-memory scanner.Registry serving the latest versions set with SetLatest.
+// Unknown packages fail like a package missing from its registry.
+type FakeRegistry struct {
+	mu       sync.Mutex
+	versions map[string]string // by ecosystem and name
+	lookups  map[string]int
+	down     map[string]bool // ecosystems whose registry is unreachable
+}
+
+func NewFakeRegistry() *FakeRegistry {
+	return &FakeRegistry{
+		versions: make(map[string]string),
+		lookups:  make(map[string]int),
+		down:     make(map[string]bool),
+	}
+}
+
+// SetLatest sets the latest version of a package, named as scans name it (e.g.
+// org.slf4j:slf4j-api for maven). The latest Go release is the go package of the go ecosystem.
+func (r *FakeRegistry) SetLatest(ecosystem, name, version string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.versions[registryKey(ecosystem, name)] = version
+}
+
+// SetDown makes every lookup of an ecosystem fail like an unreachable registry until it is set
+// up again
+func (r *FakeRegistry) SetDown(ecosystem string, down bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.down[ecosystem] = down
+}
+
+// Lookups returns how often the latest version of a package was looked up
+func (r *FakeRegistry) Lookups(ecosystem, name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lookups[registryKey(ecosystem, name)]
+}
+
+func (r *FakeRegistry) LatestVersion(ctx context.Context, dep domain.Dependency) (string, error) {
+	ecosystem := dep.Ecosystem
+	if ecosystem == "gradle" {
+		ecosystem = "maven" // gradle dependencies are resolved from Maven repositories
+	}
+	key := registryKey(ecosystem, dep.Name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups[key]++
+	if r.down[ecosystem] {
+		return "", fmt.Errorf("503 Service Unavailable: %s registry", ecosystem)
+	}
+	version, ok := r.versions[key]
+	if !ok {
+		return "", fmt.Errorf("package not found: %s", key)
+	}
+	return version, nil
+}
+
+func registryKey(ecosystem, name string) string {
+	return ecosystem + ":" + name
+}
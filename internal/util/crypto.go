@@ -6,6 +6,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"io"
 	"os"
@@ -122,3 +123,21 @@ func MustEncrypt(plaintext string) string {
 
 // ErrDecryptionFailed is returned when decryption fails
 var ErrDecryptionFailed = errors.New("decryption failed")
+
+// TokenFingerprint returns a short SHA-256 fingerprint identifying a token without revealing it
+func TokenFingerprint(token string) string {
+	if token == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(hash[:6])
+}
+
+// TokenLast4 returns the last 4 characters of a token. Short tokens return an empty string
+// so that most of the token is never exposed.
+func TokenLast4(token string) string {
+	if len(token) < 12 {
+		return ""
+	}
+	return token[len(token)-4:]
+}
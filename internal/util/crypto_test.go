@@ -1,6 +1,7 @@
 package util
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -95,3 +96,25 @@ func TestMustEncrypt(t *testing.T) {
 		t.Errorf("MustEncrypt() should return encrypted value")
 	}
 }
+
+func TestTokenHints(t *testing.T) {
+	token := "ghp_abcdefghijklmnop1234"
+
+	fp := TokenFingerprint(token)
+	if !strings.HasPrefix(fp, "sha256:") || len(fp) != len("sha256:")+12 {
+		t.Errorf("TokenFingerprint() = %q", fp)
+	}
+	if fp != TokenFingerprint(token) || fp == TokenFingerprint(token+"x") {
+		t.Error("fingerprint should be stable and differ between tokens")
+	}
+	if TokenFingerprint("") != "" {
+		t.Error("empty token should have no fingerprint")
+	}
+
+	if got := TokenLast4(token); got != "1234" {
+		t.Errorf("TokenLast4() = %q, want 1234", got)
+	}
+	if got := TokenLast4("short"); got != "" {
+		t.Errorf("TokenLast4(short) = %q, want empty", got)
+	}
+}
@@ -152,6 +152,87 @@ describe('api', () => {
     });
   });
 
+  describe('getDependency', () => {
+    it('fetches the dependency detail', async () => {
+      const mockDetail = { id: 3, name: 'react', history: [], ignores: [], alerts: [] };
+      mockFetch.mockResolvedValueOnce({
+        ok: true,
+        json: () => Promise.resolve(mockDetail),
+      });
+
+      const result = await api.getDependency(3);
+
+      expect(mockFetch).toHaveBeenCalledWith('/api/v1/dependencies/3', expect.any(Object));
+      expect(result).toEqual(mockDetail);
+    });
+  });
+
+  describe('getConvergence', () => {
+    it('includes min_repos and ecosystem', async () => {
+      mockFetch.mockResolvedValueOnce({
+        ok: true,
+        json: () => Promise.resolve([]),
+      });
+
+      await api.getConvergence(3, 'npm');
+
+      expect(mockFetch).toHaveBeenCalledWith('/api/v1/packages/convergence?min_repos=3&ecosystem=npm', expect.any(Object));
+    });
+  });
+
+  describe('setRecommendedVersion', () => {
+    it('encodes scoped package names', async () => {
+      mockFetch.mockResolvedValueOnce({
+        ok: true,
+        json: () => Promise.resolve({ id: 1, ecosystem: 'npm', name: '@org/ui', version: '2.1.0' }),
+      });
+
+      await api.setRecommendedVersion('npm', '@org/ui', { version: '2.1.0' });
+
+      expect(mockFetch).toHaveBeenCalledWith(
+        '/api/v1/recommended-versions/npm/%40org%2Fui',
+        expect.objectContaining({ method: 'PUT', body: JSON.stringify({ version: '2.1.0' }) })
+      );
+    });
+  });
+
+  describe('getPolicyViolations', () => {
+    it('filters by violation and ecosystem', async () => {
+      mockFetch.mockResolvedValueOnce({
+        ok: true,
+        json: () => Promise.resolve([]),
+      });
+
+      await api.getPolicyViolations('denied', 'npm');
+
+      expect(mockFetch).toHaveBeenCalledWith('/api/v1/policies/violations?violation=denied&ecosystem=npm', expect.any(Object));
+    });
+
+    it('omits the query without filters', async () => {
+      mockFetch.mockResolvedValueOnce({
+        ok: true,
+        json: () => Promise.resolve([]),
+      });
+
+      await api.getPolicyViolations();
+
+      expect(mockFetch).toHaveBeenCalledWith('/api/v1/policies/violations', expect.any(Object));
+    });
+  });
+
+  describe('lookupPackages', () => {
+    it('encodes the query', async () => {
+      mockFetch.mockResolvedValueOnce({
+        ok: true,
+        json: () => Promise.resolve(['org.springframework.boot:spring-boot']),
+      });
+
+      await api.lookupPackages('sprng boot');
+
+      expect(mockFetch).toHaveBeenCalledWith('/api/v1/dependencies/packages?q=sprng%20boot', expect.any(Object));
+    });
+  });
+
   describe('triggerScan', () => {
     it('triggers scan without source_id', async () => {
       mockFetch.mockResolvedValueOnce({
@@ -273,6 +354,24 @@ describe('api', () => {
     });
   });
 
+  describe('bulkRescanRepositories', () => {
+    it('sends array of ids', async () => {
+      const response = { scan_id: 7, queued: false, accepted: 2, deferred: 0, failed: 0, results: [] };
+      mockFetch.mockResolvedValueOnce({
+        ok: true,
+        json: () => Promise.resolve(response),
+      });
+
+      const result = await api.bulkRescanRepositories([1, 2]);
+
+      expect(mockFetch).toHaveBeenCalledWith('/api/v1/repositories/bulk-rescan', expect.objectContaining({
+        method: 'POST',
+        body: JSON.stringify({ ids: [1, 2] }),
+      }));
+      expect(result).toEqual(response);
+    });
+  });
+
   describe('ignored dependencies', () => {
     it('gets ignored list', async () => {
       mockFetch.mockResolvedValueOnce({
@@ -1,4 +1,4 @@
-import type { Source, SourceInput, Repository, Dependency, ScanJob, DependencyStats, PaginatedDependencies, FilterOptions, Settings, SettingsInput, NextScan, IgnoredDependency, IgnoredDependencyInput } from '../types';
+import type { Source, SourceInput, Repository, Dependency, DependencyDetail, ScanJob, BulkRescanResponse, DomainEvent, UndoResult, DependencyStats, PaginatedDependencies, DependencyGroup, DependencyGrouping, DependencySort, SavedView, SavedViewInput, PackageMaintainers, PackageConvergence, FreshnessForecast, ComparisonReport, RecommendedVersion, RecommendedVersionInput, PackagePolicy, PackagePolicyInput, PolicyViolation, PolicyViolationKind, FilterOptions, Settings, SettingsInput, NextScan, Health, IntegrationsStatus, IgnoredDependency, IgnoredDependencyInput, IgnoreImportResult, Capabilities } from '../types';
 
 const API_BASE = '/api/v1';
 
@@ -6,12 +6,14 @@ const API_BASE = '/api/v1';
 export class ApiError extends Error {
   status: number;
   statusText: string;
+  code?: string; // machine-readable error code of the problem details, e.g. scan_running
 
-  constructor(message: string, status: number, statusText: string) {
+  constructor(message: string, status: number, statusText: string, code?: string) {
     super(message);
     this.name = 'ApiError';
     this.status = status;
     this.statusText = statusText;
+    this.code = code;
   }
 
   get isUnauthorized(): boolean {
@@ -37,12 +39,12 @@ export class ApiError extends Error {
 
 // Get user-friendly error message based on status
 function getErrorMessage(status: number, statusText: string, responseText: string): string {
-  // Try to parse JSON error response
+  // Try to parse the problem details (application/problem+json) of the error
   if (responseText) {
     try {
       const json = JSON.parse(responseText);
-      if (json.error || json.message) {
-        return json.error || json.message;
+      if (json.detail || json.title || json.message || json.error) {
+        return json.detail || json.title || json.message || json.error;
       }
     } catch {
       // Not JSON, use as-is if it's not HTML
@@ -75,6 +77,15 @@ function getErrorMessage(status: number, statusText: string, responseText: strin
   }
 }
 
+// Get the machine-readable error code of problem details, if any
+function getErrorCode(responseText: string): string | undefined {
+  try {
+    return JSON.parse(responseText).error_code;
+  } catch {
+    return undefined;
+  }
+}
+
 async function request<T>(endpoint: string, options?: RequestInit): Promise<T> {
   let response: Response;
 
@@ -98,7 +109,7 @@ async function request<T>(endpoint: string, options?: RequestInit): Promise<T> {
   if (!response.ok) {
     const text = await response.text();
     const message = getErrorMessage(response.status, response.statusText, text);
-    throw new ApiError(message, response.status, response.statusText);
+    throw new ApiError(message, response.status, response.statusText, getErrorCode(text));
   }
 
   if (response.status === 204) {
@@ -110,7 +121,9 @@ async function request<T>(endpoint: string, options?: RequestInit): Promise<T> {
 
 export const api = {
   // Health
-  health: () => request<{ status: string }>('/health'),
+  health: () => request<Health>('/health'),
+  getIntegrationsStatus: () => request<IntegrationsStatus>('/status/integrations'),
+  getCapabilities: () => request<Capabilities>('/capabilities'),
 
   // Sources
   getSources: () => request<Source[]>('/sources'),
@@ -121,6 +134,8 @@ export const api = {
     request<Source>(`/sources/${id}`, { method: 'PUT', body: JSON.stringify(data) }),
   deleteSource: (id: number) =>
     request<void>(`/sources/${id}`, { method: 'DELETE' }),
+  enableSource: (id: number) =>
+    request<Source>(`/sources/${id}/enable`, { method: 'POST' }),
 
   // Repositories
   getRepositories: (sourceId?: number) => {
@@ -137,14 +152,29 @@ export const api = {
       method: 'POST',
       body: JSON.stringify({ ids }),
     }),
+  bulkRescanRepositories: (ids: number[]) =>
+    request<BulkRescanResponse>('/repositories/bulk-rescan', {
+      method: 'POST',
+      body: JSON.stringify({ ids }),
+    }),
+
+  // Events
+  getEvents: (limit?: number) =>
+    request<DomainEvent[]>(`/events${limit ? `?limit=${limit}` : ''}`),
+  undoEvent: (id: number) =>
+    request<UndoResult>(`/events/${id}/undo`, { method: 'POST' }),
 
   // Dependencies
   getDependencies: (upgradableOnly?: boolean) => {
     const params = upgradableOnly ? '?outdated=true' : '';
     return request<Dependency[]>(`/dependencies${params}`);
   },
+  getDependency: (id: number) => request<DependencyDetail>(`/dependencies/${id}`),
   getRepositoryNames: () => request<string[]>('/dependencies/repos'),
   getPackageNames: () => request<string[]>('/dependencies/packages'),
+  // Typo-tolerant lookup, closest matches first
+  lookupPackages: (q: string) =>
+    request<string[]>(`/dependencies/packages?q=${encodeURIComponent(q)}`),
   getFilterOptions: (repo?: string, ecosystem?: string, status?: string, pkg?: string) => {
     const params = new URLSearchParams();
     if (repo) params.set('repo', repo);
@@ -154,7 +184,7 @@ export const api = {
     const query = params.toString();
     return request<FilterOptions>(`/dependencies/filter-options${query ? `?${query}` : ''}`);
   },
-  getDependenciesPaginated: (page: number = 1, limit: number = 50, status?: string, repo?: string, ecosystem?: string, search?: string) => {
+  getDependenciesPaginated: (page: number = 1, limit: number = 50, status?: string, repo?: string, ecosystem?: string, search?: string, sort?: DependencySort) => {
     const params = new URLSearchParams();
     params.set('page', String(page));
     params.set('limit', String(limit));
@@ -162,10 +192,43 @@ export const api = {
     if (repo) params.set('repo', repo);
     if (ecosystem) params.set('ecosystem', ecosystem);
     if (search) params.set('search', search);
+    if (sort) params.set('sort', sort);
     return request<PaginatedDependencies>(`/dependencies/paginated?${params.toString()}`);
   },
+  getGroupedDependencies: (by: DependencyGrouping = 'package', status?: string, repo?: string, ecosystem?: string, search?: string) => {
+    const params = new URLSearchParams();
+    params.set('by', by);
+    if (status && status !== 'all') params.set('status', status);
+    if (repo) params.set('repo', repo);
+    if (ecosystem) params.set('ecosystem', ecosystem);
+    if (search) params.set('search', search);
+    return request<DependencyGroup[]>(`/dependencies/grouped?${params.toString()}`);
+  },
   getUpgradableDependencies: () => request<Dependency[]>('/dependencies/upgradable'),
   getDependencyStats: () => request<DependencyStats>('/dependencies/stats'),
+  getPackageMaintainers: (maxMaintainers: number = 1) =>
+    request<PackageMaintainers[]>(`/dependencies/maintainers?max_maintainers=${maxMaintainers}`),
+  getConvergence: (minRepos: number = 2, ecosystem?: string) => {
+    const params = new URLSearchParams({ min_repos: String(minRepos) });
+    if (ecosystem) params.set('ecosystem', ecosystem);
+    return request<PackageConvergence[]>(`/packages/convergence?${params.toString()}`);
+  },
+  getFreshnessForecast: (months: number = 6, majors: number = 2, ecosystem?: string) => {
+    const params = new URLSearchParams({ months: String(months), majors: String(majors) });
+    if (ecosystem) params.set('ecosystem', ecosystem);
+    return request<FreshnessForecast>(`/packages/forecast?${params.toString()}`);
+  },
+  getComparisonReport: (days?: number[]) =>
+    request<ComparisonReport>(days ? `/reports/comparison?days=${days.join(',')}` : '/reports/comparison'),
+
+  // Saved views
+  getViews: () => request<SavedView[]>('/views'),
+  createView: (data: SavedViewInput) =>
+    request<SavedView>('/views', { method: 'POST', body: JSON.stringify(data) }),
+  updateView: (id: number, data: SavedViewInput) =>
+    request<SavedView>(`/views/${id}`, { method: 'PUT', body: JSON.stringify(data) }),
+  deleteView: (id: number) =>
+    request<void>(`/views/${id}`, { method: 'DELETE' }),
 
   // Scans
   triggerScan: (sourceId?: number) =>
@@ -194,6 +257,27 @@ export const api = {
   testEmail: () => request<{ status: string; message: string }>('/settings/test-email', { method: 'POST' }),
   getNextScan: () => request<NextScan>('/settings/next-scan'),
 
+  // Package Policies
+  getPolicies: () => request<PackagePolicy[]>('/policies'),
+  createPolicy: (data: PackagePolicyInput) =>
+    request<PackagePolicy>('/policies', { method: 'POST', body: JSON.stringify(data) }),
+  deletePolicy: (id: number) =>
+    request<void>(`/policies/${id}`, { method: 'DELETE' }),
+  getPolicyViolations: (violation?: PolicyViolationKind, ecosystem?: string) => {
+    const params = new URLSearchParams();
+    if (violation) params.set('violation', violation);
+    if (ecosystem) params.set('ecosystem', ecosystem);
+    const query = params.toString();
+    return request<PolicyViolation[]>(`/policies/violations${query ? `?${query}` : ''}`);
+  },
+
+  // Recommended Versions (changes require the admin API key)
+  getRecommendedVersions: () => request<RecommendedVersion[]>('/recommended-versions'),
+  setRecommendedVersion: (ecosystem: string, name: string, data: RecommendedVersionInput) =>
+    request<RecommendedVersion>(`/recommended-versions/${ecosystem}/${encodeURIComponent(name)}`, { method: 'PUT', body: JSON.stringify(data) }),
+  removeRecommendedVersion: (ecosystem: string, name: string) =>
+    request<void>(`/recommended-versions/${ecosystem}/${encodeURIComponent(name)}`, { method: 'DELETE' }),
+
   // Ignored Dependencies
   getIgnored: () => request<IgnoredDependency[]>('/ignored'),
   addIgnored: (data: IgnoredDependencyInput) =>
@@ -204,4 +288,7 @@ export const api = {
     request<void>(`/ignored/${id}`, { method: 'DELETE' }),
   bulkRemoveIgnored: (ids: number[]) =>
     request<{ deleted: number }>('/ignored/bulk-delete', { method: 'POST', body: JSON.stringify({ ids }) }),
+  ignoredExportUrl: () => `${API_BASE}/ignored/export`,
+  importIgnoredCSV: (csv: string, dryRun = false) =>
+    request<IgnoreImportResult>(`/ignored/import${dryRun ? '?dry_run=true' : ''}`, { method: 'POST', body: csv, headers: { 'Content-Type': 'text/csv' } }),
 };
@@ -1,7 +1,9 @@
-import { useState } from 'react';
+import { useEffect, useState } from 'react';
 import type { ReactNode, CSSProperties } from 'react';
 import { Link, useLocation } from 'react-router-dom';
+import { api } from '../../api/client';
 import { useTheme } from '../../contexts/ThemeContext';
+import type { Health } from '../../types';
 import { SettingsPanel } from './SettingsPanel';
 
 interface Props {
@@ -18,6 +20,13 @@ export function Layout({ children }: Props) {
   const location = useLocation();
   const { theme, toggleTheme } = useTheme();
   const [settingsOpen, setSettingsOpen] = useState(false);
+  const [health, setHealth] = useState<Health | null>(null);
+
+  useEffect(() => {
+    api.health().then(setHealth).catch(() => setHealth(null));
+  }, []);
+
+  const update = health?.update;
 
   return (
     <div style={{ minHeight: '100vh', display: 'flex', flexDirection: 'column' }}>
@@ -85,9 +94,22 @@ export function Layout({ children }: Props) {
             <span style={{ fontSize: '14px', fontWeight: 700, color: 'var(--text-primary)', letterSpacing: '-0.3px' }}>
               stale
             </span>
-            <span style={versionBadgeStyle}>
-              v0.1.0
-            </span>
+            {health?.version && (
+              <span style={versionBadgeStyle}>
+                v{health.version}
+              </span>
+            )}
+            {update?.update_available && (
+              <a
+                href={update.release_url || 'https://github.com/amazingkj/stale/releases'}
+                target="_blank"
+                rel="noopener noreferrer"
+                style={updateLinkStyle}
+                title={`stale v${update.latest_version} is available`}
+              >
+                Update available: v{update.latest_version}
+              </a>
+            )}
           </div>
 
           <div style={{ display: 'flex', alignItems: 'center', gap: '20px' }}>
@@ -192,6 +214,13 @@ const versionBadgeStyle: CSSProperties = {
   boxShadow: '0 2px 6px -2px rgba(124, 181, 149, 0.3)',
 };
 
+const updateLinkStyle: CSSProperties = {
+  fontSize: '12px',
+  fontWeight: 600,
+  color: 'var(--accent)',
+  textDecoration: 'none',
+};
+
 // Components
 function IconButton({ onClick, title, children }: { onClick: () => void; title: string; children: ReactNode }) {
   return (
@@ -920,6 +920,18 @@ function EmailTab({ settings, onUpdate, onTestEmail }: {
         </label>
       </div>
 
+      <div style={{ marginBottom: '20px' }}>
+        <label style={{ display: 'flex', alignItems: 'center', gap: '10px', cursor: 'pointer' }}>
+          <input
+            type="checkbox"
+            checked={settings.email_monthly_report}
+            onChange={(e) => onUpdate({ email_monthly_report: e.target.checked })}
+            style={{ width: '18px', height: '18px', cursor: 'pointer' }}
+          />
+          <span style={{ fontSize: '14px', color: 'var(--text-primary)' }}>Email a monthly report compared with 30 and 90 days ago</span>
+        </label>
+      </div>
+
       <div style={{ marginBottom: '14px' }}>
         <label style={{ display: 'block', fontSize: '13px', fontWeight: 500, color: 'var(--text-primary)', marginBottom: '6px' }}>
           SMTP Host
@@ -1,5 +1,6 @@
 import type { CSSProperties, ReactNode } from 'react';
 import { badgeColors, type BadgeColor } from '../../constants/styles';
+import type { DependencyType } from '../../types';
 
 export type VersionDiffType = 'major' | 'minor' | 'patch' | 'unknown';
 
@@ -41,6 +42,9 @@ export function EcosystemBadge({ ecosystem }: { ecosystem: string }) {
     go: { color: 'accent', label: 'go' },
     maven: { color: 'purple', label: 'maven' },
     gradle: { color: 'success', label: 'gradle' },
+    swift: { color: 'danger', label: 'swift' },
+    cocoapods: { color: 'danger', label: 'cocoapods' },
+    android: { color: 'success', label: 'android' },
   };
 
   const { color, label } = config[ecosystem] || { color: 'muted', label: ecosystem };
@@ -52,17 +56,43 @@ export function EcosystemBadge({ ecosystem }: { ecosystem: string }) {
   );
 }
 
-export function TypeBadge({ type }: { type: 'dependency' | 'devDependency' }) {
+const typeLabels: Record<DependencyType, string> = {
+  dependency: 'prod',
+  devDependency: 'dev',
+  peerDependency: 'peer',
+  optionalDependency: 'optional',
+  bundledDependency: 'bundled',
+};
+
+export function TypeBadge({ type }: { type: DependencyType }) {
   return (
     <Badge
-      color={type === 'dependency' ? 'warning' : 'muted'}
+      color={type === 'dependency' || type === 'bundledDependency' ? 'warning' : 'muted'}
       style={{ fontSize: '10px', padding: '3px 8px' }}
     >
-      {type === 'dependency' ? 'prod' : 'dev'}
+      {typeLabels[type] ?? type}
     </Badge>
   );
 }
 
+const riskLabels: Record<string, string> = {
+  install_script: 'install script',
+  typosquat: 'typosquat?',
+};
+
+// Supply-chain risks of a dependency; the detail is shown on hover
+export function RiskBadge({ risks, detail }: { risks: string; detail?: string }) {
+  return (
+    <span title={detail} style={{ display: 'inline-flex', gap: '4px' }}>
+      {risks.split(',').map((risk) => (
+        <Badge key={risk} color="danger" style={{ fontSize: '10px', padding: '3px 8px' }}>
+          {riskLabels[risk] ?? risk}
+        </Badge>
+      ))}
+    </span>
+  );
+}
+
 export function VersionBadge({ version, isOutdated }: { version: string; isOutdated?: boolean }) {
   // If isOutdated is undefined (Latest column), use neutral styling
   const color = isOutdated === undefined ? 'muted' : isOutdated ? 'danger' : 'success';
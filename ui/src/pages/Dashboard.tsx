@@ -32,6 +32,15 @@ const filterLabels: Record<CardFilter, string> = {
   dev: 'Development Dependencies',
 };
 
+// Percentage and time left of a running scan, e.g. " (42%, about 5 min left)"
+function formatScanProgress(scan: ScanJob): string {
+  const progress = scan.progress;
+  if (!progress || progress.repos_total === 0) return '';
+  const percent = `${Math.min(Math.floor(progress.percent), 99)}%`;
+  if (progress.remaining_seconds <= 0) return ` (${percent})`;
+  return ` (${percent}, about ${Math.ceil(progress.remaining_seconds / 60)} min left)`;
+}
+
 export function Dashboard() {
   const [allDeps, setAllDeps] = useState<Dependency[]>([]);
   const [repositories, setRepositories] = useState<string[]>([]);
@@ -313,7 +322,7 @@ export function Dashboard() {
             <LoadingSpinner size="sm" />
             {currentScan.status === 'pending'
               ? 'Starting scan...'
-              : `Scanning... Found ${currentScan.repos_found} repos, ${currentScan.deps_found} dependencies`}
+              : `Scanning... Found ${currentScan.repos_found} repos, ${currentScan.deps_found} dependencies${formatScanProgress(currentScan)}`}
           </div>
           <button
             onClick={handleCancelScan}
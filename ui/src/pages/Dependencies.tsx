@@ -13,6 +13,7 @@ import {
   Th,
   Td,
   TypeBadge,
+  RiskBadge,
   VersionBadge,
   VersionDiffBadge,
   EcosystemBadge,
@@ -23,8 +24,8 @@ import {
 } from '../components/common';
 import type { PaginatedDependencies, IgnoredDependency, FilterOptions } from '../types';
 
-type StatusFilter = 'all' | 'upgradable' | 'uptodate' | 'prod' | 'dev';
-type EcosystemFilter = '' | 'npm' | 'maven' | 'gradle' | 'go';
+type StatusFilter = 'all' | 'upgradable' | 'uptodate' | 'prod' | 'dev' | 'peer' | 'optional' | 'bundled' | 'provided' | 'single_maintainer' | 'risky';
+type EcosystemFilter = '' | 'npm' | 'maven' | 'gradle' | 'go' | 'swift' | 'cocoapods' | 'android';
 
 const filterLabels: Record<StatusFilter, string> = {
   all: 'All Status',
@@ -32,6 +33,12 @@ const filterLabels: Record<StatusFilter, string> = {
   uptodate: 'Up to Date Only',
   prod: 'Production Only',
   dev: 'Development Only',
+  peer: 'Peer Only',
+  optional: 'Optional Only',
+  bundled: 'Bundled Only',
+  provided: 'Provided Scope Only',
+  single_maintainer: 'Single Maintainer Only',
+  risky: 'Supply-Chain Risks Only',
 };
 
 const ecosystemLabels: Record<EcosystemFilter, string> = {
@@ -39,6 +46,9 @@ const ecosystemLabels: Record<EcosystemFilter, string> = {
   npm: 'npm',
   maven: 'Maven',
   gradle: 'Gradle',
+  swift: 'Swift',
+  cocoapods: 'CocoaPods',
+  android: 'Android',
   go: 'Go',
 };
 
@@ -512,6 +522,9 @@ export function Dependencies() {
                       <span style={{ marginLeft: '8px', fontSize: '12px', color: 'var(--text-muted)' }}>
                         {dep.ecosystem || 'all ecosystems'}
                       </span>
+                      <span style={{ marginLeft: '8px', fontSize: '12px', color: 'var(--text-muted)' }}>
+                        {dep.repository ? `only in ${dep.repository}` : 'all repositories'}
+                      </span>
                     </div>
                   </div>
                 ))}
@@ -595,7 +608,10 @@ export function Dependencies() {
                         <EcosystemBadge ecosystem={dep.ecosystem} />
                       </Td>
                       <Td noEllipsis>
-                        <TypeBadge type={dep.type} />
+                        <div style={{ display: 'flex', alignItems: 'center', flexWrap: 'wrap', gap: '4px' }}>
+                          <TypeBadge type={dep.type} />
+                          {dep.risks && <RiskBadge risks={dep.risks} detail={dep.risk_detail} />}
+                        </div>
                       </Td>
                       <Td noEllipsis>
                         <VersionBadge version={dep.current_version} isOutdated={dep.is_outdated} />
@@ -605,6 +621,16 @@ export function Dependencies() {
                           <VersionBadge version={dep.latest_version} />
                           {dep.is_outdated && <VersionDiffBadge diffType={diffType} />}
                         </div>
+                        {dep.stale_data && (
+                          <div title="The registry could not be reached; this is the last known latest version" style={{ fontSize: '12px', color: 'var(--text-muted)', marginTop: '2px' }}>
+                            Stale data
+                          </div>
+                        )}
+                        {dep.is_outdated && dep.latest_in_major && dep.latest_in_major !== dep.latest_version && dep.latest_in_major !== dep.current_version && (
+                          <div title="Newest version within the current major version" style={{ fontSize: '12px', color: 'var(--text-muted)', marginTop: '2px' }}>
+                            Safe upgrade: {dep.latest_in_major}
+                          </div>
+                        )}
                       </Td>
                     </TableRow>
                   );
@@ -11,6 +11,7 @@ import {
   TableRow,
   Th,
   Td,
+  Badge,
   EcosystemBadge,
   EmptyState,
   LoadingSpinner,
@@ -19,7 +20,7 @@ import {
 import type { Repository, Source } from '../types';
 
 type ViewMode = 'list' | 'grouped';
-type EcosystemFilter = '' | 'npm' | 'maven' | 'gradle' | 'go';
+type EcosystemFilter = '' | 'npm' | 'maven' | 'gradle' | 'go' | 'swift' | 'cocoapods';
 type OutdatedFilter = '' | 'outdated' | 'uptodate';
 
 export function Repositories() {
@@ -30,6 +31,7 @@ export function Repositories() {
   const [error, setError] = useState<string | null>(null);
   const [selectedIds, setSelectedIds] = useState<Set<number>>(new Set());
   const [deleting, setDeleting] = useState(false);
+  const [rescanning, setRescanning] = useState(false);
   const [viewMode, setViewMode] = useState<ViewMode>('grouped');
   const [collapsedGroups, setCollapsedGroups] = useState<Set<number>>(new Set());
   const [search, setSearch] = useState('');
@@ -99,6 +101,27 @@ export function Repositories() {
     }
   }, [selectedIds]);
 
+  const handleBulkRescan = useCallback(async () => {
+    if (selectedIds.size === 0) return;
+
+    setRescanning(true);
+    try {
+      const result = await api.bulkRescanRepositories(Array.from(selectedIds));
+      if (result.failed > 0) {
+        const failures = result.results
+          .filter(r => r.status === 'failed')
+          .map(r => `#${r.id}: ${r.error}`)
+          .join(', ');
+        setError(`${result.failed} repositories could not be rescanned (${failures})`);
+      }
+      setSelectedIds(new Set());
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to rescan repositories');
+    } finally {
+      setRescanning(false);
+    }
+  }, [selectedIds]);
+
   const toggleGroup = useCallback((sourceId: number) => {
     setCollapsedGroups(prev => {
       const next = new Set(prev);
@@ -132,6 +155,8 @@ export function Repositories() {
           case 'go': return repo.has_go_mod;
           case 'maven': return repo.has_pom_xml;
           case 'gradle': return repo.has_build_gradle;
+          case 'swift': return repo.has_swiftpm;
+          case 'cocoapods': return repo.has_podfile;
           default: return true;
         }
       });
@@ -184,14 +209,24 @@ export function Repositories() {
           {selectedIds.size > 0 && ` (${selectedIds.size} selected)`}
         </span>
         {selectedIds.size > 0 && (
-          <Button
-            variant="danger"
-            size="sm"
-            onClick={handleBulkDelete}
-            loading={deleting}
-          >
-            Remove {selectedIds.size} Selected
-          </Button>
+          <div style={{ display: 'flex', gap: '8px' }}>
+            <Button
+              variant="secondary"
+              size="sm"
+              onClick={handleBulkRescan}
+              loading={rescanning}
+            >
+              Rescan {selectedIds.size} Selected
+            </Button>
+            <Button
+              variant="danger"
+              size="sm"
+              onClick={handleBulkDelete}
+              loading={deleting}
+            >
+              Remove {selectedIds.size} Selected
+            </Button>
+          </div>
         )}
       </div>
 
@@ -216,6 +251,8 @@ export function Repositories() {
           <option value="go">Go</option>
           <option value="maven">Maven</option>
           <option value="gradle">Gradle</option>
+          <option value="swift">Swift</option>
+          <option value="cocoapods">CocoaPods</option>
         </select>
         {/* Outdated filter */}
         <select
@@ -414,9 +451,23 @@ export function Repositories() {
                           <div style={{ display: 'flex', gap: '4px', flexWrap: 'wrap' }}>
                             {repo.has_package_json && <EcosystemBadge ecosystem="npm" />}
                             {repo.has_go_mod && <EcosystemBadge ecosystem="go" />}
+                      {repo.go_toolchain_outdated && (
+                        <Badge color="warning" style={{ fontSize: '10px' }}>
+                          go {repo.go_toolchain?.replace(/^go/, '') || repo.go_version} → {repo.go_latest_version}
+                        </Badge>
+                      )}
+                      {repo.go_vendored && <Badge color="muted" style={{ fontSize: '10px' }}>vendored</Badge>}
+                            {repo.go_toolchain_outdated && (
+                              <Badge color="warning" style={{ fontSize: '10px' }}>
+                                go {repo.go_toolchain?.replace(/^go/, '') || repo.go_version} → {repo.go_latest_version}
+                              </Badge>
+                            )}
+                            {repo.go_vendored && <Badge color="muted" style={{ fontSize: '10px' }}>vendored</Badge>}
                             {repo.has_pom_xml && <EcosystemBadge ecosystem="maven" />}
                             {repo.has_build_gradle && <EcosystemBadge ecosystem="gradle" />}
-                            {!repo.has_package_json && !repo.has_go_mod && !repo.has_pom_xml && !repo.has_build_gradle && (
+                            {repo.has_swiftpm && <EcosystemBadge ecosystem="swift" />}
+                            {repo.has_podfile && <EcosystemBadge ecosystem="cocoapods" />}
+                            {!repo.has_package_json && !repo.has_go_mod && !repo.has_pom_xml && !repo.has_build_gradle && !repo.has_swiftpm && !repo.has_podfile && (
                               <span style={{ color: 'var(--text-muted)', fontSize: '12px' }}>-</span>
                             )}
                           </div>
@@ -446,6 +497,11 @@ export function Repositories() {
                           {repo.last_scan_at
                             ? new Date(repo.last_scan_at).toLocaleString()
                             : '-'}
+                          {repo.last_scan_error && (
+                            <div title={repo.last_scan_error} style={{ color: 'var(--danger)', fontSize: '12px', marginTop: '2px' }}>
+                              Last scan failed
+                            </div>
+                          )}
                         </Td>
                       </TableRow>
                     ))}
@@ -521,7 +577,9 @@ export function Repositories() {
                       {repo.has_go_mod && <EcosystemBadge ecosystem="go" />}
                       {repo.has_pom_xml && <EcosystemBadge ecosystem="maven" />}
                       {repo.has_build_gradle && <EcosystemBadge ecosystem="gradle" />}
-                      {!repo.has_package_json && !repo.has_go_mod && !repo.has_pom_xml && !repo.has_build_gradle && (
+                      {repo.has_swiftpm && <EcosystemBadge ecosystem="swift" />}
+                      {repo.has_podfile && <EcosystemBadge ecosystem="cocoapods" />}
+                      {!repo.has_package_json && !repo.has_go_mod && !repo.has_pom_xml && !repo.has_build_gradle && !repo.has_swiftpm && !repo.has_podfile && (
                         <span style={{ color: 'var(--text-muted)', fontSize: '12px' }}>-</span>
                       )}
                     </div>
@@ -551,6 +609,11 @@ export function Repositories() {
                     {repo.last_scan_at
                       ? new Date(repo.last_scan_at).toLocaleString()
                       : '-'}
+                    {repo.last_scan_error && (
+                      <div title={repo.last_scan_error} style={{ color: 'var(--danger)', fontSize: '12px', marginTop: '2px' }}>
+                        Last scan failed
+                      </div>
+                    )}
                   </Td>
                 </TableRow>
               ))}
@@ -43,6 +43,15 @@ export function Sources() {
     }
   }, []);
 
+  const handleEnable = useCallback(async (id: number) => {
+    try {
+      const enabled = await api.enableSource(id);
+      setSources(prev => prev.map((s) => (s.id === id ? enabled : s)));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to enable source');
+    }
+  }, []);
+
   const handleCreate = useCallback(async (input: SourceInput) => {
     const source = await api.createSource(input);
     setSources(prev => [source, ...prev]);
@@ -95,6 +104,7 @@ export function Sources() {
               source={source}
               onEdit={() => setEditingSource(source)}
               onDelete={() => handleDelete(source.id)}
+              onEnable={() => handleEnable(source.id)}
             />
           ))}
         </div>
@@ -122,9 +132,10 @@ interface SourceCardProps {
   source: Source;
   onEdit: () => void;
   onDelete: () => void;
+  onEnable: () => void;
 }
 
-function SourceCard({ source, onEdit, onDelete }: SourceCardProps) {
+function SourceCard({ source, onEdit, onDelete, onEnable }: SourceCardProps) {
   return (
     <Card style={{ padding: '20px' }}>
       <div style={{
@@ -161,8 +172,23 @@ function SourceCard({ source, onEdit, onDelete }: SourceCardProps) {
               Last scanned: {new Date(source.last_scan_at).toLocaleString()}
             </p>
           )}
+          {source.state === 'error' && (
+            <p style={{ fontSize: '12px', fontWeight: 600, color: 'var(--danger-text)', margin: '4px 0 0', paddingLeft: '36px' }}>
+              Disabled after {source.consecutive_failures} failed scans in a row
+            </p>
+          )}
+          {source.last_scan_error && (
+            <p style={{ fontSize: '12px', color: 'var(--danger-text)', margin: '4px 0 0', paddingLeft: '36px' }}>
+              Last scan failed: {source.last_scan_error}
+            </p>
+          )}
         </div>
         <div style={{ display: 'flex', gap: '8px' }}>
+          {source.state === 'error' && (
+            <Button size="sm" onClick={onEnable}>
+              Enable
+            </Button>
+          )}
           <Button variant="secondary" size="sm" onClick={onEdit}>
             Edit
           </Button>
@@ -331,10 +357,10 @@ function SourceModal({ source, onClose, onSubmit }: SourceModalProps) {
             label="Branch (optional)"
             value={scanBranch}
             onChange={(e) => setScanBranch(e.target.value)}
-            placeholder="main, develop, release"
+            placeholder="develop, main, master"
           />
           <p style={{ fontSize: '12px', color: 'var(--text-muted)', margin: 0 }}>
-            Branch to scan. Leave empty to use each repo's default branch.
+            Branch to scan. List several to try them in order until one has manifests. Leave empty to use each repo's default branch.
           </p>
         </div>
 
@@ -12,6 +12,11 @@ export interface Source {
   created_at: string;
   updated_at: string;
   last_scan_at?: string;
+  last_scan_error?: string;  // Error of the last scan, cleared by a successful scan
+  last_scan_error_at?: string;
+  state: 'active' | 'error';  // error once disabled after repeated scan failures
+  consecutive_failures: number;
+  disabled_at?: string;
 }
 
 export interface SourceInput {
@@ -38,22 +43,44 @@ export interface Repository {
   has_pom_xml: boolean;
   has_build_gradle: boolean;
   has_go_mod: boolean;
+  has_swiftpm: boolean;
+  has_podfile: boolean;
+  go_version?: string;
+  go_toolchain?: string;
+  go_vendored: boolean;
+  go_latest_version?: string;
+  go_toolchain_outdated: boolean;
   created_at: string;
   updated_at: string;
   last_scan_at?: string;
+  last_scan_error?: string;  // e.g. a missing scan branch or a manifest that failed to parse
+  last_scan_error_at?: string;
   dependency_count: number;
   outdated_count: number;
 }
 
+export type DependencyType =
+  | 'dependency'
+  | 'devDependency'
+  | 'peerDependency'
+  | 'optionalDependency'
+  | 'bundledDependency';
+
 export interface Dependency {
   id: number;
   repository_id: number;
   name: string;
   current_version: string;
   latest_version: string;
-  type: 'dependency' | 'devDependency';
-  ecosystem: 'npm' | 'maven' | 'gradle' | 'go';
+  latest_in_major?: string; // newest stable version within the current major version
+  recommended_version?: string; // version pinned for the package, compared instead of the latest one
+  type: DependencyType;
+  scope?: string;
+  risks?: string; // comma-separated, e.g. install_script,typosquat
+  risk_detail?: string;
+  ecosystem: 'npm' | 'maven' | 'gradle' | 'go' | 'swift' | 'cocoapods' | 'android';
   is_outdated: boolean;
+  stale_data?: boolean; // latest version is the last known one, as the registry could not be reached
   updated_at: string;
   // Joined fields
   repo_name?: string;
@@ -61,26 +88,129 @@ export interface Dependency {
   source_name?: string;
 }
 
+export interface DependencyVersion {
+  version: string;
+  latest_version: string; // latest version known at the time
+  recorded_at: string;
+}
+
+export interface VulnerabilityAlert {
+  id: number;
+  repository_id: number;
+  alert_number: number;
+  ecosystem: string;
+  package_name: string;
+  manifest_path: string;
+  severity: string;
+  ghsa_id: string;
+  cve_id?: string;
+  summary: string;
+  vulnerable_range: string;
+  patched_version?: string;
+  html_url: string;
+  updated_at: string;
+}
+
+// A dependency with its version history, oldest first, and the ignore rules and alerts of its package
+export interface DependencyDetail extends Dependency {
+  history: DependencyVersion[];
+  ignores: IgnoredDependency[];
+  alerts: VulnerabilityAlert[];
+}
+
 export interface ScanJob {
   id: number;
   source_id?: number;
   status: 'pending' | 'running' | 'completed' | 'failed';
   repos_found: number;
+  repos_total?: number;
   deps_found: number;
   error?: string;
   started_at?: string;
   finished_at?: string;
   created_at: string;
+  progress?: ScanProgress;
+}
+
+// Estimate of a pending or running scan; durations are 0 when they cannot be estimated yet
+export interface ScanProgress {
+  repos_done: number;
+  repos_total: number;
+  percent: number;
+  seconds_per_repo: number;
+  elapsed_seconds: number;
+  estimated_seconds: number;
+  remaining_seconds: number;
+  estimated_finish_at?: string;
+}
+
+export type EventType = 'ignore.created' | 'ignore.deleted' | 'source.created' | 'source.deleted' | 'repository.deleted';
+
+export interface DomainEvent {
+  id: number;
+  type: EventType;
+  entity_id: number;
+  summary: string;
+  created_at: string;
+  undone_at?: string;
+  undoable: boolean;
+}
+
+export interface UndoResult {
+  event: DomainEvent;
+  restored: unknown;
+}
+
+export interface BulkRescanResult {
+  id: number;
+  status: 'accepted' | 'deferred' | 'failed';
+  scan_after?: string;
+  error?: string;
+}
+
+export interface BulkRescanResponse {
+  scan_id?: number;
+  queued: boolean;
+  accepted: number;
+  deferred: number;
+  failed: number;
+  results: BulkRescanResult[];
 }
 
 export interface DependencyStats {
   total_dependencies: number;
   outdated_count: number;
   up_to_date_count: number;
-  by_type: {
-    dependency?: number;
-    devDependency?: number;
-  };
+  by_type: Partial<Record<DependencyType, number>>;
+  by_scope?: Record<string, number>;
+  policy_violations: PolicyViolationStats; // counted apart from staleness
+  excluded_types?: DependencyType[];
+  excluded_scopes?: string[];
+}
+
+// Package on the allowlist or denylist; name is a glob pattern such as @acme/*
+export interface PackagePolicy {
+  id: number;
+  list: 'allow' | 'deny';
+  ecosystem?: string; // empty for every ecosystem
+  name: string;
+  reason?: string;
+  created_at: string;
+}
+
+export type PackagePolicyInput = Omit<PackagePolicy, 'id' | 'created_at'>;
+
+export type PolicyViolationKind = 'denied' | 'not_allowed';
+
+export interface PolicyViolation extends Dependency {
+  violation: PolicyViolationKind;
+  policy_reason?: string;
+}
+
+export interface PolicyViolationStats {
+  denied: number;
+  not_allowed: number;
+  total: number;
 }
 
 export interface PaginatedDependencies {
@@ -91,6 +221,151 @@ export interface PaginatedDependencies {
   total_pages: number;
 }
 
+export type DependencySort = 'name' | 'repo' | 'ecosystem' | 'outdated_since';
+
+// A named combination of dependency list filters
+export interface SavedView {
+  id: number;
+  name: string;
+  status: string;
+  repo: string;
+  ecosystem: string;
+  search: string;
+  sort: DependencySort | '';
+  columns: string; // comma-separated export columns, empty for the default ones
+  created_at: string;
+  updated_at: string;
+}
+
+export type SavedViewInput = Omit<SavedView, 'id' | 'created_at' | 'updated_at'>;
+
+export type DependencyGrouping = 'package' | 'repo' | 'ecosystem';
+
+export interface DependencyGroup {
+  name: string; // package name, repository full name or ecosystem
+  ecosystem?: string; // set when grouped by package
+  dependencies: number;
+  outdated: number;
+  repositories: number;
+  packages: number;
+  // Versions are set when grouped by package
+  min_version?: string;
+  max_version?: string;
+  latest_version?: string;
+}
+
+export interface PackageMaintainers {
+  ecosystem: Dependency['ecosystem'];
+  name: string;
+  maintainers: string; // comma-separated
+  maintainer_count: number;
+  repositories: number;
+  fetched_at: string;
+}
+
+export interface VersionUsage {
+  version: string;
+  usages: number;
+  repositories: number;
+}
+
+// Spread of the versions of a package across repositories
+export interface PackageConvergence {
+  ecosystem: Dependency['ecosystem'];
+  name: string;
+  repositories: number;
+  usages: number;
+  distinct_versions: number;
+  min_version?: string;
+  max_version?: string;
+  mode_version: string;
+  score: number; // share of usages at the mode version, 1 when converged
+  versions: VersionUsage[]; // newest first
+}
+
+// When the next release of a package is likely, from the gaps between its recent releases
+export interface ReleaseWindow {
+  from: string;
+  expected: string;
+  to: string;
+  overdue: boolean;
+}
+
+export interface PackageForecast {
+  ecosystem: Dependency['ecosystem'];
+  name: string;
+  releases_observed: number;
+  last_release_at: string;
+  cadence_days: number;
+  major_cadence_days?: number;
+  next_release?: ReleaseWindow;
+}
+
+export interface DependencyForecast {
+  repository_id: number;
+  repository: string;
+  name: string;
+  ecosystem: Dependency['ecosystem'];
+  manifest_path: string;
+  current_version: string;
+  latest_version: string;
+  majors_behind: number;
+  projected_majors_behind: number;
+  next_release?: ReleaseWindow;
+}
+
+export interface FreshnessForecast {
+  months: number;
+  majors: number;
+  horizon: string;
+  packages: PackageForecast[];
+  at_risk: DependencyForecast[];
+}
+
+// Change in outdated dependencies of a source or team since an archived state
+export interface ComparisonGroup {
+  name: string;
+  before: number;
+  after: number;
+  newly_outdated: number;
+  fixed: number;
+  net: number;
+}
+
+export interface ComparisonPeriod {
+  days: number;
+  snapshot_day?: string; // absent when no state that old is archived
+  before: number;
+  after: number;
+  newly_outdated: number;
+  fixed: number;
+  net: number;
+  by_source: ComparisonGroup[];
+  by_team: ComparisonGroup[];
+}
+
+export interface ComparisonReport {
+  generated_at: string;
+  outdated: number;
+  periods: ComparisonPeriod[];
+}
+
+// Version pinned by an admin for a package, e.g. an internally approved one
+export interface RecommendedVersion {
+  id: number;
+  ecosystem: Dependency['ecosystem'];
+  name: string;
+  version: string;
+  reason?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface RecommendedVersionInput {
+  version: string;
+  reason?: string;
+}
+
 export interface FilterOptions {
   repos: string[];
   packages: string[];
@@ -108,6 +383,7 @@ export interface Settings {
   email_from: string;
   email_to: string;
   email_notify_new_outdated: boolean;
+  email_monthly_report: boolean;
 }
 
 export interface SettingsInput {
@@ -121,6 +397,59 @@ export interface SettingsInput {
   email_from?: string;
   email_to?: string;
   email_notify_new_outdated?: boolean;
+  email_monthly_report?: boolean;
+}
+
+export interface UpdateStatus {
+  current_version: string;
+  latest_version?: string;
+  update_available: boolean;
+  release_url?: string;
+  checked_at?: string;
+  error?: string;
+}
+
+export interface Health {
+  status: string;
+  checks?: Record<string, string>;
+  version?: string;
+  update?: UpdateStatus;
+}
+
+export interface Capabilities {
+  role: 'anonymous' | 'user' | 'admin';
+  trigger_scans: boolean;
+  edit_settings: boolean;
+  manage_sources: boolean;
+  reveal_tokens: boolean;
+  maintenance: boolean;
+  recommended_versions: boolean;
+  maintenance_mode: boolean;
+  features: {
+    auth: boolean;
+    read_only: boolean;
+    ecosystems: string[];
+  };
+  feature_flags: Record<string, boolean>;
+}
+
+export interface IntegrationStatus {
+  name: string;
+  host: string;
+  status: 'ok' | 'degraded' | 'down';
+  requests: number;
+  errors: number;
+  error_rate: number;
+  avg_latency_ms: number;
+  p95_latency_ms: number;
+  last_error?: string;
+  last_error_at?: string;
+  last_success_at?: string;
+}
+
+export interface IntegrationsStatus {
+  window_seconds: number;
+  integrations: IntegrationStatus[];
 }
 
 export interface NextScan {
@@ -134,6 +463,7 @@ export interface IgnoredDependency {
   name: string;
   ecosystem?: string;
   reason?: string;
+  repository?: string; // full name of the only repository the rule applies to; absent for all
   created_at: string;
 }
 
@@ -141,4 +471,19 @@ export interface IgnoredDependencyInput {
   name: string;
   ecosystem?: string;
   reason?: string;
+  repository?: string;
+}
+
+export interface IgnoreImportResult {
+  dry_run: boolean;
+  created: number;
+  skipped: number;
+  failed: number;
+  rows: {
+    row: number;
+    name: string;
+    status: 'created' | 'valid' | 'skipped' | 'invalid' | 'failed';
+    ignored_id?: number;
+    error?: string;
+  }[];
 }
@@ -15,6 +15,19 @@ export function getPackageUrl(name: string, ecosystem: string): string {
     }
     case 'go':
       return `https://pkg.go.dev/${name}`;
+    case 'swift': {
+      const [host, owner, repo] = name.split('/');
+      if (host === 'github.com' && owner && repo) {
+        return `https://swiftpackageindex.com/${owner}/${repo}`;
+      }
+      return `https://${name}`;
+    }
+    case 'cocoapods':
+      return `https://cocoapods.org/pods/${name}`;
+    case 'android':
+      return name === 'com.android.tools.build:gradle'
+        ? 'https://developer.android.com/build/releases/gradle-plugin'
+        : 'https://developer.android.com/tools/releases/platforms';
     default:
       return '#';
   }